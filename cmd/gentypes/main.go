@@ -0,0 +1,43 @@
+// Command gentypes writes the API contract's JSON Schema, its OpenAPI
+// document and the frontend's TypeScript types. Run it from the repository root, usually
+// through `make generate-clients`.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/joshuakim/linefinder/internal/contract"
+)
+
+func main() {
+	schemaPath := flag.String("schema", "docs/api-schema.json", "where to write the JSON Schema")
+	tsPath := flag.String("ts", "web/src/api/types.d.ts", "where to write the TypeScript types")
+	openAPIPath := flag.String("openapi", "docs/openapi.json", "where to write the OpenAPI document")
+	flag.Parse()
+
+	schema, err := contract.Schema(contract.Entries)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ts, err := contract.TypeScript(contract.Entries)
+	if err != nil {
+		log.Fatal(err)
+	}
+	openAPI, err := contract.OpenAPI(contract.Operations)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for path, data := range map[string][]byte{*schemaPath: schema, *tsPath: ts, *openAPIPath: openAPI} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Wrote %s", path)
+	}
+}
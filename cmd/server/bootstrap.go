@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/bootstrap"
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/oddsapi"
+	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/sportsdata"
+)
+
+// runBootstrap fetches sports, games, props, players and averages once and
+// saves them to the database, so a fresh install has data on first start
+func runBootstrap(args []string, client *oddsapi.Client, oddsService *service.OddsService, sportsData *sportsdata.Client, db *database.DB, c clock.Clock) {
+	opts := bootstrap.DefaultOptions()
+
+	fs := flag.NewFlagSet("bootstrap", flag.ExitOnError)
+	sports := fs.String("sports", "nba,nfl", "sports to bootstrap (comma-separated)")
+	fs.DurationVar(&opts.PropsWindow, "props-window", opts.PropsWindow, "fetch props for games starting within this window")
+	fs.IntVar(&opts.MaxPropGames, "max-prop-games", opts.MaxPropGames, "max games per sport to fetch props for (each costs API quota)")
+	fs.IntVar(&opts.MaxPlayers, "max-players", opts.MaxPlayers, "max players per sport to compute averages for")
+	fs.IntVar(&opts.RecentGames, "recent-games", opts.RecentGames, "games per player average")
+	fs.Parse(args)
+
+	opts.Sports = nil
+	for _, s := range strings.Split(*sports, ",") {
+		if sport, ok := models.ParseSport(s); ok {
+			opts.Sports = append(opts.Sports, sport)
+		}
+	}
+	if len(opts.Sports) == 0 {
+		log.Fatalf("bootstrap: no valid sports (use %s)", models.SportChoices())
+	}
+
+	runner := bootstrap.NewRunner(client, oddsService, sportsData, db)
+	runner.SetClock(c)
+	result := runner.Run(opts)
+
+	fmt.Printf("\nBootstrap complete:\n")
+	fmt.Printf("  Sports listed:   %d\n", result.Sports)
+	fmt.Printf("  Games:           %d\n", result.Games)
+	fmt.Printf("  Games w/ props:  %d\n", result.Props)
+	fmt.Printf("  Players:         %d\n", result.Players)
+	fmt.Printf("  Averages:        %d\n", result.Averages)
+	if len(result.Errors) > 0 {
+		fmt.Printf("  Errors:          %d\n", len(result.Errors))
+		for _, e := range result.Errors {
+			fmt.Printf("    - %s\n", e)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// demoEnv is the configuration --demo runs with: polling the built-in
+// sample data every 15 seconds, whatever the time of day, with a quota
+// that won't run out
+var demoEnv = map[string]string{
+	"ODDS_API_KEY":          "demo",
+	"POLL_ENABLED":          "true",
+	"POLL_INTERVAL_SECONDS": "15",
+	"POLL_ADAPTIVE":         "false",
+	"POLL_SPORTS":           "nba,nfl",
+	"API_QUOTA_LIMIT":       "100000",
+}
+
+// applyDemoEnv sets the demo configuration, leaving variables that are
+// already set alone, and points the database at a fresh SQLite file in a
+// temporary directory unless one is configured. It returns that
+// directory, or "" when none was made.
+func applyDemoEnv() string {
+	for name, value := range demoEnv {
+		if _, set := os.LookupEnv(name); !set {
+			os.Setenv(name, value)
+		}
+	}
+
+	if os.Getenv("DATABASE_PATH") != "" || os.Getenv("DATABASE_URL") != "" {
+		return ""
+	}
+	dir, err := os.MkdirTemp("", "linefinder-demo-")
+	if err != nil {
+		log.Fatalf("Failed to create demo database directory: %v", err)
+	}
+	os.Setenv("DATABASE_PATH", filepath.Join(dir, "linefinder.db"))
+	return dir
+}
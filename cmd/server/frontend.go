@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/joshuakim/linefinder/internal/frontend"
+)
+
+// frontendFromEnv returns the handler serving the frontend under /, and
+// what it serves, from FRONTEND_DIR (a built bundle) or FRONTEND_PROXY_URL
+// (a frontend server to proxy to). It's nil when neither is set.
+func frontendFromEnv() (http.Handler, string, error) {
+	if dir := os.Getenv("FRONTEND_DIR"); dir != "" {
+		handler, err := frontend.Static(dir)
+		return handler, dir, err
+	}
+	if target := os.Getenv("FRONTEND_PROXY_URL"); target != "" {
+		handler, err := frontend.Proxy(target)
+		return handler, target, err
+	}
+	return nil, "", nil
+}
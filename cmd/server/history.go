@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/store"
+)
+
+// defaultOddsHistoryRetention is how long per-bookmaker odds history is kept
+const defaultOddsHistoryRetention = 7 * 24 * time.Hour
+
+// oddsHistoryPruneInterval is how often odds history past retention is removed
+const oddsHistoryPruneInterval = time.Hour
+
+// writeSnapshots persists changed games from store updates, so restarts and
+// reports see the latest odds rather than only what bootstrap fetched. Each
+// bookmaker market whose prices moved is also appended to odds history, which
+// is pruned to retention.
+func writeSnapshots(ctx context.Context, updates <-chan store.Update, cancel func(), db *database.DB, clk clock.Clock, retention time.Duration) {
+	defer cancel()
+
+	recorder := newOddsRecorder()
+	ticker := time.NewTicker(oddsHistoryPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pruned, err := db.PruneOddsHistory(clk.Now().Add(-retention))
+			if err != nil {
+				log.Printf("History: failed to prune odds history: %v", err)
+			} else if pruned > 0 {
+				log.Printf("History: pruned %d odds history rows", pruned)
+			}
+		case u := <-updates:
+			if !u.Changed {
+				continue
+			}
+			if err := db.SaveGameSnapshots(u.Games); err != nil {
+				log.Printf("History: failed to save %s snapshots: %v", u.Sport, err)
+			}
+			if err := db.SaveOddsHistory(recorder.changes(u.Sport, u.Games)); err != nil {
+				log.Printf("History: failed to save %s odds history: %v", u.Sport, err)
+			}
+		}
+	}
+}
+
+// oddsRecorder remembers the last prices seen for each sport's games,
+// bookmakers and markets so only moves are written to odds history
+type oddsRecorder struct {
+	last map[models.Sport]map[string]string
+}
+
+func newOddsRecorder() *oddsRecorder {
+	return &oddsRecorder{last: make(map[models.Sport]map[string]string)}
+}
+
+// changes returns a point per outcome for every bookmaker market that differs
+// from the last update for the sport. Markets of games no longer listed are
+// forgotten.
+func (r *oddsRecorder) changes(sport models.Sport, games []models.Game) []database.OddsPoint {
+	previous := r.last[sport]
+	current := make(map[string]string)
+
+	var points []database.OddsPoint
+	for _, game := range games {
+		for _, bm := range game.Bookmakers {
+			for _, market := range bm.Markets {
+				key := game.ID + "|" + bm.Key + "|" + string(market.Key)
+				fingerprint := marketFingerprint(market)
+				current[key] = fingerprint
+				if previous[key] == fingerprint {
+					continue
+				}
+
+				for _, o := range market.Outcomes {
+					points = append(points, database.OddsPoint{
+						GameID:    game.ID,
+						Sport:     string(game.SportKey),
+						Bookmaker: bm.Key,
+						Market:    string(market.Key),
+						Outcome:   o.Label(),
+						Price:     o.Price,
+						Point:     o.Point,
+					})
+				}
+			}
+		}
+	}
+	r.last[sport] = current
+	return points
+}
+
+// marketFingerprint summarizes a market's outcomes so any price or line
+// move changes it
+func marketFingerprint(market models.MarketData) string {
+	var b strings.Builder
+	for _, o := range market.Outcomes {
+		fmt.Fprintf(&b, "%s=%g", o.Label(), o.Price)
+		if o.Point != nil {
+			fmt.Fprintf(&b, "@%g", *o.Point)
+		}
+		b.WriteByte(';')
+	}
+	return b.String()
+}
@@ -2,37 +2,96 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/alertstream"
 	"github.com/joshuakim/linefinder/internal/api"
+	"github.com/joshuakim/linefinder/internal/bets"
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/closing"
+	"github.com/joshuakim/linefinder/internal/cluster"
+	"github.com/joshuakim/linefinder/internal/contract"
 	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/depthcharts"
+	"github.com/joshuakim/linefinder/internal/gamestatus"
+	"github.com/joshuakim/linefinder/internal/injuries"
+	"github.com/joshuakim/linefinder/internal/lineups"
+	"github.com/joshuakim/linefinder/internal/liveprops"
 	"github.com/joshuakim/linefinder/internal/metrics"
 	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/news"
 	"github.com/joshuakim/linefinder/internal/notifications"
 	"github.com/joshuakim/linefinder/internal/oddsapi"
 	"github.com/joshuakim/linefinder/internal/polling"
+	"github.com/joshuakim/linefinder/internal/projections"
+	"github.com/joshuakim/linefinder/internal/recheck"
+	"github.com/joshuakim/linefinder/internal/redact"
+	"github.com/joshuakim/linefinder/internal/reference"
+	"github.com/joshuakim/linefinder/internal/reports"
+	"github.com/joshuakim/linefinder/internal/scanner"
 	"github.com/joshuakim/linefinder/internal/service"
 	"github.com/joshuakim/linefinder/internal/sportsdata"
+	"github.com/joshuakim/linefinder/internal/steam"
 	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/telegram"
+	"github.com/joshuakim/linefinder/internal/upstream"
+	"github.com/joshuakim/linefinder/internal/velocity"
+	"github.com/joshuakim/linefinder/internal/version"
 	"github.com/joshuakim/linefinder/internal/websocket"
 )
 
 func main() {
-	// Get API key from environment
-	apiKey := os.Getenv("ODDS_API_KEY")
-	if apiKey == "" {
-		log.Fatal("ODDS_API_KEY environment variable is required")
+	// Deferred first so it runs after every other deferred cleanup
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
+	opts := parseRunOptions()
+	command := flag.Arg(0)
+
+	// Print build info: `linefinder version`
+	if command == "version" {
+		fmt.Println(version.Get())
+		return
+	}
+
+	if opts.configFile != "" {
+		if err := loadConfigFile(opts.configFile); err != nil {
+			log.Fatalf("Failed to load config file: %v", err)
+		}
+	}
+	setupLogging(opts)
+	log.Printf("LineFinder %s", version.Get())
+
+	// Sample data instead of the Odds API, configured to show everything
+	// working: `linefinder --demo`
+	if opts.demo {
+		if dir := applyDemoEnv(); dir != "" {
+			defer os.RemoveAll(dir)
+		}
+		log.Println("Demo mode: using built-in sample odds")
 	}
 
+	// Secrets may come from files (ODDS_API_KEY_FILE etc.); stop here with
+	// every configuration problem rather than failing on them one by one
+	mustLoadConfig()
+	apiKey := os.Getenv("ODDS_API_KEY")
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -48,26 +107,69 @@ func main() {
 		log.Println("SPORTSDATA_API_KEY not set - using dummy data for injuries/stats")
 	}
 
-	// Initialize database
+	// Initialize database: Postgres when DATABASE_URL is set, so several
+	// instances can share it, otherwise a local SQLite file
 	dbPath := os.Getenv("DATABASE_PATH")
 	if dbPath == "" {
 		homeDir, _ := os.UserHomeDir()
 		dbPath = filepath.Join(homeDir, ".linefinder", "linefinder.db")
 	}
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
-		log.Fatalf("Failed to create database directory: %v", err)
+	var db *database.DB
+	var err error
+	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
+		dbPath = "Postgres (DATABASE_URL)"
+		db, err = database.NewPostgres(databaseURL)
+	} else {
+		// Ensure directory exists
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+			log.Fatalf("Failed to create database directory: %v", err)
+		}
+		sqliteDriver := os.Getenv("SQLITE_DRIVER")
+		if sqliteDriver == "" {
+			sqliteDriver = database.DefaultSQLiteDriver()
+		}
+		db, err = database.NewSQLite(dbPath, sqliteDriver)
 	}
-
-	db, err := database.New(dbPath)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer db.Close()
-	log.Printf("Database initialized at %s", dbPath)
+
+	// Read-heavy queries go to a Postgres read replica when one is set
+	replicaCheckInterval := 30 * time.Second
+	if replicaURL := os.Getenv("DATABASE_REPLICA_URL"); replicaURL != "" {
+		if err := db.SetReadReplica(replicaURL); err != nil {
+			log.Fatalf("Failed to set up read replica: %v", err)
+		}
+		if intervalStr := os.Getenv("DATABASE_REPLICA_CHECK_SECONDS"); intervalStr != "" {
+			if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
+				replicaCheckInterval = time.Duration(interval) * time.Second
+			}
+		}
+	}
+
+	// Encrypt push subscriptions, emails and Discord/webhook secrets at rest
+	encryptionKey := os.Getenv("DATABASE_ENCRYPTION_KEY")
+	if err := db.SetEncryptionKey(encryptionKey); err != nil {
+		log.Fatalf("Failed to set up database encryption: %v", err)
+	}
+	if encryptionKey != "" {
+		log.Println("Database field encryption enabled")
+	}
+
+	// Simulated clock for demo/test environments, adjustable via /api/admin/clock
+	var appClock clock.Clock = clock.Real{}
+	var simClock *clock.Virtual
+	if os.Getenv("SIMULATED_CLOCK") == "true" {
+		simClock = clock.NewVirtual()
+		appClock = simClock
+		log.Println("Simulated clock enabled")
+	}
+	db.SetClock(appClock)
 
 	// Initialize metrics
 	m := metrics.New()
+	m.SetClock(appClock)
 
 	// Set API quota limit from environment (default: 500 for free tier)
 	if quotaStr := os.Getenv("API_QUOTA_LIMIT"); quotaStr != "" {
@@ -78,11 +180,69 @@ func main() {
 		m.APIQuotaLimit = 500 // Default free tier
 	}
 
+	// The Odds API quota renews monthly; without a day it's treated as daily
+	if resetStr := os.Getenv("API_QUOTA_RESET_DAY"); resetStr != "" {
+		if day, err := strconv.Atoi(resetStr); err == nil && day >= 1 && day <= 31 {
+			m.SetMonthlyQuotaReset(day)
+		}
+	}
+
+	// Warn in health when an allowed bookmaker is missing for this many polls
+	if missedStr := os.Getenv("BOOK_MISSED_POLLS_WARNING"); missedStr != "" {
+		if missed, err := strconv.ParseInt(missedStr, 10, 64); err == nil && missed > 0 {
+			m.SetBookMissedPolls(missed)
+		}
+	}
+
 	// Initialize core components
 	client := oddsapi.NewClient(apiKey)
+	client.SetUsageCallback(func(u oddsapi.Usage) {
+		m.RecordAPIUsage(u.Remaining, u.Used, u.LastCost)
+	})
+	if opts.demo {
+		if err := client.UseDemoData(); err != nil {
+			log.Fatalf("Failed to load demo data: %v", err)
+		}
+	}
+
+	// Simulated Odds API failures for staging, set through /api/admin/faults
+	var faults *oddsapi.FaultInjector
+	if os.Getenv("FAULT_INJECTION") == "true" {
+		faults = client.InjectFaults()
+		log.Println("Odds API fault injection enabled")
+	}
 	dataStore := store.New()
 	oddsService := service.NewOddsService(client, dataStore)
 
+	// One-time cold-start fetch: `linefinder bootstrap [flags]`
+	if command == "bootstrap" {
+		runBootstrap(flag.Args()[1:], client, oddsService, sportsDataClient, db, appClock)
+		return
+	}
+
+	// Load a CSV of projections or line targets: `linefinder projections [flags] file.csv`
+	if command == "projections" {
+		runProjectionsImport(flag.Args()[1:], db, appClock)
+		return
+	}
+
+	// Warm the store with upcoming games saved by bootstrap
+	if games, err := db.GetGameSnapshots(appClock.Now()); err != nil {
+		log.Printf("Failed to load game snapshots: %v", err)
+	} else if len(games) > 0 {
+		dataStore.UpdateGames(games)
+		log.Printf("Loaded %d games from snapshots", len(games))
+	}
+
+	// Keep pinned games in the store once they're final
+	if pins, err := db.GetPinnedGames(); err != nil {
+		log.Printf("Failed to load pinned games: %v", err)
+	} else {
+		for _, pin := range pins {
+			dataStore.Pin(pin.GameID)
+		}
+	}
+
 	// Initialize WebSocket hub
 	maxConnections := 1000
 	if maxConnStr := os.Getenv("WS_MAX_CONNECTIONS"); maxConnStr != "" {
@@ -93,19 +253,67 @@ func main() {
 	hub := websocket.NewHub(m, maxConnections)
 	go hub.Run()
 
+	// WebSocket clients authenticate with one of these tokens when any are
+	// set, and browsers may only connect from the allowed origins
+	if tokensStr := os.Getenv("WS_AUTH_TOKENS"); tokensStr != "" {
+		tokens, err := websocket.ParseTokens(tokensStr)
+		if err != nil {
+			log.Fatalf("WS_AUTH_TOKENS: %v", err)
+		}
+		hub.SetTokens(tokens)
+		log.Printf("WebSocket: authentication required (%d tokens)", len(tokens))
+	}
+	if originsStr := os.Getenv("WS_ALLOWED_ORIGINS"); originsStr != "" {
+		var origins []string
+		for _, origin := range strings.Split(originsStr, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+		hub.SetAllowedOrigins(origins)
+	}
+
+	// How long to keep serving WebSocket clients after telling them to
+	// reconnect elsewhere on shutdown, 0 to close them straight away
+	drainPeriod := 10 * time.Second
+	if drainStr := os.Getenv("WS_DRAIN_SECONDS"); drainStr != "" {
+		if drain, err := strconv.Atoi(drainStr); err == nil && drain >= 0 {
+			drainPeriod = time.Duration(drain) * time.Second
+		}
+	}
+
+	// Name this instance in health checks and WebSocket status messages,
+	// so requests behind a load balancer can be traced to it
+	instanceID := os.Getenv("INSTANCE_ID")
+	if instanceID == "" {
+		instanceID = cluster.NewInstanceID()
+	}
+	hub.SetInstanceID(instanceID)
+	m.SetInstance(instanceID)
+
 	// Initialize alert detector
 	alertDetector := alerts.NewDetector(db)
+	alertDetector.SetClock(appClock)
 
 	// Load thresholds from database
 	prefs, err := db.GetPreferences()
 	if err == nil {
-		alertDetector.UpdateThresholds(alerts.Thresholds{
-			Points:   prefs.ThresholdPoints,
-			Rebounds: prefs.ThresholdRebounds,
-			Assists:  prefs.ThresholdAssists,
-			Threes:   prefs.ThresholdThrees,
-			Default:  prefs.ThresholdDefault,
-		})
+		alertDetector.UpdateThresholds(alerts.ThresholdsFromPreferences(prefs))
+		alertDetector.SetMyBook(prefs.MyBook)
+		alertDetector.SetExcludedBookmakers(prefs.ExcludedBookmakers)
+		alertDetector.SetScanWindow(prefs.ScanWindowHours)
+		alertDetector.SetSports(prefs.Sports)
+		alertDetector.SetDetectionModes(alerts.DetectionModesFromPreferences(prefs))
+		alertDetector.SetPriceFilters(alerts.PriceFiltersFromPreferences(prefs))
+		alertDetector.SetDisagreementThreshold(prefs.ProjectionDisagreementPct)
+		oddsService.SetEVSettings(prefs.VigMethod, prefs.EVThresholdPct)
+		oddsService.SetPeriodEVThreshold(prefs.PeriodEVThresholdPct)
+		oddsService.SetOutlierSettings(prefs.OutlierPoints, prefs.OutlierCents)
+	}
+
+	// Resume a threshold experiment left running before restart
+	if err := alertDetector.LoadExperiment(); err != nil {
+		log.Printf("Failed to load threshold experiment: %v", err)
 	}
 
 	// Initialize notification service
@@ -123,7 +331,88 @@ func main() {
 		}
 	}
 
+	// Email delivery (optional)
+	notifConfig.SMTP.Host = os.Getenv("SMTP_HOST")
+	notifConfig.SMTP.Username = os.Getenv("SMTP_USERNAME")
+	notifConfig.SMTP.Password = os.Getenv("SMTP_PASSWORD")
+	notifConfig.SMTP.From = os.Getenv("SMTP_FROM")
+	if portStr := os.Getenv("SMTP_PORT"); portStr != "" {
+		if smtpPort, err := strconv.Atoi(portStr); err == nil {
+			notifConfig.SMTP.Port = smtpPort
+		}
+	}
+	// Telegram delivery and bot commands (optional)
+	notifConfig.Telegram.BotToken = os.Getenv("TELEGRAM_BOT_TOKEN")
+	notifConfig.Telegram.ChatID = os.Getenv("TELEGRAM_CHAT_ID")
+	// Delivery queues and retries
+	if workersStr := os.Getenv("NOTIFY_WORKERS"); workersStr != "" {
+		if workers, err := strconv.Atoi(workersStr); err == nil {
+			notifConfig.Dispatch.Workers = workers
+		}
+	}
+	if attemptsStr := os.Getenv("NOTIFY_MAX_ATTEMPTS"); attemptsStr != "" {
+		if attempts, err := strconv.Atoi(attemptsStr); err == nil {
+			notifConfig.Dispatch.MaxAttempts = attempts
+		}
+	}
+	if ttlStr := os.Getenv("NOTIFY_PENDING_TTL_MINUTES"); ttlStr != "" {
+		if ttl, err := strconv.Atoi(ttlStr); err == nil && ttl > 0 {
+			notifConfig.PendingTTL = time.Duration(ttl) * time.Minute
+		}
+	}
+
+	if publicURL := os.Getenv("PUBLIC_URL"); publicURL != "" {
+		notifConfig.PublicURL = publicURL
+	} else {
+		notifConfig.PublicURL = "http://localhost:" + port
+	}
+	notifConfig.HelplineText = os.Getenv("HELPLINE_TEXT")
+
+	reportBuilder := reports.NewBuilder(oddsService, db)
+	reportBuilder.SetClock(appClock)
+
 	notificationSvc := notifications.NewService(notifConfig, db, hub)
+	notificationSvc.SetReportBuilder(reportBuilder)
+	notificationSvc.SetClock(appClock)
+
+	// Open value alerts are replayed to WebSocket clients subscribing to them
+	hub.SetActiveAlerts(notificationSvc.ActiveValueAlerts)
+
+	// Every alert type across all sports, for /api/alerts/stream and the
+	// WebSocket subscribe_alerts message
+	alertStream := alertstream.New()
+	notificationSvc.SetAlertStream(alertStream)
+	hub.SetAlertStream(alertStream)
+
+	// Telegram commands from the alert chat: /alerts, /odds, /mute
+	var telegramBot *telegram.Bot
+	if notifConfig.Telegram.Configured() {
+		telegramBot = telegram.NewBot(telegram.NewClient(notifConfig.Telegram.BotToken), notifConfig.Telegram.ChatID, oddsService, db)
+		telegramBot.SetClock(appClock)
+	}
+
+	// Share broadcasts and alerts with other instances through Redis, and
+	// elect one of them to poll
+	var bridge *cluster.Bridge
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		clusterConfig := cluster.DefaultConfig()
+		clusterConfig.RedisURL = redisURL
+		clusterConfig.InstanceID = instanceID
+		if prefix := os.Getenv("REDIS_KEY_PREFIX"); prefix != "" {
+			clusterConfig.KeyPrefix = prefix
+		}
+		if ttlStr := os.Getenv("POLLER_LOCK_TTL_SECONDS"); ttlStr != "" {
+			if ttl, err := strconv.Atoi(ttlStr); err == nil && ttl > 0 {
+				clusterConfig.LockTTL = time.Duration(ttl) * time.Second
+			}
+		}
+		bridge, err = cluster.New(clusterConfig, hub, dataStore, alertStream)
+		if err != nil {
+			log.Fatalf("Failed to start cluster bridge: %v", err)
+		}
+		hub.SetRelay(bridge)
+		alertStream.SetRelay(bridge)
+	}
 
 	// Initialize polling service
 	pollConfig := polling.DefaultConfig()
@@ -139,28 +428,679 @@ func main() {
 	}
 	if sportsStr := os.Getenv("POLL_SPORTS"); sportsStr != "" {
 		pollConfig.Sports = []models.Sport{}
-		if sportsStr == "nba" || sportsStr == "nba,nfl" || sportsStr == "nfl,nba" {
-			pollConfig.Sports = append(pollConfig.Sports, models.SportNBA)
-		}
-		if sportsStr == "nfl" || sportsStr == "nba,nfl" || sportsStr == "nfl,nba" {
-			pollConfig.Sports = append(pollConfig.Sports, models.SportNFL)
+		for _, s := range strings.Split(sportsStr, ",") {
+			if sport, ok := models.ParseSport(s); ok {
+				pollConfig.Sports = append(pollConfig.Sports, sport)
+			}
 		}
 		if len(pollConfig.Sports) == 0 {
 			pollConfig.Sports = []models.Sport{models.SportNBA, models.SportNFL}
 		}
 	}
+	if retriesStr := os.Getenv("POLL_MAX_RETRIES"); retriesStr != "" {
+		if retries, err := strconv.Atoi(retriesStr); err == nil && retries > 0 {
+			pollConfig.MaxRetries = retries
+		}
+	}
+	if delayStr := os.Getenv("POLL_RETRY_BASE_DELAY_SECONDS"); delayStr != "" {
+		if delay, err := strconv.Atoi(delayStr); err == nil && delay > 0 {
+			pollConfig.RetryBaseDelay = time.Duration(delay) * time.Second
+		}
+	}
+	if maxErrStr := os.Getenv("POLL_MAX_CONSECUTIVE_ERRORS"); maxErrStr != "" {
+		if maxErr, err := strconv.Atoi(maxErrStr); err == nil && maxErr > 0 {
+			pollConfig.MaxConsecutiveErrors = maxErr
+		}
+	}
+	if recoveryStr := os.Getenv("POLL_RECOVERY_INTERVAL_SECONDS"); recoveryStr != "" {
+		if recovery, err := strconv.Atoi(recoveryStr); err == nil && recovery > 0 {
+			pollConfig.RecoveryInterval = time.Duration(recovery) * time.Second
+		}
+	}
+
+	// Requests held back from polling so the quota isn't run to zero
+	if reserveStr := os.Getenv("POLL_QUOTA_RESERVE"); reserveStr != "" {
+		if reserve, err := strconv.ParseInt(reserveStr, 10, 64); err == nil && reserve >= 0 {
+			pollConfig.QuotaReserve = reserve
+		}
+	}
+
+	// Half and quarter markets and team totals for NBA and NFL games, off
+	// unless listed
+	if marketsStr := os.Getenv("PERIOD_MARKETS"); marketsStr != "" {
+		for _, m := range strings.Split(marketsStr, ",") {
+			if market, err := models.ParseEventMarket(m); err == nil {
+				pollConfig.PeriodMarkets = append(pollConfig.PeriodMarkets, market)
+			}
+		}
+	}
+	if periodStr := os.Getenv("PERIOD_POLL_INTERVAL_MINUTES"); periodStr != "" {
+		if period, err := strconv.Atoi(periodStr); err == nil && period > 0 {
+			pollConfig.PeriodInterval = time.Duration(period) * time.Minute
+		}
+	}
+
+	// Adaptive schedule: fast around games, slow when none are near, off overnight
+	if adaptive := os.Getenv("POLL_ADAPTIVE"); adaptive == "false" {
+		pollConfig.Schedule.Enabled = false
+	}
+	if liveStr := os.Getenv("POLL_LIVE_INTERVAL_SECONDS"); liveStr != "" {
+		if live, err := strconv.Atoi(liveStr); err == nil && live > 0 {
+			pollConfig.Schedule.LiveInterval = time.Duration(live) * time.Second
+		}
+	}
+	if leadStr := os.Getenv("POLL_PREGAME_MINUTES"); leadStr != "" {
+		if lead, err := strconv.Atoi(leadStr); err == nil && lead >= 0 {
+			pollConfig.Schedule.Lead = time.Duration(lead) * time.Minute
+		}
+	}
+	if burstStr := os.Getenv("POLL_CLOSING_BURST_MINUTES"); burstStr != "" {
+		if burst, err := strconv.Atoi(burstStr); err == nil && burst >= 0 {
+			pollConfig.Schedule.BurstWindow = time.Duration(burst) * time.Minute
+		}
+	}
+	if burstStr := os.Getenv("POLL_CLOSING_BURST_SECONDS"); burstStr != "" {
+		if burst, err := strconv.Atoi(burstStr); err == nil && burst > 0 {
+			pollConfig.Schedule.BurstInterval = time.Duration(burst) * time.Second
+		}
+	}
+	if budgetStr := os.Getenv("POLL_CLOSING_BURST_BUDGET_PERCENT"); budgetStr != "" {
+		if budget, err := strconv.ParseFloat(budgetStr, 64); err == nil && budget >= 0 && budget <= 100 {
+			pollConfig.Schedule.BurstBudget = budget / 100
+		}
+	}
+	if idleStr := os.Getenv("POLL_IDLE_INTERVAL_MINUTES"); idleStr != "" {
+		if idle, err := strconv.Atoi(idleStr); err == nil && idle > 0 {
+			pollConfig.Schedule.IdleInterval = time.Duration(idle) * time.Minute
+		}
+	}
+	if horizonStr := os.Getenv("POLL_IDLE_HORIZON_HOURS"); horizonStr != "" {
+		if horizon, err := strconv.Atoi(horizonStr); err == nil && horizon > 0 {
+			pollConfig.Schedule.IdleHorizon = time.Duration(horizon) * time.Hour
+		}
+	}
+	if startStr := os.Getenv("POLL_OVERNIGHT_START_HOUR"); startStr != "" {
+		if hour, err := strconv.Atoi(startStr); err == nil && hour >= 0 && hour < 24 {
+			pollConfig.Schedule.OvernightStart = hour
+		}
+	}
+	if endStr := os.Getenv("POLL_OVERNIGHT_END_HOUR"); endStr != "" {
+		if hour, err := strconv.Atoi(endStr); err == nil && hour >= 0 && hour < 24 {
+			pollConfig.Schedule.OvernightEnd = hour
+		}
+	}
+	if tz := os.Getenv("POLL_TIMEZONE"); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			pollConfig.Schedule.Location = loc
+		} else {
+			log.Printf("Invalid POLL_TIMEZONE %q, using local time: %v", tz, err)
+		}
+	}
+
+	// Enabled sports are persisted and managed at runtime; POLL_SPORTS only
+	// seeds them on first run
+	sportsCatalog := service.NewSportsCatalog(client, db)
+	sportsCatalog.SetClock(appClock)
+	if err := sportsCatalog.Load(pollConfig.Sports); err != nil {
+		log.Printf("Failed to load sport settings: %v", err)
+	} else {
+		pollConfig.Sports = sportsCatalog.Enabled()
+	}
 
 	pollingSvc := polling.NewService(pollConfig, oddsService, hub, m)
+	pollingSvc.SetClock(appClock)
+	sportsCatalog.OnChange(pollingSvc.SetSports)
 
-	// Wire alert detection to polling service
-	pollingSvc.SetAlertDetector(alertDetector, func(valueAlerts []alerts.ValueAlert) {
+	// Alert scanning runs on its own worker, fed by store updates
+	scanConfig := scanner.DefaultConfig()
+	if enabled := os.Getenv("ALERT_SCAN_ENABLED"); enabled == "false" {
+		scanConfig.Enabled = false
+	}
+	if queueStr := os.Getenv("ALERT_SCAN_QUEUE_SIZE"); queueStr != "" {
+		if queue, err := strconv.Atoi(queueStr); err == nil && queue > 0 {
+			scanConfig.QueueSize = queue
+		}
+	}
+	if maxStr := os.Getenv("ALERT_THROTTLE_MAX"); maxStr != "" {
+		if max, err := strconv.Atoi(maxStr); err == nil && max >= 0 {
+			scanConfig.ThrottleAlerts = max
+		}
+	}
+	if minutesStr := os.Getenv("ALERT_THROTTLE_MINUTES"); minutesStr != "" {
+		if minutes, err := strconv.Atoi(minutesStr); err == nil && minutes > 0 {
+			scanConfig.ThrottleWindow = time.Duration(minutes) * time.Minute
+		}
+	}
+	// External projections blended into averages before comparing lines
+	projectionBlender := projections.NewBlender(db)
+
+	alertScanner := scanner.NewScanner(scanConfig, dataStore, alertDetector, m)
+	alertScanner.SetClock(appClock)
+	alertScanner.SetProjections(projectionBlender)
+	alertScanner.SetCallback(func(valueAlerts []alerts.ValueAlert) {
 		notificationSvc.QueueAlerts(valueAlerts)
 	})
+	alertScanner.SetStateCallback(notificationSvc.PublishAlertStates)
+	alertScanner.SetOddsService(oddsService)
+	alertScanner.SetEVCallback(notificationSvc.NotifyEV)
+	alertScanner.SetOutlierCallback(notificationSvc.NotifyOutliers)
+
+	// Re-check earlier alerts against the latest lines shortly before each game
+	recheckConfig := recheck.DefaultConfig()
+	if leadStr := os.Getenv("RECHECK_LEAD_MINUTES"); leadStr != "" {
+		if lead, err := strconv.Atoi(leadStr); err == nil && lead > 0 {
+			recheckConfig.Lead = time.Duration(lead) * time.Minute
+		}
+	}
+	recheckChecker := recheck.NewChecker(recheckConfig, db, alertDetector, oddsService)
+	recheckChecker.SetClock(appClock)
+	recheckChecker.SetProjections(projectionBlender)
+	recheckChecker.SetCallback(func(results []recheck.Result) {
+		for _, r := range results {
+			prop := fmt.Sprintf("%s %s %s", r.PlayerName, r.Direction, r.PropCategory)
+			title := fmt.Sprintf("Edge gone: %s", prop)
+			body := fmt.Sprintf("Alerted at %.1f; now %.1f at %s vs %.1f average (%s @ %s).",
+				r.PreviousLine, r.Line, r.Bookmaker, r.Average, r.AwayTeam, r.HomeTeam)
+			if r.Bookmaker == "" {
+				body = fmt.Sprintf("Alerted at %.1f; the prop is no longer offered (%s @ %s).", r.PreviousLine, r.AwayTeam, r.HomeTeam)
+			}
+			if r.Status == recheck.StillLive {
+				title = fmt.Sprintf("Still live: %s", prop)
+				body = fmt.Sprintf("Now %.1f at %s vs %.1f average (%s @ %s), %s confidence",
+					r.Line, r.Bookmaker, r.Average, r.AwayTeam, r.HomeTeam, r.Confidence)
+				if r.ConfidenceChange != "" {
+					body += fmt.Sprintf(", %s from %s", r.ConfidenceChange, r.PreviousConfidence)
+				}
+				body += "."
+			}
+			sport := ""
+			if game, ok := oddsService.GetGame(r.GameID); ok {
+				sport = string(game.SportKey)
+			}
+			notificationSvc.NotifyEvent(alerts.EventAlert{
+				Type:   "recheck",
+				Kind:   r.Status,
+				Title:  title,
+				Body:   body,
+				Sport:  sport,
+				GameID: r.GameID,
+				Player: r.PlayerName,
+			})
+		}
+	})
+
+	// Grade logged bets from final scores once their games are over
+	betConfig := bets.DefaultConfig()
+	if intervalStr := os.Getenv("BET_GRADE_INTERVAL_MINUTES"); intervalStr != "" {
+		if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
+			betConfig.Interval = time.Duration(interval) * time.Minute
+		}
+	}
+	betGrader := bets.NewGrader(betConfig, client, db)
+	betGrader.SetClock(appClock)
+
+	// Suggest hedging or middling open bets whose lines have moved before
+	// their games start
+	reminderConfig := bets.DefaultReminderConfig()
+	if leadStr := os.Getenv("BET_REMINDER_LEAD_MINUTES"); leadStr != "" {
+		if lead, err := strconv.Atoi(leadStr); err == nil && lead > 0 {
+			reminderConfig.Lead = time.Duration(lead) * time.Minute
+		}
+	}
+	betReminder := bets.NewReminder(reminderConfig, db, oddsService)
+	betReminder.SetClock(appClock)
+	betReminder.SetCallback(func(found []bets.Opportunity) {
+		for _, o := range found {
+			notificationSvc.NotifyEvent(alerts.EventAlert{
+				Type:   "bet_reminder",
+				Kind:   o.Type,
+				Title:  o.Title(),
+				Body:   o.Summary(),
+				Sport:  o.Bet.Sport,
+				GameID: o.Bet.GameID,
+			})
+		}
+	})
+
+	// Move games from scheduled to live to final, dropping final games
+	statusConfig := gamestatus.DefaultConfig()
+	if intervalStr := os.Getenv("SCORES_INTERVAL_MINUTES"); intervalStr != "" {
+		if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
+			statusConfig.ScoresInterval = time.Duration(interval) * time.Minute
+		}
+	}
+	if hoursStr := os.Getenv("GAME_FINAL_AFTER_HOURS"); hoursStr != "" {
+		if hours, err := strconv.Atoi(hoursStr); err == nil && hours > 0 {
+			statusConfig.FinalAfter = time.Duration(hours) * time.Hour
+		}
+	}
+	statusTracker := gamestatus.NewTracker(statusConfig, client, oddsService, hub)
+	statusTracker.SetClock(appClock)
+
+	// Tell the user when polling degrades into recovery mode and when it recovers
+	pollingSvc.SetRecoveryCallback(func(entered bool, consecutiveErrors int64, lastErr string) {
+		if entered {
+			notificationSvc.NotifySystem(alerts.SystemNotice{
+				Kind:  "polling_recovery_entered",
+				Title: "Odds polling degraded",
+				Body: fmt.Sprintf("Polling entered recovery mode after %d consecutive errors (last error: %s). Odds may be stale.",
+					consecutiveErrors, redact.String(lastErr)),
+			})
+			return
+		}
+		notificationSvc.NotifySystem(alerts.SystemNotice{
+			Kind:  "polling_recovery_exited",
+			Title: "Odds polling recovered",
+			Body:  "Polling is healthy again and odds are updating normally.",
+		})
+	})
+
+	// Periodic upstream availability checks, separate from polling
+	upstreamConfig := upstream.DefaultConfig()
+	if intervalStr := os.Getenv("UPSTREAM_CHECK_INTERVAL_SECONDS"); intervalStr != "" {
+		if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
+			upstreamConfig.Interval = time.Duration(interval) * time.Second
+		}
+	}
+	upstreamMonitor := upstream.NewMonitor(upstreamConfig, m)
+	upstreamMonitor.SetClock(appClock)
+	upstreamMonitor.Register("odds_api", client.Ping)
+	if sportsDataClient != nil {
+		upstreamMonitor.Register("sportsdata", sportsDataClient.Ping)
+	}
+
+	// NBA starting lineup checks near tip-off (requires SportsDataIO)
+	var lineupMonitor *lineups.Monitor
+	if sportsDataClient != nil {
+		lineupConfig := lineups.DefaultConfig()
+		if intervalStr := os.Getenv("LINEUP_CHECK_INTERVAL_SECONDS"); intervalStr != "" {
+			if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
+				lineupConfig.Interval = time.Duration(interval) * time.Second
+			}
+		}
+		if windowStr := os.Getenv("LINEUP_WINDOW_MINUTES"); windowStr != "" {
+			if window, err := strconv.Atoi(windowStr); err == nil && window > 0 {
+				lineupConfig.Window = time.Duration(window) * time.Minute
+			}
+		}
+		lineupMonitor = lineups.NewMonitor(lineupConfig, sportsDataClient, oddsService)
+		lineupMonitor.SetClock(appClock)
+		lineupMonitor.SetCallback(func(changes []lineups.Change) {
+			for _, c := range changes {
+				title := fmt.Sprintf("%s not starting", c.Player)
+				body := fmt.Sprintf("%s (%s) was projected to start for the %s but isn't in the confirmed lineup (%s @ %s).",
+					c.Player, c.Position, c.Team, c.AwayTeam, c.HomeTeam)
+				if c.Kind == lineups.SurpriseStart {
+					title = fmt.Sprintf("%s starting", c.Player)
+					body = fmt.Sprintf("%s (%s) is in the confirmed lineup for the %s without being projected to start (%s @ %s).",
+						c.Player, c.Position, c.Team, c.AwayTeam, c.HomeTeam)
+				}
+				notificationSvc.NotifyEvent(alerts.EventAlert{
+					Type:   "lineup",
+					Kind:   c.Kind,
+					Title:  title,
+					Body:   body,
+					Sport:  string(models.SportNBA),
+					GameID: c.GameID,
+					Player: c.Player,
+				})
+			}
+		})
+	}
+
+	// Live NBA box scores against pre-game prop lines (requires SportsDataIO)
+	var liveTracker *liveprops.Tracker
+	if sportsDataClient != nil {
+		liveConfig := liveprops.DefaultConfig()
+		if intervalStr := os.Getenv("LIVE_PROPS_INTERVAL_SECONDS"); intervalStr != "" {
+			if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
+				liveConfig.Interval = time.Duration(interval) * time.Second
+			}
+		}
+		liveTracker = liveprops.NewTracker(liveConfig, sportsDataClient, oddsService)
+		liveTracker.SetClock(appClock)
+		liveTracker.SetCallback(hub.BroadcastLiveProps)
+		if bridge != nil {
+			bridge.SetLiveProps(liveTracker)
+		}
+	}
+
+	// NFL depth charts for usage context (requires SportsDataIO)
+	var depthChartTracker *depthcharts.Tracker
+	if sportsDataClient != nil {
+		depthConfig := depthcharts.DefaultConfig()
+		if intervalStr := os.Getenv("DEPTH_CHART_INTERVAL_MINUTES"); intervalStr != "" {
+			if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
+				depthConfig.Interval = time.Duration(interval) * time.Minute
+			}
+		}
+		depthChartTracker = depthcharts.NewTracker(depthConfig, sportsDataClient)
+		depthChartTracker.SetClock(appClock)
+		depthChartTracker.SetCallback(func(changes []depthcharts.Change) {
+			for _, c := range changes {
+				verb := "promoted"
+				if c.Kind == depthcharts.Demoted {
+					verb = "moved down"
+				}
+				notificationSvc.NotifyEvent(alerts.EventAlert{
+					Type:   "depth_chart",
+					Kind:   c.Kind,
+					Sport:  string(models.SportNFL),
+					Title:  fmt.Sprintf("%s now %s", c.Player, c.To),
+					Body:   fmt.Sprintf("%s %s from %s to %s on the depth chart. Props may not reflect the new role yet.", c.Player, verb, c.From, c.To),
+					Player: c.Player,
+				})
+			}
+		})
+
+		// Mention NFL roles in value alert pushes
+		notificationSvc.SetPlayerContext(func(a alerts.ValueAlert) string {
+			if a.Sport != "nfl" && a.Sport != string(models.SportNFL) {
+				return ""
+			}
+			return depthChartTracker.Context(a.PlayerName)
+		})
+	}
+
+	// Injury status changes before games (requires SportsDataIO)
+	var injuryMonitor *injuries.Monitor
+	if sportsDataClient != nil {
+		injuryConfig := injuries.DefaultConfig()
+		if intervalStr := os.Getenv("INJURY_CHECK_INTERVAL_MINUTES"); intervalStr != "" {
+			if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
+				injuryConfig.Interval = time.Duration(interval) * time.Minute
+			}
+		}
+		injuryMonitor = injuries.NewMonitor(injuryConfig, sportsDataClient, oddsService)
+		injuryMonitor.SetClock(appClock)
+		injuryMonitor.SetCallback(func(changes []injuries.Change) {
+			for _, c := range changes {
+				body := fmt.Sprintf("%s (%s @ %s).", c.Summary(), c.AwayTeam, c.HomeTeam)
+				if len(c.Teammates) > 0 {
+					names := make([]string, len(c.Teammates))
+					for i, t := range c.Teammates {
+						names[i] = t.Name
+					}
+					body += fmt.Sprintf(" Teammates' props may move: %s.", strings.Join(names, ", "))
+				}
+				notificationSvc.NotifyEvent(alerts.EventAlert{
+					Type:   "injury_alert",
+					Kind:   c.Kind,
+					Title:  fmt.Sprintf("%s now %s", c.Player, injuries.StatusLabel(c.To)),
+					Body:   body,
+					Sport:  c.Sport,
+					GameID: c.GameID,
+					Player: c.Player,
+					Props:  c.Teammates,
+				})
+			}
+		})
+	}
+
+	// Per-bookmaker odds history, served by /api/history
+	oddsHistoryRetention := defaultOddsHistoryRetention
+	if retentionStr := os.Getenv("ODDS_HISTORY_RETENTION_HOURS"); retentionStr != "" {
+		if retention, err := strconv.Atoi(retentionStr); err == nil && retention > 0 {
+			oddsHistoryRetention = time.Duration(retention) * time.Hour
+		}
+	}
+
+	// Periodic copies of the metrics counters, for measuring them over a
+	// window with /api/admin/metrics/snapshot
+	metricsSnapshotInterval := defaultMetricsSnapshotInterval
+	if intervalStr := os.Getenv("METRICS_SNAPSHOT_MINUTES"); intervalStr != "" {
+		if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
+			metricsSnapshotInterval = time.Duration(interval) * time.Minute
+		}
+	}
+	metricsSnapshotRetention := defaultMetricsSnapshotRetention
+	if retentionStr := os.Getenv("METRICS_SNAPSHOT_RETENTION_HOURS"); retentionStr != "" {
+		if retention, err := strconv.Atoi(retentionStr); err == nil && retention > 0 {
+			metricsSnapshotRetention = time.Duration(retention) * time.Hour
+		}
+	}
+
+	// Scheduled integrity check, VACUUM and ANALYZE, off unless an interval
+	// is set
+	var maintenanceInterval time.Duration
+	if intervalStr := os.Getenv("DB_MAINTENANCE_INTERVAL_HOURS"); intervalStr != "" {
+		if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
+			maintenanceInterval = time.Duration(interval) * time.Hour
+		}
+	}
+
+	// Line movement velocity, with alerts on fast and accelerating moves
+	velocityConfig := velocity.DefaultConfig()
+	if windowStr := os.Getenv("VELOCITY_WINDOW_MINUTES"); windowStr != "" {
+		if window, err := strconv.Atoi(windowStr); err == nil && window > 0 {
+			velocityConfig.Window = time.Duration(window) * time.Minute
+		}
+	}
+	if pointsStr := os.Getenv("VELOCITY_POINTS_PER_MINUTE"); pointsStr != "" {
+		if points, err := strconv.ParseFloat(pointsStr, 64); err == nil && points > 0 {
+			velocityConfig.PointsPerMinute = points
+		}
+	}
+	if centsStr := os.Getenv("VELOCITY_CENTS_PER_MINUTE"); centsStr != "" {
+		if cents, err := strconv.ParseFloat(centsStr, 64); err == nil && cents > 0 {
+			velocityConfig.CentsPerMinute = cents
+		}
+	}
+	velocityMonitor := velocity.NewMonitor(velocityConfig, dataStore)
+	velocityMonitor.SetClock(appClock)
+	velocityMonitor.SetCallback(func(moves []velocity.Move) {
+		for _, mv := range moves {
+			rate := fmt.Sprintf("%.2f points/min", mv.Velocity)
+			if mv.Unit == velocity.Cents {
+				rate = fmt.Sprintf("%.1f cents/min", mv.Velocity)
+			}
+			books := ""
+			if mv.Books > 1 {
+				books = fmt.Sprintf(" (%d books moving)", mv.Books)
+			}
+			notificationSvc.NotifyEvent(alerts.EventAlert{
+				Type:  "line_move",
+				Kind:  "rapid_move",
+				Title: fmt.Sprintf("Fast %s move: %s @ %s", mv.Market, mv.AwayTeam, mv.HomeTeam),
+				Body: fmt.Sprintf("%s %s went %g to %g at %s in %.0f minutes, %s and accelerating%s. News may be breaking.",
+					mv.Outcome, mv.Market, mv.From, mv.To, mv.Bookmaker, velocityConfig.Window.Minutes(), rate, books),
+				Sport:  mv.Sport,
+				GameID: mv.GameID,
+			})
+		}
+	})
+
+	// Steam: the same line moving at several books within a window
+	steamConfig := steam.DefaultConfig()
+	if windowStr := os.Getenv("STEAM_WINDOW_MINUTES"); windowStr != "" {
+		if window, err := strconv.Atoi(windowStr); err == nil && window > 0 {
+			steamConfig.Window = time.Duration(window) * time.Minute
+		}
+	}
+	if pointsStr := os.Getenv("STEAM_POINTS"); pointsStr != "" {
+		if points, err := strconv.ParseFloat(pointsStr, 64); err == nil && points > 0 {
+			steamConfig.Points = points
+		}
+	}
+	if centsStr := os.Getenv("STEAM_CENTS"); centsStr != "" {
+		if cents, err := strconv.ParseFloat(centsStr, 64); err == nil && cents > 0 {
+			steamConfig.Cents = cents
+		}
+	}
+	if booksStr := os.Getenv("STEAM_MIN_BOOKS"); booksStr != "" {
+		if books, err := strconv.Atoi(booksStr); err == nil && books >= 2 {
+			steamConfig.MinBooks = books
+		}
+	}
+	if pointsStr := os.Getenv("DIVERGENCE_POINTS"); pointsStr != "" {
+		if points, err := strconv.ParseFloat(pointsStr, 64); err == nil && points > 0 {
+			steamConfig.DivergencePoints = points
+		}
+	}
+	if centsStr := os.Getenv("DIVERGENCE_CENTS"); centsStr != "" {
+		if cents, err := strconv.ParseFloat(centsStr, 64); err == nil && cents > 0 {
+			steamConfig.DivergenceCents = cents
+		}
+	}
+	// Closing line projections sent with line move and divergence alerts
+	closingConfig := closing.DefaultConfig()
+	if lookbackStr := os.Getenv("CLOSING_LOOKBACK_MINUTES"); lookbackStr != "" {
+		if lookback, err := strconv.Atoi(lookbackStr); err == nil && lookback > 0 {
+			closingConfig.Lookback = time.Duration(lookback) * time.Minute
+		}
+	}
+	if carryStr := os.Getenv("CLOSING_CARRY"); carryStr != "" {
+		if carry, err := strconv.ParseFloat(carryStr, 64); err == nil && carry > 0 && carry <= 1 {
+			closingConfig.Carry = carry
+		}
+	}
+	closingPredictor := closing.NewPredictor(closingConfig, db)
+	closingPredictor.SetClock(appClock)
+
+	steamDetector := steam.NewDetector(steamConfig, dataStore)
+	steamDetector.SetClock(appClock)
+	steamDetector.SetCallback(func(found []steam.Steam) {
+		for _, st := range found {
+			event := alerts.EventAlert{
+				Type:   "line_move",
+				Kind:   "steam",
+				Title:  fmt.Sprintf("Steam on %s: %s @ %s", st.Subject(), st.AwayTeam, st.HomeTeam),
+				Body:   st.Summary() + ". Sharp money may be coming in.",
+				Sport:  st.Sport,
+				GameID: st.GameID,
+				Player: st.Player,
+			}
+			outcome := models.Outcome{Name: st.Outcome, Description: st.Player}.Label()
+			if projection := closingPredictor.ProjectAlert(st.GameID, st.Market, outcome, st.CommenceTime); projection != nil {
+				event.ProjectedClose = projection
+				event.Body += " " + projection.Summary() + "."
+			}
+			notificationSvc.NotifyEvent(event)
+		}
+	})
+	steamDetector.SetDivergenceCallback(func(found []steam.SharpDivergence) {
+		for _, dv := range found {
+			event := alerts.EventAlert{
+				Type:   "sharp_divergence",
+				Kind:   dv.Unit,
+				Title:  fmt.Sprintf("Books lagging %s on %s: %s @ %s", dv.Sharp.Bookmaker, dv.Subject(), dv.AwayTeam, dv.HomeTeam),
+				Body:   dv.Summary() + ". The stale number may not last.",
+				Sport:  dv.Sport,
+				GameID: dv.GameID,
+				Player: dv.Player,
+			}
+			outcome := models.Outcome{Name: dv.Outcome, Description: dv.Player}.Label()
+			if projection := closingPredictor.ProjectAlert(dv.GameID, dv.Market, outcome, dv.CommenceTime); projection != nil {
+				event.ProjectedClose = projection
+				event.Body += " " + projection.Summary() + "."
+			}
+			notificationSvc.NotifyEvent(event)
+		}
+	})
+
+	// News feeds for watchlist players
+	var newsWatcher *news.Watcher
+	if feedsStr := os.Getenv("NEWS_FEEDS"); feedsStr != "" {
+		newsConfig := news.DefaultConfig()
+		for _, feed := range strings.Split(feedsStr, ",") {
+			if feed = strings.TrimSpace(feed); feed != "" {
+				newsConfig.Feeds = append(newsConfig.Feeds, feed)
+			}
+		}
+		if intervalStr := os.Getenv("NEWS_POLL_MINUTES"); intervalStr != "" {
+			if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
+				newsConfig.Interval = time.Duration(interval) * time.Minute
+			}
+		}
+		newsWatcher = news.NewWatcher(newsConfig, db)
+		newsWatcher.SetCallback(func(matches []news.Match) {
+			for _, m := range matches {
+				notificationSvc.NotifyEvent(alerts.EventAlert{
+					Type:   "news",
+					Kind:   "watchlist",
+					Title:  fmt.Sprintf("%s in the news", m.Player),
+					Body:   m.Item.Title,
+					Player: m.Player,
+					URL:    m.Item.Link,
+				})
+			}
+		})
+	}
+
+	// Bind the port before polling starts, so the service is reachable by
+	// the time it reports ready
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
+	if err != nil {
+		log.Fatalf("Failed to listen on port %s: %v", port, err)
+	}
 
-	// Start services in background
+	// PID, ready and health files and systemd notifications for running
+	// as a service
+	lc := newLifecycle(opts)
+	defer lc.Close()
+	pollingSvc.SetPollCallback(func() {
+		lc.Ready()
+		lc.WriteHealth(m.GetHealth(pollingSvc.IsEnabled()))
+	})
+
+	// Start services in background. In a cluster only the elected poller
+	// runs them; the other instances serve its odds and broadcasts.
 	ctx, cancel := context.WithCancel(context.Background())
-	go pollingSvc.Start(ctx)
-	go notificationSvc.Start(ctx)
+	if db.HasReplica() {
+		go newReplicaMonitor(db, replicaCheckInterval, m, appClock).Start(ctx)
+	}
+	go snapshotMetrics(ctx, db, m, metricsSnapshotInterval, metricsSnapshotRetention)
+	startWorkers := func() {
+		snapshotUpdates, stopSnapshots := dataStore.Watch("")
+		go writeSnapshots(ctx, snapshotUpdates, stopSnapshots, db, appClock, oddsHistoryRetention)
+		if maintenanceInterval > 0 {
+			go maintainDatabase(ctx, db, maintenanceInterval, notificationSvc)
+		}
+		go alertScanner.Start(ctx)
+		go velocityMonitor.Start(ctx)
+		go steamDetector.Start(ctx)
+		go closingPredictor.Start(ctx)
+		go recheckChecker.Start(ctx)
+		go betGrader.Start(ctx)
+		go betReminder.Start(ctx)
+		go statusTracker.Start(ctx)
+		go pollingSvc.Start(ctx)
+		go notificationSvc.Start(ctx)
+		go upstreamMonitor.Start(ctx)
+		if lineupMonitor != nil {
+			go lineupMonitor.Start(ctx)
+		}
+		if telegramBot != nil {
+			go telegramBot.Start(ctx)
+		}
+		if depthChartTracker != nil {
+			go depthChartTracker.Start(ctx)
+		}
+		if liveTracker != nil {
+			go liveTracker.Start(ctx)
+		}
+		if injuryMonitor != nil {
+			go injuryMonitor.Start(ctx)
+		}
+		if newsWatcher != nil {
+			go newsWatcher.Start(ctx)
+		}
+	}
+	// Closed when this instance stops being the poller, which shuts it down
+	// so a restart rejoins as a standby
+	leadershipLost := make(chan struct{})
+	loseLeadership := func() { close(leadershipLost) }
+	// A warm standby doesn't campaign for the lock; it waits to be
+	// activated through the admin API
+	warmStandby := os.Getenv("STANDBY") == "true"
+	if bridge != nil {
+		go bridge.Start(ctx)
+		if !warmStandby {
+			go bridge.Elect(ctx, startWorkers, loseLeadership)
+		}
+	} else {
+		startWorkers()
+	}
 
 	// Initialize HTTP handler
 	handler := api.NewHandler(
@@ -173,44 +1113,144 @@ func main() {
 		alertDetector,
 		notificationSvc,
 	)
+	handler.SetReportBuilder(reportBuilder)
+	handler.SetScanner(alertScanner)
+	handler.SetVelocityMonitor(velocityMonitor)
+	handler.SetSteamDetector(steamDetector)
+	handler.SetClosingPredictor(closingPredictor)
+	handler.SetAlertStream(alertStream)
+	handler.SetProjections(projectionBlender)
+	handler.SetSportsCatalog(sportsCatalog)
+	referenceSvc := reference.NewService(sportsDataClient)
+	referenceSvc.SetClock(appClock)
+	handler.SetReferenceService(referenceSvc)
+	if lineupMonitor != nil {
+		handler.SetLineupMonitor(lineupMonitor)
+	}
+	if depthChartTracker != nil {
+		handler.SetDepthChartTracker(depthChartTracker)
+	}
+	if liveTracker != nil {
+		handler.SetLiveProps(liveTracker)
+	}
+	handler.SetClock(appClock)
+	handler.SetAdminToken(os.Getenv("ADMIN_TOKEN"))
+	handler.SetProjectionsToken(os.Getenv("PROJECTIONS_TOKEN"))
+	if simClock != nil {
+		handler.SetSimulatedClock(simClock)
+	}
+	if faults != nil {
+		handler.SetFaultInjector(faults)
+	}
+	if bridge != nil {
+		handler.SetCluster(bridge)
+	}
+	if warmStandby && bridge != nil {
+		handler.SetStandby(func() error {
+			return bridge.TakeOver(ctx, startWorkers, loseLeadership)
+		})
+		log.Println("Standby: serving reads until activated with POST /api/v1/admin/activate")
+	}
+	// Unversioned /api/ paths are deprecated aliases of /api/v1/ until the
+	// sunset date, if one is set (checked by checkConfig)
+	if sunsetStr := os.Getenv("LEGACY_API_SUNSET"); sunsetStr != "" {
+		if sunset, err := time.Parse(api.SunsetDateFormat, sunsetStr); err == nil {
+			handler.SetLegacySunset(sunset)
+			log.Printf("API: unversioned /api/ paths retire on %s", sunsetStr)
+		}
+	}
+	// Public stats are unauthenticated, so they can be turned off
+	if enabled := os.Getenv("PUBLIC_STATS_ENABLED"); enabled != "false" {
+		handler.EnablePublicStats()
+	}
+	if spec, err := contract.OpenAPI(contract.Operations); err != nil {
+		log.Printf("OpenAPI: %v", err)
+	} else {
+		handler.SetOpenAPISpec(spec)
+	}
 
 	// Setup routes
 	mux := http.NewServeMux()
 	handler.RegisterRoutes(mux)
 
-	// Wrap with CORS middleware for development
-	corsHandler := api.CORSMiddleware(mux)
+	// Serve the frontend under / so the API is same-origin and needs no
+	// CORS; otherwise allow the Vite dev server's origin
+	var rootHandler http.Handler
+	frontendHandler, frontendSource, err := frontendFromEnv()
+	if err != nil {
+		log.Fatalf("Frontend: %v", err)
+	}
+	if frontendHandler != nil {
+		mux.Handle("/", frontendHandler)
+		rootHandler = handler.StandbyGuard(mux)
+		log.Printf("Frontend: serving %s at /", frontendSource)
+	} else {
+		rootHandler = api.CORSMiddleware(handler.StandbyGuard(mux))
+	}
 
 	// Create server
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%s", port),
-		Handler: corsHandler,
+		Handler: rootHandler,
 	}
 
 	// Start server in goroutine
 	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	// Without polling there's no first poll to wait for, and standbys in a
+	// cluster may never poll
+	if !pollingSvc.IsEnabled() || bridge != nil {
+		lc.Ready()
+		lc.WriteHealth(m.GetHealth(false))
+	}
+
+	if !opts.service {
 		fmt.Printf("LineFinder API starting on http://localhost%s\n", server.Addr)
-		fmt.Println("\nCore Endpoints:")
-		fmt.Println("  GET  /api/health           - Health check with metrics")
-		fmt.Println("  GET  /api/games/{sport}    - List games (nfl/nba)")
-		fmt.Println("  GET  /api/odds/{sport}     - Get raw odds data")
-		fmt.Println("  POST /api/refresh/{sport}  - Fetch fresh data from Odds API")
+		if opts.demo {
+			fmt.Println("Demo mode: polling built-in sample odds; lines move every poll and steam hits a game every 8 polls")
+		}
+		fmt.Println("\nCore Endpoints (unversioned /api/ paths are deprecated aliases):")
+		fmt.Println("  GET  /api/v1/health           - Health check with metrics")
+		fmt.Println("  GET  /api/v1/docs             - API docs (Swagger UI over /api/v1/openapi.json)")
+		fmt.Println("  GET  /api/v1/sports           - Sports offered upstream and enabled locally")
+		fmt.Println("  GET  /api/v1/games/{sport}    - List games (nfl/nba)")
+		fmt.Println("  POST /api/v1/games/{id}/pin   - Keep a game after it's final")
+		fmt.Println("  GET  /api/v1/games/pinned     - Pinned games with odds, props and alerts")
+		fmt.Println("  GET  /api/v1/odds/{sport}     - Get raw odds data")
+		fmt.Println("  POST /api/v1/refresh/{sport}  - Fetch fresh data from Odds API")
 		fmt.Println("\nPlayer Data Endpoints:")
-		fmt.Println("  GET  /api/props/{sport}/{id}    - Player props for a game")
-		fmt.Println("  GET  /api/injuries/{sport}/{id} - Injuries for a game")
-		fmt.Println("  GET  /api/averages/{sport}/{id} - Player averages")
+		fmt.Println("  GET  /api/v1/props/{sport}/{id}    - Player props for a game")
+		fmt.Println("  GET  /api/v1/injuries/{sport}/{id} - Injuries for a game")
+		fmt.Println("  GET  /api/v1/live/props/{id}       - Live NBA prop pace vs pre-game lines")
+		fmt.Println("  GET  /api/v1/averages/{sport}/{id} - Player averages")
 		fmt.Println("\nReal-time Endpoints:")
-		fmt.Println("  WS   /api/ws                - WebSocket for live updates")
-		fmt.Println("  GET  /api/metrics           - Detailed system metrics")
-		fmt.Println("  POST /api/polling/toggle    - Toggle polling on/off")
+		fmt.Println("  WS   /api/v1/ws                - WebSocket for live updates")
+		fmt.Println("  GET  /api/v1/metrics           - Detailed system metrics")
+		fmt.Println("  POST /api/v1/polling/toggle    - Toggle polling on/off")
+		fmt.Println("  PUT  /api/v1/polling/config    - Update retry/recovery settings")
 		fmt.Println("\nAlert & Notification Endpoints:")
-		fmt.Println("  GET  /api/alerts/check      - Check for value alerts")
-		fmt.Println("  GET  /api/preferences       - Get notification preferences")
-		fmt.Println("  PUT  /api/preferences       - Update preferences")
-		fmt.Println("  POST /api/subscribe         - Subscribe to push notifications")
-		fmt.Println("  POST /api/unsubscribe       - Unsubscribe from all notifications")
-		fmt.Println("  GET  /api/vapid-public-key  - Get VAPID public key")
+		fmt.Println("  GET  /api/v1/alerts/check      - Check for value alerts")
+		fmt.Println("  GET  /api/v1/topplays          - Best current value plays, ranked")
+		fmt.Println("  POST /api/v1/alerts/{id}/feedback - Rate an alert")
+		fmt.Println("  GET  /api/v1/reports/feedback  - Alert feedback report")
+		fmt.Println("  GET  /api/v1/preferences       - Get notification preferences")
+		fmt.Println("  PUT  /api/v1/preferences       - Update preferences")
+		fmt.Println("  POST /api/v1/preferences/preset/{name} - Apply a preferences preset")
+		fmt.Println("  POST /api/v1/subscribe         - Subscribe to push notifications")
+		fmt.Println("  POST /api/v1/unsubscribe       - Unsubscribe from all notifications")
+		fmt.Println("  GET  /api/v1/vapid-public-key  - Get VAPID public key")
+		fmt.Println("  POST /api/v1/email/summary     - Send the daily summary email now")
+		fmt.Println("  POST /api/v1/digest            - Send the morning digest now")
+		fmt.Println("  GET  /api/v1/notifications/status - Check delivery channels (admin)")
+		fmt.Println("  GET  /api/v1/notifications/log - Recent deliveries, retries and dead letters (admin)")
 		fmt.Printf("\nPolling: %v (interval: %v)\n", pollConfig.Enabled, pollConfig.Interval)
+		if len(pollConfig.PeriodMarkets) > 0 {
+			fmt.Printf("Period markets: %v (every %v)\n", pollConfig.PeriodMarkets, pollConfig.PeriodInterval)
+		}
 		fmt.Printf("Database: %s\n", dbPath)
 
 		if notifConfig.VAPIDPublicKey != "" {
@@ -218,19 +1258,45 @@ func main() {
 		} else {
 			fmt.Println("Push notifications: DISABLED (set VAPID keys to enable)")
 		}
-		fmt.Println()
-
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal(err)
+		if notifConfig.SMTP.Configured() {
+			fmt.Println("Email alerts and summary: ENABLED")
+		} else {
+			fmt.Println("Email alerts and summary: DISABLED (set SMTP_HOST and SMTP_FROM to enable)")
 		}
-	}()
+		if notifConfig.Telegram.Configured() {
+			fmt.Println("Telegram alerts and commands: ENABLED")
+		} else {
+			fmt.Println("Telegram alerts and commands: DISABLED (set TELEGRAM_BOT_TOKEN and TELEGRAM_CHAT_ID to enable)")
+		}
+		fmt.Println()
+	}
 
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	select {
+	case <-quit:
+	case <-leadershipLost:
+		// Exit non-zero once shut down, so supervisors restart it
+		exitCode = 1
+		log.Println("Lost poller leadership")
+	}
 
 	log.Println("Shutting down server...")
+	lc.Stopping()
+
+	// Move WebSocket clients off before stopping, so a deploy doesn't
+	// leave a gap in their alerts. Clients reconnect within the first half
+	// of the drain period and resume their subscriptions on another
+	// instance, while this one keeps serving them until they leave. Lost
+	// leadership skips this, as another instance is already polling.
+	if exitCode == 0 && drainPeriod > 0 && hub.ClientCount() > 0 {
+		hub.Drain(drainPeriod / 2)
+		deadline := time.Now().Add(drainPeriod)
+		for hub.ClientCount() > 0 && time.Now().Before(deadline) {
+			time.Sleep(250 * time.Millisecond)
+		}
+	}
 
 	// Cancel background services
 	cancel()
@@ -242,6 +1308,7 @@ func main() {
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
+	hub.CloseAll()
 
 	log.Println("Server stopped")
 }
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/notifications"
+)
+
+// maintainDatabase runs every maintenance operation on the interval: an
+// integrity check, VACUUM and ANALYZE. A failed integrity check is sent as
+// a system notice, since the database needs restoring from a backup.
+func maintainDatabase(ctx context.Context, db *database.DB, interval time.Duration, notificationSvc *notifications.Service) {
+	log.Printf("Database maintenance starting (interval: %v)", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := db.Maintain(database.MaintenanceOps)
+			if err != nil {
+				log.Printf("Database: maintenance failed: %v", err)
+				continue
+			}
+			log.Printf("Database: maintenance took %dms, %d to %d bytes", report.DurationMs, report.SizeBefore, report.SizeAfter)
+			if !report.Healthy() {
+				log.Printf("Database: integrity check failed: %s", strings.Join(report.Integrity, "; "))
+				notificationSvc.NotifySystem(alerts.SystemNotice{
+					Kind:  "database_integrity_failed",
+					Title: "Database integrity check failed",
+					Body: fmt.Sprintf("The scheduled integrity check found %d problems (first: %s). Restore the database from a backup.",
+						len(report.Integrity), report.Integrity[0]),
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/metrics"
+)
+
+// defaultMetricsSnapshotInterval is how often metrics counters are saved
+const defaultMetricsSnapshotInterval = 5 * time.Minute
+
+// defaultMetricsSnapshotRetention is how long metrics snapshots are kept
+const defaultMetricsSnapshotRetention = 7 * 24 * time.Hour
+
+// snapshotMetrics saves this instance's counters on the interval, so
+// /api/admin/metrics/snapshot can measure them over a window, and prunes
+// snapshots past retention. Every instance runs it, since each keeps its
+// own counters.
+func snapshotMetrics(ctx context.Context, db *database.DB, m *metrics.Metrics, interval, retention time.Duration) {
+	log.Printf("Metrics snapshots starting (interval: %v)", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	save := func() {
+		counters := m.Counters()
+		data, err := json.Marshal(counters)
+		if err != nil {
+			log.Printf("Metrics: failed to marshal counters: %v", err)
+			return
+		}
+		if err := db.SaveMetricsSnapshot(counters.Instance, data, counters.At); err != nil {
+			log.Printf("Metrics: failed to save snapshot: %v", err)
+		}
+		if _, err := db.PruneMetricsSnapshots(counters.At.Add(-retention)); err != nil {
+			log.Printf("Metrics: failed to prune snapshots: %v", err)
+		}
+	}
+
+	save()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			save()
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/projections"
+)
+
+// runProjectionsImport loads a CSV of projections or line targets into the
+// database, or with -preview prints what would be loaded
+func runProjectionsImport(args []string, db *database.DB, c clock.Clock) {
+	fs := flag.NewFlagSet("projections", flag.ExitOnError)
+	source := fs.String("source", "csv", "source name for the uploaded rows")
+	expires := fs.Duration("expires", 24*time.Hour, "how long the rows apply (0 never expires)")
+	preview := fs.Bool("preview", false, "print parsed rows without saving")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		log.Fatal("projections: usage: linefinder projections [-source name] [-expires 24h] [-preview] file.csv")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("projections: %v", err)
+	}
+	defer f.Close()
+
+	var expiresAt *time.Time
+	if *expires > 0 {
+		t := c.Now().Add(*expires)
+		expiresAt = &t
+	}
+
+	rows, rowErrors, err := projections.ParseCSV(f, *source, expiresAt)
+	if err != nil {
+		log.Fatalf("projections: %v", err)
+	}
+
+	fmt.Printf("\nParsed %d rows from %s (source %q)\n", len(rows), fs.Arg(0), *source)
+	for _, p := range rows {
+		fmt.Printf("  %-24s %-28s %-10s %6.1f", p.Player, p.Category, p.Kind, p.Projection)
+		if p.StdDev > 0 {
+			fmt.Printf(" ± %.1f", p.StdDev)
+		}
+		fmt.Println()
+	}
+	if len(rowErrors) > 0 {
+		fmt.Printf("\n%d invalid rows:\n", len(rowErrors))
+		for _, e := range rowErrors {
+			fmt.Printf("  line %d: %s\n", e.Row, e.Error)
+		}
+		os.Exit(1)
+	}
+
+	if *preview || len(rows) == 0 {
+		return
+	}
+	if err := db.SaveProjections(rows); err != nil {
+		log.Fatalf("projections: failed to save: %v", err)
+	}
+	if expiresAt != nil {
+		fmt.Printf("\nSaved %d rows, expiring %s\n", len(rows), expiresAt.Format(time.RFC3339))
+	} else {
+		fmt.Printf("\nSaved %d rows\n", len(rows))
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/metrics"
+	"github.com/joshuakim/linefinder/internal/upstream"
+)
+
+// replicaDependency names the read replica in the health endpoint's
+// dependencies
+const replicaDependency = "database_replica"
+
+// newReplicaMonitor returns a monitor that checks the read replica on the
+// interval, so reads move back to it once it recovers, and reports it in
+// health as a dependency. Every instance serves reads, so unlike the
+// upstream API checks it runs on standbys too.
+func newReplicaMonitor(db *database.DB, interval time.Duration, m *metrics.Metrics, c clock.Clock) *upstream.Monitor {
+	config := upstream.DefaultConfig()
+	config.Interval = interval
+	// Reads leave the replica on its first failed check
+	config.DownAfter = 1
+
+	mon := upstream.NewMonitor(config, m)
+	mon.SetClock(c)
+	mon.Register(replicaDependency, db.CheckReplica)
+	return mon
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/joshuakim/linefinder/internal/redact"
+	"github.com/joshuakim/linefinder/internal/systemd"
+)
+
+// runOptions are the flags for running under a service manager such as
+// systemd. They come before any subcommand, e.g.
+//
+//	linefinder --config /etc/linefinder.env bootstrap --sports nba
+type runOptions struct {
+	configFile string
+	logFile    string
+	pidFile    string
+	readyFile  string
+	healthFile string
+	service    bool
+	demo       bool
+}
+
+// parseRunOptions parses the global flags, leaving the subcommand and its
+// arguments in flag.Args()
+func parseRunOptions() runOptions {
+	var opts runOptions
+	flag.StringVar(&opts.configFile, "config", "", "load environment variables from this file (KEY=VALUE lines, like .env)")
+	flag.StringVar(&opts.logFile, "log-file", "", "append logs to this file instead of stderr; reopened on SIGHUP")
+	flag.StringVar(&opts.pidFile, "pid-file", "", "write the process ID to this file while running")
+	flag.StringVar(&opts.readyFile, "ready-file", "", "create this file once the server is listening and the first poll has completed")
+	flag.StringVar(&opts.healthFile, "health-file", "", "rewrite this file with health JSON after every poll")
+	flag.BoolVar(&opts.service, "service", false, "run as a service: no startup banner, and no log timestamps when logging to stderr (journald adds its own)")
+	flag.BoolVar(&opts.demo, "demo", false, "run against built-in sample odds that move every poll, with no API keys and a throwaway database")
+	flag.Parse()
+	return opts
+}
+
+// loadConfigFile sets environment variables from a file of KEY=VALUE lines.
+// Blank lines, # comments and a leading `export` are allowed, and values may
+// be quoted. Variables already set in the environment are left alone, so a
+// unit's Environment= settings win over the file.
+func loadConfigFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		key = strings.TrimSpace(key)
+		value, err := configValue(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// configValue unquotes a config file value, or strips a trailing comment
+// from an unquoted one
+func configValue(value string) (string, error) {
+	if strings.HasPrefix(value, `"`) {
+		end := strings.LastIndex(value, `"`)
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quote")
+		}
+		return strconv.Unquote(value[:end+1])
+	}
+	if strings.HasPrefix(value, "'") {
+		end := strings.LastIndex(value, "'")
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quote")
+		}
+		return value[1:end], nil
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// logFile is an append-only log destination that can be reopened after
+// logrotate moves it
+type logFile struct {
+	path string
+	mu   sync.Mutex
+	f    *os.File
+}
+
+// openLogFile opens path for appending, creating it and its directory
+func openLogFile(path string) (*logFile, error) {
+	l := &logFile{path: path}
+	if err := l.Reopen(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Write appends to the current file
+func (l *logFile) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Write(p)
+}
+
+// Reopen closes the file and opens path again
+func (l *logFile) Reopen() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	old := l.f
+	l.f = f
+	l.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// reopenOnHangup reopens the file whenever the process gets SIGHUP
+func (l *logFile) reopenOnHangup() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		if err := l.Reopen(); err != nil {
+			log.Printf("Failed to reopen log file %s: %v", l.path, err)
+			continue
+		}
+		log.Printf("Reopened log file %s", l.path)
+	}
+}
+
+// setupLogging points the standard logger at the log file when one is set
+func setupLogging(opts runOptions) {
+	if opts.logFile == "" {
+		if opts.service {
+			log.SetFlags(0)
+		}
+		log.SetOutput(redact.NewWriter(os.Stderr))
+		return
+	}
+
+	l, err := openLogFile(opts.logFile)
+	if err != nil {
+		log.Fatalf("Failed to open log file: %v", err)
+	}
+	log.SetOutput(redact.NewWriter(l))
+	go l.reopenOnHangup()
+}
+
+// lifecycle tells the service manager how startup and shutdown are going,
+// through the PID, ready and health files and systemd notifications
+type lifecycle struct {
+	opts      runOptions
+	readyOnce sync.Once
+}
+
+// newLifecycle writes the PID file, if one is set
+func newLifecycle(opts runOptions) *lifecycle {
+	l := &lifecycle{opts: opts}
+	if opts.pidFile != "" {
+		if err := writeFileAtomic(opts.pidFile, []byte(strconv.Itoa(os.Getpid())+"\n")); err != nil {
+			log.Fatalf("Failed to write PID file: %v", err)
+		}
+	}
+	return l
+}
+
+// Ready creates the ready file and notifies systemd, once
+func (l *lifecycle) Ready() {
+	l.readyOnce.Do(func() {
+		if l.opts.readyFile != "" {
+			if err := writeFileAtomic(l.opts.readyFile, []byte(strconv.Itoa(os.Getpid())+"\n")); err != nil {
+				log.Printf("Failed to write ready file: %v", err)
+			}
+		}
+		if sent, err := systemd.Notify(systemd.Ready); err != nil {
+			log.Printf("Failed to notify systemd: %v", err)
+		} else if sent {
+			log.Println("Notified systemd: ready")
+		}
+		log.Println("LineFinder ready")
+	})
+}
+
+// WriteHealth rewrites the health file with health as JSON
+func (l *lifecycle) WriteHealth(health interface{}) {
+	if l.opts.healthFile == "" {
+		return
+	}
+	data, err := json.MarshalIndent(health, "", "  ")
+	if err != nil {
+		log.Printf("Failed to encode health file: %v", err)
+		return
+	}
+	if err := writeFileAtomic(l.opts.healthFile, append(data, '\n')); err != nil {
+		log.Printf("Failed to write health file: %v", err)
+	}
+}
+
+// Stopping notifies systemd that shutdown has begun and removes the ready
+// and health files
+func (l *lifecycle) Stopping() {
+	if _, err := systemd.Notify(systemd.Stopping); err != nil {
+		log.Printf("Failed to notify systemd: %v", err)
+	}
+	removeFiles(l.opts.readyFile, l.opts.healthFile)
+}
+
+// Close removes the PID file once shutdown is done
+func (l *lifecycle) Close() {
+	removeFiles(l.opts.pidFile)
+}
+
+// removeFiles removes each set path, ignoring ones already gone
+func removeFiles(paths ...string) {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove %s: %v", path, err)
+		}
+	}
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames it
+// into place, so readers never see a partial file
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
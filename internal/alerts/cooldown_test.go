@@ -0,0 +1,54 @@
+package alerts
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+)
+
+func TestShouldNotifyCooldown(t *testing.T) {
+	db, err := database.New(filepath.Join(t.TempDir(), "alerts.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	fake := clock.NewFake(time.Date(2026, 1, 15, 19, 0, 0, 0, time.UTC))
+	db.SetClock(fake)
+	d := NewDetector(db)
+	d.SetClock(fake)
+
+	alert := &ValueAlert{
+		PlayerName:   "Jayson Tatum",
+		PropCategory: "points",
+		Direction:    "over",
+		GameID:       "game-1",
+		Line:         27.5,
+		Confidence:   ConfidenceHigh,
+	}
+	if ok, reason := d.ShouldNotify(alert); !ok {
+		t.Fatalf("first alert not sent: %s", reason)
+	}
+	if err := d.RecordAlert(alert); err != nil {
+		t.Fatal(err)
+	}
+
+	// High confidence alerts cool down for an hour
+	fake.Advance(30 * time.Minute)
+	if ok, reason := d.ShouldNotify(alert); ok {
+		t.Errorf("repeat sent during cooldown: %s", reason)
+	}
+	moved := *alert
+	moved.Line = 28.5
+	if ok, reason := d.ShouldNotify(&moved); !ok {
+		t.Errorf("alert whose line moved a point not sent during cooldown: %s", reason)
+	}
+
+	fake.Advance(31 * time.Minute)
+	if ok, reason := d.ShouldNotify(alert); !ok {
+		t.Errorf("repeat not sent after cooldown: %s", reason)
+	}
+}
@@ -1,33 +1,203 @@
 package alerts
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"math"
+	"sync"
 	"time"
 
+	"github.com/joshuakim/linefinder/internal/clock"
 	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
 )
 
 // Detector detects value opportunities in player props
 type Detector struct {
 	db         *database.DB
+	history    database.AlertHistoryStore // nil without a database
+	clock      clock.Clock
+	mu         sync.RWMutex
 	thresholds Thresholds
+	modes      DetectionModes // how each category's distance from the average is measured
+	prices     PriceFilters   // juice filters on the alerted side's price
+	myBook     string // bookmaker key of the user's primary sportsbook
+
+	// Bookmaker keys left out of best-odds selection
+	excludedBooks map[string]bool
+
+	// Only games starting within this window are scanned; 0 scans all
+	scanWindow time.Duration
+
+	// Sports alerts are wanted for; empty wants every sport
+	sports map[models.Sport]bool
+
+	// Spread between projection sources, in percent, above which alerts
+	// are flagged as disputed
+	disagreementPct float64
+
+	// Running A/B threshold experiment, if any
+	experiment *Experiment
 }
 
 // NewDetector creates a new alert detector
 func NewDetector(db *database.DB) *Detector {
-	return &Detector{
+	d := &Detector{
 		db:         db,
+		clock:      clock.Real{},
 		thresholds: DefaultThresholds(),
+		modes:      DefaultDetectionModes(),
+
+		disagreementPct: DefaultDisagreementPct,
 	}
+	if db != nil {
+		d.history = db
+	}
+	return d
+}
+
+// SetClock sets the clock used for detection times and cooldowns
+func (d *Detector) SetClock(c clock.Clock) {
+	d.clock = c
 }
 
 // UpdateThresholds updates the detection thresholds
 func (d *Detector) UpdateThresholds(t Thresholds) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	d.thresholds = t
 }
 
+// SetMyBook sets the user's primary sportsbook, compared against the best
+// price on each alert. Empty disables the comparison.
+func (d *Detector) SetMyBook(book string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.myBook = book
+}
+
+// SetExcludedBookmakers sets the bookmakers whose lines alerts never use.
+// Empty clears the list.
+func (d *Detector) SetExcludedBookmakers(books []string) {
+	excluded := make(map[string]bool, len(books))
+	for _, book := range books {
+		excluded[book] = true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.excludedBooks = excluded
+}
+
+// SetScanWindow limits scans to games starting within the given number of
+// hours. Zero or less scans every game.
+func (d *Detector) SetScanWindow(hours int) {
+	var window time.Duration
+	if hours > 0 {
+		window = time.Duration(hours) * time.Hour
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.scanWindow = window
+}
+
+// InScanWindow reports whether a game starting at gameTime should be
+// scanned. Games already underway are always in the window.
+func (d *Detector) InScanWindow(gameTime time.Time) bool {
+	d.mu.RLock()
+	window := d.scanWindow
+	d.mu.RUnlock()
+	if window == 0 {
+		return true
+	}
+	return !gameTime.After(d.clock.Now().Add(window))
+}
+
+// SetSports limits alerts to the given sports, by short or Odds API key.
+// Empty alerts on every sport.
+func (d *Detector) SetSports(sports []string) {
+	enabled := make(map[models.Sport]bool, len(sports))
+	for _, s := range sports {
+		if sport, ok := models.ParseSport(s); ok {
+			enabled[sport] = true
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sports = enabled
+}
+
+// SportEnabled reports whether a sport, by short or Odds API key, should be
+// scanned for alerts
+func (d *Detector) SportEnabled(sport string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if len(d.sports) == 0 {
+		return true
+	}
+	parsed, ok := models.ParseSport(sport)
+	return !ok || d.sports[parsed]
+}
+
+// SetDisagreementThreshold sets how far apart projection sources can be, in
+// percent of their mean, before an alert is flagged. Zero or less restores
+// the default.
+func (d *Detector) SetDisagreementThreshold(pct float64) {
+	if pct <= 0 {
+		pct = DefaultDisagreementPct
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.disagreementPct = pct
+}
+
+// SetDetectionModes sets how each prop category is measured
+func (d *Detector) SetDetectionModes(m DetectionModes) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.modes = m
+}
+
+// GetDetectionModes returns how each prop category is measured
+func (d *Detector) GetDetectionModes() DetectionModes {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.modes
+}
+
+// SetPriceFilters sets the minimum odds and maximum hold value alerts need
+func (d *Detector) SetPriceFilters(f PriceFilters) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.prices = f
+}
+
+// GetPriceFilters returns the minimum odds and maximum hold value alerts need
+func (d *Detector) GetPriceFilters() PriceFilters {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.prices
+}
+
+// GetThresholds returns the current detection thresholds
+func (d *Detector) GetThresholds() Thresholds {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.thresholds
+}
+
+// activeThresholds returns the thresholds that decide real alerts: the
+// experiment's active profile while one is running, otherwise the configured ones
+func (d *Detector) activeThresholds() Thresholds {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.experiment != nil {
+		return d.experiment.ActiveThresholds()
+	}
+	return d.thresholds
+}
+
 // PropData represents a single prop with its line and average
 type PropData struct {
 	PlayerName   string
@@ -38,6 +208,11 @@ type PropData struct {
 	BestOdds     float64
 	BestOddsDir  string // "over" or "under"
 	Bookmaker    string
+	Bookmakers   []models.PropBookmaker // every book's prices, for my book comparisons
+	Sources      map[string]float64     // each projection source's value, when blended
+	ProjectionSource string             // what Average came from, empty for the internal average
+	ExcludedBooks map[string]bool       // bookmaker keys left out of best-odds selection
+	StdDev       float64                // spread of the recent game log, 0 when unknown
 }
 
 // GameContext provides game context for alerts
@@ -51,12 +226,47 @@ type GameContext struct {
 
 // DetectValue checks a prop for value and returns an alert if found
 func (d *Detector) DetectValue(prop PropData, ctx GameContext) *ValueAlert {
-	threshold := d.thresholds.GetThreshold(prop.PropCategory)
+	alert := detectWithThresholds(prop, ctx, d.activeThresholds(), d.GetDetectionModes(), d.clock.Now())
+	if alert == nil {
+		return nil
+	}
+	if !d.GetPriceFilters().allows(prop, alert.Direction) {
+		return nil
+	}
+
+	d.mu.RLock()
+	book := d.myBook
+	disagreementPct := d.disagreementPct
+	if d.experiment != nil {
+		alert.Explanation.ThresholdProfile = d.experiment.Active
+	}
+	d.mu.RUnlock()
+	if book != "" {
+		alert.MyBook = myBookPrice(alert.PropCategory, alert.Direction, book, prop.Bookmakers)
+	}
+	if len(prop.Sources) > 1 {
+		alert.Sources = prop.Sources
+		alert.SourcesDisagree = SourceSpread(prop.Sources) > disagreementPct
+	}
+	return alert
+}
+
+// detectWithThresholds checks a prop against a specific threshold profile,
+// measured in the category's detection mode
+func detectWithThresholds(prop PropData, ctx GameContext, thresholds Thresholds, modes DetectionModes, now time.Time) *ValueAlert {
+	// Unmapped categories can't be matched to a threshold reliably
+	category, ok := taxonomy.Canonical(prop.PropCategory)
+	if !ok {
+		return nil
+	}
+	prop.PropCategory = category
+
+	mode, distance, threshold := modes.measure(prop, category, thresholds.GetThreshold(category))
 	diff := prop.Line - prop.Average
 	absDiff := math.Abs(diff)
 
 	// No alert if within threshold
-	if absDiff < threshold {
+	if distance < threshold {
 		return nil
 	}
 
@@ -68,11 +278,12 @@ func (d *Detector) DetectValue(prop PropData, ctx GameContext) *ValueAlert {
 	}
 
 	// Get confidence
-	confidence := GetConfidence(absDiff, threshold)
+	confidence := GetConfidence(distance, threshold)
 
 	// Create alert
 	alert := &ValueAlert{
 		ID:            fmt.Sprintf("%s-%s-%s-%s", ctx.GameID, prop.PlayerName, prop.PropCategory, direction),
+		Category:      models.CategoryPropValue,
 		PlayerName:    prop.PlayerName,
 		Team:          prop.Team,
 		Sport:         ctx.Sport,
@@ -89,8 +300,9 @@ func (d *Detector) DetectValue(prop PropData, ctx GameContext) *ValueAlert {
 		Confidence:    confidence,
 		BestOdds:      prop.BestOdds,
 		Bookmaker:     prop.Bookmaker,
-		DetectedAt:    time.Now(),
+		DetectedAt:    now,
 		ExpiresAt:     ctx.GameTime,
+		Explanation:   explain(prop, mode, threshold, absDiff, distance),
 	}
 
 	return alert
@@ -99,12 +311,12 @@ func (d *Detector) DetectValue(prop PropData, ctx GameContext) *ValueAlert {
 // ShouldNotify checks if an alert should trigger a notification
 // considering deduplication and cooldown
 func (d *Detector) ShouldNotify(alert *ValueAlert) (bool, string) {
-	if d.db == nil {
+	if d.history == nil {
 		return true, "no database configured"
 	}
 
 	// Check alert history
-	history, err := d.db.GetAlertHistory(
+	history, err := d.history.GetAlertHistory(
 		alert.PlayerName,
 		alert.PropCategory,
 		alert.Direction,
@@ -121,7 +333,7 @@ func (d *Detector) ShouldNotify(alert *ValueAlert) (bool, string) {
 	}
 
 	// Check if still in cooldown
-	if time.Now().Before(history.CooldownUntil) {
+	if d.clock.Now().Before(history.CooldownUntil) {
 		// Only re-alert if line moved significantly (>0.5 units)
 		lineDiff := math.Abs(alert.Line - history.LineValue)
 		if lineDiff < 0.5 {
@@ -135,7 +347,7 @@ func (d *Detector) ShouldNotify(alert *ValueAlert) (bool, string) {
 
 // RecordAlert saves an alert to history
 func (d *Detector) RecordAlert(alert *ValueAlert) error {
-	if d.db == nil {
+	if d.history == nil {
 		return nil
 	}
 
@@ -150,16 +362,28 @@ func (d *Detector) RecordAlert(alert *ValueAlert) error {
 		AverageValue:  alert.Average,
 		Difference:    alert.Difference,
 		Confidence:    alert.Confidence,
-		CooldownUntil: time.Now().Add(cooldownDuration),
+		CooldownUntil: d.clock.Now().Add(cooldownDuration),
+	}
+	if alertJSON, err := json.Marshal(alert); err == nil {
+		history.AlertJSON = string(alertJSON)
 	}
 
-	return d.db.SaveAlertHistory(history)
+	if err := d.history.SaveAlertHistory(history); err != nil {
+		return err
+	}
+	alert.HistoryID = history.ID
+	alert.State = history.State
+	return nil
 }
 
 // DetectAllValue processes multiple props and returns all value alerts
 func (d *Detector) DetectAllValue(props []PropData, ctx GameContext) []ValueAlert {
 	var alerts []ValueAlert
 
+	if !d.SportEnabled(ctx.Sport) {
+		return alerts
+	}
+
 	for _, prop := range props {
 		alert := d.DetectValue(prop, ctx)
 		if alert == nil {
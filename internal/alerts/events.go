@@ -0,0 +1,40 @@
+package alerts
+
+import (
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/closing"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// EventAlert is a player or game event worth acting on, such as a lineup
+// change, as opposed to a value alert on a line
+type EventAlert struct {
+	Type      string    `json:"type"`     // e.g. "lineup", "news"
+	Category  string    `json:"category"` // from the type when unset, see models.EventCategory
+	Kind      string    `json:"kind"`     // e.g. "starter_out"
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Sport     string    `json:"sport,omitempty"`
+	GameID    string    `json:"game_id,omitempty"`
+	Player    string    `json:"player,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Props are prop lines the event bears on, such as an injured
+	// player's teammates'
+	Props []models.PlayerWithProps `json:"props,omitempty"`
+
+	// ProjectedClose is where the line the event is about is projected to
+	// close, for market alerts on lines with odds history
+	ProjectedClose *closing.Projection `json:"projected_close,omitempty"`
+}
+
+// SystemNotice is an operational notice about the service itself, as
+// opposed to a value alert
+type SystemNotice struct {
+	Category string `json:"category"` // always models.CategorySystem
+	Kind     string `json:"kind"`     // e.g. "polling_recovery_entered"
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+}
@@ -0,0 +1,163 @@
+package alerts
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/database"
+)
+
+// Experiment profiles
+const (
+	ProfileA = "a"
+	ProfileB = "b"
+)
+
+// Experiment runs two threshold profiles side by side. Only the active
+// profile produces real alerts; both record what they would have fired.
+type Experiment struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	ProfileA  Thresholds `json:"profile_a"`
+	ProfileB  Thresholds `json:"profile_b"`
+	Active    string     `json:"active"`
+	StartedAt time.Time  `json:"started_at"`
+}
+
+// ActiveThresholds returns the thresholds of the notifying profile
+func (e *Experiment) ActiveThresholds() Thresholds {
+	if e.Active == ProfileB {
+		return e.ProfileB
+	}
+	return e.ProfileA
+}
+
+// StartExperiment persists and activates a new experiment, ending any running one
+func (d *Detector) StartExperiment(e *Experiment) error {
+	if e.Active != ProfileB {
+		e.Active = ProfileA
+	}
+
+	if d.db != nil {
+		profileA, _ := json.Marshal(e.ProfileA)
+		profileB, _ := json.Marshal(e.ProfileB)
+		record := &database.Experiment{
+			Name:     e.Name,
+			ProfileA: string(profileA),
+			ProfileB: string(profileB),
+			Active:   e.Active,
+		}
+		if err := d.db.StartExperiment(record); err != nil {
+			return err
+		}
+		e.ID = record.ID
+		e.StartedAt = record.StartedAt
+	} else {
+		e.StartedAt = d.clock.Now()
+	}
+
+	d.mu.Lock()
+	d.experiment = e
+	d.mu.Unlock()
+
+	log.Printf("Experiment %q started (active profile: %s)", e.Name, e.Active)
+	return nil
+}
+
+// StopExperiment ends the running experiment and returns it
+func (d *Detector) StopExperiment() (*Experiment, error) {
+	d.mu.Lock()
+	e := d.experiment
+	d.experiment = nil
+	d.mu.Unlock()
+
+	if e == nil {
+		return nil, nil
+	}
+
+	if d.db != nil {
+		if err := d.db.EndExperiment(e.ID); err != nil {
+			return e, err
+		}
+	}
+
+	log.Printf("Experiment %q stopped", e.Name)
+	return e, nil
+}
+
+// GetExperiment returns the running experiment, if any
+func (d *Detector) GetExperiment() *Experiment {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.experiment == nil {
+		return nil
+	}
+	e := *d.experiment
+	return &e
+}
+
+// LoadExperiment restores a running experiment from the database
+func (d *Detector) LoadExperiment() error {
+	if d.db == nil {
+		return nil
+	}
+
+	record, err := d.db.GetRunningExperiment()
+	if err != nil || record == nil {
+		return err
+	}
+
+	e := &Experiment{
+		ID:        record.ID,
+		Name:      record.Name,
+		Active:    record.Active,
+		StartedAt: record.StartedAt,
+	}
+	if err := json.Unmarshal([]byte(record.ProfileA), &e.ProfileA); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(record.ProfileB), &e.ProfileB); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.experiment = e
+	d.mu.Unlock()
+
+	log.Printf("Experiment %q resumed (active profile: %s)", e.Name, e.Active)
+	return nil
+}
+
+// ObserveExperiment evaluates a prop against both experiment profiles and
+// records which would have fired. It never notifies.
+func (d *Detector) ObserveExperiment(prop PropData, ctx GameContext) {
+	e := d.GetExperiment()
+	if e == nil || d.db == nil {
+		return
+	}
+
+	profiles := map[string]Thresholds{ProfileA: e.ProfileA, ProfileB: e.ProfileB}
+	for profile, thresholds := range profiles {
+		alert := detectWithThresholds(prop, ctx, thresholds, d.GetDetectionModes(), d.clock.Now())
+		if alert == nil {
+			continue
+		}
+
+		err := d.db.RecordExperimentResult(&database.ExperimentResult{
+			ExperimentID: e.ID,
+			Profile:      profile,
+			GameID:       alert.GameID,
+			PlayerName:   alert.PlayerName,
+			PropCategory: alert.PropCategory,
+			Direction:    alert.Direction,
+			Line:         alert.Line,
+			Average:      alert.Average,
+			Difference:   alert.Difference,
+			Confidence:   alert.Confidence,
+		})
+		if err != nil {
+			log.Printf("Error recording experiment result: %v", err)
+		}
+	}
+}
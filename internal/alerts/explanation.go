@@ -0,0 +1,98 @@
+package alerts
+
+import (
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/projections"
+)
+
+// Confidence cutoffs, as multiples of the threshold
+const (
+	HighConfidenceRatio   = 2.0
+	MediumConfidenceRatio = 1.5
+)
+
+// Explanation records why an alert fired: what the line was compared
+// against, the threshold it cleared, how confidence was graded and which
+// books were priced
+type Explanation struct {
+	// "average" for the internal last-5 average, "blend" for a weighted
+	// blend of sources, otherwise the projection or target source used
+	ProjectionSource string `json:"projection_source"`
+
+	// The detection mode the line was measured in, and the threshold in
+	// that mode's units: stat units, percent of the average or standard
+	// deviations
+	Mode             string  `json:"mode"`
+	Threshold        float64 `json:"threshold"`
+	ThresholdProfile string  `json:"threshold_profile,omitempty"` // experiment profile, while one is running
+
+	Confidence ConfidenceInputs `json:"confidence"`
+
+	Books []BookConsidered `json:"books"`
+}
+
+// ConfidenceInputs are the values GetConfidence graded the alert from
+type ConfidenceInputs struct {
+	AbsDifference float64 `json:"abs_difference"`
+	Distance      float64 `json:"distance"` // abs_difference in the mode's units
+	Ratio         float64 `json:"ratio"`    // distance / threshold
+	MediumRatio   float64 `json:"medium_ratio"`
+	HighRatio     float64 `json:"high_ratio"`
+}
+
+// BookConsidered is one sportsbook's prices for the prop. Selected marks
+// the book whose line the alert uses; Excluded books are never selected.
+type BookConsidered struct {
+	Key        string  `json:"key"`
+	Bookmaker  string  `json:"bookmaker"`
+	Line       float64 `json:"line"`
+	OverPrice  float64 `json:"over_price"`
+	UnderPrice float64 `json:"under_price"`
+	Selected   bool    `json:"selected,omitempty"`
+	Excluded   bool    `json:"excluded,omitempty"`
+}
+
+// explain builds the explanation for an alert on prop
+func explain(prop PropData, mode string, threshold, absDiff, distance float64) *Explanation {
+	source := prop.ProjectionSource
+	if source == "" {
+		source = projections.SourceAverage
+	}
+
+	books := make([]BookConsidered, 0, len(prop.Bookmakers))
+	for _, bm := range prop.Bookmakers {
+		books = append(books, BookConsidered{
+			Key:        bm.Key,
+			Bookmaker:  bm.Title,
+			Line:       bm.Point,
+			OverPrice:  bm.OverPrice,
+			UnderPrice: bm.UnderPrice,
+			Selected:   isSelected(bm, prop),
+			Excluded:   prop.ExcludedBooks[bm.Key],
+		})
+	}
+
+	inputs := ConfidenceInputs{
+		AbsDifference: absDiff,
+		Distance:      distance,
+		MediumRatio:   MediumConfidenceRatio,
+		HighRatio:     HighConfidenceRatio,
+	}
+	// A zero threshold would make the ratio infinite, which JSON can't encode
+	if threshold > 0 {
+		inputs.Ratio = distance / threshold
+	}
+
+	return &Explanation{
+		ProjectionSource: source,
+		Mode:             mode,
+		Threshold:        threshold,
+		Confidence:       inputs,
+		Books:            books,
+	}
+}
+
+// isSelected reports whether bm is the book the prop's line was taken from
+func isSelected(bm models.PropBookmaker, prop PropData) bool {
+	return !prop.ExcludedBooks[bm.Key] && bm.Title == prop.Bookmaker && bm.Point == prop.Line && bm.OverPrice == prop.BestOdds
+}
@@ -0,0 +1,63 @@
+package alerts
+
+import (
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// PriceFilters skip value alerts priced away by juice. A zero field turns
+// its filter off.
+type PriceFilters struct {
+	// MinOdds is the worst American price accepted on the alerted side,
+	// e.g. -150 skips -160 but keeps -140 and any plus price
+	MinOdds float64 `json:"min_odds"`
+
+	// MaxHoldPercent is the highest two-way hold, in percent, accepted at
+	// the alert's book
+	MaxHoldPercent float64 `json:"max_hold_percent"`
+}
+
+// PriceFiltersFromPreferences reads the price filters from stored preferences
+func PriceFiltersFromPreferences(p *database.Preferences) PriceFilters {
+	return PriceFilters{MinOdds: p.MinOdds, MaxHoldPercent: p.MaxHoldPercent}
+}
+
+// ValidMinOdds reports whether odds is 0 or an American price
+func ValidMinOdds(odds float64) bool {
+	return odds == 0 || odds <= -100 || odds >= 100
+}
+
+// allows reports whether a prop's price on one side passes the filters. The
+// price is the alert's book at the alert's line; props without it, or
+// without both sides for the hold, pass the filters they can't be held to.
+func (f PriceFilters) allows(prop PropData, direction string) bool {
+	if f.MinOdds == 0 && f.MaxHoldPercent == 0 {
+		return true
+	}
+
+	var over, under float64
+	for _, bm := range prop.Bookmakers {
+		if bm.Title == prop.Bookmaker && bm.Point == prop.Line {
+			over, under = bm.OverPrice, bm.UnderPrice
+			break
+		}
+	}
+	if over == 0 && direction == DirectionOver {
+		over = prop.BestOdds
+	}
+
+	price := over
+	if direction == DirectionUnder {
+		price = under
+	}
+	if f.MinOdds != 0 && price != 0 && models.ImpliedProbability(price) > models.ImpliedProbability(f.MinOdds) {
+		return false
+	}
+	if f.MaxHoldPercent > 0 && over != 0 && under != 0 {
+		hold := (models.ImpliedProbability(over) + models.ImpliedProbability(under) - 1) * 100
+		if hold > f.MaxHoldPercent {
+			return false
+		}
+	}
+	return true
+}
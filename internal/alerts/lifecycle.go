@@ -0,0 +1,142 @@
+package alerts
+
+import (
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/database"
+)
+
+// edgeTolerance is how far an alert's edge can drift from when it was
+// notified before it counts as improved or degraded
+const edgeTolerance = 0.1
+
+// StateChange is a stored alert moving to a new lifecycle state
+type StateChange struct {
+	AlertID      int64     `json:"alert_id"`
+	GameID       string    `json:"game_id"`
+	PlayerName   string    `json:"player_name"`
+	PropCategory string    `json:"prop_category"`
+	Direction    string    `json:"direction"`
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	Reason       string    `json:"reason,omitempty"`
+	Line         *float64  `json:"line,omitempty"`
+	Confidence   string    `json:"confidence,omitempty"`
+	ChangedAt    time.Time `json:"changed_at"`
+}
+
+// UpdateStates moves a game's stored alerts between active, improved,
+// degraded and expired as its lines move, comparing each alert's edge now
+// against the edge it was notified with. Expired alerts come back when their
+// edge returns before the game starts. Converted alerts are left alone.
+func (d *Detector) UpdateStates(props []PropData, ctx GameContext) []StateChange {
+	if d.db == nil {
+		return nil
+	}
+
+	tracked, err := d.db.GetTrackedAlerts(ctx.GameID)
+	if err != nil {
+		log.Printf("Error getting tracked alerts for %s: %v", ctx.GameID, err)
+		return nil
+	}
+	if len(tracked) == 0 {
+		return nil
+	}
+
+	byProp := make(map[string]PropData, len(props))
+	for _, prop := range props {
+		byProp[strings.ToLower(prop.PlayerName)+"|"+prop.PropCategory] = prop
+	}
+
+	started := !d.clock.Now().Before(ctx.GameTime)
+	var changes []StateChange
+	for _, h := range tracked {
+		state, reason := database.AlertStateExpired, "game started"
+		var line *float64
+		var confidence string
+
+		if !started {
+			prop, ok := byProp[strings.ToLower(h.PlayerName)+"|"+h.PropCategory]
+			reason = "no longer offered"
+			if ok {
+				line = &prop.Line
+				state, reason, confidence = d.evaluateState(h, prop, ctx)
+			}
+		}
+		if state == h.State {
+			continue
+		}
+
+		change, err := d.transition(h, state, reason, line, confidence)
+		if err != nil {
+			log.Printf("Error saving state for alert %d: %v", h.ID, err)
+			continue
+		}
+		changes = append(changes, *change)
+	}
+	return changes
+}
+
+// evaluateState grades a tracked alert against the prop's latest line
+func (d *Detector) evaluateState(h database.AlertHistory, prop PropData, ctx GameContext) (string, string, string) {
+	alert := detectWithThresholds(prop, ctx, d.activeThresholds(), d.GetDetectionModes(), d.clock.Now())
+	if alert == nil || alert.Direction != h.Direction {
+		return database.AlertStateExpired, "edge gone", ""
+	}
+
+	edge := alert.AbsDifference - math.Abs(h.Difference)
+	switch {
+	case edge > edgeTolerance:
+		return database.AlertStateImproved, "edge grew", alert.Confidence
+	case edge < -edgeTolerance:
+		return database.AlertStateDegraded, "edge shrank", alert.Confidence
+	default:
+		return database.AlertStateActive, "edge back to notified level", alert.Confidence
+	}
+}
+
+// MarkConverted moves a stored alert to converted once a bet is logged on it.
+// It returns nil when the alert was already converted.
+func (d *Detector) MarkConverted(h *database.AlertHistory) (*StateChange, error) {
+	if d.db == nil || h.State == database.AlertStateConverted {
+		return nil, nil
+	}
+	return d.transition(*h, database.AlertStateConverted, "bet logged", nil, "")
+}
+
+// transition saves a state change for a stored alert
+func (d *Detector) transition(h database.AlertHistory, to, reason string, line *float64, confidence string) (*StateChange, error) {
+	from := h.State
+	if from == "" {
+		from = database.AlertStateActive
+	}
+
+	t := &database.AlertTransition{
+		AlertID:    h.ID,
+		From:       from,
+		To:         to,
+		Reason:     reason,
+		LineValue:  line,
+		Confidence: confidence,
+	}
+	if err := d.db.SaveAlertTransition(t); err != nil {
+		return nil, err
+	}
+
+	return &StateChange{
+		AlertID:      h.ID,
+		GameID:       h.GameID,
+		PlayerName:   h.PlayerName,
+		PropCategory: h.PropCategory,
+		Direction:    h.Direction,
+		From:         from,
+		To:           to,
+		Reason:       reason,
+		Line:         line,
+		Confidence:   confidence,
+		ChangedAt:    t.CreatedAt,
+	}, nil
+}
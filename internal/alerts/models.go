@@ -1,7 +1,12 @@
 package alerts
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
 )
 
 // Confidence levels for alerts
@@ -17,25 +22,27 @@ const (
 	DirectionUnder = "under"
 )
 
-// PropCategory standard names
+// PropCategory standard names, from the category taxonomy
 const (
-	PropPoints      = "Points"
-	PropRebounds    = "Rebounds"
-	PropAssists     = "Assists"
-	PropThrees      = "Threes"
-	PropSteals      = "Steals"
-	PropBlocks      = "Blocks"
-	PropTurnovers   = "Turnovers"
-	PropPRA         = "Pts+Reb+Ast"
-	PropPR          = "Pts+Reb"
-	PropPA          = "Pts+Ast"
-	PropRA          = "Reb+Ast"
+	PropPoints      = taxonomy.Points
+	PropRebounds    = taxonomy.Rebounds
+	PropAssists     = taxonomy.Assists
+	PropThrees      = taxonomy.Threes
+	PropSteals      = taxonomy.Steals
+	PropBlocks      = taxonomy.Blocks
+	PropTurnovers   = taxonomy.Turnovers
+	PropPRA         = taxonomy.PRA
+	PropPR          = taxonomy.PR
+	PropPA          = taxonomy.PA
+	PropRA          = taxonomy.RA
 )
 
 // ValueAlert represents a detected value opportunity
 type ValueAlert struct {
 	// Identification
 	ID           string `json:"id"`
+	HistoryID    int64  `json:"history_id,omitempty"` // alert_history row, used by the alert API
+	Category     string `json:"category"`            // always models.CategoryPropValue
 	PlayerName   string `json:"player_name"`
 	Team         string `json:"team"`
 	Sport        string `json:"sport"`
@@ -55,10 +62,23 @@ type ValueAlert struct {
 	Direction  string `json:"direction"`
 	Confidence string `json:"confidence"`
 
+	// Lifecycle state once recorded, see database.AlertStateActive
+	State string `json:"state,omitempty"`
+
 	// Best available odds
 	BestOdds   float64 `json:"best_odds"`
 	Bookmaker  string  `json:"bookmaker"`
 
+	// The user's primary sportsbook vs the best price, when set
+	MyBook *models.MyBookPrice `json:"my_book,omitempty"`
+
+	// Projection sources blended into Average, flagged when they disagree
+	Sources         map[string]float64 `json:"sources,omitempty"`
+	SourcesDisagree bool               `json:"sources_disagree,omitempty"`
+
+	// Why the alert fired
+	Explanation *Explanation `json:"explanation,omitempty"`
+
 	// Timing
 	DetectedAt time.Time `json:"detected_at"`
 	ExpiresAt  time.Time `json:"expires_at"` // Game start time
@@ -92,9 +112,10 @@ func DefaultThresholds() Thresholds {
 	}
 }
 
-// GetThreshold returns the threshold for a given prop category
+// GetThreshold returns the threshold for a given prop category.
+// Aliases and market keys resolve to their canonical category.
 func (t Thresholds) GetThreshold(category string) float64 {
-	switch category {
+	switch taxonomy.Normalize(category) {
 	case PropPoints:
 		return t.Points
 	case PropRebounds:
@@ -108,6 +129,57 @@ func (t Thresholds) GetThreshold(category string) float64 {
 	}
 }
 
+// HasOwnThreshold reports whether a prop category has a threshold of its
+// own rather than using the default
+func HasOwnThreshold(category string) bool {
+	switch taxonomy.Normalize(category) {
+	case PropPoints, PropRebounds, PropAssists, PropThrees:
+		return true
+	}
+	return false
+}
+
+// SetThreshold sets the threshold for a prop category with its own
+// threshold, or the default for "default". Other categories share the
+// default, so setting one is an error rather than changing them all.
+func (t *Thresholds) SetThreshold(category string, value float64) error {
+	switch taxonomy.Normalize(category) {
+	case PropPoints:
+		t.Points = value
+	case PropRebounds:
+		t.Rebounds = value
+	case PropAssists:
+		t.Assists = value
+	case PropThrees:
+		t.Threes = value
+	case "default":
+		t.Default = value
+	default:
+		return fmt.Errorf("no threshold of its own for category %q", category)
+	}
+	return nil
+}
+
+// ThresholdsFromPreferences reads thresholds from stored preferences
+func ThresholdsFromPreferences(p *database.Preferences) Thresholds {
+	return Thresholds{
+		Points:   p.ThresholdPoints,
+		Rebounds: p.ThresholdRebounds,
+		Assists:  p.ThresholdAssists,
+		Threes:   p.ThresholdThrees,
+		Default:  p.ThresholdDefault,
+	}
+}
+
+// ApplyToPreferences writes thresholds back into preferences
+func (t Thresholds) ApplyToPreferences(p *database.Preferences) {
+	p.ThresholdPoints = t.Points
+	p.ThresholdRebounds = t.Rebounds
+	p.ThresholdAssists = t.Assists
+	p.ThresholdThrees = t.Threes
+	p.ThresholdDefault = t.Default
+}
+
 // CooldownDurations for different confidence levels
 var CooldownDurations = map[string]time.Duration{
 	ConfidenceLow:    4 * time.Hour,
@@ -128,11 +200,30 @@ func GetConfidence(absDiff float64, threshold float64) string {
 	ratio := absDiff / threshold
 
 	switch {
-	case ratio >= 2.0: // 2x threshold or more
+	case ratio >= HighConfidenceRatio: // 2x threshold or more
 		return ConfidenceHigh
-	case ratio >= 1.5: // 1.5x threshold
+	case ratio >= MediumConfidenceRatio: // 1.5x threshold
 		return ConfidenceMedium
 	default:
 		return ConfidenceLow
 	}
 }
+
+// confidenceRank orders the confidence levels
+var confidenceRank = map[string]int{
+	ConfidenceLow:    1,
+	ConfidenceMedium: 2,
+	ConfidenceHigh:   3,
+}
+
+// ValidConfidence reports whether c is a confidence level
+func ValidConfidence(c string) bool {
+	_, ok := confidenceRank[c]
+	return ok
+}
+
+// MeetsConfidence reports whether confidence c is at least min. An empty
+// min lets everything through.
+func MeetsConfidence(c, min string) bool {
+	return min == "" || confidenceRank[c] >= confidenceRank[min]
+}
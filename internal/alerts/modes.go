@@ -0,0 +1,99 @@
+package alerts
+
+import (
+	"math"
+
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
+)
+
+// Detection modes: how a line's distance from the average is measured
+const (
+	// ModeAbsolute compares the difference in stat units against the
+	// category's threshold
+	ModeAbsolute = "absolute"
+
+	// ModePercent compares the difference as a percent of the average
+	ModePercent = "percent"
+
+	// ModeZScore compares the difference in standard deviations of the
+	// player's recent game log
+	ModeZScore = "zscore"
+)
+
+// DefaultModeKey sets the mode for categories without one of their own
+const DefaultModeKey = "default"
+
+// Default thresholds for the percent and z-score modes
+const (
+	DefaultPercentThreshold = 15.0
+	DefaultZScoreThreshold  = 1.0
+)
+
+// DetectionModes picks how each prop category is measured. Categories
+// without a mode, or whose mode lacks the data it needs (a zero average,
+// or no game log spread), use the absolute thresholds.
+type DetectionModes struct {
+	Modes      map[string]string `json:"modes,omitempty"` // canonical category or "default" -> mode
+	PercentPct float64           `json:"percent_pct"`
+	ZScore     float64           `json:"zscore"`
+}
+
+// DefaultDetectionModes measures every category in absolute terms
+func DefaultDetectionModes() DetectionModes {
+	return DetectionModes{
+		PercentPct: DefaultPercentThreshold,
+		ZScore:     DefaultZScoreThreshold,
+	}
+}
+
+// ValidDetectionMode reports whether mode is a detection mode
+func ValidDetectionMode(mode string) bool {
+	return mode == ModeAbsolute || mode == ModePercent || mode == ModeZScore
+}
+
+// DetectionModesFromPreferences reads detection modes from stored
+// preferences, with defaults for unset thresholds
+func DetectionModesFromPreferences(p *database.Preferences) DetectionModes {
+	m := DetectionModes{
+		Modes:      p.DetectionModes,
+		PercentPct: p.DetectionPercentPct,
+		ZScore:     p.DetectionZScore,
+	}
+	if m.PercentPct <= 0 {
+		m.PercentPct = DefaultPercentThreshold
+	}
+	if m.ZScore <= 0 {
+		m.ZScore = DefaultZScoreThreshold
+	}
+	return m
+}
+
+// Mode returns the detection mode configured for a category
+func (m DetectionModes) Mode(category string) string {
+	if mode, ok := m.Modes[taxonomy.Normalize(category)]; ok {
+		return mode
+	}
+	if mode, ok := m.Modes[DefaultModeKey]; ok {
+		return mode
+	}
+	return ModeAbsolute
+}
+
+// measure returns the mode a prop is measured in, its distance from the
+// average in that mode's units and the threshold the distance must reach.
+// Modes missing the data they need fall back to absolute.
+func (m DetectionModes) measure(prop PropData, category string, absThreshold float64) (string, float64, float64) {
+	absDiff := math.Abs(prop.Line - prop.Average)
+	switch m.Mode(category) {
+	case ModePercent:
+		if prop.Average != 0 {
+			return ModePercent, absDiff / math.Abs(prop.Average) * 100, m.PercentPct
+		}
+	case ModeZScore:
+		if prop.StdDev > 0 {
+			return ModeZScore, absDiff / prop.StdDev, m.ZScore
+		}
+	}
+	return ModeAbsolute, absDiff, absThreshold
+}
@@ -0,0 +1,97 @@
+package alerts
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// Weights of the parts of a play's score. They sum to 1, so scores run
+// from 0 to 100.
+const (
+	EdgeWeight       = 0.40
+	ConfidenceWeight = 0.25
+	OddsWeight       = 0.20
+	TimingWeight     = 0.15
+)
+
+// PlayScore is how a value alert ranks among the current plays. Each part
+// runs from 0 to 1; Score is their weighted sum out of 100.
+type PlayScore struct {
+	Score      float64 `json:"score"`
+	Edge       float64 `json:"edge"`       // 1x the threshold is 0, 3x or more is 1
+	Confidence float64 `json:"confidence"` // low, medium, high
+	Odds       float64 `json:"odds"`       // -150 or shorter is 0, +150 or longer is 1
+	Timing     float64 `json:"timing"`     // starting within 2 hours is 1, 48 or more is 0
+}
+
+// Edge is how far an alert clears its threshold, as a multiple of it, so
+// alerts in different categories compare
+func Edge(a *ValueAlert) float64 {
+	if a.Explanation != nil && a.Explanation.Confidence.Ratio > 0 {
+		return a.Explanation.Confidence.Ratio
+	}
+	return a.AbsDifference
+}
+
+// ScorePlay scores a value alert on its edge, confidence, the price of its
+// best odds and how soon its game starts
+func ScorePlay(a *ValueAlert, now time.Time) PlayScore {
+	score := PlayScore{
+		Edge:       part((Edge(a) - 1) / 2),
+		Confidence: part(float64(confidenceRank[a.Confidence]) / float64(confidenceRank[ConfidenceHigh])),
+		Odds:       oddsQuality(a.BestOdds),
+		Timing:     part(1 - (a.ExpiresAt.Sub(now).Hours()-2)/46),
+	}
+	weighted := EdgeWeight*score.Edge +
+		ConfidenceWeight*score.Confidence +
+		OddsWeight*score.Odds +
+		TimingWeight*score.Timing
+	score.Score = math.Round(weighted*1000) / 10
+	return score
+}
+
+// RankedPlay is a value alert with its score
+type RankedPlay struct {
+	Rank  int        `json:"rank"`
+	Score PlayScore  `json:"score"`
+	Alert ValueAlert `json:"alert"`
+}
+
+// RankPlays scores the alerts and orders them best first, breaking ties by
+// the bigger edge. limit caps the result when positive.
+func RankPlays(found []ValueAlert, now time.Time, limit int) []RankedPlay {
+	plays := make([]RankedPlay, len(found))
+	for i := range found {
+		plays[i] = RankedPlay{Score: ScorePlay(&found[i], now), Alert: found[i]}
+	}
+	sort.SliceStable(plays, func(i, j int) bool {
+		if plays[i].Score.Score != plays[j].Score.Score {
+			return plays[i].Score.Score > plays[j].Score.Score
+		}
+		return Edge(&plays[i].Alert) > Edge(&plays[j].Alert)
+	})
+	if limit > 0 && len(plays) > limit {
+		plays = plays[:limit]
+	}
+	for i := range plays {
+		plays[i].Rank = i + 1
+	}
+	return plays
+}
+
+// oddsQuality scores a price by what it pays: the lower its implied
+// probability, the better. Alerts without a price count as -110.
+func oddsQuality(price float64) float64 {
+	if price == 0 {
+		price = -110
+	}
+	return part((0.6 - models.ImpliedProbability(price)) / 0.2)
+}
+
+// part clamps a score part to 0-1, to two decimals
+func part(v float64) float64 {
+	return math.Round(math.Max(0, math.Min(1, v))*100) / 100
+}
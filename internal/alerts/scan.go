@@ -0,0 +1,184 @@
+package alerts
+
+import (
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/metrics"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
+)
+
+// CollectProps pairs each player prop with the player's average for the same
+// canonical category and picks the best available line. Props whose player has
+// no averages, or whose category isn't in the taxonomy, are skipped and
+// counted in stats when it's non-nil.
+func CollectProps(props *models.GamePlayerProps, averages []store.PlayerAverages, stats *metrics.AlertScanStats) []PropData {
+	return collectProps(props, averages, nil, stats)
+}
+
+// CollectProps is the package-level CollectProps with the user's excluded
+// bookmakers left out of best-odds selection. Their prices stay in each
+// prop's Bookmakers for comparisons.
+func (d *Detector) CollectProps(props *models.GamePlayerProps, averages []store.PlayerAverages, stats *metrics.AlertScanStats) []PropData {
+	d.mu.RLock()
+	excluded := d.excludedBooks
+	d.mu.RUnlock()
+	return collectProps(props, averages, excluded, stats)
+}
+
+// collectProps pairs props with averages, picking the best line among
+// bookmakers not in excluded. Props only excluded books offer are skipped.
+func collectProps(props *models.GamePlayerProps, averages []store.PlayerAverages, excluded map[string]bool, stats *metrics.AlertScanStats) []PropData {
+	if stats == nil {
+		stats = &metrics.AlertScanStats{}
+	}
+
+	// Index averages by player and canonical category
+	avgMap := make(map[string]map[string]float64)
+	sourceMap := make(map[string]map[string]map[string]float64)
+	basisMap := make(map[string]map[string]string)
+	spreadMap := make(map[string]map[string]float64)
+	for _, pa := range averages {
+		byCategory := make(map[string]float64)
+		for category, avg := range pa.Averages {
+			byCategory[taxonomy.Normalize(category)] = avg
+		}
+		avgMap[strings.ToLower(pa.Name)] = byCategory
+		if len(pa.Sources) > 0 {
+			sources := make(map[string]map[string]float64)
+			for category, values := range pa.Sources {
+				sources[taxonomy.Normalize(category)] = values
+			}
+			sourceMap[strings.ToLower(pa.Name)] = sources
+		}
+		if len(pa.ProjectionSource) > 0 {
+			basis := make(map[string]string)
+			for category, source := range pa.ProjectionSource {
+				basis[taxonomy.Normalize(category)] = source
+			}
+			basisMap[strings.ToLower(pa.Name)] = basis
+		}
+		if len(pa.StdDevs) > 0 {
+			spreads := make(map[string]float64)
+			for category, sd := range pa.StdDevs {
+				spreads[taxonomy.Normalize(category)] = sd
+			}
+			spreadMap[strings.ToLower(pa.Name)] = spreads
+		}
+	}
+
+	var result []PropData
+	for _, player := range props.Players {
+		playerAvg := avgMap[strings.ToLower(player.Name)]
+		if playerAvg == nil {
+			stats.PropsScanned += len(player.Props)
+			stats.SkippedMissingPlayer += len(player.Props)
+			stats.AddMissingPlayer(player.Name)
+			continue
+		}
+
+		for _, prop := range player.Props {
+			stats.PropsScanned++
+
+			category, ok := taxonomy.ForProp(prop.Category, prop.Market)
+			if !ok {
+				stats.SkippedUnmappedCategory++
+				stats.AddUnmappedCategory(prop.Category)
+				continue
+			}
+
+			avg, ok := playerAvg[category]
+			if !ok {
+				stats.SkippedMissingAverage++
+				continue
+			}
+
+			// Find best odds
+			var bestLine, bestOdds float64
+			var bestBook string
+			for _, bm := range prop.Bookmakers {
+				if excluded[bm.Key] {
+					continue
+				}
+				if bestBook == "" || bm.OverPrice > bestOdds {
+					bestLine = bm.Point
+					bestOdds = bm.OverPrice
+					bestBook = bm.Title
+				}
+			}
+			if bestBook == "" && len(prop.Bookmakers) > 0 {
+				stats.SkippedExcludedBooks++
+				continue
+			}
+
+			stats.PropsMatched++
+
+			result = append(result, PropData{
+				PlayerName:   player.Name,
+				Team:         player.Team,
+				PropCategory: category,
+				Line:         bestLine,
+				Average:      avg,
+				BestOdds:     bestOdds,
+				Bookmaker:    bestBook,
+				Bookmakers:   prop.Bookmakers,
+				Sources:      sourceMap[strings.ToLower(player.Name)][category],
+
+				ProjectionSource: basisMap[strings.ToLower(player.Name)][category],
+				ExcludedBooks:    excluded,
+				StdDev:           spreadMap[strings.ToLower(player.Name)][category],
+			})
+		}
+	}
+	return result
+}
+
+// myBookPrice compares the user's book to the best price on one side of a
+// prop, or returns nil when the book doesn't offer it
+func myBookPrice(category, direction, book string, bookmakers []models.PropBookmaker) *models.MyBookPrice {
+	price := func(bm models.PropBookmaker) float64 {
+		if direction == DirectionUnder {
+			return bm.UnderPrice
+		}
+		return bm.OverPrice
+	}
+
+	var mine, best *models.PropBookmaker
+	for i := range bookmakers {
+		bm := &bookmakers[i]
+		if price(*bm) == 0 {
+			continue
+		}
+		if bm.Key == book {
+			mine = bm
+		}
+		if best == nil || price(*bm) > price(*best) {
+			best = bm
+		}
+	}
+	if mine == nil {
+		return nil
+	}
+
+	// Lower lines are better for overs, higher for unders
+	points := mine.Point - best.Point
+	outcome := "Over"
+	if direction == DirectionUnder {
+		points = best.Point - mine.Point
+		outcome = "Under"
+	}
+	myPoint, bestPoint := mine.Point, best.Point
+	return &models.MyBookPrice{
+		Market:        category,
+		Outcome:       outcome,
+		Bookmaker:     mine.Title,
+		Price:         price(*mine),
+		Point:         &myPoint,
+		BestBookmaker: best.Title,
+		BestPrice:     price(*best),
+		BestPoint:     &bestPoint,
+		CentsGivenUp:  models.PriceCents(price(*best), price(*mine)),
+		PointsGivenUp: points,
+	}
+}
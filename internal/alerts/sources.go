@@ -0,0 +1,28 @@
+package alerts
+
+import "math"
+
+// DefaultDisagreementPct is the spread between projection sources, in
+// percent of their mean, above which alerts are flagged
+const DefaultDisagreementPct = 15.0
+
+// SourceSpread returns the gap between the highest and lowest source values
+// as a percentage of their mean
+func SourceSpread(values map[string]float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	var sum float64
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if mean == 0 {
+		return 0
+	}
+	return (hi - lo) / math.Abs(mean) * 100
+}
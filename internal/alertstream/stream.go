@@ -0,0 +1,189 @@
+package alertstream
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// Alert types besides event types, which are passed through as they are
+// (e.g. "line_move", "lineup", "news")
+const (
+	TypeValue   = "value"
+	TypeEV      = "ev"
+	TypeOutlier = "outlier"
+)
+
+// subscriberBuffer is how many alerts a subscriber can fall behind before
+// new ones are dropped for it
+const subscriberBuffer = 64
+
+// Alert is any kind of alert in one shape, with the original alert in Data
+type Alert struct {
+	Type      string      `json:"type"`
+	Category  string      `json:"category"` // see models.AlertCategories
+	Kind      string      `json:"kind,omitempty"`
+	Sport     string      `json:"sport,omitempty"`
+	GameID    string      `json:"game_id,omitempty"`
+	Player    string      `json:"player,omitempty"`
+	Title     string      `json:"title"`
+	Body      string      `json:"body,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Filter picks the alerts a subscriber wants. Empty lists match everything;
+// alerts without a sport only match when Sports is empty.
+type Filter struct {
+	Types      []string `json:"types,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	Sports     []string `json:"sports,omitempty"`
+}
+
+// NewFilter builds a filter from alert types, categories and sports,
+// accepting sports by short or Odds API key
+func NewFilter(types, categories, sports []string) Filter {
+	var f Filter
+	for _, t := range types {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			f.Types = append(f.Types, t)
+		}
+	}
+	for _, c := range categories {
+		if c = strings.ToLower(strings.TrimSpace(c)); c != "" {
+			f.Categories = append(f.Categories, c)
+		}
+	}
+	for _, s := range sports {
+		if s = normalizeSport(s); s != "" {
+			f.Sports = append(f.Sports, s)
+		}
+	}
+	return f
+}
+
+// Match reports whether an alert passes the filter
+func (f Filter) Match(a Alert) bool {
+	if len(f.Types) > 0 && !contains(f.Types, a.Type) {
+		return false
+	}
+	if len(f.Categories) > 0 && !contains(f.Categories, a.Category) {
+		return false
+	}
+	if len(f.Sports) > 0 && !contains(f.Sports, normalizeSport(a.Sport)) {
+		return false
+	}
+	return true
+}
+
+// normalizeSport maps short keys onto Odds API keys so either matches
+func normalizeSport(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if sport, ok := models.ParseSport(s); ok {
+		return string(sport)
+	}
+	return s
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriber is a single filtered subscription
+type subscriber struct {
+	filter Filter
+	ch     chan Alert
+}
+
+// Stream fans every alert out to filtered subscribers, across sports and
+// independent of odds subscriptions
+type Stream struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]*subscriber
+
+	// relay shares published alerts with other instances, when set
+	relay Relay
+}
+
+// Relay passes alerts on to other instances, which Deliver them to their
+// own subscribers
+type Relay interface {
+	PublishAlert(a Alert)
+}
+
+// SetRelay shares every published alert through a relay. Call before
+// alerts are published.
+func (s *Stream) SetRelay(r Relay) {
+	s.relay = r
+}
+
+// New creates an alert stream
+func New() *Stream {
+	return &Stream{subs: make(map[int]*subscriber)}
+}
+
+// Subscribe returns a channel of alerts passing the filter. Alerts are
+// dropped rather than block publishers when the subscriber falls behind.
+// The returned function unsubscribes and closes the channel.
+func (s *Stream) Subscribe(filter Filter) (<-chan Alert, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	sub := &subscriber{filter: filter, ch: make(chan Alert, subscriberBuffer)}
+	s.subs[id] = sub
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.subs, id)
+			close(sub.ch)
+		})
+	}
+	return sub.ch, cancel
+}
+
+// Publish sends an alert to every subscriber whose filter it passes, and to
+// other instances through the relay
+func (s *Stream) Publish(a Alert) {
+	s.Deliver(a)
+	if s.relay != nil {
+		s.relay.PublishAlert(a)
+	}
+}
+
+// Deliver sends an alert to this instance's subscribers only, for alerts
+// relayed from another instance
+func (s *Stream) Deliver(a Alert) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sub := range s.subs {
+		if !sub.filter.Match(a) {
+			continue
+		}
+		select {
+		case sub.ch <- a:
+		default:
+			// Slow subscriber: drop rather than hold up alerts for the rest
+		}
+	}
+}
+
+// Subscribers returns how many subscriptions are open
+func (s *Stream) Subscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subs)
+}
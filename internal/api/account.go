@@ -0,0 +1,67 @@
+package api
+
+import "net/http"
+
+// handleMeExport returns everything stored about the user: preferences,
+// push subscription, alert history and feedback (including bets), and
+// notification and webhook logs. With a single user there's no login yet,
+// so it needs the admin token.
+// GET /api/me/export
+func (h *Handler) handleMeExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	export, err := h.db.ExportUserData()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to export data")
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="linefinder-export.json"`)
+	h.jsonResponse(w, http.StatusOK, export)
+}
+
+// handleMe deletes everything stored about the user and restores default
+// preferences. Needs the admin token, like the export.
+// DELETE /api/me
+func (h *Handler) handleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	deleted, err := h.db.DeleteUserData()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to delete data")
+		return
+	}
+
+	// Settings held in memory go back to the defaults too
+	if h.oddsService != nil {
+		if prefs, err := h.db.GetPreferences(); err == nil {
+			h.oddsService.SetEVSettings(prefs.VigMethod, prefs.EVThresholdPct)
+			h.oddsService.SetPeriodEVThreshold(prefs.PeriodEVThresholdPct)
+		}
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "all user data deleted",
+		"deleted": deleted,
+	})
+}
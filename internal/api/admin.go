@@ -0,0 +1,334 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/metrics"
+	"github.com/joshuakim/linefinder/internal/oddsapi"
+)
+
+// SetAdminToken sets the bearer token required by /api/admin endpoints.
+// Admin endpoints are disabled while the token is empty.
+func (h *Handler) SetAdminToken(token string) {
+	h.adminToken = token
+}
+
+// SetSimulatedClock enables the clock admin endpoint
+func (h *Handler) SetSimulatedClock(c *clock.Virtual) {
+	h.simClock = c
+}
+
+// SetFaultInjector enables the fault injection admin endpoint
+func (h *Handler) SetFaultInjector(f *oddsapi.FaultInjector) {
+	h.faults = f
+}
+
+// requireAdmin checks the admin bearer token, writing an error response
+// and returning false when the request isn't authorized
+func (h *Handler) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if h.adminToken == "" {
+		h.errorResponse(w, http.StatusForbidden, "admin API disabled: set ADMIN_TOKEN")
+		return false
+	}
+	return h.checkBearer(w, r, h.adminToken, "invalid admin token")
+}
+
+// checkBearer compares the request's bearer token to want, writing an
+// error response and returning false when they differ
+func (h *Handler) checkBearer(w http.ResponseWriter, r *http.Request, want, message string) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(want)) != 1 {
+		h.errorResponse(w, http.StatusUnauthorized, message)
+		return false
+	}
+	return true
+}
+
+// handleAdminClock reads or adjusts the simulated clock
+// GET  /api/admin/clock
+// POST /api/admin/clock {"time": RFC3339, "advance": "90m", "freeze": true, "reset": true}
+func (h *Handler) handleAdminClock(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	if h.simClock == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "simulated clock disabled: set SIMULATED_CLOCK=true")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.jsonResponse(w, http.StatusOK, h.simClock.Status())
+
+	case http.MethodPost:
+		var body struct {
+			Time    string `json:"time"`
+			Advance string `json:"advance"`
+			Freeze  *bool  `json:"freeze"`
+			Reset   bool   `json:"reset"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+
+		var setTime time.Time
+		if body.Time != "" {
+			t, err := time.Parse(time.RFC3339, body.Time)
+			if err != nil {
+				h.errorResponse(w, http.StatusBadRequest, "time must be RFC3339")
+				return
+			}
+			setTime = t
+		}
+
+		var advance time.Duration
+		if body.Advance != "" {
+			d, err := time.ParseDuration(body.Advance)
+			if err != nil {
+				h.errorResponse(w, http.StatusBadRequest, "advance must be a duration like '90m'")
+				return
+			}
+			advance = d
+		}
+
+		if body.Reset {
+			h.simClock.Reset()
+		}
+		if body.Freeze != nil {
+			h.simClock.Freeze(*body.Freeze)
+		}
+		if !setTime.IsZero() {
+			h.simClock.Set(setTime)
+		}
+		if advance != 0 {
+			h.simClock.Advance(advance)
+		}
+
+		h.jsonResponse(w, http.StatusOK, h.simClock.Status())
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleAdminFaults lists, adds or removes simulated Odds API failures.
+// DELETE without an ID clears them all.
+// GET    /api/admin/faults
+// POST   /api/admin/faults {"kind": "rate_limit|server_error|slow|malformed", "match": "/odds", "rate": 0.5, "delay_ms": 5000, "count": 3}
+// DELETE /api/admin/faults?id=1
+func (h *Handler) handleAdminFaults(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	if h.faults == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "fault injection disabled: set FAULT_INJECTION=true")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		faults := h.faults.Faults()
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"faults": faults,
+			"count":  len(faults),
+		})
+
+	case http.MethodPost:
+		var fault oddsapi.Fault
+		if err := json.NewDecoder(r.Body).Decode(&fault); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+		added, err := h.faults.Add(fault)
+		if err != nil {
+			h.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.jsonResponse(w, http.StatusCreated, added)
+
+	case http.MethodDelete:
+		idStr := r.URL.Query().Get("id")
+		if idStr == "" {
+			h.faults.Clear()
+			h.jsonResponse(w, http.StatusOK, map[string]string{"message": "faults cleared"})
+			return
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			h.errorResponse(w, http.StatusBadRequest, "id must be a number")
+			return
+		}
+		if !h.faults.Remove(id) {
+			h.errorResponse(w, http.StatusNotFound, "fault not found")
+			return
+		}
+		h.jsonResponse(w, http.StatusOK, map[string]string{"message": "fault removed"})
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleAdminDatabase reports the database's size and tables, or runs
+// maintenance on it: an integrity check, VACUUM and ANALYZE, or the listed
+// subset. VACUUM blocks writes while it runs.
+// GET  /api/admin/database
+// POST /api/admin/database {"operations": ["integrity_check", "vacuum", "analyze"]}
+func (h *Handler) handleAdminDatabase(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		size, err := h.db.Size()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to measure database")
+			return
+		}
+		tables, err := h.db.TableStats()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to list tables")
+			return
+		}
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"size_bytes":       size,
+			"tables":           tables,
+			"last_maintenance": h.db.LastMaintenance(),
+		})
+
+	case http.MethodPost:
+		var body struct {
+			Operations []string `json:"operations"`
+		}
+		if r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+				return
+			}
+		}
+		ops, err := database.ParseMaintenanceOps(body.Operations)
+		if err != nil {
+			h.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		report, err := h.db.Maintain(ops)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "maintenance failed: "+err.Error())
+			return
+		}
+		h.jsonResponse(w, http.StatusOK, report)
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleAdminMetricsReset zeroes the metrics counters in the listed
+// scopes, or all of them, so totals count from now. Only this instance's
+// counters are reset.
+// POST /api/admin/metrics/reset {"scopes": ["websocket", "polling", "api"]}
+func (h *Handler) handleAdminMetricsReset(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var body struct {
+		Scopes []string `json:"scopes"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+	}
+	if err := h.metrics.Reset(body.Scopes...); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, h.metrics.Counters())
+}
+
+// handleAdminMetricsSnapshot returns the latest metrics snapshot taken at
+// or before a time, this instance's counters now and how much they grew
+// since. Snapshots are taken every METRICS_SNAPSHOT_MINUTES; instance
+// picks another instance's, or a previous run's, snapshots.
+// GET /api/admin/metrics/snapshot?at=RFC3339&instance=
+func (h *Handler) handleAdminMetricsSnapshot(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	atStr := r.URL.Query().Get("at")
+	if atStr == "" {
+		h.errorResponse(w, http.StatusBadRequest, "at is required")
+		return
+	}
+	at, err := time.Parse(time.RFC3339, atStr)
+	if err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "at must be RFC3339")
+		return
+	}
+
+	current := h.metrics.Counters()
+	instance := r.URL.Query().Get("instance")
+	if instance == "" {
+		instance = current.Instance
+	}
+
+	snapshot, err := h.db.GetMetricsSnapshot(instance, at)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get metrics snapshot")
+		return
+	}
+	if snapshot == nil {
+		h.errorResponse(w, http.StatusNotFound, "no metrics snapshot at or before "+atStr)
+		return
+	}
+	var counters metrics.Counters
+	if err := json.Unmarshal(snapshot.Counters, &counters); err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to decode metrics snapshot")
+		return
+	}
+
+	response := map[string]interface{}{
+		"snapshot": counters,
+		"taken_at": snapshot.TakenAt,
+	}
+	// Deltas only make sense against this instance's own counters
+	if instance == current.Instance {
+		response["current"] = current
+		response["delta"] = current.Delta(counters)
+	}
+	h.jsonResponse(w, http.StatusOK, response)
+}
@@ -0,0 +1,404 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/store"
+)
+
+// maxAlertIDs caps how many alerts one request can look up
+const maxAlertIDs = 100
+
+// handleAlerts returns stored alerts by ID, as linked from push
+// notifications too large to embed their alerts
+// GET /api/alerts?ids=12,15,18
+func (h *Handler) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	var ids []int64
+	for _, part := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id <= 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidIDs, "invalid ids: must be comma-separated alert IDs")
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		h.errorResponse(w, http.StatusBadRequest, "ids is required")
+		return
+	}
+	if len(ids) > maxAlertIDs {
+		h.errorResponse(w, http.StatusBadRequest, "too many ids: at most 100 per request")
+		return
+	}
+
+	history, err := h.db.GetAlertsByIDs(ids)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get alerts")
+		return
+	}
+	if history == nil {
+		history = []database.AlertHistory{}
+	}
+
+	h.jsonResponse(w, http.StatusOK, AlertsResponse{Alerts: history, Count: len(history)})
+}
+
+// handleAlertRoutes dispatches per-alert endpoints
+// GET  /api/alerts/inbox
+// POST /api/alerts/inbox/read
+// GET  /api/alerts/digest
+// GET  /api/alerts/stream
+// GET  /api/alerts/{id}
+// POST /api/alerts/{id}/read
+// POST /api/alerts/{id}/feedback
+func (h *Handler) handleAlertRoutes(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/alerts/"), "/")
+	parts := strings.Split(path, "/")
+
+	if parts[0] == "inbox" {
+		switch {
+		case len(parts) == 1:
+			h.handleAlertInbox(w, r)
+		case len(parts) == 2 && parts[1] == "read":
+			h.handleAlertInboxReadAll(w, r)
+		default:
+			h.errorResponse(w, http.StatusNotFound, "not found")
+		}
+		return
+	}
+	if path == "digest" {
+		h.handleAlertDigest(w, r)
+		return
+	}
+	if path == "stream" {
+		h.handleAlertStream(w, r)
+		return
+	}
+
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		h.errorResponse(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		h.handleAlertDetail(w, r, id)
+	case len(parts) == 2 && parts[1] == "read":
+		h.handleAlertRead(w, r, id)
+	case len(parts) == 2 && parts[1] == "feedback":
+		h.handleAlertFeedback(w, r, id)
+	default:
+		h.errorResponse(w, http.StatusNotFound, "not found")
+	}
+}
+
+// AlertDetail is a stored alert alongside the prop's line now
+type AlertDetail struct {
+	alerts.ValueAlert
+
+	// LineAtDetection repeats Line for clarity next to CurrentLine
+	LineAtDetection float64 `json:"line_at_detection"`
+
+	// Current best line for the prop, nil when it's no longer offered
+	StillOffered     bool     `json:"still_offered"`
+	CurrentLine      *float64 `json:"current_line"`
+	CurrentOdds      *float64 `json:"current_odds"`
+	CurrentBookmaker string   `json:"current_bookmaker,omitempty"`
+	LineMovement     *float64 `json:"line_movement"` // current line minus line at detection
+
+	// Lifecycle state changes, oldest first
+	Transitions []database.AlertTransition `json:"transitions"`
+}
+
+// handleAlertDetail returns a stored alert with its current line, so push
+// notifications can deep-link to an alert by ID
+// GET /api/alerts/{id}
+func (h *Handler) handleAlertDetail(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	history, err := h.db.GetAlertByID(id)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get alert")
+		return
+	}
+	if history == nil {
+		h.errorResponse(w, http.StatusNotFound, "alert not found")
+		return
+	}
+
+	transitions, err := h.db.GetAlertTransitions(id)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get alert transitions")
+		return
+	}
+	if transitions == nil {
+		transitions = []database.AlertTransition{}
+	}
+
+	alert := storedAlert(history)
+	detail := AlertDetail{ValueAlert: alert, LineAtDetection: alert.Line, Transitions: transitions}
+
+	if game, found := h.oddsService.GetGame(alert.GameID); found {
+		if prop, ok := h.currentProp(game, alert.PlayerName, alert.PropCategory); ok {
+			movement := math.Round((prop.Line-alert.Line)*10) / 10
+			detail.StillOffered = true
+			detail.CurrentLine = &prop.Line
+			detail.CurrentOdds = &prop.BestOdds
+			detail.CurrentBookmaker = prop.Bookmaker
+			detail.LineMovement = &movement
+		}
+	}
+
+	h.jsonResponse(w, http.StatusOK, detail)
+}
+
+// storedAlert rebuilds the alert from its history row. Rows recorded before
+// the full alert was stored only carry the core fields.
+func storedAlert(history *database.AlertHistory) alerts.ValueAlert {
+	var alert alerts.ValueAlert
+	if history.AlertJSON != "" && json.Unmarshal([]byte(history.AlertJSON), &alert) == nil {
+		alert.HistoryID = history.ID
+		alert.State = history.State
+		alert.Category = models.CategoryPropValue
+		return alert
+	}
+
+	return alerts.ValueAlert{
+		HistoryID:     history.ID,
+		Category:      models.CategoryPropValue,
+		PlayerName:    history.PlayerName,
+		GameID:        history.GameID,
+		PropCategory:  history.PropCategory,
+		Line:          history.LineValue,
+		Average:       history.AverageValue,
+		Difference:    history.Difference,
+		AbsDifference: math.Abs(history.Difference),
+		Direction:     history.Direction,
+		Confidence:    history.Confidence,
+		State:         history.State,
+		DetectedAt:    history.CreatedAt,
+	}
+}
+
+// currentProp finds a player's prop in a game's current props
+func (h *Handler) currentProp(game models.Game, player, category string) (alerts.PropData, bool) {
+	props := store.GetDummyPlayerProps(game.ID, game.SportKey, game.HomeTeam, game.AwayTeam)
+	averages := h.projections.Apply(store.GetDummyPlayerAverages(string(game.SportKey)))
+
+	collect := alerts.CollectProps
+	if h.alertDetector != nil {
+		collect = h.alertDetector.CollectProps
+	}
+	for _, prop := range collect(props, averages, nil) {
+		if strings.EqualFold(prop.PlayerName, player) && prop.PropCategory == category {
+			return prop, true
+		}
+	}
+	return alerts.PropData{}, false
+}
+
+// handleAlertFeedback records a rating for a stored alert
+// POST /api/alerts/{id}/feedback
+func (h *Handler) handleAlertFeedback(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	var body AlertFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+		return
+	}
+
+	switch body.Rating {
+	case database.FeedbackUseful, database.FeedbackNotUseful, database.FeedbackBetIt:
+	default:
+		h.errorResponse(w, http.StatusBadRequest, "rating must be 'useful', 'not_useful', or 'bet_it'")
+		return
+	}
+
+	switch body.Outcome {
+	case "", database.OutcomeWin, database.OutcomeLoss, database.OutcomePush:
+	default:
+		h.errorResponse(w, http.StatusBadRequest, "outcome must be 'win', 'loss', or 'push'")
+		return
+	}
+
+	if body.Stake < 0 {
+		h.errorResponse(w, http.StatusBadRequest, "stake must not be negative")
+		return
+	}
+	if body.Stake > 0 && body.Rating != database.FeedbackBetIt {
+		h.errorResponse(w, http.StatusBadRequest, "stake is only recorded with rating 'bet_it'")
+		return
+	}
+
+	alert, err := h.db.GetAlertByID(id)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get alert")
+		return
+	}
+	if alert == nil {
+		h.errorResponse(w, http.StatusNotFound, "alert not found")
+		return
+	}
+
+	feedback := &database.AlertFeedback{
+		AlertID:      alert.ID,
+		PlayerName:   alert.PlayerName,
+		PropCategory: alert.PropCategory,
+		Direction:    alert.Direction,
+		Confidence:   alert.Confidence,
+		Rating:       body.Rating,
+		Outcome:      body.Outcome,
+		Note:         body.Note,
+		Stake:        body.Stake,
+	}
+	if err := h.db.SaveAlertFeedback(feedback); err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to save feedback")
+		return
+	}
+
+	// Logging a bet converts the alert
+	if body.Rating == database.FeedbackBetIt && h.alertDetector != nil {
+		change, err := h.alertDetector.MarkConverted(alert)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to convert alert")
+			return
+		}
+		if change != nil {
+			alert.State = change.To
+			if h.notificationSvc != nil {
+				h.notificationSvc.PublishAlertStates([]alerts.StateChange{*change})
+			}
+		}
+	}
+
+	response := AlertFeedbackResponse{
+		Message:  "feedback recorded",
+		Feedback: feedback,
+		State:    alert.State,
+	}
+	// Bets over the user's limits are recorded, with a warning
+	if body.Stake > 0 {
+		response.Warnings = h.betWarnings(body.Stake)
+	}
+	h.jsonResponse(w, http.StatusOK, response)
+}
+
+// handleFeedbackReport returns feedback vs outcomes per prop category.
+// Threshold suggestions are included when auto-tuning is enabled in preferences.
+// GET /api/reports/feedback
+func (h *Handler) handleFeedbackReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.reports == nil || h.db == nil || h.alertDetector == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "reports not configured")
+		return
+	}
+
+	prefs, err := h.db.GetPreferences()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+		return
+	}
+
+	report, err := h.reports.BuildFeedbackReport(h.alertDetector.GetThresholds(), prefs.AutoTuneThresholds)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to build report")
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, report)
+}
+
+// handleApplyFeedbackSuggestions applies the current threshold suggestions
+// POST /api/reports/feedback/apply
+func (h *Handler) handleApplyFeedbackSuggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.reports == nil || h.db == nil || h.alertDetector == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "reports not configured")
+		return
+	}
+
+	prefs, err := h.db.GetPreferences()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+		return
+	}
+
+	if !prefs.AutoTuneThresholds {
+		h.errorResponse(w, http.StatusConflict, "auto-tuning is disabled in preferences")
+		return
+	}
+
+	thresholds := alerts.ThresholdsFromPreferences(prefs)
+	report, err := h.reports.BuildFeedbackReport(thresholds, true)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to build report")
+		return
+	}
+
+	for _, s := range report.Suggestions {
+		if err := thresholds.SetThreshold(s.Category, s.Suggested); err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to apply suggestions")
+			return
+		}
+	}
+	thresholds.ApplyToPreferences(prefs)
+
+	if err := h.db.UpdatePreferences(prefs); err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to update preferences")
+		return
+	}
+	h.alertDetector.UpdateThresholds(thresholds)
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"message":    "threshold suggestions applied",
+		"applied":    report.Suggestions,
+		"thresholds": thresholds,
+	})
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/bets"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// handleBets lists logged bets or logs a new one. Bets on games in the
+// store only need the game ID; others need the game's sport, teams and
+// start time. The stake is in currency, or in units with stake_units once
+// the unit_size preference is set. A bet on an alert gives its alert_id,
+// which fills in the game ID and marks the alert converted.
+// GET  /api/bets?status=pending|graded
+// POST /api/bets {"game_id": "abc", "market": "spreads", "selection": "Boston Celtics", "point": -4.5, "bookmaker": "draftkings", "price": -110, "stake": 50}
+func (h *Handler) handleBets(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		status := r.URL.Query().Get("status")
+		if status != "" && status != database.BetPending && status != "graded" {
+			h.errorResponse(w, http.StatusBadRequest, "status must be 'pending' or 'graded'")
+			return
+		}
+		list, err := h.db.GetBets(status)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get bets")
+			return
+		}
+		unitSize, currency := h.betUnits()
+		for i := range list {
+			bets.FillUnits(&list[i], unitSize)
+		}
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"bets":     list,
+			"count":    len(list),
+			"currency": currency,
+		})
+
+	case http.MethodPost:
+		var body struct {
+			GameID       string    `json:"game_id"`
+			Sport        string    `json:"sport"`
+			HomeTeam     string    `json:"home_team"`
+			AwayTeam     string    `json:"away_team"`
+			CommenceTime time.Time `json:"commence_time"`
+			Market       string    `json:"market"`
+			Selection    string    `json:"selection"`
+			Point        *float64  `json:"point"`
+			Bookmaker    string    `json:"bookmaker"`
+			Price        float64   `json:"price"`
+			Stake        float64   `json:"stake"`
+			StakeUnits   *float64  `json:"stake_units"`
+			Note         string    `json:"note"`
+			AlertID      *int64    `json:"alert_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+
+		bet := &database.Bet{
+			GameID:    strings.TrimSpace(body.GameID),
+			Market:    strings.ToLower(strings.TrimSpace(body.Market)),
+			Selection: strings.TrimSpace(body.Selection),
+			Point:     body.Point,
+			Bookmaker: strings.ToLower(strings.TrimSpace(body.Bookmaker)),
+			Price:     body.Price,
+			Stake:     body.Stake,
+			Note:      body.Note,
+			AlertID:   body.AlertID,
+		}
+		bet.UnitSize, _ = h.betUnits()
+		if body.StakeUnits != nil {
+			switch {
+			case body.Stake != 0:
+				h.errorResponse(w, http.StatusBadRequest, "give stake or stake_units, not both")
+				return
+			case bet.UnitSize <= 0:
+				h.errorResponse(w, http.StatusBadRequest, "stake_units needs the unit_size preference")
+				return
+			case *body.StakeUnits <= 0:
+				h.errorResponse(w, http.StatusBadRequest, "stake_units must be positive")
+				return
+			}
+			bet.Stake = bets.StakeForUnits(*body.StakeUnits, bet.UnitSize)
+		}
+
+		var alert *database.AlertHistory
+		if bet.AlertID != nil {
+			var err error
+			if alert, err = h.db.GetAlertByID(*bet.AlertID); err != nil {
+				h.errorResponse(w, http.StatusInternalServerError, "failed to get alert")
+				return
+			}
+			if alert == nil {
+				h.errorResponse(w, http.StatusNotFound, "alert not found")
+				return
+			}
+			if bet.GameID == "" {
+				bet.GameID = alert.GameID
+			} else if bet.GameID != alert.GameID {
+				h.errorResponse(w, http.StatusBadRequest, "alert is for another game")
+				return
+			}
+		}
+		if bet.GameID == "" {
+			h.errorResponse(w, http.StatusBadRequest, "game_id is required")
+			return
+		}
+		if game, found := h.oddsService.GetGame(bet.GameID); found {
+			bet.Sport = string(game.SportKey)
+			bet.HomeTeam, bet.AwayTeam = game.HomeTeam, game.AwayTeam
+			bet.CommenceTime = game.CommenceTime
+		} else {
+			sport, ok := models.ParseSport(body.Sport)
+			if !ok || body.HomeTeam == "" || body.AwayTeam == "" || body.CommenceTime.IsZero() {
+				h.errorResponse(w, http.StatusBadRequest, "game not found: give its sport, home_team, away_team and commence_time")
+				return
+			}
+			bet.Sport = string(sport)
+			bet.HomeTeam, bet.AwayTeam = strings.TrimSpace(body.HomeTeam), strings.TrimSpace(body.AwayTeam)
+			bet.CommenceTime = body.CommenceTime
+		}
+
+		if msg := validateBet(bet); msg != "" {
+			h.errorResponse(w, http.StatusBadRequest, msg)
+			return
+		}
+		if err := h.db.SaveBet(bet); err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to save bet")
+			return
+		}
+		bets.FillUnits(bet, bet.UnitSize)
+
+		// Logging a bet converts the alert
+		if alert != nil && h.alertDetector != nil {
+			change, err := h.alertDetector.MarkConverted(alert)
+			if err != nil {
+				h.errorResponse(w, http.StatusInternalServerError, "failed to convert alert")
+				return
+			}
+			if change != nil && h.notificationSvc != nil {
+				h.notificationSvc.PublishAlertStates([]alerts.StateChange{*change})
+			}
+		}
+
+		// Bets over the user's limits are recorded, with a warning
+		warnings := h.betWarnings(bet.Stake)
+		if warnings == nil {
+			warnings = []string{}
+		}
+		h.jsonResponse(w, http.StatusCreated, map[string]interface{}{
+			"bet":      bet,
+			"gradable": bets.Gradable(bet.Market),
+			"warnings": warnings,
+		})
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// validateBet checks a bet's price, stake and selection, normalizing the
+// selection of markets graded from the score. It returns what's wrong, if
+// anything.
+func validateBet(b *database.Bet) string {
+	if b.Stake <= 0 {
+		return "stake must be positive"
+	}
+	if math.Abs(b.Price) < 100 {
+		return "price must be American odds, e.g. -110 or +150"
+	}
+	if b.Bookmaker == "" {
+		return "bookmaker is required"
+	}
+	if b.Market == "" {
+		return "market is required"
+	}
+	if b.Selection == "" {
+		return "selection is required"
+	}
+
+	switch models.Market(b.Market) {
+	case models.MarketH2H, models.MarketSpreads:
+		switch {
+		case strings.EqualFold(b.Selection, b.HomeTeam):
+			b.Selection = b.HomeTeam
+		case strings.EqualFold(b.Selection, b.AwayTeam):
+			b.Selection = b.AwayTeam
+		default:
+			return "selection must be '" + b.HomeTeam + "' or '" + b.AwayTeam + "'"
+		}
+		if models.Market(b.Market) == models.MarketH2H {
+			b.Point = nil
+		} else if b.Point == nil {
+			return "point is required for spreads"
+		}
+	case models.MarketTotals:
+		switch strings.ToLower(b.Selection) {
+		case "over":
+			b.Selection = "Over"
+		case "under":
+			b.Selection = "Under"
+		default:
+			return "selection must be 'Over' or 'Under' for totals"
+		}
+		if b.Point == nil {
+			return "point is required for totals"
+		}
+	}
+	return ""
+}
+
+// handleBetRoutes handles routes under a bet
+func (h *Handler) handleBetRoutes(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/bets/"), "/")
+	parts := strings.Split(path, "/")
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		h.errorResponse(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && parts[1] == "grade":
+		h.handleGradeBet(w, r, id)
+	default:
+		h.errorResponse(w, http.StatusNotFound, "not found")
+	}
+}
+
+// handleGradeBet grades a bet by hand, for markets the scores can't settle
+// such as player props, or to correct an automatic grade
+// POST /api/bets/{id}/grade {"result": "win"}
+func (h *Handler) handleGradeBet(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var body struct {
+		Result string `json:"result"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+		return
+	}
+	switch body.Result {
+	case database.OutcomeWin, database.OutcomeLoss, database.OutcomePush:
+	default:
+		h.errorResponse(w, http.StatusBadRequest, "result must be 'win', 'loss', or 'push'")
+		return
+	}
+
+	bet, err := h.db.GetBet(id)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get bet")
+		return
+	}
+	if bet == nil {
+		h.errorResponse(w, http.StatusNotFound, "bet not found")
+		return
+	}
+
+	bet.Result = body.Result
+	bet.Profit = bets.Profit(bet.Price, bet.Stake, bet.Result)
+	if _, err := h.db.GradeBet(bet); err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to grade bet")
+		return
+	}
+	unitSize, _ := h.betUnits()
+	bets.FillUnits(bet, unitSize)
+	h.jsonResponse(w, http.StatusOK, bet)
+}
+
+// handleBankroll returns profit, ROI, units won and win rate across every
+// logged bet and per bookmaker, in currency and units. Bets count in the
+// unit size they were logged with, then the unit_size preference, then the
+// average stake; ?unit= counts them all in one size instead.
+// GET /api/bankroll?unit=25
+func (h *Handler) handleBankroll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	var unit float64
+	if unitStr := r.URL.Query().Get("unit"); unitStr != "" {
+		var err error
+		if unit, err = strconv.ParseFloat(unitStr, 64); err != nil || unit <= 0 {
+			h.errorResponse(w, http.StatusBadRequest, "unit must be a positive amount")
+			return
+		}
+	}
+
+	list, err := h.db.GetBets("")
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get bets")
+		return
+	}
+	unitSize, currency := h.betUnits()
+	if unit > 0 {
+		unitSize = unit
+		for i := range list {
+			list[i].UnitSize = unit
+		}
+	}
+	h.jsonResponse(w, http.StatusOK, bets.NewBankroll(list, unitSize, currency))
+}
+
+// betUnits returns the unit_size and currency preferences, with no unit
+// size and the default currency when they can't be read
+func (h *Handler) betUnits() (float64, string) {
+	prefs, err := h.db.GetPreferences()
+	if err != nil || prefs.Currency == "" {
+		return 0, bets.DefaultCurrency
+	}
+	return prefs.UnitSize, prefs.Currency
+}
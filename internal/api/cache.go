@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// Cache-Control policies for GET responses
+const (
+	// cacheRevalidate is for data that changes as odds are polled: caches
+	// may keep it but must check back each time, and get a 304 while it
+	// hasn't changed
+	cacheRevalidate = "public, no-cache"
+
+	// cacheStatic is for data that only changes when the server restarts,
+	// such as averages and injuries
+	cacheStatic = "public, max-age=3600"
+
+	// cachePublicStats is for the public stats, recomputed every few
+	// minutes
+	cachePublicStats = "public, max-age=300"
+)
+
+// notModified sets the caching headers for a response last modified at a
+// time and reports whether the request's If-Modified-Since is recent
+// enough that the body isn't needed, in which case the 304 has been
+// written. A zero time sets only the policy.
+func (h *Handler) notModified(w http.ResponseWriter, r *http.Request, modified time.Time, policy string) bool {
+	w.Header().Set("Cache-Control", policy)
+	if modified.IsZero() {
+		return false
+	}
+
+	// Last-Modified has one-second precision
+	modified = modified.UTC().Truncate(time.Second)
+	w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modified.After(since) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// gamesModified returns when a sport's game list last changed for a
+// viewer: when its games did, at the latest midnight (slates are relative
+// to today), or when the preferred timezone may have
+func (h *Handler) gamesModified(r *http.Request, sport models.Sport, loc *time.Location) time.Time {
+	modified := h.oddsService.LastChanged(sport)
+	if modified.IsZero() {
+		return modified
+	}
+
+	now := h.clock.Now().In(loc)
+	if midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc); midnight.After(modified) {
+		modified = midnight
+	}
+	if r.URL.Query().Get("tz") == "" && h.db != nil {
+		if prefs, err := h.db.GetPreferences(); err == nil && prefs.UpdatedAt.After(modified) {
+			modified = prefs.UpdatedAt
+		}
+	}
+	return modified
+}
+
+// startedAt returns when the server started, which is when data that only
+// changes on restart was last modified
+func (h *Handler) startedAt() time.Time {
+	if h.metrics == nil {
+		return time.Time{}
+	}
+	return h.metrics.StartTime
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
+)
+
+// handleCategories lists the prop category taxonomy, or resolves a single
+// name, alias, or market key to its canonical category
+// GET /api/categories?sport=nba
+// GET /api/categories?name=Threes
+func (h *Handler) handleCategories(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.notModified(w, r, h.startedAt(), cacheStatic) {
+		return
+	}
+
+	if name := r.URL.Query().Get("name"); name != "" {
+		category, ok := taxonomy.Lookup(name)
+		if !ok {
+			h.errorResponse(w, http.StatusNotFound, taxonomy.Validate(name).Error())
+			return
+		}
+		h.jsonResponse(w, http.StatusOK, category)
+		return
+	}
+
+	var sport models.Sport
+	if sportStr := r.URL.Query().Get("sport"); sportStr != "" {
+		var ok bool
+		if sport, ok = models.ParseSport(sportStr); !ok {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport: use "+models.SportChoices())
+			return
+		}
+	}
+
+	categories := taxonomy.All(sport)
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"categories": categories,
+		"count":      len(categories),
+	})
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/closing"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// defaultClosingDays is how far back the closing line report looks by
+// default
+const defaultClosingDays = 30
+
+// SetClosingPredictor sets the predictor whose projected closing lines the
+// compare endpoint returns
+func (h *Handler) SetClosingPredictor(p *closing.Predictor) {
+	h.closing = p
+}
+
+// handleClosingReport measures the closing line projections sent with
+// alerts against the actual close, per sport and market
+// GET /api/reports/closing?days=30&sport=nba
+func (h *Handler) handleClosingReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.reports == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "reports not configured")
+		return
+	}
+
+	days := defaultClosingDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		d, err := strconv.Atoi(daysStr)
+		if err != nil || d <= 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidDays, "invalid days: must be a positive integer")
+			return
+		}
+		days = d
+	}
+
+	var sport models.Sport
+	if sportStr := r.URL.Query().Get("sport"); sportStr != "" {
+		sport = h.parseSport(sportStr, "")
+		if sport == "" {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport: use 'nfl', 'nba', or an enabled sport key")
+			return
+		}
+	}
+
+	since := h.clock.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	report, err := h.reports.BuildClosingReport(since, sport)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to build report")
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, report)
+}
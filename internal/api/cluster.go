@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/joshuakim/linefinder/internal/cluster"
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+// SetCluster lists every instance's connections at /api/admin/connections,
+// rather than only this one's
+func (h *Handler) SetCluster(b *cluster.Bridge) {
+	h.cluster = b
+}
+
+// handleAdminConnections lists which instance holds which WebSocket
+// connections, for tracing a client behind a load balancer. In a cluster
+// each instance's list is as of its last write, at most 10s old.
+// GET /api/admin/connections
+func (h *Handler) handleAdminConnections(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	if h.hub == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "WebSocket not available")
+		return
+	}
+
+	instances := []websocket.InstanceConnections{h.hub.Connections()}
+	if h.cluster != nil {
+		var err error
+		if instances, err = h.cluster.Connections(); err != nil {
+			h.errorResponse(w, http.StatusBadGateway, "failed to read connections from Redis: "+err.Error())
+			return
+		}
+	}
+
+	total := 0
+	for _, i := range instances {
+		total += len(i.Connections)
+	}
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"instance":  h.hub.InstanceID(),
+		"clustered": h.cluster != nil,
+		"total":     total,
+		"instances": instances,
+	})
+}
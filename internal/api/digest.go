@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/notifications"
+)
+
+// digestPlay is a live value alert at its current best line
+type digestPlay struct {
+	AlertID         int64     `json:"alert_id"`
+	PlayerName      string    `json:"player_name"`
+	PropCategory    string    `json:"prop_category"`
+	Direction       string    `json:"direction"`
+	Line            float64   `json:"line"`
+	Odds            float64   `json:"odds"`
+	LineAtDetection float64   `json:"line_at_detection"`
+	Difference      float64   `json:"difference"`
+	Confidence      string    `json:"confidence"`
+	State           string    `json:"state"`
+	GameID          string    `json:"game_id"`
+	AwayTeam        string    `json:"away_team"`
+	HomeTeam        string    `json:"home_team"`
+	CommenceTime    time.Time `json:"commence_time"`
+}
+
+// bookDigest is every live play whose best price is at one bookmaker
+type bookDigest struct {
+	Bookmaker string       `json:"bookmaker"`
+	Count     int          `json:"count"`
+	Plays     []digestPlay `json:"plays"`
+}
+
+// handleAlertDigest groups live value alerts by the bookmaker currently
+// offering the best price, so bets can be placed one book at a time. Alerts
+// whose game has started or whose prop is no longer offered are left out.
+// GET /api/alerts/digest
+func (h *Handler) handleAlertDigest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	history, err := h.db.GetLiveAlerts()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get alerts")
+		return
+	}
+
+	now := h.clock.Now()
+	byBook := make(map[string]*bookDigest)
+	total := 0
+	for i := range history {
+		alert := storedAlert(&history[i])
+		game, found := h.oddsService.GetGame(alert.GameID)
+		if !found || !game.CommenceTime.After(now) {
+			continue
+		}
+		prop, ok := h.currentProp(game, alert.PlayerName, alert.PropCategory)
+		if !ok || prop.Bookmaker == "" {
+			continue
+		}
+
+		book := byBook[prop.Bookmaker]
+		if book == nil {
+			book = &bookDigest{Bookmaker: prop.Bookmaker}
+			byBook[prop.Bookmaker] = book
+		}
+		book.Plays = append(book.Plays, digestPlay{
+			AlertID:         alert.HistoryID,
+			PlayerName:      alert.PlayerName,
+			PropCategory:    alert.PropCategory,
+			Direction:       alert.Direction,
+			Line:            prop.Line,
+			Odds:            prop.BestOdds,
+			LineAtDetection: alert.Line,
+			Difference:      alert.Difference,
+			Confidence:      alert.Confidence,
+			State:           alert.State,
+			GameID:          game.ID,
+			AwayTeam:        game.AwayTeam,
+			HomeTeam:        game.HomeTeam,
+			CommenceTime:    game.CommenceTime,
+		})
+		book.Count++
+		total++
+	}
+
+	// Books with the most plays first; within a book, earliest games first,
+	// then the biggest edges
+	books := make([]bookDigest, 0, len(byBook))
+	for _, book := range byBook {
+		sort.SliceStable(book.Plays, func(i, j int) bool {
+			a, b := book.Plays[i], book.Plays[j]
+			if !a.CommenceTime.Equal(b.CommenceTime) {
+				return a.CommenceTime.Before(b.CommenceTime)
+			}
+			return math.Abs(a.Difference) > math.Abs(b.Difference)
+		})
+		books = append(books, *book)
+	}
+	sort.Slice(books, func(i, j int) bool {
+		if books[i].Count != books[j].Count {
+			return books[i].Count > books[j].Count
+		}
+		return books[i].Bookmaker < books[j].Bookmaker
+	})
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"generated_at": now,
+		"books":        books,
+		"count":        total,
+	})
+}
+
+// handleDigest sends the morning digest immediately, to every digest
+// channel that's set up
+// POST /api/digest
+func (h *Handler) handleDigest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.notificationSvc == nil || h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "notifications not configured")
+		return
+	}
+
+	channels, err := h.notificationSvc.SendDigest(h.clock.Now())
+	if errors.Is(err, notifications.ErrNoDigestChannel) {
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.errorResponse(w, http.StatusBadGateway, "failed to send digest: "+err.Error())
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, DigestResponse{
+		Message:  "digest queued for " + strings.Join(channels, ", "),
+		Channels: channels,
+	})
+}
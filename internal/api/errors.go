@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/joshuakim/linefinder/internal/redact"
+)
+
+// Error codes clients can branch on; the message alongside is for people
+const (
+	CodeBadRequest       = "bad_request"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeConflict         = "conflict"
+	CodeGone             = "gone"
+	CodeRateLimited      = "rate_limited"
+	CodeQuotaExceeded    = "quota_exceeded"
+	CodeInternal         = "internal_error"
+	CodeUpstream         = "upstream_error"
+	CodeUnavailable      = "unavailable"
+)
+
+// Codes for a bad parameter or field, invalid_<name>. Rewording an error's
+// message doesn't change its code.
+const (
+	CodeInvalidAmount                    = "invalid_amount"
+	CodeInvalidChannel                   = "invalid_channel"
+	CodeInvalidCurrency                  = "invalid_currency"
+	CodeInvalidDays                      = "invalid_days"
+	CodeInvalidDetectionModes            = "invalid_detection_modes"
+	CodeInvalidDigestChannels            = "invalid_digest_channels"
+	CodeInvalidDigestTime                = "invalid_digest_time"
+	CodeInvalidDiscordWebhookURL         = "invalid_discord_webhook_url"
+	CodeInvalidEVThresholdPct            = "invalid_ev_threshold_pct"
+	CodeInvalidExcludedBookmakers        = "invalid_excluded_bookmakers"
+	CodeInvalidExpiresHours              = "invalid_expires_hours"
+	CodeInvalidHours                     = "invalid_hours"
+	CodeInvalidIDs                       = "invalid_ids"
+	CodeInvalidJSON                      = "invalid_json"
+	CodeInvalidLimit                     = "invalid_limit"
+	CodeInvalidMarket                    = "invalid_market"
+	CodeInvalidMaxHoldPercent            = "invalid_max_hold_percent"
+	CodeInvalidMinConfidence             = "invalid_min_confidence"
+	CodeInvalidMinOdds                   = "invalid_min_odds"
+	CodeInvalidMutedPlayers              = "invalid_muted_players"
+	CodeInvalidMyBook                    = "invalid_my_book"
+	CodeInvalidName                      = "invalid_name"
+	CodeInvalidPath                      = "invalid_path"
+	CodeInvalidPeriodEVThresholdPct      = "invalid_period_ev_threshold_pct"
+	CodeInvalidProjectionDisagreementPct = "invalid_projection_disagreement_pct"
+	CodeInvalidProjectionMode            = "invalid_projection_mode"
+	CodeInvalidProjectionWeight          = "invalid_projection_weight"
+	CodeInvalidProjectionWeights         = "invalid_projection_weights"
+	CodeInvalidRateLimitEmail            = "invalid_rate_limit_email"
+	CodeInvalidRateLimitTelegram         = "invalid_rate_limit_telegram"
+	CodeInvalidScanWindowHours           = "invalid_scan_window_hours"
+	CodeInvalidSport                     = "invalid_sport"
+	CodeInvalidSports                    = "invalid_sports"
+	CodeInvalidStatus                    = "invalid_status"
+	CodeInvalidTimezone                  = "invalid_timezone"
+	CodeInvalidUnitSize                  = "invalid_unit_size"
+	CodeInvalidURL                       = "invalid_url"
+	CodeInvalidVigMethod                 = "invalid_vig_method"
+)
+
+// errorResponse writes an error coded by its status alone. Errors about a
+// particular parameter pass its code to errorCodeResponse instead.
+func (h *Handler) errorResponse(w http.ResponseWriter, status int, message string) {
+	h.errorCodeResponse(w, status, statusCode(status), message)
+}
+
+// errorCodeResponse writes an error with an explicit code, for errors the
+// status alone doesn't distinguish
+func (h *Handler) errorCodeResponse(w http.ResponseWriter, status int, code, message string) {
+	h.jsonResponse(w, status, ErrorResponse{Error: redact.String(message), Code: code})
+}
+
+// statusCode returns the generic code for an error status
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusGone:
+		return CodeGone
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway:
+		return CodeUpstream
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
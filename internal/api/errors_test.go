@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshuakim/linefinder/internal/metrics"
+	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+func TestErrorCodes(t *testing.T) {
+	m := metrics.New()
+	h := NewHandler(service.NewOddsService(nil, store.New()), nil, websocket.NewHub(m, 0), nil, m, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+		wantCode   string
+	}{
+		{http.MethodGet, "/api/v1/odds/curling", http.StatusBadRequest, CodeInvalidSport},
+		{http.MethodPost, "/api/v1/odds/nba", http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		var body ErrorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("%s %s: %v", tt.method, tt.path, err)
+		}
+		if rec.Code != tt.wantStatus || body.Code != tt.wantCode {
+			t.Errorf("%s %s: %d %q, want %d %q", tt.method, tt.path, rec.Code, body.Code, tt.wantStatus, tt.wantCode)
+		}
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := map[int]string{
+		http.StatusBadRequest:          CodeBadRequest,
+		http.StatusNotFound:            CodeNotFound,
+		http.StatusTooManyRequests:     CodeRateLimited,
+		http.StatusInternalServerError: CodeInternal,
+		http.StatusGatewayTimeout:      CodeInternal,
+		http.StatusServiceUnavailable:  CodeUnavailable,
+		http.StatusTeapot:              CodeBadRequest,
+	}
+	for status, want := range tests {
+		if got := statusCode(status); got != want {
+			t.Errorf("statusCode(%d) = %q, want %q", status, got, want)
+		}
+	}
+}
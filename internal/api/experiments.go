@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+)
+
+// handleThresholdExperiment manages the A/B threshold experiment
+// GET  /api/experiments/thresholds - running experiment with comparison report
+// POST /api/experiments/thresholds - start a new experiment
+func (h *Handler) handleThresholdExperiment(w http.ResponseWriter, r *http.Request) {
+	if h.alertDetector == nil || h.reports == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "alert detection not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		e := h.alertDetector.GetExperiment()
+		if e == nil {
+			h.jsonResponse(w, http.StatusOK, map[string]interface{}{"running": false})
+			return
+		}
+
+		report, err := h.reports.BuildExperimentReport(e)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to build report")
+			return
+		}
+
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"running": true,
+			"report":  report,
+		})
+
+	case http.MethodPost:
+		var body struct {
+			Name     string             `json:"name"`
+			ProfileA *alerts.Thresholds `json:"profile_a"`
+			ProfileB *alerts.Thresholds `json:"profile_b"`
+			Active   string             `json:"active"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+
+		if body.ProfileB == nil {
+			h.errorResponse(w, http.StatusBadRequest, "profile_b required")
+			return
+		}
+		if body.Active != "" && body.Active != alerts.ProfileA && body.Active != alerts.ProfileB {
+			h.errorResponse(w, http.StatusBadRequest, "active must be 'a' or 'b'")
+			return
+		}
+		if body.Name == "" {
+			body.Name = "thresholds"
+		}
+
+		// Profile A defaults to the current configuration
+		profileA := h.alertDetector.GetThresholds()
+		if body.ProfileA != nil {
+			profileA = *body.ProfileA
+		}
+
+		e := &alerts.Experiment{
+			Name:     body.Name,
+			ProfileA: profileA,
+			ProfileB: *body.ProfileB,
+			Active:   body.Active,
+		}
+		if err := h.alertDetector.StartExperiment(e); err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to start experiment")
+			return
+		}
+
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"message":    "experiment started",
+			"experiment": e,
+		})
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleStopThresholdExperiment ends the running experiment, optionally
+// adopting one profile's thresholds as the new configuration
+// POST /api/experiments/thresholds/stop {"adopt": "b"}
+func (h *Handler) handleStopThresholdExperiment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.alertDetector == nil || h.reports == nil || h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "alert detection not configured")
+		return
+	}
+
+	var body struct {
+		Adopt string `json:"adopt"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+	}
+	if body.Adopt != "" && body.Adopt != alerts.ProfileA && body.Adopt != alerts.ProfileB {
+		h.errorResponse(w, http.StatusBadRequest, "adopt must be 'a' or 'b'")
+		return
+	}
+
+	e := h.alertDetector.GetExperiment()
+	if e == nil {
+		h.errorResponse(w, http.StatusNotFound, "no experiment running")
+		return
+	}
+
+	report, err := h.reports.BuildExperimentReport(e)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to build report")
+		return
+	}
+
+	if _, err := h.alertDetector.StopExperiment(); err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to stop experiment")
+		return
+	}
+
+	if body.Adopt != "" {
+		adopted := e.ProfileA
+		if body.Adopt == alerts.ProfileB {
+			adopted = e.ProfileB
+		}
+
+		prefs, err := h.db.GetPreferences()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+			return
+		}
+		adopted.ApplyToPreferences(prefs)
+		if err := h.db.UpdatePreferences(prefs); err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to update preferences")
+			return
+		}
+		h.alertDetector.UpdateThresholds(adopted)
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"message":    "experiment stopped",
+		"adopted":    body.Adopt,
+		"thresholds": h.alertDetector.GetThresholds(),
+		"report":     report,
+	})
+}
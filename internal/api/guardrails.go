@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/database"
+)
+
+// maxCoolOffDays bounds a single cool-off request
+const maxCoolOffDays = 365
+
+// guardrailStatus is where the user stands against their responsible
+// gambling limits
+type guardrailStatus struct {
+	CoolingOff         bool       `json:"cooling_off"`
+	CoolOffUntil       *time.Time `json:"cool_off_until,omitempty"`
+	DailyAlertCap      int        `json:"daily_alert_cap"`
+	AlertsToday        int        `json:"alerts_today"`
+	MaxBetAmount       float64    `json:"max_bet_amount"`
+	DailyBetLimit      float64    `json:"daily_bet_limit"`
+	StakedToday        float64    `json:"staked_today"`
+	WeeklyDepositLimit float64    `json:"weekly_deposit_limit"`
+	DepositedThisWeek  float64    `json:"deposited_this_week"`
+	Warnings           []string   `json:"warnings"`
+}
+
+// guardrailStatus totals today's alerts and stakes and the last 7 days'
+// deposits, with a warning for each limit they're over. Days are local to
+// the timezone preference.
+func (h *Handler) guardrailStatus() (*guardrailStatus, error) {
+	prefs, err := h.db.GetPreferences()
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.clock.Now().In(prefs.Location())
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	status := &guardrailStatus{
+		CoolingOff:         prefs.CoolOffUntil != nil && now.Before(*prefs.CoolOffUntil),
+		CoolOffUntil:       prefs.CoolOffUntil,
+		DailyAlertCap:      prefs.DailyAlertCap,
+		MaxBetAmount:       prefs.MaxBetAmount,
+		DailyBetLimit:      prefs.DailyBetLimit,
+		WeeklyDepositLimit: prefs.WeeklyDepositLimit,
+		Warnings:           []string{},
+	}
+
+	if status.AlertsToday, err = h.db.GetDailyAlertCount(now.Format("2006-01-02")); err != nil {
+		return nil, err
+	}
+	if status.StakedToday, err = h.db.SumStakes(dayStart); err != nil {
+		return nil, err
+	}
+	deposits, err := h.db.GetDeposits(now.AddDate(0, 0, -7))
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deposits {
+		status.DepositedThisWeek += d.Amount
+	}
+
+	for _, warning := range []string{status.dailyBetWarning(), status.depositWarning()} {
+		if warning != "" {
+			status.Warnings = append(status.Warnings, warning)
+		}
+	}
+	return status, nil
+}
+
+// dailyBetWarning describes today's stakes going over the daily bet limit
+func (s *guardrailStatus) dailyBetWarning() string {
+	if s.DailyBetLimit <= 0 || s.StakedToday <= s.DailyBetLimit {
+		return ""
+	}
+	return fmt.Sprintf("today's stakes of %.2f are over your daily bet limit of %.2f", s.StakedToday, s.DailyBetLimit)
+}
+
+// depositWarning describes the last 7 days' deposits going over the weekly
+// deposit limit
+func (s *guardrailStatus) depositWarning() string {
+	if s.WeeklyDepositLimit <= 0 || s.DepositedThisWeek <= s.WeeklyDepositLimit {
+		return ""
+	}
+	return fmt.Sprintf("deposits of %.2f in the last 7 days are over your weekly deposit limit of %.2f", s.DepositedThisWeek, s.WeeklyDepositLimit)
+}
+
+// handleGuardrails returns the user's limits and how close they are
+// GET /api/guardrails
+func (h *Handler) handleGuardrails(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	status, err := h.guardrailStatus()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get guardrails")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, status)
+}
+
+// handleCoolOff starts or extends a cool-off that mutes betting alerts for
+// a number of days. It can't be shortened or lifted once started.
+// POST /api/guardrails/cool-off {"days": 7}
+func (h *Handler) handleCoolOff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	var body struct {
+		Days int `json:"days"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+		return
+	}
+	if body.Days < 1 || body.Days > maxCoolOffDays {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidDays, fmt.Sprintf("invalid days: must be between 1 and %d", maxCoolOffDays))
+		return
+	}
+
+	prefs, err := h.db.GetPreferences()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+		return
+	}
+	until := h.clock.Now().AddDate(0, 0, body.Days).UTC()
+	if prefs.CoolOffUntil != nil && until.Before(*prefs.CoolOffUntil) {
+		h.errorResponse(w, http.StatusConflict, "cool-off already runs until "+prefs.CoolOffUntil.UTC().Format(time.RFC3339)+" and can't be shortened")
+		return
+	}
+
+	if err := h.db.SetCoolOff(until); err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to start cool-off")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"message":        fmt.Sprintf("betting alerts muted for %d days", body.Days),
+		"cool_off_until": until,
+	})
+}
+
+// handleDeposits lists the last 7 days' deposits or logs one, warning when
+// the weekly deposit limit is exceeded
+// GET  /api/guardrails/deposits
+// POST /api/guardrails/deposits {"amount": 100, "bookmaker": "draftkings"}
+func (h *Handler) handleDeposits(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		deposits, err := h.db.GetDeposits(h.clock.Now().AddDate(0, 0, -7))
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get deposits")
+			return
+		}
+		if deposits == nil {
+			deposits = []database.Deposit{}
+		}
+		total := 0.0
+		for _, d := range deposits {
+			total += d.Amount
+		}
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"deposits": deposits,
+			"count":    len(deposits),
+			"total":    total,
+		})
+
+	case http.MethodPost:
+		var deposit database.Deposit
+		if err := json.NewDecoder(r.Body).Decode(&deposit); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+		if deposit.Amount <= 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidAmount, "invalid amount: must be positive")
+			return
+		}
+		deposit.Bookmaker = strings.ToLower(strings.TrimSpace(deposit.Bookmaker))
+
+		if err := h.db.RecordDeposit(&deposit); err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to save deposit")
+			return
+		}
+
+		status, err := h.guardrailStatus()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get guardrails")
+			return
+		}
+		h.jsonResponse(w, http.StatusCreated, map[string]interface{}{
+			"deposit":  deposit,
+			"warnings": status.Warnings,
+		})
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// betWarnings checks a logged bet against the per-bet and daily limits
+func (h *Handler) betWarnings(stake float64) []string {
+	status, err := h.guardrailStatus()
+	if err != nil {
+		return nil
+	}
+
+	var warnings []string
+	if status.MaxBetAmount > 0 && stake > status.MaxBetAmount {
+		warnings = append(warnings, fmt.Sprintf("this bet of %.2f is over your max bet amount of %.2f", stake, status.MaxBetAmount))
+	}
+	if warning := status.dailyBetWarning(); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
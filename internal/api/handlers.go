@@ -2,19 +2,42 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"html"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/alertstream"
+	"github.com/joshuakim/linefinder/internal/bets"
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/closing"
+	"github.com/joshuakim/linefinder/internal/cluster"
 	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/depthcharts"
+	"github.com/joshuakim/linefinder/internal/lineups"
+	"github.com/joshuakim/linefinder/internal/liveprops"
 	"github.com/joshuakim/linefinder/internal/metrics"
 	"github.com/joshuakim/linefinder/internal/models"
 	"github.com/joshuakim/linefinder/internal/notifications"
+	"github.com/joshuakim/linefinder/internal/oddsapi"
 	"github.com/joshuakim/linefinder/internal/polling"
+	"github.com/joshuakim/linefinder/internal/projections"
+	"github.com/joshuakim/linefinder/internal/reference"
+	"github.com/joshuakim/linefinder/internal/reports"
+	"github.com/joshuakim/linefinder/internal/scanner"
 	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/slates"
 	"github.com/joshuakim/linefinder/internal/sportsdata"
+	"github.com/joshuakim/linefinder/internal/steam"
 	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
+	"github.com/joshuakim/linefinder/internal/velocity"
+	"github.com/joshuakim/linefinder/internal/version"
 	"github.com/joshuakim/linefinder/internal/websocket"
 )
 
@@ -28,6 +51,36 @@ type Handler struct {
 	db               *database.DB
 	alertDetector    *alerts.Detector
 	notificationSvc  *notifications.Service
+	reports          *reports.Builder
+	sports           *service.SportsCatalog
+	reference        *reference.Service
+	lineups          *lineups.Monitor
+	liveProps        *liveprops.Tracker
+	depthCharts      *depthcharts.Tracker
+	scanner          *scanner.Scanner
+	velocity         *velocity.Monitor
+	steam            *steam.Detector
+	closing          *closing.Predictor
+	alertStream      *alertstream.Stream
+	projections      *projections.Blender
+	clock            clock.Clock
+
+	// Admin
+	adminToken       string
+	projectionsToken string
+	simClock         *clock.Virtual
+	faults           *oddsapi.FaultInjector
+	standby          *standby
+	cluster          *cluster.Bridge
+
+	// When the unversioned /api/ paths stop working; zero for never
+	legacySunset time.Time
+
+	// Published API description, served at /api/openapi.json
+	openAPISpec []byte
+
+	// Cached public stats; nil while the endpoint is disabled
+	publicStats *publicStats
 }
 
 // NewHandler creates a new handler
@@ -50,37 +103,156 @@ func NewHandler(
 		db:               db,
 		alertDetector:    alertDetector,
 		notificationSvc:  notificationSvc,
+		clock:            clock.Real{},
 	}
 }
 
-// RegisterRoutes sets up the HTTP routes
+// SetClock sets the clock used for request-time decisions
+func (h *Handler) SetClock(c clock.Clock) {
+	h.clock = c
+}
+
+// SetReportBuilder sets the report builder used by report endpoints
+func (h *Handler) SetReportBuilder(builder *reports.Builder) {
+	h.reports = builder
+}
+
+// SetReferenceService sets the service that attaches venue and officiating
+// context to game responses
+func (h *Handler) SetReferenceService(ref *reference.Service) {
+	h.reference = ref
+}
+
+// SetLineupMonitor sets the monitor whose lineup status is included in
+// NBA props responses
+func (h *Handler) SetLineupMonitor(monitor *lineups.Monitor) {
+	h.lineups = monitor
+}
+
+// SetDepthChartTracker sets the tracker whose roles are attached to NFL
+// props responses
+func (h *Handler) SetDepthChartTracker(tracker *depthcharts.Tracker) {
+	h.depthCharts = tracker
+}
+
+// gameReference returns reference data for a game, or nil when unavailable
+func (h *Handler) gameReference(game models.Game) *reference.GameReference {
+	if h.reference == nil {
+		return nil
+	}
+	return h.reference.ForGame(game)
+}
+
+// RegisterRoutes sets up the HTTP routes under /api/v1/, with their
+// unversioned /api/ paths as deprecated aliases
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	routes := http.NewServeMux()
+
 	// Core API endpoints
-	mux.HandleFunc("/api/health", h.handleHealth)
-	mux.HandleFunc("/api/odds/", h.handleOdds)
-	mux.HandleFunc("/api/games/", h.handleGames)
-	mux.HandleFunc("/api/compare/", h.handleCompare)
-	mux.HandleFunc("/api/refresh/", h.handleRefresh)
-	mux.HandleFunc("/api/props/", h.handlePlayerProps)
-	mux.HandleFunc("/api/injuries/", h.handleInjuries)
-	mux.HandleFunc("/api/averages/", h.handlePlayerAverages)
+	routes.HandleFunc("/api/health", h.handleHealth)
+	routes.HandleFunc("/api/version", h.handleVersion)
+	routes.HandleFunc("/api/openapi.json", h.handleOpenAPI)
+	routes.HandleFunc("/api/docs", h.handleDocs)
+	routes.HandleFunc("/api/odds/", h.handleOdds)
+	routes.HandleFunc("/api/games/", h.handleGames)
+	routes.HandleFunc("/api/compare/", h.handleCompare)
+	routes.HandleFunc("/api/history/", h.handleOddsHistory)
+	routes.HandleFunc("/api/velocity", h.handleVelocity)
+	routes.HandleFunc("/api/steam", h.handleSteam)
+	routes.HandleFunc("/api/refresh/", h.handleRefresh)
+	routes.HandleFunc("/api/props/", h.handlePlayerProps)
+	routes.HandleFunc("/api/injuries/", h.handleInjuries)
+	routes.HandleFunc("/api/live/props/", h.handleLiveProps)
+	routes.HandleFunc("/api/averages/", h.handlePlayerAverages)
+	routes.HandleFunc("/api/categories", h.handleCategories)
+	routes.HandleFunc("/api/sports", h.handleSports)
+	routes.HandleFunc("/api/projections", h.handleProjections)
+	routes.HandleFunc("/api/projections/upload", h.handleProjectionsUpload)
 
 	// WebSocket endpoint
-	mux.HandleFunc("/api/ws", h.handleWebSocket)
+	routes.HandleFunc("/api/ws", h.handleWebSocket)
 
 	// Metrics and monitoring endpoints
-	mux.HandleFunc("/api/metrics", h.handleMetrics)
-	mux.HandleFunc("/api/polling/status", h.handlePollingStatus)
-	mux.HandleFunc("/api/polling/toggle", h.handlePollingToggle)
-	mux.HandleFunc("/api/polling/enable", h.handlePollingEnable)
-	mux.HandleFunc("/api/polling/disable", h.handlePollingDisable)
+	routes.HandleFunc("/api/metrics", h.handleMetrics)
+	routes.HandleFunc("/api/polling/status", h.handlePollingStatus)
+	routes.HandleFunc("/api/polling/toggle", h.handlePollingToggle)
+	routes.HandleFunc("/api/polling/enable", h.handlePollingEnable)
+	routes.HandleFunc("/api/polling/disable", h.handlePollingDisable)
+	routes.HandleFunc("/api/polling/config", h.handlePollingConfig)
+	routes.HandleFunc("/api/scanner/status", h.handleScannerStatus)
+	routes.HandleFunc("/api/scanner/enable", h.handleScannerEnable)
+	routes.HandleFunc("/api/scanner/disable", h.handleScannerDisable)
 
 	// Alert and notification endpoints
-	mux.HandleFunc("/api/alerts/check", h.handleCheckAlerts)
-	mux.HandleFunc("/api/preferences", h.handlePreferences)
-	mux.HandleFunc("/api/subscribe", h.handleSubscribe)
-	mux.HandleFunc("/api/unsubscribe", h.handleUnsubscribe)
-	mux.HandleFunc("/api/vapid-public-key", h.handleVAPIDPublicKey)
+	routes.HandleFunc("/api/alerts/check", h.handleCheckAlerts)
+	routes.HandleFunc("/api/topplays", h.handleTopPlays)
+	routes.HandleFunc("/api/alerts", h.handleAlerts)
+	routes.HandleFunc("/api/alerts/", h.handleAlertRoutes)
+	routes.HandleFunc("/api/preferences", h.handlePreferences)
+	routes.HandleFunc("/api/preferences/sports", h.handleSportPreferences)
+	routes.HandleFunc("/api/preferences/subscriptions", h.handleAlertSubscriptions)
+	routes.HandleFunc("/api/preferences/presets", h.handlePresets)
+	routes.HandleFunc("/api/preferences/presets/", h.handlePreset)
+	routes.HandleFunc("/api/preferences/preset/", h.handleApplyPreset)
+	routes.HandleFunc("/api/subscribe", h.handleSubscribe)
+	routes.HandleFunc("/api/unsubscribe", h.handleUnsubscribe)
+	routes.HandleFunc("/api/undo", h.handleUndo)
+	routes.HandleFunc("/api/vapid-public-key", h.handleVAPIDPublicKey)
+	routes.HandleFunc("/api/email/summary", h.handleEmailSummary)
+	routes.HandleFunc("/api/digest", h.handleDigest)
+	routes.HandleFunc("/api/notifications/status", h.handleNotificationStatus)
+	routes.HandleFunc("/api/notifications/log", h.handleNotificationLog)
+	routes.HandleFunc("/api/email/unsubscribe", h.handleEmailUnsubscribe)
+	routes.HandleFunc("/api/webhooks", h.handleWebhooks)
+	routes.HandleFunc("/api/webhooks/", h.handleWebhookRoutes)
+
+	// Responsible gambling guardrails
+	routes.HandleFunc("/api/guardrails", h.handleGuardrails)
+	routes.HandleFunc("/api/guardrails/cool-off", h.handleCoolOff)
+	routes.HandleFunc("/api/guardrails/deposits", h.handleDeposits)
+
+	// Bet tracking
+	routes.HandleFunc("/api/bets", h.handleBets)
+	routes.HandleFunc("/api/bets/", h.handleBetRoutes)
+	routes.HandleFunc("/api/bankroll", h.handleBankroll)
+
+	// Personal data export and deletion (require ADMIN_TOKEN until there are
+	// user accounts)
+	routes.HandleFunc("/api/me", h.handleMe)
+	routes.HandleFunc("/api/me/export", h.handleMeExport)
+
+	// Report endpoints
+	routes.HandleFunc("/api/reports/feedback", h.handleFeedbackReport)
+	routes.HandleFunc("/api/reports/feedback/apply", h.handleApplyFeedbackSuggestions)
+	routes.HandleFunc("/api/reports/hold", h.handleHoldReport)
+	routes.HandleFunc("/api/reports/closing", h.handleClosingReport)
+	routes.HandleFunc("/api/reports/coverage", h.handleCoverageReport)
+
+	// Public stats for a landing page (no auth)
+	routes.HandleFunc("/api/stats/public", h.handlePublicStats)
+
+	// Threshold experiments
+	routes.HandleFunc("/api/experiments/thresholds", h.handleThresholdExperiment)
+	routes.HandleFunc("/api/experiments/thresholds/stop", h.handleStopThresholdExperiment)
+
+	// Admin endpoints (require ADMIN_TOKEN)
+	routes.HandleFunc("/api/admin/clock", h.handleAdminClock)
+	routes.HandleFunc("/api/admin/notifications", h.handleNotificationLog)
+	routes.HandleFunc("/api/admin/database", h.handleAdminDatabase)
+	routes.HandleFunc("/api/admin/metrics/reset", h.handleAdminMetricsReset)
+	routes.HandleFunc("/api/admin/metrics/snapshot", h.handleAdminMetricsSnapshot)
+	routes.HandleFunc("/api/admin/faults", h.handleAdminFaults)
+	routes.HandleFunc("/api/admin/standby", h.handleAdminStandby)
+	routes.HandleFunc("/api/admin/activate", h.handleAdminActivate)
+	routes.HandleFunc("/api/admin/connections", h.handleAdminConnections)
+
+	// Unknown API paths get a 404 rather than falling through to the frontend
+	routes.HandleFunc("/api/", h.handleNotFound)
+
+	// Routes are served under /api/v1/; their unversioned paths stay as
+	// deprecated aliases until LEGACY_API_SUNSET
+	mux.Handle(apiV1, versioned(routes))
+	mux.Handle("/api/", h.legacy(routes))
 }
 
 // handleHealth returns service health status
@@ -91,9 +263,24 @@ func (h *Handler) handleHealth(w http.ResponseWriter, r *http.Request) {
 	}
 
 	health := h.metrics.GetHealth(pollingEnabled)
+	// Take a draining instance out of the load balancer's rotation
+	if h.hub != nil && h.hub.Draining() {
+		health.Status = "draining"
+		h.jsonResponse(w, http.StatusServiceUnavailable, health)
+		return
+	}
 	h.jsonResponse(w, http.StatusOK, health)
 }
 
+// handleVersion returns the running build's version and commit
+func (h *Handler) handleVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, version.Get())
+}
+
 // handleWebSocket upgrades HTTP to WebSocket connection
 func (h *Handler) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	if h.hub == nil {
@@ -205,6 +392,42 @@ func (h *Handler) handlePollingDisable(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handlePollingConfig gets or updates retry and recovery-mode settings
+// GET /api/polling/config
+// PUT /api/polling/config {"max_consecutive_errors": 3, "recovery_interval_seconds": 120}
+func (h *Handler) handlePollingConfig(w http.ResponseWriter, r *http.Request) {
+	if h.pollingSvc == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "polling service not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.jsonResponse(w, http.StatusOK, h.pollingSvc.GetRecoverySettings())
+
+	case http.MethodPut:
+		// Omitted fields keep their current values
+		settings := h.pollingSvc.GetRecoverySettings()
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+
+		if err := h.pollingSvc.UpdateRecoverySettings(settings); err != nil {
+			h.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"message":  "polling settings updated",
+			"settings": h.pollingSvc.GetRecoverySettings(),
+		})
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
 // handleCheckAlerts checks for value alerts across all games
 // GET /api/alerts/check?sport=nba
 func (h *Handler) handleCheckAlerts(w http.ResponseWriter, r *http.Request) {
@@ -223,33 +446,33 @@ func (h *Handler) handleCheckAlerts(w http.ResponseWriter, r *http.Request) {
 		sportStr = "nba"
 	}
 
-	var sport models.Sport
-	switch sportStr {
-	case "nfl":
-		sport = models.SportNFL
-	case "nba":
-		sport = models.SportNBA
-	default:
-		h.errorResponse(w, http.StatusBadRequest, "invalid sport: use 'nfl' or 'nba'")
+	sport, ok := models.ParseSport(sportStr)
+	if !ok {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport: use "+models.SportChoices())
 		return
 	}
+	sportStr = sport.ShortKey()
 
 	// Get all games for the sport
 	games := h.oddsService.GetGamesBySport(sport)
 
 	var allAlerts []alerts.ValueAlert
+	var scanStats metrics.AlertScanStats
 
-	// Check each game for value
+	// Check each game for value, leaving out games already underway
+	now := h.clock.Now()
 	for _, game := range games {
+		if game.Started(now) {
+			continue
+		}
+		if !h.alertDetector.InScanWindow(game.CommenceTime) {
+			scanStats.GamesOutsideWindow++
+			continue
+		}
+
 		// Get player props and averages
 		props := store.GetDummyPlayerProps(game.ID, sport, game.HomeTeam, game.AwayTeam)
-		averages := store.GetDummyPlayerAverages(sportStr)
-
-		// Build averages map
-		avgMap := make(map[string]map[string]float64)
-		for _, pa := range averages {
-			avgMap[strings.ToLower(pa.Name)] = pa.Averages
-		}
+		averages := h.projections.Apply(store.GetDummyPlayerAverages(sportStr))
 
 		ctx := alerts.GameContext{
 			GameID:   game.ID,
@@ -259,67 +482,63 @@ func (h *Handler) handleCheckAlerts(w http.ResponseWriter, r *http.Request) {
 			GameTime: game.CommenceTime,
 		}
 
-		// Process each player's props
-		for _, player := range props.Players {
-			playerAvg := avgMap[strings.ToLower(player.Name)]
-			if playerAvg == nil {
-				continue
-			}
-
-			for _, prop := range player.Props {
-				avg, ok := playerAvg[prop.Category]
-				if !ok {
-					continue
-				}
-
-				// Find best odds
-				var bestLine float64
-				var bestOdds float64
-				var bestBook string
-				for _, bm := range prop.Bookmakers {
-					if bestBook == "" || bm.OverPrice > bestOdds {
-						bestLine = bm.Point
-						bestOdds = bm.OverPrice
-						bestBook = bm.Title
-					}
-				}
-
-				propData := alerts.PropData{
-					PlayerName:   player.Name,
-					Team:         player.Team,
-					PropCategory: prop.Category,
-					Line:         bestLine,
-					Average:      avg,
-					BestOdds:     bestOdds,
-					Bookmaker:    bestBook,
-				}
+		for _, propData := range h.alertDetector.CollectProps(props, averages, &scanStats) {
+			h.alertDetector.ObserveExperiment(propData, ctx)
 
-				alert := h.alertDetector.DetectValue(propData, ctx)
-				if alert != nil {
-					shouldNotify, _ := h.alertDetector.ShouldNotify(alert)
-					if shouldNotify {
-						h.alertDetector.RecordAlert(alert)
-						allAlerts = append(allAlerts, *alert)
-					}
+			alert := h.alertDetector.DetectValue(propData, ctx)
+			if alert != nil {
+				shouldNotify, _ := h.alertDetector.ShouldNotify(alert)
+				if shouldNotify {
+					h.alertDetector.RecordAlert(alert)
+					allAlerts = append(allAlerts, *alert)
 				}
 			}
 		}
 	}
 
+	h.metrics.RecordAlertScan(string(sport), scanStats)
+
 	// Queue alerts for notification
 	if len(allAlerts) > 0 && h.notificationSvc != nil {
 		h.notificationSvc.QueueAlerts(allAlerts)
 	}
 
-	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
-		"sport":       sportStr,
-		"games":       len(games),
-		"alerts":      allAlerts,
-		"alert_count": len(allAlerts),
+	h.jsonResponse(w, http.StatusOK, CheckAlertsResponse{
+		Sport:              sportStr,
+		Games:              len(games),
+		GamesOutsideWindow: scanStats.GamesOutsideWindow,
+		Alerts:             allAlerts,
+		AlertCount:         len(allAlerts),
 	})
 }
 
-// handlePreferences handles GET/PUT for notification preferences
+// maskedSecret stands in for a stored secret in preferences responses
+const maskedSecret = "********"
+
+// maskSecrets replaces the Discord webhook URL and bot token with
+// maskedSecret when set, so they're never sent back
+func maskSecrets(prefs *database.Preferences) {
+	for _, field := range []*string{&prefs.DiscordWebhookURL, &prefs.DiscordBotToken} {
+		if *field != "" {
+			*field = maskedSecret
+		}
+	}
+}
+
+// keepSecrets fills in the stored Discord webhook URL and bot token where
+// an update leaves them empty or masked
+func keepSecrets(prefs, stored *database.Preferences) {
+	if prefs.DiscordWebhookURL == "" || prefs.DiscordWebhookURL == maskedSecret {
+		prefs.DiscordWebhookURL = stored.DiscordWebhookURL
+	}
+	if prefs.DiscordBotToken == "" || prefs.DiscordBotToken == maskedSecret {
+		prefs.DiscordBotToken = stored.DiscordBotToken
+	}
+}
+
+// handlePreferences handles GET/PUT for notification preferences. The
+// Discord secrets are masked on GET and kept when a PUT leaves them empty
+// or masked.
 func (h *Handler) handlePreferences(w http.ResponseWriter, r *http.Request) {
 	if h.db == nil {
 		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
@@ -333,38 +552,258 @@ func (h *Handler) handlePreferences(w http.ResponseWriter, r *http.Request) {
 			h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
 			return
 		}
+		maskSecrets(prefs)
 		h.jsonResponse(w, http.StatusOK, prefs)
 
 	case http.MethodPut:
 		var prefs database.Preferences
 		if err := json.NewDecoder(r.Body).Decode(&prefs); err != nil {
-			h.errorResponse(w, http.StatusBadRequest, "invalid JSON")
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+		previous, err := h.db.GetPreferences()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+			return
+		}
+		keepSecrets(&prefs, previous)
+		if prefs.MyBook != "" && !service.IsAllowedBookmaker(prefs.MyBook) {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidMyBook, "invalid my_book: use 'draftkings', 'fanduel', or 'betmgm'")
+			return
+		}
+		if !projections.ValidMode(prefs.ProjectionMode) {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidProjectionMode, "invalid projection_mode: use 'off', 'override', or 'blend'")
+			return
+		}
+		if prefs.ProjectionWeight < 0 || prefs.ProjectionWeight > 1 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidProjectionWeight, "invalid projection_weight: must be between 0 and 1")
+			return
+		}
+		for source, weight := range prefs.ProjectionWeights {
+			if weight < 0 {
+				h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidProjectionWeights, "invalid projection_weights: "+source+" must not be negative")
+				return
+			}
+		}
+		if prefs.ScanWindowHours < 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidScanWindowHours, "invalid scan_window_hours: must not be negative")
+			return
+		}
+		if prefs.ProjectionDisagreementPct < 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidProjectionDisagreementPct, "invalid projection_disagreement_pct: must not be negative")
+			return
+		}
+		if prefs.VigMethod != "" && !service.ValidVigMethod(prefs.VigMethod) {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidVigMethod, "invalid vig_method: use 'multiplicative' or 'power'")
+			return
+		}
+		if prefs.EVThresholdPct < 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidEVThresholdPct, "invalid ev_threshold_pct: must not be negative")
+			return
+		}
+		if prefs.PeriodEVThresholdPct < 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidPeriodEVThresholdPct, "invalid period_ev_threshold_pct: must not be negative")
+			return
+		}
+		if prefs.OutlierPoints < 0 || prefs.OutlierCents < 0 {
+			h.errorResponse(w, http.StatusBadRequest, "invalid outlier margin: outlier_points and outlier_cents must not be negative")
+			return
+		}
+		if prefs.MinConfidence != "" && !alerts.ValidConfidence(prefs.MinConfidence) {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidMinConfidence, "invalid min_confidence: use 'low', 'medium', or 'high'")
+			return
+		}
+		if prefs.DiscordWebhookURL != "" && !strings.HasPrefix(prefs.DiscordWebhookURL, "https://") {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidDiscordWebhookURL, "invalid discord_webhook_url: must be an https URL")
+			return
+		}
+		if prefs.EnableDiscord && prefs.DiscordWebhookURL == "" && (prefs.DiscordBotToken == "" || prefs.DiscordChannelID == "") {
+			h.errorResponse(w, http.StatusBadRequest, "enable_discord needs discord_webhook_url, or discord_bot_token and discord_channel_id")
+			return
+		}
+		if prefs.EnableEmail && prefs.Email == "" {
+			h.errorResponse(w, http.StatusBadRequest, "enable_email needs email")
+			return
+		}
+		if prefs.RateLimitEmail < 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidRateLimitEmail, "invalid rate_limit_email: must not be negative")
+			return
+		}
+		if prefs.RateLimitTelegram < 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidRateLimitTelegram, "invalid rate_limit_telegram: must not be negative")
+			return
+		}
+		muted := make([]string, 0, len(prefs.MutedPlayers))
+		for _, player := range prefs.MutedPlayers {
+			player = strings.TrimSpace(player)
+			if strings.Contains(player, ",") {
+				h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidMutedPlayers, "invalid muted_players: names must not contain commas")
+				return
+			}
+			if player != "" {
+				muted = append(muted, player)
+			}
+		}
+		prefs.MutedPlayers = muted
+		if prefs.DigestTime == "" {
+			prefs.DigestTime = "08:00"
+		} else if t, err := time.Parse("15:04", prefs.DigestTime); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidDigestTime, "invalid digest_time: use HH:MM, e.g. 08:00")
+			return
+		} else {
+			prefs.DigestTime = t.Format("15:04")
+		}
+		for i, channel := range prefs.DigestChannels {
+			channel = strings.ToLower(strings.TrimSpace(channel))
+			if !notifications.ValidDigestChannel(channel) {
+				h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidDigestChannels, "invalid digest_channels: use 'push', 'webhook', or 'email'")
+				return
+			}
+			prefs.DigestChannels[i] = channel
+		}
+		if prefs.DigestEnabled && len(prefs.DigestChannels) == 0 {
+			h.errorResponse(w, http.StatusBadRequest, "digest_enabled needs at least one of digest_channels")
 			return
 		}
+		if prefs.UnitSize < 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidUnitSize, "invalid unit_size: must not be negative")
+			return
+		}
+		prefs.Currency = strings.ToUpper(strings.TrimSpace(prefs.Currency))
+		if prefs.Currency == "" {
+			prefs.Currency = bets.DefaultCurrency
+		} else if !bets.ValidCurrency(prefs.Currency) {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidCurrency, "invalid currency: use a three-letter code such as USD, EUR or GBP")
+			return
+		}
+		if prefs.DailyAlertCap < 0 || prefs.MaxBetAmount < 0 || prefs.DailyBetLimit < 0 || prefs.WeeklyDepositLimit < 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidLimit, "invalid limit: daily_alert_cap, max_bet_amount, daily_bet_limit and weekly_deposit_limit must not be negative")
+			return
+		}
+		for i, book := range prefs.ExcludedBookmakers {
+			book = strings.ToLower(strings.TrimSpace(book))
+			if !service.IsAllowedBookmaker(book) {
+				h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidExcludedBookmakers, "invalid excluded_bookmakers: use 'draftkings', 'fanduel', or 'betmgm'")
+				return
+			}
+			prefs.ExcludedBookmakers[i] = book
+		}
+		if prefs.DetectionPercentPct < 0 || prefs.DetectionZScore < 0 {
+			h.errorResponse(w, http.StatusBadRequest, "invalid detection threshold: detection_percent_pct and detection_zscore must not be negative")
+			return
+		}
+		if !alerts.ValidMinOdds(prefs.MinOdds) {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidMinOdds, "invalid min_odds: use an American price like -150 or +120, or 0 for any")
+			return
+		}
+		if prefs.MaxHoldPercent < 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidMaxHoldPercent, "invalid max_hold_percent: must not be negative")
+			return
+		}
+		modes := make(map[string]string, len(prefs.DetectionModes))
+		for category, mode := range prefs.DetectionModes {
+			mode = strings.ToLower(strings.TrimSpace(mode))
+			if !alerts.ValidDetectionMode(mode) {
+				h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidDetectionModes, "invalid detection_modes: use 'absolute', 'percent', or 'zscore'")
+				return
+			}
+			key := alerts.DefaultModeKey
+			if !strings.EqualFold(category, alerts.DefaultModeKey) {
+				canonical, ok := taxonomy.Canonical(category)
+				if !ok {
+					h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidDetectionModes, "invalid detection_modes: unknown category '"+category+"'")
+					return
+				}
+				key = canonical
+			}
+			modes[key] = mode
+		}
+		prefs.DetectionModes = modes
+		if msg := subscriptionsError(prefs.AlertSubscriptions); msg != "" {
+			h.errorResponse(w, http.StatusBadRequest, msg)
+			return
+		}
+		prefs.AlertSubscriptions = normalizeSubscriptions(prefs.AlertSubscriptions)
+		for i, sport := range prefs.Sports {
+			info, ok := models.LookupSport(sport)
+			if !ok {
+				h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSports, "invalid sports: use "+models.SportChoices())
+				return
+			}
+			prefs.Sports[i] = info.Key
+		}
+		if prefs.ProjectionMode == "" {
+			prefs.ProjectionMode = projections.ModeBlend
+		}
+		if prefs.ProjectionDisagreementPct == 0 {
+			prefs.ProjectionDisagreementPct = alerts.DefaultDisagreementPct
+		}
+		if prefs.ProjectionWeight == 0 {
+			prefs.ProjectionWeight = projections.DefaultWeight
+		}
+		if prefs.VigMethod == "" {
+			prefs.VigMethod = models.VigMultiplicative
+		}
+		if prefs.EVThresholdPct == 0 {
+			prefs.EVThresholdPct = service.DefaultEVThresholdPct
+		}
+		if prefs.PeriodEVThresholdPct == 0 {
+			prefs.PeriodEVThresholdPct = service.DefaultPeriodEVThresholdPct
+		}
+		if prefs.OutlierPoints == 0 {
+			prefs.OutlierPoints = service.DefaultOutlierPoints
+		}
+		if prefs.OutlierCents == 0 {
+			prefs.OutlierCents = service.DefaultOutlierCents
+		}
+		if prefs.MinConfidence == "" {
+			prefs.MinConfidence = alerts.ConfidenceLow
+		}
+		if prefs.DetectionPercentPct == 0 {
+			prefs.DetectionPercentPct = alerts.DefaultPercentThreshold
+		}
+		if prefs.DetectionZScore == 0 {
+			prefs.DetectionZScore = alerts.DefaultZScoreThreshold
+		}
 
+		undo := h.saveUndo(w, database.UndoPreferences, previous)
+		if undo == nil {
+			return
+		}
 		if err := h.db.UpdatePreferences(&prefs); err != nil {
 			h.errorResponse(w, http.StatusInternalServerError, "failed to update preferences")
 			return
 		}
+		h.applyPreferences(&prefs)
 
-		// Update alert detector thresholds
-		if h.alertDetector != nil {
-			h.alertDetector.UpdateThresholds(alerts.Thresholds{
-				Points:   prefs.ThresholdPoints,
-				Rebounds: prefs.ThresholdRebounds,
-				Assists:  prefs.ThresholdAssists,
-				Threes:   prefs.ThresholdThrees,
-				Default:  prefs.ThresholdDefault,
-			})
-		}
-
-		h.jsonResponse(w, http.StatusOK, map[string]string{"message": "preferences updated"})
+		h.jsonResponse(w, http.StatusOK, undoable("preferences updated", undo))
 
 	default:
 		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
 	}
 }
 
+// applyPreferences hands saved preferences to the services that keep their
+// own copy
+func (h *Handler) applyPreferences(prefs *database.Preferences) {
+	if h.alertDetector != nil {
+		h.alertDetector.UpdateThresholds(alerts.ThresholdsFromPreferences(prefs))
+		h.alertDetector.SetMyBook(prefs.MyBook)
+		h.alertDetector.SetExcludedBookmakers(prefs.ExcludedBookmakers)
+		h.alertDetector.SetScanWindow(prefs.ScanWindowHours)
+		h.alertDetector.SetSports(prefs.Sports)
+		h.alertDetector.SetDetectionModes(alerts.DetectionModesFromPreferences(prefs))
+		h.alertDetector.SetPriceFilters(alerts.PriceFiltersFromPreferences(prefs))
+		h.alertDetector.SetDisagreementThreshold(prefs.ProjectionDisagreementPct)
+	}
+	h.oddsService.SetEVSettings(prefs.VigMethod, prefs.EVThresholdPct)
+	h.oddsService.SetPeriodEVThreshold(prefs.PeriodEVThresholdPct)
+	h.oddsService.SetOutlierSettings(prefs.OutlierPoints, prefs.OutlierCents)
+	if h.notificationSvc != nil {
+		h.notificationSvc.SetBatchInterval(time.Duration(prefs.BatchIntervalSeconds) * time.Second)
+	}
+}
+
 // handleSubscribe handles push notification subscription
 // POST /api/subscribe
 func (h *Handler) handleSubscribe(w http.ResponseWriter, r *http.Request) {
@@ -382,7 +821,7 @@ func (h *Handler) handleSubscribe(w http.ResponseWriter, r *http.Request) {
 		Subscription string `json:"subscription"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		h.errorResponse(w, http.StatusBadRequest, "invalid JSON")
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
 		return
 	}
 
@@ -412,12 +851,21 @@ func (h *Handler) handleUnsubscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	prefs, err := h.db.GetPreferences()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+		return
+	}
+	undo := h.saveUndo(w, database.UndoPreferences, prefs)
+	if undo == nil {
+		return
+	}
 	if err := h.db.Unsubscribe(); err != nil {
 		h.errorResponse(w, http.StatusInternalServerError, "failed to unsubscribe")
 		return
 	}
 
-	h.jsonResponse(w, http.StatusOK, map[string]string{"message": "unsubscribed from all notifications"})
+	h.jsonResponse(w, http.StatusOK, undoable("unsubscribed from all notifications", undo))
 }
 
 // handleVAPIDPublicKey returns the VAPID public key for push subscription
@@ -439,7 +887,106 @@ func (h *Handler) handleVAPIDPublicKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.jsonResponse(w, http.StatusOK, map[string]string{"publicKey": key})
+	h.jsonResponse(w, http.StatusOK, VAPIDKeyResponse{PublicKey: key})
+}
+
+// handleEmailSummary sends the daily summary email immediately
+// POST /api/email/summary
+func (h *Handler) handleEmailSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.notificationSvc == nil || h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "notifications not configured")
+		return
+	}
+
+	if !h.notificationSvc.EmailConfigured() {
+		h.errorResponse(w, http.StatusServiceUnavailable, "SMTP not configured")
+		return
+	}
+
+	prefs, err := h.db.GetPreferences()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+		return
+	}
+
+	if prefs.Email == "" {
+		h.errorResponse(w, http.StatusBadRequest, "no email address in preferences")
+		return
+	}
+
+	if err := h.notificationSvc.SendDailySummary(prefs.Email, prefs.Sports, h.clock.Now()); err != nil {
+		h.errorResponse(w, http.StatusBadGateway, "failed to send summary: "+err.Error())
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]string{"message": "summary sent to " + prefs.Email})
+}
+
+// handleEmailUnsubscribe disables alert email and the daily summary from an
+// email link. GET only asks for confirmation, since link scanners and
+// prefetchers open links; the confirm form and mail clients' one-click
+// unsubscribe (RFC 8058) POST. The change can be undone like any other
+// unsubscribe.
+// GET|POST /api/email/unsubscribe?token=...
+func (h *Handler) handleEmailUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	ok, err := h.db.ValidUnsubscribeToken(token)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to unsubscribe")
+		return
+	}
+	if !ok {
+		h.errorResponse(w, http.StatusNotFound, "invalid unsubscribe token")
+		return
+	}
+
+	// Links are opened from mail clients, so answer with readable pages
+	if r.Method == http.MethodGet {
+		emailPage(w, `<p>Stop LineFinder alert email, the daily summary and the digest email?</p>`+
+			`<form method="post" action="?token=`+html.EscapeString(url.QueryEscape(token))+`"><button type="submit">Unsubscribe</button></form>`)
+		return
+	}
+
+	prefs, err := h.db.GetPreferences()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+		return
+	}
+	undo := h.saveUndo(w, database.UndoPreferences, prefs)
+	if undo == nil {
+		return
+	}
+	if ok, err := h.db.UnsubscribeEmail(token); err != nil || !ok {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to unsubscribe")
+		return
+	}
+
+	undoBody, _ := json.Marshal(UndoRequest{UndoToken: undo.Token})
+	emailPage(w, `<p>You've been unsubscribed from LineFinder email.</p>`+
+		`<button onclick="fetch('/api/v1/undo', {method: 'POST', headers: {'Content-Type': 'application/json'}, body: '`+html.EscapeString(string(undoBody))+`'})`+
+		`.then(r => { document.body.innerHTML = r.ok ? '<p>Email is back on.</p>' : '<p>Too late to undo: turn email back on in Settings.</p>'; })">Undo</button>`)
+}
+
+// emailPage answers a link opened from an email with a minimal page
+func emailPage(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`<html><body style="font-family:sans-serif">` + body + `</body></html>`))
 }
 
 // handleOdds returns raw odds data for a sport
@@ -452,21 +999,37 @@ func (h *Handler) handleOdds(w http.ResponseWriter, r *http.Request) {
 
 	sport := h.parseSport(r.URL.Path, "/api/odds/")
 	if sport == "" {
-		h.errorResponse(w, http.StatusBadRequest, "invalid sport: use 'nfl' or 'nba'")
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport: use 'nfl', 'nba', or an enabled sport key")
+		return
+	}
+
+	if h.notModified(w, r, h.oddsService.LastChanged(sport), cacheRevalidate) {
 		return
 	}
 
 	games := h.oddsService.GetGamesBySport(sport)
-	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
-		"sport": sport,
-		"count": len(games),
-		"games": games,
+	h.jsonResponse(w, http.StatusOK, OddsResponse{
+		Sport: sport,
+		Count: len(games),
+		Games: games,
 	})
 }
 
-// handleGames returns a summary of games for a sport
-// GET /api/games/{sport}
+// handleGames returns a summary of games for a sport, placed on slates
+// (today, tomorrow, this week) using the preference timezone
+// GET /api/games/{sport}?group=slate|week&tz=America/Chicago
+// Pinned games are under /api/games/pinned and /api/games/{id}/pin.
 func (h *Handler) handleGames(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/games/"), "/")
+	if path == "pinned" {
+		h.handlePinnedGames(w, r)
+		return
+	}
+	if parts := strings.Split(path, "/"); len(parts) == 2 && parts[1] == "pin" {
+		h.handleGamePin(w, r, parts[0])
+		return
+	}
+
 	if r.Method != http.MethodGet {
 		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
@@ -474,11 +1037,35 @@ func (h *Handler) handleGames(w http.ResponseWriter, r *http.Request) {
 
 	sport := h.parseSport(r.URL.Path, "/api/games/")
 	if sport == "" {
-		h.errorResponse(w, http.StatusBadRequest, "invalid sport: use 'nfl' or 'nba'")
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport: use 'nfl', 'nba', or an enabled sport key")
+		return
+	}
+
+	group := r.URL.Query().Get("group")
+	if group != "" && group != "slate" && group != "week" {
+		h.errorResponse(w, http.StatusBadRequest, "group must be 'slate' or 'week'")
+		return
+	}
+	if group == "week" && sport != models.SportNFL {
+		h.errorResponse(w, http.StatusBadRequest, "week grouping is only available for nfl")
+		return
+	}
+
+	loc, err := h.viewerLocation(r)
+	if err != nil {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidTimezone, "invalid timezone")
+		return
+	}
+
+	if h.notModified(w, r, h.gamesModified(r, sport, loc), cacheRevalidate) {
 		return
 	}
 
 	games := h.oddsService.GetGamesBySport(sport)
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].CommenceTime.Before(games[j].CommenceTime)
+	})
+	assignments := slates.Assign(games, sport, h.clock.Now(), loc)
 
 	// Return simplified game list
 	type gameSummary struct {
@@ -487,6 +1074,8 @@ func (h *Handler) handleGames(w http.ResponseWriter, r *http.Request) {
 		AwayTeam       string `json:"away_team"`
 		CommenceTime   string `json:"commence_time"`
 		BookmakerCount int    `json:"bookmaker_count"`
+		slates.Assignment
+		Reference *reference.GameReference `json:"reference,omitempty"`
 	}
 
 	summaries := make([]gameSummary, len(games))
@@ -497,14 +1086,82 @@ func (h *Handler) handleGames(w http.ResponseWriter, r *http.Request) {
 			AwayTeam:       game.AwayTeam,
 			CommenceTime:   game.CommenceTime.Format("2006-01-02 15:04 MST"),
 			BookmakerCount: len(game.Bookmakers),
+			Assignment:     assignments[game.ID],
+			Reference:      h.gameReference(game),
 		}
 	}
 
-	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
-		"sport": sport,
-		"count": len(summaries),
-		"games": summaries,
-	})
+	response := map[string]interface{}{
+		"sport":    sport,
+		"count":    len(summaries),
+		"timezone": loc.String(),
+	}
+
+	if group == "" {
+		response["games"] = summaries
+		h.jsonResponse(w, http.StatusOK, response)
+		return
+	}
+
+	type slateGroup struct {
+		Key   string        `json:"key"`
+		Label string        `json:"label"`
+		Count int           `json:"count"`
+		Games []gameSummary `json:"games"`
+	}
+
+	var groups []*slateGroup
+	index := make(map[string]*slateGroup)
+	if group == "slate" {
+		for _, key := range slates.Order {
+			g := &slateGroup{Key: key, Label: slates.Label(key)}
+			index[key] = g
+			groups = append(groups, g)
+		}
+	}
+
+	// Games are sorted by start time, so weeks are appended in order
+	for _, summary := range summaries {
+		key := summary.Slate
+		label := slates.Label(key)
+		if group == "week" {
+			key = strconv.Itoa(summary.Week)
+			label = slates.WeekLabel(summary.Week)
+		}
+		g, ok := index[key]
+		if !ok {
+			g = &slateGroup{Key: key, Label: label}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.Games = append(g.Games, summary)
+		g.Count++
+	}
+
+	nonEmpty := make([]*slateGroup, 0, len(groups))
+	for _, g := range groups {
+		if g.Count > 0 {
+			nonEmpty = append(nonEmpty, g)
+		}
+	}
+	response["slates"] = nonEmpty
+	h.jsonResponse(w, http.StatusOK, response)
+}
+
+// viewerLocation returns the zone used for day boundaries: the tz query
+// parameter, then the preference timezone, then the server's zone
+func (h *Handler) viewerLocation(r *http.Request) (*time.Location, error) {
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		return time.LoadLocation(tz)
+	}
+	if h.db != nil {
+		if prefs, err := h.db.GetPreferences(); err == nil && prefs.Timezone != "" {
+			if loc, err := time.LoadLocation(prefs.Timezone); err == nil {
+				return loc, nil
+			}
+		}
+	}
+	return time.Local, nil
 }
 
 // handleCompare returns odds comparison for a specific game
@@ -528,7 +1185,24 @@ func (h *Handler) handleCompare(w http.ResponseWriter, r *http.Request) {
 	}
 
 	comparison := h.oddsService.CompareOdds(game)
-	h.jsonResponse(w, http.StatusOK, comparison)
+	if h.db != nil {
+		if prefs, err := h.db.GetPreferences(); err == nil && prefs.MyBook != "" {
+			comparison.MyBook = h.oddsService.CompareMyBook(game, prefs.MyBook)
+		}
+	}
+	response := CompareResponse{OddsComparison: comparison, Reference: h.gameReference(game)}
+	if h.steam != nil {
+		response.Divergences = h.steam.Divergences(game.ID)
+	}
+	if h.closing != nil {
+		projections, err := h.closing.Project(game)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to project closing lines")
+			return
+		}
+		response.ProjectedClose = projections
+	}
+	h.jsonResponse(w, http.StatusOK, response)
 }
 
 // handleRefresh fetches fresh data from the Odds API
@@ -541,11 +1215,15 @@ func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
 
 	sport := h.parseSport(r.URL.Path, "/api/refresh/")
 	if sport == "" {
-		h.errorResponse(w, http.StatusBadRequest, "invalid sport: use 'nfl' or 'nba'")
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport: use 'nfl', 'nba', or an enabled sport key")
 		return
 	}
 
 	games, err := h.oddsService.FetchAndStoreOdds(sport)
+	if errors.Is(err, oddsapi.ErrQuotaExceeded) {
+		h.errorCodeResponse(w, http.StatusTooManyRequests, CodeQuotaExceeded, "failed to fetch odds: "+err.Error())
+		return
+	}
 	if err != nil {
 		h.errorResponse(w, http.StatusInternalServerError, "failed to fetch odds: "+err.Error())
 		return
@@ -570,23 +1248,19 @@ func (h *Handler) handlePlayerProps(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/props/")
 	parts := strings.Split(path, "/")
 	if len(parts) < 2 {
-		h.errorResponse(w, http.StatusBadRequest, "invalid path: use /api/props/{sport}/{gameID}")
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidPath, "invalid path: use /api/v1/props/{sport}/{gameID}")
 		return
 	}
 
 	sportStr := strings.ToLower(parts[0])
 	gameID := parts[1]
 
-	var sport models.Sport
-	switch sportStr {
-	case "nfl":
-		sport = models.SportNFL
-	case "nba":
-		sport = models.SportNBA
-	default:
-		h.errorResponse(w, http.StatusBadRequest, "invalid sport: use 'nfl' or 'nba'")
+	sport, ok := models.ParseSport(sportStr)
+	if !ok {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport: use "+models.SportChoices())
 		return
 	}
+	sportStr = sport.ShortKey()
 
 	// Get actual game data if available
 	game, found := h.oddsService.GetGame(gameID)
@@ -600,15 +1274,18 @@ func (h *Handler) handlePlayerProps(w http.ResponseWriter, r *http.Request) {
 
 	// Return dummy player props data
 	props := store.GetDummyPlayerProps(gameID, sport, homeTeam, awayTeam)
+	if h.depthCharts != nil && sport == models.SportNFL {
+		for i := range props.Players {
+			if role, ok := h.depthCharts.Role(props.Players[i].Name); ok {
+				props.Players[i].Role = role.Label()
+			}
+		}
+	}
 
-	// Check for value alerts if detector is available
+	// Check for value alerts if detector is available, until the game starts
 	var valueAlerts []alerts.ValueAlert
-	if h.alertDetector != nil && found {
-		averages := store.GetDummyPlayerAverages(sportStr)
-		avgMap := make(map[string]map[string]float64)
-		for _, pa := range averages {
-			avgMap[strings.ToLower(pa.Name)] = pa.Averages
-		}
+	if h.alertDetector != nil && found && !game.Started(h.clock.Now()) {
+		averages := h.projections.Apply(store.GetDummyPlayerAverages(sportStr))
 
 		ctx := alerts.GameContext{
 			GameID:   gameID,
@@ -618,52 +1295,24 @@ func (h *Handler) handlePlayerProps(w http.ResponseWriter, r *http.Request) {
 			GameTime: gameTime,
 		}
 
-		for _, player := range props.Players {
-			playerAvg := avgMap[strings.ToLower(player.Name)]
-			if playerAvg == nil {
-				continue
-			}
-
-			for _, prop := range player.Props {
-				avg, ok := playerAvg[prop.Category]
-				if !ok {
-					continue
-				}
-
-				var bestLine, bestOdds float64
-				var bestBook string
-				for _, bm := range prop.Bookmakers {
-					if bestBook == "" || bm.OverPrice > bestOdds {
-						bestLine = bm.Point
-						bestOdds = bm.OverPrice
-						bestBook = bm.Title
-					}
-				}
-
-				propData := alerts.PropData{
-					PlayerName:   player.Name,
-					Team:         player.Team,
-					PropCategory: prop.Category,
-					Line:         bestLine,
-					Average:      avg,
-					BestOdds:     bestOdds,
-					Bookmaker:    bestBook,
-				}
-
-				alert := h.alertDetector.DetectValue(propData, ctx)
-				if alert != nil {
-					valueAlerts = append(valueAlerts, *alert)
-				}
+		// A page view, not a scan: the sport's scan stats are left alone
+		for _, propData := range h.alertDetector.CollectProps(props, averages, nil) {
+			alert := h.alertDetector.DetectValue(propData, ctx)
+			if alert != nil {
+				valueAlerts = append(valueAlerts, *alert)
 			}
 		}
 	}
 
-	response := map[string]interface{}{
-		"game_id":      props.GameID,
-		"home_team":    props.HomeTeam,
-		"away_team":    props.AwayTeam,
-		"players":      props.Players,
-		"value_alerts": valueAlerts,
+	response := PropsResponse{
+		GameID:      props.GameID,
+		HomeTeam:    props.HomeTeam,
+		AwayTeam:    props.AwayTeam,
+		Players:     props.Players,
+		ValueAlerts: valueAlerts,
+	}
+	if h.lineups != nil && sport == models.SportNBA {
+		response.Lineup = h.lineups.Status(gameID)
 	}
 
 	h.jsonResponse(w, http.StatusOK, response)
@@ -681,15 +1330,15 @@ func (h *Handler) handleInjuries(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/injuries/")
 	parts := strings.Split(path, "/")
 	if len(parts) < 2 {
-		h.errorResponse(w, http.StatusBadRequest, "invalid path: use /api/injuries/{sport}/{gameID}")
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidPath, "invalid path: use /api/v1/injuries/{sport}/{gameID}")
 		return
 	}
 
 	sportStr := strings.ToLower(parts[0])
 	gameID := parts[1]
 
-	if sportStr != "nfl" && sportStr != "nba" {
-		h.errorResponse(w, http.StatusBadRequest, "invalid sport: use 'nfl' or 'nba'")
+	if _, ok := models.ParseSport(sportStr); !ok {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport: use "+models.SportChoices())
 		return
 	}
 
@@ -704,6 +1353,10 @@ func (h *Handler) handleInjuries(w http.ResponseWriter, r *http.Request) {
 		awayTeam = "Away Team"
 	}
 
+	if h.notModified(w, r, h.startedAt(), cacheStatic) {
+		return
+	}
+
 	// Return dummy injury data
 	injuries := store.GetDummyInjuries(gameID, homeTeam, awayTeam, sportStr)
 	h.jsonResponse(w, http.StatusOK, injuries)
@@ -721,14 +1374,18 @@ func (h *Handler) handlePlayerAverages(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/averages/")
 	parts := strings.Split(path, "/")
 	if len(parts) < 2 {
-		h.errorResponse(w, http.StatusBadRequest, "invalid path: use /api/averages/{sport}/{gameID}")
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidPath, "invalid path: use /api/v1/averages/{sport}/{gameID}")
 		return
 	}
 
 	sportStr := strings.ToLower(parts[0])
 
-	if sportStr != "nfl" && sportStr != "nba" {
-		h.errorResponse(w, http.StatusBadRequest, "invalid sport: use 'nfl' or 'nba'")
+	if _, ok := models.ParseSport(sportStr); !ok {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport: use "+models.SportChoices())
+		return
+	}
+
+	if h.notModified(w, r, h.startedAt(), cacheStatic) {
 		return
 	}
 
@@ -742,14 +1399,15 @@ func (h *Handler) parseSport(path, prefix string) models.Sport {
 	sportStr := strings.TrimPrefix(path, prefix)
 	sportStr = strings.ToLower(strings.TrimSuffix(sportStr, "/"))
 
-	switch sportStr {
-	case "nfl":
-		return models.SportNFL
-	case "nba":
-		return models.SportNBA
-	default:
-		return ""
+	if sport, ok := models.ParseSport(sportStr); ok {
+		return sport
 	}
+
+	// Other sports are addressed by their full key once enabled
+	if h.sports != nil && h.sports.IsEnabled(models.Sport(sportStr)) {
+		return models.Sport(sportStr)
+	}
+	return ""
 }
 
 func (h *Handler) jsonResponse(w http.ResponseWriter, status int, data interface{}) {
@@ -763,7 +1421,7 @@ func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -773,7 +1431,3 @@ func CORSMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
-
-func (h *Handler) errorResponse(w http.ResponseWriter, status int, message string) {
-	h.jsonResponse(w, status, map[string]string{"error": message})
-}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// oddsSeries is one bookmaker's prices for one outcome over time
+type oddsSeries struct {
+	Bookmaker string           `json:"bookmaker"`
+	Outcome   string           `json:"outcome"`
+	Points    []oddsSeriesItem `json:"points"`
+}
+
+// oddsSeriesItem is a recorded price, with the line for spreads and totals
+type oddsSeriesItem struct {
+	Time  time.Time `json:"time"`
+	Price float64   `json:"price"`
+	Point *float64  `json:"point,omitempty"`
+}
+
+// handleOddsHistory returns a game's recorded odds as a time series per
+// bookmaker and outcome, for charting line movement
+// GET /api/history/{gameID}?market=spreads&book=draftkings
+func (h *Handler) handleOddsHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	gameID := strings.TrimPrefix(r.URL.Path, "/api/history/")
+	if gameID == "" || strings.Contains(gameID, "/") {
+		h.errorResponse(w, http.StatusBadRequest, "game ID required")
+		return
+	}
+
+	market := models.MarketH2H
+	if marketStr := r.URL.Query().Get("market"); marketStr != "" {
+		market = models.Market(strings.ToLower(marketStr))
+	}
+	if market != models.MarketH2H && market != models.MarketSpreads && market != models.MarketTotals {
+		// Half and quarter markets and team totals are recorded once they're
+		// polled
+		if _, err := models.ParseEventMarket(string(market)); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidMarket, "invalid market: use 'h2h', 'spreads', 'totals' or 'team_totals', or one with a period such as 'spreads_h1'")
+			return
+		}
+	}
+	book := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("book")))
+
+	points, err := h.db.GetOddsHistory(gameID, string(market), book)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get odds history")
+		return
+	}
+
+	// Points are only added as prices move (and pruned past retention), so
+	// the latest is when the series last changed
+	var latest time.Time
+	for _, p := range points {
+		if p.RecordedAt.After(latest) {
+			latest = p.RecordedAt
+		}
+	}
+	if h.notModified(w, r, latest, cacheRevalidate) {
+		return
+	}
+
+	// Group points into series, in the order each first appears
+	series := []*oddsSeries{}
+	index := make(map[string]*oddsSeries)
+	for _, p := range points {
+		key := p.Bookmaker + "|" + p.Outcome
+		s, ok := index[key]
+		if !ok {
+			s = &oddsSeries{Bookmaker: p.Bookmaker, Outcome: p.Outcome}
+			index[key] = s
+			series = append(series, s)
+		}
+		s.Points = append(s.Points, oddsSeriesItem{Time: p.RecordedAt, Price: p.Price, Point: p.Point})
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"game_id": gameID,
+		"market":  market,
+		"book":    book,
+		"series":  series,
+		"count":   len(points),
+	})
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+)
+
+// defaultInboxLimit is how many alerts the inbox returns by default
+const defaultInboxLimit = 50
+
+// inboxItem is an alert in the inbox with its read state
+type inboxItem struct {
+	alerts.ValueAlert
+	Read   bool       `json:"read"`
+	ReadAt *time.Time `json:"read_at,omitempty"`
+}
+
+// handleAlertInbox returns stored alerts newest first with their read state
+// and the unread count
+// GET /api/alerts/inbox?unread=true&limit=50
+func (h *Handler) handleAlertInbox(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+	limit := defaultInboxLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidLimit, "invalid limit: must be a positive integer")
+			return
+		}
+		limit = l
+	}
+
+	entries, err := h.db.GetInbox(unreadOnly, limit)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get inbox")
+		return
+	}
+	unread, err := h.db.CountUnreadAlerts()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to count unread alerts")
+		return
+	}
+
+	items := make([]inboxItem, 0, len(entries))
+	for i := range entries {
+		items = append(items, inboxItem{
+			ValueAlert: storedAlert(&entries[i].AlertHistory),
+			Read:       entries[i].ReadAt != nil,
+			ReadAt:     entries[i].ReadAt,
+		})
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"alerts": items,
+		"count":  len(items),
+		"unread": unread,
+	})
+}
+
+// handleAlertRead marks a stored alert as read
+// POST /api/alerts/{id}/read
+func (h *Handler) handleAlertRead(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	found, err := h.db.MarkAlertRead(id)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to mark alert read")
+		return
+	}
+	if !found {
+		h.errorResponse(w, http.StatusNotFound, "alert not found")
+		return
+	}
+
+	h.respondUnread(w, "alert marked read")
+}
+
+// handleAlertInboxReadAll marks every unread alert as read
+// POST /api/alerts/inbox/read
+func (h *Handler) handleAlertInboxReadAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	if _, err := h.db.MarkAllAlertsRead(); err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to mark alerts read")
+		return
+	}
+
+	h.respondUnread(w, "all alerts marked read")
+}
+
+// respondUnread broadcasts the new unread count to other open dashboards
+// and returns it
+func (h *Handler) respondUnread(w http.ResponseWriter, message string) {
+	if h.notificationSvc != nil {
+		h.notificationSvc.PublishUnreadCount()
+	}
+
+	unread, err := h.db.CountUnreadAlerts()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to count unread alerts")
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"message": message,
+		"unread":  unread,
+	})
+}
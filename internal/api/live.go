@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/liveprops"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// SetLiveProps sets the tracker whose prop paces the live props endpoint
+// returns
+func (h *Handler) SetLiveProps(tracker *liveprops.Tracker) {
+	h.liveProps = tracker
+}
+
+// handleLiveProps returns a live NBA game's box score so far against each
+// prop's pre-game line, with the pace each stat is on
+// GET /api/live/props/{gameID}
+func (h *Handler) handleLiveProps(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.liveProps == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "live prop tracking not configured: set SPORTSDATA_API_KEY")
+		return
+	}
+
+	gameID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/live/props/"), "/")
+	if gameID == "" || strings.Contains(gameID, "/") {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidPath, "invalid path: use /api/v1/live/props/{gameID}")
+		return
+	}
+
+	live := h.liveProps.Game(gameID)
+	if live == nil {
+		game, found := h.oddsService.GetGame(gameID)
+		switch {
+		case !found:
+			h.errorResponse(w, http.StatusNotFound, "game not found")
+		case game.SportKey != models.SportNBA:
+			h.errorResponse(w, http.StatusNotFound, "live props are only tracked for NBA games")
+		case !game.Started(h.clock.Now()):
+			h.errorResponse(w, http.StatusNotFound, "game hasn't started")
+		default:
+			h.errorResponse(w, http.StatusNotFound, "no box score for game yet")
+		}
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, live)
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/notifications"
+)
+
+// defaultStatusHours is the window failure counts cover by default
+const defaultStatusHours = 24
+
+// handleNotificationStatus checks every delivery channel and reports its
+// last send and recent failures, so a broken channel shows up before an
+// alert is missed. Checks reach out to the SMTP server, webhooks and
+// Discord, so it needs the admin token.
+// GET /api/notifications/status?hours=24
+func (h *Handler) handleNotificationStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.notificationSvc == nil || h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "notifications not configured")
+		return
+	}
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	hours := defaultStatusHours
+	if hoursStr := r.URL.Query().Get("hours"); hoursStr != "" {
+		n, err := strconv.Atoi(hoursStr)
+		if err != nil || n <= 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidHours, "invalid hours: must be a positive integer")
+			return
+		}
+		hours = n
+	}
+	since := h.clock.Now().Add(-time.Duration(hours) * time.Hour)
+
+	channels, err := h.notificationSvc.ChannelStatuses(since)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to check notification channels")
+		return
+	}
+
+	// Healthy when every channel in use passes its check
+	healthy := true
+	for _, ch := range channels {
+		if ch.Enabled && !ch.OK {
+			healthy = false
+		}
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"healthy":  healthy,
+		"channels": channels,
+		"since":    since,
+	})
+}
+
+// handleNotificationLog lists recent deliveries, newest first, with their
+// status, attempts and last error, for tracing a missed alert. Retrying
+// deliveries show when they're next tried. Payloads can hold alert
+// details, so it needs the admin token; /api/admin/notifications is an
+// alias.
+// GET /api/notifications/log?channel=push&status=dead_letter&limit=50
+func (h *Handler) handleNotificationLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	channel := query.Get("channel")
+	if channel != "" && !notifications.ValidChannel(channel) {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidChannel, "invalid channel: use "+strings.Join(notifications.Channels, ", "))
+		return
+	}
+	status := query.Get("status")
+	if status != "" && !database.ValidNotificationStatus(status) {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidStatus, "invalid status: use 'sent', 'retrying' or 'dead_letter'")
+		return
+	}
+
+	limit := 50
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidLimit, "invalid limit: must be a positive integer")
+			return
+		}
+		limit = l
+	}
+
+	entries, err := h.db.GetNotificationLog(channel, status, limit)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to load notification log")
+		return
+	}
+	if entries == nil {
+		entries = []database.NotificationLogEntry{}
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
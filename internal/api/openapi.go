@@ -0,0 +1,48 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// swaggerPage is Swagger UI pointed at /api/v1/openapi.json. Its scripts and
+// styles load from unpkg.
+//
+//go:embed swagger.html
+var swaggerPage []byte
+
+// SetOpenAPISpec sets the OpenAPI document served at /api/openapi.json,
+// generated from the handler layer's types by internal/contract
+func (h *Handler) SetOpenAPISpec(spec []byte) {
+	h.openAPISpec = spec
+}
+
+// handleOpenAPI returns the OpenAPI document
+// GET /api/openapi.json
+func (h *Handler) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.openAPISpec == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "API description not configured")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(h.openAPISpec)
+}
+
+// handleDocs serves Swagger UI for the OpenAPI document
+// GET /api/docs
+func (h *Handler) handleDocs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(swaggerPage)
+}
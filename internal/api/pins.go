@@ -0,0 +1,166 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/store"
+)
+
+// PinnedGameDetail is a pinned game with what was kept of it: its last odds,
+// player props and the alerts sent for it with their outcomes
+type PinnedGameDetail struct {
+	database.PinnedGame
+	Game   *models.Game            `json:"game"`
+	Props  *models.GamePlayerProps `json:"props"`
+	Alerts []PinnedAlert           `json:"alerts"`
+}
+
+// PinnedAlert is an alert sent for a pinned game and the feedback on it,
+// which holds the bet outcome when one was reported
+type PinnedAlert struct {
+	database.AlertHistory
+	Feedback *database.AlertFeedback `json:"feedback,omitempty"`
+}
+
+// handlePinnedGames lists pinned games with their kept data
+// GET /api/games/pinned
+func (h *Handler) handlePinnedGames(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	pins, err := h.db.GetPinnedGames()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get pinned games")
+		return
+	}
+	games := make([]PinnedGameDetail, 0, len(pins))
+	for _, pin := range pins {
+		detail, err := h.pinnedGameDetail(pin)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get pinned game")
+			return
+		}
+		games = append(games, detail)
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"games": games,
+		"count": len(games),
+	})
+}
+
+// handleGamePin pins or unpins a game. A pinned game stays available once
+// it's final: GET /api/games/pinned returns its closing odds, props and
+// alerts, and its odds history and alerts aren't cleaned up.
+// POST   /api/games/{id}/pin
+// DELETE /api/games/{id}/pin
+func (h *Handler) handleGamePin(w http.ResponseWriter, r *http.Request, gameID string) {
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		game, err := h.findGame(gameID)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get game")
+			return
+		}
+		if game == nil {
+			h.errorResponse(w, http.StatusNotFound, "game not found")
+			return
+		}
+
+		// Keep the props as served now, unless bootstrap already stored some
+		props, err := h.db.GetPropSnapshot(gameID)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get props")
+			return
+		}
+		if props == nil {
+			props = store.GetDummyPlayerProps(gameID, game.SportKey, game.HomeTeam, game.AwayTeam)
+			if err := h.db.SavePropSnapshot(game.SportKey, props); err != nil {
+				h.errorResponse(w, http.StatusInternalServerError, "failed to save props")
+				return
+			}
+		}
+
+		pin, err := h.db.PinGame(gameID, game.SportKey)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to pin game")
+			return
+		}
+		h.oddsService.PinGame(gameID)
+
+		detail, err := h.pinnedGameDetail(*pin)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get pinned game")
+			return
+		}
+		h.jsonResponse(w, http.StatusCreated, detail)
+
+	case http.MethodDelete:
+		found, err := h.db.UnpinGame(gameID)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to unpin game")
+			return
+		}
+		if !found {
+			h.errorResponse(w, http.StatusNotFound, "game not pinned")
+			return
+		}
+		h.oddsService.UnpinGame(gameID)
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{"game_id": gameID, "pinned": false})
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// findGame returns a game from the store, or its last snapshot once it's
+// gone, or nil when neither has it
+func (h *Handler) findGame(gameID string) (*models.Game, error) {
+	if game, found := h.oddsService.GetGame(gameID); found {
+		return &game, nil
+	}
+	return h.db.GetGameSnapshot(gameID)
+}
+
+// pinnedGameDetail gathers what's kept of a pinned game
+func (h *Handler) pinnedGameDetail(pin database.PinnedGame) (PinnedGameDetail, error) {
+	detail := PinnedGameDetail{PinnedGame: pin, Alerts: []PinnedAlert{}}
+
+	var err error
+	if detail.Game, err = h.findGame(pin.GameID); err != nil {
+		return detail, err
+	}
+	if detail.Props, err = h.db.GetPropSnapshot(pin.GameID); err != nil {
+		return detail, err
+	}
+
+	history, err := h.db.GetGameAlerts(pin.GameID)
+	if err != nil {
+		return detail, err
+	}
+	feedback, err := h.db.GetGameFeedback(pin.GameID)
+	if err != nil {
+		return detail, err
+	}
+	for _, alert := range history {
+		pa := PinnedAlert{AlertHistory: alert}
+		if f, ok := feedback[alert.ID]; ok {
+			pa.Feedback = &f
+		}
+		detail.Alerts = append(detail.Alerts, pa)
+	}
+	return detail, nil
+}
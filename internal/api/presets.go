@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/database"
+)
+
+// presetNamePattern keeps custom preset names short and usable in a path
+var presetNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,39}$`)
+
+// handlePresets lists the presets, or saves the current alert settings as
+// a custom preset
+// GET  /api/preferences/presets
+// POST /api/preferences/presets {"name": "weekend"}
+func (h *Handler) handlePresets(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		custom, err := h.db.GetPreferencePresets()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get presets")
+			return
+		}
+		presets := append(append([]database.PreferencePreset{}, database.BuiltInPresets...), custom...)
+		h.jsonResponse(w, http.StatusOK, PresetsResponse{Presets: presets})
+
+	case http.MethodPost:
+		var body SavePresetRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+		name := strings.ToLower(strings.TrimSpace(body.Name))
+		if !presetNamePattern.MatchString(name) {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidName, "invalid name: use up to 40 lowercase letters, digits, '-' or '_'")
+			return
+		}
+		if database.BuiltInPreset(name) != nil {
+			h.errorResponse(w, http.StatusConflict, "'"+name+"' is a built-in preset: choose another name")
+			return
+		}
+
+		prefs, err := h.db.GetPreferences()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+			return
+		}
+		preset := database.PresetFromPreferences(name, prefs)
+		if err := h.db.SavePreferencePreset(&preset); err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to save preset")
+			return
+		}
+		h.jsonResponse(w, http.StatusCreated, preset)
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handlePreset deletes a custom preset, restorable with the undo_token
+// DELETE /api/preferences/presets/{name}
+func (h *Handler) handlePreset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/preferences/presets/"), "/")
+	if database.BuiltInPreset(name) != nil {
+		h.errorResponse(w, http.StatusConflict, "built-in presets can't be deleted")
+		return
+	}
+	preset, err := h.db.GetPreferencePreset(name)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get preset")
+		return
+	}
+	if preset == nil {
+		h.errorResponse(w, http.StatusNotFound, "preset not found")
+		return
+	}
+	undo := h.saveUndo(w, database.UndoPreset, preset)
+	if undo == nil {
+		return
+	}
+	if _, err := h.db.DeletePreferencePreset(name); err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to delete preset")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, undoable("preset deleted", undo))
+}
+
+// handleApplyPreset sets the alert settings from a built-in or custom
+// preset in one call. Channels, limits and everything else stay as they
+// are.
+// POST /api/preferences/preset/{name}
+func (h *Handler) handleApplyPreset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/preferences/preset/"), "/")
+	preset := database.BuiltInPreset(name)
+	if preset == nil {
+		var err error
+		if preset, err = h.db.GetPreferencePreset(name); err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get preset")
+			return
+		}
+	}
+	if preset == nil {
+		h.errorResponse(w, http.StatusNotFound, "preset not found: use conservative, balanced, aggressive or a saved preset")
+		return
+	}
+
+	prefs, err := h.db.GetPreferences()
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+		return
+	}
+	preset.Apply(prefs)
+	if err := h.db.UpdatePreferences(prefs); err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to update preferences")
+		return
+	}
+	h.applyPreferences(prefs)
+
+	h.jsonResponse(w, http.StatusOK, PresetResponse{
+		Message:     "preset applied",
+		Preset:      *preset,
+		Preferences: prefs,
+	})
+}
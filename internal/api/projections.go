@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/projections"
+)
+
+// maxProjectionsPerUpload caps the rows accepted in one upload
+const maxProjectionsPerUpload = 5000
+
+// SetProjections sets the blender applying external projections to player
+// averages
+func (h *Handler) SetProjections(b *projections.Blender) {
+	h.projections = b
+}
+
+// SetProjectionsToken sets the bearer token required to upload
+// projections. Uploads fall back to the admin token when it's empty.
+func (h *Handler) SetProjectionsToken(token string) {
+	h.projectionsToken = token
+}
+
+// requireProjectionsToken checks the projections bearer token, writing an
+// error response and returning false when the request isn't authorized
+func (h *Handler) requireProjectionsToken(w http.ResponseWriter, r *http.Request) bool {
+	token := h.projectionsToken
+	if token == "" {
+		token = h.adminToken
+	}
+	if token == "" {
+		h.errorResponse(w, http.StatusForbidden, "projections API disabled: set PROJECTIONS_TOKEN")
+		return false
+	}
+	return h.checkBearer(w, r, token, "invalid projections token")
+}
+
+// handleProjections lists, uploads or deletes external projections
+// GET    /api/projections
+// POST   /api/projections {"source": "model", "projections": [{"player", "category", "projection", "stddev", "source"}]}
+// DELETE /api/projections?source=model
+func (h *Handler) handleProjections(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		stored, err := h.db.GetProjections()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get projections")
+			return
+		}
+		if stored == nil {
+			stored = []database.Projection{}
+		}
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"projections": stored,
+			"count":       len(stored),
+		})
+
+	case http.MethodPost:
+		if !h.requireProjectionsToken(w, r) {
+			return
+		}
+
+		var body struct {
+			Source      string                `json:"source"`
+			Projections []database.Projection `json:"projections"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+		if len(body.Projections) == 0 {
+			h.errorResponse(w, http.StatusBadRequest, "projections required")
+			return
+		}
+		if len(body.Projections) > maxProjectionsPerUpload {
+			h.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("too many projections: at most %d per upload", maxProjectionsPerUpload))
+			return
+		}
+
+		for i := range body.Projections {
+			p := &body.Projections[i]
+			if p.Source == "" {
+				p.Source = body.Source
+			}
+			if err := projections.Validate(p); err != nil {
+				h.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("projections[%d]: %v", i, err))
+				return
+			}
+		}
+
+		if err := h.db.SaveProjections(body.Projections); err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to save projections")
+			return
+		}
+
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"message": "projections saved",
+			"count":   len(body.Projections),
+		})
+
+	case http.MethodDelete:
+		if !h.requireProjectionsToken(w, r) {
+			return
+		}
+
+		source := r.URL.Query().Get("source")
+		if source == "" {
+			h.errorResponse(w, http.StatusBadRequest, "source required")
+			return
+		}
+		deleted, err := h.db.DeleteProjections(source)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to delete projections")
+			return
+		}
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"message": "projections deleted",
+			"count":   deleted,
+		})
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// defaultCSVExpiryHours is how long CSV uploads apply when no expiry is given
+const defaultCSVExpiryHours = 24
+
+// maxCSVBytes caps the size of a CSV upload
+const maxCSVBytes = 1 << 20
+
+// handleProjectionsUpload parses a CSV of projections or line targets and
+// saves it, or with preview=true returns the parsed rows without saving.
+// Uploads with invalid rows are rejected. expires_hours=0 never expires.
+// POST /api/projections/upload?source=mine&expires_hours=24&preview=true (body: CSV)
+func (h *Handler) handleProjectionsUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	if !h.requireProjectionsToken(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	source := query.Get("source")
+	if source == "" {
+		source = "csv"
+	}
+	preview := query.Get("preview") == "true"
+
+	hours := defaultCSVExpiryHours
+	if hoursStr := query.Get("expires_hours"); hoursStr != "" {
+		n, err := strconv.Atoi(hoursStr)
+		if err != nil || n < 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidExpiresHours, "invalid expires_hours: must be a non-negative integer")
+			return
+		}
+		hours = n
+	}
+	var expiresAt *time.Time
+	if hours > 0 {
+		t := h.clock.Now().Add(time.Duration(hours) * time.Hour)
+		expiresAt = &t
+	}
+
+	rows, rowErrors, err := projections.ParseCSV(http.MaxBytesReader(w, r.Body, maxCSVBytes), source, expiresAt)
+	if err != nil {
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if rows == nil {
+		rows = []database.Projection{}
+	}
+	if rowErrors == nil {
+		rowErrors = []projections.RowError{}
+	}
+
+	result := map[string]interface{}{
+		"preview":    preview,
+		"source":     source,
+		"expires_at": expiresAt,
+		"rows":       rows,
+		"count":      len(rows),
+		"errors":     rowErrors,
+	}
+
+	switch {
+	case preview:
+		h.jsonResponse(w, http.StatusOK, result)
+	case len(rowErrors) > 0:
+		result["error"] = fmt.Sprintf("%d invalid rows: fix them or preview the upload", len(rowErrors))
+		h.jsonResponse(w, http.StatusBadRequest, result)
+	case len(rows) == 0:
+		h.errorResponse(w, http.StatusBadRequest, "no rows to upload")
+	case len(rows) > maxProjectionsPerUpload:
+		h.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("too many rows: at most %d per upload", maxProjectionsPerUpload))
+	default:
+		if err := h.db.SaveProjections(rows); err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to save projections")
+			return
+		}
+		result["message"] = "projections saved"
+		h.jsonResponse(w, http.StatusOK, result)
+	}
+}
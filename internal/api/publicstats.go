@@ -0,0 +1,92 @@
+package api
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// publicStatsTTL is how long public stats are served before being
+// recomputed, so unauthenticated traffic can't load the database
+const publicStatsTTL = 5 * time.Minute
+
+// publicStats caches the public stats response
+type publicStats struct {
+	mu       sync.Mutex
+	response *PublicStatsResponse
+}
+
+// EnablePublicStats serves GET /api/stats/public. Without it the endpoint
+// doesn't exist.
+func (h *Handler) EnablePublicStats() {
+	h.publicStats = &publicStats{}
+}
+
+// handlePublicStats returns coarse stats about the service: games tracked,
+// alerts fired today and how often alerts with a reported outcome won.
+// Unauthenticated and cacheable, for a public landing page.
+// GET /api/stats/public
+func (h *Handler) handlePublicStats(w http.ResponseWriter, r *http.Request) {
+	if h.publicStats == nil {
+		h.handleNotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	h.publicStats.mu.Lock()
+	stats := h.publicStats.response
+	if stats == nil || h.clock.Now().Sub(stats.UpdatedAt) >= publicStatsTTL {
+		stats = h.buildPublicStats()
+		h.publicStats.response = stats
+	}
+	h.publicStats.mu.Unlock()
+
+	if h.notModified(w, r, stats.UpdatedAt, cachePublicStats) {
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, stats)
+}
+
+// buildPublicStats computes the public stats. Figures that can't be read
+// are left at zero rather than failing a public page.
+func (h *Handler) buildPublicStats() *PublicStatsResponse {
+	now := h.clock.Now()
+	stats := &PublicStatsResponse{
+		GamesTracked: len(h.oddsService.GetAllGames()),
+		UpdatedAt:    now,
+	}
+	if h.db == nil {
+		return stats
+	}
+
+	// Today in the preferred timezone, as for the daily alert cap
+	loc := time.Local
+	if prefs, err := h.db.GetPreferences(); err == nil {
+		loc = prefs.Location()
+	}
+	local := now.In(loc)
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	if count, err := h.db.CountAlertsSince(dayStart); err != nil {
+		log.Printf("Public stats: failed to count alerts: %v", err)
+	} else {
+		stats.AlertsToday = count
+	}
+
+	wins, losses, err := h.db.CountFeedbackOutcomes()
+	if err != nil {
+		log.Printf("Public stats: failed to count outcomes: %v", err)
+		return stats
+	}
+	stats.GradedAlerts = wins + losses
+	if stats.GradedAlerts > 0 {
+		rate := math.Round(float64(wins)/float64(stats.GradedAlerts)*1000) / 10
+		stats.AlertHitRate = &rate
+	}
+	return stats
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/metrics"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// defaultHoldDays is how far back the hold report looks by default
+const defaultHoldDays = 30
+
+// handleHoldReport ranks bookmakers by average hold per market over stored odds
+// GET /api/reports/hold?days=30&sport=nba
+func (h *Handler) handleHoldReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.reports == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "reports not configured")
+		return
+	}
+
+	days := defaultHoldDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		d, err := strconv.Atoi(daysStr)
+		if err != nil || d <= 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidDays, "invalid days: must be a positive integer")
+			return
+		}
+		days = d
+	}
+
+	var sport models.Sport
+	if sportStr := r.URL.Query().Get("sport"); sportStr != "" {
+		sport = h.parseSport(sportStr, "")
+		if sport == "" {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport: use 'nfl', 'nba', or an enabled sport key")
+			return
+		}
+	}
+
+	since := h.clock.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	report, err := h.reports.BuildHoldReport(since, sport)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to build report")
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, report)
+}
+
+// handleCoverageReport returns how often each allowed bookmaker appeared in
+// polls per sport and market since startup, with the books that have gaps
+// GET /api/reports/coverage
+func (h *Handler) handleCoverageReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	coverage := h.metrics.BookCoverage()
+	var gaps []metrics.BookCoverage
+	for _, c := range coverage {
+		if c.Present < c.Polls {
+			gaps = append(gaps, c)
+		}
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"bookmakers": coverage,
+		"gaps":       gaps,
+	})
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/closing"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/lineups"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/reference"
+	"github.com/joshuakim/linefinder/internal/steam"
+)
+
+// Response bodies the frontend reads. They're part of the API contract
+// (see internal/contract), so the generated TypeScript types follow them.
+
+// OddsResponse is a sport's games with their odds
+// GET /api/odds/{sport}
+type OddsResponse struct {
+	Sport models.Sport  `json:"sport"`
+	Count int           `json:"count"`
+	Games []models.Game `json:"games"`
+}
+
+// PropsResponse is a game's player props, the value alerts found in them,
+// and for NBA games the starting lineups once known
+// GET /api/props/{sport}/{gameID}
+type PropsResponse struct {
+	GameID      string                   `json:"game_id"`
+	HomeTeam    string                   `json:"home_team"`
+	AwayTeam    string                   `json:"away_team"`
+	Players     []models.PlayerWithProps `json:"players"`
+	ValueAlerts []alerts.ValueAlert      `json:"value_alerts"`
+	Lineup      *lineups.Status          `json:"lineup,omitempty"`
+}
+
+// CompareResponse is a game's best prices across bookmakers, with its
+// reference line when one is available, any sharp divergences standing on
+// it and each outcome's projected closing line
+// GET /api/compare/{gameID}
+type CompareResponse struct {
+	models.OddsComparison
+	Reference      *reference.GameReference `json:"reference,omitempty"`
+	Divergences    []steam.SharpDivergence  `json:"divergences,omitempty"`
+	ProjectedClose []closing.Projection     `json:"projected_close,omitempty"`
+}
+
+// CheckAlertsResponse is the value alerts found in a sport's upcoming games
+// GET /api/alerts/check
+type CheckAlertsResponse struct {
+	Sport              string              `json:"sport"`
+	Games              int                 `json:"games"`
+	GamesOutsideWindow int                 `json:"games_outside_window"`
+	Alerts             []alerts.ValueAlert `json:"alerts"`
+	AlertCount         int                 `json:"alert_count"`
+}
+
+// TopPlaysResponse is a sport's best current value opportunities
+// GET /api/topplays?sport=nba&limit=10
+type TopPlaysResponse struct {
+	Sport     string              `json:"sport"`
+	Games     int                 `json:"games"`
+	Plays     []alerts.RankedPlay `json:"plays"`
+	Count     int                 `json:"count"`
+	Available int                 `json:"available"` // value opportunities before the limit
+}
+
+// SteamResponse is the steam found recently on upcoming games
+// GET /api/steam?sport=nba
+type SteamResponse struct {
+	Sport string        `json:"sport,omitempty"`
+	Steam []steam.Steam `json:"steam"`
+	Count int           `json:"count"`
+}
+
+// AlertsResponse is stored alerts looked up by ID
+// GET /api/alerts?ids=12,15
+type AlertsResponse struct {
+	Alerts []database.AlertHistory `json:"alerts"`
+	Count  int                     `json:"count"`
+}
+
+// AlertFeedbackRequest rates an alert
+// POST /api/alerts/{id}/feedback
+type AlertFeedbackRequest struct {
+	Rating  string  `json:"rating"` // useful, not_useful or bet_it
+	Outcome string  `json:"outcome"`
+	Note    string  `json:"note"`
+	Stake   float64 `json:"stake"` // bet_it only
+}
+
+// AlertFeedbackResponse is recorded feedback and the alert's state after it
+// POST /api/alerts/{id}/feedback
+type AlertFeedbackResponse struct {
+	Message  string                  `json:"message"`
+	Feedback *database.AlertFeedback `json:"feedback"`
+	State    string                  `json:"state"`
+
+	// Set when a bet_it stake passes the user's limits
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// MessageResponse confirms an action
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// UndoableResponse confirms a destructive change with a token that reverses
+// it through /api/undo until it expires
+// PUT /api/preferences, POST /api/unsubscribe,
+// DELETE /api/preferences/presets/{name}, DELETE /api/webhooks/{id}
+type UndoableResponse struct {
+	Message       string    `json:"message"`
+	UndoToken     string    `json:"undo_token"`
+	UndoExpiresAt time.Time `json:"undo_expires_at"`
+}
+
+// UndoRequest names the change to reverse
+// POST /api/undo
+type UndoRequest struct {
+	UndoToken string `json:"undo_token"`
+}
+
+// UndoResponse confirms what an undo restored: "preferences", "preset" or
+// "webhook"
+// POST /api/undo
+type UndoResponse struct {
+	Message string `json:"message"`
+	Kind    string `json:"kind"`
+}
+
+// PresetsResponse is the built-in presets followed by the custom ones
+// GET /api/preferences/presets
+type PresetsResponse struct {
+	Presets []database.PreferencePreset `json:"presets"`
+}
+
+// SavePresetRequest names a custom preset of the current alert settings
+// POST /api/preferences/presets
+type SavePresetRequest struct {
+	Name string `json:"name"`
+}
+
+// PresetResponse is the preferences after a preset is applied
+// POST /api/preferences/preset/{name}
+type PresetResponse struct {
+	Message     string                    `json:"message"`
+	Preset      database.PreferencePreset `json:"preset"`
+	Preferences *database.Preferences     `json:"preferences"`
+}
+
+// SportPreferences is whether alerts are on for each sport, by short key
+// GET, PUT /api/preferences/sports
+type SportPreferences struct {
+	Sports map[string]bool `json:"sports"`
+}
+
+// AlertSubscriptions is whether each alert category is delivered over each
+// channel
+// GET, PUT /api/preferences/subscriptions
+type AlertSubscriptions struct {
+	Subscriptions map[string]map[string]bool `json:"subscriptions"`
+}
+
+// DigestResponse lists the channels the digest was queued for
+// POST /api/digest
+type DigestResponse struct {
+	Message  string   `json:"message"`
+	Channels []string `json:"channels"`
+}
+
+// VAPIDKeyResponse is the key browsers subscribe to push notifications with
+// GET /api/vapid-public-key
+type VAPIDKeyResponse struct {
+	PublicKey string `json:"publicKey"`
+}
+
+// PublicStatsResponse is coarse stats about the service for a public
+// landing page. AlertHitRate is left out until outcomes are reported.
+// GET /api/stats/public
+type PublicStatsResponse struct {
+	GamesTracked int       `json:"games_tracked"`
+	AlertsToday  int       `json:"alerts_today"`
+	AlertHitRate *float64  `json:"alert_hit_rate_percent,omitempty"`
+	GradedAlerts int       `json:"graded_alerts"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ErrorResponse is the body of every error. Code is machine-readable, such
+// as invalid_sport or not_found; Error says what went wrong.
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
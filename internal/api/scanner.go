@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/joshuakim/linefinder/internal/scanner"
+)
+
+// SetScanner sets the alert scanner controlled by the scanner endpoints
+func (h *Handler) SetScanner(s *scanner.Scanner) {
+	h.scanner = s
+}
+
+// handleScannerStatus returns the alert scanner's state and counters
+// GET /api/scanner/status
+func (h *Handler) handleScannerStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.scanner == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "alert scanner not configured")
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, h.scanner.Status())
+}
+
+// handleScannerEnable enables alert scanning
+// POST /api/scanner/enable
+func (h *Handler) handleScannerEnable(w http.ResponseWriter, r *http.Request) {
+	h.setScannerEnabled(w, r, true)
+}
+
+// handleScannerDisable disables alert scanning without affecting polling
+// POST /api/scanner/disable
+func (h *Handler) handleScannerDisable(w http.ResponseWriter, r *http.Request) {
+	h.setScannerEnabled(w, r, false)
+}
+
+func (h *Handler) setScannerEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.scanner == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "alert scanner not configured")
+		return
+	}
+
+	message := "alert scanner disabled"
+	if enabled {
+		h.scanner.Enable()
+		message = "alert scanner enabled"
+	} else {
+		h.scanner.Disable()
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"message": message,
+		"status":  h.scanner.Status(),
+	})
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// handleSportPreferences shows or changes which sports alerts are on for.
+// PUT only changes the sports it names.
+// GET /api/preferences/sports
+// PUT /api/preferences/sports {"sports": {"nfl": false}}
+func (h *Handler) handleSportPreferences(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		prefs, err := h.db.GetPreferences()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+			return
+		}
+		h.jsonResponse(w, http.StatusOK, sportFlags(prefs.Sports))
+
+	case http.MethodPut:
+		var body SportPreferences
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+		prefs, err := h.db.GetPreferences()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+			return
+		}
+
+		flags := sportFlags(prefs.Sports)
+		for name, enabled := range body.Sports {
+			info, ok := models.LookupSport(name)
+			if !ok {
+				h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport '"+name+"': use "+models.SportChoices())
+				return
+			}
+			flags.Sports[info.Key] = enabled
+		}
+
+		var sports []string
+		for _, info := range models.Sports() {
+			if flags.Sports[info.Key] {
+				sports = append(sports, info.Key)
+			}
+		}
+		// An empty list means every sport, so it can't stand for none
+		if len(sports) == 0 {
+			h.errorResponse(w, http.StatusBadRequest, "at least one sport must stay enabled")
+			return
+		}
+
+		prefs.Sports = sports
+		if err := h.db.UpdatePreferences(prefs); err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to update preferences")
+			return
+		}
+		h.applyPreferences(prefs)
+
+		h.jsonResponse(w, http.StatusOK, flags)
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// sportFlags turns the preferred sports list into a flag per registered
+// sport. An empty list enables them all.
+func sportFlags(sports []string) SportPreferences {
+	flags := SportPreferences{Sports: make(map[string]bool)}
+	for _, info := range models.Sports() {
+		flags.Sports[info.Key] = len(sports) == 0
+	}
+	for _, s := range sports {
+		if info, ok := models.LookupSport(s); ok {
+			flags.Sports[info.Key] = true
+		}
+	}
+	return flags
+}
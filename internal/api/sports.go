@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/service"
+)
+
+// SetSportsCatalog sets the catalog used for sport discovery and lets
+// enabled sports be addressed by their full key
+func (h *Handler) SetSportsCatalog(catalog *service.SportsCatalog) {
+	h.sports = catalog
+}
+
+// handleSports lists the sports offered upstream, or enables/disables one
+// GET /api/sports?refresh=true
+// PUT /api/sports {"sport": "icehockey_nhl", "enabled": true} (admin)
+func (h *Handler) handleSports(w http.ResponseWriter, r *http.Request) {
+	if h.sports == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "sports catalog not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listings, err := h.sports.List(r.URL.Query().Get("refresh") == "true")
+		if err != nil {
+			h.errorResponse(w, http.StatusBadGateway, "failed to fetch sports list: "+err.Error())
+			return
+		}
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"count":      len(listings),
+			"enabled":    h.sports.Enabled(),
+			"sports":     listings,
+			"registered": models.Sports(),
+		})
+
+	case http.MethodPut:
+		if !h.requireAdmin(w, r) {
+			return
+		}
+
+		var body struct {
+			Sport   string `json:"sport"`
+			Enabled *bool  `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+		if body.Sport == "" || body.Enabled == nil {
+			h.errorResponse(w, http.StatusBadRequest, "sport and enabled are required")
+			return
+		}
+
+		sport := models.Sport(body.Sport)
+		if registered, ok := models.ParseSport(body.Sport); ok {
+			sport = registered
+		}
+		enabled, err := h.sports.SetEnabled(sport, *body.Enabled)
+		if errors.Is(err, service.ErrUnknownSport) {
+			h.errorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to update sport: "+err.Error())
+			return
+		}
+
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"sport":   sport,
+			"enabled": enabled,
+		})
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// standby holds a warm standby's state. A standby serves reads, WebSocket
+// clients and history but doesn't poll, notify or accept writes until it's
+// activated.
+type standby struct {
+	mu       sync.Mutex
+	active   bool
+	activate func() error
+}
+
+// SetStandby puts the handler in standby mode. activate makes the instance
+// the active one; it's called once, by POST /api/admin/activate.
+func (h *Handler) SetStandby(activate func() error) {
+	h.standby = &standby{activate: activate}
+}
+
+// inStandby reports whether the instance is a standby that hasn't been
+// activated
+func (h *Handler) inStandby() bool {
+	if h.standby == nil {
+		return false
+	}
+	h.standby.mu.Lock()
+	defer h.standby.mu.Unlock()
+	return !h.standby.active
+}
+
+// StandbyGuard rejects API requests that write while the instance is in
+// standby, so changes go to the active instance. Admin endpoints are let
+// through. /api/alerts/check is a GET but records alerts, which would stop
+// the active instance sending them, so it's rejected too.
+func (h *Handler) StandbyGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := unversioned(r.URL.Path)
+		if h.inStandby() && strings.HasPrefix(path, "/api/") && !strings.HasPrefix(path, "/api/admin/") {
+			write := r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions
+			if write || path == "/api/alerts/check" {
+				h.errorResponse(w, http.StatusServiceUnavailable, "instance is in standby: send writes to the active instance")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleAdminStandby reports whether the instance is in standby
+// GET /api/admin/standby
+func (h *Handler) handleAdminStandby(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"standby_mode": h.standby != nil,
+		"standby":      h.inStandby(),
+	})
+}
+
+// handleAdminActivate switches a standby to active: it takes the poller
+// lock from the current poller, which stops, and starts polling and
+// notifications here
+// POST /api/admin/activate
+func (h *Handler) handleAdminActivate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	if h.standby == nil {
+		h.errorResponse(w, http.StatusConflict, "instance isn't in standby mode: set STANDBY=true")
+		return
+	}
+
+	h.standby.mu.Lock()
+	defer h.standby.mu.Unlock()
+	if h.standby.active {
+		h.errorResponse(w, http.StatusConflict, "instance is already active")
+		return
+	}
+	if err := h.standby.activate(); err != nil {
+		h.errorResponse(w, http.StatusBadGateway, "failed to take poller lock: "+err.Error())
+		return
+	}
+	h.standby.active = true
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"message":      "instance active",
+		"standby_mode": true,
+		"standby":      false,
+	})
+}
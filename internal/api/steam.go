@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/steam"
+)
+
+// SetSteamDetector sets the detector whose steam the steam endpoint returns
+func (h *Handler) SetSteamDetector(detector *steam.Detector) {
+	h.steam = detector
+}
+
+// handleSteam returns the steam found in the last few hours on games that
+// haven't started, newest first. Without ?sport= every sport is listed.
+// GET /api/steam?sport=nba
+func (h *Handler) handleSteam(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.steam == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "steam detector not configured")
+		return
+	}
+
+	var sport models.Sport
+	sportStr := r.URL.Query().Get("sport")
+	if sportStr != "" {
+		var ok bool
+		if sport, ok = models.ParseSport(sportStr); !ok {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport: use "+models.SportChoices())
+			return
+		}
+		sportStr = sport.ShortKey()
+	}
+
+	moves := h.steam.Recent(sport)
+	h.jsonResponse(w, http.StatusOK, SteamResponse{
+		Sport: sportStr,
+		Steam: moves,
+		Count: len(moves),
+	})
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/alertstream"
+)
+
+// streamHeartbeat is how often an idle alert stream sends a comment, so
+// proxies don't close it
+const streamHeartbeat = 30 * time.Second
+
+// SetAlertStream sets the stream served by /api/alerts/stream
+func (h *Handler) SetAlertStream(stream *alertstream.Stream) {
+	h.alertStream = stream
+}
+
+// handleAlertStream streams every alert type across all sports as
+// server-sent events, one event per alert named after its type. Filters
+// are comma-separated. When WebSocket clients have to authenticate, so do
+// streams, with one of the same tokens as a bearer token or in the URL,
+// since EventSource can't set headers.
+// GET /api/alerts/stream?types=value,line_move&categories=injury&sports=nba,nfl&token=...
+func (h *Handler) handleAlertStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.hub != nil && h.hub.AuthRequired() {
+		token := r.URL.Query().Get("token")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			token = bearer
+		}
+		if _, ok := h.hub.Identify(token); !ok {
+			h.errorResponse(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+	}
+
+	if h.alertStream == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "alert stream not configured")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.errorResponse(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := alertstream.NewFilter(splitList(query.Get("types")), splitList(query.Get("categories")), splitList(query.Get("sports")))
+	alerts, unsubscribe := h.alertStream.Subscribe(filter)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case alert, ok := <-alerts:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(alert)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", alert.Type, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// splitList splits a comma-separated query value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshuakim/linefinder/internal/alertstream"
+	"github.com/joshuakim/linefinder/internal/metrics"
+	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+// TestAlertStreamTokens checks the alert stream takes the WebSocket tokens,
+// so it can't be used to get around them
+func TestAlertStreamTokens(t *testing.T) {
+	m := metrics.New()
+	hub := websocket.NewHub(m, 0)
+	hub.SetTokens(map[string]string{"s3cret": "alice"})
+	h := NewHandler(service.NewOddsService(nil, store.New()), nil, hub, nil, m, nil, nil, nil)
+	h.SetAlertStream(alertstream.New())
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		query  string
+		bearer string
+		want   int
+	}{
+		{"no token", "", "", http.StatusUnauthorized},
+		{"wrong token", "?token=wrong", "", http.StatusUnauthorized},
+		{"token in the URL", "?token=s3cret", "", http.StatusOK},
+		{"bearer token", "", "s3cret", http.StatusOK},
+		{"wrong bearer over a right URL token", "?token=s3cret", "wrong", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/alerts/stream"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.want)
+			}
+			if tt.want == http.StatusOK {
+				// The stream stays open; its first line is enough
+				line, err := bufio.NewReader(resp.Body).ReadString('\n')
+				if err != nil || line != ": connected\n" {
+					t.Errorf("first line %q (%v), want \": connected\"", line, err)
+				}
+			}
+		})
+	}
+}
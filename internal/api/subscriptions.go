@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// handleAlertSubscriptions shows or changes which alert categories are
+// delivered over which channels. PUT only changes the pairs it names.
+// GET /api/preferences/subscriptions
+// PUT /api/preferences/subscriptions {"subscriptions": {"news": {"push": false}}}
+func (h *Handler) handleAlertSubscriptions(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		prefs, err := h.db.GetPreferences()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+			return
+		}
+		h.jsonResponse(w, http.StatusOK, subscriptionFlags(prefs))
+
+	case http.MethodPut:
+		var body AlertSubscriptions
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+		if msg := subscriptionsError(body.Subscriptions); msg != "" {
+			h.errorResponse(w, http.StatusBadRequest, msg)
+			return
+		}
+		prefs, err := h.db.GetPreferences()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+			return
+		}
+
+		if prefs.AlertSubscriptions == nil {
+			prefs.AlertSubscriptions = make(map[string]map[string]bool)
+		}
+		for category, channels := range normalizeSubscriptions(body.Subscriptions) {
+			if prefs.AlertSubscriptions[category] == nil {
+				prefs.AlertSubscriptions[category] = make(map[string]bool)
+			}
+			for channel, on := range channels {
+				prefs.AlertSubscriptions[category][channel] = on
+			}
+		}
+
+		if err := h.db.UpdatePreferences(prefs); err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to update preferences")
+			return
+		}
+
+		h.jsonResponse(w, http.StatusOK, subscriptionFlags(prefs))
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// subscriptionFlags spells out every category and channel pair from the
+// preferences, filling in the ones left out as subscribed
+func subscriptionFlags(prefs *database.Preferences) AlertSubscriptions {
+	flags := AlertSubscriptions{Subscriptions: make(map[string]map[string]bool)}
+	for _, category := range models.AlertCategories {
+		flags.Subscriptions[category] = make(map[string]bool)
+		for _, channel := range models.AlertChannels {
+			flags.Subscriptions[category][channel] = prefs.Subscribed(category, channel)
+		}
+	}
+	return flags
+}
+
+// subscriptionsError describes the first unknown category or channel in
+// subscriptions, or returns "" when they're all known
+func subscriptionsError(subscriptions map[string]map[string]bool) string {
+	for category, channels := range subscriptions {
+		if !models.ValidAlertCategory(strings.ToLower(category)) {
+			return "invalid alert category '" + category + "': use " + strings.Join(models.AlertCategories, ", ")
+		}
+		for channel := range channels {
+			if !models.ValidAlertChannel(strings.ToLower(channel)) {
+				return "invalid alert channel '" + channel + "': use " + strings.Join(models.AlertChannels, ", ")
+			}
+		}
+	}
+	return ""
+}
+
+// normalizeSubscriptions lowercases categories and channels
+func normalizeSubscriptions(subscriptions map[string]map[string]bool) map[string]map[string]bool {
+	normalized := make(map[string]map[string]bool, len(subscriptions))
+	for category, channels := range subscriptions {
+		category = strings.ToLower(category)
+		if normalized[category] == nil {
+			normalized[category] = make(map[string]bool, len(channels))
+		}
+		for channel, on := range channels {
+			normalized[category][strings.ToLower(channel)] = on
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/metrics"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/store"
+)
+
+// maxTopPlays caps ?limit= on the top plays endpoint
+const maxTopPlays = 50
+
+// handleTopPlays ranks the value opportunities in a sport's upcoming games
+// by score, best first. Unlike /api/alerts/check it only looks: nothing is
+// recorded or notified, and alerts in cooldown still rank.
+// GET /api/topplays?sport=nba&limit=10
+func (h *Handler) handleTopPlays(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.alertDetector == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "alert detection not configured")
+		return
+	}
+
+	sportStr := r.URL.Query().Get("sport")
+	if sportStr == "" {
+		sportStr = "nba"
+	}
+	sport, ok := models.ParseSport(sportStr)
+	if !ok {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidSport, "invalid sport: use "+models.SportChoices())
+		return
+	}
+	sportStr = sport.ShortKey()
+
+	limit := 10
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 || l > maxTopPlays {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidLimit, "invalid limit: must be between 1 and "+strconv.Itoa(maxTopPlays))
+			return
+		}
+		limit = l
+	}
+
+	games := h.oddsService.GetGamesBySport(sport)
+
+	var found []alerts.ValueAlert
+	var scanStats metrics.AlertScanStats
+	now := h.clock.Now()
+	for _, game := range games {
+		if game.Started(now) || !h.alertDetector.InScanWindow(game.CommenceTime) {
+			continue
+		}
+
+		props := store.GetDummyPlayerProps(game.ID, sport, game.HomeTeam, game.AwayTeam)
+		averages := h.projections.Apply(store.GetDummyPlayerAverages(sportStr))
+
+		ctx := alerts.GameContext{
+			GameID:   game.ID,
+			Sport:    sportStr,
+			HomeTeam: game.HomeTeam,
+			AwayTeam: game.AwayTeam,
+			GameTime: game.CommenceTime,
+		}
+
+		for _, propData := range h.alertDetector.CollectProps(props, averages, &scanStats) {
+			if alert := h.alertDetector.DetectValue(propData, ctx); alert != nil {
+				found = append(found, *alert)
+			}
+		}
+	}
+
+	plays := alerts.RankPlays(found, now, limit)
+	h.jsonResponse(w, http.StatusOK, TopPlaysResponse{
+		Sport:     sportStr,
+		Games:     len(games),
+		Plays:     plays,
+		Count:     len(plays),
+		Available: len(found),
+	})
+}
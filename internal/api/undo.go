@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/joshuakim/linefinder/internal/database"
+)
+
+// undoMessages confirm what an undo restored, by kind
+var undoMessages = map[string]string{
+	database.UndoPreferences: "preferences restored",
+	database.UndoPreset:      "preset restored",
+	database.UndoWebhook:     "webhook restored",
+}
+
+// handleUndo reverses a destructive change within the undo window, using
+// the undo_token its response returned. Each token works once.
+// POST /api/undo {"undo_token": "..."}
+func (h *Handler) handleUndo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	var body UndoRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.UndoToken == "" {
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON: expected {\"undo_token\": \"...\"}")
+		return
+	}
+
+	kind, err := h.db.Undo(body.UndoToken)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to undo")
+		return
+	}
+	if kind == "" {
+		h.errorResponse(w, http.StatusNotFound, "undo token not found or expired")
+		return
+	}
+
+	if kind == database.UndoPreferences {
+		prefs, err := h.db.GetPreferences()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get preferences")
+			return
+		}
+		h.applyPreferences(prefs)
+	}
+	h.jsonResponse(w, http.StatusOK, UndoResponse{Message: undoMessages[kind], Kind: kind})
+}
+
+// saveUndo stores what a destructive change is about to replace, answering
+// with an error and returning nil if it can't. Saving first means a change
+// is never made without a way back.
+func (h *Handler) saveUndo(w http.ResponseWriter, kind string, state interface{}) *database.UndoAction {
+	action, err := h.db.SaveUndo(kind, state)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to save undo")
+		return nil
+	}
+	return action
+}
+
+// undoable confirms a destructive change with the token that reverses it
+func undoable(message string, action *database.UndoAction) UndoableResponse {
+	return UndoableResponse{
+		Message:       message,
+		UndoToken:     action.Token,
+		UndoExpiresAt: action.ExpiresAt,
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/velocity"
+)
+
+// SetVelocityMonitor sets the monitor whose readings the velocity endpoint
+// returns
+func (h *Handler) SetVelocityMonitor(monitor *velocity.Monitor) {
+	h.velocity = monitor
+}
+
+// handleVelocity returns how fast each upcoming game's lines are moving per
+// bookmaker, fastest relative to the alert threshold first. Lines that
+// haven't moved are left out unless ?game_id= is given.
+// GET /api/velocity?game_id=abc123
+func (h *Handler) handleVelocity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.velocity == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "velocity monitor not configured")
+		return
+	}
+
+	gameID := strings.TrimSpace(r.URL.Query().Get("game_id"))
+	readings := h.velocity.Readings(gameID)
+	if gameID == "" {
+		moving := readings[:0]
+		for _, reading := range readings {
+			if reading.Velocity != 0 || reading.PriorVelocity != 0 {
+				moving = append(moving, reading)
+			}
+		}
+		readings = moving
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"game_id":  gameID,
+		"readings": readings,
+		"count":    len(readings),
+	})
+}
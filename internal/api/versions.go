@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiV1 is the path prefix of the current API version. Routes are
+// registered at their unversioned /api/ paths and served under it.
+const apiV1 = "/api/v1/"
+
+// SunsetDateFormat is the format of LEGACY_API_SUNSET
+const SunsetDateFormat = "2006-01-02"
+
+// SetLegacySunset sets when the unversioned /api/ paths stop working. Until
+// then they answer with a Sunset header; from then on with 410 Gone.
+func (h *Handler) SetLegacySunset(t time.Time) {
+	h.legacySunset = t
+}
+
+// unversioned maps an /api/v1/ path to the /api/ path its route is
+// registered at, leaving other paths alone
+func unversioned(path string) string {
+	if rest, ok := strings.CutPrefix(path, apiV1); ok {
+		return "/api/" + rest
+	}
+	return path
+}
+
+// versioned serves /api/v1/ requests with the routes registered under
+// /api/. The ResponseWriter is passed through untouched, so WebSocket
+// upgrades and streaming still work.
+func versioned(routes http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = unversioned(r.URL.Path)
+		r2.URL.RawPath = unversioned(r.URL.RawPath)
+		routes.ServeHTTP(w, r2)
+	})
+}
+
+// legacy serves the unversioned /api/ paths as deprecated aliases of their
+// /api/v1/ successors, saying so in Deprecation, Link and (once a sunset is
+// set) Sunset headers
+func (h *Handler) legacy(routes http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		successor := apiV1 + strings.TrimPrefix(r.URL.Path, "/api/")
+		if !h.legacySunset.IsZero() {
+			if !time.Now().Before(h.legacySunset) {
+				h.errorResponse(w, http.StatusGone, "unversioned API paths were retired on "+h.legacySunset.Format(SunsetDateFormat)+": use "+successor)
+				return
+			}
+			w.Header().Set("Sunset", h.legacySunset.UTC().Format(http.TimeFormat))
+		}
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		routes.ServeHTTP(w, r)
+	})
+}
+
+// handleNotFound answers API paths no route matches
+func (h *Handler) handleNotFound(w http.ResponseWriter, r *http.Request) {
+	h.errorResponse(w, http.StatusNotFound, "no such endpoint")
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/database"
+)
+
+// handleWebhooks lists or adds outbound webhooks. Webhooks send alerts to
+// other systems, so they need the admin token.
+// GET  /api/webhooks
+// POST /api/webhooks {"url": "https://...", "secret": "optional"}
+func (h *Handler) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		webhooks, err := h.db.GetWebhooks()
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to get webhooks")
+			return
+		}
+		if webhooks == nil {
+			webhooks = []database.Webhook{}
+		}
+		for i := range webhooks {
+			webhooks[i].Secret = ""
+		}
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"webhooks": webhooks,
+			"count":    len(webhooks),
+		})
+
+	case http.MethodPost:
+		var body struct {
+			URL    string `json:"url"`
+			Secret string `json:"secret"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON")
+			return
+		}
+		u, err := url.Parse(strings.TrimSpace(body.URL))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidURL, "invalid url: must be an absolute http or https URL")
+			return
+		}
+
+		hook := database.Webhook{URL: u.String(), Secret: body.Secret, Enabled: true}
+		if hook.Secret == "" {
+			secret := make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				h.errorResponse(w, http.StatusInternalServerError, "failed to generate secret")
+				return
+			}
+			hook.Secret = hex.EncodeToString(secret)
+		}
+		if err := h.db.CreateWebhook(&hook); err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to save webhook")
+			return
+		}
+
+		// The secret is only shown now, for the receiver to verify signatures
+		h.jsonResponse(w, http.StatusCreated, hook)
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleWebhookRoutes dispatches per-webhook endpoints
+// PUT    /api/webhooks/{id} {"enabled": false}
+// DELETE /api/webhooks/{id}
+// GET    /api/webhooks/{id}/deliveries?status=failed&limit=50
+func (h *Handler) handleWebhookRoutes(w http.ResponseWriter, r *http.Request) {
+	if h.db == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/webhooks/"), "/")
+	parts := strings.Split(path, "/")
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		h.errorResponse(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		h.handleWebhook(w, r, id)
+	case len(parts) == 2 && parts[1] == "deliveries":
+		h.handleWebhookDeliveries(w, r, id)
+	default:
+		h.errorResponse(w, http.StatusNotFound, "not found")
+	}
+}
+
+// handleWebhook enables, disables or deletes a webhook. Deletes can be
+// undone with the undo_token until the undo window passes.
+func (h *Handler) handleWebhook(w http.ResponseWriter, r *http.Request, id int64) {
+	switch r.Method {
+	case http.MethodPut:
+		var body struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Enabled == nil {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON: expected {\"enabled\": true|false}")
+			return
+		}
+		found, err := h.db.SetWebhookEnabled(id, *body.Enabled)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to update webhook")
+			return
+		}
+		if !found {
+			h.errorResponse(w, http.StatusNotFound, "webhook not found")
+			return
+		}
+		h.jsonResponse(w, http.StatusOK, map[string]interface{}{"id": id, "enabled": *body.Enabled})
+
+	case http.MethodDelete:
+		undo := h.saveUndo(w, database.UndoWebhook, id)
+		if undo == nil {
+			return
+		}
+		found, err := h.db.DeleteWebhook(id)
+		if err != nil {
+			h.errorResponse(w, http.StatusInternalServerError, "failed to delete webhook")
+			return
+		}
+		if !found {
+			h.errorResponse(w, http.StatusNotFound, "webhook not found")
+			return
+		}
+		h.jsonResponse(w, http.StatusOK, undoable("webhook deleted", undo))
+
+	default:
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleWebhookDeliveries returns a webhook's recent deliveries, newest first
+func (h *Handler) handleWebhookDeliveries(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodGet {
+		h.errorResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", database.WebhookPending, database.WebhookDelivered, database.WebhookFailed:
+	default:
+		h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidStatus, "invalid status: use 'pending', 'delivered' or 'failed'")
+		return
+	}
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 {
+			h.errorCodeResponse(w, http.StatusBadRequest, CodeInvalidLimit, "invalid limit: must be a positive integer")
+			return
+		}
+		limit = l
+	}
+
+	deliveries, err := h.db.GetWebhookDeliveries(id, status, limit)
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "failed to load webhook deliveries")
+		return
+	}
+	if deliveries == nil {
+		deliveries = []database.WebhookDelivery{}
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
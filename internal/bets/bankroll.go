@@ -0,0 +1,128 @@
+package bets
+
+import (
+	"sort"
+
+	"github.com/joshuakim/linefinder/internal/database"
+)
+
+// Summary is how a set of bets has done, in currency and in units. Staked,
+// ROI and win rate cover graded bets; pending bets are counted separately.
+type Summary struct {
+	Bets         int     `json:"bets"`
+	Pending      int     `json:"pending"`
+	PendingStake float64 `json:"pending_stake"`
+	PendingUnits float64 `json:"pending_units"`
+	Wins         int     `json:"wins"`
+	Losses       int     `json:"losses"`
+	Pushes       int     `json:"pushes"`
+	Staked       float64 `json:"staked"`
+	StakedUnits  float64 `json:"staked_units"`
+	Profit       float64 `json:"profit"`
+	ROI          float64 `json:"roi"`       // profit per amount staked, in percent
+	UnitsWon     float64 `json:"units_won"` // profit in units
+	WinRate      float64 `json:"win_rate"`  // wins per decided bet, in percent
+}
+
+// BookSummary is how bets at one bookmaker have done
+type BookSummary struct {
+	Bookmaker string `json:"bookmaker"`
+	Summary
+}
+
+// Bankroll is how every logged bet has done, overall and per bookmaker.
+// UnitSize is the unit for bets logged without their own.
+type Bankroll struct {
+	Currency string  `json:"currency"`
+	UnitSize float64 `json:"unit_size"`
+	Summary
+	Books []BookSummary `json:"books"`
+}
+
+// NewBankroll summarizes bets in a currency and in units. Each bet counts
+// in the unit size it was logged with; bets without one use unitSize, or
+// the average stake when that's zero.
+func NewBankroll(bets []database.Bet, unitSize float64, currency string) Bankroll {
+	if unitSize <= 0 && len(bets) > 0 {
+		var total float64
+		for _, b := range bets {
+			total += b.Stake
+		}
+		unitSize = round(total / float64(len(bets)))
+	}
+
+	bankroll := Bankroll{Currency: currency, UnitSize: unitSize, Books: []BookSummary{}}
+	byBook := make(map[string]*BookSummary)
+	for _, b := range bets {
+		book := byBook[b.Bookmaker]
+		if book == nil {
+			book = &BookSummary{Bookmaker: b.Bookmaker}
+			byBook[b.Bookmaker] = book
+		}
+		unit := unitSize
+		if b.UnitSize > 0 {
+			unit = b.UnitSize
+		}
+		bankroll.add(b, unit)
+		book.add(b, unit)
+	}
+	bankroll.finish()
+
+	for _, book := range byBook {
+		book.finish()
+		bankroll.Books = append(bankroll.Books, *book)
+	}
+	// Most profitable books first
+	sort.Slice(bankroll.Books, func(i, j int) bool {
+		a, b := bankroll.Books[i], bankroll.Books[j]
+		if a.Profit != b.Profit {
+			return a.Profit > b.Profit
+		}
+		return a.Bookmaker < b.Bookmaker
+	})
+	return bankroll
+}
+
+// add counts a bet, converting it to units of a size
+func (s *Summary) add(b database.Bet, unit float64) {
+	var units float64
+	if unit > 0 {
+		units = b.Stake / unit
+	}
+	s.Bets++
+	switch b.Result {
+	case database.BetPending:
+		s.Pending++
+		s.PendingStake += b.Stake
+		s.PendingUnits += units
+		return
+	case database.OutcomeWin:
+		s.Wins++
+	case database.OutcomeLoss:
+		s.Losses++
+	case database.OutcomePush:
+		s.Pushes++
+	}
+	s.Staked += b.Stake
+	s.StakedUnits += units
+	s.Profit += b.Profit
+	if unit > 0 {
+		s.UnitsWon += b.Profit / unit
+	}
+}
+
+// finish works out the rates once every bet is counted
+func (s *Summary) finish() {
+	s.PendingStake = round(s.PendingStake)
+	s.PendingUnits = round(s.PendingUnits)
+	s.Staked = round(s.Staked)
+	s.StakedUnits = round(s.StakedUnits)
+	s.Profit = round(s.Profit)
+	s.UnitsWon = round(s.UnitsWon)
+	if s.Staked > 0 {
+		s.ROI = round(s.Profit / s.Staked * 100)
+	}
+	if decided := s.Wins + s.Losses; decided > 0 {
+		s.WinRate = round(float64(s.Wins) / float64(decided) * 100)
+	}
+}
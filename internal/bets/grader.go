@@ -0,0 +1,233 @@
+package bets
+
+import (
+	"context"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/oddsapi"
+)
+
+// maxScoreDays is how far back the scores feed reports completed games
+const maxScoreDays = 3
+
+// Config holds bet grading configuration
+type Config struct {
+	// Interval is the time between grading passes
+	Interval time.Duration
+
+	// Delay is how long after a game starts before its scores are looked
+	// up, so passes don't spend requests on games still being played
+	Delay time.Duration
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Interval: 30 * time.Minute,
+		Delay:    2 * time.Hour,
+	}
+}
+
+// Gradable reports whether bets on a market are graded from the final
+// score. Other markets, such as player props, are graded by hand.
+func Gradable(market string) bool {
+	switch models.Market(market) {
+	case models.MarketH2H, models.MarketSpreads, models.MarketTotals:
+		return true
+	}
+	return false
+}
+
+// Grader grades pending bets once their games' final scores are in
+type Grader struct {
+	config Config
+	client *oddsapi.Client
+	db     *database.DB
+	clock  clock.Clock
+}
+
+// NewGrader creates a new bet grader
+func NewGrader(config Config, client *oddsapi.Client, db *database.DB) *Grader {
+	return &Grader{
+		config: config,
+		client: client,
+		db:     db,
+		clock:  clock.Real{},
+	}
+}
+
+// SetClock sets the clock used to decide which games are over
+func (g *Grader) SetClock(c clock.Clock) {
+	g.clock = c
+}
+
+// Start grades bets on every interval until the context is cancelled
+func (g *Grader) Start(ctx context.Context) {
+	if g.config.Interval <= 0 {
+		g.config.Interval = DefaultConfig().Interval
+	}
+
+	log.Printf("Bet grader starting (interval: %v, delay: %v)", g.config.Interval, g.config.Delay)
+
+	ticker := time.NewTicker(g.config.Interval)
+	defer ticker.Stop()
+
+	g.Grade()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.Grade()
+		}
+	}
+}
+
+// Grade looks up final scores for pending bets on games that should be
+// over and grades the ones that are, returning them. Scores are fetched
+// once per sport, and only for sports with bets to grade.
+func (g *Grader) Grade() []database.Bet {
+	now := g.clock.Now()
+	pending, err := g.db.GetPendingBets(now.Add(-g.config.Delay))
+	if err != nil {
+		log.Printf("Bets: Failed to get pending bets: %v", err)
+		return nil
+	}
+
+	bySport := make(map[string][]database.Bet)
+	var sports []string
+	for _, b := range pending {
+		if !Gradable(b.Market) {
+			continue
+		}
+		if _, ok := bySport[b.Sport]; !ok {
+			sports = append(sports, b.Sport)
+		}
+		bySport[b.Sport] = append(bySport[b.Sport], b)
+	}
+
+	var graded []database.Bet
+	for _, sport := range sports {
+		sportBets := bySport[sport]
+
+		// Bets are ordered by start time, so the first is the oldest game
+		days := int(math.Ceil(now.Sub(sportBets[0].CommenceTime).Hours() / 24))
+		if days < 1 {
+			days = 1
+		}
+		if days > maxScoreDays {
+			days = maxScoreDays
+		}
+		scores, err := g.client.GetScores(models.Sport(sport), days)
+		if err != nil {
+			log.Printf("Bets: Failed to get %s scores: %v", sport, err)
+			continue
+		}
+		byGame := make(map[string]oddsapi.Score, len(scores))
+		for _, s := range scores {
+			byGame[s.ID] = s
+		}
+
+		for _, b := range sportBets {
+			score, ok := byGame[b.GameID]
+			if !ok || !score.Completed {
+				continue
+			}
+			home, away, ok := finalScore(score, b.HomeTeam, b.AwayTeam)
+			if !ok {
+				continue
+			}
+			b.Result = Outcome(b, home, away)
+			b.Profit = Profit(b.Price, b.Stake, b.Result)
+			b.HomeScore, b.AwayScore = &home, &away
+			if _, err := g.db.GradeBet(&b); err != nil {
+				log.Printf("Bets: Failed to grade bet %d: %v", b.ID, err)
+				continue
+			}
+			graded = append(graded, b)
+		}
+	}
+
+	if len(graded) > 0 {
+		log.Printf("Bets: Graded %d bet(s)", len(graded))
+	}
+	return graded
+}
+
+// finalScore returns the home and away teams' points from a score
+func finalScore(score oddsapi.Score, homeTeam, awayTeam string) (home, away int, ok bool) {
+	var foundHome, foundAway bool
+	for _, ts := range score.Scores {
+		points, err := strconv.Atoi(ts.Score)
+		if err != nil {
+			return 0, 0, false
+		}
+		switch ts.Name {
+		case homeTeam:
+			home, foundHome = points, true
+		case awayTeam:
+			away, foundAway = points, true
+		}
+	}
+	return home, away, foundHome && foundAway
+}
+
+// Outcome grades a moneyline, spread or total bet against a final score
+func Outcome(b database.Bet, home, away int) string {
+	var margin float64
+	switch models.Market(b.Market) {
+	case models.MarketH2H, models.MarketSpreads:
+		// The selected team's points less the other team's, after the spread
+		margin = float64(home - away)
+		if b.Selection == b.AwayTeam {
+			margin = -margin
+		}
+		if b.Point != nil && models.Market(b.Market) == models.MarketSpreads {
+			margin += *b.Point
+		}
+	case models.MarketTotals:
+		margin = float64(home+away) - pointOrZero(b.Point)
+		if b.Selection == "Under" {
+			margin = -margin
+		}
+	}
+
+	switch {
+	case margin > 0:
+		return database.OutcomeWin
+	case margin < 0:
+		return database.OutcomeLoss
+	}
+	return database.OutcomePush
+}
+
+// Profit returns what a bet at American odds won or lost for a result
+func Profit(price, stake float64, result string) float64 {
+	switch result {
+	case database.OutcomeWin:
+		if price > 0 {
+			return round(stake * price / 100)
+		}
+		return round(stake * 100 / -price)
+	case database.OutcomeLoss:
+		return -stake
+	}
+	return 0
+}
+
+func pointOrZero(point *float64) float64 {
+	if point == nil {
+		return 0
+	}
+	return *point
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
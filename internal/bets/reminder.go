@@ -0,0 +1,361 @@
+package bets
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/service"
+)
+
+// Opportunity types
+const (
+	// OpportunityHedge is an opposite side priced so that betting it locks
+	// in a profit whichever side wins
+	OpportunityHedge = "hedge"
+
+	// OpportunityMiddle is an opposite side on a number far enough from
+	// the bet's that both can win
+	OpportunityMiddle = "middle"
+)
+
+// ReminderConfig holds open bet reminder configuration
+type ReminderConfig struct {
+	// Interval is the time between checks of bets on upcoming games
+	Interval time.Duration
+
+	// Lead is how long before a game's start its bets are checked
+	Lead time.Duration
+}
+
+// DefaultReminderConfig returns a sensible default configuration
+func DefaultReminderConfig() ReminderConfig {
+	return ReminderConfig{
+		Interval: 5 * time.Minute,
+		Lead:     90 * time.Minute,
+	}
+}
+
+// Opportunity is a hedge or middle on an open bet, from the best current
+// price on the other side. HedgeStake evens out the payouts: LockedProfit
+// is the result when one side wins, MiddleProfit when both do.
+type Opportunity struct {
+	Bet  database.Bet `json:"bet"`
+	Type string       `json:"type"`
+
+	// The bet's side at its best current price
+	CurrentPrice float64  `json:"current_price"`
+	CurrentPoint *float64 `json:"current_point,omitempty"`
+
+	// The other side to take
+	Selection string   `json:"selection"`
+	Point     *float64 `json:"point,omitempty"`
+	Price     float64  `json:"price"`
+	Bookmaker string   `json:"bookmaker"`
+
+	HedgeStake   float64 `json:"hedge_stake"`
+	LockedProfit float64 `json:"locked_profit"`
+
+	// Middles only: the whole-number results both bets win on, as the
+	// bet's team's margin for spreads or the combined score for totals
+	MiddleLow    int     `json:"middle_low,omitempty"`
+	MiddleHigh   int     `json:"middle_high,omitempty"`
+	MiddleProfit float64 `json:"middle_profit,omitempty"`
+}
+
+// Title names the opportunity and the bet it's on
+func (o Opportunity) Title() string {
+	kind := "Hedge"
+	if o.Type == OpportunityMiddle {
+		kind = "Middle"
+	}
+	return fmt.Sprintf("%s your bet: %s @ %s", kind, o.Bet.AwayTeam, o.Bet.HomeTeam)
+}
+
+// Summary describes the move and what taking the other side does
+func (o Opportunity) Summary() string {
+	bet := fmt.Sprintf("You have %s %+.0f at %s, now %+.0f.",
+		selectionLabel(o.Bet.Selection, o.Bet.Point, o.Bet.Market), o.Bet.Price, o.Bet.Bookmaker, o.CurrentPrice)
+	if o.CurrentPoint != nil {
+		bet = fmt.Sprintf("You have %s %+.0f at %s, now %s %+.0f.",
+			selectionLabel(o.Bet.Selection, o.Bet.Point, o.Bet.Market), o.Bet.Price, o.Bet.Bookmaker,
+			selectionLabel(o.Bet.Selection, o.CurrentPoint, o.Bet.Market), o.CurrentPrice)
+	}
+	take := fmt.Sprintf("%s %+.0f at %s for %.2f", selectionLabel(o.Selection, o.Point, o.Bet.Market), o.Price, o.Bookmaker, o.HedgeStake)
+
+	if o.Type == OpportunityHedge {
+		return fmt.Sprintf("%s %s locks in %+.2f either way.", bet, take, o.LockedProfit)
+	}
+	return fmt.Sprintf("%s %s wins both %s (%+.2f), otherwise %+.2f.",
+		bet, take, o.middleRange(), o.MiddleProfit, o.LockedProfit)
+}
+
+// middleRange describes the results both bets win on
+func (o Opportunity) middleRange() string {
+	if models.Market(o.Bet.Market) == models.MarketTotals {
+		return fmt.Sprintf("on a total of %s", span(o.MiddleLow, o.MiddleHigh))
+	}
+	switch {
+	case o.MiddleLow > 0:
+		return fmt.Sprintf("if %s wins by %s", o.Bet.Selection, span(o.MiddleLow, o.MiddleHigh))
+	case o.MiddleHigh < 0:
+		return fmt.Sprintf("if %s wins by %s", o.Selection, span(-o.MiddleHigh, -o.MiddleLow))
+	default:
+		return fmt.Sprintf("on a %s margin of %+d to %+d", o.Bet.Selection, o.MiddleLow, o.MiddleHigh)
+	}
+}
+
+// span is "4" or "4 to 6"
+func span(low, high int) string {
+	if low == high {
+		return fmt.Sprint(low)
+	}
+	return fmt.Sprintf("%d to %d", low, high)
+}
+
+// selectionLabel is a selection with its point, e.g. "Boston Celtics -4.5"
+// or "Over 221.5"
+func selectionLabel(selection string, point *float64, market string) string {
+	if point == nil {
+		return selection
+	}
+	if models.Market(market) == models.MarketTotals {
+		return fmt.Sprintf("%s %g", selection, *point)
+	}
+	return fmt.Sprintf("%s %+g", selection, *point)
+}
+
+// FindOpportunity looks for a hedge or middle on a pending bet in a game's
+// current prices, or returns nil. Middles are preferred, the widest first,
+// then hedges locking in the most. Only moneylines, spreads and totals are
+// priced in comparisons.
+func FindOpportunity(b database.Bet, c models.OddsComparison) *Opportunity {
+	home := b.Selection == c.HomeTeam
+	var best *Opportunity
+	consider := func(o Opportunity) {
+		if o.Type == "" {
+			return
+		}
+		if best == nil || better(o, *best) {
+			found := o
+			best = &found
+		}
+	}
+
+	switch models.Market(b.Market) {
+	case models.MarketH2H:
+		if c.Moneyline == nil {
+			return nil
+		}
+		current, other, selection := c.Moneyline.BestHome, c.Moneyline.BestAway, c.AwayTeam
+		if !home {
+			current, other, selection = c.Moneyline.BestAway, c.Moneyline.BestHome, c.HomeTeam
+		}
+		o := newOpportunity(b, current.Price, nil, selection, nil, other.Price, other.Bookmaker)
+		if o.LockedProfit > 0 {
+			o.Type = OpportunityHedge
+		}
+		consider(o)
+
+	case models.MarketSpreads:
+		if c.Spread == nil || b.Point == nil {
+			return nil
+		}
+		current, selection := c.Spread.BestHome, c.AwayTeam
+		if !home {
+			current, selection = c.Spread.BestAway, c.HomeTeam
+		}
+		for _, book := range c.Spread.AllBookmakers {
+			price, point := book.AwayPrice, book.AwayPoint
+			if !home {
+				price, point = book.HomePrice, book.HomePoint
+			}
+			if price == 0 {
+				continue
+			}
+			// The bet's team covers above -bet point, the other team
+			// below its own point
+			consider(classify(newOpportunity(b, current.Price, &current.Point, selection, &point, price, book.Bookmaker),
+				-*b.Point, point))
+		}
+
+	case models.MarketTotals:
+		if c.Total == nil || b.Point == nil {
+			return nil
+		}
+		over := b.Selection == "Over"
+		current, selection := c.Total.BestOver, "Under"
+		if !over {
+			current, selection = c.Total.BestUnder, "Over"
+		}
+		for _, book := range c.Total.AllBookmakers {
+			price := book.UnderPrice
+			if !over {
+				price = book.OverPrice
+			}
+			if price == 0 {
+				continue
+			}
+			point := book.Point
+			o := newOpportunity(b, current.Price, &current.Point, selection, &point, price, book.Bookmaker)
+			if over {
+				consider(classify(o, *b.Point, point))
+			} else {
+				consider(classify(o, point, *b.Point))
+			}
+		}
+	}
+	return best
+}
+
+// newOpportunity prices taking the other side against a bet, staking
+// enough to even out the payouts
+func newOpportunity(b database.Bet, currentPrice float64, currentPoint *float64, selection string, point *float64, price float64, bookmaker string) Opportunity {
+	betPayout := b.Stake * models.DecimalPayout(b.Price)
+	hedge := round(betPayout / models.DecimalPayout(price))
+	return Opportunity{
+		Bet:          b,
+		CurrentPrice: currentPrice,
+		CurrentPoint: currentPoint,
+		Selection:    selection,
+		Point:        point,
+		Price:        price,
+		Bookmaker:    bookmaker,
+		HedgeStake:   hedge,
+		LockedProfit: round(betPayout - b.Stake - hedge),
+	}
+}
+
+// classify makes an opportunity a middle when whole-number results fall
+// strictly between low and high, where both bets win, or a hedge when it
+// locks in a profit on the same number
+func classify(o Opportunity, low, high float64) Opportunity {
+	first, last := int(math.Floor(low))+1, int(math.Ceil(high))-1
+	switch {
+	case first <= last:
+		o.Type = OpportunityMiddle
+		o.MiddleLow, o.MiddleHigh = first, last
+		// Both win: the locked result plus the other side's whole payout
+		o.MiddleProfit = round(o.LockedProfit + o.HedgeStake*models.DecimalPayout(o.Price))
+	case low == high && o.LockedProfit > 0:
+		o.Type = OpportunityHedge
+	}
+	return o
+}
+
+// better reports whether one opportunity beats another: any middle beats a
+// hedge, wider middles beat narrower ones, then more locked in wins
+func better(a, b Opportunity) bool {
+	if a.Type != b.Type {
+		return a.Type == OpportunityMiddle
+	}
+	if a.Type == OpportunityMiddle {
+		if width, other := a.MiddleHigh-a.MiddleLow, b.MiddleHigh-b.MiddleLow; width != other {
+			return width > other
+		}
+	}
+	return a.LockedProfit > b.LockedProfit
+}
+
+// Reminder checks open bets shortly before their games start and reports
+// the ones whose line has moved far enough to hedge or middle. Each bet
+// gets one reminder.
+type Reminder struct {
+	config      ReminderConfig
+	db          *database.DB
+	oddsService *service.OddsService
+	clock       clock.Clock
+
+	mu       sync.RWMutex
+	callback func([]Opportunity)
+}
+
+// NewReminder creates a new open bet reminder
+func NewReminder(config ReminderConfig, db *database.DB, oddsService *service.OddsService) *Reminder {
+	return &Reminder{
+		config:      config,
+		db:          db,
+		oddsService: oddsService,
+		clock:       clock.Real{},
+	}
+}
+
+// SetClock sets the clock used for the lead window
+func (r *Reminder) SetClock(c clock.Clock) {
+	r.clock = c
+}
+
+// SetCallback sets the function called with each pass's opportunities
+func (r *Reminder) SetCallback(fn func([]Opportunity)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.callback = fn
+}
+
+// Start checks bets on every interval until the context is cancelled
+func (r *Reminder) Start(ctx context.Context) {
+	if r.config.Interval <= 0 {
+		r.config.Interval = DefaultReminderConfig().Interval
+	}
+
+	log.Printf("Bet reminder starting (interval: %v, lead: %v)", r.config.Interval, r.config.Lead)
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Check()
+		}
+	}
+}
+
+// Check prices the other side of pending bets on games starting within the
+// lead window, reporting and marking each bet with an opportunity. Bets
+// without one are checked again next pass until their game starts.
+func (r *Reminder) Check() []Opportunity {
+	now := r.clock.Now()
+	pending, err := r.db.GetBetsToRemind(now, now.Add(r.config.Lead))
+	if err != nil {
+		log.Printf("Bets: Failed to get bets to remind: %v", err)
+		return nil
+	}
+
+	var found []Opportunity
+	for _, b := range pending {
+		game, ok := r.oddsService.GetGame(b.GameID)
+		if !ok {
+			continue
+		}
+		o := FindOpportunity(b, r.oddsService.CompareOdds(game))
+		if o == nil {
+			continue
+		}
+		if err := r.db.MarkBetReminded(b.ID); err != nil {
+			log.Printf("Bets: Failed to mark bet %d reminded: %v", b.ID, err)
+			continue
+		}
+		found = append(found, *o)
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	log.Printf("Bets: %d open bets can be hedged or middled", len(found))
+
+	r.mu.RLock()
+	callback := r.callback
+	r.mu.RUnlock()
+	if callback != nil {
+		callback(found)
+	}
+	return found
+}
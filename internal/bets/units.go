@@ -0,0 +1,39 @@
+package bets
+
+import "github.com/joshuakim/linefinder/internal/database"
+
+// DefaultCurrency is the currency stakes are in until one is set
+const DefaultCurrency = "USD"
+
+// ValidCurrency reports whether a code is shaped like an ISO 4217
+// currency: three capital letters, e.g. USD, EUR or GBP
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// StakeForUnits converts a stake in units to currency at a unit size
+func StakeForUnits(units, unitSize float64) float64 {
+	return round(units * unitSize)
+}
+
+// FillUnits sets a bet's stake and profit in units, at the unit size it
+// was logged with or, for bets logged without one, unitSize. With neither
+// they're left at 0.
+func FillUnits(b *database.Bet, unitSize float64) {
+	if b.UnitSize > 0 {
+		unitSize = b.UnitSize
+	}
+	if unitSize <= 0 {
+		return
+	}
+	b.StakeUnits = round(b.Stake / unitSize)
+	b.ProfitUnits = round(b.Profit / unitSize)
+}
@@ -0,0 +1,343 @@
+package bootstrap
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/oddsapi"
+	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/sportsdata"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
+)
+
+// Options controls what a bootstrap run fetches
+type Options struct {
+	// Sports to bootstrap
+	Sports []models.Sport
+
+	// PropsWindow limits prop fetches to games starting within this window
+	PropsWindow time.Duration
+
+	// MaxPropGames caps prop fetches per sport, since each costs API quota
+	MaxPropGames int
+
+	// MaxPlayers caps how many players get averages per sport
+	MaxPlayers int
+
+	// RecentGames is how many recent games each average covers
+	RecentGames int
+}
+
+// DefaultOptions returns a sensible default configuration
+func DefaultOptions() Options {
+	return Options{
+		Sports:       []models.Sport{models.SportNBA, models.SportNFL},
+		PropsWindow:  36 * time.Hour,
+		MaxPropGames: 5,
+		MaxPlayers:   40,
+		RecentGames:  5,
+	}
+}
+
+// Result summarizes a bootstrap run
+type Result struct {
+	Sports   int      `json:"sports"`
+	Games    int      `json:"games"`
+	Props    int      `json:"prop_games"`
+	Players  int      `json:"players"`
+	Averages int      `json:"averages"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// Runner performs a one-time fetch of reference and slate data
+type Runner struct {
+	oddsClient  *oddsapi.Client
+	oddsService *service.OddsService
+	sportsData  *sportsdata.Client
+	db          *database.DB
+	clock       clock.Clock
+}
+
+// NewRunner creates a bootstrap runner. sportsData may be nil, in which case
+// players and averages are skipped.
+func NewRunner(oddsClient *oddsapi.Client, oddsService *service.OddsService, sportsData *sportsdata.Client, db *database.DB) *Runner {
+	return &Runner{
+		oddsClient:  oddsClient,
+		oddsService: oddsService,
+		sportsData:  sportsData,
+		db:          db,
+		clock:       clock.Real{},
+	}
+}
+
+// SetClock sets the clock used for the imminent-games window and seasons
+func (r *Runner) SetClock(c clock.Clock) {
+	r.clock = c
+}
+
+// Run fetches sports, games, props for imminent games, rosters, and averages.
+// Failures in one step are recorded and the remaining steps still run.
+func (r *Runner) Run(opts Options) *Result {
+	result := &Result{}
+	fail := func(format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		log.Printf("Bootstrap: %s", msg)
+		result.Errors = append(result.Errors, msg)
+	}
+
+	// Sports list
+	sports, err := r.oddsClient.GetSports()
+	if err != nil {
+		fail("sports list: %v", err)
+	} else {
+		upstream := make([]database.UpstreamSport, len(sports))
+		for i, s := range sports {
+			upstream[i] = database.UpstreamSport{
+				Key:         s.Key,
+				Group:       s.Group,
+				Title:       s.Title,
+				Description: s.Description,
+				Active:      s.Active,
+			}
+		}
+		if err := r.db.SaveUpstreamSports(upstream); err != nil {
+			fail("saving sports list: %v", err)
+		} else {
+			result.Sports = len(upstream)
+			log.Printf("Bootstrap: %d sports listed", len(upstream))
+		}
+	}
+
+	now := r.clock.Now()
+	for _, sport := range opts.Sports {
+		// Games
+		games, err := r.oddsService.FetchAndStoreOdds(sport)
+		if err != nil {
+			fail("%s games: %v", sport, err)
+			continue
+		}
+		if err := r.db.SaveGameSnapshots(games); err != nil {
+			fail("saving %s games: %v", sport, err)
+		}
+		result.Games += len(games)
+		log.Printf("Bootstrap: %d %s games", len(games), sport)
+
+		// Props for imminent games
+		propPlayers := make(map[string]bool)
+		for _, game := range imminentGames(games, now, opts.PropsWindow, opts.MaxPropGames) {
+			info, _ := models.LookupSport(string(sport))
+			props, err := r.oddsClient.GetEventPlayerProps(sport, game.ID, info.Markets)
+			if err != nil {
+				fail("%s props for %s: %v", sport, game.ID, err)
+				continue
+			}
+			if err := r.db.SavePropSnapshot(sport, props); err != nil {
+				fail("saving props for %s: %v", game.ID, err)
+				continue
+			}
+			result.Props++
+			for _, p := range props.Players {
+				propPlayers[strings.ToLower(p.Name)] = true
+			}
+		}
+
+		if r.sportsData == nil {
+			continue
+		}
+
+		// Rosters, then averages for players with props
+		players, averages, err := r.fetchPlayers(sport, propPlayers, now, opts)
+		if err != nil {
+			fail("%s players: %v", sport, err)
+			continue
+		}
+		if err := r.db.SavePlayers(players); err != nil {
+			fail("saving %s players: %v", sport, err)
+		} else {
+			result.Players += len(players)
+		}
+		if err := r.db.SavePlayerAverages(averages); err != nil {
+			fail("saving %s averages: %v", sport, err)
+		} else {
+			result.Averages += len(averages)
+		}
+		log.Printf("Bootstrap: %d %s players, %d averages", len(players), sport, len(averages))
+	}
+
+	if r.sportsData == nil {
+		log.Println("Bootstrap: SPORTSDATA_API_KEY not set - skipped players and averages")
+	}
+
+	return result
+}
+
+// imminentGames returns up to max games starting within the window, soonest first
+func imminentGames(games []models.Game, now time.Time, window time.Duration, max int) []models.Game {
+	var upcoming []models.Game
+	for _, g := range games {
+		if g.CommenceTime.After(now) && g.CommenceTime.Before(now.Add(window)) {
+			upcoming = append(upcoming, g)
+		}
+	}
+	sort.Slice(upcoming, func(i, j int) bool {
+		return upcoming[i].CommenceTime.Before(upcoming[j].CommenceTime)
+	})
+	if max > 0 && len(upcoming) > max {
+		upcoming = upcoming[:max]
+	}
+	return upcoming
+}
+
+// fetchPlayers loads the roster and computes recent averages for players
+// named in the fetched props
+func (r *Runner) fetchPlayers(sport models.Sport, propPlayers map[string]bool, now time.Time, opts Options) ([]database.Player, []database.PlayerAverage, error) {
+	var roster []sportsdata.Player
+	var err error
+	switch sport {
+	case models.SportNBA:
+		roster, err = r.sportsData.GetNBAPlayers()
+	case models.SportNFL:
+		roster, err = r.sportsData.GetNFLPlayers()
+	default:
+		// Rosters and game logs only come from SportsDataIO's NBA and NFL feeds
+		return nil, nil, fmt.Errorf("no player stats source for %s", sport)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sportStr := sport.ShortKey()
+	players := make([]database.Player, 0, len(roster))
+	var averages []database.PlayerAverage
+	fetched := 0
+
+	for _, p := range roster {
+		name := strings.TrimSpace(p.FirstName + " " + p.LastName)
+		injury := ""
+		if p.InjuryStatus != nil {
+			injury = *p.InjuryStatus
+		}
+		players = append(players, database.Player{
+			Sport:        sportStr,
+			PlayerID:     p.PlayerID,
+			Name:         name,
+			Team:         p.Team,
+			Position:     p.Position,
+			InjuryStatus: injury,
+		})
+
+		if !propPlayers[strings.ToLower(name)] || fetched >= opts.MaxPlayers {
+			continue
+		}
+		fetched++
+
+		stats, err := r.playerStats(sport, p.PlayerID, now)
+		if err != nil {
+			log.Printf("Bootstrap: stats for %s: %v", name, err)
+			continue
+		}
+		averages = append(averages, recentAverages(sportStr, name, stats, opts.RecentGames)...)
+	}
+
+	return players, averages, nil
+}
+
+// playerStats fetches a player's game logs for the current season
+func (r *Runner) playerStats(sport models.Sport, playerID int, now time.Time) ([]sportsdata.PlayerGameStats, error) {
+	season := currentSeason(sport, now)
+	if sport == models.SportNBA {
+		return r.sportsData.GetNBAPlayerGameStats(season, playerID)
+	}
+	return r.sportsData.GetNFLPlayerGameStats(season, playerID)
+}
+
+// currentSeason returns the season label SportsDataIO uses. NBA seasons are
+// labeled by the year they end; NFL seasons by the year they start.
+func currentSeason(sport models.Sport, now time.Time) string {
+	year := now.Year()
+	switch sport {
+	case models.SportNBA:
+		if now.Month() >= time.October {
+			year++
+		}
+	case models.SportNFL:
+		if now.Month() < time.March {
+			year--
+		}
+	}
+	return fmt.Sprintf("%d", year)
+}
+
+// recentAverages averages the most recent n games per prop category, with
+// the games' standard deviation
+func recentAverages(sport, name string, stats []sportsdata.PlayerGameStats, n int) []database.PlayerAverage {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].DateTime > stats[j].DateTime
+	})
+	if n > 0 && len(stats) > n {
+		stats = stats[:n]
+	}
+
+	totals := make(map[string]float64)
+	squares := make(map[string]float64)
+	for _, s := range stats {
+		for category, value := range categoryValues(sport, s) {
+			totals[category] += value
+			squares[category] += value * value
+		}
+	}
+
+	games := float64(len(stats))
+	averages := make([]database.PlayerAverage, 0, len(totals))
+	for category, total := range totals {
+		mean := total / games
+		averages = append(averages, database.PlayerAverage{
+			Sport:       sport,
+			PlayerName:  name,
+			Category:    category,
+			Average:     mean,
+			StdDev:      math.Sqrt(math.Max(squares[category]/games-mean*mean, 0)),
+			GamesPlayed: len(stats),
+		})
+	}
+	return averages
+}
+
+// categoryValues maps one game's stats to taxonomy categories
+func categoryValues(sport string, s sportsdata.PlayerGameStats) map[string]float64 {
+	if sport == "nba" {
+		return map[string]float64{
+			taxonomy.Points:   s.Points,
+			taxonomy.Rebounds: s.Rebounds,
+			taxonomy.Assists:  s.Assists,
+			taxonomy.Threes:   s.ThreePointersMade,
+			taxonomy.Steals:   s.Steals,
+			taxonomy.Blocks:   s.BlockedShots,
+			taxonomy.PRA:      s.Points + s.Rebounds + s.Assists,
+			taxonomy.PR:       s.Points + s.Rebounds,
+			taxonomy.PA:       s.Points + s.Assists,
+			taxonomy.RA:       s.Rebounds + s.Assists,
+		}
+	}
+	return map[string]float64{
+		taxonomy.PassingYards:   s.PassingYards,
+		taxonomy.PassingTDs:     s.PassingTouchdowns,
+		taxonomy.PassAttempts:   s.PassingAttempts,
+		taxonomy.Completions:    s.PassingCompletions,
+		taxonomy.RushYards:      s.RushingYards,
+		taxonomy.RushAttempts:   s.RushingAttempts,
+		taxonomy.Receptions:     s.Receptions,
+		taxonomy.ReceivingYards: s.ReceivingYards,
+	}
+}
@@ -0,0 +1,158 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time and waits. Time-dependent components take a
+// Clock so they can run against simulated time in demo and test environments.
+// Waits that have to end early on shutdown select on After and the context.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is the wall clock
+type Real struct{}
+
+// Now returns the current wall-clock time
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep pauses the current goroutine for d
+func (Real) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// After sends the current time once d has passed
+func (Real) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Virtual follows the wall clock until simulation is enabled, after which it
+// reports an adjustable simulated time that keeps ticking (or stays frozen)
+type Virtual struct {
+	mu        sync.RWMutex
+	simulated bool
+	offset    time.Duration // simulated - real, while running
+	frozen    bool
+	frozenAt  time.Time
+}
+
+// NewVirtual creates a virtual clock in real-time mode
+func NewVirtual() *Virtual {
+	return &Virtual{}
+}
+
+// Now returns the simulated time when simulation is on, else wall-clock time
+func (v *Virtual) Now() time.Time {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if !v.simulated {
+		return time.Now()
+	}
+	if v.frozen {
+		return v.frozenAt
+	}
+	return time.Now().Add(v.offset)
+}
+
+// Sleep waits in real time; simulated time keeps moving with it unless frozen
+func (v *Virtual) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// After fires once d has passed in real time, like Sleep
+func (v *Virtual) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Set jumps simulated time to t, enabling simulation
+func (v *Virtual) Set(t time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.simulated = true
+	if v.frozen {
+		v.frozenAt = t
+		return
+	}
+	v.offset = time.Until(t)
+}
+
+// Advance moves simulated time forward by d, enabling simulation
+func (v *Virtual) Advance(d time.Duration) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.simulated = true
+	if v.frozen {
+		v.frozenAt = v.frozenAt.Add(d)
+		return
+	}
+	v.offset += d
+}
+
+// Freeze stops (true) or resumes (false) the passage of simulated time
+func (v *Virtual) Freeze(frozen bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if frozen == v.frozen {
+		return
+	}
+
+	now := time.Now()
+	if frozen {
+		if v.simulated {
+			v.frozenAt = now.Add(v.offset)
+		} else {
+			v.frozenAt = now
+		}
+		v.simulated = true
+	} else {
+		v.offset = v.frozenAt.Sub(now)
+	}
+	v.frozen = frozen
+}
+
+// Reset returns the clock to wall-clock time
+func (v *Virtual) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.simulated = false
+	v.frozen = false
+	v.offset = 0
+	v.frozenAt = time.Time{}
+}
+
+// Status describes the clock's current mode
+type Status struct {
+	Simulated bool      `json:"simulated"`
+	Frozen    bool      `json:"frozen"`
+	Now       time.Time `json:"now"`
+	RealNow   time.Time `json:"real_now"`
+	Offset    string    `json:"offset"`
+}
+
+// Status returns the clock's current mode and times
+func (v *Virtual) Status() Status {
+	now := v.Now()
+	realNow := time.Now()
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return Status{
+		Simulated: v.simulated,
+		Frozen:    v.frozen,
+		Now:       now,
+		RealNow:   realNow,
+		Offset:    now.Sub(realNow).Round(time.Second).String(),
+	}
+}
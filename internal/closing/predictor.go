@@ -0,0 +1,520 @@
+// Package closing projects where a game line is likely to close from how
+// its consensus has been moving, and checks those projections against the
+// actual close once the game starts.
+//
+// The model is deliberately simple: the consensus line's drift over the
+// lookback is expected to carry on to the close in part. How much carries
+// on is fitted per sport and market from earlier projections, by least
+// squares of the move to the close on the drift, starting from a default
+// share until a market has enough of them.
+package closing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/steam"
+)
+
+// Advice on taking the current number, from the projected move's direction
+// for the outcome
+const (
+	// BetNow is a line projected to move against the outcome before the close
+	BetNow = "bet_now"
+
+	// Wait is a line projected to move in the outcome's favor
+	Wait = "wait"
+
+	// Hold is a projected move too small to act on
+	Hold = "hold"
+)
+
+// Config holds closing line projection configuration
+type Config struct {
+	// Interval is the time between resolving projections for started games
+	Interval time.Duration
+
+	// Lookback is how far back the consensus line's drift is measured
+	Lookback time.Duration
+
+	// Carry is the share of the drift expected to carry on to the close for
+	// markets without enough resolved projections to fit their own
+	Carry float64
+
+	// MinSamples is how many resolved projections with a drift a sport's
+	// market needs before its carry is fitted
+	MinSamples int
+
+	// FitWindow is how far back resolved projections are fitted from
+	FitWindow time.Duration
+
+	// HoldPoints and HoldCents are the smallest projected moves advised on
+	HoldPoints float64
+	HoldCents  float64
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Interval:   10 * time.Minute,
+		Lookback:   3 * time.Hour,
+		Carry:      0.5,
+		MinSamples: 20,
+		FitWindow:  30 * 24 * time.Hour,
+		HoldPoints: 0.25,
+		HoldCents:  5,
+	}
+}
+
+// Projection is an outcome's projected closing line. Lines are spread and
+// total points, or American prices for moneylines; Drift and Move are in
+// the unit.
+type Projection struct {
+	GameID       string    `json:"game_id"`
+	Sport        string    `json:"sport"`
+	Market       string    `json:"market"`
+	Outcome      string    `json:"outcome"`
+	Unit         string    `json:"unit"`    // steam.Points or steam.Cents
+	Current      float64   `json:"current"` // consensus across bookmakers now
+	Drift        float64   `json:"drift"`   // consensus move over the lookback
+	Carry        float64   `json:"carry"`   // share of the drift expected to carry on
+	Fitted       bool      `json:"fitted"`  // carry fitted from resolved projections rather than the default
+	Projected    float64   `json:"projected_close"`
+	Move         float64   `json:"projected_move"`
+	Advice       string    `json:"advice"` // BetNow, Wait or Hold
+	Books        int       `json:"books"`
+	CommenceTime time.Time `json:"commence_time"`
+	ProjectedAt  time.Time `json:"projected_at"`
+}
+
+// Summary describes the projection for alert text, e.g. "Projected close
+// -4.5 from -4 now: bet now"
+func (p Projection) Summary() string {
+	format := func(v float64) string { return fmt.Sprintf("%g", v) }
+	if p.Unit == steam.Cents {
+		format = func(v float64) string { return fmt.Sprintf("%+.0f", v) }
+	}
+
+	advice := "little movement expected"
+	switch p.Advice {
+	case BetNow:
+		advice = "bet now"
+	case Wait:
+		advice = "waiting may get a better number"
+	}
+	return fmt.Sprintf("Projected close %s from %s now: %s", format(p.Projected), format(p.Current), advice)
+}
+
+// Predictor projects closing lines from odds history and resolves the
+// projections sent with alerts once their games start
+type Predictor struct {
+	config Config
+	db     *database.DB
+	clock  clock.Clock
+}
+
+// NewPredictor creates a new closing line predictor
+func NewPredictor(config Config, db *database.DB) *Predictor {
+	defaults := DefaultConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.Lookback <= 0 {
+		config.Lookback = defaults.Lookback
+	}
+	if config.Carry <= 0 || config.Carry > 1 {
+		config.Carry = defaults.Carry
+	}
+	if config.MinSamples <= 0 {
+		config.MinSamples = defaults.MinSamples
+	}
+	if config.FitWindow <= 0 {
+		config.FitWindow = defaults.FitWindow
+	}
+	if config.HoldPoints <= 0 {
+		config.HoldPoints = defaults.HoldPoints
+	}
+	if config.HoldCents <= 0 {
+		config.HoldCents = defaults.HoldCents
+	}
+	return &Predictor{
+		config: config,
+		db:     db,
+		clock:  clock.Real{},
+	}
+}
+
+// SetClock sets the clock used for drift and resolution
+func (p *Predictor) SetClock(c clock.Clock) {
+	p.clock = c
+}
+
+// Start resolves projections on every interval until the context is
+// cancelled
+func (p *Predictor) Start(ctx context.Context) {
+	log.Printf("Closing line projections starting (lookback: %v, default carry: %g)", p.config.Lookback, p.config.Carry)
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Resolve()
+		}
+	}
+}
+
+// Project returns the projected close of every outcome with odds history
+// in each of a game's markets. Games that have started have none.
+func (p *Predictor) Project(game models.Game) ([]Projection, error) {
+	now := p.clock.Now()
+	if !game.CommenceTime.After(now) {
+		return nil, nil
+	}
+
+	var markets []string
+	seen := make(map[string]bool)
+	for _, bm := range game.Bookmakers {
+		for _, market := range bm.Markets {
+			if key := string(market.Key); !seen[key] {
+				seen[key] = true
+				markets = append(markets, key)
+			}
+		}
+	}
+	sort.Strings(markets)
+
+	projections := []Projection{}
+	carries := make(map[string]carry)
+	for _, market := range markets {
+		history, err := p.db.GetOddsHistory(game.ID, market, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, outcome := range outcomes(history) {
+			pr, ok := p.project(history, outcome, now)
+			if !ok {
+				continue
+			}
+			key := market + "|" + pr.Unit
+			c, ok := carries[key]
+			if !ok {
+				if c, err = p.carry(pr.Sport, market, pr.Unit); err != nil {
+					return nil, err
+				}
+				carries[key] = c
+			}
+			p.finish(&pr, c, game.CommenceTime)
+			projections = append(projections, pr)
+		}
+	}
+	return projections, nil
+}
+
+// ProjectAlert projects one outcome's close for an alert and records the
+// projection so its accuracy is tracked. It returns nil when the outcome
+// has no odds history, such as a player prop.
+func (p *Predictor) ProjectAlert(gameID, market, outcome string, commence time.Time) *Projection {
+	now := p.clock.Now()
+	if !commence.After(now) {
+		return nil
+	}
+
+	history, err := p.db.GetOddsHistory(gameID, market, "")
+	if err != nil {
+		log.Printf("Closing: failed to get odds history for %s: %v", gameID, err)
+		return nil
+	}
+	pr, ok := p.project(history, outcome, now)
+	if !ok {
+		return nil
+	}
+	c, err := p.carry(pr.Sport, market, pr.Unit)
+	if err != nil {
+		log.Printf("Closing: failed to fit carry for %s %s: %v", pr.Sport, market, err)
+		return nil
+	}
+	p.finish(&pr, c, commence)
+
+	if _, err := p.db.SaveClosingPrediction(&database.ClosingPrediction{
+		GameID:       pr.GameID,
+		Sport:        pr.Sport,
+		Market:       pr.Market,
+		Outcome:      pr.Outcome,
+		Unit:         pr.Unit,
+		Line:         pr.Current,
+		Drift:        pr.Drift,
+		Carry:        pr.Carry,
+		Projected:    pr.Projected,
+		CommenceTime: commence,
+	}); err != nil {
+		log.Printf("Closing: failed to save projection for %s: %v", gameID, err)
+	}
+	return &pr
+}
+
+// Resolve records the closing consensus line of projections whose games
+// have started
+func (p *Predictor) Resolve() {
+	pending, err := p.db.GetPendingClosingPredictions(p.clock.Now())
+	if err != nil {
+		log.Printf("Closing: failed to get pending projections: %v", err)
+		return
+	}
+
+	histories := make(map[string][]database.OddsPoint)
+	resolved := 0
+	for _, pr := range pending {
+		key := pr.GameID + "|" + pr.Market
+		history, ok := histories[key]
+		if !ok {
+			if history, err = p.db.GetOddsHistory(pr.GameID, pr.Market, ""); err != nil {
+				log.Printf("Closing: failed to get odds history for %s: %v", pr.GameID, err)
+				continue
+			}
+			histories[key] = history
+		}
+
+		var close *float64
+		if value, books := consensusAt(history, pr.Outcome, pr.Unit, pr.CommenceTime); books > 0 {
+			line := natural(value, pr.Unit)
+			close = &line
+		}
+		if err := p.db.ResolveClosingPrediction(pr.ID, close); err != nil {
+			log.Printf("Closing: failed to resolve projection %d: %v", pr.ID, err)
+			continue
+		}
+		resolved++
+	}
+	if resolved > 0 {
+		log.Printf("Closing: resolved %d projections", resolved)
+	}
+}
+
+// project measures an outcome's consensus now and its drift over the
+// lookback
+func (p *Predictor) project(history []database.OddsPoint, outcome string, now time.Time) (Projection, bool) {
+	var first *database.OddsPoint
+	unit := steam.Cents
+	for i := range history {
+		h := &history[i]
+		if h.Outcome != outcome {
+			continue
+		}
+		if first == nil {
+			first = h
+		}
+		if h.Point != nil {
+			unit = steam.Points
+		}
+	}
+	if first == nil {
+		return Projection{}, false
+	}
+
+	current, books := consensusAt(history, outcome, unit, now)
+	if books == 0 {
+		return Projection{}, false
+	}
+	// A series starting inside the lookback drifts from its first line
+	from := now.Add(-p.config.Lookback)
+	if first.RecordedAt.After(from) {
+		from = first.RecordedAt
+	}
+	past, _ := consensusAt(history, outcome, unit, from)
+
+	return Projection{
+		GameID:      first.GameID,
+		Sport:       first.Sport,
+		Market:      first.Market,
+		Outcome:     outcome,
+		Unit:        unit,
+		Current:     current,
+		Drift:       round(current-past, unit),
+		Books:       books,
+		ProjectedAt: now,
+	}, true
+}
+
+// finish applies a carry to a measured projection, converts its lines back
+// from the unit and advises on it
+func (p *Predictor) finish(pr *Projection, c carry, commence time.Time) {
+	pr.Carry, pr.Fitted = c.share, c.fitted
+	pr.CommenceTime = commence
+
+	projected := round(pr.Current+pr.Carry*pr.Drift, pr.Unit)
+	pr.Move = round(projected-pr.Current, pr.Unit)
+	pr.Current = natural(pr.Current, pr.Unit)
+	pr.Projected = natural(projected, pr.Unit)
+
+	hold := p.config.HoldPoints
+	if pr.Unit == steam.Cents {
+		hold = p.config.HoldCents
+	}
+	gain := favor(pr.Market, pr.Outcome, pr.Unit) * pr.Move
+	switch {
+	case gain <= -hold:
+		pr.Advice = BetNow
+	case gain >= hold:
+		pr.Advice = Wait
+	default:
+		pr.Advice = Hold
+	}
+}
+
+// carry is the share of drift expected to carry on to the close
+type carry struct {
+	share  float64
+	fitted bool
+}
+
+// carry fits a sport's market from its resolved projections, falling back
+// to the default share
+func (p *Predictor) carry(sport, market, unit string) (carry, error) {
+	resolved, err := p.db.GetResolvedClosingPredictions(p.clock.Now().Add(-p.config.FitWindow), sport)
+	if err != nil {
+		return carry{}, err
+	}
+	var matching []database.ClosingPrediction
+	for _, pr := range resolved {
+		if pr.Market == market && pr.Unit == unit {
+			matching = append(matching, pr)
+		}
+	}
+	share, samples := FitCarry(matching)
+	if samples < p.config.MinSamples {
+		return carry{share: p.config.Carry}, nil
+	}
+	return carry{share: share, fitted: true}, nil
+}
+
+// FitCarry fits the share of drift that carried on to the close across
+// resolved projections, by least squares through the origin, clamped to
+// between none and all of it. It returns how many projections had a drift
+// to fit from.
+func FitCarry(predictions []database.ClosingPrediction) (float64, int) {
+	var product, square float64
+	samples := 0
+	for _, pr := range predictions {
+		if pr.Close == nil || pr.Drift == 0 {
+			continue
+		}
+		product += pr.Drift * UnitMove(pr.Line, *pr.Close, pr.Unit)
+		square += pr.Drift * pr.Drift
+		samples++
+	}
+	if samples == 0 {
+		return 0, 0
+	}
+	return math.Max(0, math.Min(1, product/square)), samples
+}
+
+// UnitMove returns the move from one line to another in a unit: points, or
+// cents between American prices
+func UnitMove(from, to float64, unit string) float64 {
+	if unit == steam.Cents {
+		return models.PriceCents(to, from)
+	}
+	return to - from
+}
+
+// outcomes lists the outcomes in odds history in the order they first
+// appear
+func outcomes(history []database.OddsPoint) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, h := range history {
+		if !seen[h.Outcome] {
+			seen[h.Outcome] = true
+			names = append(names, h.Outcome)
+		}
+	}
+	return names
+}
+
+// consensusAt returns the median of each bookmaker's last line for an
+// outcome at a time, in the unit, and how many books it's across. Prices
+// are measured in cents from even money so they can be averaged across
+// -100 and +100.
+func consensusAt(history []database.OddsPoint, outcome, unit string, t time.Time) (float64, int) {
+	latest := make(map[string]float64)
+	for _, h := range history {
+		if h.Outcome != outcome || h.RecordedAt.After(t) {
+			continue
+		}
+		if unit == steam.Points {
+			if h.Point == nil {
+				delete(latest, h.Bookmaker)
+				continue
+			}
+			latest[h.Bookmaker] = *h.Point
+		} else {
+			latest[h.Bookmaker] = models.PriceCents(h.Price, 100)
+		}
+	}
+	if len(latest) == 0 {
+		return 0, 0
+	}
+
+	values := make([]float64, 0, len(latest))
+	for _, v := range latest {
+		values = append(values, v)
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2, len(values)
+	}
+	return values[mid], len(values)
+}
+
+// natural converts a consensus value back from its unit: points stay as
+// they are and cents from even money become an American price
+func natural(value float64, unit string) float64 {
+	if unit != steam.Cents {
+		return value
+	}
+	if value >= 0 {
+		return value + 100
+	}
+	return value - 100
+}
+
+// round keeps points to a tenth and cents whole
+func round(value float64, unit string) float64 {
+	if unit == steam.Cents {
+		return math.Round(value)
+	}
+	return math.Round(value*10) / 10
+}
+
+// favor is which way a move helps a bettor on the outcome: a higher price,
+// more points on a spread, a lower total for the over and a higher one for
+// the under. It's 0 when the market has no better direction.
+func favor(market, outcome, unit string) float64 {
+	if unit == steam.Cents {
+		return 1
+	}
+	base, _ := models.SplitPeriod(models.Market(market))
+	switch {
+	case base == models.MarketSpreads:
+		return 1
+	case strings.HasSuffix(outcome, "Over"):
+		return -1
+	case strings.HasSuffix(outcome, "Under"):
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,332 @@
+// Package cluster lets several linefinder instances run side by side. A
+// Redis pub/sub bridge shares odds updates, status messages, alerts and
+// live prop paces between them, a lock in Redis elects the one instance
+// that polls, and each instance lists its WebSocket connections there.
+package cluster
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/alertstream"
+	"github.com/joshuakim/linefinder/internal/liveprops"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+// outboxSize is how many events can wait to be published before new ones
+// are dropped
+const outboxSize = 256
+
+// Event kinds on the channel
+const (
+	eventOdds   = "odds"
+	eventStatus = "status"
+	eventMsg    = "message"
+	eventAlert  = "alert"
+	eventLive   = "live"
+	eventValue  = "value_alert"
+
+	// eventTakeover tells the poller another instance has taken its lock,
+	// so it stops without waiting to find out at its next renewal
+	eventTakeover = "takeover"
+)
+
+// Config holds cluster configuration
+type Config struct {
+	// RedisURL is the Redis server, e.g. redis://:password@localhost:6379/0
+	RedisURL string
+
+	// KeyPrefix namespaces the channel and lock, so deployments can share
+	// a Redis server
+	KeyPrefix string
+
+	// LockTTL is how long the poller lock lasts without being renewed. It's
+	// renewed every third of that; an instance that can't renew it in time
+	// stops being the poller.
+	LockTTL time.Duration
+
+	// InstanceID names this instance to the others; one is made from the
+	// host name when empty
+	InstanceID string
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		KeyPrefix: "linefinder",
+		LockTTL:   15 * time.Second,
+	}
+}
+
+// event is a broadcast as sent between instances
+type event struct {
+	Source string               `json:"source"` // instance that published it
+	Kind   string               `json:"kind"`
+	Sport  models.Sport         `json:"sport,omitempty"`
+	Games  []models.Game        `json:"games,omitempty"`
+	Status string               `json:"status,omitempty"`
+	Msg    *websocket.Message   `json:"message,omitempty"`
+	Alert  *alertstream.Alert   `json:"alert,omitempty"`
+	Live   *liveprops.GameProps `json:"live,omitempty"`
+	Value  *alerts.ValueAlert   `json:"value_alert,omitempty"`
+}
+
+// Bridge relays broadcasts between instances through Redis pub/sub. Odds
+// updates from other instances are mirrored into the local store and sent
+// to local WebSocket clients; status messages and alerts go to local
+// clients and alert stream subscribers. Live prop paces go to local clients
+// and, once set, the local live prop tracker.
+type Bridge struct {
+	config Config
+	id     string
+	redis  *redisClient
+	outbox chan []byte
+
+	// Signalled when another instance takes over the poller lock
+	takenOver chan struct{}
+
+	hub       *websocket.Hub
+	dataStore *store.Store
+	stream    *alertstream.Stream
+	live      *liveprops.Tracker
+}
+
+// New connects to Redis and creates a bridge. Call SetRelay on the hub and
+// stream with it, then Start it.
+func New(config Config, hub *websocket.Hub, dataStore *store.Store, stream *alertstream.Stream) (*Bridge, error) {
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = DefaultConfig().KeyPrefix
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = DefaultConfig().LockTTL
+	}
+
+	client, err := newRedisClient(config.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := client.Do("PING"); err != nil {
+		return nil, fmt.Errorf("connecting to Redis: %w", err)
+	}
+
+	if config.InstanceID == "" {
+		config.InstanceID = NewInstanceID()
+	}
+
+	return &Bridge{
+		config:    config,
+		id:        config.InstanceID,
+		redis:     client,
+		outbox:    make(chan []byte, outboxSize),
+		takenOver: make(chan struct{}, 1),
+		hub:       hub,
+		dataStore: dataStore,
+		stream:    stream,
+	}, nil
+}
+
+// ID identifies this instance in the cluster
+func (b *Bridge) ID() string {
+	return b.id
+}
+
+// SetLiveProps mirrors other instances' live prop paces into a tracker, so
+// this instance's API can answer for live games
+func (b *Bridge) SetLiveProps(t *liveprops.Tracker) {
+	b.live = t
+}
+
+// channel is the pub/sub channel events are sent on
+func (b *Bridge) channel() string {
+	return b.config.KeyPrefix + ":events"
+}
+
+// Start publishes and receives events until the context is cancelled
+func (b *Bridge) Start(ctx context.Context) {
+	log.Printf("Cluster bridge starting (instance: %s, channel: %s)", b.id, b.channel())
+	go b.publishLoop(ctx)
+	go b.reportConnections(ctx)
+	b.subscribeLoop(ctx)
+}
+
+// PublishOdds shares an odds update
+func (b *Bridge) PublishOdds(sport models.Sport, games []models.Game) {
+	b.publish(event{Kind: eventOdds, Sport: sport, Games: games})
+}
+
+// PublishStatus shares a status message
+func (b *Bridge) PublishStatus(status string) {
+	b.publish(event{Kind: eventStatus, Status: status})
+}
+
+// PublishMessage shares a message for every client
+func (b *Bridge) PublishMessage(msg websocket.Message) {
+	b.publish(event{Kind: eventMsg, Msg: &msg})
+}
+
+// PublishAlert shares an alert
+func (b *Bridge) PublishAlert(a alertstream.Alert) {
+	b.publish(event{Kind: eventAlert, Alert: &a})
+}
+
+// PublishLiveProps shares a live game's prop paces
+func (b *Bridge) PublishLiveProps(live liveprops.GameProps) {
+	b.publish(event{Kind: eventLive, Live: &live})
+}
+
+// PublishValueAlert shares a value alert
+func (b *Bridge) PublishValueAlert(alert alerts.ValueAlert) {
+	b.publish(event{Kind: eventValue, Value: &alert})
+}
+
+// publish queues an event without blocking the broadcaster
+func (b *Bridge) publish(e event) {
+	e.Source = b.id
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("Cluster: Failed to marshal %s event: %v", e.Kind, err)
+		return
+	}
+	select {
+	case b.outbox <- data:
+	default:
+		log.Printf("Cluster: Outbox full, dropping %s event", e.Kind)
+	}
+}
+
+// publishLoop sends queued events
+func (b *Bridge) publishLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			b.redis.Close()
+			return
+		case data := <-b.outbox:
+			if _, err := b.redis.Do("PUBLISH", b.channel(), string(data)); err != nil {
+				log.Printf("Cluster: Failed to publish event: %v", err)
+			}
+		}
+	}
+}
+
+// subscribeLoop receives events, reconnecting with backoff when the
+// subscription drops
+func (b *Bridge) subscribeLoop(ctx context.Context) {
+	backoff := time.Second
+	for {
+		err := b.subscribe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Cluster: Subscription lost, retrying in %v: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// subscribe reads events on a dedicated connection until it fails or the
+// context is cancelled
+func (b *Bridge) subscribe(ctx context.Context) error {
+	conn, r, err := b.redis.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	if err := writeCommand(conn, []string{"SUBSCRIBE", b.channel()}); err != nil {
+		return err
+	}
+	return b.receive(r)
+}
+
+// receive handles messages on a subscribed connection
+func (b *Bridge) receive(r *bufio.Reader) error {
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return err
+		}
+		msg, ok := reply.([]interface{})
+		if !ok || len(msg) != 3 || msg[0] != "message" {
+			continue // subscribe confirmations
+		}
+		data, _ := msg[2].(string)
+
+		var e event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			log.Printf("Cluster: Ignoring malformed event: %v", err)
+			continue
+		}
+		if e.Source == b.id {
+			continue
+		}
+		b.handle(e)
+	}
+}
+
+// handle delivers another instance's event locally
+func (b *Bridge) handle(e event) {
+	switch e.Kind {
+	case eventOdds:
+		b.dataStore.Mirror(e.Sport, e.Games)
+		b.hub.DeliverOdds(e.Sport, e.Games)
+	case eventStatus:
+		b.hub.DeliverStatus(e.Status)
+	case eventMsg:
+		if e.Msg != nil {
+			b.hub.DeliverMessage(*e.Msg)
+		}
+	case eventAlert:
+		if e.Alert != nil {
+			b.stream.Deliver(*e.Alert)
+		}
+	case eventValue:
+		if e.Value != nil {
+			b.hub.DeliverValueAlert(*e.Value)
+		}
+	case eventLive:
+		if e.Live != nil {
+			if b.live != nil {
+				b.live.Mirror(*e.Live)
+			}
+			b.hub.DeliverLiveProps(*e.Live)
+		}
+	case eventTakeover:
+		select {
+		case b.takenOver <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// NewInstanceID names an instance by host, with a random suffix so
+// instances on one host differ
+func NewInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "linefinder"
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return host + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,97 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+// connectionsInterval is how often each instance writes its connections.
+// The entry lasts three intervals, so an instance that stops drops out of
+// the listing shortly after.
+const connectionsInterval = 10 * time.Second
+
+// connectionsKey holds an instance's connections
+func (b *Bridge) connectionsKey(id string) string {
+	return b.config.KeyPrefix + ":connections:" + id
+}
+
+// reportConnections writes this instance's connections on every interval
+// until the context is cancelled
+func (b *Bridge) reportConnections(ctx context.Context) {
+	ticker := time.NewTicker(connectionsInterval)
+	defer ticker.Stop()
+
+	for {
+		b.writeConnections()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeConnections stores this instance's connections with a TTL
+func (b *Bridge) writeConnections() {
+	data, err := json.Marshal(b.hub.Connections())
+	if err != nil {
+		log.Printf("Cluster: Failed to marshal connections: %v", err)
+		return
+	}
+	ttl := strconv.FormatInt((3 * connectionsInterval).Milliseconds(), 10)
+	if _, err := b.redis.Do("SET", b.connectionsKey(b.id), string(data), "PX", ttl); err != nil {
+		log.Printf("Cluster: Failed to write connections: %v", err)
+	}
+}
+
+// Connections lists every instance's WebSocket connections, this one's as
+// of now and the others' as of their last write, ordered by instance
+func (b *Bridge) Connections() ([]websocket.InstanceConnections, error) {
+	all := []websocket.InstanceConnections{b.hub.Connections()}
+
+	cursor := "0"
+	for {
+		reply, err := b.redis.Do("SCAN", cursor, "MATCH", b.connectionsKey("*"), "COUNT", "100")
+		if err != nil {
+			return nil, err
+		}
+		page, _ := reply.([]interface{})
+		if len(page) != 2 {
+			break
+		}
+		keys, _ := page[1].([]interface{})
+		for _, k := range keys {
+			key, _ := k.(string)
+			if key == b.connectionsKey(b.id) {
+				continue
+			}
+			// Gone if it expired since the scan
+			value, err := b.redis.Do("GET", key)
+			if err != nil {
+				return nil, err
+			}
+			data, ok := value.(string)
+			if !ok {
+				continue
+			}
+			var conns websocket.InstanceConnections
+			if err := json.Unmarshal([]byte(data), &conns); err != nil {
+				log.Printf("Cluster: Ignoring malformed connections at %s: %v", key, err)
+				continue
+			}
+			all = append(all, conns)
+		}
+		if cursor, _ = page[0].(string); cursor == "0" || cursor == "" {
+			break
+		}
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Instance < all[j].Instance })
+	return all, nil
+}
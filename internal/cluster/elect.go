@@ -0,0 +1,98 @@
+package cluster
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+)
+
+// The lock is only renewed or released by the instance holding it. Scripts
+// run atomically, so another instance can't take it in between.
+const (
+	renewScript   = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+	releaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+)
+
+// lockKey is the poller lock, holding the ID of the instance that has it
+func (b *Bridge) lockKey() string {
+	return b.config.KeyPrefix + ":poller"
+}
+
+// Elect campaigns for the poller lock until the context is cancelled,
+// calling elected once this instance holds it. If the lock is then lost,
+// because it was taken over or couldn't be renewed before expiring, lost
+// is called and Elect returns. The lock is released on cancel so another
+// instance can take over straight away.
+func (b *Bridge) Elect(ctx context.Context, elected func(), lost func()) {
+	log.Printf("Cluster: campaigning for poller lock %s (ttl: %v)", b.lockKey(), b.config.LockTTL)
+	b.campaign(ctx, false, elected, lost)
+}
+
+// TakeOver takes the poller lock from whichever instance holds it, tells
+// that instance to stop, and calls elected. The lock is then held as in
+// Elect, in the background.
+func (b *Bridge) TakeOver(ctx context.Context, elected func(), lost func()) error {
+	ttl := strconv.FormatInt(b.config.LockTTL.Milliseconds(), 10)
+	if _, err := b.redis.Do("SET", b.lockKey(), b.id, "PX", ttl); err != nil {
+		return err
+	}
+	b.publish(event{Kind: eventTakeover})
+	log.Printf("Cluster: %s took over poller lock %s", b.id, b.lockKey())
+
+	elected()
+	go b.campaign(ctx, true, func() {}, lost)
+	return nil
+}
+
+// campaign takes the lock when it's free and renews it while held
+func (b *Bridge) campaign(ctx context.Context, leading bool, elected func(), lost func()) {
+	key := b.lockKey()
+	ttl := strconv.FormatInt(b.config.LockTTL.Milliseconds(), 10)
+	ticker := time.NewTicker(b.config.LockTTL / 3)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		now := time.Now()
+		if !leading {
+			reply, err := b.redis.Do("SET", key, b.id, "NX", "PX", ttl)
+			if err != nil {
+				log.Printf("Cluster: Failed to take poller lock: %v", err)
+			} else if reply == "OK" {
+				leading, renewed = true, now
+				log.Printf("Cluster: %s elected poller", b.id)
+				elected()
+			}
+		} else {
+			reply, err := b.redis.Do("EVAL", renewScript, "1", key, b.id, ttl)
+			switch {
+			case err == nil && reply == int64(1):
+				renewed = now
+			case err == nil:
+				log.Printf("Cluster: Poller lock taken over by another instance")
+				lost()
+				return
+			case now.Sub(renewed) >= b.config.LockTTL:
+				log.Printf("Cluster: Poller lock expired without renewal: %v", err)
+				lost()
+				return
+			default:
+				log.Printf("Cluster: Failed to renew poller lock, retrying: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if leading {
+				if _, err := b.redis.Do("EVAL", releaseScript, "1", key, b.id); err != nil {
+					log.Printf("Cluster: Failed to release poller lock: %v", err)
+				}
+			}
+			return
+		case <-ticker.C:
+		case <-b.takenOver:
+			// Checked by renewing now rather than at the next tick
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Package contract lists the types the REST and WebSocket APIs send and
+// receive, generates a JSON Schema, an OpenAPI document and TypeScript
+// types from them, and checks JSON documents against the schema. `make generate-clients` writes
+// both; the frontend imports the TypeScript types, so a model change that
+// breaks the UI shows up in its type check, and `make check-contract`
+// checks live responses against the published schema.
+package contract
+
+//go:generate go run ../../cmd/gentypes -schema ../../docs/api-schema.json -ts ../../web/src/api/types.d.ts -openapi ../../docs/openapi.json
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/api"
+	"github.com/joshuakim/linefinder/internal/bets"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/liveprops"
+	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+// Entry is a type on the wire and where it's used
+type Entry struct {
+	Usage string
+	Value interface{}
+}
+
+// Entries are the API's top-level types. Types they reference are included
+// under their Go names.
+var Entries = []Entry{
+	{"GET /api/v1/odds/{sport}", api.OddsResponse{}},
+	{"GET /api/v1/props/{sport}/{gameID}", api.PropsResponse{}},
+	{"GET /api/v1/averages/{sport}/{gameID} (array)", store.PlayerAverages{}},
+	{"GET /api/v1/injuries/{sport}/{gameID}", store.GameInjuries{}},
+	{"GET /api/v1/topplays", api.TopPlaysResponse{}},
+	{"GET /api/v1/steam", api.SteamResponse{}},
+	{"GET /api/v1/live/props/{gameID}", liveprops.GameProps{}},
+	{"GET, PUT /api/v1/preferences", database.Preferences{}},
+	{"GET, PUT /api/v1/preferences/sports", api.SportPreferences{}},
+	{"GET, PUT /api/v1/preferences/subscriptions", api.AlertSubscriptions{}},
+	{"GET /api/v1/preferences/presets", api.PresetsResponse{}},
+	{"POST /api/v1/preferences/preset/{name}", api.PresetResponse{}},
+	{"GET /api/v1/vapid-public-key", api.VAPIDKeyResponse{}},
+	{"PUT /api/v1/preferences, POST /api/v1/unsubscribe, DELETE /api/v1/preferences/presets/{name} and /api/v1/webhooks/{id}", api.UndoableResponse{}},
+	{"POST /api/v1/undo", api.UndoResponse{}},
+	{"GET /api/v1/bets (bets), POST /api/v1/bets (bet)", database.Bet{}},
+	{"GET /api/v1/bankroll", bets.Bankroll{}},
+	{"GET /api/v1/stats/public", api.PublicStatsResponse{}},
+	{"Error responses", api.ErrorResponse{}},
+	{"WebSocket /api/v1/ws, server to client", websocket.Message{}},
+	{"WebSocket /api/v1/ws, client to server", websocket.ClientMessage{}},
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// kind is how a Go type is encoded
+type kind int
+
+const (
+	kindUnknown kind = iota
+	kindBool
+	kindInteger
+	kindNumber
+	kindString
+	kindTime
+	kindArray
+	kindMap
+	kindObject
+)
+
+// typeRef is a Go type as it appears in JSON. Named types refer to a
+// definition by name; others are described in place.
+type typeRef struct {
+	kind     kind
+	name     string   // definition name of a named struct or scalar
+	elem     *typeRef // element of an array or map
+	fields   []field  // fields of an anonymous struct
+	nullable bool     // nil pointers, slices and maps encode as null
+}
+
+// field is a struct field as encoded by encoding/json
+type field struct {
+	name     string
+	optional bool // omitempty
+	typ      typeRef
+}
+
+// definition is a named type in the contract
+type definition struct {
+	name   string
+	usage  string
+	goType reflect.Type
+	typ    typeRef // for structs, kindObject with fields
+}
+
+// contract is every definition reachable from the entries
+type contract struct {
+	defs   map[string]*definition
+	byType map[reflect.Type]string
+}
+
+// build walks the entries and every type they reference
+func build(entries []Entry) (*contract, error) {
+	c := &contract{
+		defs:   make(map[string]*definition),
+		byType: make(map[reflect.Type]string),
+	}
+	for _, e := range entries {
+		t := reflect.TypeOf(e.Value)
+		if _, err := c.ref(t); err != nil {
+			return nil, err
+		}
+		if def := c.defs[c.byType[t]]; def != nil {
+			def.usage = e.Usage
+		}
+	}
+	return c, nil
+}
+
+// sorted returns the definitions by name
+func (c *contract) sorted() []*definition {
+	defs := make([]*definition, 0, len(c.defs))
+	for _, d := range c.defs {
+		defs = append(defs, d)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].name < defs[j].name })
+	return defs
+}
+
+// ref describes a type, adding definitions for named types
+func (c *contract) ref(t reflect.Type) (typeRef, error) {
+	if t == timeType {
+		return typeRef{kind: kindTime}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := c.ref(t.Elem())
+		elem.nullable = true
+		return elem, err
+	case reflect.Interface:
+		return typeRef{kind: kindUnknown}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is base64
+			return typeRef{kind: kindString}, nil
+		}
+		elem, err := c.ref(t.Elem())
+		if err != nil {
+			return typeRef{}, err
+		}
+		return typeRef{kind: kindArray, elem: &elem, nullable: t.Kind() == reflect.Slice}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return typeRef{}, fmt.Errorf("contract: %s: map keys must be strings", t)
+		}
+		elem, err := c.ref(t.Elem())
+		if err != nil {
+			return typeRef{}, err
+		}
+		return typeRef{kind: kindMap, elem: &elem, nullable: true}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			fields, err := c.fields(t)
+			return typeRef{kind: kindObject, fields: fields}, err
+		}
+		return c.named(t)
+	}
+
+	k, ok := scalarKind(t.Kind())
+	if !ok {
+		return typeRef{}, fmt.Errorf("contract: %s can't be encoded as JSON", t)
+	}
+	// Named scalars such as models.Sport get a definition of their own
+	if t.PkgPath() != "" {
+		return c.named(t)
+	}
+	return typeRef{kind: k}, nil
+}
+
+// named adds a definition for a named type and refers to it
+func (c *contract) named(t reflect.Type) (typeRef, error) {
+	if name, ok := c.byType[t]; ok {
+		return typeRef{name: name}, nil
+	}
+	name := t.Name()
+	if other, ok := c.defs[name]; ok {
+		return typeRef{}, fmt.Errorf("contract: %s and %s are both named %s", other.goType, t, name)
+	}
+
+	// Registered before the fields so recursive types terminate
+	def := &definition{name: name, goType: t}
+	c.defs[name] = def
+	c.byType[t] = name
+
+	if t.Kind() == reflect.Struct {
+		fields, err := c.fields(t)
+		if err != nil {
+			return typeRef{}, err
+		}
+		def.typ = typeRef{kind: kindObject, fields: fields}
+	} else {
+		k, _ := scalarKind(t.Kind())
+		def.typ = typeRef{kind: k}
+	}
+	return typeRef{name: name}, nil
+}
+
+// fields lists a struct's fields the way encoding/json encodes them,
+// flattening embedded structs
+func (c *contract) fields(t reflect.Type) ([]field, error) {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded, err := c.fields(ft)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, embedded...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		typ, err := c.ref(ft)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t, f.Name, err)
+		}
+		optional := false
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				// Empty values, including nil ones, are left out. Structs
+				// are never empty.
+				if ft.Kind() != reflect.Struct {
+					optional, typ.nullable = true, false
+				}
+			case "string":
+				typ = typeRef{kind: kindString}
+			}
+		}
+		fields = append(fields, field{name: name, optional: optional, typ: typ})
+	}
+	return fields, nil
+}
+
+func scalarKind(k reflect.Kind) (kind, bool) {
+	switch k {
+	case reflect.Bool:
+		return kindBool, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return kindInteger, true
+	case reflect.Float32, reflect.Float64:
+		return kindNumber, true
+	case reflect.String:
+		return kindString, true
+	}
+	return kindUnknown, false
+}
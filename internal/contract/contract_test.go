@@ -0,0 +1,281 @@
+package contract_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/api"
+	"github.com/joshuakim/linefinder/internal/contract"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/metrics"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/notifications"
+	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/steam"
+	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+// schemaPath is the published JSON Schema, relative to this package
+const schemaPath = "../../docs/api-schema.json"
+
+// check is a request and the definition its response must match
+type check struct {
+	method string
+	path   string
+	body   string
+	status int
+	def    string
+
+	// field checks one field of the response instead of all of it. A "[]"
+	// suffix (or just "[]" for the response itself) checks each element.
+	field string
+}
+
+var checks = []check{
+	{method: "GET", path: "/api/v1/odds/nba", status: 200, def: "OddsResponse"},
+	{method: "GET", path: "/api/v1/props/nba/" + fixtureGameID, status: 200, def: "PropsResponse"},
+	{method: "GET", path: "/api/v1/averages/nba/" + fixtureGameID, status: 200, def: "PlayerAverages", field: "[]"},
+	{method: "GET", path: "/api/v1/injuries/nba/" + fixtureGameID, status: 200, def: "GameInjuries"},
+	{method: "GET", path: "/api/v1/topplays?sport=nba&limit=5", status: 200, def: "TopPlaysResponse"},
+	{method: "GET", path: "/api/v1/steam?sport=nba", status: 200, def: "SteamResponse"},
+	{method: "GET", path: "/api/v1/preferences", status: 200, def: "Preferences"},
+	{method: "GET", path: "/api/v1/vapid-public-key", status: 200, def: "VAPIDKeyResponse"},
+	{
+		method: "POST", path: "/api/v1/bets", status: 201, def: "Bet", field: "bet",
+		body: `{"game_id": "` + fixtureGameID + `", "market": "spreads", "selection": "Boston Celtics", "point": -4.5, "bookmaker": "draftkings", "price": -110, "stake": 50}`,
+	},
+	{method: "GET", path: "/api/v1/bets", status: 200, def: "Bet", field: "bets[]"},
+	{method: "GET", path: "/api/v1/bankroll", status: 200, def: "Bankroll"},
+	{method: "GET", path: "/api/v1/odds/curling", status: 400, def: "ErrorResponse"},
+	{method: "GET", path: "/api/v1/nope", status: 404, def: "ErrorResponse"},
+
+	// Legacy unversioned paths are aliases of /api/v1/
+	{method: "GET", path: "/api/odds/nba", status: 200, def: "OddsResponse"},
+}
+
+const fixtureGameID = "contract-nba-1"
+
+// TestContract serves the API with fixture data and checks its responses
+// and WebSocket messages against the published schema, so a field renamed
+// or retyped without regenerating it fails. Checks run in order, since the
+// bet listed is the one logged before it.
+func TestContract(t *testing.T) {
+	// The server's logs would bury the mismatches
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	schema, err := os.ReadFile(schemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := contract.NewValidator(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := database.New(filepath.Join(t.TempDir(), "contract.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	games := fixtureGames(time.Now())
+	dataStore := store.New()
+	dataStore.UpdateGames(games)
+
+	m := metrics.New()
+	hub := websocket.NewHub(m, 0)
+	go hub.Run()
+	notificationSvc := notifications.NewService(notifications.Config{VAPIDPublicKey: "contract-public-key"}, db, hub)
+
+	handler := api.NewHandler(service.NewOddsService(nil, dataStore), nil, hub, nil, m, db, alerts.NewDetector(db), notificationSvc)
+	handler.SetSteamDetector(steam.NewDetector(steam.DefaultConfig(), dataStore))
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	for _, c := range checks {
+		t.Run(fmt.Sprintf("%s %s (%s)", c.method, c.path, c.def), func(t *testing.T) {
+			problems, err := c.run(server.URL, validator)
+			report(t, problems, err)
+		})
+	}
+	t.Run("WebSocket /api/v1/ws (ClientMessage, Message)", func(t *testing.T) {
+		problems, err := checkWebSocket(server.URL, hub, games, validator)
+		report(t, problems, err)
+	})
+}
+
+// run makes the check's request and validates the response
+func (c check) run(baseURL string, v *contract.Validator) ([]string, error) {
+	req, err := http.NewRequest(c.method, baseURL+c.path, strings.NewReader(c.body))
+	if err != nil {
+		return nil, err
+	}
+	if c.body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != c.status {
+		return nil, fmt.Errorf("status %d, want %d: %s", resp.StatusCode, c.status, body)
+	}
+
+	docs := []json.RawMessage{body}
+	field, each := strings.CutSuffix(c.field, "[]")
+	if field != "" {
+		var envelope map[string]json.RawMessage
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return nil, err
+		}
+		doc, ok := envelope[field]
+		if !ok {
+			return []string{"missing field " + field}, nil
+		}
+		docs = []json.RawMessage{doc}
+	}
+	if each {
+		var elems []json.RawMessage
+		if err := json.Unmarshal(docs[0], &elems); err != nil {
+			return nil, err
+		}
+		if len(elems) == 0 {
+			return nil, fmt.Errorf("no elements to check")
+		}
+		docs = elems
+	}
+
+	var problems []string
+	for _, doc := range docs {
+		found, err := v.Validate(c.def, doc)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, found...)
+	}
+	return problems, nil
+}
+
+// checkWebSocket subscribes, pings and receives a broadcast, checking what
+// the client sends and every message it gets back
+func checkWebSocket(baseURL string, hub *websocket.Hub, games []models.Game, v *contract.Validator) ([]string, error) {
+	conn, _, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(baseURL, "http")+"/api/v1/ws", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var problems []string
+	for _, msg := range []websocket.ClientMessage{
+		{Type: websocket.MessageTypeSubscribe, Sport: "nba"},
+		{Type: "ping"},
+	} {
+		data, _ := json.Marshal(msg)
+		found, err := v.Validate("ClientMessage", data)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, found...)
+		if err := conn.WriteMessage(gorilla.TextMessage, data); err != nil {
+			return nil, err
+		}
+	}
+
+	// A subscription status and a pong, then the broadcast. Frames can
+	// batch several messages, one per line.
+	want := []string{websocket.MessageTypeStatus, websocket.MessageTypePong, websocket.MessageTypeOddsUpdate}
+	var got []string
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for len(got) < len(want) {
+		if len(got) == 2 {
+			hub.Broadcast(models.SportNBA, games)
+		}
+		_, frame, err := conn.ReadMessage()
+		if err != nil {
+			return nil, fmt.Errorf("after %v: %w", got, err)
+		}
+		for _, data := range strings.Split(string(frame), "\n") {
+			found, err := v.Validate("Message", []byte(data))
+			if err != nil {
+				return nil, err
+			}
+			problems = append(problems, found...)
+
+			var msg websocket.Message
+			json.Unmarshal([]byte(data), &msg)
+			got = append(got, msg.Type)
+		}
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		return nil, fmt.Errorf("got messages %v, want %v", got, want)
+	}
+	return problems, nil
+}
+
+// report fails a check with its error or each mismatch found
+func report(t *testing.T, problems []string, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		t.Error(p)
+	}
+	if len(problems) > 0 {
+		t.Log("If the change is intended, run `make generate-clients` and commit the schema.")
+	}
+}
+
+// fixtureGames is an NBA game later today with every main market priced
+// at two books
+func fixtureGames(now time.Time) []models.Game {
+	spread, total := -4.5, 221.5
+	plus, under := 4.5, 221.5
+	markets := []models.MarketData{
+		{Key: models.MarketH2H, Outcomes: []models.Outcome{
+			{Name: "Boston Celtics", Price: -180},
+			{Name: "Los Angeles Lakers", Price: 155},
+		}},
+		{Key: models.MarketSpreads, Outcomes: []models.Outcome{
+			{Name: "Boston Celtics", Price: -110, Point: &spread},
+			{Name: "Los Angeles Lakers", Price: -110, Point: &plus},
+		}},
+		{Key: models.MarketTotals, Outcomes: []models.Outcome{
+			{Name: "Over", Price: -108, Point: &total},
+			{Name: "Under", Price: -112, Point: &under},
+		}},
+	}
+	return []models.Game{{
+		ID:           fixtureGameID,
+		SportKey:     models.SportNBA,
+		SportTitle:   "NBA",
+		CommenceTime: now.Add(3 * time.Hour).UTC().Truncate(time.Second),
+		HomeTeam:     "Boston Celtics",
+		AwayTeam:     "Los Angeles Lakers",
+		Bookmakers: []models.Bookmaker{
+			{Key: "draftkings", Title: "DraftKings", LastUpdate: now.UTC(), Markets: markets},
+			{Key: "fanduel", Title: "FanDuel", LastUpdate: now.UTC(), Markets: markets},
+		},
+	}}
+}
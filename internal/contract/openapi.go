@@ -0,0 +1,333 @@
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/api"
+	"github.com/joshuakim/linefinder/internal/bets"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/liveprops"
+	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/version"
+)
+
+// Param is a path or query parameter of an operation
+type Param struct {
+	Name        string
+	In          string // "path" or "query"
+	Type        string // JSON Schema type; "string" when empty
+	Description string
+}
+
+// Operation is an endpoint in the OpenAPI document: its parameters and the
+// handler layer's request and response types
+type Operation struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	Params  []Param
+
+	// Request is the JSON body, nil for none. Response is the success
+	// body; Status is its code, 200 when zero.
+	Request  interface{}
+	Response interface{}
+	Status   int
+}
+
+// Path parameters shared by several operations
+var (
+	sportParam   = Param{Name: "sport", In: "path", Description: "nfl, nba, or an enabled sport key"}
+	gameIDParam  = Param{Name: "gameID", In: "path", Description: "Odds API event ID"}
+	alertIDParam = Param{Name: "id", In: "path", Type: "integer", Description: "alert_history ID"}
+)
+
+// Operations are the endpoints published at /api/v1/openapi.json
+var Operations = []Operation{
+	{
+		Method: http.MethodGet, Path: "/api/v1/odds/{sport}", Tag: "odds",
+		Summary:  "A sport's games with their odds",
+		Params:   []Param{sportParam},
+		Response: api.OddsResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/compare/{gameID}", Tag: "odds",
+		Summary:  "A game's best prices across bookmakers",
+		Params:   []Param{gameIDParam},
+		Response: api.CompareResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/props/{sport}/{gameID}", Tag: "props",
+		Summary:  "A game's player props and the value alerts in them",
+		Params:   []Param{sportParam, gameIDParam},
+		Response: api.PropsResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/averages/{sport}/{gameID}", Tag: "props",
+		Summary:  "Season averages for a game's players",
+		Params:   []Param{sportParam, gameIDParam},
+		Response: []store.PlayerAverages{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/injuries/{sport}/{gameID}", Tag: "props",
+		Summary:  "A game's injury report",
+		Params:   []Param{sportParam, gameIDParam},
+		Response: store.GameInjuries{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/live/props/{gameID}", Tag: "props",
+		Summary:  "A live NBA game's box score against each prop's pre-game line",
+		Params:   []Param{gameIDParam},
+		Response: liveprops.GameProps{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/alerts/check", Tag: "alerts",
+		Summary:  "Scan a sport's upcoming games for value alerts",
+		Params:   []Param{{Name: "sport", In: "query", Description: "Sport to scan (default nba)"}},
+		Response: api.CheckAlertsResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/topplays", Tag: "alerts",
+		Summary: "A sport's current value opportunities ranked by score",
+		Params: []Param{
+			{Name: "sport", In: "query", Description: "Sport to rank (default nba)"},
+			{Name: "limit", In: "query", Type: "integer", Description: "Plays to return, 1 to 50 (default 10)"},
+		},
+		Response: api.TopPlaysResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/steam", Tag: "odds",
+		Summary:  "Lines that moved the same way at several books in the last few hours",
+		Params:   []Param{{Name: "sport", In: "query", Description: "Sport to list (default every sport)"}},
+		Response: api.SteamResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/alerts", Tag: "alerts",
+		Summary:  "Stored alerts by ID",
+		Params:   []Param{{Name: "ids", In: "query", Description: "Comma-separated alert IDs, at most 100"}},
+		Response: api.AlertsResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/alerts/{id}", Tag: "alerts",
+		Summary:  "A stored alert with its current line and lifecycle transitions",
+		Params:   []Param{alertIDParam},
+		Response: api.AlertDetail{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/alerts/{id}/feedback", Tag: "alerts",
+		Summary:  "Rate an alert",
+		Params:   []Param{alertIDParam},
+		Request:  api.AlertFeedbackRequest{},
+		Response: api.AlertFeedbackResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/preferences", Tag: "preferences",
+		Summary:  "Notification and alert preferences",
+		Response: database.Preferences{},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/v1/preferences", Tag: "preferences",
+		Summary:  "Replace the preferences",
+		Request:  database.Preferences{},
+		Response: api.UndoableResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/preferences/sports", Tag: "preferences",
+		Summary:  "Whether alerts are on for each sport",
+		Response: api.SportPreferences{},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/v1/preferences/sports", Tag: "preferences",
+		Summary:  "Turn alerts on or off for the sports named",
+		Request:  api.SportPreferences{},
+		Response: api.SportPreferences{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/preferences/subscriptions", Tag: "preferences",
+		Summary:  "Whether each alert category is delivered over each channel",
+		Response: api.AlertSubscriptions{},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/v1/preferences/subscriptions", Tag: "preferences",
+		Summary:  "Subscribe or unsubscribe alert categories per channel",
+		Request:  api.AlertSubscriptions{},
+		Response: api.AlertSubscriptions{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/preferences/presets", Tag: "preferences",
+		Summary:  "Built-in and saved presets of alert settings",
+		Response: api.PresetsResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/preferences/presets", Tag: "preferences",
+		Summary:  "Save the current alert settings as a named preset",
+		Request:  api.SavePresetRequest{},
+		Response: database.PreferencePreset{},
+		Status:   http.StatusCreated,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/preferences/preset/{name}", Tag: "preferences",
+		Summary:  "Apply a preset's thresholds, confidence filter, batching and quiet hours",
+		Params:   []Param{{Name: "name", In: "path", Description: "conservative, balanced, aggressive, or a saved preset"}},
+		Response: api.PresetResponse{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/v1/preferences/presets/{name}", Tag: "preferences",
+		Summary:  "Delete a saved preset",
+		Params:   []Param{{Name: "name", In: "path", Description: "A saved preset"}},
+		Response: api.UndoableResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/unsubscribe", Tag: "preferences",
+		Summary:  "Turn off WebSocket and push notifications",
+		Response: api.UndoableResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/undo", Tag: "preferences",
+		Summary:  "Reverse a preference change, unsubscribe or delete within the undo window",
+		Request:  api.UndoRequest{},
+		Response: api.UndoResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/vapid-public-key", Tag: "preferences",
+		Summary:  "The key browsers subscribe to push notifications with",
+		Response: api.VAPIDKeyResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/bankroll", Tag: "bets",
+		Summary:  "ROI, profit and win rate over logged bets",
+		Params:   []Param{{Name: "unit", In: "query", Type: "number", Description: "Unit size (default: average stake)"}},
+		Response: bets.Bankroll{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/stats/public", Tag: "stats",
+		Summary:  "Coarse stats about the service for a public landing page",
+		Response: api.PublicStatsResponse{},
+	},
+}
+
+// OpenAPI returns an OpenAPI 3.1 document describing the operations, with
+// a schema under components for every type they use. 3.1 schemas are JSON
+// Schema, so they match the ones in Schema.
+func OpenAPI(ops []Operation) ([]byte, error) {
+	c := &contract{
+		defs:   make(map[string]*definition),
+		byType: make(map[reflect.Type]string),
+	}
+	const refBase = "#/components/schemas/"
+
+	body := func(v interface{}) (map[string]interface{}, error) {
+		t, err := c.ref(reflect.TypeOf(v))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schemaFor(t, refBase)},
+		}, nil
+	}
+	errorBody, err := body(api.ErrorResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]map[string]interface{})
+	for _, op := range ops {
+		params := make([]interface{}, 0, len(op.Params))
+		for _, p := range op.Params {
+			typ := p.Type
+			if typ == "" {
+				typ = "string"
+			}
+			params = append(params, map[string]interface{}{
+				"name":        p.Name,
+				"in":          p.In,
+				"required":    p.In == "path",
+				"description": p.Description,
+				"schema":      map[string]interface{}{"type": typ},
+			})
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		content, err := body(op.Response)
+		if err != nil {
+			return nil, err
+		}
+		operation := map[string]interface{}{
+			"summary":     op.Summary,
+			"operationId": operationID(op),
+			"tags":        []string{op.Tag},
+			"parameters":  params,
+			"responses": map[string]interface{}{
+				strconv.Itoa(status): map[string]interface{}{
+					"description": http.StatusText(status),
+					"content":     content,
+				},
+				"default": map[string]interface{}{
+					"description": "Error",
+					"content":     errorBody,
+				},
+			},
+		}
+		if op.Request != nil {
+			content, err := body(op.Request)
+			if err != nil {
+				return nil, err
+			}
+			operation["requestBody"] = map[string]interface{}{"required": true, "content": content}
+		}
+
+		if paths[op.Path] == nil {
+			paths[op.Path] = make(map[string]interface{})
+		}
+		paths[op.Path][strings.ToLower(op.Method)] = operation
+	}
+
+	schemas := make(map[string]interface{}, len(c.defs))
+	for _, d := range c.sorted() {
+		schemas[d.name] = schemaFor(d.typ, refBase)
+	}
+
+	out, err := json.MarshalIndent(map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":   "LineFinder API",
+			"version": version.Version,
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// operationID names an operation from its method and path, e.g.
+// getPropsBySportAndGameID for GET /api/v1/props/{sport}/{gameID}
+func operationID(op Operation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	params := 0
+	for _, part := range strings.Split(strings.TrimPrefix(op.Path, "/api/v1/"), "/") {
+		if strings.HasPrefix(part, "{") {
+			if params == 0 {
+				b.WriteString("By")
+			} else {
+				b.WriteString("And")
+			}
+			params++
+			part = strings.Trim(part, "{}")
+		}
+		for _, word := range strings.FieldsFunc(part, func(r rune) bool { return r == '-' || r == '.' || r == '_' }) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package contract
+
+import (
+	"encoding/json"
+)
+
+// schemaID identifies the generated schema
+const schemaID = "https://github.com/joshuakim/linefinder/docs/api-schema.json"
+
+// Schema returns a JSON Schema (draft 2020-12) with a definition under
+// $defs for every type in the contract
+func Schema(entries []Entry) ([]byte, error) {
+	c, err := build(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	defs := make(map[string]interface{}, len(c.defs))
+	for _, d := range c.sorted() {
+		s := schemaFor(d.typ, "#/$defs/")
+		if d.usage != "" {
+			s["description"] = d.usage
+		}
+		defs[d.name] = s
+	}
+
+	out, err := json.MarshalIndent(map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     schemaID,
+		"title":   "LineFinder API",
+		"$defs":   defs,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// schemaFor describes a type as a schema object, referring to definitions
+// under refBase
+func schemaFor(t typeRef, refBase string) map[string]interface{} {
+	var s map[string]interface{}
+	switch {
+	case t.name != "":
+		s = map[string]interface{}{"$ref": refBase + t.name}
+	case t.kind == kindBool:
+		s = map[string]interface{}{"type": "boolean"}
+	case t.kind == kindInteger:
+		s = map[string]interface{}{"type": "integer"}
+	case t.kind == kindNumber:
+		s = map[string]interface{}{"type": "number"}
+	case t.kind == kindString:
+		s = map[string]interface{}{"type": "string"}
+	case t.kind == kindTime:
+		s = map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.kind == kindArray:
+		s = map[string]interface{}{"type": "array", "items": schemaFor(*t.elem, refBase)}
+	case t.kind == kindMap:
+		s = map[string]interface{}{"type": "object", "additionalProperties": schemaFor(*t.elem, refBase)}
+	case t.kind == kindObject:
+		props := make(map[string]interface{}, len(t.fields))
+		required := []string{}
+		for _, f := range t.fields {
+			props[f.name] = schemaFor(f.typ, refBase)
+			if !f.optional {
+				required = append(required, f.name)
+			}
+		}
+		// Structs have a fixed set of fields, so anything else is a rename
+		s = map[string]interface{}{
+			"type":                 "object",
+			"properties":           props,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	default:
+		// Any JSON value
+		s = map[string]interface{}{}
+	}
+
+	if t.nullable {
+		return map[string]interface{}{"anyOf": []interface{}{s, map[string]interface{}{"type": "null"}}}
+	}
+	return s
+}
@@ -0,0 +1,104 @@
+package contract
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypeScript returns a declaration file with an exported type for every
+// type in the contract
+func TypeScript(entries []Entry) ([]byte, error) {
+	c, err := build(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by cmd/gentypes from internal/contract. DO NOT EDIT.\n")
+	b.WriteString("// Regenerate with `make generate-clients`.\n")
+	for _, d := range c.sorted() {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "/** %s", d.goType)
+		if d.usage != "" {
+			fmt.Fprintf(&b, ": %s", d.usage)
+		}
+		b.WriteString(" */\n")
+
+		if d.typ.kind == kindObject {
+			fmt.Fprintf(&b, "export interface %s ", d.name)
+			writeObject(&b, d.typ.fields, "")
+			b.WriteString("\n")
+		} else {
+			fmt.Fprintf(&b, "export type %s = %s;\n", d.name, tsType(d.typ, ""))
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+// tsType writes a type as a TypeScript type expression
+func tsType(t typeRef, indent string) string {
+	var s string
+	switch {
+	case t.name != "":
+		s = t.name
+	case t.kind == kindBool:
+		s = "boolean"
+	case t.kind == kindInteger, t.kind == kindNumber:
+		s = "number"
+	case t.kind == kindString:
+		s = "string"
+	case t.kind == kindTime:
+		// RFC 3339
+		s = "string"
+	case t.kind == kindArray:
+		elem := tsType(*t.elem, indent)
+		if t.elem.nullable {
+			elem = "(" + elem + ")"
+		}
+		s = elem + "[]"
+	case t.kind == kindMap:
+		s = "Record<string, " + tsType(*t.elem, indent) + ">"
+	case t.kind == kindObject:
+		var b strings.Builder
+		writeObject(&b, t.fields, indent)
+		s = b.String()
+	default:
+		s = "unknown"
+	}
+
+	if t.nullable {
+		return s + " | null"
+	}
+	return s
+}
+
+// writeObject writes a struct's fields as an object type
+func writeObject(b *strings.Builder, fields []field, indent string) {
+	b.WriteString("{\n")
+	for _, f := range fields {
+		name := f.name
+		if !isIdentifier(name) {
+			name = fmt.Sprintf("%q", name)
+		}
+		if f.optional {
+			name += "?"
+		}
+		fmt.Fprintf(b, "%s  %s: %s;\n", indent, name, tsType(f.typ, indent+"  "))
+	}
+	b.WriteString(indent + "}")
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
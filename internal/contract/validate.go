@@ -0,0 +1,163 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Validator checks JSON documents against the definitions in a schema
+// written by Schema. It understands the keywords Schema uses: $ref, anyOf,
+// type, format, properties, required, additionalProperties and items.
+type Validator struct {
+	defs map[string]interface{}
+}
+
+// NewValidator reads a schema
+func NewValidator(schema []byte) (*Validator, error) {
+	var doc struct {
+		Defs map[string]interface{} `json:"$defs"`
+	}
+	if err := json.Unmarshal(schema, &doc); err != nil {
+		return nil, fmt.Errorf("contract: reading schema: %w", err)
+	}
+	if len(doc.Defs) == 0 {
+		return nil, fmt.Errorf("contract: schema has no $defs")
+	}
+	return &Validator{defs: doc.Defs}, nil
+}
+
+// Validate checks a document against a definition, returning every place
+// it doesn't match. An error means the document isn't JSON or the
+// definition doesn't exist.
+func (v *Validator) Validate(def string, doc []byte) ([]string, error) {
+	schema, ok := v.defs[def]
+	if !ok {
+		return nil, fmt.Errorf("contract: no definition named %s", def)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("contract: %s: %w", def, err)
+	}
+	return v.check(schema, value, def), nil
+}
+
+// check compares a decoded value to a schema object
+func (v *Validator) check(node interface{}, value interface{}, path string) []string {
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return []string{path + ": schema isn't an object"}
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/$defs/")
+		def, ok := v.defs[name]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown $ref %s", path, ref)}
+		}
+		return v.check(def, value, path)
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		var first []string
+		for _, branch := range anyOf {
+			problems := v.check(branch, value, path)
+			if len(problems) == 0 {
+				return nil
+			}
+			// Report against the non-null branch, which is the useful one
+			if first == nil && !isNullSchema(branch) {
+				first = problems
+			}
+		}
+		return first
+	}
+
+	if want, ok := schema["type"].(string); ok {
+		if got := jsonType(value); got != want && !(want == "number" && got == "integer") {
+			return []string{fmt.Sprintf("%s: expected %s, got %s", path, want, got)}
+		}
+	}
+
+	var problems []string
+	switch value := value.(type) {
+	case string:
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %q isn't an RFC 3339 time", path, value))
+			}
+		}
+
+	case []interface{}:
+		if items, ok := schema["items"]; ok {
+			for i, item := range value {
+				problems = append(problems, v.check(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+
+	case map[string]interface{}:
+		props, _ := schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := value[name.(string)]; !ok {
+					problems = append(problems, fmt.Sprintf("%s: missing field %s", path, name))
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fieldPath := path + "." + k
+			if prop, ok := props[k]; ok {
+				problems = append(problems, v.check(prop, value[k], fieldPath)...)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					problems = append(problems, fieldPath+": unexpected field")
+				}
+			case map[string]interface{}:
+				problems = append(problems, v.check(extra, value[k], fieldPath)...)
+			}
+		}
+	}
+	return problems
+}
+
+// jsonType names a decoded value's JSON Schema type
+func jsonType(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func isNullSchema(node interface{}) bool {
+	schema, ok := node.(map[string]interface{})
+	return ok && schema["type"] == "null"
+}
@@ -0,0 +1,145 @@
+package database
+
+import "time"
+
+// userTables hold data about the user, in the order they're deleted.
+// Everything else (odds, players, projections, reference data) is shared
+// market data.
+var userTables = []string{
+	"webhook_deliveries",
+	"webhooks",
+	"alert_transitions",
+	"alert_feedback",
+	"alert_history",
+	"pending_notifications",
+	"notification_log",
+	"rate_limits",
+	"experiment_results",
+	"experiments",
+	"daily_alert_counts",
+	"deposits",
+	"bets",
+	"pinned_games",
+	"preference_presets",
+	"undo_actions",
+	"preferences",
+}
+
+// userTableKeys are the primary keys of the user tables not keyed by id,
+// which exports are ordered by
+var userTableKeys = map[string]string{
+	"daily_alert_counts": "day",
+	"pinned_games":       "game_id",
+	"preference_presets": "name",
+}
+
+// UserExport is every row stored about the user, by table, with encrypted
+// fields decrypted
+type UserExport struct {
+	ExportedAt time.Time                           `json:"exported_at"`
+	Tables     map[string][]map[string]interface{} `json:"tables"`
+}
+
+// ExportUserData returns every row in the user's tables. Until there are
+// multiple users, that's all of them.
+func (db *DB) ExportUserData() (*UserExport, error) {
+	export := &UserExport{
+		ExportedAt: db.clock.Now().UTC(),
+		Tables:     make(map[string][]map[string]interface{}, len(userTables)),
+	}
+	for _, table := range userTables {
+		rows, err := db.exportTable(table)
+		if err != nil {
+			return nil, err
+		}
+		export.Tables[table] = rows
+	}
+	return export, nil
+}
+
+// exportTable reads every column of every row in a table, in primary key
+// order
+func (db *DB) exportTable(table string) ([]map[string]interface{}, error) {
+	key := "id"
+	if k, ok := userTableKeys[table]; ok {
+		key = k
+	}
+	rows, err := db.conn.Query("SELECT * FROM " + table + " ORDER BY " + key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	encrypted := make(map[string]bool)
+	for _, c := range encryptedColumns {
+		if c.table == table {
+			encrypted[c.column] = true
+		}
+	}
+
+	result := []map[string]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			value := values[i]
+			if b, ok := value.([]byte); ok {
+				value = string(b)
+			}
+			if s, ok := value.(string); ok && encrypted[column] {
+				if value, err = db.decrypt(s); err != nil {
+					return nil, err
+				}
+			}
+			row[column] = value
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// DeleteUserData deletes every row in the user's tables in one transaction,
+// restores default preferences and vacuums the file so nothing deleted is
+// left on disk. It returns the rows deleted per table.
+func (db *DB) DeleteUserData() (map[string]int64, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	deleted := make(map[string]int64, len(userTables))
+	for _, table := range userTables {
+		result, err := tx.Exec("DELETE FROM " + table)
+		if err != nil {
+			return nil, err
+		}
+		if deleted[table], err = result.RowsAffected(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO preferences (id) VALUES (1)`); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// Deleted rows linger in free pages until the file is rebuilt
+	if _, err := db.conn.Exec(`VACUUM`); err != nil {
+		return deleted, err
+	}
+	return deleted, nil
+}
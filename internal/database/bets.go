@@ -0,0 +1,190 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// BetPending is a bet's result until it's graded with an outcome
+const BetPending = "pending"
+
+// Bet is a wager the user logged. Profit is what it won or lost once
+// graded; scores are set when it was graded from the final score. UnitSize
+// is the unit_size preference when it was logged, 0 if none was set; the
+// unit fields are filled in for responses, not stored. AlertID is the
+// alert the bet was placed on, if any.
+type Bet struct {
+	ID           int64      `json:"id"`
+	GameID       string     `json:"game_id"`
+	Sport        string     `json:"sport"`
+	HomeTeam     string     `json:"home_team"`
+	AwayTeam     string     `json:"away_team"`
+	CommenceTime time.Time  `json:"commence_time"`
+	Market       string     `json:"market"`
+	Selection    string     `json:"selection"` // a team, "Over"/"Under", or a prop
+	Point        *float64   `json:"point,omitempty"`
+	Bookmaker    string     `json:"bookmaker"`
+	Price        float64    `json:"price"` // American odds
+	Stake        float64    `json:"stake"`
+	UnitSize     float64    `json:"unit_size"`
+	StakeUnits   float64    `json:"stake_units"`
+	ProfitUnits  float64    `json:"profit_units"`
+	Note         string     `json:"note,omitempty"`
+	Result       string     `json:"result"`
+	Profit       float64    `json:"profit"`
+	HomeScore    *int       `json:"home_score,omitempty"`
+	AwayScore    *int       `json:"away_score,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	GradedAt     *time.Time `json:"graded_at,omitempty"`
+	RemindedAt   *time.Time `json:"reminded_at,omitempty"` // when a hedge or middle reminder went out
+	AlertID      *int64     `json:"alert_id,omitempty"`
+}
+
+const betColumns = `id, game_id, sport, home_team, away_team, commence_time, market,
+	selection, point, bookmaker, price, stake, COALESCE(note, ''), result,
+	COALESCE(profit, 0), home_score, away_score, created_at, graded_at,
+	COALESCE(unit_size, 0), reminded_at, alert_id`
+
+// SaveBet logs a pending bet, setting its ID, result and time
+func (db *DB) SaveBet(b *Bet) error {
+	b.Result = BetPending
+	b.Profit = 0
+	b.CreatedAt = db.clock.Now().UTC()
+	id, err := db.conn.insert(`
+		INSERT INTO bets (game_id, sport, home_team, away_team, commence_time, market,
+			selection, point, bookmaker, price, stake, note, result, created_at, unit_size, alert_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, b.GameID, b.Sport, b.HomeTeam, b.AwayTeam, b.CommenceTime.UTC(), b.Market,
+		b.Selection, b.Point, b.Bookmaker, b.Price, b.Stake, b.Note, b.Result, b.CreatedAt, b.UnitSize, b.AlertID)
+	if err != nil {
+		return err
+	}
+	b.ID = id
+	return nil
+}
+
+// GetBet returns a bet by ID, or nil when it doesn't exist
+func (db *DB) GetBet(id int64) (*Bet, error) {
+	row := db.conn.QueryRow(`SELECT `+betColumns+` FROM bets WHERE id = ?`, id)
+	b, err := scanBet(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return b, err
+}
+
+// GetBets returns bets, newest first: pending ones, graded ones, or all of
+// them for an empty result
+func (db *DB) GetBets(result string) ([]Bet, error) {
+	query := `SELECT ` + betColumns + ` FROM bets`
+	var args []interface{}
+	switch result {
+	case "":
+	case BetPending:
+		query += ` WHERE result = ?`
+		args = append(args, BetPending)
+	default:
+		query += ` WHERE result != ?`
+		args = append(args, BetPending)
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+	return db.queryBets(query, args...)
+}
+
+// GetPendingBets returns ungraded bets on games that started before a
+// time, earliest game first
+func (db *DB) GetPendingBets(startedBefore time.Time) ([]Bet, error) {
+	return db.queryBets(`
+		SELECT `+betColumns+`
+		FROM bets
+		WHERE result = ? AND commence_time < ?
+		ORDER BY commence_time, id
+	`, BetPending, startedBefore.UTC())
+}
+
+// GetBetsToRemind returns pending bets on games starting after now and by
+// until that haven't had a reminder, earliest game first
+func (db *DB) GetBetsToRemind(now, until time.Time) ([]Bet, error) {
+	return db.queryBets(`
+		SELECT `+betColumns+`
+		FROM bets
+		WHERE result = ? AND reminded_at IS NULL AND commence_time > ? AND commence_time <= ?
+		ORDER BY commence_time, id
+	`, BetPending, now.UTC(), until.UTC())
+}
+
+// MarkBetReminded records that a bet's reminder went out
+func (db *DB) MarkBetReminded(id int64) error {
+	_, err := db.conn.Exec(`UPDATE bets SET reminded_at = ? WHERE id = ?`, db.clock.Now().UTC(), id)
+	return err
+}
+
+// GradeBet records a bet's result, profit and, when graded from the final
+// score, the score. Returns false when the bet doesn't exist.
+func (db *DB) GradeBet(b *Bet) (bool, error) {
+	now := db.clock.Now().UTC()
+	result, err := db.conn.Exec(`
+		UPDATE bets SET result = ?, profit = ?, home_score = ?, away_score = ?, graded_at = ?
+		WHERE id = ?
+	`, b.Result, b.Profit, b.HomeScore, b.AwayScore, now, b.ID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if n > 0 {
+		b.GradedAt = &now
+	}
+	return n > 0, err
+}
+
+func (db *DB) queryBets(query string, args ...interface{}) ([]Bet, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bets := []Bet{}
+	for rows.Next() {
+		b, err := scanBet(rows)
+		if err != nil {
+			return nil, err
+		}
+		bets = append(bets, *b)
+	}
+	return bets, rows.Err()
+}
+
+// scanBet reads a bet selected with betColumns
+func scanBet(row interface{ Scan(...interface{}) error }) (*Bet, error) {
+	var b Bet
+	var point sql.NullFloat64
+	var homeScore, awayScore, alertID sql.NullInt64
+	var gradedAt, remindedAt sql.NullTime
+	if err := row.Scan(&b.ID, &b.GameID, &b.Sport, &b.HomeTeam, &b.AwayTeam, &b.CommenceTime, &b.Market,
+		&b.Selection, &point, &b.Bookmaker, &b.Price, &b.Stake, &b.Note, &b.Result,
+		&b.Profit, &homeScore, &awayScore, &b.CreatedAt, &gradedAt, &b.UnitSize, &remindedAt, &alertID); err != nil {
+		return nil, err
+	}
+	if point.Valid {
+		b.Point = &point.Float64
+	}
+	if homeScore.Valid {
+		score := int(homeScore.Int64)
+		b.HomeScore = &score
+	}
+	if awayScore.Valid {
+		score := int(awayScore.Int64)
+		b.AwayScore = &score
+	}
+	if gradedAt.Valid {
+		b.GradedAt = &gradedAt.Time
+	}
+	if remindedAt.Valid {
+		b.RemindedAt = &remindedAt.Time
+	}
+	if alertID.Valid {
+		b.AlertID = &alertID.Int64
+	}
+	return &b, nil
+}
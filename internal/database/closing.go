@@ -0,0 +1,103 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ClosingPrediction is a projected closing line sent with an alert. Lines
+// are spread or total points, or American prices for moneylines; Drift is
+// in the unit, points or cents.
+type ClosingPrediction struct {
+	ID           int64      `json:"id"`
+	GameID       string     `json:"game_id"`
+	Sport        string     `json:"sport"`
+	Market       string     `json:"market"`
+	Outcome      string     `json:"outcome"`
+	Unit         string     `json:"unit"`
+	Line         float64    `json:"line"` // consensus when projected
+	Drift        float64    `json:"drift"`
+	Carry        float64    `json:"carry"`
+	Projected    float64    `json:"projected"`
+	CommenceTime time.Time  `json:"commence_time"`
+	PredictedAt  time.Time  `json:"predicted_at"`
+	Close        *float64   `json:"close,omitempty"` // consensus at game start, once resolved
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
+
+// SaveClosingPrediction records a projection, stamped with the current time
+func (db *DB) SaveClosingPrediction(p *ClosingPrediction) (int64, error) {
+	p.PredictedAt = db.clock.Now().UTC()
+	return db.conn.insert(`
+		INSERT INTO closing_predictions (game_id, sport, market, outcome, unit,
+			line_value, drift, carry, projected, commence_time, predicted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, p.GameID, p.Sport, p.Market, p.Outcome, p.Unit,
+		p.Line, p.Drift, p.Carry, p.Projected, p.CommenceTime.UTC(), p.PredictedAt)
+}
+
+// GetPendingClosingPredictions returns unresolved projections for games
+// that started before the given time, oldest game first
+func (db *DB) GetPendingClosingPredictions(before time.Time) ([]ClosingPrediction, error) {
+	return db.queryClosingPredictions(`
+		WHERE resolved_at IS NULL AND commence_time <= ?
+		ORDER BY commence_time, id
+	`, before.UTC())
+}
+
+// GetResolvedClosingPredictions returns projections made since the given
+// time whose closing line was found, oldest first. An empty sport returns
+// every sport.
+func (db *DB) GetResolvedClosingPredictions(since time.Time, sport string) ([]ClosingPrediction, error) {
+	return db.queryClosingPredictions(`
+		WHERE close_value IS NOT NULL AND predicted_at >= ? AND (? = '' OR sport = ?)
+		ORDER BY predicted_at, id
+	`, since.UTC(), sport, sport)
+}
+
+// ResolveClosingPrediction records a projection's closing line. A nil close
+// marks it resolved without one, when no odds were recorded near the start.
+func (db *DB) ResolveClosingPrediction(id int64, close *float64) error {
+	var value interface{}
+	if close != nil {
+		value = *close
+	}
+	_, err := db.conn.Exec(`
+		UPDATE closing_predictions SET close_value = ?, resolved_at = ?
+		WHERE id = ?
+	`, value, db.clock.Now().UTC(), id)
+	return err
+}
+
+// queryClosingPredictions selects projections with the given filter
+func (db *DB) queryClosingPredictions(where string, args ...interface{}) ([]ClosingPrediction, error) {
+	rows, err := db.readQuery(`
+		SELECT id, game_id, sport, market, outcome, unit, line_value, drift,
+			carry, projected, commence_time, predicted_at, close_value, resolved_at
+		FROM closing_predictions
+	`+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var predictions []ClosingPrediction
+	for rows.Next() {
+		var p ClosingPrediction
+		var close sql.NullFloat64
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(&p.ID, &p.GameID, &p.Sport, &p.Market, &p.Outcome, &p.Unit,
+			&p.Line, &p.Drift, &p.Carry, &p.Projected, &p.CommenceTime, &p.PredictedAt,
+			&close, &resolvedAt); err != nil {
+			return nil, err
+		}
+		if close.Valid {
+			p.Close = &close.Float64
+		}
+		if resolvedAt.Valid {
+			p.ResolvedAt = &resolvedAt.Time
+		}
+		predictions = append(predictions, p)
+	}
+	return predictions, rows.Err()
+}
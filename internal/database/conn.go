@@ -0,0 +1,125 @@
+package database
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Backends the database can run on
+const (
+	backendSQLite   = "sqlite"
+	backendPostgres = "postgres"
+)
+
+// conn is the database connection. Queries are written for SQLite, with ?
+// placeholders; on Postgres they're rewritten to $1, $2, ... before running.
+type conn struct {
+	*sql.DB
+	backend string
+}
+
+// Exec runs a statement
+func (c *conn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.DB.Exec(c.rebind(query), args...)
+}
+
+// Query runs a query returning rows
+func (c *conn) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.DB.Query(c.rebind(query), args...)
+}
+
+// QueryRow runs a query returning at most one row
+func (c *conn) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.DB.QueryRow(c.rebind(query), args...)
+}
+
+// Begin starts a transaction
+func (c *conn) Begin() (*tx, error) {
+	t, err := c.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &tx{Tx: t, conn: c}, nil
+}
+
+// insert runs an INSERT and returns the new row's ID. Postgres drivers
+// don't report the last insert ID, so the ID is returned by the statement.
+func (c *conn) insert(query string, args ...interface{}) (int64, error) {
+	if c.backend == backendPostgres {
+		var id int64
+		err := c.QueryRow(query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+	result, err := c.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// tx is a transaction on a conn
+type tx struct {
+	*sql.Tx
+	conn *conn
+}
+
+// Exec runs a statement in the transaction
+func (t *tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return t.Tx.Exec(t.conn.rebind(query), args...)
+}
+
+// QueryRow runs a query returning at most one row in the transaction
+func (t *tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return t.Tx.QueryRow(t.conn.rebind(query), args...)
+}
+
+// rebind numbers a query's placeholders for Postgres, leaving question
+// marks inside string literals alone
+func (c *conn) rebind(query string) string {
+	if c.backend != backendPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+	n, quoted := 0, false
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'':
+			quoted = !quoted
+		case ch == '?' && !quoted:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// postgresTypes maps the SQLite column types used in the schema to their
+// Postgres equivalents, in order
+var postgresTypes = []struct {
+	pattern *regexp.Regexp
+	replace string
+}{
+	{regexp.MustCompile(`\bINTEGER PRIMARY KEY AUTOINCREMENT\b`), "BIGSERIAL PRIMARY KEY"},
+	{regexp.MustCompile(`\bINTEGER\b`), "BIGINT"},
+	{regexp.MustCompile(`\bREAL\b`), "DOUBLE PRECISION"},
+	{regexp.MustCompile(`\bTIMESTAMP\b`), "TIMESTAMPTZ"},
+}
+
+// ddl adapts a schema statement or column definition to the backend
+func (c *conn) ddl(statement string) string {
+	if c.backend != backendPostgres {
+		return statement
+	}
+	for _, t := range postgresTypes {
+		statement = t.pattern.ReplaceAllString(statement, t.replace)
+	}
+	return statement
+}
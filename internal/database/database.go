@@ -1,37 +1,106 @@
 package database
 
 import (
+	"crypto/cipher"
+	"crypto/rand"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/joshuakim/linefinder/internal/clock"
 )
 
-// DB wraps the SQLite database connection
+// DB wraps the database connection, SQLite by default or Postgres when
+// opened with NewPostgres
 type DB struct {
-	conn *sql.DB
+	conn  *conn
+	clock clock.Clock
+	rand  io.Reader
+
+	// aead encrypts sensitive columns when an encryption key is set
+	aead cipher.AEAD
+
+	// maintenance serializes maintenance runs and keeps the last report
+	maintenance maintenanceState
+
+	// replica takes read-heavy queries on Postgres, when configured
+	replica replicaState
 }
 
 // New creates a new database connection and initializes schema
 func New(dbPath string) (*DB, error) {
-	conn, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL")
+	return NewSQLite(dbPath, DefaultSQLiteDriver())
+}
+
+// NewSQLite opens a SQLite file with one of the SQLite drivers and
+// initializes schema
+func NewSQLite(dbPath, driver string) (*DB, error) {
+	d, ok := sqliteDrivers[driver]
+	if !ok {
+		return nil, fmt.Errorf("unknown SQLite driver %q: use %s or %s", driver, SQLiteDriverCgo, SQLiteDriverPureGo)
+	}
+	if driver == SQLiteDriverCgo && !cgoSQLite {
+		return nil, fmt.Errorf("built without cgo: use the %s SQLite driver", SQLiteDriverPureGo)
+	}
+	db, err := open(backendSQLite, d.name, dbPath+d.params)
 	if err != nil {
 		return nil, err
 	}
 
-	db := &DB{conn: conn}
-	if err := db.initSchema(); err != nil {
-		conn.Close()
+	log.Printf("Database initialized at %s (%s)", dbPath, d.pkg)
+	return db, nil
+}
+
+// NewPostgres connects to a Postgres database by URL and initializes
+// schema, so several instances can share preferences, alert history, rate
+// limits and pending notifications. The driver is only compiled in with
+// the postgres build tag.
+func NewPostgres(url string) (*DB, error) {
+	if !postgresDriver {
+		return nil, fmt.Errorf("built without Postgres support: rebuild with -tags postgres")
+	}
+	db, err := open(backendPostgres, "pgx", url)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Database initialized on Postgres")
+	return db, nil
+}
+
+// open connects with a database/sql driver and initializes schema
+func open(backend, driver, dsn string) (*DB, error) {
+	sqlDB, err := sql.Open(driver, dsn)
+	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("Database initialized at %s", dbPath)
+	db := &DB{conn: &conn{DB: sqlDB, backend: backend}, clock: clock.Real{}, rand: rand.Reader}
+	if err := db.initSchema(); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
 	return db, nil
 }
 
+// SetClock sets the clock used for rate limit windows and cleanup cutoffs
+func (db *DB) SetClock(c clock.Clock) {
+	db.clock = c
+}
+
+// SetRandSource sets the source of random bytes for generated tokens
+func (db *DB) SetRandSource(r io.Reader) {
+	db.rand = r
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
+	if err := db.closeReplica(); err != nil {
+		log.Printf("Database: closing read replica: %v", err)
+	}
 	return db.conn.Close()
 }
 
@@ -67,11 +136,21 @@ func (db *DB) initSchema() error {
 		-- Batching
 		batch_interval_seconds INTEGER DEFAULT 60,
 
+		-- Email summary
+		email TEXT DEFAULT '',
+		email_summary_enabled BOOLEAN DEFAULT false,
+		email_summary_time TEXT DEFAULT '08:00',
+		email_summary_last_sent TEXT DEFAULT '',
+		email_unsubscribe_token TEXT DEFAULT '',
+
+		-- Threshold tuning
+		auto_tune_thresholds BOOLEAN DEFAULT false,
+
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
 	-- Insert default preferences if not exists
-	INSERT OR IGNORE INTO preferences (id) VALUES (1);
+	INSERT INTO preferences (id) VALUES (1) ON CONFLICT DO NOTHING;
 
 	-- Alert history for deduplication
 	CREATE TABLE IF NOT EXISTS alert_history (
@@ -117,6 +196,268 @@ func (db *DB) initSchema() error {
 		batch_id TEXT
 	);
 
+	-- Delivery log per channel: one row per delivery, retrying until it's
+	-- sent or dead-lettered
+	CREATE TABLE IF NOT EXISTS notification_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		channel TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL,
+		error TEXT DEFAULT '',
+		payload TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	-- Outbound webhooks receiving alert batches
+	CREATE TABLE IF NOT EXISTS webhooks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		enabled BOOLEAN DEFAULT true,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	-- One row per webhook POST, updated as it's retried
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		webhook_id INTEGER NOT NULL,
+		event TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER DEFAULT 0,
+		response_status INTEGER DEFAULT 0,
+		error TEXT DEFAULT '',
+		payload TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+
+	-- User feedback on alerts (one record per alert)
+	CREATE TABLE IF NOT EXISTS alert_feedback (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		alert_id INTEGER NOT NULL UNIQUE,
+
+		-- Snapshot of the alert so feedback survives history cleanup
+		player_name TEXT NOT NULL,
+		prop_category TEXT NOT NULL,
+		direction TEXT NOT NULL,
+		confidence TEXT NOT NULL,
+
+		rating TEXT NOT NULL,
+		outcome TEXT DEFAULT '',
+		note TEXT DEFAULT '',
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- A/B threshold experiments
+	CREATE TABLE IF NOT EXISTS experiments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		profile_a TEXT NOT NULL,
+		profile_b TEXT NOT NULL,
+		active_profile TEXT NOT NULL DEFAULT 'a',
+		started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		ended_at TIMESTAMP
+	);
+
+	-- Alerts each experiment profile would have fired
+	CREATE TABLE IF NOT EXISTS experiment_results (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		experiment_id INTEGER NOT NULL,
+		profile TEXT NOT NULL,
+		game_id TEXT NOT NULL,
+		player_name TEXT NOT NULL,
+		prop_category TEXT NOT NULL,
+		direction TEXT NOT NULL,
+		line_value REAL NOT NULL,
+		average_value REAL NOT NULL,
+		difference REAL NOT NULL,
+		confidence TEXT NOT NULL,
+		times_seen INTEGER DEFAULT 1,
+		first_seen TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_seen TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(experiment_id, profile, game_id, player_name, prop_category, direction)
+	);
+
+	-- Reference data fetched by bootstrap
+	CREATE TABLE IF NOT EXISTS upstream_sports (
+		sport_key TEXT PRIMARY KEY,
+		group_name TEXT NOT NULL,
+		title TEXT NOT NULL,
+		description TEXT DEFAULT '',
+		active BOOLEAN DEFAULT true,
+		fetched_at TIMESTAMP NOT NULL
+	);
+
+	-- Sports enabled locally, managed at runtime by admins
+	CREATE TABLE IF NOT EXISTS sport_settings (
+		sport_key TEXT PRIMARY KEY,
+		enabled BOOLEAN NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS game_snapshots (
+		game_id TEXT PRIMARY KEY,
+		sport TEXT NOT NULL,
+		commence_time TIMESTAMP NOT NULL,
+		data TEXT NOT NULL,
+		fetched_at TIMESTAMP NOT NULL
+	);
+
+	-- Alert lifecycle state changes
+	CREATE TABLE IF NOT EXISTS alert_transitions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		alert_id INTEGER NOT NULL,
+		from_state TEXT NOT NULL,
+		to_state TEXT NOT NULL,
+		reason TEXT DEFAULT '',
+		line_value REAL,
+		confidence TEXT DEFAULT '',
+		created_at TIMESTAMP NOT NULL
+	);
+
+	-- Per-bookmaker odds, one row per outcome each time a book's market changes
+	CREATE TABLE IF NOT EXISTS odds_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		game_id TEXT NOT NULL,
+		sport TEXT NOT NULL,
+		bookmaker TEXT NOT NULL,
+		market TEXT NOT NULL,
+		outcome TEXT NOT NULL,
+		price REAL NOT NULL,
+		point REAL,
+		recorded_at TIMESTAMP NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS prop_snapshots (
+		game_id TEXT PRIMARY KEY,
+		sport TEXT NOT NULL,
+		data TEXT NOT NULL,
+		fetched_at TIMESTAMP NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS players (
+		sport TEXT NOT NULL,
+		player_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		team TEXT DEFAULT '',
+		position TEXT DEFAULT '',
+		injury_status TEXT DEFAULT '',
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (sport, player_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS player_averages (
+		sport TEXT NOT NULL,
+		player_name TEXT NOT NULL,
+		category TEXT NOT NULL,
+		average REAL NOT NULL,
+		games_played INTEGER NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (sport, player_name, category)
+	);
+
+	-- Projections uploaded by external models, one per player, category and source
+	CREATE TABLE IF NOT EXISTS projections (
+		player TEXT NOT NULL,
+		category TEXT NOT NULL,
+		source TEXT NOT NULL,
+		projection REAL NOT NULL,
+		stddev REAL DEFAULT 0,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (player, category, source)
+	);
+
+	-- Betting alerts delivered per local day, for the daily alert cap
+	CREATE TABLE IF NOT EXISTS daily_alert_counts (
+		day TEXT PRIMARY KEY,
+		count INTEGER DEFAULT 0
+	);
+
+	-- Deposits the user logs, checked against the weekly deposit limit
+	CREATE TABLE IF NOT EXISTS deposits (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		amount REAL NOT NULL,
+		bookmaker TEXT DEFAULT '',
+		created_at TIMESTAMP NOT NULL
+	);
+
+	-- Wagers the user logs, graded from final scores for the bankroll
+	CREATE TABLE IF NOT EXISTS bets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		game_id TEXT NOT NULL,
+		sport TEXT NOT NULL,
+		home_team TEXT NOT NULL,
+		away_team TEXT NOT NULL,
+		commence_time TIMESTAMP NOT NULL,
+		market TEXT NOT NULL,
+		selection TEXT NOT NULL,
+		point REAL,
+		bookmaker TEXT NOT NULL,
+		price REAL NOT NULL,
+		stake REAL NOT NULL,
+		note TEXT DEFAULT '',
+		result TEXT NOT NULL DEFAULT 'pending',
+		profit REAL DEFAULT 0,
+		home_score INTEGER,
+		away_score INTEGER,
+		created_at TIMESTAMP NOT NULL,
+		graded_at TIMESTAMP
+	);
+
+	-- Closing line projections sent with market alerts, resolved against
+	-- the consensus line at game start to track their accuracy
+	CREATE TABLE IF NOT EXISTS closing_predictions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		game_id TEXT NOT NULL,
+		sport TEXT NOT NULL,
+		market TEXT NOT NULL,
+		outcome TEXT NOT NULL,
+		unit TEXT NOT NULL,
+		line_value REAL NOT NULL,
+		drift REAL NOT NULL,
+		carry REAL NOT NULL,
+		projected REAL NOT NULL,
+		commence_time TIMESTAMP NOT NULL,
+		predicted_at TIMESTAMP NOT NULL,
+		close_value REAL,
+		resolved_at TIMESTAMP
+	);
+
+	-- Games kept for post-game analysis, exempt from history cleanup
+	CREATE TABLE IF NOT EXISTS pinned_games (
+		game_id TEXT PRIMARY KEY,
+		sport TEXT NOT NULL,
+		pinned_at TIMESTAMP NOT NULL
+	);
+
+	-- Alert settings saved under a name to switch back to
+	CREATE TABLE IF NOT EXISTS preference_presets (
+		name TEXT PRIMARY KEY,
+		settings TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	-- What a destructive change replaced, restorable until it expires
+	CREATE TABLE IF NOT EXISTS undo_actions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		token TEXT NOT NULL UNIQUE,
+		kind TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP NOT NULL
+	);
+
+	-- Each instance's metrics counters, taken periodically
+	CREATE TABLE IF NOT EXISTS metrics_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		instance TEXT NOT NULL,
+		counters TEXT NOT NULL,
+		taken_at TIMESTAMP NOT NULL
+	);
+
 	-- Create indexes
 	CREATE INDEX IF NOT EXISTS idx_alert_history_lookup
 		ON alert_history(player_name, prop_category, direction, game_id);
@@ -124,10 +465,164 @@ func (db *DB) initSchema() error {
 		ON alert_history(cooldown_until);
 	CREATE INDEX IF NOT EXISTS idx_pending_batch
 		ON pending_notifications(batch_id);
+	CREATE INDEX IF NOT EXISTS idx_alert_transitions_alert
+		ON alert_transitions(alert_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_odds_history_series
+		ON odds_history(game_id, market, bookmaker, recorded_at);
+	CREATE INDEX IF NOT EXISTS idx_odds_history_recorded
+		ON odds_history(recorded_at);
+	CREATE INDEX IF NOT EXISTS idx_notification_log_status
+		ON notification_log(status, created_at);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook
+		ON webhook_deliveries(webhook_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_bets_result
+		ON bets(result, commence_time);
+	CREATE INDEX IF NOT EXISTS idx_closing_predictions_pending
+		ON closing_predictions(resolved_at, commence_time);
+	CREATE INDEX IF NOT EXISTS idx_metrics_snapshots_instance
+		ON metrics_snapshots(instance, taken_at);
 	`
 
-	_, err := db.conn.Exec(schema)
-	return err
+	if _, err := db.conn.Exec(db.conn.ddl(schema)); err != nil {
+		return err
+	}
+
+	return db.migrate()
+}
+
+// columnMigration adds a column to a table created by an older schema
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+}
+
+// columnMigrations lists columns added after the initial schema. CREATE TABLE
+// IF NOT EXISTS won't touch existing databases, so each is added on startup
+// when missing.
+var columnMigrations = []columnMigration{
+	{"preferences", "email", "TEXT DEFAULT ''"},
+	{"preferences", "email_summary_enabled", "BOOLEAN DEFAULT false"},
+	{"preferences", "email_summary_time", "TEXT DEFAULT '08:00'"},
+	{"preferences", "email_summary_last_sent", "TEXT DEFAULT ''"},
+	{"preferences", "email_unsubscribe_token", "TEXT DEFAULT ''"},
+	{"preferences", "auto_tune_thresholds", "BOOLEAN DEFAULT false"},
+	{"preferences", "watchlist", "TEXT DEFAULT ''"},
+	{"preferences", "rate_limit_news", "INTEGER DEFAULT 10"},
+	{"preferences", "my_book", "TEXT DEFAULT ''"},
+	{"alert_history", "alert_json", "TEXT DEFAULT ''"},
+	{"alert_history", "read_at", "TIMESTAMP"},
+	{"preferences", "projection_mode", "TEXT DEFAULT 'blend'"},
+	{"preferences", "projection_weight", "REAL DEFAULT 0.5"},
+	{"preferences", "projection_sources", "TEXT DEFAULT ''"},
+	{"projections", "kind", "TEXT DEFAULT 'projection'"},
+	{"projections", "expires_at", "TIMESTAMP"},
+	{"preferences", "projection_weights", "TEXT DEFAULT ''"},
+	{"preferences", "projection_disagreement_pct", "REAL DEFAULT 15"},
+	{"preferences", "excluded_bookmakers", "TEXT DEFAULT ''"},
+	{"preferences", "scan_window_hours", "INTEGER DEFAULT 0"},
+	{"alert_history", "recheck_status", "TEXT DEFAULT ''"},
+	{"alert_history", "rechecked_at", "TIMESTAMP"},
+	{"alert_history", "state", "TEXT DEFAULT 'active'"},
+	{"alert_history", "state_changed_at", "TIMESTAMP"},
+	{"preferences", "vig_method", "TEXT DEFAULT 'multiplicative'"},
+	{"preferences", "ev_threshold_pct", "REAL DEFAULT 2.0"},
+	{"preferences", "period_ev_threshold_pct", "REAL DEFAULT 3.0"},
+	{"preferences", "enable_discord", "BOOLEAN DEFAULT false"},
+	{"preferences", "discord_webhook_url", "TEXT DEFAULT ''"},
+	{"preferences", "discord_bot_token", "TEXT DEFAULT ''"},
+	{"preferences", "discord_channel_id", "TEXT DEFAULT ''"},
+	{"preferences", "rate_limit_discord", "INTEGER DEFAULT 10"},
+	{"preferences", "daily_alert_cap", "INTEGER DEFAULT 0"},
+	{"preferences", "max_bet_amount", "REAL DEFAULT 0"},
+	{"preferences", "daily_bet_limit", "REAL DEFAULT 0"},
+	{"preferences", "weekly_deposit_limit", "REAL DEFAULT 0"},
+	{"preferences", "show_helpline", "BOOLEAN DEFAULT false"},
+	{"preferences", "cool_off_until", "TIMESTAMP"},
+	{"alert_feedback", "stake", "REAL DEFAULT 0"},
+	{"preferences", "min_confidence", "TEXT DEFAULT 'low'"},
+	{"preferences", "detection_modes", "TEXT DEFAULT ''"},
+	{"preferences", "detection_percent_pct", "REAL DEFAULT 15"},
+	{"preferences", "detection_zscore", "REAL DEFAULT 1"},
+	{"preferences", "min_odds", "REAL DEFAULT 0"},
+	{"preferences", "max_hold_percent", "REAL DEFAULT 0"},
+	{"webhooks", "deleted_at", "TIMESTAMP"},
+	{"preferences", "outlier_points", "REAL DEFAULT 1"},
+	{"preferences", "outlier_cents", "REAL DEFAULT 20"},
+	{"preferences", "alert_subscriptions", "TEXT DEFAULT ''"},
+	{"preferences", "enable_email", "BOOLEAN DEFAULT false"},
+	{"preferences", "rate_limit_email", "INTEGER DEFAULT 4"},
+	{"preferences", "enable_telegram", "BOOLEAN DEFAULT true"},
+	{"preferences", "rate_limit_telegram", "INTEGER DEFAULT 10"},
+	{"preferences", "muted_players", "TEXT DEFAULT ''"},
+	{"preferences", "digest_enabled", "BOOLEAN DEFAULT false"},
+	{"preferences", "digest_time", "TEXT DEFAULT '08:00'"},
+	{"preferences", "digest_channels", "TEXT DEFAULT 'push,webhook,email'"},
+	{"preferences", "digest_last_sent", "TEXT DEFAULT ''"},
+	{"preferences", "unit_size", "REAL DEFAULT 0"},
+	{"preferences", "currency", "TEXT DEFAULT 'USD'"},
+	{"bets", "unit_size", "REAL DEFAULT 0"},
+	{"bets", "reminded_at", "TIMESTAMP"},
+	{"bets", "alert_id", "INTEGER"},
+	{"notification_log", "next_attempt_at", "TIMESTAMP"},
+	{"notification_log", "updated_at", "TIMESTAMP"},
+	{"player_averages", "stddev", "REAL DEFAULT 0"},
+}
+
+// migrate applies column migrations to existing databases
+func (db *DB) migrate() error {
+	for _, m := range columnMigrations {
+		exists, err := db.columnExists(m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.conn.Exec("ALTER TABLE " + m.table + " ADD COLUMN " + m.column + " " + db.conn.ddl(m.definition)); err != nil {
+			return err
+		}
+		log.Printf("Database: added column %s.%s", m.table, m.column)
+	}
+	return nil
+}
+
+// columnExists checks whether a table already has a column
+func (db *DB) columnExists(table, column string) (bool, error) {
+	if db.conn.backend == backendPostgres {
+		var exists bool
+		err := db.conn.QueryRow(`
+			SELECT EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?
+			)
+		`, table, column).Scan(&exists)
+		return exists, err
+	}
+
+	rows, err := db.conn.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
 }
 
 // Preferences represents user notification preferences
@@ -152,11 +647,125 @@ type Preferences struct {
 	Timezone   string `json:"timezone"`
 
 	// Rate limits
-	RateLimitPush int `json:"rate_limit_push"`
+	RateLimitPush     int `json:"rate_limit_push"`
+	RateLimitNews     int `json:"rate_limit_news"`
+	RateLimitDiscord  int `json:"rate_limit_discord"`
+	RateLimitTelegram int `json:"rate_limit_telegram"`
+
+	// Discord delivery, through a channel webhook URL or a bot token and
+	// channel ID
+	EnableDiscord     bool   `json:"enable_discord"`
+	DiscordWebhookURL string `json:"discord_webhook_url"`
+	DiscordBotToken   string `json:"discord_bot_token"`
+	DiscordChannelID  string `json:"discord_channel_id"`
+
+	// Telegram delivery to the chat configured with the bot token
+	EnableTelegram bool `json:"enable_telegram"`
+
+	// Per-category subscriptions, mapping an alert category to channels
+	// and whether it's delivered over them (see models.AlertCategories and
+	// models.AlertChannels). Categories and channels left out are on.
+	AlertSubscriptions map[string]map[string]bool `json:"alert_subscriptions"`
+
+	// Players to watch in news feeds
+	Watchlist []string `json:"watchlist"`
+
+	// Players whose alerts aren't sent. A name mutes every player whose
+	// name contains it, ignoring case, so "LeBron" mutes LeBron James.
+	MutedPlayers []string `json:"muted_players"`
+
+	// Primary sportsbook key (e.g. "draftkings"), compared against the best price
+	MyBook string `json:"my_book"`
+
+	// Bookmaker keys whose lines alerts never use. They still appear in
+	// comparisons.
+	ExcludedBookmakers []string `json:"excluded_bookmakers"`
+
+	// Only games starting within this many hours are scanned for value
+	// alerts; 0 scans every game
+	ScanWindowHours int `json:"scan_window_hours"`
+
+	// How vig is removed for fair odds ("multiplicative" or "power") and the
+	// expected value, in percent, a price needs over fair odds for an EV alert
+	VigMethod      string  `json:"vig_method"`
+	EVThresholdPct float64 `json:"ev_threshold_pct"`
+
+	// The EV threshold, in percent, for half and quarter prices
+	PeriodEVThresholdPct float64 `json:"period_ev_threshold_pct"`
+
+	// How far a book's line, in points, or its price, in cents, can be from
+	// the median across books before it's flagged as an outlier
+	OutlierPoints float64 `json:"outlier_points"`
+	OutlierCents  float64 `json:"outlier_cents"`
+
+	// External projections: "off", "override" or "blend" with internal
+	// averages at ProjectionWeight. Sources listed earlier take precedence.
+	ProjectionMode    string   `json:"projection_mode"`
+	ProjectionWeight  float64  `json:"projection_weight"`
+	ProjectionSources []string `json:"projection_sources"`
+
+	// Per-source blend weights ("average" is the internal last-5 average),
+	// overriding ProjectionWeight, and how far apart sources can be, in
+	// percent, before alerts are flagged as disputed
+	ProjectionWeights         map[string]float64 `json:"projection_weights"`
+	ProjectionDisagreementPct float64            `json:"projection_disagreement_pct"`
+
+	// Alerts below this confidence ("low", "medium" or "high") aren't sent
+	MinConfidence string `json:"min_confidence"`
+
+	// How each prop category's distance from the average is measured:
+	// "absolute" against the thresholds above, "percent" of the average
+	// against DetectionPercentPct, or "zscore", standard deviations of the
+	// recent game log, against DetectionZScore. Keyed by canonical category,
+	// with "default" for the rest; unset categories are absolute.
+	DetectionModes      map[string]string `json:"detection_modes"`
+	DetectionPercentPct float64           `json:"detection_percent_pct"`
+	DetectionZScore     float64           `json:"detection_zscore"`
+
+	// Value alerts priced worse than MinOdds (American, e.g. -150), or at a
+	// book whose two-way hold on the prop is over MaxHoldPercent, are
+	// skipped; 0 turns a filter off
+	MinOdds        float64 `json:"min_odds"`
+	MaxHoldPercent float64 `json:"max_hold_percent"`
 
 	// Batching
 	BatchIntervalSeconds int `json:"batch_interval_seconds"`
 
+	// Email: value alerts as each batch goes out, under their own hourly
+	// limit, and the daily summary
+	Email               string `json:"email"`
+	EnableEmail         bool   `json:"enable_email"`
+	RateLimitEmail      int    `json:"rate_limit_email"`
+	EmailSummaryEnabled bool   `json:"email_summary_enabled"`
+	EmailSummaryTime    string `json:"email_summary_time"`
+
+	// Morning digest: the day's games, best lines, open alerts and
+	// injuries in one message per channel at DigestTime
+	DigestEnabled  bool     `json:"digest_enabled"`
+	DigestTime     string   `json:"digest_time"`
+	DigestChannels []string `json:"digest_channels"`
+
+	// Threshold tuning suggestions from alert feedback
+	AutoTuneThresholds bool `json:"auto_tune_thresholds"`
+
+	// Responsible gambling guardrails; 0 turns a limit off. Betting alerts
+	// stop for the day after DailyAlertCap, bets and deposits over their
+	// limits raise warnings, and ShowHelpline adds helpline info to digests.
+	DailyAlertCap      int     `json:"daily_alert_cap"`
+	MaxBetAmount       float64 `json:"max_bet_amount"`
+	DailyBetLimit      float64 `json:"daily_bet_limit"`
+	WeeklyDepositLimit float64 `json:"weekly_deposit_limit"`
+	ShowHelpline       bool    `json:"show_helpline"`
+
+	// CoolOffUntil mutes betting alerts until then. It's set with
+	// SetCoolOff, never by UpdatePreferences, so it can't be lifted early.
+	CoolOffUntil *time.Time `json:"cool_off_until,omitempty"`
+
+	// Bet tracking: the amount of one unit, 0 for the average stake, and
+	// the ISO 4217 currency stakes and profits are in
+	UnitSize float64 `json:"unit_size"`
+	Currency string  `json:"currency"`
+
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
@@ -168,20 +777,57 @@ func (db *DB) GetPreferences() (*Preferences, error) {
 			threshold_points, threshold_rebounds, threshold_assists,
 			threshold_threes, threshold_default,
 			sports, quiet_start, quiet_end, timezone,
-			rate_limit_push, batch_interval_seconds, updated_at
+			rate_limit_push, batch_interval_seconds,
+			email, email_summary_enabled, email_summary_time,
+			auto_tune_thresholds,
+			rate_limit_news, watchlist, my_book,
+			projection_mode, projection_weight, projection_sources,
+			projection_weights, projection_disagreement_pct,
+			excluded_bookmakers, scan_window_hours,
+			vig_method, ev_threshold_pct, period_ev_threshold_pct,
+			enable_discord, discord_webhook_url, discord_bot_token,
+			discord_channel_id, rate_limit_discord,
+			daily_alert_cap, max_bet_amount, daily_bet_limit,
+			weekly_deposit_limit, show_helpline, cool_off_until,
+			min_confidence, detection_modes, detection_percent_pct,
+			detection_zscore, min_odds, max_hold_percent,
+			outlier_points, outlier_cents, alert_subscriptions,
+			enable_email, rate_limit_email, enable_telegram,
+			rate_limit_telegram, muted_players, digest_enabled,
+			digest_time, digest_channels, unit_size,
+			currency, updated_at
 		FROM preferences WHERE id = 1
 	`)
 
 	var p Preferences
-	var sportsStr string
+	var sportsStr, watchlistStr, mutedStr, digestChannelsStr, sourcesStr, weightsStr, excludedStr, modesStr, subscriptionsStr string
 	var pushSub sql.NullString
+	var coolOffUntil sql.NullTime
 
 	err := row.Scan(
 		&p.EnableWebsocket, &p.EnablePush, &pushSub,
 		&p.ThresholdPoints, &p.ThresholdRebounds, &p.ThresholdAssists,
 		&p.ThresholdThrees, &p.ThresholdDefault,
 		&sportsStr, &p.QuietStart, &p.QuietEnd, &p.Timezone,
-		&p.RateLimitPush, &p.BatchIntervalSeconds, &p.UpdatedAt,
+		&p.RateLimitPush, &p.BatchIntervalSeconds,
+		&p.Email, &p.EmailSummaryEnabled, &p.EmailSummaryTime,
+		&p.AutoTuneThresholds,
+		&p.RateLimitNews, &watchlistStr, &p.MyBook,
+		&p.ProjectionMode, &p.ProjectionWeight, &sourcesStr,
+		&weightsStr, &p.ProjectionDisagreementPct,
+		&excludedStr, &p.ScanWindowHours,
+		&p.VigMethod, &p.EVThresholdPct, &p.PeriodEVThresholdPct,
+		&p.EnableDiscord, &p.DiscordWebhookURL, &p.DiscordBotToken,
+		&p.DiscordChannelID, &p.RateLimitDiscord,
+		&p.DailyAlertCap, &p.MaxBetAmount, &p.DailyBetLimit,
+		&p.WeeklyDepositLimit, &p.ShowHelpline, &coolOffUntil,
+		&p.MinConfidence, &modesStr, &p.DetectionPercentPct,
+		&p.DetectionZScore, &p.MinOdds, &p.MaxHoldPercent,
+		&p.OutlierPoints, &p.OutlierCents, &subscriptionsStr,
+		&p.EnableEmail, &p.RateLimitEmail, &p.EnableTelegram,
+		&p.RateLimitTelegram, &mutedStr, &p.DigestEnabled,
+		&p.DigestTime, &digestChannelsStr, &p.UnitSize,
+		&p.Currency, &p.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -190,6 +836,14 @@ func (db *DB) GetPreferences() (*Preferences, error) {
 	if pushSub.Valid {
 		p.PushSubscription = pushSub.String
 	}
+	if coolOffUntil.Valid {
+		p.CoolOffUntil = &coolOffUntil.Time
+	}
+	for _, field := range []*string{&p.PushSubscription, &p.Email, &p.DiscordWebhookURL, &p.DiscordBotToken} {
+		if *field, err = db.decrypt(*field); err != nil {
+			return nil, err
+		}
+	}
 
 	// Parse sports
 	if sportsStr != "" {
@@ -200,12 +854,96 @@ func (db *DB) GetPreferences() (*Preferences, error) {
 		}
 	}
 
+	// Parse watchlist
+	if watchlistStr != "" {
+		for _, name := range splitAndTrim(watchlistStr, ",") {
+			if name != "" {
+				p.Watchlist = append(p.Watchlist, name)
+			}
+		}
+	}
+
+	// Parse muted players
+	if mutedStr != "" {
+		for _, name := range splitAndTrim(mutedStr, ",") {
+			if name != "" {
+				p.MutedPlayers = append(p.MutedPlayers, name)
+			}
+		}
+	}
+
+	// Parse digest channels
+	if digestChannelsStr != "" {
+		p.DigestChannels = splitAndTrim(digestChannelsStr, ",")
+	}
+
+	// Parse projection sources
+	if sourcesStr != "" {
+		p.ProjectionSources = splitAndTrim(sourcesStr, ",")
+	}
+	if weightsStr != "" {
+		if err := json.Unmarshal([]byte(weightsStr), &p.ProjectionWeights); err != nil {
+			log.Printf("Database: ignoring invalid projection weights: %v", err)
+		}
+	}
+	if excludedStr != "" {
+		p.ExcludedBookmakers = splitAndTrim(excludedStr, ",")
+	}
+	if modesStr != "" {
+		if err := json.Unmarshal([]byte(modesStr), &p.DetectionModes); err != nil {
+			log.Printf("Database: ignoring invalid detection modes: %v", err)
+		}
+	}
+	if subscriptionsStr != "" {
+		if err := json.Unmarshal([]byte(subscriptionsStr), &p.AlertSubscriptions); err != nil {
+			log.Printf("Database: ignoring invalid alert subscriptions: %v", err)
+		}
+	}
+
 	return &p, nil
 }
 
 // UpdatePreferences updates user preferences
 func (db *DB) UpdatePreferences(p *Preferences) error {
 	sportsStr := joinStrings(p.Sports, ",")
+	watchlistStr := joinStrings(p.Watchlist, ",")
+	mutedStr := joinStrings(p.MutedPlayers, ",")
+	digestChannelsStr := joinStrings(p.DigestChannels, ",")
+	sourcesStr := joinStrings(p.ProjectionSources, ",")
+	excludedStr := joinStrings(p.ExcludedBookmakers, ",")
+	weightsStr := ""
+	if len(p.ProjectionWeights) > 0 {
+		weights, err := json.Marshal(p.ProjectionWeights)
+		if err != nil {
+			return err
+		}
+		weightsStr = string(weights)
+	}
+	modesStr := ""
+	if len(p.DetectionModes) > 0 {
+		modes, err := json.Marshal(p.DetectionModes)
+		if err != nil {
+			return err
+		}
+		modesStr = string(modes)
+	}
+	subscriptionsStr := ""
+	if len(p.AlertSubscriptions) > 0 {
+		subscriptions, err := json.Marshal(p.AlertSubscriptions)
+		if err != nil {
+			return err
+		}
+		subscriptionsStr = string(subscriptions)
+	}
+
+	// Sensitive fields are encrypted at rest when a key is set
+	pushSub, email, discordURL, discordToken := p.PushSubscription, p.Email, p.DiscordWebhookURL, p.DiscordBotToken
+	for _, field := range []*string{&pushSub, &email, &discordURL, &discordToken} {
+		var err error
+		if *field, err = db.encrypt(*field); err != nil {
+			return err
+		}
+	}
 
 	_, err := db.conn.Exec(`
 		UPDATE preferences SET
@@ -223,21 +961,89 @@ func (db *DB) UpdatePreferences(p *Preferences) error {
 			timezone = ?,
 			rate_limit_push = ?,
 			batch_interval_seconds = ?,
+			email = ?,
+			email_summary_enabled = ?,
+			email_summary_time = ?,
+			auto_tune_thresholds = ?,
+			rate_limit_news = ?,
+			watchlist = ?,
+			my_book = ?,
+			projection_mode = ?,
+			projection_weight = ?,
+			projection_sources = ?,
+			projection_weights = ?,
+			projection_disagreement_pct = ?,
+			excluded_bookmakers = ?,
+			scan_window_hours = ?,
+			vig_method = ?,
+			ev_threshold_pct = ?,
+			period_ev_threshold_pct = ?,
+			enable_discord = ?,
+			discord_webhook_url = ?,
+			discord_bot_token = ?,
+			discord_channel_id = ?,
+			rate_limit_discord = ?,
+			daily_alert_cap = ?,
+			max_bet_amount = ?,
+			daily_bet_limit = ?,
+			weekly_deposit_limit = ?,
+			show_helpline = ?,
+			min_confidence = ?,
+			detection_modes = ?,
+			detection_percent_pct = ?,
+			detection_zscore = ?,
+			min_odds = ?,
+			max_hold_percent = ?,
+			outlier_points = ?,
+			outlier_cents = ?,
+			alert_subscriptions = ?,
+			enable_email = ?,
+			rate_limit_email = ?,
+			enable_telegram = ?,
+			rate_limit_telegram = ?,
+			muted_players = ?,
+			digest_enabled = ?,
+			digest_time = ?,
+			digest_channels = ?,
+			unit_size = ?,
+			currency = ?,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = 1
 	`,
-		p.EnableWebsocket, p.EnablePush, p.PushSubscription,
+		p.EnableWebsocket, p.EnablePush, pushSub,
 		p.ThresholdPoints, p.ThresholdRebounds, p.ThresholdAssists,
 		p.ThresholdThrees, p.ThresholdDefault,
 		sportsStr, p.QuietStart, p.QuietEnd, p.Timezone,
 		p.RateLimitPush, p.BatchIntervalSeconds,
+		email, p.EmailSummaryEnabled, p.EmailSummaryTime,
+		p.AutoTuneThresholds,
+		p.RateLimitNews, watchlistStr, p.MyBook,
+		p.ProjectionMode, p.ProjectionWeight, sourcesStr,
+		weightsStr, p.ProjectionDisagreementPct,
+		excludedStr, p.ScanWindowHours,
+		p.VigMethod, p.EVThresholdPct, p.PeriodEVThresholdPct,
+		p.EnableDiscord, discordURL, discordToken,
+		p.DiscordChannelID, p.RateLimitDiscord,
+		p.DailyAlertCap, p.MaxBetAmount, p.DailyBetLimit,
+		p.WeeklyDepositLimit, p.ShowHelpline,
+		p.MinConfidence, modesStr, p.DetectionPercentPct,
+		p.DetectionZScore, p.MinOdds, p.MaxHoldPercent,
+		p.OutlierPoints, p.OutlierCents, subscriptionsStr,
+		p.EnableEmail, p.RateLimitEmail, p.EnableTelegram,
+		p.RateLimitTelegram, mutedStr, p.DigestEnabled,
+		p.DigestTime, digestChannelsStr, p.UnitSize,
+		p.Currency,
 	)
 	return err
 }
 
 // SetPushSubscription updates the push subscription
 func (db *DB) SetPushSubscription(subscription string) error {
-	_, err := db.conn.Exec(`
+	subscription, err := db.encrypt(subscription)
+	if err != nil {
+		return err
+	}
+	_, err = db.conn.Exec(`
 		UPDATE preferences SET
 			push_subscription = ?,
 			enable_push = true,
@@ -273,6 +1079,8 @@ type AlertHistory struct {
 	Confidence    string    `json:"confidence"`
 	CreatedAt     time.Time `json:"created_at"`
 	CooldownUntil time.Time `json:"cooldown_until"`
+	State         string    `json:"state"` // lifecycle state, see AlertStateActive
+	AlertJSON     string    `json:"-"` // full alert as detected, for the alert detail API
 }
 
 // GetAlertHistory retrieves alert history for deduplication check
@@ -280,7 +1088,7 @@ func (db *DB) GetAlertHistory(playerName, propCategory, direction, gameID string
 	row := db.conn.QueryRow(`
 		SELECT id, player_name, prop_category, direction, game_id,
 			   line_value, average_value, difference, confidence,
-			   created_at, cooldown_until
+			   created_at, cooldown_until, COALESCE(state, 'active')
 		FROM alert_history
 		WHERE player_name = ? AND prop_category = ? AND direction = ? AND game_id = ?
 	`, playerName, propCategory, direction, gameID)
@@ -289,7 +1097,7 @@ func (db *DB) GetAlertHistory(playerName, propCategory, direction, gameID string
 	err := row.Scan(
 		&h.ID, &h.PlayerName, &h.PropCategory, &h.Direction, &h.GameID,
 		&h.LineValue, &h.AverageValue, &h.Difference, &h.Confidence,
-		&h.CreatedAt, &h.CooldownUntil,
+		&h.CreatedAt, &h.CooldownUntil, &h.State,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -300,13 +1108,14 @@ func (db *DB) GetAlertHistory(playerName, propCategory, direction, gameID string
 	return &h, nil
 }
 
-// SaveAlertHistory saves or updates alert history
+// SaveAlertHistory saves or updates alert history and sets h.ID and h.State.
+// Saving an alert again makes it active, unless a bet was logged on it.
 func (db *DB) SaveAlertHistory(h *AlertHistory) error {
-	_, err := db.conn.Exec(`
+	return db.conn.QueryRow(`
 		INSERT INTO alert_history
 			(player_name, prop_category, direction, game_id,
-			 line_value, average_value, difference, confidence, cooldown_until)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			 line_value, average_value, difference, confidence, cooldown_until, created_at, alert_json)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(player_name, prop_category, direction, game_id)
 		DO UPDATE SET
 			line_value = excluded.line_value,
@@ -314,24 +1123,133 @@ func (db *DB) SaveAlertHistory(h *AlertHistory) error {
 			difference = excluded.difference,
 			confidence = excluded.confidence,
 			cooldown_until = excluded.cooldown_until,
-			created_at = CURRENT_TIMESTAMP
+			created_at = excluded.created_at,
+			alert_json = excluded.alert_json,
+			read_at = NULL,
+			recheck_status = '',
+			rechecked_at = NULL,
+			state = CASE WHEN alert_history.state = 'converted' THEN alert_history.state ELSE 'active' END,
+			state_changed_at = CASE WHEN alert_history.state = 'converted' THEN alert_history.state_changed_at ELSE excluded.created_at END
+		RETURNING id, COALESCE(state, 'active')
 	`, h.PlayerName, h.PropCategory, h.Direction, h.GameID,
-		h.LineValue, h.AverageValue, h.Difference, h.Confidence, h.CooldownUntil)
-	return err
+		h.LineValue, h.AverageValue, h.Difference, h.Confidence, h.CooldownUntil,
+		db.clock.Now().UTC(), h.AlertJSON).Scan(&h.ID, &h.State)
+}
+
+// GetAlertByID retrieves a single alert history record, including the
+// full alert JSON
+func (db *DB) GetAlertByID(id int64) (*AlertHistory, error) {
+	row := db.conn.QueryRow(`
+		SELECT id, player_name, prop_category, direction, game_id,
+			   line_value, average_value, difference, confidence,
+			   created_at, cooldown_until, COALESCE(state, 'active'), COALESCE(alert_json, '')
+		FROM alert_history
+		WHERE id = ?
+	`, id)
+
+	var h AlertHistory
+	err := row.Scan(
+		&h.ID, &h.PlayerName, &h.PropCategory, &h.Direction, &h.GameID,
+		&h.LineValue, &h.AverageValue, &h.Difference, &h.Confidence,
+		&h.CreatedAt, &h.CooldownUntil, &h.State, &h.AlertJSON,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
 }
 
-// CleanupExpiredHistory removes old alert history
+// GetAlertsByIDs retrieves alert history records by ID, newest first.
+// IDs that don't exist are skipped.
+func (db *DB) GetAlertsByIDs(ids []int64) ([]AlertHistory, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, player_name, prop_category, direction, game_id,
+			   line_value, average_value, difference, confidence,
+			   created_at, cooldown_until, COALESCE(state, 'active')
+		FROM alert_history
+		WHERE id IN (`
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		if i > 0 {
+			query += ","
+		}
+		query += "?"
+		args[i] = id
+	}
+	query += ") ORDER BY created_at DESC"
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []AlertHistory
+	for rows.Next() {
+		var h AlertHistory
+		if err := rows.Scan(
+			&h.ID, &h.PlayerName, &h.PropCategory, &h.Direction, &h.GameID,
+			&h.LineValue, &h.AverageValue, &h.Difference, &h.Confidence,
+			&h.CreatedAt, &h.CooldownUntil, &h.State,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// GetRecentAlerts returns alerts recorded since the given time, newest first
+func (db *DB) GetRecentAlerts(since time.Time, limit int) ([]AlertHistory, error) {
+	rows, err := db.readQuery(`
+		SELECT id, player_name, prop_category, direction, game_id,
+			   line_value, average_value, difference, confidence,
+			   created_at, cooldown_until, COALESCE(state, 'active')
+		FROM alert_history
+		WHERE created_at >= ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, since.UTC().Format("2006-01-02 15:04:05"), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []AlertHistory
+	for rows.Next() {
+		var h AlertHistory
+		if err := rows.Scan(
+			&h.ID, &h.PlayerName, &h.PropCategory, &h.Direction, &h.GameID,
+			&h.LineValue, &h.AverageValue, &h.Difference, &h.Confidence,
+			&h.CreatedAt, &h.CooldownUntil, &h.State,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// CleanupExpiredHistory removes old alert history, except for pinned games
 func (db *DB) CleanupExpiredHistory() error {
 	_, err := db.conn.Exec(`
 		DELETE FROM alert_history
-		WHERE cooldown_until < datetime('now', '-24 hours')
-	`)
+		WHERE cooldown_until < ?
+			AND game_id NOT IN (SELECT game_id FROM pinned_games)
+	`, db.clock.Now().Add(-24*time.Hour))
 	return err
 }
 
 // CheckRateLimit checks if we can send on a channel
 func (db *DB) CheckRateLimit(channel string, limit int) (bool, int, error) {
-	windowStart := time.Now().Truncate(time.Hour)
+	windowStart := db.clock.Now().Truncate(time.Hour)
 
 	// Get or create rate limit record
 	row := db.conn.QueryRow(`
@@ -353,13 +1271,13 @@ func (db *DB) CheckRateLimit(channel string, limit int) (bool, int, error) {
 
 // IncrementRateLimit increments the rate limit counter
 func (db *DB) IncrementRateLimit(channel string) error {
-	windowStart := time.Now().Truncate(time.Hour)
+	windowStart := db.clock.Now().Truncate(time.Hour)
 
 	_, err := db.conn.Exec(`
 		INSERT INTO rate_limits (channel, window_start, count)
 		VALUES (?, ?, 1)
 		ON CONFLICT(channel, window_start)
-		DO UPDATE SET count = count + 1
+		DO UPDATE SET count = rate_limits.count + 1
 	`, channel, windowStart)
 	return err
 }
@@ -368,8 +1286,8 @@ func (db *DB) IncrementRateLimit(channel string) error {
 func (db *DB) CleanupOldRateLimits() error {
 	_, err := db.conn.Exec(`
 		DELETE FROM rate_limits
-		WHERE window_start < datetime('now', '-2 hours')
-	`)
+		WHERE window_start < ?
+	`, db.clock.Now().Add(-2*time.Hour))
 	return err
 }
 
@@ -384,9 +1302,9 @@ type PendingNotification struct {
 // AddPendingNotification adds a notification to the batch queue
 func (db *DB) AddPendingNotification(alertJSON string) error {
 	_, err := db.conn.Exec(`
-		INSERT INTO pending_notifications (alert_json)
-		VALUES (?)
-	`, alertJSON)
+		INSERT INTO pending_notifications (alert_json, created_at)
+		VALUES (?, ?)
+	`, alertJSON, db.clock.Now().UTC())
 	return err
 }
 
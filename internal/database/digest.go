@@ -0,0 +1,50 @@
+package database
+
+import "strings"
+
+// DigestChannel reports whether the morning digest goes to a channel
+func (p *Preferences) DigestChannel(channel string) bool {
+	for _, c := range p.DigestChannels {
+		if strings.EqualFold(c, channel) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDigestLastSent returns the local date (YYYY-MM-DD) of the last digest sent
+func (db *DB) GetDigestLastSent() (string, error) {
+	var day string
+	err := db.conn.QueryRow(`
+		SELECT COALESCE(digest_last_sent, '') FROM preferences WHERE id = 1
+	`).Scan(&day)
+	return day, err
+}
+
+// SetDigestLastSent records the local date the digest was sent
+func (db *DB) SetDigestLastSent(day string) error {
+	_, err := db.conn.Exec(`
+		UPDATE preferences SET digest_last_sent = ? WHERE id = 1
+	`, day)
+	return err
+}
+
+// dropDigestChannel takes a channel out of the digest, leaving the others
+func (db *DB) dropDigestChannel(channel string) error {
+	var channelsStr string
+	if err := db.conn.QueryRow(`
+		SELECT COALESCE(digest_channels, '') FROM preferences WHERE id = 1
+	`).Scan(&channelsStr); err != nil {
+		return err
+	}
+	var kept []string
+	for _, c := range splitAndTrim(channelsStr, ",") {
+		if !strings.EqualFold(c, channel) {
+			kept = append(kept, c)
+		}
+	}
+	_, err := db.conn.Exec(`
+		UPDATE preferences SET digest_channels = ? WHERE id = 1
+	`, joinStrings(kept, ","))
+	return err
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"encoding/hex"
+	"io"
+)
+
+// EnsureUnsubscribeToken returns the email unsubscribe token, creating one if needed
+func (db *DB) EnsureUnsubscribeToken() (string, error) {
+	var token string
+	err := db.conn.QueryRow(`
+		SELECT COALESCE(email_unsubscribe_token, '') FROM preferences WHERE id = 1
+	`).Scan(&token)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		return token, nil
+	}
+
+	buf := make([]byte, 16)
+	if _, err := io.ReadFull(db.rand, buf); err != nil {
+		return "", err
+	}
+	token = hex.EncodeToString(buf)
+
+	_, err = db.conn.Exec(`
+		UPDATE preferences SET email_unsubscribe_token = ? WHERE id = 1
+	`, token)
+	return token, err
+}
+
+// ValidUnsubscribeToken reports whether token is the email unsubscribe token
+func (db *DB) ValidUnsubscribeToken(token string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	var n int
+	err := db.conn.QueryRow(`
+		SELECT COUNT(*) FROM preferences WHERE id = 1 AND email_unsubscribe_token = ?
+	`, token).Scan(&n)
+	return n > 0, err
+}
+
+// UnsubscribeEmail disables alert email, the email summary and the digest
+// email if the token matches. Returns false when the token is unknown.
+func (db *DB) UnsubscribeEmail(token string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	result, err := db.conn.Exec(`
+		UPDATE preferences SET
+			email_summary_enabled = false,
+			enable_email = false,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = 1 AND email_unsubscribe_token = ?
+	`, token)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	return true, db.dropDigestChannel("email")
+}
+
+// GetEmailSummaryLastSent returns the local date (YYYY-MM-DD) of the last summary sent
+func (db *DB) GetEmailSummaryLastSent() (string, error) {
+	var day string
+	err := db.conn.QueryRow(`
+		SELECT COALESCE(email_summary_last_sent, '') FROM preferences WHERE id = 1
+	`).Scan(&day)
+	return day, err
+}
+
+// SetEmailSummaryLastSent records the local date the summary was sent
+func (db *DB) SetEmailSummaryLastSent(day string) error {
+	_, err := db.conn.Exec(`
+		UPDATE preferences SET email_summary_last_sent = ? WHERE id = 1
+	`, day)
+	return err
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testEmail = "someone@example.com"
+
+// setEmail saves an email address in the preferences
+func setEmail(t *testing.T, db *DB, email string) {
+	t.Helper()
+	prefs, err := db.GetPreferences()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefs.Email = email
+	if err := db.UpdatePreferences(prefs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// storedEmail reads the email column as stored, without decrypting it
+func storedEmail(t *testing.T, db *DB) string {
+	t.Helper()
+	var email string
+	if err := db.conn.QueryRow(`SELECT COALESCE(email, '') FROM preferences WHERE id = 1`).Scan(&email); err != nil {
+		t.Fatal(err)
+	}
+	return email
+}
+
+// readEmail checks the email reads back decrypted
+func readEmail(t *testing.T, db *DB) {
+	t.Helper()
+	prefs, err := db.GetPreferences()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prefs.Email != testEmail {
+		t.Errorf("email read back as %q, want %q", prefs.Email, testEmail)
+	}
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "encryption.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.SetEncryptionKey("correct horse battery staple"); err != nil {
+		t.Fatal(err)
+	}
+	setEmail(t, db, testEmail)
+
+	stored := storedEmail(t, db)
+	if !strings.HasPrefix(stored, encryptedPrefix) || strings.Contains(stored, testEmail) {
+		t.Errorf("email stored as %q, want it encrypted", stored)
+	}
+	readEmail(t, db)
+
+	// Each write gets its own nonce
+	setEmail(t, db, testEmail)
+	if again := storedEmail(t, db); again == stored {
+		t.Error("the same value was sealed to the same ciphertext twice")
+	}
+}
+
+func TestEncryptionMigratesPlaintext(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "encryption.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	setEmail(t, db, testEmail)
+	if stored := storedEmail(t, db); stored != testEmail {
+		t.Fatalf("email stored as %q without a key, want plaintext", stored)
+	}
+
+	if err := db.SetEncryptionKey("correct horse battery staple"); err != nil {
+		t.Fatal(err)
+	}
+	if stored := storedEmail(t, db); !strings.HasPrefix(stored, encryptedPrefix) {
+		t.Errorf("email stored as %q after setting a key, want it encrypted in place", stored)
+	}
+	readEmail(t, db)
+
+	// Setting the key again finds nothing left to encrypt
+	sealed := storedEmail(t, db)
+	if err := db.SetEncryptionKey("correct horse battery staple"); err != nil {
+		t.Fatal(err)
+	}
+	if stored := storedEmail(t, db); stored != sealed {
+		t.Error("an encrypted field was encrypted again")
+	}
+}
+
+func TestEncryptionKeyMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "encryption.db")
+	db, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetEncryptionKey("correct horse battery staple"); err != nil {
+		t.Fatal(err)
+	}
+	setEmail(t, db, testEmail)
+	db.Close()
+
+	db, err = New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.SetEncryptionKey("wrong key"); err == nil {
+		t.Error("a different key was accepted")
+	}
+	if err := db.SetEncryptionKey(""); !errors.Is(err, ErrNoEncryptionKey) {
+		t.Errorf("no key: got %v, want ErrNoEncryptionKey", err)
+	}
+	if _, err := db.GetPreferences(); err == nil {
+		t.Error("encrypted preferences read without a key")
+	}
+
+	if err := db.SetEncryptionKey("correct horse battery staple"); err != nil {
+		t.Fatal(err)
+	}
+	readEmail(t, db)
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Experiment is a stored A/B threshold experiment
+type Experiment struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	ProfileA  string    `json:"profile_a"` // JSON-encoded thresholds
+	ProfileB  string    `json:"profile_b"`
+	Active    string    `json:"active"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// ExperimentResult records an alert a profile would have fired
+type ExperimentResult struct {
+	ExperimentID int64     `json:"experiment_id"`
+	Profile      string    `json:"profile"`
+	GameID       string    `json:"game_id"`
+	PlayerName   string    `json:"player_name"`
+	PropCategory string    `json:"prop_category"`
+	Direction    string    `json:"direction"`
+	Line         float64   `json:"line"`
+	Average      float64   `json:"average"`
+	Difference   float64   `json:"difference"`
+	Confidence   string    `json:"confidence"`
+	TimesSeen    int       `json:"times_seen"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+// StartExperiment ends any running experiment and inserts a new one
+func (db *DB) StartExperiment(e *Experiment) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE experiments SET ended_at = CURRENT_TIMESTAMP WHERE ended_at IS NULL
+	`); err != nil {
+		return err
+	}
+
+	if err := tx.QueryRow(`
+		INSERT INTO experiments (name, profile_a, profile_b, active_profile)
+		VALUES (?, ?, ?, ?)
+		RETURNING id, started_at
+	`, e.Name, e.ProfileA, e.ProfileB, e.Active).Scan(&e.ID, &e.StartedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// EndExperiment marks an experiment as finished
+func (db *DB) EndExperiment(id int64) error {
+	_, err := db.conn.Exec(`
+		UPDATE experiments SET ended_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND ended_at IS NULL
+	`, id)
+	return err
+}
+
+// GetRunningExperiment returns the experiment without an end time, if any
+func (db *DB) GetRunningExperiment() (*Experiment, error) {
+	var e Experiment
+	err := db.conn.QueryRow(`
+		SELECT id, name, profile_a, profile_b, active_profile, started_at
+		FROM experiments
+		WHERE ended_at IS NULL
+		ORDER BY started_at DESC
+		LIMIT 1
+	`).Scan(&e.ID, &e.Name, &e.ProfileA, &e.ProfileB, &e.Active, &e.StartedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// RecordExperimentResult upserts a would-have-fired alert for a profile
+func (db *DB) RecordExperimentResult(r *ExperimentResult) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO experiment_results
+			(experiment_id, profile, game_id, player_name, prop_category, direction,
+			 line_value, average_value, difference, confidence)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(experiment_id, profile, game_id, player_name, prop_category, direction)
+		DO UPDATE SET
+			line_value = excluded.line_value,
+			average_value = excluded.average_value,
+			difference = excluded.difference,
+			confidence = excluded.confidence,
+			times_seen = experiment_results.times_seen + 1,
+			last_seen = CURRENT_TIMESTAMP
+	`, r.ExperimentID, r.Profile, r.GameID, r.PlayerName, r.PropCategory, r.Direction,
+		r.Line, r.Average, r.Difference, r.Confidence)
+	return err
+}
+
+// GetExperimentResults returns everything recorded for an experiment
+func (db *DB) GetExperimentResults(experimentID int64) ([]ExperimentResult, error) {
+	rows, err := db.readQuery(`
+		SELECT experiment_id, profile, game_id, player_name, prop_category, direction,
+			   line_value, average_value, difference, confidence,
+			   times_seen, first_seen, last_seen
+		FROM experiment_results
+		WHERE experiment_id = ?
+		ORDER BY first_seen ASC
+	`, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []ExperimentResult
+	for rows.Next() {
+		var r ExperimentResult
+		if err := rows.Scan(
+			&r.ExperimentID, &r.Profile, &r.GameID, &r.PlayerName, &r.PropCategory, &r.Direction,
+			&r.Line, &r.Average, &r.Difference, &r.Confidence,
+			&r.TimesSeen, &r.FirstSeen, &r.LastSeen,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,81 @@
+package database
+
+import "time"
+
+// Feedback ratings
+const (
+	FeedbackUseful    = "useful"
+	FeedbackNotUseful = "not_useful"
+	FeedbackBetIt     = "bet_it"
+)
+
+// Bet outcomes reported with feedback
+const (
+	OutcomeWin  = "win"
+	OutcomeLoss = "loss"
+	OutcomePush = "push"
+)
+
+// AlertFeedback is a user's rating of a single alert
+type AlertFeedback struct {
+	ID           int64     `json:"id"`
+	AlertID      int64     `json:"alert_id"`
+	PlayerName   string    `json:"player_name"`
+	PropCategory string    `json:"prop_category"`
+	Direction    string    `json:"direction"`
+	Confidence   string    `json:"confidence"`
+	Rating       string    `json:"rating"`
+	Outcome      string    `json:"outcome,omitempty"`
+	Stake        float64   `json:"stake,omitempty"` // amount wagered, for bet_it
+	Note         string    `json:"note,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SaveAlertFeedback records feedback for an alert, replacing any earlier rating
+func (db *DB) SaveAlertFeedback(f *AlertFeedback) error {
+	return db.conn.QueryRow(`
+		INSERT INTO alert_feedback
+			(alert_id, player_name, prop_category, direction, confidence,
+			 rating, outcome, note, stake)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(alert_id)
+		DO UPDATE SET
+			rating = excluded.rating,
+			outcome = excluded.outcome,
+			note = excluded.note,
+			stake = excluded.stake,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING id
+	`, f.AlertID, f.PlayerName, f.PropCategory, f.Direction, f.Confidence,
+		f.Rating, f.Outcome, f.Note, f.Stake).Scan(&f.ID)
+}
+
+// GetAllFeedback returns all stored alert feedback
+func (db *DB) GetAllFeedback() ([]AlertFeedback, error) {
+	rows, err := db.readQuery(`
+		SELECT id, alert_id, player_name, prop_category, direction, confidence,
+			   rating, COALESCE(outcome, ''), COALESCE(note, ''), COALESCE(stake, 0),
+			   created_at, updated_at
+		FROM alert_feedback
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var feedback []AlertFeedback
+	for rows.Next() {
+		var f AlertFeedback
+		if err := rows.Scan(
+			&f.ID, &f.AlertID, &f.PlayerName, &f.PropCategory, &f.Direction, &f.Confidence,
+			&f.Rating, &f.Outcome, &f.Note, &f.Stake,
+			&f.CreatedAt, &f.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		feedback = append(feedback, f)
+	}
+	return feedback, rows.Err()
+}
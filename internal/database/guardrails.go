@@ -0,0 +1,126 @@
+package database
+
+import "time"
+
+// Deposit is money the user logged moving into a sportsbook
+type Deposit struct {
+	ID        int64     `json:"id"`
+	Amount    float64   `json:"amount"`
+	Bookmaker string    `json:"bookmaker,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Location returns the preferences' timezone, falling back to local time
+func (p *Preferences) Location() *time.Location {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// SetCoolOff mutes betting alerts until the given time
+func (db *DB) SetCoolOff(until time.Time) error {
+	_, err := db.conn.Exec(`
+		UPDATE preferences SET cool_off_until = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 1
+	`, until.UTC())
+	return err
+}
+
+// ReserveDailyAlerts counts up to want alerts against a day's cap,
+// returning how many fit
+func (db *DB) ReserveDailyAlerts(day string, want, limit int) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRow(`
+		SELECT COALESCE((SELECT count FROM daily_alert_counts WHERE day = ?), 0)
+	`, day).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	granted := limit - count
+	if granted > want {
+		granted = want
+	}
+	if granted <= 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO daily_alert_counts (day, count) VALUES (?, ?)
+		ON CONFLICT(day) DO UPDATE SET count = daily_alert_counts.count + excluded.count
+	`, day, granted); err != nil {
+		return 0, err
+	}
+	return granted, tx.Commit()
+}
+
+// GetDailyAlertCount returns how many betting alerts went out on a day
+func (db *DB) GetDailyAlertCount(day string) (int, error) {
+	var count int
+	err := db.conn.QueryRow(`
+		SELECT COALESCE((SELECT count FROM daily_alert_counts WHERE day = ?), 0)
+	`, day).Scan(&count)
+	return count, err
+}
+
+// RecordDeposit saves a deposit, setting its ID and time
+func (db *DB) RecordDeposit(d *Deposit) error {
+	d.CreatedAt = db.clock.Now().UTC()
+	id, err := db.conn.insert(`
+		INSERT INTO deposits (amount, bookmaker, created_at) VALUES (?, ?, ?)
+	`, d.Amount, d.Bookmaker, d.CreatedAt)
+	if err != nil {
+		return err
+	}
+	d.ID = id
+	return nil
+}
+
+// GetDeposits returns deposits made since a time, newest first
+func (db *DB) GetDeposits(since time.Time) ([]Deposit, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, amount, COALESCE(bookmaker, ''), created_at
+		FROM deposits
+		WHERE created_at >= ?
+		ORDER BY created_at DESC, id DESC
+	`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deposits []Deposit
+	for rows.Next() {
+		var d Deposit
+		if err := rows.Scan(&d.ID, &d.Amount, &d.Bookmaker, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, d)
+	}
+	return deposits, rows.Err()
+}
+
+// SumStakes returns the total staked on bets logged since a time, with
+// alert feedback or in the bet log
+func (db *DB) SumStakes(since time.Time) (float64, error) {
+	var feedback, bets float64
+	// created_at is CURRENT_TIMESTAMP's UTC "YYYY-MM-DD HH:MM:SS"
+	err := db.conn.QueryRow(`
+		SELECT COALESCE(SUM(stake), 0)
+		FROM alert_feedback
+		WHERE rating = ? AND created_at >= ?
+	`, FeedbackBetIt, since.UTC().Format("2006-01-02 15:04:05")).Scan(&feedback)
+	if err != nil {
+		return 0, err
+	}
+	err = db.conn.QueryRow(`
+		SELECT COALESCE(SUM(stake), 0) FROM bets WHERE created_at >= ?
+	`, since.UTC()).Scan(&bets)
+	return feedback + bets, err
+}
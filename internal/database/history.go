@@ -0,0 +1,90 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// OddsPoint is one outcome's price at one bookmaker at a point in time
+type OddsPoint struct {
+	GameID     string    `json:"game_id"`
+	Sport      string    `json:"sport"`
+	Bookmaker  string    `json:"bookmaker"`
+	Market     string    `json:"market"`
+	Outcome    string    `json:"outcome"`
+	Price      float64   `json:"price"`
+	Point      *float64  `json:"point,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// SaveOddsHistory appends odds points in one transaction, stamped with the
+// current time
+func (db *DB) SaveOddsHistory(points []OddsPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := db.clock.Now().UTC()
+	for _, p := range points {
+		var point interface{}
+		if p.Point != nil {
+			point = *p.Point
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO odds_history (game_id, sport, bookmaker, market, outcome, price, point, recorded_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, p.GameID, p.Sport, p.Bookmaker, p.Market, p.Outcome, p.Price, point, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetOddsHistory returns a game's recorded odds for one market, oldest first.
+// An empty book returns every bookmaker.
+func (db *DB) GetOddsHistory(gameID, market, book string) ([]OddsPoint, error) {
+	rows, err := db.readQuery(`
+		SELECT game_id, sport, bookmaker, market, outcome, price, point, recorded_at
+		FROM odds_history
+		WHERE game_id = ? AND market = ? AND (? = '' OR bookmaker = ?)
+		ORDER BY recorded_at, id
+	`, gameID, market, book, book)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []OddsPoint
+	for rows.Next() {
+		var p OddsPoint
+		var point sql.NullFloat64
+		if err := rows.Scan(&p.GameID, &p.Sport, &p.Bookmaker, &p.Market, &p.Outcome, &p.Price, &point, &p.RecordedAt); err != nil {
+			return nil, err
+		}
+		if point.Valid {
+			p.Point = &point.Float64
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// PruneOddsHistory removes odds recorded before the given time, except for
+// pinned games, and returns how many rows were deleted
+func (db *DB) PruneOddsHistory(before time.Time) (int64, error) {
+	result, err := db.conn.Exec(`
+		DELETE FROM odds_history
+		WHERE recorded_at < ?
+			AND game_id NOT IN (SELECT game_id FROM pinned_games)
+	`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
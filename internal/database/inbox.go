@@ -0,0 +1,83 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// InboxEntry is a stored alert with its read state
+type InboxEntry struct {
+	AlertHistory
+	ReadAt *time.Time `json:"read_at,omitempty"`
+}
+
+// GetInbox returns stored alerts newest first, optionally only unread ones
+func (db *DB) GetInbox(unreadOnly bool, limit int) ([]InboxEntry, error) {
+	rows, err := db.readQuery(`
+		SELECT id, player_name, prop_category, direction, game_id,
+			   line_value, average_value, difference, confidence,
+			   created_at, cooldown_until, COALESCE(state, 'active'), COALESCE(alert_json, ''), read_at
+		FROM alert_history
+		WHERE NOT ? OR read_at IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, unreadOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []InboxEntry
+	for rows.Next() {
+		var e InboxEntry
+		var readAt sql.NullTime
+		if err := rows.Scan(
+			&e.ID, &e.PlayerName, &e.PropCategory, &e.Direction, &e.GameID,
+			&e.LineValue, &e.AverageValue, &e.Difference, &e.Confidence,
+			&e.CreatedAt, &e.CooldownUntil, &e.State, &e.AlertJSON, &readAt,
+		); err != nil {
+			return nil, err
+		}
+		if readAt.Valid {
+			e.ReadAt = &readAt.Time
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// CountUnreadAlerts returns how many stored alerts haven't been read
+func (db *DB) CountUnreadAlerts() (int, error) {
+	var count int
+	err := db.conn.QueryRow(`
+		SELECT COUNT(*) FROM alert_history WHERE read_at IS NULL
+	`).Scan(&count)
+	return count, err
+}
+
+// MarkAlertRead marks a stored alert as read, returning false when it
+// doesn't exist. Marking an already read alert keeps its original time.
+func (db *DB) MarkAlertRead(id int64) (bool, error) {
+	result, err := db.conn.Exec(`
+		UPDATE alert_history SET read_at = COALESCE(read_at, ?)
+		WHERE id = ?
+	`, db.clock.Now().UTC(), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// MarkAllAlertsRead marks every unread alert as read, returning how many
+// were updated
+func (db *DB) MarkAllAlertsRead() (int64, error) {
+	result, err := db.conn.Exec(`
+		UPDATE alert_history SET read_at = ?
+		WHERE read_at IS NULL
+	`, db.clock.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,155 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Alert lifecycle states
+const (
+	// AlertStateActive is an alert whose edge is as it was last notified
+	AlertStateActive = "active"
+	// AlertStateImproved is an alert whose edge has grown since it was notified
+	AlertStateImproved = "improved"
+	// AlertStateDegraded is an alert whose edge has shrunk but still clears
+	// its threshold
+	AlertStateDegraded = "degraded"
+	// AlertStateExpired is an alert whose edge is gone or whose game started
+	AlertStateExpired = "expired"
+	// AlertStateConverted is an alert a bet was logged on. It's final.
+	AlertStateConverted = "converted"
+)
+
+// AlertTransition is a change in an alert's lifecycle state
+type AlertTransition struct {
+	ID         int64     `json:"id"`
+	AlertID    int64     `json:"alert_id"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Reason     string    `json:"reason,omitempty"`
+	LineValue  *float64  `json:"line,omitempty"`
+	Confidence string    `json:"confidence,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// GetTrackedAlerts returns a game's alerts whose state can still change,
+// i.e. every alert not yet converted
+func (db *DB) GetTrackedAlerts(gameID string) ([]AlertHistory, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, player_name, prop_category, direction, game_id,
+			   line_value, average_value, difference, confidence,
+			   created_at, cooldown_until, COALESCE(state, 'active')
+		FROM alert_history
+		WHERE game_id = ? AND COALESCE(state, 'active') != ?
+		ORDER BY created_at, id
+	`, gameID, AlertStateConverted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []AlertHistory
+	for rows.Next() {
+		var h AlertHistory
+		if err := rows.Scan(
+			&h.ID, &h.PlayerName, &h.PropCategory, &h.Direction, &h.GameID,
+			&h.LineValue, &h.AverageValue, &h.Difference, &h.Confidence,
+			&h.CreatedAt, &h.CooldownUntil, &h.State,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// GetLiveAlerts returns alerts whose edge still stands (active, improved or
+// degraded), with the full alert as detected, oldest first
+func (db *DB) GetLiveAlerts() ([]AlertHistory, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, player_name, prop_category, direction, game_id,
+			   line_value, average_value, difference, confidence,
+			   created_at, cooldown_until, COALESCE(state, 'active'), COALESCE(alert_json, '')
+		FROM alert_history
+		WHERE COALESCE(state, 'active') IN (?, ?, ?)
+		ORDER BY created_at, id
+	`, AlertStateActive, AlertStateImproved, AlertStateDegraded)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []AlertHistory
+	for rows.Next() {
+		var h AlertHistory
+		if err := rows.Scan(
+			&h.ID, &h.PlayerName, &h.PropCategory, &h.Direction, &h.GameID,
+			&h.LineValue, &h.AverageValue, &h.Difference, &h.Confidence,
+			&h.CreatedAt, &h.CooldownUntil, &h.State, &h.AlertJSON,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// SaveAlertTransition moves an alert to t.To and records the transition,
+// setting t.ID and t.CreatedAt
+func (db *DB) SaveAlertTransition(t *AlertTransition) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	t.CreatedAt = db.clock.Now().UTC()
+	if _, err := tx.Exec(`
+		UPDATE alert_history SET state = ?, state_changed_at = ?
+		WHERE id = ?
+	`, t.To, t.CreatedAt, t.AlertID); err != nil {
+		return err
+	}
+
+	var line interface{}
+	if t.LineValue != nil {
+		line = *t.LineValue
+	}
+	if err := tx.QueryRow(`
+		INSERT INTO alert_transitions (alert_id, from_state, to_state, reason, line_value, confidence, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, t.AlertID, t.From, t.To, t.Reason, line, t.Confidence, t.CreatedAt).Scan(&t.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAlertTransitions returns an alert's state changes, oldest first
+func (db *DB) GetAlertTransitions(alertID int64) ([]AlertTransition, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, alert_id, from_state, to_state, COALESCE(reason, ''),
+			   line_value, COALESCE(confidence, ''), created_at
+		FROM alert_transitions
+		WHERE alert_id = ?
+		ORDER BY created_at, id
+	`, alertID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transitions []AlertTransition
+	for rows.Next() {
+		var t AlertTransition
+		var line sql.NullFloat64
+		if err := rows.Scan(&t.ID, &t.AlertID, &t.From, &t.To, &t.Reason, &line, &t.Confidence, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		if line.Valid {
+			t.LineValue = &line.Float64
+		}
+		transitions = append(transitions, t)
+	}
+	return transitions, rows.Err()
+}
@@ -0,0 +1,265 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Maintenance operations, run in this order whichever are requested
+const (
+	// OpIntegrityCheck runs PRAGMA integrity_check. SQLite only.
+	OpIntegrityCheck = "integrity_check"
+
+	// OpVacuum rebuilds the database to reclaim space left by deleted rows
+	OpVacuum = "vacuum"
+
+	// OpAnalyze refreshes the statistics the query planner uses
+	OpAnalyze = "analyze"
+)
+
+// MaintenanceOps lists every maintenance operation in the order they run
+var MaintenanceOps = []string{OpIntegrityCheck, OpVacuum, OpAnalyze}
+
+// integrityOK is what integrity_check reports for a healthy database
+const integrityOK = "ok"
+
+// maxIntegrityErrors caps how many problems integrity_check reports
+const maxIntegrityErrors = 100
+
+// TableStats is a table's row count and, where the backend reports it, the
+// space it takes on disk including indexes
+type TableStats struct {
+	Name  string `json:"name"`
+	Rows  int64  `json:"rows"`
+	Bytes int64  `json:"bytes,omitempty"`
+}
+
+// MaintenanceReport is the outcome of a maintenance run
+type MaintenanceReport struct {
+	Backend    string    `json:"backend"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Operations []string  `json:"operations"`
+
+	// Integrity is "ok" or the problems found, when the check ran
+	Integrity []string `json:"integrity,omitempty"`
+
+	SizeBefore int64        `json:"size_before_bytes"`
+	SizeAfter  int64        `json:"size_after_bytes"`
+	Tables     []TableStats `json:"tables"`
+}
+
+// Healthy reports whether the integrity check, if it ran, found nothing
+func (r *MaintenanceReport) Healthy() bool {
+	return len(r.Integrity) == 0 || (len(r.Integrity) == 1 && r.Integrity[0] == integrityOK)
+}
+
+// maintenanceState keeps runs from overlapping and remembers the last one
+type maintenanceState struct {
+	mu   sync.Mutex
+	last *MaintenanceReport
+}
+
+// ParseMaintenanceOps checks a list of maintenance operations, returning
+// them in run order. An empty list means all of them.
+func ParseMaintenanceOps(ops []string) ([]string, error) {
+	if len(ops) == 0 {
+		return MaintenanceOps, nil
+	}
+	requested := make(map[string]bool, len(ops))
+	for _, op := range ops {
+		op = strings.ToLower(strings.TrimSpace(op))
+		known := false
+		for _, valid := range MaintenanceOps {
+			known = known || op == valid
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown operation %q: use %s", op, strings.Join(MaintenanceOps, ", "))
+		}
+		requested[op] = true
+	}
+	var result []string
+	for _, op := range MaintenanceOps {
+		if requested[op] {
+			result = append(result, op)
+		}
+	}
+	return result, nil
+}
+
+// Maintain runs maintenance operations and reports the database's size
+// and tables afterwards. Only one run happens at a time; VACUUM blocks
+// writes while it rebuilds the file. The integrity check is skipped on
+// Postgres, which has no equivalent.
+func (db *DB) Maintain(ops []string) (*MaintenanceReport, error) {
+	db.maintenance.mu.Lock()
+	defer db.maintenance.mu.Unlock()
+
+	report := &MaintenanceReport{
+		Backend:   db.conn.backend,
+		StartedAt: db.clock.Now().UTC(),
+	}
+	start := time.Now()
+
+	var err error
+	if report.SizeBefore, err = db.Size(); err != nil {
+		return nil, fmt.Errorf("measure size: %w", err)
+	}
+	for _, op := range ops {
+		switch op {
+		case OpIntegrityCheck:
+			if db.conn.backend == backendPostgres {
+				continue
+			}
+			if report.Integrity, err = db.IntegrityCheck(); err != nil {
+				return nil, fmt.Errorf("integrity check: %w", err)
+			}
+		case OpVacuum:
+			if _, err := db.conn.Exec(`VACUUM`); err != nil {
+				return nil, fmt.Errorf("vacuum: %w", err)
+			}
+		case OpAnalyze:
+			if _, err := db.conn.Exec(`ANALYZE`); err != nil {
+				return nil, fmt.Errorf("analyze: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unknown operation %q", op)
+		}
+		report.Operations = append(report.Operations, op)
+	}
+	if report.SizeAfter, err = db.Size(); err != nil {
+		return nil, fmt.Errorf("measure size: %w", err)
+	}
+	if report.Tables, err = db.TableStats(); err != nil {
+		return nil, fmt.Errorf("table stats: %w", err)
+	}
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	db.maintenance.last = report
+	return report, nil
+}
+
+// LastMaintenance returns the most recent maintenance run's report, or nil
+// if none has run since startup
+func (db *DB) LastMaintenance() *MaintenanceReport {
+	db.maintenance.mu.Lock()
+	defer db.maintenance.mu.Unlock()
+	return db.maintenance.last
+}
+
+// IntegrityCheck runs SQLite's integrity check, returning "ok" or the
+// problems it found
+func (db *DB) IntegrityCheck() ([]string, error) {
+	if db.conn.backend == backendPostgres {
+		return nil, fmt.Errorf("integrity check is only available on SQLite")
+	}
+
+	rows, err := db.conn.Query(fmt.Sprintf("PRAGMA integrity_check(%d)", maxIntegrityErrors))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		result = append(result, line)
+	}
+	return result, rows.Err()
+}
+
+// Size returns the database's size on disk in bytes
+func (db *DB) Size() (int64, error) {
+	var size int64
+	if db.conn.backend == backendPostgres {
+		err := db.conn.QueryRow(`SELECT pg_database_size(current_database())`).Scan(&size)
+		return size, err
+	}
+
+	var pages, pageSize int64
+	if err := db.conn.QueryRow(`PRAGMA page_count`).Scan(&pages); err != nil {
+		return 0, err
+	}
+	if err := db.conn.QueryRow(`PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return pages * pageSize, nil
+}
+
+// TableStats returns every table's row count and size, largest first.
+// SQLite only reports sizes when built with the dbstat table.
+func (db *DB) TableStats() ([]TableStats, error) {
+	tables, err := db.tableNames()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]TableStats, 0, len(tables))
+	for _, table := range tables {
+		s := TableStats{Name: table}
+		if err := db.conn.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&s.Rows); err != nil {
+			return nil, fmt.Errorf("%s: %w", table, err)
+		}
+		s.Bytes = db.tableBytes(table)
+		stats = append(stats, s)
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Bytes != stats[j].Bytes {
+			return stats[i].Bytes > stats[j].Bytes
+		}
+		return stats[i].Rows > stats[j].Rows
+	})
+	return stats, nil
+}
+
+// tableNames lists the application's tables by name
+func (db *DB) tableNames() ([]string, error) {
+	query := `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`
+	if db.conn.backend == backendPostgres {
+		query = `SELECT tablename FROM pg_tables WHERE schemaname = current_schema() ORDER BY tablename`
+	}
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// tableBytes returns a table's size with its indexes, or 0 when the
+// backend can't say
+func (db *DB) tableBytes(table string) int64 {
+	var size int64
+	if db.conn.backend == backendPostgres {
+		if err := db.conn.QueryRow(`SELECT pg_total_relation_size(?::regclass)`, table).Scan(&size); err != nil {
+			return 0
+		}
+		return size
+	}
+
+	err := db.conn.QueryRow(`
+		SELECT COALESCE(SUM(pgsize), 0) FROM dbstat
+		WHERE name = ? OR name IN (SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = ?)
+	`, table, table).Scan(&size)
+	if err != nil {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// MetricsSnapshot is an instance's metrics counters as they stood at a
+// point in time, as JSON
+type MetricsSnapshot struct {
+	ID       int64           `json:"id"`
+	Instance string          `json:"instance"`
+	Counters json.RawMessage `json:"counters"`
+	TakenAt  time.Time       `json:"taken_at"`
+}
+
+// SaveMetricsSnapshot records an instance's counters
+func (db *DB) SaveMetricsSnapshot(instance string, counters json.RawMessage, takenAt time.Time) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO metrics_snapshots (instance, counters, taken_at)
+		VALUES (?, ?, ?)
+	`, instance, string(counters), takenAt.UTC())
+	return err
+}
+
+// GetMetricsSnapshot returns an instance's latest snapshot taken at or
+// before the given time, or nil when there's none
+func (db *DB) GetMetricsSnapshot(instance string, at time.Time) (*MetricsSnapshot, error) {
+	var s MetricsSnapshot
+	var counters string
+	err := db.conn.QueryRow(`
+		SELECT id, instance, counters, taken_at
+		FROM metrics_snapshots
+		WHERE instance = ? AND taken_at <= ?
+		ORDER BY taken_at DESC, id DESC
+		LIMIT 1
+	`, instance, at.UTC()).Scan(&s.ID, &s.Instance, &counters, &s.TakenAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Counters = json.RawMessage(counters)
+	return &s, nil
+}
+
+// PruneMetricsSnapshots removes snapshots taken before the given time and
+// returns how many were deleted
+func (db *DB) PruneMetricsSnapshots(before time.Time) (int64, error) {
+	result, err := db.conn.Exec(`DELETE FROM metrics_snapshots WHERE taken_at < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
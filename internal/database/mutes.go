@@ -0,0 +1,67 @@
+package database
+
+import "strings"
+
+// PlayerMuted reports whether a player's alerts are muted: whether any
+// muted name is part of theirs, ignoring case
+func (p *Preferences) PlayerMuted(player string) bool {
+	player = strings.ToLower(player)
+	for _, name := range p.MutedPlayers {
+		if name != "" && strings.Contains(player, strings.ToLower(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// MutePlayer adds a name to the muted players and returns them all. A name
+// already muted, ignoring case, isn't added again.
+func (db *DB) MutePlayer(name string) ([]string, error) {
+	muted, err := db.mutedPlayers()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range muted {
+		if strings.EqualFold(m, name) {
+			return muted, nil
+		}
+	}
+	muted = append(muted, name)
+	return muted, db.setMutedPlayers(muted)
+}
+
+// UnmutePlayer removes a name from the muted players, ignoring case, and
+// returns false when it wasn't muted
+func (db *DB) UnmutePlayer(name string) (bool, error) {
+	muted, err := db.mutedPlayers()
+	if err != nil {
+		return false, err
+	}
+	kept := muted[:0]
+	for _, m := range muted {
+		if !strings.EqualFold(m, name) {
+			kept = append(kept, m)
+		}
+	}
+	if len(kept) == len(muted) {
+		return false, nil
+	}
+	return true, db.setMutedPlayers(kept)
+}
+
+// mutedPlayers reads the muted players column on its own
+func (db *DB) mutedPlayers() ([]string, error) {
+	var mutedStr string
+	if err := db.conn.QueryRow(`SELECT muted_players FROM preferences WHERE id = 1`).Scan(&mutedStr); err != nil {
+		return nil, err
+	}
+	return splitAndTrim(mutedStr, ","), nil
+}
+
+// setMutedPlayers writes the muted players column on its own
+func (db *DB) setMutedPlayers(muted []string) error {
+	_, err := db.conn.Exec(`
+		UPDATE preferences SET muted_players = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 1
+	`, joinStrings(muted, ","))
+	return err
+}
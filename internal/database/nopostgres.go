@@ -0,0 +1,6 @@
+//go:build !postgres
+
+package database
+
+// postgresDriver reports whether NewPostgres can connect
+const postgresDriver = false
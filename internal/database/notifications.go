@@ -0,0 +1,233 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Notification log statuses
+const (
+	NotificationSent       = "sent"
+	NotificationRetrying   = "retrying"
+	NotificationDeadLetter = "dead_letter"
+)
+
+// ValidNotificationStatus reports whether a status is one the log uses
+func ValidNotificationStatus(status string) bool {
+	switch status {
+	case NotificationSent, NotificationRetrying, NotificationDeadLetter:
+		return true
+	}
+	return false
+}
+
+// NotificationLogEntry records a delivery on a notification channel
+type NotificationLogEntry struct {
+	ID            int64      `json:"id"`
+	Channel       string     `json:"channel"`
+	Kind          string     `json:"kind"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	Error         string     `json:"error,omitempty"`
+	Payload       string     `json:"payload"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"` // while retrying
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// LogNotification records a delivery, returning its log ID
+func (db *DB) LogNotification(e NotificationLogEntry) (int64, error) {
+	now := db.clock.Now().UTC()
+	return db.conn.insert(`
+		INSERT INTO notification_log (channel, kind, status, attempts, error, payload, next_attempt_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, e.Channel, e.Kind, e.Status, e.Attempts, e.Error, e.Payload, nullableTime(e.NextAttemptAt), now, now)
+}
+
+// UpdateNotificationLog records a logged delivery's latest attempt
+func (db *DB) UpdateNotificationLog(e NotificationLogEntry) error {
+	_, err := db.conn.Exec(`
+		UPDATE notification_log
+		SET status = ?, attempts = ?, error = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id = ?
+	`, e.Status, e.Attempts, e.Error, nullableTime(e.NextAttemptAt), db.clock.Now().UTC(), e.ID)
+	return err
+}
+
+// nullableTime is a time argument in UTC, or NULL for nil
+func nullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// AbandonRetryingNotifications dead-letters deliveries still waiting to be
+// retried, for use at startup: their retries were lost with the previous
+// run. Returns how many were abandoned.
+func (db *DB) AbandonRetryingNotifications() (int64, error) {
+	result, err := db.conn.Exec(`
+		UPDATE notification_log
+		SET status = ?, error = CASE WHEN error = '' THEN ? ELSE error || ' (' || ? || ')' END,
+			next_attempt_at = NULL, updated_at = ?
+		WHERE status = ?
+	`, NotificationDeadLetter, "interrupted by restart", "interrupted by restart", db.clock.Now().UTC(), NotificationRetrying)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetNotificationLog returns the most recent log entries, newest first,
+// optionally filtered by channel and status
+func (db *DB) GetNotificationLog(channel, status string, limit int) ([]NotificationLogEntry, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, channel, kind, status, attempts, error, payload, next_attempt_at, created_at, updated_at
+		FROM notification_log
+		WHERE (? = '' OR channel = ?) AND (? = '' OR status = ?)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, channel, channel, status, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []NotificationLogEntry
+	for rows.Next() {
+		var e NotificationLogEntry
+		var nextAttempt, updatedAt sql.NullTime
+		if err := rows.Scan(&e.ID, &e.Channel, &e.Kind, &e.Status, &e.Attempts, &e.Error, &e.Payload, &nextAttempt,
+			&e.CreatedAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		if nextAttempt.Valid {
+			e.NextAttemptAt = &nextAttempt.Time
+		}
+		// Entries logged before updates were recorded
+		e.UpdatedAt = e.CreatedAt
+		if updatedAt.Valid {
+			e.UpdatedAt = updatedAt.Time
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// NotificationChannelStats summarizes a channel's notification log
+type NotificationChannelStats struct {
+	LastSent    *time.Time `json:"last_sent"`
+	LastFailure *time.Time `json:"last_failure"`
+	LastError   string     `json:"last_error,omitempty"`
+
+	// Deliveries since the stats' cutoff
+	Sent     int `json:"sent"`
+	Failures int `json:"failures"`
+}
+
+// GetNotificationStats returns each logged channel's last send and last
+// failure, with its sends and dead letters since the cutoff
+func (db *DB) GetNotificationStats(since time.Time) (map[string]NotificationChannelStats, error) {
+	stats := make(map[string]NotificationChannelStats)
+
+	rows, err := db.conn.Query(`
+		SELECT channel, status, COUNT(*)
+		FROM notification_log
+		WHERE created_at >= ?
+		GROUP BY channel, status
+	`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var channel, status string
+		var count int
+		if err := rows.Scan(&channel, &status, &count); err != nil {
+			return nil, err
+		}
+		st := stats[channel]
+		switch status {
+		case NotificationSent:
+			st.Sent = count
+		case NotificationDeadLetter:
+			st.Failures = count
+		}
+		stats[channel] = st
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	latest, err := db.conn.Query(`
+		SELECT channel, status, error, created_at
+		FROM notification_log
+		WHERE id IN (SELECT MAX(id) FROM notification_log GROUP BY channel, status)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer latest.Close()
+	for latest.Next() {
+		var channel, status, errMsg string
+		var at time.Time
+		if err := latest.Scan(&channel, &status, &errMsg, &at); err != nil {
+			return nil, err
+		}
+		st := stats[channel]
+		switch status {
+		case NotificationSent:
+			st.LastSent = &at
+		case NotificationDeadLetter:
+			st.LastFailure = &at
+			st.LastError = errMsg
+		}
+		stats[channel] = st
+	}
+	return stats, latest.Err()
+}
+
+// ClaimPendingNotifications marks pending notifications as part of a batch
+// so they aren't picked up again while the batch is being delivered
+func (db *DB) ClaimPendingNotifications(ids []int64, batchID string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := "UPDATE pending_notifications SET batch_id = ? WHERE id IN ("
+	args := []interface{}{batchID}
+	for i, id := range ids {
+		if i > 0 {
+			query += ","
+		}
+		query += "?"
+		args = append(args, id)
+	}
+	query += ")"
+
+	_, err := db.conn.Exec(query, args...)
+	return err
+}
+
+// ReleasePendingNotifications returns a batch's notifications to the queue.
+// An empty batch ID releases every claimed notification, for use at startup.
+func (db *DB) ReleasePendingNotifications(batchID string) error {
+	_, err := db.conn.Exec(`
+		UPDATE pending_notifications SET batch_id = NULL
+		WHERE batch_id IS NOT NULL AND (? = '' OR batch_id = ?)
+	`, batchID, batchID)
+	return err
+}
+
+// ExpirePendingNotifications deletes unclaimed notifications queued before
+// the cutoff, returning how many were removed
+func (db *DB) ExpirePendingNotifications(before time.Time) (int64, error) {
+	result, err := db.conn.Exec(`
+		DELETE FROM pending_notifications
+		WHERE batch_id IS NULL AND created_at < ?
+	`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
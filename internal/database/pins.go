@@ -0,0 +1,150 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// PinnedGame is a game kept for post-game analysis. Its odds history and
+// alerts aren't cleaned up while it's pinned.
+type PinnedGame struct {
+	GameID   string    `json:"game_id"`
+	Sport    string    `json:"sport"`
+	PinnedAt time.Time `json:"pinned_at"`
+}
+
+// PinGame pins a game, returning the pin. Pinning a pinned game again
+// keeps the original pin time.
+func (db *DB) PinGame(gameID string, sport models.Sport) (*PinnedGame, error) {
+	if _, err := db.conn.Exec(`
+		INSERT INTO pinned_games (game_id, sport, pinned_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(game_id) DO NOTHING
+	`, gameID, string(sport), db.clock.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	p := &PinnedGame{}
+	err := db.conn.QueryRow(`
+		SELECT game_id, sport, pinned_at FROM pinned_games WHERE game_id = ?
+	`, gameID).Scan(&p.GameID, &p.Sport, &p.PinnedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UnpinGame removes a pin, returning false when the game wasn't pinned
+func (db *DB) UnpinGame(gameID string) (bool, error) {
+	result, err := db.conn.Exec(`DELETE FROM pinned_games WHERE game_id = ?`, gameID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// GetPinnedGames returns every pinned game, most recently pinned first
+func (db *DB) GetPinnedGames() ([]PinnedGame, error) {
+	rows, err := db.conn.Query(`
+		SELECT game_id, sport, pinned_at FROM pinned_games
+		ORDER BY pinned_at DESC, game_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pins []PinnedGame
+	for rows.Next() {
+		var p PinnedGame
+		if err := rows.Scan(&p.GameID, &p.Sport, &p.PinnedAt); err != nil {
+			return nil, err
+		}
+		pins = append(pins, p)
+	}
+	return pins, rows.Err()
+}
+
+// GetGameSnapshot returns the last stored copy of a game, or nil if none.
+// Snapshots outlive the store, so they hold final games' closing odds.
+func (db *DB) GetGameSnapshot(gameID string) (*models.Game, error) {
+	var data string
+	err := db.conn.QueryRow(`SELECT data FROM game_snapshots WHERE game_id = ?`, gameID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var g models.Game
+	if err := json.Unmarshal([]byte(data), &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// GetGameAlerts returns every alert sent for a game in any state, oldest
+// first
+func (db *DB) GetGameAlerts(gameID string) ([]AlertHistory, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, player_name, prop_category, direction, game_id,
+			   line_value, average_value, difference, confidence,
+			   created_at, cooldown_until, COALESCE(state, 'active')
+		FROM alert_history
+		WHERE game_id = ?
+		ORDER BY created_at, id
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []AlertHistory
+	for rows.Next() {
+		var h AlertHistory
+		if err := rows.Scan(
+			&h.ID, &h.PlayerName, &h.PropCategory, &h.Direction, &h.GameID,
+			&h.LineValue, &h.AverageValue, &h.Difference, &h.Confidence,
+			&h.CreatedAt, &h.CooldownUntil, &h.State,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// GetGameFeedback returns feedback on a game's alerts keyed by alert ID
+func (db *DB) GetGameFeedback(gameID string) (map[int64]AlertFeedback, error) {
+	rows, err := db.conn.Query(`
+		SELECT f.id, f.alert_id, f.player_name, f.prop_category, f.direction, f.confidence,
+			   f.rating, COALESCE(f.outcome, ''), COALESCE(f.note, ''), COALESCE(f.stake, 0),
+			   f.created_at, f.updated_at
+		FROM alert_feedback f
+		JOIN alert_history h ON h.id = f.alert_id
+		WHERE h.game_id = ?
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feedback := make(map[int64]AlertFeedback)
+	for rows.Next() {
+		var f AlertFeedback
+		if err := rows.Scan(
+			&f.ID, &f.AlertID, &f.PlayerName, &f.PropCategory, &f.Direction, &f.Confidence,
+			&f.Rating, &f.Outcome, &f.Note, &f.Stake,
+			&f.CreatedAt, &f.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		feedback[f.AlertID] = f
+	}
+	return feedback, rows.Err()
+}
@@ -0,0 +1,9 @@
+//go:build postgres
+
+package database
+
+// The pgx driver is only compiled in with the postgres tag
+import _ "github.com/jackc/pgx/v5/stdlib"
+
+// postgresDriver reports whether NewPostgres can connect
+const postgresDriver = true
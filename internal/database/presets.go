@@ -0,0 +1,176 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// PreferencePreset is a named set of alert settings: thresholds, the
+// confidence filter, batching and quiet hours. Applying one changes those
+// preferences and leaves the rest, such as channels and limits, alone.
+type PreferencePreset struct {
+	Name    string `json:"name"`
+	BuiltIn bool   `json:"built_in"`
+
+	ThresholdPoints   float64 `json:"threshold_points"`
+	ThresholdRebounds float64 `json:"threshold_rebounds"`
+	ThresholdAssists  float64 `json:"threshold_assists"`
+	ThresholdThrees   float64 `json:"threshold_threes"`
+	ThresholdDefault  float64 `json:"threshold_default"`
+	EVThresholdPct    float64 `json:"ev_threshold_pct"`
+
+	PeriodEVThresholdPct float64 `json:"period_ev_threshold_pct,omitempty"`
+
+	MinConfidence        string `json:"min_confidence"`
+	BatchIntervalSeconds int    `json:"batch_interval_seconds"`
+
+	// Quiet hours; the same start and end turns them off
+	QuietStart string `json:"quiet_start"`
+	QuietEnd   string `json:"quiet_end"`
+
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// BuiltInPresets can't be changed or deleted. Balanced is the defaults.
+var BuiltInPresets = []PreferencePreset{
+	{
+		Name: "conservative", BuiltIn: true,
+		ThresholdPoints: 3.0, ThresholdRebounds: 2.0, ThresholdAssists: 1.5,
+		ThresholdThrees: 1.0, ThresholdDefault: 3.0, EVThresholdPct: 4.0,
+		PeriodEVThresholdPct: 5.0, MinConfidence: "high", BatchIntervalSeconds: 300,
+		QuietStart: "22:00", QuietEnd: "09:00",
+	},
+	{
+		Name: "balanced", BuiltIn: true,
+		ThresholdPoints: 2.0, ThresholdRebounds: 1.5, ThresholdAssists: 1.0,
+		ThresholdThrees: 0.5, ThresholdDefault: 2.0, EVThresholdPct: 2.0,
+		PeriodEVThresholdPct: 3.0, MinConfidence: "low", BatchIntervalSeconds: 60,
+		QuietStart: "23:00", QuietEnd: "08:00",
+	},
+	{
+		Name: "aggressive", BuiltIn: true,
+		ThresholdPoints: 1.5, ThresholdRebounds: 1.0, ThresholdAssists: 0.5,
+		ThresholdThrees: 0.5, ThresholdDefault: 1.5, EVThresholdPct: 1.0,
+		PeriodEVThresholdPct: 2.0, MinConfidence: "low", BatchIntervalSeconds: 30,
+		QuietStart: "00:00", QuietEnd: "00:00",
+	},
+}
+
+// BuiltInPreset returns the built-in preset with the name, or nil
+func BuiltInPreset(name string) *PreferencePreset {
+	for i := range BuiltInPresets {
+		if BuiltInPresets[i].Name == name {
+			preset := BuiltInPresets[i]
+			return &preset
+		}
+	}
+	return nil
+}
+
+// PresetFromPreferences captures the preset settings of the preferences
+func PresetFromPreferences(name string, p *Preferences) PreferencePreset {
+	return PreferencePreset{
+		Name:                 name,
+		ThresholdPoints:      p.ThresholdPoints,
+		ThresholdRebounds:    p.ThresholdRebounds,
+		ThresholdAssists:     p.ThresholdAssists,
+		ThresholdThrees:      p.ThresholdThrees,
+		ThresholdDefault:     p.ThresholdDefault,
+		EVThresholdPct:       p.EVThresholdPct,
+		PeriodEVThresholdPct: p.PeriodEVThresholdPct,
+		MinConfidence:        p.MinConfidence,
+		BatchIntervalSeconds: p.BatchIntervalSeconds,
+		QuietStart:           p.QuietStart,
+		QuietEnd:             p.QuietEnd,
+	}
+}
+
+// Apply sets the preset's settings on the preferences
+func (preset PreferencePreset) Apply(p *Preferences) {
+	p.ThresholdPoints = preset.ThresholdPoints
+	p.ThresholdRebounds = preset.ThresholdRebounds
+	p.ThresholdAssists = preset.ThresholdAssists
+	p.ThresholdThrees = preset.ThresholdThrees
+	p.ThresholdDefault = preset.ThresholdDefault
+	p.EVThresholdPct = preset.EVThresholdPct
+	// Presets saved before half and quarter markets leave theirs alone
+	if preset.PeriodEVThresholdPct > 0 {
+		p.PeriodEVThresholdPct = preset.PeriodEVThresholdPct
+	}
+	p.MinConfidence = preset.MinConfidence
+	p.BatchIntervalSeconds = preset.BatchIntervalSeconds
+	p.QuietStart = preset.QuietStart
+	p.QuietEnd = preset.QuietEnd
+}
+
+// SavePreferencePreset stores a custom preset, replacing one with the same
+// name
+func (db *DB) SavePreferencePreset(preset *PreferencePreset) error {
+	preset.BuiltIn = false
+	now := db.clock.Now().UTC()
+	preset.CreatedAt = &now
+
+	settings, err := json.Marshal(preset)
+	if err != nil {
+		return err
+	}
+	_, err = db.conn.Exec(`
+		INSERT INTO preference_presets (name, settings, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET settings = excluded.settings, created_at = excluded.created_at
+	`, preset.Name, string(settings), now)
+	return err
+}
+
+// GetPreferencePreset returns a custom preset, or nil if there's none by
+// the name
+func (db *DB) GetPreferencePreset(name string) (*PreferencePreset, error) {
+	var settings string
+	err := db.conn.QueryRow(`SELECT settings FROM preference_presets WHERE name = ?`, name).Scan(&settings)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var preset PreferencePreset
+	if err := json.Unmarshal([]byte(settings), &preset); err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+// GetPreferencePresets returns the custom presets by name
+func (db *DB) GetPreferencePresets() ([]PreferencePreset, error) {
+	rows, err := db.conn.Query(`SELECT settings FROM preference_presets ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	presets := []PreferencePreset{}
+	for rows.Next() {
+		var settings string
+		if err := rows.Scan(&settings); err != nil {
+			return nil, err
+		}
+		var preset PreferencePreset
+		if err := json.Unmarshal([]byte(settings), &preset); err != nil {
+			return nil, err
+		}
+		presets = append(presets, preset)
+	}
+	return presets, rows.Err()
+}
+
+// DeletePreferencePreset removes a custom preset, returning false when
+// there was none by the name
+func (db *DB) DeletePreferencePreset(name string) (bool, error) {
+	result, err := db.conn.Exec(`DELETE FROM preference_presets WHERE name = ?`, name)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Projection kinds
+const (
+	// ProjectionModel is a model's projected stat, blended with averages
+	ProjectionModel = "projection"
+	// ProjectionTarget is a line the user expects, compared against as is
+	ProjectionTarget = "target"
+)
+
+// Projection is an external model's projection for a player's stat
+type Projection struct {
+	Player     string     `json:"player"`
+	Category   string     `json:"category"`
+	Projection float64    `json:"projection"`
+	StdDev     float64    `json:"stddev"`
+	Source     string     `json:"source"`
+	Kind       string     `json:"kind"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// SaveProjections inserts or replaces projections in one transaction
+func (db *DB) SaveProjections(projections []Projection) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := db.clock.Now().UTC()
+	for _, p := range projections {
+		kind := p.Kind
+		if kind == "" {
+			kind = ProjectionModel
+		}
+		var expiresAt interface{}
+		if p.ExpiresAt != nil {
+			expiresAt = p.ExpiresAt.UTC()
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO projections (player, category, source, projection, stddev, kind, expires_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(player, category, source) DO UPDATE SET
+				projection = excluded.projection,
+				stddev = excluded.stddev,
+				kind = excluded.kind,
+				expires_at = excluded.expires_at,
+				updated_at = excluded.updated_at
+		`, p.Player, p.Category, p.Source, p.Projection, p.StdDev, kind, expiresAt, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetProjections returns every unexpired projection, most recently updated
+// first
+func (db *DB) GetProjections() ([]Projection, error) {
+	rows, err := db.conn.Query(`
+		SELECT player, category, source, projection, stddev,
+			   COALESCE(kind, 'projection'), expires_at, updated_at
+		FROM projections
+		WHERE expires_at IS NULL OR expires_at > ?
+		ORDER BY updated_at DESC, player, category
+	`, db.clock.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projections []Projection
+	for rows.Next() {
+		var p Projection
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&p.Player, &p.Category, &p.Source, &p.Projection, &p.StdDev, &p.Kind, &expiresAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if expiresAt.Valid {
+			p.ExpiresAt = &expiresAt.Time
+		}
+		projections = append(projections, p)
+	}
+	return projections, rows.Err()
+}
+
+// DeleteProjections removes a source's projections, returning how many
+func (db *DB) DeleteProjections(source string) (int64, error) {
+	result, err := db.conn.Exec(`DELETE FROM projections WHERE source = ?`, source)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
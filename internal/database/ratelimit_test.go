@@ -0,0 +1,46 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+)
+
+func TestRateLimitWindow(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "ratelimit.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	fake := clock.NewFake(time.Date(2026, 1, 15, 19, 10, 0, 0, time.UTC))
+	db.SetClock(fake)
+
+	const limit = 2
+	for i := 0; i < limit; i++ {
+		if ok, remaining, err := db.CheckRateLimit("push", limit); err != nil || !ok || remaining != limit-i {
+			t.Fatalf("send %d: got (%v, %d, %v), want (true, %d, nil)", i+1, ok, remaining, err, limit-i)
+		}
+		if err := db.IncrementRateLimit("push"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ok, remaining, err := db.CheckRateLimit("push", limit); err != nil || ok || remaining != 0 {
+		t.Fatalf("over the limit: got (%v, %d, %v), want (false, 0, nil)", ok, remaining, err)
+	}
+	if ok, _, err := db.CheckRateLimit("email", limit); err != nil || !ok {
+		t.Errorf("email limited by push sends: got (%v, %v)", ok, err)
+	}
+
+	// Windows are clock hours, so the count resets on the hour
+	fake.Set(time.Date(2026, 1, 15, 19, 59, 0, 0, time.UTC))
+	if ok, _, _ := db.CheckRateLimit("push", limit); ok {
+		t.Error("limit reset before the hour")
+	}
+	fake.Advance(time.Minute)
+	if ok, remaining, err := db.CheckRateLimit("push", limit); err != nil || !ok || remaining != limit {
+		t.Errorf("next hour: got (%v, %d, %v), want (true, %d, nil)", ok, remaining, err, limit)
+	}
+}
@@ -0,0 +1,58 @@
+package database
+
+// GetAlertsToRecheck returns a game's alerts that haven't had their pre-game
+// re-check and haven't been acted on with feedback, oldest first
+func (db *DB) GetAlertsToRecheck(gameID string) ([]AlertHistory, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, player_name, prop_category, direction, game_id,
+			   line_value, average_value, difference, confidence,
+			   created_at, cooldown_until, COALESCE(state, 'active')
+		FROM alert_history
+		WHERE game_id = ? AND rechecked_at IS NULL
+			AND id NOT IN (SELECT alert_id FROM alert_feedback)
+		ORDER BY created_at, id
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []AlertHistory
+	for rows.Next() {
+		var h AlertHistory
+		if err := rows.Scan(
+			&h.ID, &h.PlayerName, &h.PropCategory, &h.Direction, &h.GameID,
+			&h.LineValue, &h.AverageValue, &h.Difference, &h.Confidence,
+			&h.CreatedAt, &h.CooldownUntil, &h.State,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// SaveAlertRecheck records the outcome of an alert's pre-game re-check. When
+// the edge is still live, the alert's line, average and confidence are
+// updated to the re-checked values.
+func (db *DB) SaveAlertRecheck(id int64, status string, h *AlertHistory) error {
+	now := db.clock.Now().UTC()
+	if h == nil {
+		_, err := db.conn.Exec(`
+			UPDATE alert_history SET recheck_status = ?, rechecked_at = ?
+			WHERE id = ?
+		`, status, now, id)
+		return err
+	}
+	_, err := db.conn.Exec(`
+		UPDATE alert_history SET
+			recheck_status = ?,
+			rechecked_at = ?,
+			line_value = ?,
+			average_value = ?,
+			difference = ?,
+			confidence = ?
+		WHERE id = ?
+	`, status, now, h.LineValue, h.AverageValue, h.Difference, h.Confidence, id)
+	return err
+}
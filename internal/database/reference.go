@@ -0,0 +1,292 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// UpstreamSport is a sport listed by The Odds API
+type UpstreamSport struct {
+	Key         string    `json:"key"`
+	Group       string    `json:"group"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// Player is a roster entry from SportsDataIO
+type Player struct {
+	Sport        string `json:"sport"`
+	PlayerID     int    `json:"player_id"`
+	Name         string `json:"name"`
+	Team         string `json:"team"`
+	Position     string `json:"position"`
+	InjuryStatus string `json:"injury_status"`
+}
+
+// PlayerAverage is a player's recent average for one prop category
+type PlayerAverage struct {
+	Sport       string  `json:"sport"`
+	PlayerName  string  `json:"player_name"`
+	Category    string  `json:"category"`
+	Average     float64 `json:"average"`
+	StdDev      float64 `json:"stddev"` // spread of the games averaged
+	GamesPlayed int     `json:"games_played"`
+}
+
+// SaveUpstreamSports replaces the stored sports list
+func (db *DB) SaveUpstreamSports(sports []UpstreamSport) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM upstream_sports`); err != nil {
+		return err
+	}
+
+	now := db.clock.Now().UTC()
+	for _, s := range sports {
+		_, err := tx.Exec(`
+			INSERT INTO upstream_sports (sport_key, group_name, title, description, active, fetched_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, s.Key, s.Group, s.Title, s.Description, s.Active, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SaveGameSnapshots stores games so they can be loaded into the store on startup
+func (db *DB) SaveGameSnapshots(games []models.Game) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := db.clock.Now().UTC()
+	for _, g := range games {
+		data, err := json.Marshal(g)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO game_snapshots (game_id, sport, commence_time, data, fetched_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(game_id) DO UPDATE SET
+				commence_time = excluded.commence_time,
+				data = excluded.data,
+				fetched_at = excluded.fetched_at
+		`, g.ID, string(g.SportKey), g.CommenceTime.UTC(), string(data), now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetGameSnapshots returns stored games that haven't started before since
+func (db *DB) GetGameSnapshots(since time.Time) ([]models.Game, error) {
+	rows, err := db.readQuery(`
+		SELECT data FROM game_snapshots
+		WHERE commence_time >= ?
+		ORDER BY commence_time
+	`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []models.Game
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var g models.Game
+		if err := json.Unmarshal([]byte(data), &g); err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+	}
+	return games, rows.Err()
+}
+
+// SavePropSnapshot stores the player props fetched for a game
+func (db *DB) SavePropSnapshot(sport models.Sport, props *models.GamePlayerProps) error {
+	data, err := json.Marshal(props)
+	if err != nil {
+		return err
+	}
+	_, err = db.conn.Exec(`
+		INSERT INTO prop_snapshots (game_id, sport, data, fetched_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(game_id) DO UPDATE SET
+			data = excluded.data,
+			fetched_at = excluded.fetched_at
+	`, props.GameID, string(sport), string(data), db.clock.Now().UTC())
+	return err
+}
+
+// GetPropSnapshot returns stored player props for a game, or nil if none
+func (db *DB) GetPropSnapshot(gameID string) (*models.GamePlayerProps, error) {
+	var data string
+	err := db.conn.QueryRow(`SELECT data FROM prop_snapshots WHERE game_id = ?`, gameID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var props models.GamePlayerProps
+	if err := json.Unmarshal([]byte(data), &props); err != nil {
+		return nil, err
+	}
+	return &props, nil
+}
+
+// GetPropSnapshots returns player props stored since the given time
+func (db *DB) GetPropSnapshots(since time.Time) ([]models.GamePlayerProps, error) {
+	rows, err := db.readQuery(`
+		SELECT data FROM prop_snapshots
+		WHERE fetched_at >= ?
+	`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.GamePlayerProps
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var props models.GamePlayerProps
+		if err := json.Unmarshal([]byte(data), &props); err != nil {
+			return nil, err
+		}
+		result = append(result, props)
+	}
+	return result, rows.Err()
+}
+
+// SavePlayers upserts roster entries
+func (db *DB) SavePlayers(players []Player) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := db.clock.Now().UTC()
+	for _, p := range players {
+		_, err := tx.Exec(`
+			INSERT INTO players (sport, player_id, name, team, position, injury_status, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(sport, player_id) DO UPDATE SET
+				name = excluded.name,
+				team = excluded.team,
+				position = excluded.position,
+				injury_status = excluded.injury_status,
+				updated_at = excluded.updated_at
+		`, p.Sport, p.PlayerID, p.Name, p.Team, p.Position, p.InjuryStatus, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SavePlayerAverages upserts per-category player averages
+func (db *DB) SavePlayerAverages(averages []PlayerAverage) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := db.clock.Now().UTC()
+	for _, a := range averages {
+		_, err := tx.Exec(`
+			INSERT INTO player_averages (sport, player_name, category, average, stddev, games_played, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(sport, player_name, category) DO UPDATE SET
+				average = excluded.average,
+				stddev = excluded.stddev,
+				games_played = excluded.games_played,
+				updated_at = excluded.updated_at
+		`, a.Sport, a.PlayerName, a.Category, a.Average, a.StdDev, a.GamesPlayed, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetUpstreamSports returns the stored sports list, ordered by group and title
+func (db *DB) GetUpstreamSports() ([]UpstreamSport, error) {
+	rows, err := db.conn.Query(`
+		SELECT sport_key, group_name, title, description, active, fetched_at
+		FROM upstream_sports
+		ORDER BY group_name, title
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sports []UpstreamSport
+	for rows.Next() {
+		var s UpstreamSport
+		if err := rows.Scan(&s.Key, &s.Group, &s.Title, &s.Description, &s.Active, &s.FetchedAt); err != nil {
+			return nil, err
+		}
+		sports = append(sports, s)
+	}
+	return sports, rows.Err()
+}
+
+// GetSportSettings returns the locally enabled state of each configured sport
+func (db *DB) GetSportSettings() (map[string]bool, error) {
+	rows, err := db.conn.Query(`SELECT sport_key, enabled FROM sport_settings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := make(map[string]bool)
+	for rows.Next() {
+		var key string
+		var enabled bool
+		if err := rows.Scan(&key, &enabled); err != nil {
+			return nil, err
+		}
+		settings[key] = enabled
+	}
+	return settings, rows.Err()
+}
+
+// SetSportEnabled enables or disables a sport locally
+func (db *DB) SetSportEnabled(key string, enabled bool) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO sport_settings (sport_key, enabled, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(sport_key) DO UPDATE SET
+			enabled = excluded.enabled,
+			updated_at = excluded.updated_at
+	`, key, enabled, db.clock.Now().UTC())
+	return err
+}
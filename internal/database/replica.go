@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// replicaPingTimeout bounds each replica health check
+const replicaPingTimeout = 5 * time.Second
+
+// replicaState is an optional Postgres read replica and whether it's
+// answering
+type replicaState struct {
+	mu      sync.RWMutex
+	conn    *conn
+	healthy bool
+}
+
+// current returns the replica while it's healthy, otherwise nil
+func (r *replicaState) current() *conn {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.healthy {
+		return nil
+	}
+	return r.conn
+}
+
+// SetReadReplica connects a Postgres read replica. Read-heavy queries (odds
+// history, reports and the alert inbox) go to it while it's healthy; every
+// write, and anything read back right after one, stays on the primary. A
+// replica that can't be reached yet is used once a check succeeds.
+func (db *DB) SetReadReplica(url string) error {
+	if db.conn.backend != backendPostgres {
+		return fmt.Errorf("read replicas need Postgres")
+	}
+	sqlDB, err := sql.Open("pgx", url)
+	if err != nil {
+		return err
+	}
+
+	db.replica.mu.Lock()
+	db.replica.conn = &conn{DB: sqlDB, backend: backendPostgres}
+	db.replica.mu.Unlock()
+
+	if err := db.CheckReplica(); err != nil {
+		log.Printf("Database: read replica unavailable, reading from the primary: %v", err)
+	}
+	return nil
+}
+
+// HasReplica reports whether a read replica is configured
+func (db *DB) HasReplica() bool {
+	db.replica.mu.RLock()
+	defer db.replica.mu.RUnlock()
+	return db.replica.conn != nil
+}
+
+// CheckReplica pings the read replica, routing reads back to it when it
+// answers and to the primary when it doesn't. It returns nil without a
+// replica.
+func (db *DB) CheckReplica() error {
+	db.replica.mu.RLock()
+	replica := db.replica.conn
+	db.replica.mu.RUnlock()
+	if replica == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+	defer cancel()
+	err := replica.PingContext(ctx)
+
+	db.replica.mu.Lock()
+	was := db.replica.healthy
+	db.replica.healthy = err == nil
+	db.replica.mu.Unlock()
+
+	switch {
+	case was && err != nil:
+		log.Printf("Database: read replica failed, reading from the primary: %v", err)
+	case !was && err == nil:
+		log.Println("Database: reading from the read replica")
+	}
+	return err
+}
+
+// readQuery runs a read-heavy query on the replica while it's healthy. If
+// the replica fails and a check finds it down, the query runs on the
+// primary instead; errors from the query itself are returned as is.
+func (db *DB) readQuery(query string, args ...interface{}) (*sql.Rows, error) {
+	replica := db.replica.current()
+	if replica == nil {
+		return db.conn.Query(query, args...)
+	}
+	rows, err := replica.Query(query, args...)
+	if err == nil {
+		return rows, nil
+	}
+	if db.CheckReplica() == nil {
+		return nil, err
+	}
+	return db.conn.Query(query, args...)
+}
+
+// closeReplica closes the read replica's connections, if any
+func (db *DB) closeReplica() error {
+	db.replica.mu.Lock()
+	defer db.replica.mu.Unlock()
+	if db.replica.conn == nil {
+		return nil
+	}
+	err := db.replica.conn.Close()
+	db.replica.conn = nil
+	db.replica.healthy = false
+	return err
+}
@@ -0,0 +1,21 @@
+package database
+
+import "github.com/joshuakim/linefinder/internal/models"
+
+// SportEnabled reports whether alerts for a sport, by short or Odds API
+// key, are wanted. An empty sports list wants every sport.
+func (p *Preferences) SportEnabled(sport string) bool {
+	if len(p.Sports) == 0 {
+		return true
+	}
+	want, ok := models.ParseSport(sport)
+	if !ok {
+		return true
+	}
+	for _, s := range p.Sports {
+		if enabled, ok := models.ParseSport(s); ok && enabled == want {
+			return true
+		}
+	}
+	return false
+}
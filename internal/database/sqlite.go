@@ -0,0 +1,45 @@
+package database
+
+import _ "modernc.org/sqlite"
+
+// SQLite drivers New can open a file with, chosen by SQLITE_DRIVER. The cgo
+// driver wraps the C library and is only compiled in when cgo is on. The
+// pure-Go one is a translation of the library to Go, so it's always there,
+// but it's slower on heavy writes.
+const (
+	SQLiteDriverCgo    = "cgo"
+	SQLiteDriverPureGo = "purego"
+)
+
+// sqliteDriver is a SQLite driver's package, its database/sql name and the
+// DSN options every connection opens with
+type sqliteDriver struct {
+	pkg    string
+	name   string
+	params string
+}
+
+// sqliteDrivers by SQLITE_DRIVER value. Both use WAL and a five second busy
+// timeout, and the pure-Go driver writes times in the format the cgo one
+// does, so a database written with one reads back the same with the other.
+var sqliteDrivers = map[string]sqliteDriver{
+	SQLiteDriverCgo: {
+		pkg:    "mattn/go-sqlite3",
+		name:   "sqlite3",
+		params: "?_journal_mode=WAL",
+	},
+	SQLiteDriverPureGo: {
+		pkg:    "modernc.org/sqlite",
+		name:   "sqlite",
+		params: "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_time_format=sqlite",
+	},
+}
+
+// DefaultSQLiteDriver is the cgo driver when it's compiled in, otherwise
+// the pure-Go one
+func DefaultSQLiteDriver() string {
+	if cgoSQLite {
+		return SQLiteDriverCgo
+	}
+	return SQLiteDriverPureGo
+}
@@ -0,0 +1,8 @@
+//go:build cgo
+
+package database
+
+import _ "github.com/mattn/go-sqlite3"
+
+// cgoSQLite reports whether the cgo SQLite driver is compiled in
+const cgoSQLite = true
@@ -0,0 +1,6 @@
+//go:build !cgo
+
+package database
+
+// cgoSQLite reports whether the cgo SQLite driver is compiled in
+const cgoSQLite = false
@@ -0,0 +1,24 @@
+package database
+
+import "time"
+
+// CountAlertsSince returns how many alerts were recorded since a time
+func (db *DB) CountAlertsSince(since time.Time) (int, error) {
+	var count int
+	err := db.conn.QueryRow(`
+		SELECT COUNT(*) FROM alert_history WHERE created_at >= ?
+	`, since.UTC().Format("2006-01-02 15:04:05")).Scan(&count)
+	return count, err
+}
+
+// CountFeedbackOutcomes returns how many alerts were reported won and lost
+// with feedback
+func (db *DB) CountFeedbackOutcomes() (wins, losses int, err error) {
+	err = db.conn.QueryRow(`
+		SELECT
+			COALESCE(SUM(CASE WHEN outcome = ? THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN outcome = ? THEN 1 ELSE 0 END), 0)
+		FROM alert_feedback
+	`, OutcomeWin, OutcomeLoss).Scan(&wins, &losses)
+	return wins, losses, err
+}
@@ -0,0 +1,34 @@
+package database
+
+import "time"
+
+// The interfaces below are the state instances have to share to run side
+// by side without repeating alerts or exceeding rate limits. DB implements
+// them on SQLite, for a single instance, and on Postgres, for several.
+
+// PreferencesStore reads the user's notification preferences
+type PreferencesStore interface {
+	GetPreferences() (*Preferences, error)
+}
+
+// AlertHistoryStore holds sent alerts, used for deduplication and cooldowns
+type AlertHistoryStore interface {
+	GetAlertHistory(playerName, propCategory, direction, gameID string) (*AlertHistory, error)
+	SaveAlertHistory(h *AlertHistory) error
+}
+
+// RateLimitStore counts notifications sent per channel each hour
+type RateLimitStore interface {
+	CheckRateLimit(channel string, limit int) (bool, int, error)
+	IncrementRateLimit(channel string) error
+}
+
+// PendingNotificationStore queues notifications waiting to be retried
+type PendingNotificationStore interface {
+	AddPendingNotification(alertJSON string) error
+	GetPendingNotifications() ([]PendingNotification, error)
+	ClaimPendingNotifications(ids []int64, batchID string) error
+	ReleasePendingNotifications(batchID string) error
+	ClearPendingNotifications(ids []int64) error
+	ExpirePendingNotifications(before time.Time) (int64, error)
+}
@@ -0,0 +1,10 @@
+package database
+
+// Subscribed reports whether alerts in a category are wanted over a
+// channel. Categories and channels missing from AlertSubscriptions are.
+func (p *Preferences) Subscribed(category, channel string) bool {
+	if on, ok := p.AlertSubscriptions[category][channel]; ok {
+		return on
+	}
+	return true
+}
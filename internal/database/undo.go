@@ -0,0 +1,138 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Undo kinds: what an undo token restores
+const (
+	// UndoPreferences restores every preference as it was before a change
+	// or an unsubscribe
+	UndoPreferences = "preferences"
+
+	// UndoPreset restores a deleted custom preset
+	UndoPreset = "preset"
+
+	// UndoWebhook restores a deleted webhook with its delivery history
+	UndoWebhook = "webhook"
+)
+
+// UndoWindow is how long a destructive change can be undone. Deleted
+// webhooks are purged once it passes.
+const UndoWindow = 5 * time.Minute
+
+// UndoAction is a token that reverses a destructive change until it expires
+type UndoAction struct {
+	Token     string    `json:"undo_token"`
+	Kind      string    `json:"kind"`
+	ExpiresAt time.Time `json:"undo_expires_at"`
+}
+
+// SaveUndo stores what a destructive change replaced, a snapshot for
+// preferences and presets or the ID of a soft-deleted webhook, and returns
+// the token that restores it. Expired actions are cleared on the way.
+func (db *DB) SaveUndo(kind string, state interface{}) (*UndoAction, error) {
+	if err := db.purgeUndo(); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := db.encrypt(string(payload))
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 16)
+	if _, err := io.ReadFull(db.rand, buf); err != nil {
+		return nil, err
+	}
+
+	now := db.clock.Now().UTC()
+	action := &UndoAction{
+		Token:     hex.EncodeToString(buf),
+		Kind:      kind,
+		ExpiresAt: now.Add(UndoWindow),
+	}
+	_, err = db.conn.Exec(`
+		INSERT INTO undo_actions (token, kind, payload, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, action.Token, kind, sealed, now, action.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// Undo reverses the change behind a token and uses the token up, returning
+// the kind restored. It returns "" when the token is unknown or expired.
+func (db *DB) Undo(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+
+	var kind, payload string
+	var expiresAt time.Time
+	err := db.conn.QueryRow(`
+		SELECT kind, payload, expires_at FROM undo_actions WHERE token = ?
+	`, token).Scan(&kind, &payload, &expiresAt)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !db.clock.Now().Before(expiresAt) {
+		return "", nil
+	}
+	if payload, err = db.decrypt(payload); err != nil {
+		return "", err
+	}
+
+	switch kind {
+	case UndoPreferences:
+		var p Preferences
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			return "", err
+		}
+		err = db.UpdatePreferences(&p)
+	case UndoPreset:
+		var preset PreferencePreset
+		if err := json.Unmarshal([]byte(payload), &preset); err != nil {
+			return "", err
+		}
+		err = db.SavePreferencePreset(&preset)
+	case UndoWebhook:
+		var id int64
+		if err := json.Unmarshal([]byte(payload), &id); err != nil {
+			return "", err
+		}
+		_, err = db.conn.Exec(`UPDATE webhooks SET deleted_at = NULL WHERE id = ?`, id)
+	default:
+		return "", fmt.Errorf("unknown undo kind %q", kind)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := db.conn.Exec(`DELETE FROM undo_actions WHERE token = ?`, token); err != nil {
+		return "", err
+	}
+	return kind, nil
+}
+
+// purgeUndo clears expired undo actions and the webhooks they can no
+// longer bring back
+func (db *DB) purgeUndo() error {
+	now := db.clock.Now().UTC()
+	if _, err := db.conn.Exec(`DELETE FROM undo_actions WHERE expires_at <= ?`, now); err != nil {
+		return err
+	}
+	return db.purgeDeletedWebhooks(now.Add(-UndoWindow))
+}
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookPending   = "pending"
+	WebhookDelivered = "delivered"
+	WebhookFailed    = "failed"
+)
+
+// Webhook is a URL that alert batches are POSTed to, signed with its secret
+type Webhook struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"` // only returned when created
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is one POST to a webhook and how it went
+type WebhookDelivery struct {
+	ID             int64     `json:"id"`
+	WebhookID      int64     `json:"webhook_id"`
+	Event          string    `json:"event"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	ResponseStatus int       `json:"response_status,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	Payload        string    `json:"payload"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CreateWebhook saves a webhook, setting its ID and creation time
+func (db *DB) CreateWebhook(w *Webhook) error {
+	secret, err := db.encrypt(w.Secret)
+	if err != nil {
+		return err
+	}
+	w.CreatedAt = db.clock.Now().UTC()
+	id, err := db.conn.insert(`
+		INSERT INTO webhooks (url, secret, enabled, created_at)
+		VALUES (?, ?, ?, ?)
+	`, w.URL, secret, w.Enabled, w.CreatedAt)
+	if err != nil {
+		return err
+	}
+	w.ID = id
+	return nil
+}
+
+// GetWebhooks returns every webhook not deleted, oldest first. Secrets are included, so
+// callers serving them over the API should clear them.
+func (db *DB) GetWebhooks() ([]Webhook, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, url, secret, enabled, created_at
+		FROM webhooks
+		WHERE deleted_at IS NULL
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []Webhook
+	for rows.Next() {
+		var w Webhook
+		if err := rows.Scan(&w.ID, &w.URL, &w.Secret, &w.Enabled, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		if w.Secret, err = db.decrypt(w.Secret); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+// SetWebhookEnabled turns a webhook on or off, returning false when it
+// doesn't exist
+func (db *DB) SetWebhookEnabled(id int64, enabled bool) (bool, error) {
+	result, err := db.conn.Exec(`UPDATE webhooks SET enabled = ? WHERE id = ? AND deleted_at IS NULL`, enabled, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteWebhook soft-deletes a webhook, returning false when it doesn't
+// exist. It stops receiving alerts at once; it and its delivery records are
+// purged once the undo window passes.
+func (db *DB) DeleteWebhook(id int64) (bool, error) {
+	result, err := db.conn.Exec(`
+		UPDATE webhooks SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL
+	`, db.clock.Now().UTC(), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// purgeDeletedWebhooks removes webhooks deleted before the cutoff and their
+// delivery records
+func (db *DB) purgeDeletedWebhooks(before time.Time) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		DELETE FROM webhook_deliveries
+		WHERE webhook_id IN (SELECT id FROM webhooks WHERE deleted_at IS NOT NULL AND deleted_at < ?)
+	`, before); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM webhooks WHERE deleted_at IS NOT NULL AND deleted_at < ?`, before); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateWebhookDelivery records a delivery before its first attempt,
+// setting its ID and timestamps
+func (db *DB) CreateWebhookDelivery(d *WebhookDelivery) error {
+	now := db.clock.Now().UTC()
+	d.CreatedAt, d.UpdatedAt = now, now
+	if d.Status == "" {
+		d.Status = WebhookPending
+	}
+	id, err := db.conn.insert(`
+		INSERT INTO webhook_deliveries (webhook_id, event, status, attempts, response_status, error, payload, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, d.WebhookID, d.Event, d.Status, d.Attempts, d.ResponseStatus, d.Error, d.Payload, d.CreatedAt, d.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	d.ID = id
+	return nil
+}
+
+// UpdateWebhookDelivery saves a delivery's status after an attempt
+func (db *DB) UpdateWebhookDelivery(d *WebhookDelivery) error {
+	d.UpdatedAt = db.clock.Now().UTC()
+	_, err := db.conn.Exec(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, response_status = ?, error = ?, updated_at = ?
+		WHERE id = ?
+	`, d.Status, d.Attempts, d.ResponseStatus, d.Error, d.UpdatedAt, d.ID)
+	return err
+}
+
+// GetWebhookDeliveries returns a webhook's most recent deliveries, newest
+// first, optionally filtered by status
+func (db *DB) GetWebhookDeliveries(webhookID int64, status string, limit int) ([]WebhookDelivery, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, webhook_id, event, status, attempts, response_status, error, payload, created_at, updated_at
+		FROM webhook_deliveries
+		WHERE webhook_id = ? AND (? = '' OR status = ?)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, webhookID, status, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		var errMsg sql.NullString
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.Event, &d.Status, &d.Attempts, &d.ResponseStatus, &errMsg, &d.Payload, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+		d.Error = errMsg.String
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
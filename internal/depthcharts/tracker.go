@@ -0,0 +1,247 @@
+package depthcharts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/sportsdata"
+)
+
+// Change kinds
+const (
+	// Promoted is a player moving up to the top of their position
+	Promoted = "promoted"
+
+	// Demoted is a player losing the top spot at their position
+	Demoted = "demoted"
+)
+
+// trackedPositions are the offensive positions that drive player props
+var trackedPositions = map[string]bool{
+	"QB": true,
+	"RB": true,
+	"WR": true,
+	"TE": true,
+}
+
+// recentChangeWindow is how long a role change is mentioned as context
+const recentChangeWindow = 7 * 24 * time.Hour
+
+// Config holds depth chart tracker configuration
+type Config struct {
+	// Interval is the time between depth chart refreshes
+	Interval time.Duration
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Interval: time.Hour,
+	}
+}
+
+// Role is a player's place on their team's depth chart
+type Role struct {
+	Player   string `json:"player"`
+	TeamID   int    `json:"team_id"`
+	Position string `json:"position"`
+	Depth    int    `json:"depth"`
+}
+
+// Label returns the role as position and depth, e.g. "RB1"
+func (r Role) Label() string {
+	return fmt.Sprintf("%s%d", r.Position, r.Depth)
+}
+
+// Change is a move into or out of the top spot at a position
+type Change struct {
+	Kind       string    `json:"kind"`
+	Player     string    `json:"player"`
+	TeamID     int       `json:"team_id"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// Tracker keeps NFL depth charts current and reports role changes
+type Tracker struct {
+	config     Config
+	sportsData *sportsdata.Client
+	clock      clock.Clock
+
+	mu        sync.RWMutex
+	roles     map[string]Role   // lowercased name -> role
+	changes   map[string]Change // lowercased name -> most recent change
+	updatedAt time.Time
+	callback  func([]Change)
+}
+
+// NewTracker creates a new depth chart tracker
+func NewTracker(config Config, sportsData *sportsdata.Client) *Tracker {
+	return &Tracker{
+		config:     config,
+		sportsData: sportsData,
+		clock:      clock.Real{},
+		roles:      make(map[string]Role),
+		changes:    make(map[string]Change),
+	}
+}
+
+// SetClock sets the clock used for change timestamps
+func (t *Tracker) SetClock(c clock.Clock) {
+	t.clock = c
+}
+
+// SetCallback sets the function called with role changes after each refresh
+func (t *Tracker) SetCallback(fn func([]Change)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.callback = fn
+}
+
+// Start refreshes depth charts immediately and then on every interval
+func (t *Tracker) Start(ctx context.Context) {
+	if t.config.Interval <= 0 {
+		t.config.Interval = DefaultConfig().Interval
+	}
+
+	log.Printf("Depth chart tracker starting (interval: %v)", t.config.Interval)
+	t.refreshAndLog()
+
+	ticker := time.NewTicker(t.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.refreshAndLog()
+		}
+	}
+}
+
+func (t *Tracker) refreshAndLog() {
+	if err := t.Refresh(); err != nil {
+		log.Printf("Depth charts: %v", err)
+	}
+}
+
+// Refresh fetches depth charts and records role changes. The first refresh
+// only establishes the baseline.
+func (t *Tracker) Refresh() error {
+	charts, err := t.sportsData.GetNFLDepthCharts()
+	if err != nil {
+		return err
+	}
+
+	roles := make(map[string]Role)
+	for _, chart := range charts {
+		for _, e := range chart.Offense {
+			if !trackedPositions[e.Position] || e.DepthOrder <= 0 {
+				continue
+			}
+			key := strings.ToLower(e.Name)
+			// A player listed at several positions keeps their highest spot
+			if existing, ok := roles[key]; ok && existing.Depth <= e.DepthOrder {
+				continue
+			}
+			roles[key] = Role{Player: e.Name, TeamID: e.TeamID, Position: e.Position, Depth: e.DepthOrder}
+		}
+	}
+
+	now := t.clock.Now()
+
+	t.mu.Lock()
+	var changes []Change
+	if !t.updatedAt.IsZero() {
+		changes = diff(t.roles, roles, now)
+	}
+	for _, c := range changes {
+		t.changes[strings.ToLower(c.Player)] = c
+	}
+	for key, c := range t.changes {
+		if now.Sub(c.DetectedAt) > recentChangeWindow {
+			delete(t.changes, key)
+		}
+	}
+	t.roles = roles
+	t.updatedAt = now
+	callback := t.callback
+	t.mu.Unlock()
+
+	if len(changes) > 0 {
+		log.Printf("Depth charts: %d role changes", len(changes))
+		if callback != nil {
+			callback(changes)
+		}
+	}
+	return nil
+}
+
+// diff finds players who moved into or out of the top spot at a position
+func diff(previous, current map[string]Role, now time.Time) []Change {
+	var changes []Change
+	for key, cur := range current {
+		prev, ok := previous[key]
+		if !ok || prev.Position != cur.Position || prev.Depth == cur.Depth {
+			continue
+		}
+		if cur.Depth != 1 && prev.Depth != 1 {
+			continue
+		}
+
+		kind := Promoted
+		if cur.Depth > prev.Depth {
+			kind = Demoted
+		}
+		changes = append(changes, Change{
+			Kind:       kind,
+			Player:     cur.Player,
+			TeamID:     cur.TeamID,
+			From:       prev.Label(),
+			To:         cur.Label(),
+			DetectedAt: now,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Player < changes[j].Player
+	})
+	return changes
+}
+
+// Role returns a player's current depth chart role
+func (t *Tracker) Role(player string) (Role, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	r, ok := t.roles[strings.ToLower(player)]
+	return r, ok
+}
+
+// Context describes a player's role for notifications, mentioning a recent
+// change, e.g. "RB1, up from RB2". Returns "" for untracked players.
+func (t *Tracker) Context(player string) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	key := strings.ToLower(player)
+	role, ok := t.roles[key]
+	if !ok {
+		return ""
+	}
+	if c, ok := t.changes[key]; ok && c.To == role.Label() {
+		direction := "up"
+		if c.Kind == Demoted {
+			direction = "down"
+		}
+		return fmt.Sprintf("%s, %s from %s", role.Label(), direction, c.From)
+	}
+	return role.Label()
+}
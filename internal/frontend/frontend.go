@@ -0,0 +1,84 @@
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Static serves a built frontend bundle (such as web/dist) from a
+// directory. Paths that aren't files get index.html so client-side routes
+// load the app. Vite's fingerprinted assets are cached for good; index.html
+// is revalidated so new builds show up on the next load.
+func Static(dir string) (http.Handler, error) {
+	index := filepath.Join(dir, "index.html")
+	if _, err := os.Stat(index); err != nil {
+		return nil, fmt.Errorf("frontend bundle: %w", err)
+	}
+
+	files := http.FileServer(http.Dir(dir))
+	return apiNotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean("/" + r.URL.Path)
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil || info.IsDir() {
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+			// A missing file with an extension is a real 404, not a route
+			if path.Ext(name) != "" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Cache-Control", "no-cache")
+			http.ServeFile(w, r, index)
+			return
+		}
+
+		if strings.HasPrefix(name, "/assets/") {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		files.ServeHTTP(w, r)
+	})), nil
+}
+
+// Proxy forwards frontend requests to another server, such as the Vite dev
+// server, including its WebSocket for hot reload
+func Proxy(target string) (http.Handler, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("frontend proxy: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("frontend proxy: %q is not an http(s) URL", target)
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(u)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		// Dev servers check the host they're asked for
+		r.Host = u.Host
+	}
+	return apiNotFound(proxy), nil
+}
+
+// apiNotFound keeps unknown API paths from falling through to the
+// frontend, so they get a 404 rather than the app
+func apiNotFound(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api" || strings.HasPrefix(r.URL.Path, "/api/") {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,174 @@
+package gamestatus
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/oddsapi"
+	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+// Config holds game status tracking configuration
+type Config struct {
+	// Interval is the time between checks. Checks move games that have
+	// started to live without any requests.
+	Interval time.Duration
+
+	// ScoresInterval is the time between scores lookups for a sport while
+	// it has live games. Each lookup costs 2 requests.
+	ScoresInterval time.Duration
+
+	// FinalAfter is how long after its start a live game is assumed over
+	// when no final score has come in, such as when scores can't be fetched
+	FinalAfter time.Duration
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Interval:       time.Minute,
+		ScoresInterval: 5 * time.Minute,
+		FinalAfter:     6 * time.Hour,
+	}
+}
+
+// Tracker moves games from scheduled to live to final, removing final
+// games from the store and telling WebSocket subscribers
+type Tracker struct {
+	config      Config
+	client      *oddsapi.Client
+	oddsService *service.OddsService
+	hub         *websocket.Hub
+	clock       clock.Clock
+
+	mu         sync.Mutex
+	lastScores map[models.Sport]time.Time
+}
+
+// NewTracker creates a new game status tracker. Without a client, live
+// games are only finished by FinalAfter.
+func NewTracker(config Config, client *oddsapi.Client, oddsService *service.OddsService, hub *websocket.Hub) *Tracker {
+	return &Tracker{
+		config:      config,
+		client:      client,
+		oddsService: oddsService,
+		hub:         hub,
+		clock:       clock.Real{},
+		lastScores:  make(map[models.Sport]time.Time),
+	}
+}
+
+// SetClock sets the clock used to decide which games have started
+func (t *Tracker) SetClock(c clock.Clock) {
+	t.clock = c
+}
+
+// Start checks games on every interval until the context is cancelled
+func (t *Tracker) Start(ctx context.Context) {
+	if t.config.Interval <= 0 {
+		t.config.Interval = DefaultConfig().Interval
+	}
+
+	log.Printf("Game status tracker starting (interval: %v, scores: %v, final after: %v)",
+		t.config.Interval, t.config.ScoresInterval, t.config.FinalAfter)
+
+	ticker := time.NewTicker(t.config.Interval)
+	defer ticker.Stop()
+
+	t.Check()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.Check()
+		}
+	}
+}
+
+// Check works out every stored game's status, looking up scores for
+// sports with live games when they're due, and returns the statuses that
+// changed by game ID
+func (t *Tracker) Check() map[string]models.GameStatus {
+	now := t.clock.Now()
+	statuses := make(map[string]models.GameStatus)
+	live := make(map[models.Sport][]models.Game)
+	var sports []models.Sport
+
+	for _, game := range t.oddsService.GetAllGames() {
+		status := game.Status
+		if status != models.GameLive && game.Started(now) {
+			status = models.GameLive
+		}
+		if status == models.GameLive && t.config.FinalAfter > 0 && now.Sub(game.CommenceTime) >= t.config.FinalAfter {
+			status = models.GameFinal
+		}
+		if status != game.Status {
+			statuses[game.ID] = status
+		}
+		if status == models.GameLive {
+			if _, ok := live[game.SportKey]; !ok {
+				sports = append(sports, game.SportKey)
+			}
+			live[game.SportKey] = append(live[game.SportKey], game)
+		}
+	}
+
+	for _, sport := range sports {
+		if !t.scoresDue(sport, now) {
+			continue
+		}
+		scores, err := t.client.GetScores(sport, 1)
+		if err != nil {
+			log.Printf("Game status: Failed to get %s scores: %v", sport, err)
+			continue
+		}
+		completed := make(map[string]bool, len(scores))
+		for _, s := range scores {
+			completed[s.ID] = s.Completed
+		}
+		for _, game := range live[sport] {
+			if completed[game.ID] {
+				statuses[game.ID] = models.GameFinal
+			}
+		}
+	}
+
+	if len(statuses) == 0 {
+		return statuses
+	}
+
+	var finals int
+	for _, status := range statuses {
+		if status == models.GameFinal {
+			finals++
+		}
+	}
+	changed := t.oddsService.SetGameStatuses(statuses)
+	for _, sport := range changed {
+		// Subscribers replace their games with these, dropping final ones
+		t.hub.Broadcast(sport, t.oddsService.GetGamesBySport(sport))
+	}
+	log.Printf("Game status: %d game(s) changed status, %d final and removed", len(statuses), finals)
+	return statuses
+}
+
+// scoresDue reports whether a sport's scores should be looked up, and if
+// so counts the lookup
+func (t *Tracker) scoresDue(sport models.Sport, now time.Time) bool {
+	if t.client == nil || t.config.ScoresInterval <= 0 {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if last, ok := t.lastScores[sport]; ok && now.Sub(last) < t.config.ScoresInterval {
+		return false
+	}
+	t.lastScores[sport] = now
+	return true
+}
@@ -0,0 +1,321 @@
+package injuries
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/reference"
+	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/sportsdata"
+	"github.com/joshuakim/linefinder/internal/store"
+)
+
+// Change kinds
+const (
+	// Downgrade is a player becoming less likely to play, e.g.
+	// Questionable to Out
+	Downgrade = "downgrade"
+
+	// Upgrade is a player becoming more likely to play
+	Upgrade = "upgrade"
+)
+
+// severity orders injury statuses by how unlikely the player is to play.
+// No status means healthy; statuses not listed count as out.
+var severity = map[string]int{
+	"":             0,
+	"probable":     1,
+	"questionable": 2,
+	"doubtful":     3,
+	"out":          4,
+}
+
+// Config holds injury monitor configuration
+type Config struct {
+	// Interval is the time between roster checks
+	Interval time.Duration
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Interval: 10 * time.Minute,
+	}
+}
+
+// Change is a player's injury status changing before one of their games
+type Change struct {
+	Kind         string    `json:"kind"`
+	Sport        string    `json:"sport"`
+	PlayerID     int       `json:"player_id"`
+	Player       string    `json:"player"`
+	Position     string    `json:"position"`
+	Team         string    `json:"team"`
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	BodyPart     string    `json:"body_part,omitempty"`
+	Notes        string    `json:"notes,omitempty"`
+	GameID       string    `json:"game_id"`
+	HomeTeam     string    `json:"home_team"`
+	AwayTeam     string    `json:"away_team"`
+	CommenceTime time.Time `json:"commence_time"`
+
+	// Teammates are the prop lines of the player's teammates in the game,
+	// which usually move with the news
+	Teammates []models.PlayerWithProps `json:"teammates,omitempty"`
+
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// Summary describes the change in a sentence, e.g. "Jaylen Brown (SF)
+// went from Questionable to Out (Knee) for the Boston Celtics"
+func (c Change) Summary() string {
+	s := fmt.Sprintf("%s (%s) went from %s to %s", c.Player, c.Position, StatusLabel(c.From), StatusLabel(c.To))
+	if c.BodyPart != "" {
+		s += fmt.Sprintf(" (%s)", c.BodyPart)
+	}
+	return s + fmt.Sprintf(" for the %s", c.Team)
+}
+
+// StatusLabel names an injury status, with no status as healthy
+func StatusLabel(status string) string {
+	if status == "" {
+		return "Healthy"
+	}
+	return status
+}
+
+// statusSeverity ranks an injury status, see severity
+func statusSeverity(status string) int {
+	if rank, ok := severity[strings.ToLower(status)]; ok {
+		return rank
+	}
+	return severity["out"]
+}
+
+// Monitor polls SportsDataIO rosters and reports injury status changes for
+// players with an upcoming game
+type Monitor struct {
+	config      Config
+	sportsData  *sportsdata.Client
+	oddsService *service.OddsService
+	clock       clock.Clock
+
+	mu       sync.RWMutex
+	statuses map[models.Sport]map[int]string // player ID -> injury status
+	callback func([]Change)
+}
+
+// NewMonitor creates a new injury monitor
+func NewMonitor(config Config, sportsData *sportsdata.Client, oddsService *service.OddsService) *Monitor {
+	return &Monitor{
+		config:      config,
+		sportsData:  sportsData,
+		oddsService: oddsService,
+		clock:       clock.Real{},
+		statuses:    make(map[models.Sport]map[int]string),
+	}
+}
+
+// SetClock sets the clock used to find upcoming games
+func (m *Monitor) SetClock(c clock.Clock) {
+	m.clock = c
+}
+
+// SetCallback sets the function called with injury status changes
+func (m *Monitor) SetCallback(fn func([]Change)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callback = fn
+}
+
+// Start checks rosters on every interval until the context is cancelled
+func (m *Monitor) Start(ctx context.Context) {
+	if m.config.Interval <= 0 {
+		m.config.Interval = DefaultConfig().Interval
+	}
+
+	log.Printf("Injury monitor starting (interval: %v)", m.config.Interval)
+
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// Check fetches NBA and NFL rosters and reports status changes since the
+// last check. A sport's first check only records statuses.
+func (m *Monitor) Check() {
+	now := m.clock.Now()
+
+	var changes []Change
+	for _, sport := range []models.Sport{models.SportNBA, models.SportNFL} {
+		games := m.upcoming(sport, now)
+		if len(games) == 0 {
+			// Start the next slate from a fresh baseline rather than
+			// reporting everything that changed while no games were listed
+			m.mu.Lock()
+			delete(m.statuses, sport)
+			m.mu.Unlock()
+			continue
+		}
+
+		var roster []sportsdata.Player
+		var err error
+		if sport == models.SportNBA {
+			roster, err = m.sportsData.GetNBAPlayers()
+		} else {
+			roster, err = m.sportsData.GetNFLPlayers()
+		}
+		if err != nil {
+			log.Printf("Injuries: %s: %v", sport.ShortKey(), err)
+			continue
+		}
+		changes = append(changes, m.update(sport, roster, games, now)...)
+	}
+
+	m.mu.RLock()
+	callback := m.callback
+	m.mu.RUnlock()
+	if len(changes) > 0 {
+		log.Printf("Injuries: %d injury status changes", len(changes))
+		if callback != nil {
+			callback(changes)
+		}
+	}
+}
+
+// upcoming returns a sport's games that haven't started, soonest first
+func (m *Monitor) upcoming(sport models.Sport, now time.Time) []models.Game {
+	var games []models.Game
+	for _, game := range m.oddsService.GetGamesBySport(sport) {
+		if !game.Started(now) {
+			games = append(games, game)
+		}
+	}
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].CommenceTime.Before(games[j].CommenceTime)
+	})
+	return games
+}
+
+// update records a sport's injury statuses and returns the changes for
+// players whose team has an upcoming game
+func (m *Monitor) update(sport models.Sport, roster []sportsdata.Player, games []models.Game, now time.Time) []Change {
+	m.mu.Lock()
+	previous, seeded := m.statuses[sport]
+	current := make(map[int]string, len(roster))
+	for _, p := range roster {
+		current[p.PlayerID] = injuryStatus(p)
+	}
+	m.statuses[sport] = current
+	m.mu.Unlock()
+
+	if !seeded {
+		return nil
+	}
+
+	var changes []Change
+	for _, p := range roster {
+		from, known := previous[p.PlayerID]
+		to := current[p.PlayerID]
+		if !known || strings.EqualFold(from, to) {
+			continue
+		}
+		game, team, ok := nextGame(games, p.Team)
+		if !ok {
+			continue
+		}
+
+		kind := Downgrade
+		if statusSeverity(to) < statusSeverity(from) {
+			kind = Upgrade
+		}
+		name := strings.TrimSpace(p.FirstName + " " + p.LastName)
+		change := Change{
+			Kind:         kind,
+			Sport:        string(sport),
+			PlayerID:     p.PlayerID,
+			Player:       name,
+			Position:     p.Position,
+			Team:         team,
+			From:         from,
+			To:           to,
+			GameID:       game.ID,
+			HomeTeam:     game.HomeTeam,
+			AwayTeam:     game.AwayTeam,
+			CommenceTime: game.CommenceTime,
+			Teammates:    teammateProps(sport, game, team, name),
+			DetectedAt:   now,
+		}
+		if p.InjuryBodyPart != nil {
+			change.BodyPart = *p.InjuryBodyPart
+		}
+		if p.InjuryNotes != nil {
+			change.Notes = *p.InjuryNotes
+		}
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].GameID != changes[j].GameID {
+			return changes[i].GameID < changes[j].GameID
+		}
+		return changes[i].Player < changes[j].Player
+	})
+	return changes
+}
+
+// injuryStatus returns a player's injury status, empty when healthy
+func injuryStatus(p sportsdata.Player) string {
+	if p.InjuryStatus == nil {
+		return ""
+	}
+	return strings.TrimSpace(*p.InjuryStatus)
+}
+
+// nextGame finds the soonest upcoming game for a team by its SportsDataIO
+// key, returning the game and the team's Odds API name
+func nextGame(games []models.Game, teamKey string) (models.Game, string, bool) {
+	if teamKey == "" {
+		return models.Game{}, "", false
+	}
+	for _, game := range games {
+		if key, ok := reference.Abbreviation(game.HomeTeam); ok && key == teamKey {
+			return game, game.HomeTeam, true
+		}
+		if key, ok := reference.Abbreviation(game.AwayTeam); ok && key == teamKey {
+			return game, game.AwayTeam, true
+		}
+	}
+	return models.Game{}, "", false
+}
+
+// teammateProps returns the prop lines of a player's teammates in a game
+func teammateProps(sport models.Sport, game models.Game, team, player string) []models.PlayerWithProps {
+	props := store.GetDummyPlayerProps(game.ID, sport, game.HomeTeam, game.AwayTeam)
+	if props == nil {
+		return nil
+	}
+	var teammates []models.PlayerWithProps
+	for _, p := range props.Players {
+		if p.Team == team && !strings.EqualFold(p.Name, player) && len(p.Props) > 0 {
+			teammates = append(teammates, p)
+		}
+	}
+	return teammates
+}
@@ -0,0 +1,100 @@
+// Package lineseries tracks how lines move over time, such as one outcome
+// at one bookmaker, keeping only their recent moves
+package lineseries
+
+import (
+	"sort"
+	"time"
+)
+
+// sample is a line's value from the time it was first seen
+type sample[V comparable] struct {
+	at    time.Time
+	value V
+}
+
+// Series is one line's recent moves, with what the caller knows about it
+type Series[V comparable, I any] struct {
+	Info     I
+	samples  []sample[V]
+	lastSeen time.Time
+}
+
+// At returns the line's value at a time: the last move at or before it, or
+// the first value seen when the series starts later
+func (s *Series[V, I]) At(t time.Time) V {
+	i := sort.Search(len(s.samples), func(i int) bool { return s.samples[i].at.After(t) })
+	if i == 0 {
+		return s.samples[0].value
+	}
+	return s.samples[i-1].value
+}
+
+// Latest returns the line's current value
+func (s *Series[V, I]) Latest() V {
+	return s.samples[len(s.samples)-1].value
+}
+
+// Tracker keeps series by key. It isn't safe for concurrent use; callers
+// guard it with their own lock.
+type Tracker[V comparable, I any] struct {
+	retention time.Duration
+	expiry    time.Duration
+	series    map[string]*Series[V, I]
+}
+
+// New creates a tracker that keeps moves for retention and forgets series
+// that haven't been seen for expiry
+func New[V comparable, I any](retention, expiry time.Duration) *Tracker[V, I] {
+	return &Tracker[V, I]{
+		retention: retention,
+		expiry:    expiry,
+		series:    make(map[string]*Series[V, I]),
+	}
+}
+
+// Observe records a line's current value and info. Only moves are kept;
+// the value holds until the next one.
+func (t *Tracker[V, I]) Observe(key string, info I, value V, now time.Time) *Series[V, I] {
+	s := t.series[key]
+	if s == nil {
+		s = &Series[V, I]{}
+		t.series[key] = s
+	}
+	s.Info = info
+	s.lastSeen = now
+	if n := len(s.samples); n == 0 || s.samples[n-1].value != value {
+		s.samples = append(s.samples, sample[V]{at: now, value: value})
+	}
+	t.prune(s, now)
+	return s
+}
+
+// prune drops moves older than the retention, keeping the last of them as
+// the value the retention starts from
+func (t *Tracker[V, I]) prune(s *Series[V, I], now time.Time) {
+	cutoff := now.Add(-t.retention)
+	i := sort.Search(len(s.samples), func(i int) bool { return s.samples[i].at.After(cutoff) })
+	if i > 1 {
+		s.samples = append(s.samples[:0], s.samples[i-1:]...)
+	}
+}
+
+// Forget drops series that haven't been seen within the expiry, such as
+// finished games
+func (t *Tracker[V, I]) Forget(now time.Time) {
+	for key, s := range t.series {
+		if now.Sub(s.lastSeen) > t.expiry {
+			delete(t.series, key)
+		}
+	}
+}
+
+// All returns every series, in no particular order
+func (t *Tracker[V, I]) All() []*Series[V, I] {
+	all := make([]*Series[V, I], 0, len(t.series))
+	for _, s := range t.series {
+		all = append(all, s)
+	}
+	return all
+}
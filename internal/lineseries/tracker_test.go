@@ -0,0 +1,50 @@
+package lineseries
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	tracker := New[float64, string](10*time.Minute, 20*time.Minute)
+
+	tracker.Observe("celtics", "draftkings", -3, at(0))
+	tracker.Observe("celtics", "draftkings", -3, at(2))
+	tracker.Observe("celtics", "draftkings", -3.5, at(5))
+	s := tracker.Observe("celtics", "fanduel", -4, at(8))
+
+	if s.Info != "fanduel" {
+		t.Errorf("info %q, want the latest", s.Info)
+	}
+	// An unchanged value isn't a move
+	if len(s.samples) != 3 {
+		t.Errorf("%d samples, want 3", len(s.samples))
+	}
+	for _, tt := range []struct {
+		at   time.Time
+		want float64
+	}{
+		{at(-5), -3}, // before the series started
+		{at(3), -3},
+		{at(5), -3.5},
+		{at(30), -4},
+	} {
+		if got := s.At(tt.at); got != tt.want {
+			t.Errorf("At(%v) = %g, want %g", tt.at.Sub(start), got, tt.want)
+		}
+	}
+
+	// Past the retention, the last old move is kept as where the window starts
+	s = tracker.Observe("celtics", "fanduel", -4, at(17))
+	if len(s.samples) != 2 || s.At(at(7)) != -3.5 || s.Latest() != -4 {
+		t.Errorf("after pruning: %+v", s.samples)
+	}
+
+	tracker.Observe("lakers", "fanduel", 2, at(20))
+	tracker.Forget(at(38))
+	if all := tracker.All(); len(all) != 1 || all[0].Latest() != 2 {
+		t.Errorf("after forgetting celtics: %d series", len(all))
+	}
+}
@@ -0,0 +1,333 @@
+package lineups
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/reference"
+	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/sportsdata"
+)
+
+// Change kinds
+const (
+	// StarterOut is a projected starter missing from the confirmed lineup
+	StarterOut = "starter_out"
+
+	// SurpriseStart is a confirmed starter who wasn't projected to start
+	SurpriseStart = "surprise_start"
+)
+
+// Config holds lineup monitor configuration
+type Config struct {
+	// Interval is the time between lineup checks
+	Interval time.Duration
+
+	// Window is how long before tip-off lineups are checked
+	Window time.Duration
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Interval: 5 * time.Minute,
+		Window:   2 * time.Hour,
+	}
+}
+
+// Starter is a player in a starting lineup
+type Starter struct {
+	Name     string `json:"name"`
+	Position string `json:"position"`
+}
+
+// TeamLineup is one team's starters
+type TeamLineup struct {
+	Team      string    `json:"team"`
+	Confirmed bool      `json:"confirmed"`
+	Starters  []Starter `json:"starters"`
+}
+
+// Change is a difference between the projected and confirmed lineups
+type Change struct {
+	Kind       string    `json:"kind"`
+	GameID     string    `json:"game_id"`
+	HomeTeam   string    `json:"home_team"`
+	AwayTeam   string    `json:"away_team"`
+	Team       string    `json:"team"`
+	Player     string    `json:"player"`
+	Position   string    `json:"position"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// Status is the latest lineup state for a game
+type Status struct {
+	GameID    string     `json:"game_id"`
+	Confirmed bool       `json:"confirmed"`
+	Home      TeamLineup `json:"home"`
+	Away      TeamLineup `json:"away"`
+	Changes   []Change   `json:"changes,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// gameState tracks a game's lineups across checks
+type gameState struct {
+	status    Status
+	projected map[string]map[string]Starter // team -> name -> starter
+	compared  map[string]bool               // teams already diffed
+}
+
+// Monitor polls NBA starting lineups near tip-off and reports changes from
+// the projected lineups
+type Monitor struct {
+	config      Config
+	sportsData  *sportsdata.Client
+	oddsService *service.OddsService
+	clock       clock.Clock
+
+	mu       sync.RWMutex
+	games    map[string]*gameState
+	callback func([]Change)
+}
+
+// NewMonitor creates a new lineup monitor
+func NewMonitor(config Config, sportsData *sportsdata.Client, oddsService *service.OddsService) *Monitor {
+	return &Monitor{
+		config:      config,
+		sportsData:  sportsData,
+		oddsService: oddsService,
+		clock:       clock.Real{},
+		games:       make(map[string]*gameState),
+	}
+}
+
+// SetClock sets the clock used for the tip-off window
+func (m *Monitor) SetClock(c clock.Clock) {
+	m.clock = c
+}
+
+// SetCallback sets the function called with changes as lineups are confirmed
+func (m *Monitor) SetCallback(fn func([]Change)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callback = fn
+}
+
+// Start checks lineups on every interval until the context is cancelled
+func (m *Monitor) Start(ctx context.Context) {
+	if m.config.Interval <= 0 {
+		m.config.Interval = DefaultConfig().Interval
+	}
+
+	log.Printf("Lineup monitor starting (interval: %v, window: %v)", m.config.Interval, m.config.Window)
+
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// Check fetches lineups for NBA games starting within the window
+func (m *Monitor) Check() {
+	now := m.clock.Now()
+
+	eastern, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		eastern = time.UTC
+	}
+
+	// Group upcoming games by Eastern date, which is how lineups are listed
+	byDate := make(map[string][]models.Game)
+	dates := make(map[string]time.Time)
+	for _, game := range m.oddsService.GetGamesBySport(models.SportNBA) {
+		if game.CommenceTime.Before(now) || game.CommenceTime.After(now.Add(m.config.Window)) {
+			continue
+		}
+		local := game.CommenceTime.In(eastern)
+		key := local.Format("2006-01-02")
+		byDate[key] = append(byDate[key], game)
+		dates[key] = local
+	}
+
+	var changes []Change
+	for key, games := range byDate {
+		lineups, err := m.sportsData.GetNBAStartingLineups(dates[key])
+		if err != nil {
+			log.Printf("Lineups: %v", err)
+			continue
+		}
+		for _, game := range games {
+			if l := findLineups(lineups, game); l != nil {
+				changes = append(changes, m.update(game, l, now)...)
+			}
+		}
+	}
+
+	m.prune(now)
+
+	m.mu.RLock()
+	callback := m.callback
+	m.mu.RUnlock()
+	if len(changes) > 0 {
+		log.Printf("Lineups: %d changes from projected lineups", len(changes))
+		if callback != nil {
+			callback(changes)
+		}
+	}
+}
+
+// findLineups matches an Odds API game to its SportsDataIO lineups
+func findLineups(lineups []sportsdata.StartingLineups, game models.Game) *sportsdata.StartingLineups {
+	home, ok := reference.Abbreviation(game.HomeTeam)
+	if !ok {
+		return nil
+	}
+	away, ok := reference.Abbreviation(game.AwayTeam)
+	if !ok {
+		return nil
+	}
+	for i := range lineups {
+		if lineups[i].HomeTeam == home && lineups[i].AwayTeam == away {
+			return &lineups[i]
+		}
+	}
+	return nil
+}
+
+// update records a game's lineups. Projected starters are remembered, and
+// each team's confirmed lineup is diffed against them once.
+func (m *Monitor) update(game models.Game, l *sportsdata.StartingLineups, now time.Time) []Change {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.games[game.ID]
+	if !ok {
+		state = &gameState{
+			projected: make(map[string]map[string]Starter),
+			compared:  make(map[string]bool),
+		}
+		m.games[game.ID] = state
+	}
+
+	home := teamLineup(game.HomeTeam, l.HomeLineup)
+	away := teamLineup(game.AwayTeam, l.AwayLineup)
+
+	var changes []Change
+	for _, team := range []TeamLineup{home, away} {
+		if !team.Confirmed {
+			state.projected[team.Team] = starterSet(team.Starters)
+			continue
+		}
+		projected, hasProjection := state.projected[team.Team]
+		if !hasProjection || state.compared[team.Team] {
+			continue
+		}
+		state.compared[team.Team] = true
+
+		confirmed := starterSet(team.Starters)
+		change := func(kind string, s Starter) Change {
+			return Change{
+				Kind:       kind,
+				GameID:     game.ID,
+				HomeTeam:   game.HomeTeam,
+				AwayTeam:   game.AwayTeam,
+				Team:       team.Team,
+				Player:     s.Name,
+				Position:   s.Position,
+				DetectedAt: now,
+			}
+		}
+		for name, s := range projected {
+			if _, ok := confirmed[name]; !ok {
+				changes = append(changes, change(StarterOut, s))
+			}
+		}
+		for name, s := range confirmed {
+			if _, ok := projected[name]; !ok {
+				changes = append(changes, change(SurpriseStart, s))
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].Player < changes[j].Player
+	})
+
+	state.status = Status{
+		GameID:    game.ID,
+		Confirmed: home.Confirmed && away.Confirmed,
+		Home:      home,
+		Away:      away,
+		Changes:   append(state.status.Changes, changes...),
+		UpdatedAt: now,
+	}
+	return changes
+}
+
+// prune drops games that tipped off more than a day ago
+func (m *Monitor) prune(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id := range m.games {
+		game, found := m.oddsService.GetGame(id)
+		if !found || game.CommenceTime.Before(now.Add(-24*time.Hour)) {
+			delete(m.games, id)
+		}
+	}
+}
+
+// Status returns the latest lineup state for a game, or nil if lineups
+// haven't been checked
+func (m *Monitor) Status(gameID string) *Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state, ok := m.games[gameID]
+	if !ok {
+		return nil
+	}
+	status := state.status
+	return &status
+}
+
+// teamLineup extracts a team's starters. The lineup counts as confirmed once
+// every listed starter is confirmed.
+func teamLineup(team string, players []sportsdata.LineupPlayer) TeamLineup {
+	lineup := TeamLineup{Team: team}
+	confirmed := true
+	for _, p := range players {
+		if !p.Starting {
+			continue
+		}
+		lineup.Starters = append(lineup.Starters, Starter{Name: p.Name, Position: p.Position})
+		if !p.Confirmed {
+			confirmed = false
+		}
+	}
+	lineup.Confirmed = confirmed && len(lineup.Starters) > 0
+	return lineup
+}
+
+func starterSet(starters []Starter) map[string]Starter {
+	set := make(map[string]Starter, len(starters))
+	for _, s := range starters {
+		set[s.Name] = s
+	}
+	return set
+}
@@ -0,0 +1,468 @@
+// Package liveprops tracks player props during live NBA games, comparing
+// each player's box score so far, and the pace it's on, with the prop's
+// pre-game line.
+package liveprops
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/reference"
+	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/sportsdata"
+	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
+)
+
+// Where a prop stands against its line
+const (
+	// PaceHit is a stat already over the line
+	PaceHit = "hit"
+	// PaceOver is a stat on pace to finish over the line
+	PaceOver = "over"
+	// PaceUnder is a stat on pace to finish under the line
+	PaceUnder = "under"
+)
+
+// Regulation NBA game length
+const (
+	quarterMinutes    = 12.0
+	regulationMinutes = 4 * quarterMinutes
+)
+
+// Config holds live prop tracking configuration
+type Config struct {
+	// Interval is the time between box score lookups while NBA games are
+	// live. Each lookup costs one request per game date.
+	Interval time.Duration
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Interval: time.Minute,
+	}
+}
+
+// PropPace is a player's stat so far against a prop's pre-game line
+type PropPace struct {
+	Player   string  `json:"player"`
+	Team     string  `json:"team"`
+	Category string  `json:"category"`
+	Line     float64 `json:"line"`    // median line across bookmakers before tip-off
+	Current  float64 `json:"current"` // stat so far
+	// Projected is the stat at its current pace over regulation
+	Projected float64 `json:"projected"`
+	Pace      string  `json:"pace"` // hit, over or under
+	Minutes   int     `json:"minutes"`
+}
+
+// GameProps is a live game's score, clock and prop paces
+type GameProps struct {
+	GameID    string `json:"game_id"`
+	HomeTeam  string `json:"home_team"`
+	AwayTeam  string `json:"away_team"`
+	HomeScore int    `json:"home_score"`
+	AwayScore int    `json:"away_score"`
+
+	// Period is the quarter ("1" to "4"), "Half" or overtime ("OT", "2OT")
+	Period string `json:"period"`
+	Clock  string `json:"clock,omitempty"` // time left in the period, e.g. "5:32"
+	// Elapsed is the share of regulation played, from 0 to 1
+	Elapsed float64 `json:"elapsed"`
+	Final   bool    `json:"final"`
+
+	Props     []PropPace `json:"props"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// line is a prop's pre-game line
+type line struct {
+	player   string
+	team     string
+	category string
+	value    float64
+}
+
+// gameState tracks a game's lines and latest box score
+type gameState struct {
+	lines []line
+	live  *GameProps
+}
+
+// Tracker looks up box scores for live NBA games and works out each prop's
+// pace against its pre-game line
+type Tracker struct {
+	config      Config
+	sportsData  *sportsdata.Client
+	oddsService *service.OddsService
+	clock       clock.Clock
+
+	mu       sync.RWMutex
+	games    map[string]*gameState
+	callback func(GameProps)
+}
+
+// NewTracker creates a new live prop tracker
+func NewTracker(config Config, sportsData *sportsdata.Client, oddsService *service.OddsService) *Tracker {
+	return &Tracker{
+		config:      config,
+		sportsData:  sportsData,
+		oddsService: oddsService,
+		clock:       clock.Real{},
+		games:       make(map[string]*gameState),
+	}
+}
+
+// SetClock sets the clock used to decide which games are live
+func (t *Tracker) SetClock(c clock.Clock) {
+	t.clock = c
+}
+
+// SetCallback sets the function called with each live game's update
+func (t *Tracker) SetCallback(fn func(GameProps)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.callback = fn
+}
+
+// Start checks live games on every interval until the context is cancelled
+func (t *Tracker) Start(ctx context.Context) {
+	if t.config.Interval <= 0 {
+		t.config.Interval = DefaultConfig().Interval
+	}
+
+	log.Printf("Live prop tracker starting (interval: %v)", t.config.Interval)
+
+	ticker := time.NewTicker(t.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.Check()
+		}
+	}
+}
+
+// Check notes the lines of NBA games yet to start, then fetches box scores
+// for games underway and updates their prop paces. Nothing is fetched
+// while no game is live.
+func (t *Tracker) Check() {
+	now := t.clock.Now()
+
+	eastern, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		eastern = time.UTC
+	}
+
+	// Group live games by Eastern date, which is how box scores are listed
+	byDate := make(map[string][]models.Game)
+	dates := make(map[string]time.Time)
+	for _, game := range t.oddsService.GetGamesBySport(models.SportNBA) {
+		if !game.Started(now) {
+			t.noteLines(game)
+			continue
+		}
+		if game.Status == models.GameFinal {
+			continue
+		}
+		local := game.CommenceTime.In(eastern)
+		key := local.Format("2006-01-02")
+		byDate[key] = append(byDate[key], game)
+		dates[key] = local
+	}
+
+	var updates []GameProps
+	for key, games := range byDate {
+		boxScores, err := t.sportsData.GetNBABoxScores(dates[key])
+		if err != nil {
+			log.Printf("Live props: %v", err)
+			continue
+		}
+		for _, game := range games {
+			if box := findBoxScore(boxScores, game); box != nil {
+				updates = append(updates, t.update(game, box, now))
+			}
+		}
+	}
+
+	t.mu.Lock()
+	t.prune()
+	callback := t.callback
+	t.mu.Unlock()
+	if callback != nil {
+		for _, u := range updates {
+			callback(u)
+		}
+	}
+}
+
+// findBoxScore matches an Odds API game to its SportsDataIO box score
+func findBoxScore(boxScores []sportsdata.BoxScore, game models.Game) *sportsdata.BoxScore {
+	home, ok := reference.Abbreviation(game.HomeTeam)
+	if !ok {
+		return nil
+	}
+	away, ok := reference.Abbreviation(game.AwayTeam)
+	if !ok {
+		return nil
+	}
+	for i := range boxScores {
+		if boxScores[i].Game.HomeTeam == home && boxScores[i].Game.AwayTeam == away {
+			return &boxScores[i]
+		}
+	}
+	return nil
+}
+
+// noteLines records an upcoming game's current lines, which are its
+// pre-game lines once it starts
+func (t *Tracker) noteLines(game models.Game) {
+	lines := pregameLines(game)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.games[game.ID]
+	if !ok {
+		state = &gameState{}
+		t.games[game.ID] = state
+	}
+	state.lines = lines
+}
+
+// update records a game's box score. A game that started before the
+// tracker saw it takes the lines its props have then.
+func (t *Tracker) update(game models.Game, box *sportsdata.BoxScore, now time.Time) GameProps {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.games[game.ID]
+	if !ok {
+		state = &gameState{}
+		t.games[game.ID] = state
+	}
+	if state.lines == nil {
+		state.lines = pregameLines(game)
+	}
+
+	stats := make(map[string]sportsdata.PlayerGameStats, len(box.PlayerGames))
+	for _, s := range box.PlayerGames {
+		stats[strings.ToLower(s.Name)] = s
+	}
+
+	elapsed, final := elapsedMinutes(box.Game)
+	live := GameProps{
+		GameID:    game.ID,
+		HomeTeam:  game.HomeTeam,
+		AwayTeam:  game.AwayTeam,
+		HomeScore: intValue(box.Game.HomeTeamScore),
+		AwayScore: intValue(box.Game.AwayTeamScore),
+		Period:    stringValue(box.Game.Quarter),
+		Clock:     gameClock(box.Game),
+		Elapsed:   round(math.Min(elapsed/regulationMinutes, 1), 3),
+		Final:     final,
+		Props:     []PropPace{},
+		UpdatedAt: now,
+	}
+	for _, l := range state.lines {
+		s, ok := stats[strings.ToLower(l.player)]
+		if !ok {
+			continue
+		}
+		current, ok := statValue(l.category, s)
+		if !ok {
+			continue
+		}
+		projected := current
+		if !final && elapsed > 0 && elapsed < regulationMinutes {
+			projected = current * regulationMinutes / elapsed
+		}
+		pace := PaceUnder
+		switch {
+		case current > l.value:
+			pace = PaceHit
+		case projected > l.value:
+			pace = PaceOver
+		}
+		live.Props = append(live.Props, PropPace{
+			Player:    l.player,
+			Team:      l.team,
+			Category:  l.category,
+			Line:      l.value,
+			Current:   current,
+			Projected: round(projected, 1),
+			Pace:      pace,
+			Minutes:   s.Minutes,
+		})
+	}
+
+	state.live = &live
+	return live
+}
+
+// Mirror records an update made by another instance, so every instance
+// can answer for live games
+func (t *Tracker) Mirror(live GameProps) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.games[live.GameID]
+	if !ok {
+		state = &gameState{}
+		t.games[live.GameID] = state
+	}
+	state.live = &live
+	t.prune()
+}
+
+// prune drops games that have left the store, which final games do
+// shortly after they end. The caller holds t.mu.
+func (t *Tracker) prune() {
+	for id := range t.games {
+		if _, found := t.oddsService.GetGame(id); !found {
+			delete(t.games, id)
+		}
+	}
+}
+
+// Game returns a live game's latest prop paces, or nil if it has no box
+// score yet
+func (t *Tracker) Game(gameID string) *GameProps {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	state, ok := t.games[gameID]
+	if !ok || state.live == nil {
+		return nil
+	}
+	live := *state.live
+	return &live
+}
+
+// pregameLines takes the median line of each of a game's props, in player
+// and category order
+func pregameLines(game models.Game) []line {
+	props := store.GetDummyPlayerProps(game.ID, models.SportNBA, game.HomeTeam, game.AwayTeam)
+
+	var lines []line
+	for _, player := range props.Players {
+		for _, prop := range player.Props {
+			category, ok := taxonomy.ForProp(prop.Category, prop.Market)
+			if !ok || len(prop.Bookmakers) == 0 {
+				continue
+			}
+			points := make([]float64, len(prop.Bookmakers))
+			for i, bm := range prop.Bookmakers {
+				points[i] = bm.Point
+			}
+			sort.Float64s(points)
+			lines = append(lines, line{
+				player:   player.Name,
+				team:     player.Team,
+				category: category,
+				value:    median(points),
+			})
+		}
+	}
+	return lines
+}
+
+// statValue is a box score's value for a canonical category
+func statValue(category string, s sportsdata.PlayerGameStats) (float64, bool) {
+	switch category {
+	case taxonomy.Points:
+		return s.Points, true
+	case taxonomy.Rebounds:
+		return s.Rebounds, true
+	case taxonomy.Assists:
+		return s.Assists, true
+	case taxonomy.Threes:
+		return s.ThreePointersMade, true
+	case taxonomy.Steals:
+		return s.Steals, true
+	case taxonomy.Blocks:
+		return s.BlockedShots, true
+	case taxonomy.Turnovers:
+		return s.Turnovers, true
+	case taxonomy.PRA:
+		return s.Points + s.Rebounds + s.Assists, true
+	case taxonomy.PR:
+		return s.Points + s.Rebounds, true
+	case taxonomy.PA:
+		return s.Points + s.Assists, true
+	case taxonomy.RA:
+		return s.Rebounds + s.Assists, true
+	}
+	return 0, false
+}
+
+// elapsedMinutes is how many minutes of game time have been played, and
+// whether the game is over. Overtime counts as regulation played.
+func elapsedMinutes(g sportsdata.LiveGame) (float64, bool) {
+	if g.IsClosed || strings.HasPrefix(g.Status, "F") {
+		return regulationMinutes, true
+	}
+	quarter := stringValue(g.Quarter)
+	switch {
+	case quarter == "":
+		return 0, false
+	case quarter == "Half":
+		return 2 * quarterMinutes, false
+	case strings.HasSuffix(quarter, "OT"):
+		return regulationMinutes, false
+	}
+	q, err := strconv.Atoi(quarter)
+	if err != nil || q < 1 {
+		return 0, false
+	}
+	left := float64(intValue(g.TimeRemainingMinutes)) + float64(intValue(g.TimeRemainingSeconds))/60
+	return float64(q-1)*quarterMinutes + math.Max(0, quarterMinutes-left), false
+}
+
+// gameClock formats the time left in the period, or "" between periods
+func gameClock(g sportsdata.LiveGame) string {
+	if g.TimeRemainingMinutes == nil || g.TimeRemainingSeconds == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d:%02d", *g.TimeRemainingMinutes, *g.TimeRemainingSeconds)
+}
+
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func round(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
+
+func intValue(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func stringValue(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Counter scopes, for resetting one group of counters at a time
+const (
+	ScopeWebSocket = "websocket"
+	ScopePolling   = "polling"
+	ScopeAPI       = "api"
+)
+
+// Scopes lists the counter scopes
+var Scopes = []string{ScopeWebSocket, ScopePolling, ScopeAPI}
+
+// Counters is a point-in-time copy of the cumulative counters, each scope
+// counting since the process started or it was last reset
+type Counters struct {
+	At        time.Time         `json:"at"`
+	Instance  string            `json:"instance,omitempty"`
+	Polling   PollingCounters   `json:"polling"`
+	WebSocket WebSocketCounters `json:"websocket"`
+	API       APICounters       `json:"api"`
+}
+
+// PollingCounters counts polls and the changes they found
+type PollingCounters struct {
+	Since           time.Time `json:"since"`
+	TotalPolls      int64     `json:"total_polls"`
+	SuccessfulPolls int64     `json:"successful_polls"`
+	FailedPolls     int64     `json:"failed_polls"`
+	ChangesDetected int64     `json:"changes_detected"`
+}
+
+// WebSocketCounters counts connections and what was sent over them
+type WebSocketCounters struct {
+	Since            time.Time `json:"since"`
+	TotalConnections int64     `json:"total_connections"`
+	PeakConnections  int64     `json:"peak_connections"`
+	MessagesSent     int64     `json:"messages_sent"`
+	MessagesFailed   int64     `json:"messages_failed"`
+	BytesSent        int64     `json:"bytes_sent"`
+	BroadcastCount   int64     `json:"broadcast_count"`
+}
+
+// APICounters counts Odds API requests. Requests counted towards the
+// quota aren't reset with them.
+type APICounters struct {
+	Since         time.Time `json:"since"`
+	RequestsTotal int64     `json:"requests_total"`
+}
+
+// ParseScopes checks a list of counter scopes. An empty list means all of
+// them.
+func ParseScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return Scopes, nil
+	}
+	var result []string
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		known := false
+		for _, valid := range Scopes {
+			known = known || scope == valid
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown scope %q: use %s", scope, strings.Join(Scopes, ", "))
+		}
+		result = append(result, scope)
+	}
+	return result, nil
+}
+
+// Counters returns the cumulative counters as they stand
+func (m *Metrics) Counters() Counters {
+	m.mu.RLock()
+	since := func(scope string) time.Time {
+		if t, ok := m.resets[scope]; ok {
+			return t
+		}
+		return m.StartTime
+	}
+	c := Counters{
+		At:       m.clock.Now(),
+		Instance: m.instance,
+		Polling: PollingCounters{
+			Since:           since(ScopePolling),
+			TotalPolls:      m.PollCount.Load(),
+			SuccessfulPolls: m.PollSuccessCount.Load(),
+			FailedPolls:     m.PollErrorCount.Load(),
+			ChangesDetected: m.ChangesDetected.Load(),
+		},
+		WebSocket: WebSocketCounters{
+			Since:            since(ScopeWebSocket),
+			TotalConnections: m.ConnectionsTotal.Load(),
+			PeakConnections:  m.ConnectionsPeak.Load(),
+			MessagesSent:     m.MessagesOut.Load(),
+			MessagesFailed:   m.MessagesFailed.Load(),
+			BytesSent:        m.BytesOut.Load(),
+			BroadcastCount:   m.BroadcastCount.Load(),
+		},
+		API: APICounters{
+			Since:         since(ScopeAPI),
+			RequestsTotal: m.APIRequestsTotal.Load(),
+		},
+	}
+	m.mu.RUnlock()
+	return c
+}
+
+// Reset zeroes the counters in the given scopes, all of them when none are
+// given. Gauges such as current connections and the quota are left alone;
+// peak connections restarts from the current count.
+func (m *Metrics) Reset(scopes ...string) error {
+	scopes, err := ParseScopes(scopes)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.resets == nil {
+		m.resets = make(map[string]time.Time)
+	}
+	now := m.clock.Now()
+	for _, scope := range scopes {
+		switch scope {
+		case ScopePolling:
+			m.PollCount.Store(0)
+			m.PollSuccessCount.Store(0)
+			m.PollErrorCount.Store(0)
+			m.ChangesDetected.Store(0)
+			for _, sm := range m.sportMetrics {
+				sm.PollCount = 0
+				sm.ChangeCount = 0
+			}
+		case ScopeWebSocket:
+			m.ConnectionsTotal.Store(0)
+			m.ConnectionsPeak.Store(m.ConnectionsCurrent.Load())
+			m.MessagesOut.Store(0)
+			m.MessagesFailed.Store(0)
+			m.BytesOut.Store(0)
+			m.BroadcastCount.Store(0)
+		case ScopeAPI:
+			m.APIRequestsTotal.Store(0)
+		}
+		m.resets[scope] = now
+	}
+	return nil
+}
+
+// Delta is how much the counters grew between two copies. A scope reset
+// or restarted in between counts from then, so Since is when it starts.
+func (c Counters) Delta(earlier Counters) Counters {
+	d := c
+	if c.Polling.Since.Equal(earlier.Polling.Since) {
+		d.Polling = PollingCounters{
+			Since:           earlier.At,
+			TotalPolls:      c.Polling.TotalPolls - earlier.Polling.TotalPolls,
+			SuccessfulPolls: c.Polling.SuccessfulPolls - earlier.Polling.SuccessfulPolls,
+			FailedPolls:     c.Polling.FailedPolls - earlier.Polling.FailedPolls,
+			ChangesDetected: c.Polling.ChangesDetected - earlier.Polling.ChangesDetected,
+		}
+	}
+	if c.WebSocket.Since.Equal(earlier.WebSocket.Since) {
+		d.WebSocket = WebSocketCounters{
+			Since:            earlier.At,
+			TotalConnections: c.WebSocket.TotalConnections - earlier.WebSocket.TotalConnections,
+			// A peak isn't cumulative: the window's is at most the latest
+			PeakConnections: c.WebSocket.PeakConnections,
+			MessagesSent:    c.WebSocket.MessagesSent - earlier.WebSocket.MessagesSent,
+			MessagesFailed:  c.WebSocket.MessagesFailed - earlier.WebSocket.MessagesFailed,
+			BytesSent:       c.WebSocket.BytesSent - earlier.WebSocket.BytesSent,
+			BroadcastCount:  c.WebSocket.BroadcastCount - earlier.WebSocket.BroadcastCount,
+		}
+	}
+	if c.API.Since.Equal(earlier.API.Since) {
+		d.API = APICounters{
+			Since:         earlier.At,
+			RequestsTotal: c.API.RequestsTotal - earlier.API.RequestsTotal,
+		}
+	}
+	return d
+}
@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// DefaultBookMissedPolls is how many consecutive polls an allowed bookmaker
+// can be missing from a sport before health warns about it
+const DefaultBookMissedPolls = 3
+
+// BookCoverage tracks how often a bookmaker appears in polls for one sport
+type BookCoverage struct {
+	Bookmaker   string           `json:"bookmaker"`
+	Sport       string           `json:"sport"`
+	Polls       int64            `json:"polls"`
+	Present     int64            `json:"present"`
+	CoveragePct float64          `json:"coverage_percent"`
+	MissedPolls int64            `json:"consecutive_missed_polls"`
+	LastSeen    time.Time        `json:"last_seen,omitempty"`
+	Markets     []MarketCoverage `json:"markets"`
+}
+
+// MarketCoverage is the share of polled games a bookmaker offered a market for
+type MarketCoverage struct {
+	Market      string  `json:"market"`
+	Games       int64   `json:"games"`
+	Offered     int64   `json:"offered"`
+	CoveragePct float64 `json:"coverage_percent"`
+}
+
+// bookCoverage accumulates coverage counts for a bookmaker and sport
+type bookCoverage struct {
+	polls       int64
+	present     int64
+	missedPolls int64
+	lastSeen    time.Time
+	games       map[models.Market]int64
+	offered     map[models.Market]int64
+}
+
+// polledMarkets are the markets requested from the Odds API
+var polledMarkets = []models.Market{models.MarketH2H, models.MarketSpreads, models.MarketTotals}
+
+// SetBookMissedPolls sets how many consecutive missed polls trigger a
+// coverage warning
+func (m *Metrics) SetBookMissedPolls(n int64) {
+	m.mu.Lock()
+	m.bookMissedPolls = n
+	m.mu.Unlock()
+}
+
+// RecordBookCoverage records which of the allowed bookmakers appeared in a
+// poll's games, and for which markets. Polls without games are ignored since
+// no book is expected to appear.
+func (m *Metrics) RecordBookCoverage(sport string, games []models.Game, books []string) {
+	if len(games) == 0 {
+		return
+	}
+	now := m.clock.Now()
+
+	offered := make(map[string]map[models.Market]int64)
+	for _, game := range games {
+		for _, bm := range game.Bookmakers {
+			if offered[bm.Key] == nil {
+				offered[bm.Key] = make(map[models.Market]int64)
+			}
+			for _, market := range bm.Markets {
+				offered[bm.Key][market.Key]++
+			}
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.bookCoverage[sport] == nil {
+		m.bookCoverage[sport] = make(map[string]*bookCoverage)
+	}
+	for _, book := range books {
+		c := m.bookCoverage[sport][book]
+		if c == nil {
+			c = &bookCoverage{
+				games:   make(map[models.Market]int64),
+				offered: make(map[models.Market]int64),
+			}
+			m.bookCoverage[sport][book] = c
+		}
+
+		c.polls++
+		if _, ok := offered[book]; ok {
+			c.present++
+			c.missedPolls = 0
+			c.lastSeen = now
+		} else {
+			c.missedPolls++
+		}
+		for _, market := range polledMarkets {
+			c.games[market] += int64(len(games))
+			c.offered[market] += offered[book][market]
+		}
+	}
+}
+
+// BookCoverage returns coverage for every tracked bookmaker and sport,
+// sorted by sport then bookmaker
+func (m *Metrics) BookCoverage() []BookCoverage {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []BookCoverage
+	for sport, books := range m.bookCoverage {
+		for book, c := range books {
+			bc := BookCoverage{
+				Bookmaker:   book,
+				Sport:       sport,
+				Polls:       c.polls,
+				Present:     c.present,
+				CoveragePct: percent(c.present, c.polls),
+				MissedPolls: c.missedPolls,
+				LastSeen:    c.lastSeen,
+			}
+			for _, market := range polledMarkets {
+				bc.Markets = append(bc.Markets, MarketCoverage{
+					Market:      string(market),
+					Games:       c.games[market],
+					Offered:     c.offered[market],
+					CoveragePct: percent(c.offered[market], c.games[market]),
+				})
+			}
+			result = append(result, bc)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Sport != result[j].Sport {
+			return result[i].Sport < result[j].Sport
+		}
+		return result[i].Bookmaker < result[j].Bookmaker
+	})
+	return result
+}
+
+// bookCoverageWarnings warns about allowed bookmakers missing from the last
+// several polls of a sport
+func (m *Metrics) bookCoverageWarnings() []string {
+	m.mu.RLock()
+	limit := m.bookMissedPolls
+	m.mu.RUnlock()
+	if limit <= 0 {
+		limit = DefaultBookMissedPolls
+	}
+
+	var warnings []string
+	for _, c := range m.BookCoverage() {
+		if c.MissedPolls >= limit {
+			warnings = append(warnings, fmt.Sprintf("Bookmaker %s missing from %s odds for %d consecutive polls",
+				c.Bookmaker, c.Sport, c.MissedPolls))
+		}
+	}
+	return warnings
+}
+
+// percent returns part as a percentage of total, rounded to one decimal
+func percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part*1000/total) / 10
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Dependency status values
+const (
+	DependencyOK       = "ok"
+	DependencyDegraded = "degraded"
+	DependencyDown     = "down"
+)
+
+// DependencyStatus is the latest availability check of an upstream service
+type DependencyStatus struct {
+	Name                string    `json:"name"`
+	Status              string    `json:"status"`
+	LastCheck           time.Time `json:"last_check"`
+	LatencyMs           int64     `json:"latency_ms"`
+	LastError           string    `json:"last_error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastOK              time.Time `json:"last_ok,omitempty"`
+}
+
+// RecordDependencyCheck stores the latest check result for a dependency
+func (m *Metrics) RecordDependencyCheck(status DependencyStatus) {
+	m.mu.Lock()
+	m.dependencies[status.Name] = status
+	m.mu.Unlock()
+}
+
+// GetDependency returns the latest check result for a dependency
+func (m *Metrics) GetDependency(name string) (DependencyStatus, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	status, ok := m.dependencies[name]
+	return status, ok
+}
+
+// dependencyHealth returns a snapshot of dependency statuses and warnings
+// for any that aren't ok
+func (m *Metrics) dependencyHealth() (map[string]DependencyStatus, []string, bool) {
+	m.mu.RLock()
+	snapshot := make(map[string]DependencyStatus, len(m.dependencies))
+	names := make([]string, 0, len(m.dependencies))
+	for name, status := range m.dependencies {
+		snapshot[name] = status
+		names = append(names, name)
+	}
+	m.mu.RUnlock()
+	sort.Strings(names)
+
+	var warnings []string
+	degraded := false
+	for _, name := range names {
+		status := snapshot[name]
+		switch status.Status {
+		case DependencyDown:
+			degraded = true
+			warnings = append(warnings, fmt.Sprintf("Upstream %s is down: %s", name, status.LastError))
+		case DependencyDegraded:
+			degraded = true
+			if status.LastError != "" {
+				warnings = append(warnings, fmt.Sprintf("Upstream %s is degraded: %s", name, status.LastError))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("Upstream %s is slow (%dms)", name, status.LatencyMs))
+			}
+		}
+	}
+
+	return snapshot, warnings, degraded
+}
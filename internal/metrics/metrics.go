@@ -5,6 +5,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
 )
 
 // Metrics tracks system health and performance metrics
@@ -36,11 +38,28 @@ type Metrics struct {
 	APIRequestsTotal   atomic.Int64 // Total requests ever
 	APIQuotaLimit      int64        // Daily quota limit
 	APIQuotaResetTime  atomic.Value // time.Time when quota resets
+	APIRequestsRemaining atomic.Int64 // Remaining as last reported by the Odds API
+	APILastRequestCost   atomic.Int64 // What the last request cost, as reported
+	apiUsageReported     atomic.Bool  // The Odds API has reported usage since the reset
+	quotaResetDay        atomic.Int64 // Day of the month the quota resets, or 0 for daily
+	expectedPollInterval atomic.Int64 // time.Duration between polls, negative while paused
+
+	// Alert scan coverage
+	PropsScanned       atomic.Int64 // Props seen by alert scans
+	PropsSkipped       atomic.Int64 // Props skipped for missing averages or categories
+	AlertsThrottled    atomic.Int64 // Value alerts held back by scan throttling
 
 	// System health
 	StartTime          time.Time
+	instance           string // set before serving, then only read
+	clock              clock.Clock
 	mu                 sync.RWMutex
 	sportMetrics       map[string]*SportMetrics
+	alertScans         map[string]AlertScanStats
+	dependencies       map[string]DependencyStatus
+	bookCoverage       map[string]map[string]*bookCoverage // sport -> bookmaker
+	bookMissedPolls    int64
+	resets             map[string]time.Time // scope -> when its counters were last reset
 }
 
 // SportMetrics tracks per-sport metrics
@@ -58,7 +77,11 @@ type SportMetrics struct {
 func New() *Metrics {
 	m := &Metrics{
 		StartTime:    time.Now(),
+		clock:        clock.Real{},
 		sportMetrics: make(map[string]*SportMetrics),
+		alertScans:   make(map[string]AlertScanStats),
+		dependencies: make(map[string]DependencyStatus),
+		bookCoverage: make(map[string]map[string]*bookCoverage),
 	}
 	m.LastPollTime.Store(time.Time{})
 	m.LastChangeTime.Store(time.Time{})
@@ -67,29 +90,45 @@ func New() *Metrics {
 	return m
 }
 
+// SetClock sets the clock used for poll and change timestamps and uptime,
+// restarting the start time on it. Call before recording anything.
+func (m *Metrics) SetClock(c clock.Clock) {
+	m.clock = c
+	m.StartTime = c.Now()
+}
+
+// SetInstance names the instance in health reports, so responses behind a
+// load balancer show which instance answered
+func (m *Metrics) SetInstance(id string) {
+	m.instance = id
+}
+
 // RecordPollStart records the start of a poll
 func (m *Metrics) RecordPollStart() time.Time {
-	return time.Now()
+	return m.clock.Now()
 }
 
 // RecordPollSuccess records a successful poll
 func (m *Metrics) RecordPollSuccess(start time.Time, sport string, gamesCount int) {
-	duration := time.Since(start)
+	duration := m.clock.Now().Sub(start)
 
 	m.PollCount.Add(1)
 	m.PollSuccessCount.Add(1)
-	m.LastPollTime.Store(time.Now())
+	m.LastPollTime.Store(m.clock.Now())
 	m.LastPollDuration.Store(duration.Milliseconds())
 	m.ConsecutiveErrors.Store(0)
 	m.LastPollError.Store("")
-	m.APIRequestsToday.Add(1)
+	// Usage reported by the Odds API already includes this request
+	if !m.apiUsageReported.Load() {
+		m.APIRequestsToday.Add(1)
+	}
 	m.APIRequestsTotal.Add(1)
 
 	m.mu.Lock()
 	if m.sportMetrics[sport] == nil {
 		m.sportMetrics[sport] = &SportMetrics{Sport: sport}
 	}
-	m.sportMetrics[sport].LastPollTime = time.Now()
+	m.sportMetrics[sport].LastPollTime = m.clock.Now()
 	m.sportMetrics[sport].GamesTracked = gamesCount
 	m.sportMetrics[sport].PollCount++
 	m.mu.Unlock()
@@ -99,8 +138,8 @@ func (m *Metrics) RecordPollSuccess(start time.Time, sport string, gamesCount in
 func (m *Metrics) RecordPollError(start time.Time, err error) {
 	m.PollCount.Add(1)
 	m.PollErrorCount.Add(1)
-	m.LastPollTime.Store(time.Now())
-	m.LastPollDuration.Store(time.Since(start).Milliseconds())
+	m.LastPollTime.Store(m.clock.Now())
+	m.LastPollDuration.Store(m.clock.Now().Sub(start).Milliseconds())
 	m.ConsecutiveErrors.Add(1)
 	m.LastPollError.Store(err.Error())
 }
@@ -108,11 +147,11 @@ func (m *Metrics) RecordPollError(start time.Time, err error) {
 // RecordChange records when odds changes are detected
 func (m *Metrics) RecordChange(sport string) {
 	m.ChangesDetected.Add(1)
-	m.LastChangeTime.Store(time.Now())
+	m.LastChangeTime.Store(m.clock.Now())
 
 	m.mu.Lock()
 	if m.sportMetrics[sport] != nil {
-		m.sportMetrics[sport].LastChangeTime = time.Now()
+		m.sportMetrics[sport].LastChangeTime = m.clock.Now()
 		m.sportMetrics[sport].ChangeCount++
 	}
 	m.mu.Unlock()
@@ -165,18 +204,21 @@ func (m *Metrics) UpdateSubscriberCount(sport string, count int64) {
 // ResetDailyQuota resets daily API quota counter
 func (m *Metrics) ResetDailyQuota() {
 	m.APIRequestsToday.Store(0)
-	m.APIQuotaResetTime.Store(time.Now().Add(24 * time.Hour))
+	m.APIQuotaResetTime.Store(m.clock.Now().Add(24 * time.Hour))
 }
 
 // HealthStatus represents the system health
 type HealthStatus struct {
 	Status             string                   `json:"status"` // "healthy", "degraded", "unhealthy"
+	Instance           string                   `json:"instance,omitempty"`
 	Uptime             string                   `json:"uptime"`
 	UptimeSeconds      int64                    `json:"uptime_seconds"`
 	Polling            PollingHealth            `json:"polling"`
 	WebSocket          WebSocketHealth          `json:"websocket"`
 	API                APIHealth                `json:"api"`
 	Sports             map[string]*SportMetrics `json:"sports"`
+	AlertScan          AlertScanHealth          `json:"alert_scan"`
+	Dependencies       map[string]DependencyStatus `json:"dependencies,omitempty"`
 	Warnings           []string                 `json:"warnings,omitempty"`
 }
 
@@ -214,11 +256,12 @@ type APIHealth struct {
 	QuotaRemaining int64     `json:"quota_remaining"`
 	QuotaUsedPct   float64   `json:"quota_used_percent"`
 	QuotaResetTime time.Time `json:"quota_reset_time"`
+	QuotaSource    string    `json:"quota_source"` // "reported" by the Odds API or "counted"
 }
 
 // GetHealth returns current health status
 func (m *Metrics) GetHealth(pollingEnabled bool) HealthStatus {
-	uptime := time.Since(m.StartTime)
+	uptime := m.clock.Now().Sub(m.StartTime)
 
 	totalPolls := m.PollCount.Load()
 	successPolls := m.PollSuccessCount.Load()
@@ -239,17 +282,12 @@ func (m *Metrics) GetHealth(pollingEnabled bool) HealthStatus {
 	lastPollTime := m.LastPollTime.Load().(time.Time)
 	lastChangeTime := m.LastChangeTime.Load().(time.Time)
 	lastPollError := m.LastPollError.Load().(string)
+	requestsToday, quotaRemaining, quotaLimit, quotaSource := m.quotaUsage()
 	quotaResetTime := m.APIQuotaResetTime.Load().(time.Time)
 
-	requestsToday := m.APIRequestsToday.Load()
-	quotaRemaining := m.APIQuotaLimit - requestsToday
-	if quotaRemaining < 0 {
-		quotaRemaining = 0
-	}
-
 	var quotaUsedPct float64
-	if m.APIQuotaLimit > 0 {
-		quotaUsedPct = float64(requestsToday) / float64(m.APIQuotaLimit) * 100
+	if quotaLimit > 0 {
+		quotaUsedPct = float64(requestsToday) / float64(quotaLimit) * 100
 	}
 
 	// Determine overall health status
@@ -265,9 +303,9 @@ func (m *Metrics) GetHealth(pollingEnabled bool) HealthStatus {
 		warnings = append(warnings, "Multiple consecutive poll errors")
 	}
 
-	if pollingEnabled && !lastPollTime.IsZero() && time.Since(lastPollTime) > 5*time.Minute {
+	if pollingEnabled && m.pollStale(lastPollTime) {
 		status = "degraded"
-		warnings = append(warnings, "Polling appears stale (>5 min since last poll)")
+		warnings = append(warnings, "Polling appears stale (>5 min overdue)")
 	}
 
 	if quotaUsedPct > 90 {
@@ -281,6 +319,21 @@ func (m *Metrics) GetHealth(pollingEnabled bool) HealthStatus {
 		warnings = append(warnings, "Message delivery rate below 95%")
 	}
 
+	alertScan, scanWarnings, scanDegraded := m.alertScanHealth()
+	warnings = append(warnings, scanWarnings...)
+	if scanDegraded && status == "healthy" {
+		status = "degraded"
+	}
+
+	// Missing books shrink comparisons but don't affect availability
+	warnings = append(warnings, m.bookCoverageWarnings()...)
+
+	dependencies, depWarnings, depDegraded := m.dependencyHealth()
+	warnings = append(warnings, depWarnings...)
+	if depDegraded && status == "healthy" {
+		status = "degraded"
+	}
+
 	// Build sport metrics snapshot
 	m.mu.RLock()
 	sports := make(map[string]*SportMetrics)
@@ -292,14 +345,15 @@ func (m *Metrics) GetHealth(pollingEnabled bool) HealthStatus {
 
 	var lastPollAgo, lastChangeAgo string
 	if !lastPollTime.IsZero() {
-		lastPollAgo = time.Since(lastPollTime).Round(time.Second).String()
+		lastPollAgo = m.clock.Now().Sub(lastPollTime).Round(time.Second).String()
 	}
 	if !lastChangeTime.IsZero() {
-		lastChangeAgo = time.Since(lastChangeTime).Round(time.Second).String()
+		lastChangeAgo = m.clock.Now().Sub(lastChangeTime).Round(time.Second).String()
 	}
 
 	return HealthStatus{
 		Status:        status,
+		Instance:      m.instance,
 		Uptime:        uptime.Round(time.Second).String(),
 		UptimeSeconds: int64(uptime.Seconds()),
 		Polling: PollingHealth{
@@ -330,13 +384,16 @@ func (m *Metrics) GetHealth(pollingEnabled bool) HealthStatus {
 		API: APIHealth{
 			RequestsToday:  requestsToday,
 			RequestsTotal:  m.APIRequestsTotal.Load(),
-			QuotaLimit:     m.APIQuotaLimit,
+			QuotaLimit:     quotaLimit,
 			QuotaRemaining: quotaRemaining,
 			QuotaUsedPct:   quotaUsedPct,
 			QuotaResetTime: quotaResetTime,
+			QuotaSource:    quotaSource,
 		},
-		Sports:   sports,
-		Warnings: warnings,
+		Sports:    sports,
+		AlertScan: alertScan,
+		Dependencies: dependencies,
+		Warnings:  warnings,
 	}
 }
 
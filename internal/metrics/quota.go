@@ -0,0 +1,121 @@
+package metrics
+
+import "time"
+
+// QuotaSource values, for where the quota figures come from
+const (
+	QuotaReported = "reported" // the Odds API's usage headers
+	QuotaCounted  = "counted"  // successful polls since the reset
+)
+
+// RecordAPIUsage records the quota the Odds API reported on a response,
+// which replaces the self-counted requests until the next reset
+func (m *Metrics) RecordAPIUsage(remaining, used, lastCost int64) {
+	m.APIRequestsRemaining.Store(remaining)
+	m.APIRequestsToday.Store(used)
+	if lastCost > 0 {
+		m.APILastRequestCost.Store(lastCost)
+	}
+	m.apiUsageReported.Store(true)
+}
+
+// SetMonthlyQuotaReset makes the quota renew monthly on a day of the month
+// (UTC), as the Odds API's does, instead of every 24 hours
+func (m *Metrics) SetMonthlyQuotaReset(day int) {
+	m.quotaResetDay.Store(int64(day))
+	m.APIQuotaResetTime.Store(nextMonthlyReset(m.clock.Now(), day))
+}
+
+// Quota returns the requests left before the quota resets and when it
+// does. Remaining is as the Odds API last reported, or the limit less
+// requests counted since the reset. ok is false when there's no quota to go
+// by.
+func (m *Metrics) Quota() (remaining int64, reset time.Time, ok bool) {
+	_, remaining, limit, _ := m.quotaUsage()
+	return remaining, m.APIQuotaResetTime.Load().(time.Time), limit > 0
+}
+
+// RequestCost returns what a poll costs against the quota, as last reported
+func (m *Metrics) RequestCost() int64 {
+	if cost := m.APILastRequestCost.Load(); cost > 0 {
+		return cost
+	}
+	return 1
+}
+
+// SetExpectedPollInterval records how often polls are due, so health only
+// calls polling stale once it's well overdue. A negative interval means
+// polls are paused on purpose, such as overnight or with the quota
+// exhausted.
+func (m *Metrics) SetExpectedPollInterval(interval time.Duration) {
+	m.expectedPollInterval.Store(int64(interval))
+}
+
+// quotaUsage returns requests used and remaining, the quota they add up to
+// and where the figures come from
+func (m *Metrics) quotaUsage() (used, remaining, limit int64, source string) {
+	m.rolloverQuota()
+
+	used = m.APIRequestsToday.Load()
+	if m.apiUsageReported.Load() {
+		remaining = m.APIRequestsRemaining.Load()
+		return used, remaining, used + remaining, QuotaReported
+	}
+	remaining = m.APIQuotaLimit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return used, remaining, m.APIQuotaLimit, QuotaCounted
+}
+
+// rolloverQuota clears usage once the reset time passes. Until the Odds API
+// reports again, usage is counted from zero.
+func (m *Metrics) rolloverQuota() {
+	now := m.clock.Now()
+	reset := m.APIQuotaResetTime.Load().(time.Time)
+	if now.Before(reset) {
+		return
+	}
+
+	next := reset
+	if day := int(m.quotaResetDay.Load()); day > 0 {
+		next = nextMonthlyReset(now, day)
+	} else {
+		for !next.After(now) {
+			next = next.Add(24 * time.Hour)
+		}
+	}
+	if m.APIQuotaResetTime.CompareAndSwap(reset, next) {
+		m.APIRequestsToday.Store(0)
+		m.apiUsageReported.Store(false)
+	}
+}
+
+// pollStale reports whether the last poll is overdue by more than five
+// minutes
+func (m *Metrics) pollStale(lastPoll time.Time) bool {
+	if lastPoll.IsZero() {
+		return false
+	}
+	expected := time.Duration(m.expectedPollInterval.Load())
+	if expected < 0 {
+		return false
+	}
+	return m.clock.Now().Sub(lastPoll) > expected+5*time.Minute
+}
+
+// nextMonthlyReset returns the next midnight UTC on a day of the month after
+// a time, using the month's last day when it's shorter
+func nextMonthlyReset(after time.Time, day int) time.Time {
+	after = after.UTC()
+	for months := 0; ; months++ {
+		first := time.Date(after.Year(), after.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+		d := day
+		if last := first.AddDate(0, 1, -1).Day(); d > last {
+			d = last
+		}
+		if reset := first.AddDate(0, 0, d-1); reset.After(after) {
+			return reset
+		}
+	}
+}
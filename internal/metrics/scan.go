@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxScanSamples caps how many unmatched names are kept per scan
+const maxScanSamples = 10
+
+// AlertScanStats counts props seen by an alert scan and why any were skipped
+type AlertScanStats struct {
+	PropsScanned            int      `json:"props_scanned"`
+	PropsMatched            int      `json:"props_matched"`
+	SkippedMissingPlayer    int      `json:"skipped_missing_player"`
+	SkippedUnmappedCategory int      `json:"skipped_unmapped_category"`
+	SkippedMissingAverage   int      `json:"skipped_missing_average"`
+	SkippedExcludedBooks    int      `json:"skipped_excluded_books"` // offered only by excluded bookmakers
+	GamesOutsideWindow      int      `json:"games_outside_window"`   // starting after the scan window
+	MissingPlayers          []string `json:"missing_players,omitempty"`
+	UnmappedCategories      []string `json:"unmapped_categories,omitempty"`
+
+	// Value alerts the scan found and how many throttling held back, with
+	// the sport's throttle while it lasts
+	AlertsFound     int            `json:"alerts_found"`
+	AlertsThrottled int            `json:"alerts_throttled"`
+	Throttle        *AlertThrottle `json:"throttle,omitempty"`
+
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// AlertThrottle is a sport's raised thresholds after a scan found more
+// value alerts than the cap
+type AlertThrottle struct {
+	// MinEdge is the multiple of its threshold an alert needs to be
+	// delivered until then
+	MinEdge float64   `json:"min_edge"`
+	Until   time.Time `json:"until"`
+}
+
+// Skipped returns the total number of props skipped for missing data.
+// Props left out by the user's excluded bookmakers aren't counted.
+func (s AlertScanStats) Skipped() int {
+	return s.SkippedMissingPlayer + s.SkippedUnmappedCategory + s.SkippedMissingAverage
+}
+
+// AddMissingPlayer records a player with no averages
+func (s *AlertScanStats) AddMissingPlayer(name string) {
+	s.MissingPlayers = addSample(s.MissingPlayers, name)
+}
+
+// AddUnmappedCategory records a prop category outside the taxonomy
+func (s *AlertScanStats) AddUnmappedCategory(category string) {
+	s.UnmappedCategories = addSample(s.UnmappedCategories, category)
+}
+
+// addSample appends name if it's new and there's room
+func addSample(samples []string, name string) []string {
+	if len(samples) >= maxScanSamples {
+		return samples
+	}
+	for _, s := range samples {
+		if s == name {
+			return samples
+		}
+	}
+	return append(samples, name)
+}
+
+// RecordAlertScan stores the result of the latest alert scan for a sport
+func (m *Metrics) RecordAlertScan(sport string, stats AlertScanStats) {
+	stats.ScannedAt = m.clock.Now()
+
+	m.PropsScanned.Add(int64(stats.PropsScanned))
+	m.PropsSkipped.Add(int64(stats.Skipped()))
+	m.AlertsThrottled.Add(int64(stats.AlertsThrottled))
+
+	m.mu.Lock()
+	m.alertScans[sport] = stats
+	m.mu.Unlock()
+}
+
+// AlertScanHealth summarizes alert scan coverage
+type AlertScanHealth struct {
+	PropsScannedTotal    int64                     `json:"props_scanned_total"`
+	PropsSkippedTotal    int64                     `json:"props_skipped_total"`
+	AlertsThrottledTotal int64                     `json:"alerts_throttled_total"`
+	LastScans            map[string]AlertScanStats `json:"last_scans,omitempty"`
+}
+
+// alertScanHealth returns scan coverage and warnings for props the latest
+// scans couldn't evaluate
+func (m *Metrics) alertScanHealth() (AlertScanHealth, []string, bool) {
+	health := AlertScanHealth{
+		PropsScannedTotal:    m.PropsScanned.Load(),
+		PropsSkippedTotal:    m.PropsSkipped.Load(),
+		AlertsThrottledTotal: m.AlertsThrottled.Load(),
+		LastScans:            make(map[string]AlertScanStats),
+	}
+
+	m.mu.RLock()
+	sports := make([]string, 0, len(m.alertScans))
+	for sport, stats := range m.alertScans {
+		health.LastScans[sport] = stats
+		sports = append(sports, sport)
+	}
+	m.mu.RUnlock()
+	sort.Strings(sports)
+
+	var warnings []string
+	degraded := false
+	for _, sport := range sports {
+		stats := health.LastScans[sport]
+		if t := stats.Throttle; t != nil && m.clock.Now().Before(t.Until) {
+			warnings = append(warnings, fmt.Sprintf("Alert scan (%s) throttled: %d of %d value alerts held back, alerts need %.2fx their threshold until %s",
+				sport, stats.AlertsThrottled, stats.AlertsFound, t.MinEdge, t.Until.Format("15:04")))
+		}
+		if stats.Skipped() == 0 {
+			continue
+		}
+
+		if stats.SkippedMissingPlayer > 0 {
+			warnings = append(warnings, fmt.Sprintf("Alert scan (%s) skipped %d props with no player averages: %s",
+				sport, stats.SkippedMissingPlayer, strings.Join(stats.MissingPlayers, ", ")))
+		}
+		if stats.SkippedUnmappedCategory > 0 {
+			warnings = append(warnings, fmt.Sprintf("Alert scan (%s) skipped %d props with unmapped categories: %s",
+				sport, stats.SkippedUnmappedCategory, strings.Join(stats.UnmappedCategories, ", ")))
+		}
+		if stats.SkippedMissingAverage > 0 {
+			warnings = append(warnings, fmt.Sprintf("Alert scan (%s) skipped %d props with no average for the category",
+				sport, stats.SkippedMissingAverage))
+		}
+
+		// Nothing could be evaluated, so the scan can't produce any alerts
+		if stats.PropsScanned > 0 && stats.PropsMatched == 0 {
+			degraded = true
+		}
+	}
+
+	return health, warnings, degraded
+}
@@ -0,0 +1,66 @@
+package models
+
+import "strings"
+
+// Bookmaker tiers
+const (
+	// TierSharp books take big limits at low margins and move their lines
+	// first when informed money comes in
+	TierSharp = "sharp"
+
+	// TierRecreational books cater to the public and tend to follow the
+	// sharp books' lines
+	TierRecreational = "recreational"
+)
+
+// BookmakerInfo describes a bookmaker the Odds API lists
+type BookmakerInfo struct {
+	Key  string `json:"key"`  // Odds API key
+	Name string `json:"name"` // display name
+	Tier string `json:"tier"` // TierSharp or TierRecreational
+}
+
+// bookmakers lists the bookmakers with a known tier. Books not listed
+// aren't treated as either.
+var bookmakers = []BookmakerInfo{
+	{Key: "pinnacle", Name: "Pinnacle", Tier: TierSharp},
+	{Key: "circasports", Name: "Circa Sports", Tier: TierSharp},
+	{Key: "betonlineag", Name: "BetOnline.ag", Tier: TierSharp},
+	{Key: "lowvig", Name: "LowVig.ag", Tier: TierSharp},
+	{Key: "bookmaker", Name: "Bookmaker", Tier: TierSharp},
+	{Key: "draftkings", Name: "DraftKings", Tier: TierRecreational},
+	{Key: "fanduel", Name: "FanDuel", Tier: TierRecreational},
+	{Key: "betmgm", Name: "BetMGM", Tier: TierRecreational},
+	{Key: "williamhill_us", Name: "Caesars", Tier: TierRecreational},
+	{Key: "betrivers", Name: "BetRivers", Tier: TierRecreational},
+	{Key: "espnbet", Name: "ESPN BET", Tier: TierRecreational},
+	{Key: "fanatics", Name: "Fanatics", Tier: TierRecreational},
+	{Key: "hardrockbet", Name: "Hard Rock Bet", Tier: TierRecreational},
+	{Key: "pointsbetus", Name: "PointsBet (US)", Tier: TierRecreational},
+	{Key: "unibet_us", Name: "Unibet", Tier: TierRecreational},
+	{Key: "bovada", Name: "Bovada", Tier: TierRecreational},
+	{Key: "mybookieag", Name: "MyBookie.ag", Tier: TierRecreational},
+	{Key: "betus", Name: "BetUS", Tier: TierRecreational},
+}
+
+// Bookmakers returns every bookmaker with a known tier
+func Bookmakers() []BookmakerInfo {
+	return append([]BookmakerInfo(nil), bookmakers...)
+}
+
+// LookupBookmaker finds a bookmaker by its Odds API key, ignoring case
+func LookupBookmaker(key string) (BookmakerInfo, bool) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	for _, info := range bookmakers {
+		if key == info.Key {
+			return info, true
+		}
+	}
+	return BookmakerInfo{}, false
+}
+
+// BookmakerTier returns a bookmaker's tier, or "" when it has none
+func BookmakerTier(key string) string {
+	info, _ := LookupBookmaker(key)
+	return info.Tier
+}
@@ -0,0 +1,77 @@
+package models
+
+// Alert categories group every kind of alert for per-channel subscriptions
+// and client-side routing. Each alert carries one in its category field.
+const (
+	CategoryPropValue  = "prop_value"       // prop lines off the player's average, and their rechecks
+	CategoryLineMove   = "line_move"        // fast line movement
+	CategoryArbitrage  = "arbitrage"        // prices off the market: +EV prices and outlier books
+	CategoryDivergence = "sharp_divergence" // recreational books lagging a sharp book's move
+	CategoryInjury     = "injury"           // injuries, lineups and depth charts
+	CategoryNews       = "news"             // news on watched players
+	CategorySystem     = "system"           // notices about the service itself
+)
+
+// AlertCategories lists every alert category
+var AlertCategories = []string{
+	CategoryPropValue, CategoryLineMove, CategoryArbitrage, CategoryDivergence,
+	CategoryInjury, CategoryNews, CategorySystem,
+}
+
+// Channels alerts are delivered over, as named in category subscriptions.
+// They match the notification channel names.
+const (
+	AlertChannelWebSocket = "websocket"
+	AlertChannelPush      = "push"
+	AlertChannelDiscord   = "discord"
+	AlertChannelEmail     = "email"
+	AlertChannelWebhook   = "webhook"
+	AlertChannelTelegram  = "telegram"
+)
+
+// AlertChannels lists every channel a category can be subscribed on
+var AlertChannels = []string{
+	AlertChannelWebSocket, AlertChannelPush, AlertChannelDiscord,
+	AlertChannelEmail, AlertChannelWebhook, AlertChannelTelegram,
+}
+
+// ValidAlertCategory reports whether a category is known
+func ValidAlertCategory(category string) bool {
+	for _, c := range AlertCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidAlertChannel reports whether a channel is known
+func ValidAlertChannel(channel string) bool {
+	for _, c := range AlertChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// EventCategory returns the category of an event alert type, e.g.
+// "lineup" is an injury alert. Unknown types are system alerts.
+func EventCategory(eventType string) string {
+	switch eventType {
+	case "recheck":
+		return CategoryPropValue
+	case "line_move":
+		return CategoryLineMove
+	case "ev", "outlier", "bet_reminder":
+		return CategoryArbitrage
+	case "sharp_divergence":
+		return CategoryDivergence
+	case "lineup", "depth_chart", "injury_alert":
+		return CategoryInjury
+	case "news":
+		return CategoryNews
+	default:
+		return CategorySystem
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ConsensusLine is the median line and price across bookmakers for one
+// outcome of a market
+type ConsensusLine struct {
+	Market  string   `json:"market"`
+	Outcome string   `json:"outcome"`
+	Point   *float64 `json:"point,omitempty"` // median line, for spreads and totals
+	Price   float64  `json:"price"`           // median price at the median line
+	Books   int      `json:"books"`
+}
+
+// OutlierLine is a bookmaker whose line or price is further from the
+// consensus than the outlier margin, often a stale or mispriced line
+type OutlierLine struct {
+	ID           string    `json:"id"`
+	Category     string    `json:"category"` // always CategoryArbitrage
+	GameID       string    `json:"game_id"`
+	Sport        string    `json:"sport,omitempty"`
+	HomeTeam     string    `json:"home_team"`
+	AwayTeam     string    `json:"away_team"`
+	CommenceTime time.Time `json:"commence_time"`
+
+	Market       string   `json:"market"`
+	Outcome      string   `json:"outcome"`
+	Bookmaker    string   `json:"bookmaker"`
+	BookmakerKey string   `json:"bookmaker_key"`
+	Point        *float64 `json:"point,omitempty"`
+	Price        float64  `json:"price"`
+
+	ConsensusPoint *float64 `json:"consensus_point,omitempty"`
+	ConsensusPrice float64  `json:"consensus_price"`
+	ConsensusBooks int      `json:"consensus_books"`
+
+	// PointsOff is the book's line minus the consensus line; CentsOff is
+	// how many cents better its price is than the consensus at the same
+	// line. Favorable is true when the difference is in the bettor's favor.
+	PointsOff float64 `json:"points_off,omitempty"`
+	CentsOff  float64 `json:"cents_off,omitempty"`
+	Favorable bool    `json:"favorable"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// Vig removal methods for fair odds
+const (
+	// VigMultiplicative scales each outcome's implied probability down by
+	// the book's overround
+	VigMultiplicative = "multiplicative"
+	// VigPower raises each implied probability to the power that makes them
+	// sum to 1, taking more vig out of longshots
+	VigPower = "power"
+)
+
+// FairOdds is one outcome's no-vig consensus across bookmakers
+type FairOdds struct {
+	Market  string   `json:"market"`
+	Outcome string   `json:"outcome"`
+	Point   *float64 `json:"point,omitempty"`
+
+	// Consensus no-vig probability by each method
+	Multiplicative float64 `json:"multiplicative"`
+	Power          float64 `json:"power"`
+
+	// American price from the selected method's probability
+	FairPrice float64 `json:"fair_price"`
+
+	Books []BookProbability `json:"books"`
+}
+
+// BookProbability is one bookmaker's price for an outcome as probabilities
+type BookProbability struct {
+	Bookmaker string  `json:"bookmaker"`
+	Price     float64 `json:"price"`
+	Implied   float64 `json:"implied"` // including the book's vig
+	NoVig     float64 `json:"no_vig"`  // by the selected method
+}
+
+// EVOpportunity is a bookmaker's price that beats the no-vig consensus by
+// at least the EV threshold
+type EVOpportunity struct {
+	ID           string    `json:"id"`
+	Category     string    `json:"category"` // always CategoryArbitrage
+	GameID       string    `json:"game_id"`
+	Sport        string    `json:"sport,omitempty"`
+	HomeTeam     string    `json:"home_team"`
+	AwayTeam     string    `json:"away_team"`
+	CommenceTime time.Time `json:"commence_time"`
+
+	Market       string   `json:"market"`
+	Outcome      string   `json:"outcome"`
+	Point        *float64 `json:"point,omitempty"`
+	Bookmaker    string   `json:"bookmaker"`
+	BookmakerKey string   `json:"bookmaker_key"`
+	Price        float64  `json:"price"`
+
+	ImpliedProbability float64 `json:"implied_probability"`
+	FairProbability    float64 `json:"fair_probability"`
+	FairPrice          float64 `json:"fair_price"`
+	EVPercent          float64 `json:"ev_pct"`
+	Method             string  `json:"method"`
+	ConsensusBooks     int     `json:"consensus_books"`
+}
+
+// DecimalPayout converts an American price to decimal odds, the total
+// returned per unit staked
+func DecimalPayout(price float64) float64 {
+	if price < 0 {
+		return 1 + 100/-price
+	}
+	return 1 + price/100
+}
+
+// AmericanPrice converts a probability to the American price it implies
+func AmericanPrice(probability float64) float64 {
+	if probability <= 0 || probability >= 1 {
+		return 0
+	}
+	if probability >= 0.5 {
+		return -100 * probability / (1 - probability)
+	}
+	return 100 * (1 - probability) / probability
+}
@@ -19,6 +19,15 @@ const (
 	MarketTotals  Market = "totals"  // Over/under
 )
 
+// GameStatus is where a game is in its lifecycle
+type GameStatus string
+
+const (
+	GameScheduled GameStatus = "scheduled"
+	GameLive      GameStatus = "live"
+	GameFinal     GameStatus = "final"
+)
+
 // Game represents a single sporting event
 type Game struct {
 	ID           string    `json:"id"`
@@ -27,9 +36,16 @@ type Game struct {
 	CommenceTime time.Time `json:"commence_time"`
 	HomeTeam     string    `json:"home_team"`
 	AwayTeam     string    `json:"away_team"`
+	Status       GameStatus `json:"status,omitempty"`
 	Bookmakers   []Bookmaker `json:"bookmakers,omitempty"`
 }
 
+// Started reports whether a game is underway or over, by its status or,
+// before the status catches up, its start time
+func (g Game) Started(now time.Time) bool {
+	return g.Status == GameLive || g.Status == GameFinal || !now.Before(g.CommenceTime)
+}
+
 // Bookmaker represents a sportsbook's odds for a game
 type Bookmaker struct {
 	Key        string    `json:"key"`
@@ -49,6 +65,7 @@ type Outcome struct {
 	Name  string   `json:"name"`
 	Price float64  `json:"price"`  // American odds (e.g., -110, +150)
 	Point *float64 `json:"point,omitempty"` // Spread or total line
+	Description string `json:"description,omitempty"` // Team for team totals
 }
 
 // OddsComparison represents the best odds found across bookmakers
@@ -60,6 +77,25 @@ type OddsComparison struct {
 	Moneyline    *MoneylineComparison `json:"moneyline,omitempty"`
 	Spread       *SpreadComparison    `json:"spread,omitempty"`
 	Total        *TotalComparison     `json:"total,omitempty"`
+	TeamTotals   *TeamTotalsComparison `json:"team_totals,omitempty"`
+	MyBook       []MyBookPrice        `json:"my_book,omitempty"`
+
+	// Half and quarter markets, for the periods any book prices
+	Periods []PeriodComparison `json:"periods,omitempty"`
+
+	// No-vig consensus per outcome and prices beating it
+	VigMethod      string          `json:"vig_method,omitempty"`
+	EVThresholdPct float64         `json:"ev_threshold_pct,omitempty"`
+	PeriodEVThresholdPct float64   `json:"period_ev_threshold_pct,omitempty"`
+	Fair           []FairOdds      `json:"fair,omitempty"`
+	PositiveEV     []EVOpportunity `json:"positive_ev,omitempty"`
+
+	// Median line and price per outcome and the books off it by more than
+	// the outlier margins
+	OutlierPoints float64         `json:"outlier_points,omitempty"`
+	OutlierCents  float64         `json:"outlier_cents,omitempty"`
+	Consensus     []ConsensusLine `json:"consensus,omitempty"`
+	Outliers      []OutlierLine   `json:"outliers,omitempty"`
 }
 
 // MoneylineComparison shows best moneyline odds
@@ -182,6 +218,7 @@ type GamePlayerProps struct {
 type PlayerWithProps struct {
 	Name  string               `json:"name"`
 	Team  string               `json:"team"`
+	Role  string               `json:"role,omitempty"` // NFL depth chart role, e.g. "RB1"
 	Props []PlayerPropCategory `json:"props"`
 }
 
@@ -191,3 +228,40 @@ type PlayerPropCategory struct {
 	Market     PlayerPropMarket `json:"market"`
 	Bookmakers []PropBookmaker `json:"bookmakers"`
 }
+
+// MyBookPrice compares the user's primary sportsbook to the best available
+// price for one side of a market. Negative amounts given up mean the user's
+// book is the better option.
+type MyBookPrice struct {
+	Market        string   `json:"market"`
+	Outcome       string   `json:"outcome"`
+	Bookmaker     string   `json:"bookmaker"`
+	Price         float64  `json:"price"`
+	Point         *float64 `json:"point,omitempty"`
+	BestBookmaker string   `json:"best_bookmaker"`
+	BestPrice     float64  `json:"best_price"`
+	BestPoint     *float64 `json:"best_point,omitempty"`
+	CentsGivenUp  float64  `json:"cents_given_up"`
+	PointsGivenUp float64  `json:"points_given_up,omitempty"`
+}
+
+// PriceCents returns how many cents better American price a is than b,
+// treating -100 and +100 as the same price: -105 is 5 cents better than
+// -110, and +105 is 15 cents better than -110.
+func PriceCents(a, b float64) float64 {
+	cents := func(price float64) float64 {
+		if price >= 100 {
+			return price - 100
+		}
+		return price + 100
+	}
+	return cents(a) - cents(b)
+}
+
+// ImpliedProbability converts an American price to its implied probability
+func ImpliedProbability(price float64) float64 {
+	if price < 0 {
+		return -price / (-price + 100)
+	}
+	return 100 / (price + 100)
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Period is part of a game with its own markets, such as the first half.
+// Period markets are keyed by the full-game market and the period, e.g.
+// "spreads_h1".
+type Period string
+
+const (
+	PeriodFirstHalf     Period = "h1"
+	PeriodSecondHalf    Period = "h2"
+	PeriodFirstQuarter  Period = "q1"
+	PeriodSecondQuarter Period = "q2"
+	PeriodThirdQuarter  Period = "q3"
+	PeriodFourthQuarter Period = "q4"
+)
+
+// periods lists every period in game order, with its display label
+var periods = []struct {
+	period Period
+	label  string
+}{
+	{PeriodFirstHalf, "1H"},
+	{PeriodSecondHalf, "2H"},
+	{PeriodFirstQuarter, "1Q"},
+	{PeriodSecondQuarter, "2Q"},
+	{PeriodThirdQuarter, "3Q"},
+	{PeriodFourthQuarter, "4Q"},
+}
+
+// Periods returns every period in game order
+func Periods() []Period {
+	result := make([]Period, len(periods))
+	for i, p := range periods {
+		result[i] = p.period
+	}
+	return result
+}
+
+// Label returns a period's display label, e.g. "1H"
+func (p Period) Label() string {
+	for _, known := range periods {
+		if known.period == p {
+			return known.label
+		}
+	}
+	return strings.ToUpper(string(p))
+}
+
+// PeriodMarket returns the key of a full-game market's version for a period
+func PeriodMarket(base Market, period Period) Market {
+	return Market(string(base) + "_" + string(period))
+}
+
+// SplitPeriod splits a market key into its full-game market and period.
+// Full-game markets have no period.
+func SplitPeriod(market Market) (Market, Period) {
+	i := strings.LastIndex(string(market), "_")
+	if i < 0 {
+		return market, ""
+	}
+	period := Period(market[i+1:])
+	for _, known := range periods {
+		if known.period == period {
+			return market[:i], period
+		}
+	}
+	return market, ""
+}
+
+// ParsePeriodMarket checks a period market key such as "totals_q1": a
+// moneyline, spread, total or team total for a known period
+func ParsePeriodMarket(s string) (Market, error) {
+	market := Market(strings.ToLower(strings.TrimSpace(s)))
+	base, period := SplitPeriod(market)
+	if period == "" {
+		return "", fmt.Errorf("unknown period market %q: use h2h, spreads, totals or team_totals with _h1, _h2 or _q1 to _q4", s)
+	}
+	switch base {
+	case MarketH2H, MarketSpreads, MarketTotals, MarketTeamTotals:
+		return market, nil
+	}
+	return "", fmt.Errorf("unknown period market %q: use h2h, spreads, totals or team_totals with _h1, _h2 or _q1 to _q4", s)
+}
+
+// PeriodComparison is the best odds across bookmakers for one period's
+// markets
+type PeriodComparison struct {
+	Period     Period                `json:"period"`
+	Label      string                `json:"label"`
+	Moneyline  *MoneylineComparison  `json:"moneyline,omitempty"`
+	Spread     *SpreadComparison     `json:"spread,omitempty"`
+	Total      *TotalComparison      `json:"total,omitempty"`
+	TeamTotals *TeamTotalsComparison `json:"team_totals,omitempty"`
+}
+
+// PeriodsOffered reports whether a sport's games are dealt in halves and
+// quarters
+func PeriodsOffered(sport Sport) bool {
+	return sport == SportNBA || sport == SportNFL
+}
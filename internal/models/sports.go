@@ -0,0 +1,121 @@
+package models
+
+import "strings"
+
+// Sports added through the registry
+const (
+	SportMLB Sport = "baseball_mlb"
+	SportNHL Sport = "icehockey_nhl"
+)
+
+// MLB player prop markets
+const (
+	BatterHits         PlayerPropMarket = "batter_hits"
+	BatterTotalBases   PlayerPropMarket = "batter_total_bases"
+	BatterHomeRuns     PlayerPropMarket = "batter_home_runs"
+	BatterRBIs         PlayerPropMarket = "batter_rbis"
+	BatterRunsScored   PlayerPropMarket = "batter_runs_scored"
+	PitcherStrikeouts  PlayerPropMarket = "pitcher_strikeouts"
+	PitcherOuts        PlayerPropMarket = "pitcher_outs"
+	PitcherHitsAllowed PlayerPropMarket = "pitcher_hits_allowed"
+)
+
+// NHL player prop markets. NHL points and assists share their market keys
+// with the NBA's, so they aren't tracked.
+const (
+	PlayerGoals           PlayerPropMarket = "player_goals"
+	PlayerShotsOnGoal     PlayerPropMarket = "player_shots_on_goal"
+	PlayerTotalSaves      PlayerPropMarket = "player_total_saves"
+	PlayerPowerPlayPoints PlayerPropMarket = "player_power_play_points"
+	PlayerBlockedShots    PlayerPropMarket = "player_blocked_shots"
+)
+
+// SportInfo describes a sport with player props and value alerts
+type SportInfo struct {
+	Sport   Sport              `json:"sport"`   // Odds API key
+	Key     string             `json:"key"`     // short key used in URLs and preferences
+	Name    string             `json:"name"`    // display name
+	Markets []PlayerPropMarket `json:"markets"` // player prop markets fetched
+}
+
+// registry lists every sport with player props, in display order
+var registry = []SportInfo{
+	{
+		Sport:   SportNBA,
+		Key:     "nba",
+		Name:    "NBA",
+		Markets: []PlayerPropMarket{PlayerPoints, PlayerRebounds, PlayerAssists, PlayerThrees},
+	},
+	{
+		Sport:   SportNFL,
+		Key:     "nfl",
+		Name:    "NFL",
+		Markets: []PlayerPropMarket{PlayerPassYards, PlayerPassTDs, PlayerRushYards, PlayerReceptions, PlayerReceivingYards},
+	},
+	{
+		Sport:   SportMLB,
+		Key:     "mlb",
+		Name:    "MLB",
+		Markets: []PlayerPropMarket{BatterHits, BatterTotalBases, BatterHomeRuns, BatterRBIs, PitcherStrikeouts, PitcherOuts},
+	},
+	{
+		Sport:   SportNHL,
+		Key:     "nhl",
+		Name:    "NHL",
+		Markets: []PlayerPropMarket{PlayerGoals, PlayerShotsOnGoal, PlayerTotalSaves, PlayerPowerPlayPoints},
+	},
+}
+
+// Sports returns every registered sport
+func Sports() []SportInfo {
+	return append([]SportInfo(nil), registry...)
+}
+
+// SportKeys returns the Odds API keys of every registered sport
+func SportKeys() []Sport {
+	keys := make([]Sport, len(registry))
+	for i, info := range registry {
+		keys[i] = info.Sport
+	}
+	return keys
+}
+
+// LookupSport finds a registered sport by its short key ("nba") or Odds API
+// key ("basketball_nba"), ignoring case
+func LookupSport(name string) (SportInfo, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, info := range registry {
+		if name == info.Key || name == string(info.Sport) {
+			return info, true
+		}
+	}
+	return SportInfo{}, false
+}
+
+// ParseSport resolves a short or Odds API key to a registered sport
+func ParseSport(name string) (Sport, bool) {
+	info, ok := LookupSport(name)
+	return info.Sport, ok
+}
+
+// ShortKey returns the sport's short key, or the sport unchanged when it
+// isn't registered
+func (s Sport) ShortKey() string {
+	if info, ok := LookupSport(string(s)); ok {
+		return info.Key
+	}
+	return string(s)
+}
+
+// SportChoices lists the registered short keys for error messages, e.g.
+// "'nba', 'nfl', 'mlb', or 'nhl'"
+func SportChoices() string {
+	quoted := make([]string, len(registry))
+	for i, info := range registry {
+		quoted[i] = "'" + info.Key + "'"
+	}
+	if len(quoted) < 2 {
+		return strings.Join(quoted, "")
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + ", or " + quoted[len(quoted)-1]
+}
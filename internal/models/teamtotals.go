@@ -0,0 +1,46 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MarketTeamTotals is each team's own over/under. Its outcomes name the
+// team in Description, and like half and quarter markets it's only served
+// per game.
+const MarketTeamTotals Market = "team_totals"
+
+// TeamTotalsComparison shows best over/under odds on each team's total
+type TeamTotalsComparison struct {
+	Home *TotalComparison `json:"home,omitempty"`
+	Away *TotalComparison `json:"away,omitempty"`
+}
+
+// Label names an outcome uniquely within its market: the team it's for,
+// when it has one, then its name, e.g. "Boston Celtics Over"
+func (o Outcome) Label() string {
+	if o.Description == "" {
+		return o.Name
+	}
+	return o.Description + " " + o.Name
+}
+
+// EventOnly reports whether a market is only served per game: half and
+// quarter markets and team totals
+func EventOnly(market Market) bool {
+	base, period := SplitPeriod(market)
+	return period != "" || base == MarketTeamTotals
+}
+
+// ParseEventMarket checks a market fetched per game: team totals, or a
+// period market such as "totals_q1"
+func ParseEventMarket(s string) (Market, error) {
+	if market := Market(strings.ToLower(strings.TrimSpace(s))); market == MarketTeamTotals {
+		return market, nil
+	}
+	market, err := ParsePeriodMarket(s)
+	if err != nil {
+		return "", fmt.Errorf("unknown event market %q: use team_totals, or h2h, spreads, totals or team_totals with _h1, _h2 or _q1 to _q4", s)
+	}
+	return market, nil
+}
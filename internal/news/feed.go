@@ -0,0 +1,136 @@
+package news
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Item is a single headline from a feed
+type Item struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Link        string    `json:"link"`
+	Description string    `json:"description,omitempty"`
+	Published   time.Time `json:"published,omitempty"`
+	Feed        string    `json:"feed"`
+}
+
+// rssFeed covers RSS 2.0
+type rssFeed struct {
+	Channel struct {
+		Items []struct {
+			GUID        string `xml:"guid"`
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+			PubDate     string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// atomFeed covers Atom 1.0
+type atomFeed struct {
+	Entries []struct {
+		ID      string `xml:"id"`
+		Title   string `xml:"title"`
+		Summary string `xml:"summary"`
+		Updated string `xml:"updated"`
+		Links   []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+	} `xml:"entry"`
+}
+
+// fetchFeed downloads and parses an RSS or Atom feed
+func fetchFeed(client *http.Client, url string) ([]Item, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed error (status %d)", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 5<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+	return parseFeed(url, body)
+}
+
+// parseFeed parses RSS 2.0 or Atom 1.0, keyed on the root element
+func parseFeed(url string, body []byte) ([]Item, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(body, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	var items []Item
+	switch root.XMLName.Local {
+	case "rss":
+		var feed rssFeed
+		if err := xml.Unmarshal(body, &feed); err != nil {
+			return nil, fmt.Errorf("failed to parse RSS: %w", err)
+		}
+		for _, i := range feed.Channel.Items {
+			id := i.GUID
+			if id == "" {
+				id = i.Link
+			}
+			published, _ := time.Parse(time.RFC1123Z, i.PubDate)
+			if published.IsZero() {
+				published, _ = time.Parse(time.RFC1123, i.PubDate)
+			}
+			items = append(items, Item{
+				ID:          id,
+				Title:       strings.TrimSpace(i.Title),
+				Link:        strings.TrimSpace(i.Link),
+				Description: i.Description,
+				Published:   published,
+				Feed:        url,
+			})
+		}
+
+	case "feed":
+		var feed atomFeed
+		if err := xml.Unmarshal(body, &feed); err != nil {
+			return nil, fmt.Errorf("failed to parse Atom: %w", err)
+		}
+		for _, e := range feed.Entries {
+			link := ""
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			id := e.ID
+			if id == "" {
+				id = link
+			}
+			published, _ := time.Parse(time.RFC3339, e.Updated)
+			items = append(items, Item{
+				ID:          id,
+				Title:       strings.TrimSpace(e.Title),
+				Link:        link,
+				Description: e.Summary,
+				Published:   published,
+				Feed:        url,
+			})
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported feed format %q", root.XMLName.Local)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,146 @@
+package news
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/database"
+)
+
+// Config holds news watcher configuration
+type Config struct {
+	// Feeds are the RSS or Atom feed URLs to watch
+	Feeds []string
+
+	// Interval is the time between feed checks
+	Interval time.Duration
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Interval: 10 * time.Minute,
+	}
+}
+
+// Match is a new headline mentioning a watchlist player
+type Match struct {
+	Player string `json:"player"`
+	Item   Item   `json:"item"`
+}
+
+// Watcher polls news feeds for headlines about watchlist players
+type Watcher struct {
+	config     Config
+	prefs      database.PreferencesStore
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	seen     map[string]map[string]bool // feed -> item IDs in its last fetch
+	callback func([]Match)
+}
+
+// NewWatcher creates a new news watcher
+func NewWatcher(config Config, prefs database.PreferencesStore) *Watcher {
+	return &Watcher{
+		config: config,
+		prefs:  prefs,
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+		seen: make(map[string]map[string]bool),
+	}
+}
+
+// SetCallback sets the function called with new matching headlines
+func (w *Watcher) SetCallback(fn func([]Match)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = fn
+}
+
+// Start checks feeds immediately and then on every interval
+func (w *Watcher) Start(ctx context.Context) {
+	if w.config.Interval <= 0 {
+		w.config.Interval = DefaultConfig().Interval
+	}
+
+	log.Printf("News watcher starting (%d feeds, interval: %v)", len(w.config.Feeds), w.config.Interval)
+	w.Check()
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// Check fetches every feed and reports new headlines naming a watchlist
+// player. Items already in a feed when it's first read are only recorded,
+// so a restart doesn't replay old news. Only the IDs from each feed's latest
+// fetch are kept, which bounds memory to the size of the feeds.
+func (w *Watcher) Check() {
+	prefs, err := w.prefs.GetPreferences()
+	if err != nil {
+		log.Printf("News: failed to get preferences: %v", err)
+		return
+	}
+	patterns := watchlistPatterns(prefs.Watchlist)
+
+	var matches []Match
+	for _, url := range w.config.Feeds {
+		items, err := fetchFeed(w.httpClient, url)
+		if err != nil {
+			log.Printf("News: %s: %v", url, err)
+			continue
+		}
+
+		current := make(map[string]bool, len(items))
+		w.mu.Lock()
+		previous, primed := w.seen[url]
+		for _, item := range items {
+			current[item.ID] = true
+			if !primed || previous[item.ID] {
+				continue
+			}
+			for player, re := range patterns {
+				if re.MatchString(item.Title) || re.MatchString(item.Description) {
+					matches = append(matches, Match{Player: player, Item: item})
+					break
+				}
+			}
+		}
+		w.seen[url] = current
+		w.mu.Unlock()
+	}
+
+	w.mu.Lock()
+	callback := w.callback
+	w.mu.Unlock()
+
+	if len(matches) > 0 {
+		log.Printf("News: %d new headlines for watchlist players", len(matches))
+		if callback != nil {
+			callback(matches)
+		}
+	}
+}
+
+// watchlistPatterns builds case-insensitive whole-name matchers
+func watchlistPatterns(watchlist []string) map[string]*regexp.Regexp {
+	patterns := make(map[string]*regexp.Regexp, len(watchlist))
+	for _, name := range watchlist {
+		patterns[name] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(name) + `\b`)
+	}
+	return patterns
+}
@@ -0,0 +1,19 @@
+package notifications
+
+// subscribed reports whether alerts in a category are wanted over a
+// channel. If the preferences can't be read the alert goes out, since
+// subscriptions only ever narrow delivery.
+func (s *Service) subscribed(category, channel string) bool {
+	prefs, err := s.db.GetPreferences()
+	if err != nil {
+		return true
+	}
+	return prefs.Subscribed(category, channel)
+}
+
+// categoryTag prefixes a push tag with the alert's category, e.g.
+// "injury:lineup-401585-LeBron James", so the service worker can route
+// notifications by category without parsing their data
+func categoryTag(category, tag string) string {
+	return category + ":" + tag
+}
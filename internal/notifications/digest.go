@@ -0,0 +1,209 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/reports"
+)
+
+// DigestChannels are the channels the morning digest can go to
+var DigestChannels = []string{ChannelPush, ChannelWebhook, ChannelEmail}
+
+// ValidDigestChannel reports whether the digest can go to a channel
+func ValidDigestChannel(channel string) bool {
+	for _, c := range DigestChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrNoDigestChannel is returned by SendDigest when none of the digest
+// channels is set up: push needs VAPID keys and push enabled, webhook an
+// enabled webhook, and email SMTP and an address
+var ErrNoDigestChannel = errors.New("no digest channel is set up")
+
+// digestPushGames is the most games named in the digest push
+const digestPushGames = 3
+
+// checkDigest sends the morning digest once the configured local time has
+// passed, at most once per local day
+func (s *Service) checkDigest() {
+	if s.reports == nil {
+		return
+	}
+
+	prefs, err := s.db.GetPreferences()
+	if err != nil || !prefs.DigestEnabled {
+		return
+	}
+	// The digest is a betting digest, so a cool-off holds it too
+	if s.coolingOff(prefs) {
+		return
+	}
+
+	now := s.clock.Now().In(prefs.Location())
+	today := now.Format("2006-01-02")
+
+	sendHour, sendMin := 8, 0
+	fmt.Sscanf(prefs.DigestTime, "%d:%d", &sendHour, &sendMin)
+	if now.Hour()*60+now.Minute() < sendHour*60+sendMin {
+		return
+	}
+
+	lastSent, err := s.db.GetDigestLastSent()
+	if err != nil || lastSent == today {
+		return
+	}
+
+	if _, err := s.SendDigest(now); err != nil {
+		log.Printf("Failed to send digest: %v", err)
+		return
+	}
+
+	if err := s.db.SetDigestLastSent(today); err != nil {
+		log.Printf("Failed to record digest: %v", err)
+	}
+}
+
+// SendDigest builds the morning digest of the rest of today's games and
+// queues one message to each digest channel that can take it, returning
+// those channels. The digest goes out at the time chosen for it, so quiet
+// hours and rate limits don't apply.
+func (s *Service) SendDigest(now time.Time) ([]string, error) {
+	if s.reports == nil {
+		return nil, fmt.Errorf("reports not configured")
+	}
+	prefs, err := s.db.GetPreferences()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	loc := prefs.Location()
+	now = now.In(loc)
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+	digest, err := s.reports.BuildDigest(summarySports(prefs.Sports), endOfDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build digest: %w", err)
+	}
+	open := digest.Alerts[:0]
+	for _, a := range digest.Alerts {
+		if !prefs.PlayerMuted(a.PlayerName) {
+			open = append(open, a)
+		}
+	}
+	digest.Alerts = open
+
+	var sent []string
+	if prefs.DigestChannel(ChannelPush) && s.config.VAPIDPrivateKey != "" && s.config.VAPIDPublicKey != "" && prefs.EnablePush {
+		s.pushDigest(digest, loc)
+		sent = append(sent, ChannelPush)
+	}
+	if prefs.DigestChannel(ChannelWebhook) && s.queueWebhooks(WebhookPayload{
+		Event:     WebhookEventDigest,
+		CreatedAt: digest.GeneratedAt,
+		Count:     len(digest.Alerts),
+		Alerts:    digest.Alerts,
+		Digest:    digest,
+	}) > 0 {
+		sent = append(sent, ChannelWebhook)
+	}
+	if prefs.DigestChannel(ChannelEmail) && s.email.config.Configured() && prefs.Email != "" {
+		if err := s.emailDigest(prefs, digest, now); err != nil {
+			return sent, err
+		}
+		sent = append(sent, ChannelEmail)
+	}
+	if len(sent) == 0 {
+		return nil, ErrNoDigestChannel
+	}
+
+	log.Printf("Digest queued for %s (%d games, %d alerts)", strings.Join(sent, ", "), len(digest.Games), len(digest.Alerts))
+	return sent, nil
+}
+
+// pushDigest queues the digest as one push naming the first few games
+func (s *Service) pushDigest(digest *reports.DailySummary, loc *time.Location) {
+	payload := PushPayload{
+		Title: fmt.Sprintf("Today: %d games, %d open alerts", len(digest.Games), len(digest.Alerts)),
+		Body:  digestPushBody(digest, loc),
+		Icon:  "/icon-192.png",
+		Badge: "/badge-72.png",
+		Tag:   categoryTag(models.CategoryPropValue, "daily-digest"),
+		Data: PushData{
+			URL:      "/",
+			Category: models.CategoryPropValue,
+			Count:    len(digest.Alerts),
+		},
+	}
+	s.dispatch(ChannelPush, delivery{
+		kind:    "daily_digest",
+		payload: payload,
+		send:    func() (bool, error) { return s.deliverPush(payload) },
+	})
+}
+
+// digestPushBody lists the first games with their start times, then the
+// newest open alert and the injury count
+func digestPushBody(digest *reports.DailySummary, loc *time.Location) string {
+	var lines []string
+	for i, g := range digest.Games {
+		if i == digestPushGames {
+			lines = append(lines, fmt.Sprintf("+%d more games", len(digest.Games)-digestPushGames))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s @ %s %s", g.AwayTeam, g.HomeTeam, g.CommenceTime.In(loc).Format("3:04 PM")))
+	}
+	if len(digest.Games) == 0 {
+		lines = append(lines, "No games left today")
+	}
+	if len(digest.Alerts) > 0 {
+		a := digest.Alerts[0]
+		lines = append(lines, fmt.Sprintf("Newest alert: %s %s %s %.1f", a.PlayerName, a.PropCategory, strings.ToUpper(a.Direction), a.LineValue))
+	}
+	if len(digest.Injuries) > 0 {
+		lines = append(lines, fmt.Sprintf("%d players out or doubtful", len(digest.Injuries)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// emailDigest queues the digest as one email in the daily summary layout
+func (s *Service) emailDigest(prefs *database.Preferences, digest *reports.DailySummary, now time.Time) error {
+	token, err := s.db.EnsureUnsubscribeToken()
+	if err != nil {
+		return fmt.Errorf("failed to get unsubscribe token: %w", err)
+	}
+	unsubscribeURL := s.unsubscribeURL(token)
+
+	body, err := renderSummaryEmail(summaryEmailData{
+		Heading:        "LineFinder Morning Digest",
+		Date:           now.Format("Monday, January 2"),
+		Summary:        digest,
+		NoAlerts:       "No open value alerts.",
+		Reason:         "the morning digest is enabled",
+		UnsubscribeURL: unsubscribeURL,
+		Helpline:       s.helplineText(prefs),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	to := prefs.Email
+	subject := fmt.Sprintf("LineFinder digest: %d games, %d open alerts", len(digest.Games), len(digest.Alerts))
+	headers := unsubscribeHeaders(unsubscribeURL)
+	s.dispatch(ChannelEmail, delivery{
+		kind:    "daily_digest",
+		payload: map[string]interface{}{"games": len(digest.Games), "alerts": len(digest.Alerts)},
+		send: func() (bool, error) {
+			return true, s.email.Send(to, subject, body, headers)
+		},
+	})
+	return nil
+}
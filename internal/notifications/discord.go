@@ -0,0 +1,188 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// Discord gets its own hourly budget, rate_limit_discord, separate from push
+const (
+	discordRateLimitChannel = "discord"
+	defaultDiscordRateLimit = 10
+)
+
+// discordAPIBase is the Discord REST API used with a bot token
+const discordAPIBase = "https://discord.com/api/v10"
+
+// discordMaxEmbeds is the most embeds Discord accepts in one message
+const discordMaxEmbeds = 10
+
+// Embed colors by alert confidence
+var discordColors = map[string]int{
+	alerts.ConfidenceHigh:   0x2ecc71,
+	alerts.ConfidenceMedium: 0xf1c40f,
+	alerts.ConfidenceLow:    0x95a5a6,
+}
+
+// DiscordEmbed is a Discord rich embed
+type DiscordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color,omitempty"`
+	Fields      []DiscordEmbedField `json:"fields,omitempty"`
+	Footer      *DiscordEmbedFooter `json:"footer,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+}
+
+// DiscordEmbedField is a name/value pair in an embed
+type DiscordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// DiscordEmbedFooter is the small text under an embed
+type DiscordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// DiscordMessage is the body posted to a Discord webhook or channel
+type DiscordMessage struct {
+	Username string         `json:"username,omitempty"` // webhooks only
+	Content  string         `json:"content,omitempty"`
+	Embeds   []DiscordEmbed `json:"embeds"`
+}
+
+// discordConfigured reports whether preferences have somewhere to post
+func discordConfigured(prefs *database.Preferences) bool {
+	return prefs.DiscordWebhookURL != "" || (prefs.DiscordBotToken != "" && prefs.DiscordChannelID != "")
+}
+
+// sendDiscord queues a value alert batch for Discord, subject to quiet hours
+// and Discord's own rate limit
+func (s *Service) sendDiscord(batch []alerts.ValueAlert) {
+	if len(batch) == 0 {
+		return
+	}
+
+	prefs, err := s.db.GetPreferences()
+	if err != nil {
+		log.Printf("Failed to get preferences for Discord: %v", err)
+		return
+	}
+	if !prefs.EnableDiscord || !discordConfigured(prefs) || !prefs.Subscribed(models.CategoryPropValue, models.AlertChannelDiscord) {
+		return
+	}
+	if s.isQuietHours() {
+		log.Printf("Quiet hours - skipping Discord for %d alerts", len(batch))
+		return
+	}
+	if !s.checkRateLimit(discordRateLimitChannel) {
+		log.Printf("Rate limit exceeded - skipping Discord for %d alerts", len(batch))
+		return
+	}
+
+	message := s.formatDiscord(batch)
+	webhookURL, token, channelID := prefs.DiscordWebhookURL, prefs.DiscordBotToken, prefs.DiscordChannelID
+	s.dispatch(ChannelDiscord, delivery{
+		kind:    "value_alerts",
+		payload: message,
+		send:    func() (bool, error) { return s.postDiscord(webhookURL, token, channelID, message) },
+		onSent: func() {
+			s.limits.IncrementRateLimit(discordRateLimitChannel)
+			log.Printf("Discord message sent: %d alerts", len(batch))
+		},
+	})
+}
+
+// formatDiscord builds one embed per alert, up to Discord's limit, with a
+// summary line noting any that didn't fit
+func (s *Service) formatDiscord(batch []alerts.ValueAlert) DiscordMessage {
+	message := DiscordMessage{Username: "LineFinder", Content: s.formatTitle(batch)}
+	if len(batch) > discordMaxEmbeds {
+		message.Content += fmt.Sprintf(" (showing %d, %d more in the app)", discordMaxEmbeds, len(batch)-discordMaxEmbeds)
+		batch = batch[:discordMaxEmbeds]
+	}
+
+	for _, a := range batch {
+		embed := DiscordEmbed{
+			Title: fmt.Sprintf("%s: %s %s %.1f", a.PlayerName, a.PropCategory, strings.ToUpper(a.Direction), a.Line),
+			Color: discordColors[a.Confidence],
+			Fields: []DiscordEmbedField{
+				{Name: "Line", Value: fmt.Sprintf("%.1f", a.Line), Inline: true},
+				{Name: "Average", Value: fmt.Sprintf("%.1f", a.Average), Inline: true},
+				{Name: "Difference", Value: fmt.Sprintf("%+.1f", a.Difference), Inline: true},
+				{Name: "Best Book", Value: fmt.Sprintf("%s %+.0f", a.Bookmaker, a.BestOdds), Inline: true},
+				{Name: "Confidence", Value: a.Confidence, Inline: true},
+			},
+			Footer: &DiscordEmbedFooter{Text: fmt.Sprintf("%s @ %s", a.AwayTeam, a.HomeTeam)},
+		}
+		if !a.DetectedAt.IsZero() {
+			embed.Timestamp = a.DetectedAt.UTC().Format("2006-01-02T15:04:05Z")
+		}
+
+		var notes []string
+		if mb := a.MyBook; mb != nil && mb.CentsGivenUp > 0 {
+			notes = append(notes, fmt.Sprintf("%s: %+.0f (%.0f¢ behind best)", mb.Bookmaker, mb.Price, mb.CentsGivenUp))
+		}
+		if a.SourcesDisagree {
+			notes = append(notes, "Projection sources disagree")
+		}
+		if ctx := s.contextFor(a); ctx != "" {
+			notes = append(notes, ctx)
+		}
+		embed.Description = strings.Join(notes, "\n")
+
+		message.Embeds = append(message.Embeds, embed)
+	}
+	return message
+}
+
+// postDiscord posts a message through the webhook URL when set, otherwise
+// as the bot to the channel. Client errors other than rate limiting won't
+// succeed on retry, so they're permanent.
+func (s *Service) postDiscord(webhookURL, token, channelID string, message DiscordMessage) (bool, error) {
+	url := webhookURL
+	if url == "" {
+		url = fmt.Sprintf("%s/channels/%s/messages", discordAPIBase, channelID)
+		message.Username = ""
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return false, permanent(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if webhookURL == "" {
+		req.Header.Set("Authorization", "Bot "+token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return true, nil
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("discord returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return false, permanent(err)
+	}
+	return false, err
+}
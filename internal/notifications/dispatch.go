@@ -0,0 +1,317 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/redact"
+)
+
+// Delivery channels, each with its own queue and workers
+const (
+	ChannelPush     = "push"
+	ChannelEmail    = "email"
+	ChannelWebhook  = "webhook"
+	ChannelDiscord  = "discord"
+	ChannelTelegram = "telegram"
+)
+
+// Channels lists every delivery channel
+var Channels = []string{ChannelPush, ChannelEmail, ChannelWebhook, ChannelDiscord, ChannelTelegram}
+
+// ValidChannel reports whether a channel is a delivery channel
+func ValidChannel(channel string) bool {
+	for _, c := range Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// DispatchConfig holds the queue and retry settings for a delivery channel
+type DispatchConfig struct {
+	// QueueSize is how many deliveries can wait for a worker. Deliveries
+	// arriving at a full queue are dead-lettered.
+	QueueSize int
+
+	// Workers is how many deliveries are sent concurrently
+	Workers int
+
+	// MaxAttempts is how many times a delivery is tried before it's
+	// dead-lettered
+	MaxAttempts int
+
+	// RetryDelay is the wait before the first retry. It doubles with each
+	// attempt up to MaxRetryDelay. Deliveries wait in the channel's retry
+	// queue, not on a worker.
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+}
+
+// DefaultDispatchConfig returns sensible default dispatch settings
+func DefaultDispatchConfig() DispatchConfig {
+	return DispatchConfig{
+		QueueSize:     64,
+		Workers:       2,
+		MaxAttempts:   3,
+		RetryDelay:    5 * time.Second,
+		MaxRetryDelay: 2 * time.Minute,
+	}
+}
+
+// delivery is one message waiting to be sent on a channel
+type delivery struct {
+	kind    string      // e.g. "value_alerts", "event", "system"
+	payload interface{} // recorded in the notification log
+
+	// send delivers the message, returning false without error when there
+	// was nothing to send to (e.g. no subscription)
+	send func() (bool, error)
+
+	// onSent runs after a successful send
+	onSent func()
+
+	// onFailed runs after the delivery is dead-lettered
+	onFailed func(err error)
+
+	// Set by the dispatcher: attempts so far and the delivery's log entry,
+	// once it has one
+	attempts int
+	logID    int64
+}
+
+// permanentError marks a delivery failure that retrying won't fix, such as
+// a rejected subscription
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// permanent wraps an error so the dispatcher doesn't retry it
+func permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// isPermanent reports whether a delivery error shouldn't be retried
+func isPermanent(err error) bool {
+	var pe permanentError
+	return errors.As(err, &pe)
+}
+
+// retryPoll is how often the retry queue is checked for due deliveries
+const retryPoll = time.Second
+
+// scheduledRetry is a failed delivery waiting for its next attempt
+type scheduledRetry struct {
+	item delivery
+	due  time.Time
+}
+
+// dispatcher runs a channel's queue and worker pool, and the retry queue
+// holding failed deliveries until they're due again
+type dispatcher struct {
+	channel string
+	config  DispatchConfig
+	db      *database.DB
+	clock   clock.Clock
+	queue   chan delivery
+	start   sync.Once
+
+	mu      sync.Mutex
+	retries []scheduledRetry
+}
+
+func newDispatcher(channel string, config DispatchConfig, db *database.DB) *dispatcher {
+	defaults := DefaultDispatchConfig()
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.MaxRetryDelay < config.RetryDelay {
+		config.MaxRetryDelay = config.RetryDelay
+	}
+	return &dispatcher{
+		channel: channel,
+		config:  config,
+		db:      db,
+		clock:   clock.Real{},
+		queue:   make(chan delivery, config.QueueSize),
+	}
+}
+
+// run starts the channel's workers and retry queue, once
+func (d *dispatcher) run(ctx context.Context) {
+	d.start.Do(func() {
+		for i := 0; i < d.config.Workers; i++ {
+			go d.work(ctx)
+		}
+		go d.retryLoop(ctx)
+	})
+}
+
+// enqueue queues a delivery without blocking the caller
+func (d *dispatcher) enqueue(item delivery) {
+	select {
+	case d.queue <- item:
+	default:
+		log.Printf("Notifications: %s queue full - dropping %s", d.channel, item.kind)
+		d.deadLetter(item, 0, fmt.Errorf("queue full"))
+	}
+}
+
+func (d *dispatcher) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item := <-d.queue:
+			d.deliver(ctx, item)
+		}
+	}
+}
+
+// deliver makes one attempt at a delivery. A retryable failure is logged
+// as retrying and waits in the retry queue, backing off exponentially,
+// until the delivery runs out of attempts and is dead-lettered.
+func (d *dispatcher) deliver(ctx context.Context, item delivery) {
+	item.attempts++
+	sent, err := item.send()
+	if err == nil {
+		switch {
+		case sent:
+			if item.onSent != nil {
+				item.onSent()
+			}
+			d.record(item, database.NotificationSent, item.attempts, "", nil)
+		case item.logID != 0:
+			// Nowhere to send it any more, e.g. the subscription was removed
+			d.record(item, database.NotificationDeadLetter, item.attempts, "no longer anywhere to send", nil)
+		}
+		return
+	}
+
+	log.Printf("Notifications: %s %s attempt %d/%d failed: %v", d.channel, item.kind, item.attempts, d.config.MaxAttempts, err)
+	if isPermanent(err) || item.attempts >= d.config.MaxAttempts || ctx.Err() != nil {
+		d.deadLetter(item, item.attempts, err)
+		return
+	}
+
+	due := d.clock.Now().Add(d.retryDelay(item.attempts))
+	item.logID = d.record(item, database.NotificationRetrying, item.attempts, redact.Error(err), &due)
+	d.mu.Lock()
+	d.retries = append(d.retries, scheduledRetry{item: item, due: due})
+	d.mu.Unlock()
+}
+
+// retryDelay is the wait after a delivery's nth failed attempt
+func (d *dispatcher) retryDelay(attempts int) time.Duration {
+	delay := d.config.RetryDelay
+	for i := 1; i < attempts && delay < d.config.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.config.MaxRetryDelay {
+		delay = d.config.MaxRetryDelay
+	}
+	return delay
+}
+
+// retryLoop puts failed deliveries back on the queue once they're due,
+// until the context is cancelled
+func (d *dispatcher) retryLoop(ctx context.Context) {
+	ticker := time.NewTicker(retryPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := d.clock.Now()
+		d.mu.Lock()
+		var due []delivery
+		waiting := d.retries[:0]
+		for _, r := range d.retries {
+			if now.Before(r.due) {
+				waiting = append(waiting, r)
+			} else {
+				due = append(due, r.item)
+			}
+		}
+		d.retries = waiting
+		d.mu.Unlock()
+
+		// Retries were accepted once already, so they wait for room rather
+		// than being dropped
+		for _, item := range due {
+			select {
+			case d.queue <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// pendingRetries is how many failed deliveries are waiting to be retried
+func (d *dispatcher) pendingRetries() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.retries)
+}
+
+func (d *dispatcher) deadLetter(item delivery, attempts int, err error) {
+	d.record(item, database.NotificationDeadLetter, attempts, redact.Error(err), nil)
+	if item.onFailed != nil {
+		item.onFailed(err)
+	}
+}
+
+// record writes a delivery's status to the notification log, updating its
+// entry once it has one, and returns the entry's ID
+func (d *dispatcher) record(item delivery, status string, attempts int, errMsg string, nextAttempt *time.Time) int64 {
+	entry := database.NotificationLogEntry{
+		ID:            item.logID,
+		Channel:       d.channel,
+		Kind:          item.kind,
+		Status:        status,
+		Attempts:      attempts,
+		Error:         errMsg,
+		NextAttemptAt: nextAttempt,
+	}
+	if item.logID != 0 {
+		if err := d.db.UpdateNotificationLog(entry); err != nil {
+			log.Printf("Notifications: failed to log %s delivery: %v", d.channel, err)
+		}
+		return item.logID
+	}
+
+	payload, _ := json.Marshal(item.payload)
+	entry.Payload = redact.String(string(payload))
+	id, err := d.db.LogNotification(entry)
+	if err != nil {
+		log.Printf("Notifications: failed to log %s delivery: %v", d.channel, err)
+	}
+	return id
+}
+
+// dispatch queues a delivery on a channel
+func (s *Service) dispatch(channel string, item delivery) {
+	s.dispatchers[channel].enqueue(item)
+}
@@ -0,0 +1,333 @@
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/reports"
+)
+
+// SMTPConfig holds outgoing mail settings
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Configured returns whether enough settings are present to send mail
+func (c SMTPConfig) Configured() bool {
+	return c.Host != "" && c.From != ""
+}
+
+// emailSender delivers HTML email over SMTP
+type emailSender struct {
+	config SMTPConfig
+	clock  clock.Clock
+}
+
+// Send delivers a single HTML message. The subject, which can carry alert
+// text, is MIME-encoded; addresses and extra headers, added verbatim, are
+// refused if they contain a line break, which would start a header of
+// their own.
+func (e *emailSender) Send(to, subject, htmlBody string, headers map[string]string) error {
+	if !e.config.Configured() {
+		return fmt.Errorf("SMTP not configured")
+	}
+	if strings.ContainsAny(e.config.From+to, "\r\n") {
+		return fmt.Errorf("email address contains a line break")
+	}
+	for k, v := range headers {
+		if strings.ContainsAny(k+v, "\r\n") {
+			return fmt.Errorf("email header %q contains a line break", k)
+		}
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", e.clock.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
+	for k, v := range headers {
+		fmt.Fprintf(&msg, "%s: %s\r\n", k, v)
+	}
+	msg.WriteString("\r\n")
+	msg.WriteString(htmlBody)
+
+	addr := fmt.Sprintf("%s:%d", e.config.Host, e.config.Port)
+
+	var auth smtp.Auth
+	if e.config.Username != "" {
+		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+	}
+
+	return smtp.SendMail(addr, auth, e.config.From, []string{to}, msg.Bytes())
+}
+
+// summaryEmailData is the template input for the daily summary and the
+// morning digest
+type summaryEmailData struct {
+	Heading        string
+	Date           string
+	Summary        *reports.DailySummary
+	NoAlerts       string // shown when there are no alerts
+	Reason         string // why the email was sent, e.g. "the daily summary is enabled"
+	UnsubscribeURL string
+	Helpline       string
+}
+
+var emailFuncs = template.FuncMap{
+	"odds": func(price float64) string {
+		return fmt.Sprintf("%+.0f", price)
+	},
+	"point": func(point float64) string {
+		return fmt.Sprintf("%+.1f", point)
+	},
+	"line": func(point float64) string {
+		return fmt.Sprintf("%.1f", point)
+	},
+	"kickoff": func(t time.Time) string {
+		return t.Format("Mon 3:04 PM MST")
+	},
+	"upper": strings.ToUpper,
+}
+
+const tableStyle = `style="border-collapse:collapse;width:100%;font-size:13px;margin-bottom:24px"`
+const cellStyle = `style="border:1px solid #ddd;padding:6px 8px;text-align:left"`
+
+var summaryTemplate = template.Must(template.New("summary").Funcs(emailFuncs).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;color:#222;max-width:720px;margin:0 auto;padding:16px">
+<h2 style="margin-bottom:4px">{{.Heading}}</h2>
+<p style="color:#666;margin-top:0">{{.Date}}</p>
+
+<h3>Best Lines</h3>
+{{if .Summary.Games}}
+<table ` + tableStyle + `>
+<tr>
+<th ` + cellStyle + `>Game</th>
+<th ` + cellStyle + `>Start</th>
+<th ` + cellStyle + `>Moneyline</th>
+<th ` + cellStyle + `>Spread</th>
+<th ` + cellStyle + `>Total</th>
+</tr>
+{{range .Summary.Games}}
+<tr>
+<td ` + cellStyle + `><strong>{{upper .Sport}}</strong> {{.AwayTeam}} @ {{.HomeTeam}}</td>
+<td ` + cellStyle + `>{{kickoff .CommenceTime}}</td>
+<td ` + cellStyle + `>{{with .Comparison.Moneyline}}{{odds .BestAway.Price}} ({{.BestAway.Bookmaker}})<br>{{odds .BestHome.Price}} ({{.BestHome.Bookmaker}}){{else}}-{{end}}</td>
+<td ` + cellStyle + `>{{with .Comparison.Spread}}{{point .BestAway.Point}} {{odds .BestAway.Price}} ({{.BestAway.Bookmaker}})<br>{{point .BestHome.Point}} {{odds .BestHome.Price}} ({{.BestHome.Bookmaker}}){{else}}-{{end}}</td>
+<td ` + cellStyle + `>{{with .Comparison.Total}}O {{line .BestOver.Point}} {{odds .BestOver.Price}} ({{.BestOver.Bookmaker}})<br>U {{line .BestUnder.Point}} {{odds .BestUnder.Price}} ({{.BestUnder.Bookmaker}}){{else}}-{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>No games on today's slate.</p>
+{{end}}
+
+<h3>Active Alerts</h3>
+{{if .Summary.Alerts}}
+<table ` + tableStyle + `>
+<tr>
+<th ` + cellStyle + `>Player</th>
+<th ` + cellStyle + `>Prop</th>
+<th ` + cellStyle + `>Pick</th>
+<th ` + cellStyle + `>Line</th>
+<th ` + cellStyle + `>Avg</th>
+<th ` + cellStyle + `>Confidence</th>
+</tr>
+{{range .Summary.Alerts}}
+<tr>
+<td ` + cellStyle + `>{{.PlayerName}}</td>
+<td ` + cellStyle + `>{{.PropCategory}}</td>
+<td ` + cellStyle + `>{{upper .Direction}}</td>
+<td ` + cellStyle + `>{{line .LineValue}}</td>
+<td ` + cellStyle + `>{{line .AverageValue}}</td>
+<td ` + cellStyle + `>{{.Confidence}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>{{.NoAlerts}}</p>
+{{end}}
+
+<h3>Injuries of Note</h3>
+{{if .Summary.Injuries}}
+<table ` + tableStyle + `>
+<tr>
+<th ` + cellStyle + `>Player</th>
+<th ` + cellStyle + `>Team</th>
+<th ` + cellStyle + `>Status</th>
+<th ` + cellStyle + `>Notes</th>
+</tr>
+{{range .Summary.Injuries}}
+<tr>
+<td ` + cellStyle + `>{{.Player}} ({{.Position}})</td>
+<td ` + cellStyle + `>{{.Team}}</td>
+<td ` + cellStyle + `>{{.Status}}</td>
+<td ` + cellStyle + `>{{.Notes}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>No notable injuries.</p>
+{{end}}
+
+{{if .Helpline}}
+<p style="background:#f5f5f5;border-radius:4px;padding:12px;font-size:13px">{{.Helpline}}</p>
+{{end}}
+
+<p style="color:#999;font-size:12px;margin-top:32px">
+You're receiving this because {{.Reason}} in LineFinder.
+<a href="{{.UnsubscribeURL}}" style="color:#999">Unsubscribe</a>
+</p>
+</body>
+</html>
+`))
+
+// renderSummaryEmail renders the daily summary or digest HTML
+func renderSummaryEmail(data summaryEmailData) (string, error) {
+	var buf bytes.Buffer
+	err := summaryTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// checkDailySummary sends the daily summary once the configured local time
+// has passed, at most once per local day
+func (s *Service) checkDailySummary() {
+	if s.reports == nil || !s.email.config.Configured() {
+		return
+	}
+
+	prefs, err := s.db.GetPreferences()
+	if err != nil || !prefs.EmailSummaryEnabled || prefs.Email == "" {
+		return
+	}
+	// An emailed digest covers the same slate, so it replaces the summary
+	if prefs.DigestEnabled && prefs.DigestChannel(ChannelEmail) {
+		return
+	}
+	// The summary is a betting digest, so a cool-off holds it too
+	if s.coolingOff(prefs) {
+		return
+	}
+
+	loc, err := time.LoadLocation(prefs.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	now := s.clock.Now().In(loc)
+	today := now.Format("2006-01-02")
+
+	sendHour, sendMin := 8, 0
+	fmt.Sscanf(prefs.EmailSummaryTime, "%d:%d", &sendHour, &sendMin)
+	if now.Hour()*60+now.Minute() < sendHour*60+sendMin {
+		return
+	}
+
+	lastSent, err := s.db.GetEmailSummaryLastSent()
+	if err != nil || lastSent == today {
+		return
+	}
+
+	if err := s.SendDailySummary(prefs.Email, prefs.Sports, now); err != nil {
+		log.Printf("Failed to send daily summary: %v", err)
+		return
+	}
+
+	if err := s.db.SetEmailSummaryLastSent(today); err != nil {
+		log.Printf("Failed to record daily summary: %v", err)
+	}
+}
+
+// SendDailySummary builds and emails the daily summary to the given address
+func (s *Service) SendDailySummary(to string, sports []string, now time.Time) error {
+	if s.reports == nil {
+		return fmt.Errorf("reports not configured")
+	}
+
+	summary, err := s.reports.BuildDailySummary(summarySports(sports), 24*time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to build summary: %w", err)
+	}
+
+	token, err := s.db.EnsureUnsubscribeToken()
+	if err != nil {
+		return fmt.Errorf("failed to get unsubscribe token: %w", err)
+	}
+	unsubscribeURL := s.unsubscribeURL(token)
+
+	helpline := ""
+	if prefs, err := s.db.GetPreferences(); err == nil {
+		helpline = s.helplineText(prefs)
+	}
+
+	body, err := renderSummaryEmail(summaryEmailData{
+		Heading:        "LineFinder Daily Summary",
+		Date:           now.Format("Monday, January 2"),
+		Summary:        summary,
+		NoAlerts:       "No value alerts in the last 24 hours.",
+		Reason:         "the daily summary is enabled",
+		UnsubscribeURL: unsubscribeURL,
+		Helpline:       helpline,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render summary: %w", err)
+	}
+
+	subject := fmt.Sprintf("LineFinder: %d games, %d alerts", len(summary.Games), len(summary.Alerts))
+	headers := unsubscribeHeaders(unsubscribeURL)
+
+	// Logged like dispatched email so it counts toward the channel's status
+	summaryLog := delivery{
+		kind:    "daily_summary",
+		payload: map[string]interface{}{"games": len(summary.Games), "alerts": len(summary.Alerts)},
+	}
+	if err := s.email.Send(to, subject, body, headers); err != nil {
+		s.dispatchers[ChannelEmail].deadLetter(summaryLog, 1, err)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	s.dispatchers[ChannelEmail].record(summaryLog, database.NotificationSent, 1, "", nil)
+
+	log.Printf("Daily summary sent to %s (%d games, %d alerts)", to, len(summary.Games), len(summary.Alerts))
+	return nil
+}
+
+// unsubscribeURL returns the one-click link that turns off email
+func (s *Service) unsubscribeURL(token string) string {
+	return fmt.Sprintf("%s/api/v1/email/unsubscribe?token=%s", strings.TrimSuffix(s.config.PublicURL, "/"), token)
+}
+
+// unsubscribeHeaders lets mail clients offer one-click unsubscribe
+func unsubscribeHeaders(unsubscribeURL string) map[string]string {
+	return map[string]string{
+		"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+}
+
+// summarySports maps the preference sport filter to sport keys
+func summarySports(sports []string) []models.Sport {
+	var result []models.Sport
+	for _, s := range sports {
+		if sport, ok := models.ParseSport(s); ok {
+			result = append(result, sport)
+		}
+	}
+	if len(result) == 0 {
+		result = models.SportKeys()
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+package notifications
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// Alert email gets its own hourly budget, rate_limit_email, separate from
+// push. A digest counts once however many alerts it holds.
+const (
+	emailRateLimitChannel = "email"
+	defaultEmailRateLimit = 4
+)
+
+// alertEmailData is the template input for alert email
+type alertEmailData struct {
+	Alerts         []alerts.ValueAlert
+	UnsubscribeURL string
+	Helpline       string
+}
+
+const alertEmailHeader = `<!DOCTYPE html>
+<html>
+<body style="font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;color:#222;max-width:720px;margin:0 auto;padding:16px">
+`
+
+const alertEmailFooter = `{{if .Helpline}}
+<p style="background:#f5f5f5;border-radius:4px;padding:12px;font-size:13px">{{.Helpline}}</p>
+{{end}}
+
+<p style="color:#999;font-size:12px;margin-top:32px">
+You're receiving this because alert email is enabled in LineFinder.
+<a href="{{.UnsubscribeURL}}" style="color:#999">Unsubscribe</a>
+</p>
+</body>
+</html>
+`
+
+// alertEmailTemplate lays out a single alert in full
+var alertEmailTemplate = template.Must(template.New("alert").Funcs(emailFuncs).Parse(alertEmailHeader + `{{with index .Alerts 0}}
+<h2 style="margin-bottom:4px">{{.PlayerName}} {{.PropCategory}} {{upper .Direction}} {{line .Line}}</h2>
+<p style="color:#666;margin-top:0">{{.AwayTeam}} @ {{.HomeTeam}}</p>
+
+<table ` + tableStyle + `>
+<tr><th ` + cellStyle + `>Line</th><td ` + cellStyle + `>{{line .Line}}</td></tr>
+<tr><th ` + cellStyle + `>Average</th><td ` + cellStyle + `>{{line .Average}}</td></tr>
+<tr><th ` + cellStyle + `>Difference</th><td ` + cellStyle + `>{{point .Difference}}</td></tr>
+<tr><th ` + cellStyle + `>Best price</th><td ` + cellStyle + `>{{odds .BestOdds}} ({{.Bookmaker}})</td></tr>
+{{with .MyBook}}<tr><th ` + cellStyle + `>My book</th><td ` + cellStyle + `>{{odds .Price}} ({{.Bookmaker}})</td></tr>
+{{end}}<tr><th ` + cellStyle + `>Confidence</th><td ` + cellStyle + `>{{.Confidence}}</td></tr>
+</table>
+{{if .SourcesDisagree}}<p>Projection sources disagree on this one.</p>{{end}}
+{{end}}
+` + alertEmailFooter))
+
+// digestEmailTemplate lists a batch of alerts in one table
+var digestEmailTemplate = template.Must(template.New("digest").Funcs(emailFuncs).Parse(alertEmailHeader + `<h2>{{len .Alerts}} Value Alerts</h2>
+
+<table ` + tableStyle + `>
+<tr>
+<th ` + cellStyle + `>Player</th>
+<th ` + cellStyle + `>Game</th>
+<th ` + cellStyle + `>Prop</th>
+<th ` + cellStyle + `>Pick</th>
+<th ` + cellStyle + `>Line</th>
+<th ` + cellStyle + `>Avg</th>
+<th ` + cellStyle + `>Best</th>
+<th ` + cellStyle + `>Confidence</th>
+</tr>
+{{range .Alerts}}
+<tr>
+<td ` + cellStyle + `>{{.PlayerName}}</td>
+<td ` + cellStyle + `>{{.AwayTeam}} @ {{.HomeTeam}}</td>
+<td ` + cellStyle + `>{{.PropCategory}}</td>
+<td ` + cellStyle + `>{{upper .Direction}}</td>
+<td ` + cellStyle + `>{{line .Line}}</td>
+<td ` + cellStyle + `>{{line .Average}}</td>
+<td ` + cellStyle + `>{{odds .BestOdds}} ({{.Bookmaker}})</td>
+<td ` + cellStyle + `>{{.Confidence}}</td>
+</tr>
+{{end}}
+</table>
+` + alertEmailFooter))
+
+// renderAlertEmail renders one alert on its own and more as a digest
+func renderAlertEmail(batch []alerts.ValueAlert, unsubscribeURL, helpline string) (string, error) {
+	tmpl := digestEmailTemplate
+	if len(batch) == 1 {
+		tmpl = alertEmailTemplate
+	}
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, alertEmailData{
+		Alerts:         batch,
+		UnsubscribeURL: unsubscribeURL,
+		Helpline:       helpline,
+	})
+	return buf.String(), err
+}
+
+// sendEmailAlerts queues a value alert batch by email, subject to quiet
+// hours and the email rate limit
+func (s *Service) sendEmailAlerts(batch []alerts.ValueAlert) {
+	if len(batch) == 0 || !s.email.config.Configured() {
+		return
+	}
+
+	prefs, err := s.db.GetPreferences()
+	if err != nil {
+		log.Printf("Failed to get preferences for alert email: %v", err)
+		return
+	}
+	if !prefs.EnableEmail || prefs.Email == "" || !prefs.Subscribed(models.CategoryPropValue, models.AlertChannelEmail) {
+		return
+	}
+	if s.isQuietHours() {
+		log.Printf("Quiet hours - skipping email for %d alerts", len(batch))
+		return
+	}
+	if !s.checkRateLimit(emailRateLimitChannel) {
+		log.Printf("Rate limit exceeded - skipping email for %d alerts", len(batch))
+		return
+	}
+
+	token, err := s.db.EnsureUnsubscribeToken()
+	if err != nil {
+		log.Printf("Failed to get unsubscribe token: %v", err)
+		return
+	}
+	unsubscribeURL := s.unsubscribeURL(token)
+
+	body, err := renderAlertEmail(batch, unsubscribeURL, s.helplineText(prefs))
+	if err != nil {
+		log.Printf("Failed to render alert email: %v", err)
+		return
+	}
+
+	to, subject, headers := prefs.Email, "LineFinder: "+s.formatTitle(batch), unsubscribeHeaders(unsubscribeURL)
+	s.dispatch(ChannelEmail, delivery{
+		kind:    "value_alerts",
+		payload: map[string]interface{}{"alerts": len(batch)},
+		send: func() (bool, error) {
+			return true, s.email.Send(to, subject, body, headers)
+		},
+		onSent: func() {
+			s.limits.IncrementRateLimit(emailRateLimitChannel)
+			log.Printf("Alert email sent: %d alerts", len(batch))
+		},
+	})
+}
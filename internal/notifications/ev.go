@@ -0,0 +1,90 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+// NotifyEV delivers prices beating the no-vig consensus. Each goes out over
+// WebSocket as an ev_alert message; push gets one notification for the
+// batch, led by the biggest edge.
+func (s *Service) NotifyEV(opportunities []models.EVOpportunity) {
+	prefs, err := s.db.GetPreferences()
+	if err != nil {
+		log.Printf("Failed to get preferences for EV alert: %v", err)
+		return
+	}
+
+	opportunities = wantedEV(opportunities, prefs)
+	if len(opportunities) == 0 {
+		return
+	}
+
+	allowed := s.allowBettingAlerts(len(opportunities))
+	if allowed == 0 {
+		return
+	}
+	opportunities = capEV(opportunities, allowed)
+
+	if s.hub != nil && prefs.EnableWebsocket && prefs.Subscribed(models.CategoryArbitrage, models.AlertChannelWebSocket) {
+		for _, opp := range opportunities {
+			s.hub.BroadcastMessage(websocket.Message{Type: websocket.MessageTypeEVAlert, Sport: opp.Sport, EV: &opp})
+		}
+	}
+
+	s.publishEV(opportunities)
+	s.sendWebhooks(models.CategoryArbitrage, WebhookEventEVAlerts, opportunities, len(opportunities))
+
+	best := opportunities[0]
+	for _, opp := range opportunities[1:] {
+		if opp.EVPercent > best.EVPercent {
+			best = opp
+		}
+	}
+
+	title := fmt.Sprintf("+EV: %s %+.0f", evSelection(best), best.Price)
+	if len(opportunities) > 1 {
+		title = fmt.Sprintf("%d +EV prices", len(opportunities))
+	}
+	s.pushEvent(alerts.EventAlert{
+		Type:     "ev",
+		Category: models.CategoryArbitrage,
+		Kind:     best.Market,
+		Title:    title,
+		Body: fmt.Sprintf("%s @ %s: %s %+.0f @ %s, %.1f%% EV (fair %+.0f)",
+			best.AwayTeam, best.HomeTeam, evSelection(best), best.Price, best.Bookmaker, best.EVPercent, best.FairPrice),
+		GameID: best.GameID,
+	})
+}
+
+// evSelection describes the side an EV price is on, e.g. "Over 221.5" or
+// "1H Over 110.5"
+func evSelection(opp models.EVOpportunity) string {
+	market, period := models.SplitPeriod(models.Market(opp.Market))
+	selection := opp.Outcome
+	if opp.Point != nil && market == models.MarketSpreads {
+		selection = fmt.Sprintf("%s %+.1f", opp.Outcome, *opp.Point)
+	} else if opp.Point != nil {
+		selection = fmt.Sprintf("%s %.1f", opp.Outcome, *opp.Point)
+	}
+	if period != "" {
+		return period.Label() + " " + selection
+	}
+	return selection
+}
+
+// wantedEV drops prices for sports left out of the preferences
+func wantedEV(opportunities []models.EVOpportunity, prefs *database.Preferences) []models.EVOpportunity {
+	var wanted []models.EVOpportunity
+	for _, opp := range opportunities {
+		if prefs.SportEnabled(opp.Sport) {
+			wanted = append(wanted, opp)
+		}
+	}
+	return wanted
+}
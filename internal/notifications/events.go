@@ -0,0 +1,105 @@
+package notifications
+
+import (
+	"log"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+// News alerts have their own hourly push budget so a busy news day can't
+// crowd out value alerts
+const (
+	newsRateLimitChannel = "push_news"
+	defaultNewsRateLimit = 10
+)
+
+// NotifyEvent delivers an event alert over WebSocket and push. Events are
+// time-sensitive, so they skip batching but still honor quiet hours and
+// rate limits; news uses its own limit, other events share the push limit.
+// A cool-off mutes them along with value alerts, and events for sports
+// left out of the preferences are dropped. Each channel is skipped when the
+// event's category is unsubscribed on it.
+func (s *Service) NotifyEvent(event alerts.EventAlert) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = s.clock.Now()
+	}
+	if event.Category == "" {
+		event.Category = models.EventCategory(event.Type)
+	}
+
+	prefs, err := s.db.GetPreferences()
+	if err != nil {
+		log.Printf("Failed to get preferences for event alert: %v", err)
+		return
+	}
+	if s.coolingOff(prefs) {
+		log.Printf("Cool-off - muting %s event", event.Type)
+		return
+	}
+	if event.Sport != "" && !prefs.SportEnabled(event.Sport) {
+		return
+	}
+	if event.Player != "" && prefs.PlayerMuted(event.Player) {
+		return
+	}
+
+	if s.hub != nil && prefs.EnableWebsocket && prefs.Subscribed(event.Category, models.AlertChannelWebSocket) {
+		s.hub.BroadcastMessage(websocket.Message{Type: websocket.MessageTypeEventAlert, Sport: event.Sport, Event: &event})
+	}
+	s.publishEvent(event)
+
+	s.pushEvent(event)
+}
+
+// pushEvent sends an event alert as a push notification, honoring quiet
+// hours, the event's rate limit and its category's push subscription
+func (s *Service) pushEvent(event alerts.EventAlert) {
+	if !s.config.Enabled || s.config.VAPIDPrivateKey == "" || s.config.VAPIDPublicKey == "" {
+		return
+	}
+	if event.Category == "" {
+		event.Category = models.EventCategory(event.Type)
+	}
+	if !s.subscribed(event.Category, models.AlertChannelPush) {
+		return
+	}
+	if s.isQuietHours() {
+		log.Printf("Quiet hours - skipping push for %s event", event.Type)
+		return
+	}
+	channel := "push"
+	if event.Type == "news" {
+		channel = newsRateLimitChannel
+	}
+	if !s.checkRateLimit(channel) {
+		return
+	}
+
+	url := event.URL
+	if url == "" {
+		url = "/"
+	}
+	tag := event.Type + "-" + event.GameID
+	if event.Player != "" {
+		tag += "-" + event.Player
+	}
+	payload := PushPayload{
+		Title: event.Title,
+		Body:  event.Body,
+		Icon:  "/icon-192.png",
+		Badge: "/badge-72.png",
+		Tag:   categoryTag(event.Category, tag),
+		Data:  PushData{URL: url, Category: event.Category},
+	}
+	s.dispatch(ChannelPush, delivery{
+		kind:    event.Type + "_event",
+		payload: payload,
+		send:    func() (bool, error) { return s.deliverPush(payload) },
+		onSent: func() {
+			s.limits.IncrementRateLimit(channel)
+			log.Printf("Push notification sent: %s event (%s)", event.Type, event.Kind)
+		},
+	})
+}
@@ -0,0 +1,67 @@
+package notifications
+
+import (
+	"log"
+	"sort"
+
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// DefaultHelplineText is added to digests when the show_helpline preference
+// is on, unless Config.HelplineText replaces it
+const DefaultHelplineText = "If you or someone you know has a gambling problem, call or text 1-800-GAMBLER for free, confidential help, 24/7."
+
+// coolingOff reports whether a cool-off is muting betting alerts
+func (s *Service) coolingOff(prefs *database.Preferences) bool {
+	return prefs.CoolOffUntil != nil && s.clock.Now().Before(*prefs.CoolOffUntil)
+}
+
+// allowBettingAlerts returns how many of n betting alerts (value and +EV)
+// may go out: none during a cool-off, otherwise what's left of the daily
+// alert cap, which they're counted against
+func (s *Service) allowBettingAlerts(n int) int {
+	prefs, err := s.db.GetPreferences()
+	if err != nil {
+		return n
+	}
+	if s.coolingOff(prefs) {
+		log.Printf("Cool-off until %s - muting %d alerts", prefs.CoolOffUntil.Format("2006-01-02 15:04"), n)
+		return 0
+	}
+	if prefs.DailyAlertCap <= 0 {
+		return n
+	}
+
+	day := s.clock.Now().In(prefs.Location()).Format("2006-01-02")
+	allowed, err := s.db.ReserveDailyAlerts(day, n, prefs.DailyAlertCap)
+	if err != nil {
+		log.Printf("Failed to check daily alert cap: %v", err)
+		return n
+	}
+	if allowed < n {
+		log.Printf("Daily alert cap of %d reached - muting %d alerts", prefs.DailyAlertCap, n-allowed)
+	}
+	return allowed
+}
+
+// capEV keeps the n biggest edges when the daily cap cuts a batch short
+func capEV(opportunities []models.EVOpportunity, n int) []models.EVOpportunity {
+	if n >= len(opportunities) {
+		return opportunities
+	}
+	sorted := append([]models.EVOpportunity{}, opportunities...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].EVPercent > sorted[j].EVPercent })
+	return sorted[:n]
+}
+
+// helplineText returns the helpline line for digests, or "" when it's off
+func (s *Service) helplineText(prefs *database.Preferences) string {
+	if !prefs.ShowHelpline {
+		return ""
+	}
+	if s.config.HelplineText != "" {
+		return s.config.HelplineText
+	}
+	return DefaultHelplineText
+}
@@ -0,0 +1,36 @@
+package notifications
+
+import (
+	"log"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+// PublishUnreadCount sends the alert inbox's unread count over WebSocket
+// as an alert_inbox message, so every open dashboard can update its badge
+func (s *Service) PublishUnreadCount() {
+	if s.hub == nil {
+		return
+	}
+
+	count, err := s.db.CountUnreadAlerts()
+	if err != nil {
+		log.Printf("Failed to count unread alerts: %v", err)
+		return
+	}
+
+	s.hub.BroadcastMessage(websocket.Message{Type: websocket.MessageTypeAlertInbox, Unread: &count})
+}
+
+// PublishAlertStates sends stored alerts' lifecycle changes over WebSocket as
+// alert_state messages, one per change
+func (s *Service) PublishAlertStates(changes []alerts.StateChange) {
+	if s.hub == nil {
+		return
+	}
+
+	for _, change := range changes {
+		s.hub.BroadcastMessage(websocket.Message{Type: websocket.MessageTypeAlertState, AlertState: &change})
+	}
+}
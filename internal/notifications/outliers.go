@@ -0,0 +1,93 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+// NotifyOutliers delivers bookmakers far off the consensus line or price.
+// Each goes out over WebSocket as an outlier_alert message; push gets one
+// notification for the batch.
+func (s *Service) NotifyOutliers(outliers []models.OutlierLine) {
+	prefs, err := s.db.GetPreferences()
+	if err != nil {
+		log.Printf("Failed to get preferences for outlier alert: %v", err)
+		return
+	}
+
+	outliers = wantedOutliers(outliers, prefs)
+	if len(outliers) == 0 {
+		return
+	}
+
+	allowed := s.allowBettingAlerts(len(outliers))
+	if allowed == 0 {
+		return
+	}
+	if allowed < len(outliers) {
+		outliers = outliers[:allowed]
+	}
+
+	if s.hub != nil && prefs.EnableWebsocket && prefs.Subscribed(models.CategoryArbitrage, models.AlertChannelWebSocket) {
+		for _, o := range outliers {
+			s.hub.BroadcastMessage(websocket.Message{Type: websocket.MessageTypeOutlierAlert, Sport: o.Sport, Outlier: &o})
+		}
+	}
+
+	s.publishOutliers(outliers)
+	s.sendWebhooks(models.CategoryArbitrage, WebhookEventOutlierAlerts, outliers, len(outliers))
+
+	first := outliers[0]
+	title := "Outlier: " + outlierTitle(first)
+	if len(outliers) > 1 {
+		title = fmt.Sprintf("%d outlier lines", len(outliers))
+	}
+	s.pushEvent(alerts.EventAlert{
+		Type:     "outlier",
+		Category: models.CategoryArbitrage,
+		Kind:     first.Market,
+		Title:    title,
+		Body:     outlierBody(first),
+		GameID:   first.GameID,
+	})
+}
+
+// outlierTitle names an outlier's book and side, e.g. "FanDuel Over 224.5 -110"
+func outlierTitle(o models.OutlierLine) string {
+	return fmt.Sprintf("%s %s %+.0f", o.Bookmaker, outlierSelection(o.Outcome, o.Market, o.Point), o.Price)
+}
+
+// outlierBody compares an outlier with the consensus
+func outlierBody(o models.OutlierLine) string {
+	return fmt.Sprintf("%s @ %s: consensus %s %+.0f across %d books",
+		o.AwayTeam, o.HomeTeam, outlierSelection(o.Outcome, o.Market, o.ConsensusPoint), o.ConsensusPrice, o.ConsensusBooks)
+}
+
+// outlierSelection describes a side at a line, e.g. "Celtics -4.5" or
+// "Over 224.5"
+func outlierSelection(outcome, market string, point *float64) string {
+	switch {
+	case point == nil:
+		return outcome
+	case models.Market(market) == models.MarketSpreads:
+		return fmt.Sprintf("%s %+.1f", outcome, *point)
+	default:
+		return fmt.Sprintf("%s %.1f", outcome, *point)
+	}
+}
+
+// wantedOutliers drops outliers for sports left out of the preferences
+func wantedOutliers(outliers []models.OutlierLine, prefs *database.Preferences) []models.OutlierLine {
+	var wanted []models.OutlierLine
+	for _, o := range outliers {
+		if prefs.SportEnabled(o.Sport) {
+			wanted = append(wanted, o)
+		}
+	}
+	return wanted
+}
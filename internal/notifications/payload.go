@@ -0,0 +1,70 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// maxPushPayloadBytes is the largest JSON payload we send. Push services
+// accept about 4KB once encrypted, so this leaves room for the overhead.
+const maxPushPayloadBytes = 3072
+
+// payloadSize returns the size of a payload's JSON encoding
+func payloadSize(payload PushPayload) int {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
+
+// fitPushPayload returns the payload unchanged when it's within the size
+// limit, and otherwise with its embedded alerts trimmed to IDs
+func fitPushPayload(payload PushPayload) PushPayload {
+	if len(payload.Data.Alerts) == 0 || payloadSize(payload) <= maxPushPayloadBytes {
+		return payload
+	}
+	return trimPushPayload(payload)
+}
+
+// trimPushPayload replaces the embedded alerts with their alert_history IDs
+// and a URL the service worker can fetch their details from. If the IDs
+// alone are still too large, the list is cut short; Count keeps the full
+// number of alerts.
+func trimPushPayload(payload PushPayload) PushPayload {
+	var ids []int64
+	for _, a := range payload.Data.Alerts {
+		if a.HistoryID != 0 {
+			ids = append(ids, a.HistoryID)
+		}
+	}
+
+	trimmed := payload
+	trimmed.Data.Alerts = nil
+	trimmed.Data.Truncated = true
+	for {
+		trimmed.Data.AlertIDs = ids
+		trimmed.Data.FetchURL = alertsFetchURL(ids)
+		if len(ids) == 0 || payloadSize(trimmed) <= maxPushPayloadBytes {
+			break
+		}
+		ids = ids[:len(ids)/2]
+	}
+
+	log.Printf("Push payload trimmed: %d alerts embedded as %d IDs", len(payload.Data.Alerts), len(ids))
+	return trimmed
+}
+
+// alertsFetchURL returns the API path that returns details for alert IDs
+func alertsFetchURL(ids []int64) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprint(id)
+	}
+	return "/api/v1/alerts?ids=" + strings.Join(parts, ",")
+}
@@ -0,0 +1,44 @@
+package notifications
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+)
+
+func TestIsQuietHours(t *testing.T) {
+	db, err := database.New(filepath.Join(t.TempDir(), "notifications.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Default preferences are quiet from 23:00 to 08:00 in New York
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	fake := clock.NewFake(time.Time{})
+	s := NewService(Config{}, db, nil)
+	s.SetClock(fake)
+
+	for _, tc := range []struct {
+		hour, minute int
+		quiet        bool
+	}{
+		{22, 59, false},
+		{23, 0, true},
+		{3, 0, true},
+		{7, 59, true},
+		{8, 0, false},
+		{12, 0, false},
+	} {
+		fake.Set(time.Date(2026, 1, 15, tc.hour, tc.minute, 0, 0, loc))
+		if got := s.isQuietHours(); got != tc.quiet {
+			t.Errorf("%02d:%02d: quiet %v, want %v", tc.hour, tc.minute, got, tc.quiet)
+		}
+	}
+}
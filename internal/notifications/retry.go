@@ -0,0 +1,101 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+)
+
+// failedBatch holds alerts from earlier pushes that failed with a retryable
+// error, claimed from pending_notifications for another attempt
+type failedBatch struct {
+	id     string
+	ids    []int64
+	alerts []alerts.ValueAlert
+}
+
+// claimFailedAlerts expires failed alerts older than the pending TTL and
+// claims the rest for the next push. Returns nil when there is nothing to
+// retry.
+func (s *Service) claimFailedAlerts() *failedBatch {
+	cutoff := s.clock.Now().Add(-s.config.PendingTTL)
+	if expired, err := s.pending.ExpirePendingNotifications(cutoff); err != nil {
+		log.Printf("Failed to expire pending notifications: %v", err)
+	} else if expired > 0 {
+		log.Printf("Expired %d undelivered alerts older than %v", expired, s.config.PendingTTL)
+	}
+
+	pending, err := s.pending.GetPendingNotifications()
+	if err != nil {
+		log.Printf("Failed to load pending notifications: %v", err)
+		return nil
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	fb := &failedBatch{id: fmt.Sprintf("retry-%d", s.clock.Now().UnixNano())}
+	var corrupt []int64
+	for _, n := range pending {
+		var alert alerts.ValueAlert
+		if err := json.Unmarshal([]byte(n.AlertJSON), &alert); err != nil {
+			corrupt = append(corrupt, n.ID)
+			continue
+		}
+		fb.ids = append(fb.ids, n.ID)
+		fb.alerts = append(fb.alerts, alert)
+	}
+	if len(corrupt) > 0 {
+		log.Printf("Dropping %d unreadable pending notifications", len(corrupt))
+		s.pending.ClearPendingNotifications(corrupt)
+	}
+	if len(fb.ids) == 0 {
+		return nil
+	}
+
+	if err := s.pending.ClaimPendingNotifications(fb.ids, fb.id); err != nil {
+		log.Printf("Failed to claim pending notifications: %v", err)
+		return nil
+	}
+	return fb
+}
+
+// releaseFailedAlerts returns claimed alerts to the queue for a later attempt
+func (s *Service) releaseFailedAlerts(fb *failedBatch) {
+	if fb == nil {
+		return
+	}
+	if err := s.pending.ReleasePendingNotifications(fb.id); err != nil {
+		log.Printf("Failed to release pending notifications: %v", err)
+	}
+}
+
+// clearFailedAlerts removes claimed alerts once they're delivered or given up on
+func (s *Service) clearFailedAlerts(fb *failedBatch) {
+	if fb == nil {
+		return
+	}
+	if err := s.pending.ClearPendingNotifications(fb.ids); err != nil {
+		log.Printf("Failed to clear pending notifications: %v", err)
+	}
+}
+
+// saveFailedAlerts stores alerts from a failed push so a later batch
+// retries them until they expire
+func (s *Service) saveFailedAlerts(batch []alerts.ValueAlert) {
+	if len(batch) == 0 {
+		return
+	}
+	for _, alert := range batch {
+		alertJSON, err := json.Marshal(alert)
+		if err != nil {
+			continue
+		}
+		if err := s.pending.AddPendingNotification(string(alertJSON)); err != nil {
+			log.Printf("Failed to save undelivered alert: %v", err)
+		}
+	}
+	log.Printf("Saved %d undelivered alerts for retry", len(batch))
+}
@@ -5,12 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"sync"
 	"time"
 
 	webpush "github.com/SherClockHolmes/webpush-go"
 	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/alertstream"
+	"github.com/joshuakim/linefinder/internal/clock"
 	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/reports"
+	"github.com/joshuakim/linefinder/internal/telegram"
 	"github.com/joshuakim/linefinder/internal/websocket"
 )
 
@@ -24,6 +30,26 @@ type Config struct {
 	// Batching
 	BatchInterval time.Duration
 
+	// PendingTTL is how long alerts from a failed push are kept for retry
+	// before they're dropped as stale
+	PendingTTL time.Duration
+
+	// Email delivery
+	SMTP SMTPConfig
+
+	// Telegram delivery
+	Telegram TelegramConfig
+
+	// PublicURL is the externally reachable base URL, used for links in emails
+	PublicURL string
+
+	// Dispatch queue and retry settings, shared by every channel
+	Dispatch DispatchConfig
+
+	// HelplineText is added to digests when the show_helpline preference is
+	// on; empty uses DefaultHelplineText
+	HelplineText string
+
 	// Enable/disable
 	Enabled bool
 }
@@ -32,6 +58,10 @@ type Config struct {
 func DefaultConfig() Config {
 	return Config{
 		BatchInterval: 60 * time.Second,
+		PendingTTL:    2 * time.Hour,
+		SMTP:          SMTPConfig{Port: 587},
+		PublicURL:     "http://localhost:8080",
+		Dispatch:      DefaultDispatchConfig(),
 		Enabled:       true,
 	}
 }
@@ -41,24 +71,102 @@ type Service struct {
 	config Config
 	db     *database.DB
 	hub    *websocket.Hub
+	clock  clock.Clock
+
+	// Hourly send counts and the queue of alerts to retry, shared by
+	// every instance
+	limits  database.RateLimitStore
+	pending database.PendingNotificationStore
+
+	// Email and reports
+	email   *emailSender
+	reports *reports.Builder
+
+	// playerContext describes a player's situation for push bodies
+	playerContext func(alerts.ValueAlert) string
+
+	// Per-channel delivery queues
+	dispatchers map[string]*dispatcher
+
+	// Client for webhook and Discord posts
+	httpClient *http.Client
+
+	// Telegram bot client, when configured
+	telegram *telegram.Client
+
+	// Unified feed of every alert type, when set
+	stream *alertstream.Stream
 
 	// Pending alerts for batching
 	mu            sync.Mutex
 	pendingAlerts []alerts.ValueAlert
 
 	// Control
-	stopCh chan struct{}
+	stopCh        chan struct{}
+	batchInterval chan time.Duration
 }
 
 // NewService creates a new notification service
 func NewService(config Config, db *database.DB, hub *websocket.Hub) *Service {
+	var telegramClient *telegram.Client
+	if config.Telegram.Configured() {
+		telegramClient = telegram.NewClient(config.Telegram.BotToken)
+	}
 	return &Service{
 		config:        config,
 		db:            db,
 		hub:           hub,
+		clock:         clock.Real{},
+		limits:        db,
+		pending:       db,
+		email:         &emailSender{config: config.SMTP, clock: clock.Real{}},
 		pendingAlerts: make([]alerts.ValueAlert, 0),
+		dispatchers: map[string]*dispatcher{
+			ChannelPush:     newDispatcher(ChannelPush, config.Dispatch, db),
+			ChannelEmail:    newDispatcher(ChannelEmail, config.Dispatch, db),
+			ChannelWebhook:  newDispatcher(ChannelWebhook, config.Dispatch, db),
+			ChannelDiscord:  newDispatcher(ChannelDiscord, config.Dispatch, db),
+			ChannelTelegram: newDispatcher(ChannelTelegram, config.Dispatch, db),
+		},
+		httpClient:    &http.Client{Timeout: webhookTimeout},
+		telegram:      telegramClient,
 		stopCh:        make(chan struct{}),
+		batchInterval: make(chan time.Duration, 1),
+	}
+}
+
+// SetClock sets the clock used for quiet hours and scheduled summaries
+func (s *Service) SetClock(c clock.Clock) {
+	s.clock = c
+	s.email.clock = c
+	for _, d := range s.dispatchers {
+		d.clock = c
+	}
+}
+
+// SetReportBuilder sets the report builder used for the daily email summary
+// and the morning digest
+func (s *Service) SetReportBuilder(builder *reports.Builder) {
+	s.reports = builder
+}
+
+// SetPlayerContext sets a function describing an alert's player, such as
+// their depth chart role, added to push notification bodies
+func (s *Service) SetPlayerContext(fn func(alerts.ValueAlert) string) {
+	s.playerContext = fn
+}
+
+// contextFor returns the player context for an alert, or ""
+func (s *Service) contextFor(a alerts.ValueAlert) string {
+	if s.playerContext == nil {
+		return ""
 	}
+	return s.playerContext(a)
+}
+
+// EmailConfigured returns whether SMTP delivery is configured
+func (s *Service) EmailConfigured() bool {
+	return s.email.config.Configured()
 }
 
 // Start starts the batch processing loop
@@ -70,6 +178,26 @@ func (s *Service) Start(ctx context.Context) {
 	ticker := time.NewTicker(s.config.BatchInterval)
 	defer ticker.Stop()
 
+	// Daily summary and digest are checked every minute against their
+	// preferred send times
+	summaryTicker := time.NewTicker(time.Minute)
+	defer summaryTicker.Stop()
+
+	for _, d := range s.dispatchers {
+		d.run(ctx)
+	}
+
+	// Claims left by a previous run will never be delivered or released
+	if err := s.pending.ReleasePendingNotifications(""); err != nil {
+		log.Printf("Failed to release pending notifications: %v", err)
+	}
+	// Nor will its retries, which were held in memory
+	if abandoned, err := s.db.AbandonRetryingNotifications(); err != nil {
+		log.Printf("Failed to abandon interrupted retries: %v", err)
+	} else if abandoned > 0 {
+		log.Printf("Dead-lettered %d deliveries whose retries were interrupted by a restart", abandoned)
+	}
+
 	log.Printf("Notification service started (batch interval: %v)", s.config.BatchInterval)
 
 	for {
@@ -83,10 +211,35 @@ func (s *Service) Start(ctx context.Context) {
 			return
 		case <-ticker.C:
 			s.processBatch()
+		case d := <-s.batchInterval:
+			s.config.BatchInterval = d
+			ticker.Reset(d)
+			log.Printf("Notification batch interval set to %v", d)
+		case <-summaryTicker.C:
+			s.checkDailySummary()
+			s.checkDigest()
 		}
 	}
 }
 
+// SetBatchInterval changes how often batched alerts are sent, restarting
+// the batch timer. It's called when the batch_interval_seconds preference
+// is saved; NOTIFICATION_BATCH_SECONDS sets it at startup.
+func (s *Service) SetBatchInterval(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	// Only the latest interval matters
+	select {
+	case <-s.batchInterval:
+	default:
+	}
+	select {
+	case s.batchInterval <- d:
+	default:
+	}
+}
+
 // Stop stops the notification service
 func (s *Service) Stop() {
 	close(s.stopCh)
@@ -98,14 +251,26 @@ func (s *Service) QueueAlert(alert alerts.ValueAlert) {
 		return
 	}
 
+	// Alerts under the preferred confidence, for unwanted sports or for
+	// muted players go nowhere
+	if prefs, err := s.db.GetPreferences(); err == nil && (!alerts.MeetsConfidence(alert.Confidence, prefs.MinConfidence) || !prefs.SportEnabled(alert.Sport) || prefs.PlayerMuted(alert.PlayerName)) {
+		return
+	}
+
+	// Cool-off and the daily alert cap mute alerts before any channel
+	if s.allowBettingAlerts(1) == 0 {
+		return
+	}
+
 	s.mu.Lock()
 	s.pendingAlerts = append(s.pendingAlerts, alert)
 	s.mu.Unlock()
 
 	log.Printf("Alert queued: %s %s %s", alert.PlayerName, alert.PropCategory, alert.Direction)
 
-	// Send immediately via WebSocket
+	// Send immediately via WebSocket and the alert stream
 	s.sendWebSocket(alert)
+	s.publishValue(alert)
 }
 
 // QueueAlerts adds multiple alerts to the pending batch
@@ -113,37 +278,56 @@ func (s *Service) QueueAlerts(alertsList []alerts.ValueAlert) {
 	for _, alert := range alertsList {
 		s.QueueAlert(alert)
 	}
+	if len(alertsList) > 0 {
+		s.PublishUnreadCount()
+	}
 }
 
 // processBatch processes pending alerts and sends push notification
 func (s *Service) processBatch() {
-	s.mu.Lock()
-	if len(s.pendingAlerts) == 0 {
-		s.mu.Unlock()
-		return
-	}
-
 	// Take the pending alerts
+	s.mu.Lock()
 	batch := s.pendingAlerts
 	s.pendingAlerts = make([]alerts.ValueAlert, 0)
 	s.mu.Unlock()
 
+	// Webhooks get every batch as it is; retries are per delivery
+	s.sendWebhooks(models.CategoryPropValue, WebhookEventValueAlerts, batch, len(batch))
+
+	// Discord, Telegram and email check quiet hours and their own rate limits
+	s.sendDiscord(batch)
+	s.sendTelegram(batch)
+	s.sendEmailAlerts(batch)
+
+	// Alerts from earlier pushes that failed go out with this batch
+	failed := s.claimFailedAlerts()
+	if len(batch) == 0 && failed == nil {
+		return
+	}
+
+	// Without a push subscription to the category, earlier failures are
+	// dropped along with the batch
+	if !s.subscribed(models.CategoryPropValue, models.AlertChannelPush) {
+		s.clearFailedAlerts(failed)
+		return
+	}
+
 	// Check if we're in quiet hours
 	if s.isQuietHours() {
 		log.Printf("Quiet hours - skipping push for %d alerts", len(batch))
+		s.releaseFailedAlerts(failed)
 		return
 	}
 
 	// Check rate limit
 	if !s.checkRateLimit("push") {
 		log.Printf("Rate limit exceeded - skipping push for %d alerts", len(batch))
+		s.releaseFailedAlerts(failed)
 		return
 	}
 
-	// Send push notification
-	if err := s.sendPush(batch); err != nil {
-		log.Printf("Failed to send push notification: %v", err)
-	}
+	// Queue the push so a slow push service doesn't hold up the batch cycle
+	s.sendPush(batch, failed)
 }
 
 // sendWebSocket sends an alert via WebSocket
@@ -153,95 +337,175 @@ func (s *Service) sendWebSocket(alert alerts.ValueAlert) {
 	}
 
 	prefs, err := s.db.GetPreferences()
-	if err != nil || !prefs.EnableWebsocket {
+	if err != nil || !prefs.EnableWebsocket || !prefs.Subscribed(alert.Category, models.AlertChannelWebSocket) {
 		return
 	}
 
-	// Create WebSocket message
-	msg := websocket.Message{
-		Type:      "value_alert",
-		Timestamp: time.Now(),
-	}
+	// Clients subscribed to the alerts topic get it, whatever their sport
+	s.hub.BroadcastValueAlert(alert)
+}
 
-	// Marshal alert data
-	alertData, _ := json.Marshal(alert)
-	msg.Status = string(alertData) // Using Status field to carry alert data
+// ActiveValueAlerts returns the value alerts whose edge still stands and
+// that WebSocket clients would be sent now, oldest first, for replaying to
+// clients subscribing to alerts
+func (s *Service) ActiveValueAlerts() []alerts.ValueAlert {
+	prefs, err := s.db.GetPreferences()
+	if err != nil || !prefs.EnableWebsocket {
+		return nil
+	}
+	live, err := s.db.GetLiveAlerts()
+	if err != nil {
+		log.Printf("Failed to load live alerts for replay: %v", err)
+		return nil
+	}
 
-	// Broadcast to all connected clients
-	// Since we have single user, broadcast to all sports
-	s.hub.BroadcastStatus(fmt.Sprintf("value_alert:%s", string(alertData)))
+	var active []alerts.ValueAlert
+	for _, h := range live {
+		// Rows recorded before the full alert was stored are left out
+		var alert alerts.ValueAlert
+		if h.AlertJSON == "" || json.Unmarshal([]byte(h.AlertJSON), &alert) != nil {
+			continue
+		}
+		alert.HistoryID = h.ID
+		alert.State = h.State
+		alert.Category = models.CategoryPropValue
+		// Re-checks update the stored line and confidence
+		alert.Line = h.LineValue
+		alert.Confidence = h.Confidence
+		if !prefs.Subscribed(alert.Category, models.AlertChannelWebSocket) || !prefs.SportEnabled(alert.Sport) || prefs.PlayerMuted(alert.PlayerName) {
+			continue
+		}
+		active = append(active, alert)
+	}
+	return active
 }
 
-// sendPush sends a batched push notification
-func (s *Service) sendPush(batch []alerts.ValueAlert) error {
+// sendPush queues a batched push notification. Alerts retried from failed
+// pushes are cleared once delivered; when the push fails with a retryable
+// error they're released and the new alerts are saved to go out later.
+func (s *Service) sendPush(batch []alerts.ValueAlert, failed *failedBatch) {
 	if s.config.VAPIDPrivateKey == "" || s.config.VAPIDPublicKey == "" {
 		log.Println("VAPID keys not configured - skipping push")
-		return nil
-	}
-
-	prefs, err := s.db.GetPreferences()
-	if err != nil {
-		return fmt.Errorf("failed to get preferences: %w", err)
+		s.releaseFailedAlerts(failed)
+		return
 	}
 
-	if !prefs.EnablePush || prefs.PushSubscription == "" {
-		return nil
+	all := batch
+	if failed != nil {
+		all = append(append([]alerts.ValueAlert{}, failed.alerts...), batch...)
 	}
 
 	// Create notification payload
 	payload := PushPayload{
-		Title: s.formatTitle(batch),
-		Body:  s.formatBody(batch),
+		Title: s.formatTitle(all),
+		Body:  s.formatBody(all),
 		Icon:  "/icon-192.png",
 		Badge: "/badge-72.png",
-		Tag:   "value-alerts",
+		Tag:   categoryTag(models.CategoryPropValue, "value-alerts"),
 		Data: PushData{
-			URL:    "/",
-			Alerts: batch,
-			Count:  len(batch),
+			URL:      "/",
+			Category: models.CategoryPropValue,
+			Alerts:   all,
+			Count:    len(all),
 		},
 	}
 
-	payloadJSON, err := json.Marshal(payload)
+	s.dispatch(ChannelPush, delivery{
+		kind:    "value_alerts",
+		payload: payload,
+		send:    func() (bool, error) { return s.deliverPush(payload) },
+		onSent: func() {
+			// Increment rate limit
+			s.limits.IncrementRateLimit("push")
+			s.clearFailedAlerts(failed)
+			log.Printf("Push notification sent: %d alerts", len(all))
+		},
+		onFailed: func(err error) {
+			if isPermanent(err) {
+				s.clearFailedAlerts(failed)
+				return
+			}
+			s.releaseFailedAlerts(failed)
+			s.saveFailedAlerts(batch)
+		},
+	})
+}
+
+// deliverPush sends a payload to the stored push subscription. Returns false
+// without error when push is disabled or there is no subscription.
+func (s *Service) deliverPush(payload PushPayload) (bool, error) {
+	prefs, err := s.db.GetPreferences()
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return false, fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	if !prefs.EnablePush || prefs.PushSubscription == "" {
+		return false, nil
 	}
 
 	// Parse subscription
 	sub := &webpush.Subscription{}
 	if err := json.Unmarshal([]byte(prefs.PushSubscription), sub); err != nil {
-		return fmt.Errorf("failed to parse subscription: %w", err)
+		return false, permanent(fmt.Errorf("failed to parse subscription: %w", err))
 	}
 
-	// Send push notification
-	resp, err := webpush.SendNotification(payloadJSON, sub, &webpush.Options{
-		Subscriber:      s.config.VAPIDSubject,
-		VAPIDPublicKey:  s.config.VAPIDPublicKey,
-		VAPIDPrivateKey: s.config.VAPIDPrivateKey,
-		TTL:             3600, // 1 hour
-	})
+	payload = fitPushPayload(payload)
+	status, err := s.sendPushPayload(sub, payload)
 	if err != nil {
-		return fmt.Errorf("failed to send push: %w", err)
+		return false, err
+	}
+
+	// The push service's limit can be lower than ours: retry once with the
+	// alerts trimmed to IDs
+	if status == http.StatusRequestEntityTooLarge && len(payload.Data.Alerts) > 0 {
+		log.Println("Push payload rejected as too large - retrying trimmed")
+		status, err = s.sendPushPayload(sub, trimPushPayload(payload))
+		if err != nil {
+			return false, err
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
+	if status >= 400 {
 		// Subscription might be invalid
-		if resp.StatusCode == 410 || resp.StatusCode == 404 {
+		if status == 410 || status == 404 {
 			log.Println("Push subscription expired/invalid - disabling")
 			s.db.UpdatePreferences(&database.Preferences{
 				EnablePush:       false,
 				PushSubscription: "",
 			})
 		}
-		return fmt.Errorf("push failed with status %d", resp.StatusCode)
+		err := fmt.Errorf("push failed with status %d", status)
+		// Server errors and throttling are worth retrying; other client
+		// errors mean the request itself was rejected
+		if status < 500 && status != http.StatusTooManyRequests {
+			return false, permanent(err)
+		}
+		return false, err
 	}
 
-	// Increment rate limit
-	s.db.IncrementRateLimit("push")
+	return true, nil
+}
+
+// sendPushPayload sends one payload to a subscription, returning the push
+// service's response status
+func (s *Service) sendPushPayload(sub *webpush.Subscription, payload PushPayload) (int, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return 0, permanent(fmt.Errorf("failed to marshal payload: %w", err))
+	}
 
-	log.Printf("Push notification sent: %d alerts", len(batch))
-	return nil
+	resp, err := webpush.SendNotification(payloadJSON, sub, &webpush.Options{
+		Subscriber:      s.config.VAPIDSubject,
+		VAPIDPublicKey:  s.config.VAPIDPublicKey,
+		VAPIDPrivateKey: s.config.VAPIDPrivateKey,
+		TTL:             3600, // 1 hour
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to send push: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
 }
 
 // formatTitle creates the push notification title
@@ -272,8 +536,18 @@ func (s *Service) formatBody(batch []alerts.ValueAlert) string {
 		if a.Direction == alerts.DirectionUnder {
 			dir = "UNDER"
 		}
-		return fmt.Sprintf("%s %.1f (avg %.1f, diff %.1f). Best: %+.0f @ %s",
+		body := fmt.Sprintf("%s %.1f (avg %.1f, diff %.1f). Best: %+.0f @ %s",
 			dir, a.Line, a.Average, a.AbsDifference, a.BestOdds, a.Bookmaker)
+		if mb := a.MyBook; mb != nil && mb.CentsGivenUp > 0 {
+			body += fmt.Sprintf(". %s: %+.0f (%.0f¢ behind best)", mb.Bookmaker, mb.Price, mb.CentsGivenUp)
+		}
+		if a.SourcesDisagree {
+			body += ". Projection sources disagree"
+		}
+		if ctx := s.contextFor(a); ctx != "" {
+			body += fmt.Sprintf(" [%s]", ctx)
+		}
+		return body
 	}
 
 	// Summary for multiple alerts
@@ -286,7 +560,11 @@ func (s *Service) formatBody(batch []alerts.ValueAlert) string {
 		if a.Direction == alerts.DirectionUnder {
 			dir = "U"
 		}
-		lines = append(lines, fmt.Sprintf("%s %s %.1f (%s)", a.PlayerName, a.PropCategory, a.Line, dir))
+		name := a.PlayerName
+		if ctx := s.contextFor(a); ctx != "" {
+			name += fmt.Sprintf(" [%s]", ctx)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %.1f (%s)", name, a.PropCategory, a.Line, dir))
 	}
 
 	body := ""
@@ -316,7 +594,7 @@ func (s *Service) isQuietHours() bool {
 		loc = time.Local
 	}
 
-	now := time.Now().In(loc)
+	now := s.clock.Now().In(loc)
 	currentMinutes := now.Hour()*60 + now.Minute()
 
 	// Parse quiet start
@@ -347,7 +625,29 @@ func (s *Service) checkRateLimit(channel string) bool {
 	}
 
 	limit := prefs.RateLimitPush
-	canSend, remaining, err := s.db.CheckRateLimit(channel, limit)
+	switch channel {
+	case newsRateLimitChannel:
+		limit = prefs.RateLimitNews
+		if limit <= 0 {
+			limit = defaultNewsRateLimit
+		}
+	case discordRateLimitChannel:
+		limit = prefs.RateLimitDiscord
+		if limit <= 0 {
+			limit = defaultDiscordRateLimit
+		}
+	case emailRateLimitChannel:
+		limit = prefs.RateLimitEmail
+		if limit <= 0 {
+			limit = defaultEmailRateLimit
+		}
+	case telegramRateLimitChannel:
+		limit = prefs.RateLimitTelegram
+		if limit <= 0 {
+			limit = defaultTelegramRateLimit
+		}
+	}
+	canSend, remaining, err := s.limits.CheckRateLimit(channel, limit)
 	if err != nil {
 		log.Printf("Rate limit check error: %v", err)
 		return true
@@ -379,7 +679,14 @@ type PushPayload struct {
 
 // PushData represents custom data in push notification
 type PushData struct {
-	URL    string              `json:"url,omitempty"`
-	Alerts []alerts.ValueAlert `json:"alerts,omitempty"`
-	Count  int                 `json:"count"`
+	URL      string              `json:"url,omitempty"`
+	Category string              `json:"category,omitempty"` // see models.AlertCategories
+	Alerts   []alerts.ValueAlert `json:"alerts,omitempty"`
+	Count    int                 `json:"count"`
+
+	// Set when the alerts were too large to embed: AlertIDs lists their
+	// alert_history IDs and FetchURL returns their details
+	Truncated bool    `json:"truncated,omitempty"`
+	AlertIDs  []int64 `json:"alert_ids,omitempty"`
+	FetchURL  string  `json:"fetch_url,omitempty"`
 }
@@ -0,0 +1,273 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/redact"
+)
+
+// statusCheckTimeout bounds each channel's live check
+const statusCheckTimeout = 5 * time.Second
+
+// ChannelStatus is a delivery channel's health: whether it's set up,
+// whether a live check of its configuration passes, and how its recent
+// deliveries went
+type ChannelStatus struct {
+	Channel string `json:"channel"`
+
+	// Configured is whether the server has what the channel needs, such as
+	// VAPID keys or SMTP settings. Enabled is whether preferences turn it on
+	// and give it somewhere to deliver.
+	Configured bool `json:"configured"`
+	Enabled    bool `json:"enabled"`
+
+	// OK is whether the live check passed; Error says why it didn't
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+
+	// Webhooks holds each enabled webhook's ping result
+	Webhooks []WebhookStatus `json:"webhooks,omitempty"`
+
+	// Retrying is how many failed deliveries are waiting to be retried
+	Retrying int `json:"retrying"`
+
+	database.NotificationChannelStats
+}
+
+// WebhookStatus is the result of pinging one webhook
+type WebhookStatus struct {
+	ID             int64  `json:"id"`
+	URL            string `json:"url"`
+	OK             bool   `json:"ok"`
+	ResponseStatus int    `json:"response_status,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// ChannelStatuses checks every delivery channel, returning them with their
+// delivery stats since the cutoff. The checks run together:
+//   - push: the VAPID keys parse and form a pair
+//   - email: the SMTP server answers
+//   - webhook: each enabled webhook accepts a signed "ping" event
+//   - discord: the webhook URL or bot channel can be read (nothing is posted)
+//   - telegram: the bot token is accepted (nothing is posted)
+func (s *Service) ChannelStatuses(since time.Time) ([]ChannelStatus, error) {
+	prefs, err := s.db.GetPreferences()
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := s.db.GetWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.db.GetNotificationStats(since)
+	if err != nil {
+		return nil, err
+	}
+
+	var enabledHooks []database.Webhook
+	for _, hook := range webhooks {
+		if hook.Enabled {
+			enabledHooks = append(enabledHooks, hook)
+		}
+	}
+
+	statuses := []ChannelStatus{
+		{
+			Channel:    ChannelPush,
+			Configured: s.config.VAPIDPublicKey != "" && s.config.VAPIDPrivateKey != "",
+			Enabled:    prefs.EnablePush && prefs.PushSubscription != "",
+		},
+		{
+			Channel:    ChannelEmail,
+			Configured: s.email.config.Configured(),
+			Enabled:    prefs.Email != "" && (prefs.EnableEmail || prefs.EmailSummaryEnabled),
+		},
+		{
+			Channel:    ChannelWebhook,
+			Configured: len(enabledHooks) > 0,
+			Enabled:    len(enabledHooks) > 0,
+		},
+		{
+			Channel:    ChannelDiscord,
+			Configured: discordConfigured(prefs),
+			Enabled:    prefs.EnableDiscord && discordConfigured(prefs),
+		},
+		{
+			Channel:    ChannelTelegram,
+			Configured: s.telegram != nil,
+			Enabled:    prefs.EnableTelegram && s.telegram != nil,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), statusCheckTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := range statuses {
+		st := &statuses[i]
+		st.NotificationChannelStats = stats[st.Channel]
+		st.Retrying = s.dispatchers[st.Channel].pendingRetries()
+		if !st.Configured {
+			st.Error = "not configured"
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			switch st.Channel {
+			case ChannelPush:
+				err = checkVAPIDKeys(s.config.VAPIDPublicKey, s.config.VAPIDPrivateKey)
+			case ChannelEmail:
+				err = s.checkSMTP(ctx)
+			case ChannelWebhook:
+				st.Webhooks = s.pingWebhooks(ctx, enabledHooks)
+				for _, hook := range st.Webhooks {
+					if !hook.OK {
+						err = fmt.Errorf("webhook %d failed its ping", hook.ID)
+						break
+					}
+				}
+			case ChannelDiscord:
+				err = s.checkDiscord(ctx, prefs)
+			case ChannelTelegram:
+				err = s.checkTelegram(ctx)
+			}
+			st.OK = err == nil
+			if err != nil {
+				st.Error = redact.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return statuses, nil
+}
+
+// checkVAPIDKeys checks that the keys decode to a P-256 key pair
+func checkVAPIDKeys(publicKey, privateKey string) error {
+	priv, err := base64.RawURLEncoding.DecodeString(privateKey)
+	if err != nil {
+		return fmt.Errorf("VAPID private key isn't base64url: %w", err)
+	}
+	pub, err := base64.RawURLEncoding.DecodeString(publicKey)
+	if err != nil {
+		return fmt.Errorf("VAPID public key isn't base64url: %w", err)
+	}
+
+	key, err := ecdh.P256().NewPrivateKey(priv)
+	if err != nil {
+		return fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	if _, err := ecdh.P256().NewPublicKey(pub); err != nil {
+		return fmt.Errorf("invalid VAPID public key: %w", err)
+	}
+	if !bytes.Equal(key.PublicKey().Bytes(), pub) {
+		return fmt.Errorf("VAPID public key doesn't match the private key")
+	}
+	return nil
+}
+
+// checkSMTP connects to the SMTP server and exchanges greetings
+func (s *Service) checkSMTP(ctx context.Context) error {
+	addr := net.JoinHostPort(s.email.config.Host, strconv.Itoa(s.email.config.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("SMTP server unreachable: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.email.config.Host)
+	if err != nil {
+		return fmt.Errorf("SMTP server didn't greet: %w", err)
+	}
+	defer client.Close()
+	if err := client.Hello("localhost"); err != nil {
+		return fmt.Errorf("SMTP server rejected EHLO: %w", err)
+	}
+	return client.Quit()
+}
+
+// pingWebhooks POSTs a signed ping event to each webhook. Pings aren't
+// recorded as deliveries.
+func (s *Service) pingWebhooks(ctx context.Context, webhooks []database.Webhook) []WebhookStatus {
+	body, _ := json.Marshal(WebhookPayload{
+		Event:     WebhookEventPing,
+		CreatedAt: s.clock.Now(),
+		Alerts:    []interface{}{},
+	})
+
+	results := make([]WebhookStatus, len(webhooks))
+	var wg sync.WaitGroup
+	for i, hook := range webhooks {
+		results[i] = WebhookStatus{ID: hook.ID, URL: hook.URL}
+		wg.Add(1)
+		go func(result *WebhookStatus, hook database.Webhook) {
+			defer wg.Done()
+			req, err := s.newWebhookRequest(hook, WebhookEventPing, 0, body)
+			if err != nil {
+				result.Error = redact.Error(err)
+				return
+			}
+			resp, err := s.httpClient.Do(req.WithContext(ctx))
+			if err != nil {
+				result.Error = redact.Error(err)
+				return
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+
+			result.ResponseStatus = resp.StatusCode
+			result.OK = resp.StatusCode >= 200 && resp.StatusCode < 300
+			if !result.OK {
+				result.Error = fmt.Sprintf("webhook returned %s", resp.Status)
+			}
+		}(&results[i], hook)
+	}
+	wg.Wait()
+	return results
+}
+
+// checkDiscord reads the Discord webhook, or the bot's channel, which
+// confirms the URL or token and channel ID are good without posting
+func (s *Service) checkDiscord(ctx context.Context, prefs *database.Preferences) error {
+	url := prefs.DiscordWebhookURL
+	if url == "" {
+		url = fmt.Sprintf("%s/channels/%s", discordAPIBase, prefs.DiscordChannelID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if prefs.DiscordWebhookURL == "" {
+		req.Header.Set("Authorization", "Bot "+prefs.DiscordBotToken)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("discord returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/alertstream"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// SetAlertStream sets the stream every delivered alert is published to, for
+// the unified SSE and WebSocket alert feeds
+func (s *Service) SetAlertStream(stream *alertstream.Stream) {
+	s.stream = stream
+}
+
+// publishValue adds a value alert to the alert stream
+func (s *Service) publishValue(a alerts.ValueAlert) {
+	if s.stream == nil {
+		return
+	}
+	s.stream.Publish(alertstream.Alert{
+		Type:     alertstream.TypeValue,
+		Category: a.Category,
+		Kind:     a.Confidence,
+		Sport:    a.Sport,
+		GameID:   a.GameID,
+		Player:   a.PlayerName,
+		Title:    fmt.Sprintf("%s: %s %s %.1f", a.PlayerName, a.PropCategory, strings.ToUpper(a.Direction), a.Line),
+		Body: fmt.Sprintf("%.1f average, %+.1f difference, %+.0f at %s (%s @ %s)",
+			a.Average, a.Difference, a.BestOdds, a.Bookmaker, a.AwayTeam, a.HomeTeam),
+		Data:      a,
+		CreatedAt: s.clock.Now(),
+	})
+}
+
+// publishEV adds +EV prices to the alert stream
+func (s *Service) publishEV(opportunities []models.EVOpportunity) {
+	if s.stream == nil {
+		return
+	}
+	for _, opp := range opportunities {
+		s.stream.Publish(alertstream.Alert{
+			Type:     alertstream.TypeEV,
+			Category: opp.Category,
+			Kind:     opp.Market,
+			Sport:    opp.Sport,
+			GameID:   opp.GameID,
+			Title:    fmt.Sprintf("+EV: %s %+.0f", evSelection(opp), opp.Price),
+			Body: fmt.Sprintf("%s @ %s: %s %+.0f @ %s, %.1f%% EV (fair %+.0f)",
+				opp.AwayTeam, opp.HomeTeam, evSelection(opp), opp.Price, opp.Bookmaker, opp.EVPercent, opp.FairPrice),
+			Data:      opp,
+			CreatedAt: s.clock.Now(),
+		})
+	}
+}
+
+// publishOutliers adds outlier books to the alert stream
+func (s *Service) publishOutliers(outliers []models.OutlierLine) {
+	if s.stream == nil {
+		return
+	}
+	for _, o := range outliers {
+		s.stream.Publish(alertstream.Alert{
+			Type:      alertstream.TypeOutlier,
+			Category:  o.Category,
+			Kind:      o.Market,
+			Sport:     o.Sport,
+			GameID:    o.GameID,
+			Title:     "Outlier: " + outlierTitle(o),
+			Body:      outlierBody(o),
+			Data:      o,
+			CreatedAt: s.clock.Now(),
+		})
+	}
+}
+
+// publishEvent adds an event alert to the alert stream under its own type
+func (s *Service) publishEvent(event alerts.EventAlert) {
+	if s.stream == nil {
+		return
+	}
+	s.stream.Publish(alertstream.Alert{
+		Type:      event.Type,
+		Category:  event.Category,
+		Kind:      event.Kind,
+		Sport:     event.Sport,
+		GameID:    event.GameID,
+		Player:    event.Player,
+		Title:     event.Title,
+		Body:      event.Body,
+		Data:      event,
+		CreatedAt: event.CreatedAt,
+	})
+}
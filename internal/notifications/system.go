@@ -0,0 +1,72 @@
+package notifications
+
+import (
+	"fmt"
+	"html"
+	"log"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/websocket"
+)
+
+// NotifySystem delivers a system notice over WebSocket, push, and email.
+// Push is skipped during quiet hours; email is sent whenever an address is
+// configured, since these notices are infrequent and actionable. Channels
+// the system category is unsubscribed on are skipped.
+func (s *Service) NotifySystem(notice alerts.SystemNotice) {
+	notice.Category = models.CategorySystem
+
+	if s.hub != nil && s.subscribed(notice.Category, models.AlertChannelWebSocket) {
+		s.hub.BroadcastMessage(websocket.Message{Type: websocket.MessageTypeSystemNotice, Notice: &notice})
+	}
+
+	if !s.config.Enabled {
+		return
+	}
+
+	if s.isQuietHours() {
+		log.Printf("Quiet hours - skipping push for system notice %s", notice.Kind)
+	} else if s.config.VAPIDPrivateKey != "" && s.config.VAPIDPublicKey != "" && s.subscribed(notice.Category, models.AlertChannelPush) {
+		payload := PushPayload{
+			Title: notice.Title,
+			Body:  notice.Body,
+			Icon:  "/icon-192.png",
+			Badge: "/badge-72.png",
+			Tag:   categoryTag(notice.Category, "system-"+notice.Kind),
+			Data:  PushData{URL: "/", Category: notice.Category},
+		}
+		s.dispatch(ChannelPush, delivery{
+			kind:    "system",
+			payload: payload,
+			send:    func() (bool, error) { return s.deliverPush(payload) },
+		})
+	}
+
+	if !s.email.config.Configured() {
+		return
+	}
+
+	prefs, err := s.db.GetPreferences()
+	if err != nil || prefs.Email == "" || !prefs.Subscribed(notice.Category, models.AlertChannelEmail) {
+		return
+	}
+
+	body := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<body style="font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;color:#222">
+<h3>%s</h3>
+<p>%s</p>
+</body>
+</html>
+`, html.EscapeString(notice.Title), html.EscapeString(notice.Body))
+
+	subject := "LineFinder: " + notice.Title
+	s.dispatch(ChannelEmail, delivery{
+		kind:    "system",
+		payload: notice,
+		send: func() (bool, error) {
+			return true, s.email.Send(prefs.Email, subject, body, nil)
+		},
+	})
+}
@@ -0,0 +1,115 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/telegram"
+)
+
+// Telegram gets its own hourly budget, rate_limit_telegram, separate from
+// push
+const (
+	telegramRateLimitChannel = "telegram"
+	defaultTelegramRateLimit = 10
+)
+
+// telegramMaxAlerts is the most alerts listed in one message, keeping it
+// well under Telegram's length limit
+const telegramMaxAlerts = 15
+
+// TelegramConfig holds the Telegram bot alerts are sent through
+type TelegramConfig struct {
+	BotToken string
+	ChatID   string // numeric chat ID, or @channel for a channel the bot posts in
+}
+
+// Configured reports whether a bot and chat are set
+func (c TelegramConfig) Configured() bool {
+	return c.BotToken != "" && c.ChatID != ""
+}
+
+// sendTelegram queues a value alert batch for Telegram, subject to quiet
+// hours and Telegram's own rate limit
+func (s *Service) sendTelegram(batch []alerts.ValueAlert) {
+	if len(batch) == 0 || s.telegram == nil {
+		return
+	}
+
+	prefs, err := s.db.GetPreferences()
+	if err != nil {
+		log.Printf("Failed to get preferences for Telegram: %v", err)
+		return
+	}
+	if !prefs.EnableTelegram || !prefs.Subscribed(models.CategoryPropValue, models.AlertChannelTelegram) {
+		return
+	}
+	if s.isQuietHours() {
+		log.Printf("Quiet hours - skipping Telegram for %d alerts", len(batch))
+		return
+	}
+	if !s.checkRateLimit(telegramRateLimitChannel) {
+		log.Printf("Rate limit exceeded - skipping Telegram for %d alerts", len(batch))
+		return
+	}
+
+	text := s.formatTelegram(batch)
+	chatID := s.config.Telegram.ChatID
+	s.dispatch(ChannelTelegram, delivery{
+		kind:    "value_alerts",
+		payload: map[string]interface{}{"alerts": len(batch)},
+		send: func() (bool, error) {
+			err := s.telegram.SendMessage(context.Background(), chatID, text)
+			var apiErr *telegram.APIError
+			if errors.As(err, &apiErr) && apiErr.Permanent() {
+				return false, permanent(err)
+			}
+			return err == nil, err
+		},
+		onSent: func() {
+			s.limits.IncrementRateLimit(telegramRateLimitChannel)
+			log.Printf("Telegram message sent: %d alerts", len(batch))
+		},
+	})
+}
+
+// formatTelegram lists a batch in one HTML message, one alert per block,
+// noting any past the limit
+func (s *Service) formatTelegram(batch []alerts.ValueAlert) string {
+	blocks := []string{"<b>" + html.EscapeString(s.formatTitle(batch)) + "</b>"}
+	more := 0
+	if len(batch) > telegramMaxAlerts {
+		more = len(batch) - telegramMaxAlerts
+		batch = batch[:telegramMaxAlerts]
+	}
+
+	for _, a := range batch {
+		lines := []string{
+			fmt.Sprintf("<b>%s</b> %s %s %.1f", html.EscapeString(a.PlayerName), html.EscapeString(a.PropCategory), strings.ToUpper(a.Direction), a.Line),
+			fmt.Sprintf("Avg %.1f (%+.1f) · %s %+.0f · %s", a.Average, a.Difference, html.EscapeString(a.Bookmaker), a.BestOdds, a.Confidence),
+			html.EscapeString(fmt.Sprintf("%s @ %s", a.AwayTeam, a.HomeTeam)),
+		}
+		if mb := a.MyBook; mb != nil && mb.CentsGivenUp > 0 {
+			lines = append(lines, html.EscapeString(fmt.Sprintf("%s: %+.0f (%.0f¢ behind best)", mb.Bookmaker, mb.Price, mb.CentsGivenUp)))
+		}
+		if ctx := s.contextFor(a); ctx != "" {
+			lines = append(lines, "<i>"+html.EscapeString(ctx)+"</i>")
+		}
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+	if more > 0 {
+		blocks = append(blocks, fmt.Sprintf("%d more in the app", more))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// checkTelegram checks the bot token with getMe, which posts nothing
+func (s *Service) checkTelegram(ctx context.Context) error {
+	return s.telegram.GetMe(ctx)
+}
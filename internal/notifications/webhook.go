@@ -0,0 +1,190 @@
+package notifications
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/redact"
+	"github.com/joshuakim/linefinder/internal/reports"
+)
+
+// Webhook events
+const (
+	WebhookEventValueAlerts   = "value_alerts"
+	WebhookEventEVAlerts      = "ev_alerts"
+	WebhookEventOutlierAlerts = "outlier_alerts"
+	WebhookEventDigest        = "daily_digest"
+
+	// WebhookEventPing is sent by the notification status check, with no
+	// alerts and delivery ID 0
+	WebhookEventPing = "ping"
+)
+
+// webhookTimeout bounds each POST, so a slow receiver only holds up one of
+// the channel's workers
+const webhookTimeout = 10 * time.Second
+
+// Headers sent with every webhook POST
+const (
+	WebhookSignatureHeader = "X-LineFinder-Signature"
+	WebhookTimestampHeader = "X-LineFinder-Timestamp"
+	WebhookEventHeader     = "X-LineFinder-Event"
+	WebhookDeliveryHeader  = "X-LineFinder-Delivery"
+)
+
+// WebhookPayload is the JSON body POSTed to webhooks
+type WebhookPayload struct {
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Count     int         `json:"count"`
+	Alerts    interface{} `json:"alerts"`
+
+	// Digest is the whole morning digest, for daily_digest events
+	Digest *reports.DailySummary `json:"digest,omitempty"`
+}
+
+// SignWebhook returns the signature header value for a webhook body: the
+// hex HMAC-SHA256 of "{timestamp}.{body}" keyed by the webhook's secret.
+// Receivers should recompute it and reject stale timestamps.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendWebhooks queues a POST of an alert batch to every enabled webhook,
+// unless the alerts' category is unsubscribed from webhooks. Webhooks feed
+// other systems rather than people, so quiet hours and push rate limits
+// don't apply.
+func (s *Service) sendWebhooks(category, event string, alerts interface{}, count int) {
+	if count == 0 || !s.subscribed(category, models.AlertChannelWebhook) {
+		return
+	}
+
+	s.queueWebhooks(WebhookPayload{
+		Event:     event,
+		CreatedAt: s.clock.Now(),
+		Count:     count,
+		Alerts:    alerts,
+	})
+}
+
+// queueWebhooks queues a POST of a payload to every enabled webhook and
+// returns how many were queued
+func (s *Service) queueWebhooks(payload WebhookPayload) int {
+	webhooks, err := s.db.GetWebhooks()
+	if err != nil {
+		log.Printf("Failed to get webhooks: %v", err)
+		return 0
+	}
+
+	event, count := payload.Event, payload.Count
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to encode webhook payload: %v", err)
+		return 0
+	}
+
+	queued := 0
+
+	for _, hook := range webhooks {
+		if !hook.Enabled {
+			continue
+		}
+
+		hook := hook
+		d := &database.WebhookDelivery{WebhookID: hook.ID, Event: event, Payload: string(body)}
+		if err := s.db.CreateWebhookDelivery(d); err != nil {
+			log.Printf("Failed to record webhook delivery: %v", err)
+			continue
+		}
+
+		s.dispatch(ChannelWebhook, delivery{
+			kind:    event,
+			payload: map[string]interface{}{"webhook_id": hook.ID, "delivery_id": d.ID, "url": hook.URL, "count": count},
+			send:    func() (bool, error) { return s.postWebhook(hook, d, body) },
+			onSent: func() {
+				d.Status = database.WebhookDelivered
+				d.Error = ""
+				s.saveWebhookDelivery(d)
+			},
+			onFailed: func(err error) {
+				d.Status = database.WebhookFailed
+				d.Error = redact.Error(err)
+				s.saveWebhookDelivery(d)
+			},
+		})
+		queued++
+	}
+	return queued
+}
+
+// postWebhook makes one delivery attempt. Client errors other than timeouts
+// and rate limiting won't succeed on retry, so they're permanent.
+func (s *Service) postWebhook(hook database.Webhook, d *database.WebhookDelivery, body []byte) (bool, error) {
+	d.Attempts++
+
+	req, err := s.newWebhookRequest(hook, d.Event, d.ID, body)
+	if err != nil {
+		return false, permanent(err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		d.ResponseStatus = 0
+		d.Error = redact.Error(err)
+		s.saveWebhookDelivery(d)
+		return false, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	d.ResponseStatus = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return true, nil
+	}
+
+	err = fmt.Errorf("webhook returned %s", resp.Status)
+	d.Error = redact.Error(err)
+	s.saveWebhookDelivery(d)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return false, permanent(err)
+	}
+	return false, err
+}
+
+// newWebhookRequest builds a signed POST of body to a webhook
+func (s *Service) newWebhookRequest(hook database.Webhook, event string, deliveryID int64, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := s.clock.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LineFinder-Webhook")
+	req.Header.Set(WebhookEventHeader, event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(deliveryID, 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(hook.Secret, timestamp, body))
+	return req, nil
+}
+
+// saveWebhookDelivery records a delivery's latest status
+func (s *Service) saveWebhookDelivery(d *database.WebhookDelivery) {
+	if err := s.db.UpdateWebhookDelivery(d); err != nil {
+		log.Printf("Failed to update webhook delivery %d: %v", d.ID, err)
+	}
+}
@@ -0,0 +1,67 @@
+package notifications
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+)
+
+func TestSignWebhook(t *testing.T) {
+	for _, tc := range []struct {
+		secret    string
+		timestamp int64
+		body      string
+		want      string
+	}{
+		// Checked against Python's hmac.new(secret, b"{timestamp}." + body, sha256)
+		{"whsec_test", 1700000000, `{"event":"ping"}`, "sha256=aa8efe37b751e71157c508c5ac4acb1e9fe5225db98355dfc00f4b680afbc447"},
+		{"", 0, "", "sha256=b849d5a581847b281957065739df36df2463d1977ea8d6e1e4e6cf33fadc68c3"},
+	} {
+		if got := SignWebhook(tc.secret, tc.timestamp, []byte(tc.body)); got != tc.want {
+			t.Errorf("SignWebhook(%q, %d, %q) = %s, want %s", tc.secret, tc.timestamp, tc.body, got, tc.want)
+		}
+	}
+
+	// The timestamp is signed, so a replayed body with a fresh one fails
+	body := []byte(`{"event":"ping"}`)
+	if SignWebhook("whsec_test", 1700000000, body) == SignWebhook("whsec_test", 1700000001, body) {
+		t.Error("signature doesn't depend on the timestamp")
+	}
+	if SignWebhook("whsec_test", 1700000000, body) == SignWebhook("whsec_other", 1700000000, body) {
+		t.Error("signature doesn't depend on the secret")
+	}
+}
+
+func TestWebhookRequestHeaders(t *testing.T) {
+	db, err := database.New(filepath.Join(t.TempDir(), "notifications.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s := NewService(Config{}, db, nil)
+	s.SetClock(clock.NewFake(time.Unix(1700000000, 0)))
+
+	hook := database.Webhook{URL: "https://hooks.example.com/linefinder", Secret: "whsec_test"}
+	req, err := s.newWebhookRequest(hook, WebhookEventPing, 42, []byte(`{"event":"ping"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for header, want := range map[string]string{
+		WebhookEventHeader:     WebhookEventPing,
+		WebhookDeliveryHeader:  "42",
+		WebhookTimestampHeader: "1700000000",
+		WebhookSignatureHeader: "sha256=aa8efe37b751e71157c508c5ac4acb1e9fe5225db98355dfc00f4b680afbc447",
+	} {
+		if got := req.Header.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	if body, _ := io.ReadAll(req.Body); string(body) != `{"event":"ping"}` {
+		t.Errorf("body %q, want the signed one", body)
+	}
+}
@@ -2,13 +2,19 @@ package oddsapi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
 )
 
 const baseURL = "https://api.the-odds-api.com/v4"
@@ -18,6 +24,32 @@ type Client struct {
 	apiKey     string
 	httpClient *http.Client
 	baseURL    string
+
+	mu            sync.RWMutex
+	usageCallback func(Usage)
+}
+
+// Usage is the account's request quota as reported by the usage headers of
+// the last response that counted against it
+type Usage struct {
+	Remaining int64 `json:"remaining"`
+	Used      int64 `json:"used"`
+	LastCost  int64 `json:"last_cost"` // what the request itself cost
+}
+
+// ErrQuotaExceeded is wrapped by errors from requests the API refused
+// because the account's usage quota is used up
+var ErrQuotaExceeded = errors.New("usage quota exceeded")
+
+// apiError describes a failed response, wrapping ErrQuotaExceeded when the
+// quota is the reason
+func apiError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	err := fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	if strings.Contains(string(body), "OUT_OF_USAGE_CREDITS") {
+		return fmt.Errorf("%w: %w", ErrQuotaExceeded, err)
+	}
+	return err
 }
 
 // NewClient creates a new Odds API client
@@ -51,16 +83,10 @@ func (c *Client) GetOdds(sport models.Sport) ([]models.Game, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, apiError(resp)
 	}
 
-	// Log remaining requests from headers
-	remaining := resp.Header.Get("X-Requests-Remaining")
-	used := resp.Header.Get("X-Requests-Used")
-	if remaining != "" {
-		fmt.Printf("[OddsAPI] Requests remaining: %s, used: %s\n", remaining, used)
-	}
+	c.recordUsage(resp)
 
 	var games []models.Game
 	if err := json.NewDecoder(resp.Body).Decode(&games); err != nil {
@@ -70,6 +96,132 @@ func (c *Client) GetOdds(sport models.Sport) ([]models.Game, error) {
 	return games, nil
 }
 
+// SetUsageCallback sets the function called with the quota reported by each
+// response that counts against it
+func (c *Client) SetUsageCallback(fn func(Usage)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.usageCallback = fn
+}
+
+// recordUsage reads the usage headers from a response and reports them.
+// Responses without them, such as from a proxy, are ignored.
+func (c *Client) recordUsage(resp *http.Response) {
+	remaining, ok := usageHeader(resp, "X-Requests-Remaining")
+	if !ok {
+		return
+	}
+	usage := Usage{Remaining: remaining}
+	usage.Used, _ = usageHeader(resp, "X-Requests-Used")
+	usage.LastCost, _ = usageHeader(resp, "X-Requests-Last")
+	log.Printf("OddsAPI: Requests remaining: %d, used: %d", usage.Remaining, usage.Used)
+
+	c.mu.RLock()
+	callback := c.usageCallback
+	c.mu.RUnlock()
+	if callback != nil {
+		callback(usage)
+	}
+}
+
+// usageHeader parses a usage header, which may be sent as a decimal
+func usageHeader(resp *http.Response, name string) (int64, bool) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(resp.Header.Get(name)), 64)
+	if err != nil {
+		return 0, false
+	}
+	return int64(value), true
+}
+
+// SportInfo describes a sport listed by The Odds API
+type SportInfo struct {
+	Key          string `json:"key"`
+	Group        string `json:"group"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Active       bool   `json:"active"`
+	HasOutrights bool   `json:"has_outrights"`
+}
+
+// GetSports fetches the list of in-season sports. This endpoint doesn't
+// count against the usage quota.
+func (c *Client) GetSports() ([]SportInfo, error) {
+	params := url.Values{}
+	params.Add("apiKey", c.apiKey)
+
+	resp, err := c.httpClient.Get(c.baseURL + "/sports/?" + params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sports: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	var sports []SportInfo
+	if err := json.NewDecoder(resp.Body).Decode(&sports); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return sports, nil
+}
+
+// Ping checks that the API is reachable and the key is accepted
+func (c *Client) Ping() error {
+	_, err := c.GetSports()
+	return err
+}
+
+// Score is a game's score from the scores endpoint. Scores are nil until
+// the game starts.
+type Score struct {
+	ID           string      `json:"id"`
+	SportKey     string      `json:"sport_key"`
+	CommenceTime time.Time   `json:"commence_time"`
+	Completed    bool        `json:"completed"`
+	HomeTeam     string      `json:"home_team"`
+	AwayTeam     string      `json:"away_team"`
+	Scores       []TeamScore `json:"scores"`
+	LastUpdate   *time.Time  `json:"last_update"`
+}
+
+// TeamScore is one team's score, which the API sends as a string
+type TeamScore struct {
+	Name  string `json:"name"`
+	Score string `json:"score"`
+}
+
+// GetScores fetches live and upcoming games' scores for a sport, and
+// completed games' from up to daysFrom days ago (1-3; 0 leaves them out).
+// Asking for completed games costs 2 requests instead of 1.
+func (c *Client) GetScores(sport models.Sport, daysFrom int) ([]Score, error) {
+	params := url.Values{}
+	params.Add("apiKey", c.apiKey)
+	if daysFrom > 0 {
+		params.Add("daysFrom", strconv.Itoa(daysFrom))
+	}
+
+	resp, err := c.httpClient.Get(fmt.Sprintf("%s/sports/%s/scores/?%s", c.baseURL, sport, params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch scores: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	c.recordUsage(resp)
+
+	var scores []Score
+	if err := json.NewDecoder(resp.Body).Decode(&scores); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return scores, nil
+}
+
 // GetNFLOdds fetches NFL odds
 func (c *Client) GetNFLOdds() ([]models.Game, error) {
 	return c.GetOdds(models.SportNFL)
@@ -79,3 +231,165 @@ func (c *Client) GetNFLOdds() ([]models.Game, error) {
 func (c *Client) GetNBAOdds() ([]models.Game, error) {
 	return c.GetOdds(models.SportNBA)
 }
+
+// GetEventOdds fetches game markets only offered per event, such as half
+// and quarter lines or team totals, for a single event. Each market counts against the
+// usage quota.
+func (c *Client) GetEventOdds(sport models.Sport, eventID string, markets []models.Market) (models.Game, error) {
+	endpoint := fmt.Sprintf("%s/sports/%s/events/%s/odds", c.baseURL, sport, eventID)
+
+	marketKeys := make([]string, len(markets))
+	for i, m := range markets {
+		marketKeys[i] = string(m)
+	}
+
+	params := url.Values{}
+	params.Add("apiKey", c.apiKey)
+	params.Add("regions", "us")
+	params.Add("markets", strings.Join(marketKeys, ","))
+	params.Add("oddsFormat", "american")
+	params.Add("bookmakers", "draftkings,fanduel,betmgm")
+
+	resp, err := c.httpClient.Get(endpoint + "?" + params.Encode())
+	if err != nil {
+		return models.Game{}, fmt.Errorf("failed to fetch event odds: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return models.Game{}, apiError(resp)
+	}
+
+	c.recordUsage(resp)
+
+	var game models.Game
+	if err := json.NewDecoder(resp.Body).Decode(&game); err != nil {
+		return models.Game{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return game, nil
+}
+
+// eventOdds is the event odds response, where player prop outcomes carry the
+// player in Description and Over/Under in Name
+type eventOdds struct {
+	ID         string `json:"id"`
+	HomeTeam   string `json:"home_team"`
+	AwayTeam   string `json:"away_team"`
+	Bookmakers []struct {
+		Key     string `json:"key"`
+		Title   string `json:"title"`
+		Markets []struct {
+			Key      string `json:"key"`
+			Outcomes []struct {
+				Name        string  `json:"name"`
+				Description string  `json:"description"`
+				Price       float64 `json:"price"`
+				Point       float64 `json:"point"`
+			} `json:"outcomes"`
+		} `json:"markets"`
+	} `json:"bookmakers"`
+}
+
+// GetEventPlayerProps fetches player prop markets for a single event.
+// Each market counts against the usage quota.
+func (c *Client) GetEventPlayerProps(sport models.Sport, eventID string, markets []models.PlayerPropMarket) (*models.GamePlayerProps, error) {
+	endpoint := fmt.Sprintf("%s/sports/%s/events/%s/odds", c.baseURL, sport, eventID)
+
+	marketKeys := make([]string, len(markets))
+	for i, m := range markets {
+		marketKeys[i] = string(m)
+	}
+
+	params := url.Values{}
+	params.Add("apiKey", c.apiKey)
+	params.Add("regions", "us")
+	params.Add("markets", strings.Join(marketKeys, ","))
+	params.Add("oddsFormat", "american")
+	params.Add("bookmakers", "draftkings,fanduel,betmgm")
+
+	resp, err := c.httpClient.Get(endpoint + "?" + params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch player props: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
+
+	c.recordUsage(resp)
+
+	var event eventOdds
+	if err := json.NewDecoder(resp.Body).Decode(&event); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return event.toGamePlayerProps(), nil
+}
+
+// toGamePlayerProps groups outcomes by player and market, pairing the Over
+// and Under prices for each bookmaker
+func (e *eventOdds) toGamePlayerProps() *models.GamePlayerProps {
+	props := &models.GamePlayerProps{
+		GameID:   e.ID,
+		HomeTeam: e.HomeTeam,
+		AwayTeam: e.AwayTeam,
+	}
+
+	playerIndex := make(map[string]int)
+	marketIndex := make(map[string]int) // player|market -> prop index
+
+	for _, bm := range e.Bookmakers {
+		for _, market := range bm.Markets {
+			for _, outcome := range market.Outcomes {
+				if outcome.Description == "" {
+					continue
+				}
+
+				pi, ok := playerIndex[outcome.Description]
+				if !ok {
+					pi = len(props.Players)
+					playerIndex[outcome.Description] = pi
+					props.Players = append(props.Players, models.PlayerWithProps{Name: outcome.Description})
+				}
+				player := &props.Players[pi]
+
+				key := outcome.Description + "|" + market.Key
+				mi, ok := marketIndex[key]
+				if !ok {
+					mi = len(player.Props)
+					marketIndex[key] = mi
+					player.Props = append(player.Props, models.PlayerPropCategory{
+						Category: taxonomy.Normalize(market.Key),
+						Market:   models.PlayerPropMarket(market.Key),
+					})
+				}
+				prop := &player.Props[mi]
+
+				// Find or add this bookmaker's line
+				var book *models.PropBookmaker
+				for i := range prop.Bookmakers {
+					if prop.Bookmakers[i].Key == bm.Key {
+						book = &prop.Bookmakers[i]
+						break
+					}
+				}
+				if book == nil {
+					prop.Bookmakers = append(prop.Bookmakers, models.PropBookmaker{Key: bm.Key, Title: bm.Title})
+					book = &prop.Bookmakers[len(prop.Bookmakers)-1]
+				}
+
+				book.Point = outcome.Point
+				switch outcome.Name {
+				case "Over":
+					book.OverPrice = outcome.Price
+				case "Under":
+					book.UnderPrice = outcome.Price
+				}
+			}
+		}
+	}
+
+	return props
+}
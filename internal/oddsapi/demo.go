@@ -0,0 +1,278 @@
+package oddsapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// demoOdds is the sample data the demo serves: a few upcoming games per
+// sport, each starting some minutes after the demo does
+//
+//go:embed demo_odds.json
+var demoOdds []byte
+
+const (
+	// demoQuota is the request quota demo responses report
+	demoQuota = 100000
+
+	// demoSteamEvery is how many polls of a sport apart its steam moves
+	// come. Each moves one game, in turn, a point further towards the
+	// favourite at every book, and its next one moves it back.
+	demoSteamEvery = 8
+
+	// demoLag is how many polls one book per game trails steam moves by,
+	// leaving its line and price off the market's meanwhile
+	demoLag = 2
+
+	// demoSteamCents is how far steam moves moneyline prices
+	demoSteamCents = 20
+)
+
+// demoGame is a sample game, its start time relative to the demo's
+type demoGame struct {
+	StartsIn int             `json:"starts_in_minutes"`
+	Game     json.RawMessage `json:"game"`
+}
+
+// DemoTransport answers Odds API requests from sample data built into the
+// binary, so the whole pipeline runs without an API key. Lines move on
+// every poll: prices drift a few cents and, every few polls, one game's
+// spread and moneyline jump at every book, with one book catching up a
+// couple of polls late, so steam, velocity and value alerts fire.
+type DemoTransport struct {
+	start time.Time
+	games map[models.Sport][]demoGame
+
+	mu    sync.Mutex
+	polls map[models.Sport]int
+	used  int64
+}
+
+// NewDemoTransport loads the sample data, timing its games from now
+func NewDemoTransport() (*DemoTransport, error) {
+	var games map[models.Sport][]demoGame
+	if err := json.Unmarshal(demoOdds, &games); err != nil {
+		return nil, fmt.Errorf("demo data: %w", err)
+	}
+	return &DemoTransport{
+		start: time.Now(),
+		games: games,
+		polls: make(map[models.Sport]int),
+	}, nil
+}
+
+// UseDemoData answers the client's requests from the demo data rather
+// than the API
+func (c *Client) UseDemoData() error {
+	t, err := NewDemoTransport()
+	if err != nil {
+		return err
+	}
+	c.httpClient.Transport = t
+	return nil
+}
+
+// RoundTrip serves the sports list, odds, event odds and scores endpoints
+func (t *DemoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// /v4/sports/, /v4/sports/{sport}/odds/, /v4/sports/{sport}/scores/
+	// and /v4/sports/{sport}/events/{id}/odds
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "v4" || parts[1] != "sports" {
+		return faultResponse(req, http.StatusNotFound, `{"message":"Not in the demo data"}`), nil
+	}
+	parts = parts[2:]
+
+	switch {
+	case len(parts) == 0:
+		return t.respond(req, t.sportsList(), false)
+	case len(parts) == 2 && parts[1] == "odds":
+		games, ok := t.poll(models.Sport(parts[0]))
+		if !ok {
+			break
+		}
+		return t.respond(req, games, true)
+	case len(parts) == 2 && parts[1] == "scores":
+		games, ok := t.current(models.Sport(parts[0]))
+		if !ok {
+			break
+		}
+		scores := make([]Score, 0, len(games))
+		for _, g := range games {
+			scores = append(scores, Score{ID: g.ID, SportKey: string(g.SportKey), CommenceTime: g.CommenceTime, HomeTeam: g.HomeTeam, AwayTeam: g.AwayTeam})
+		}
+		return t.respond(req, scores, true)
+	case len(parts) == 4 && parts[1] == "events" && parts[3] == "odds":
+		games, ok := t.current(models.Sport(parts[0]))
+		if !ok {
+			break
+		}
+		for _, g := range games {
+			if g.ID == parts[2] {
+				return t.respond(req, onlyMarkets(g, req.URL.Query().Get("markets")), true)
+			}
+		}
+		return faultResponse(req, http.StatusNotFound, `{"message":"Event not found (demo data)"}`), nil
+	}
+	return faultResponse(req, http.StatusNotFound, `{"message":"Not in the demo data"}`), nil
+}
+
+// respond sends a JSON body, with usage headers when the request counts
+// against the quota
+func (t *DemoTransport) respond(req *http.Request, v interface{}, counts bool) (*http.Response, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	resp := faultResponse(req, http.StatusOK, string(body))
+	if counts {
+		t.mu.Lock()
+		t.used++
+		used := t.used
+		t.mu.Unlock()
+		resp.Header.Set("X-Requests-Remaining", strconv.FormatInt(demoQuota-used, 10))
+		resp.Header.Set("X-Requests-Used", strconv.FormatInt(used, 10))
+		resp.Header.Set("X-Requests-Last", "1")
+	}
+	return resp, nil
+}
+
+// sportsList is the sports endpoint's answer: the sports with demo data
+func (t *DemoTransport) sportsList() []SportInfo {
+	var sports []SportInfo
+	for _, s := range models.Sports() {
+		if _, ok := t.games[s.Sport]; ok {
+			sports = append(sports, SportInfo{Key: string(s.Sport), Group: s.Name, Title: s.Name, Description: s.Name + " (demo data)", Active: true})
+		}
+	}
+	return sports
+}
+
+// poll counts a poll of a sport and returns its games as of it
+func (t *DemoTransport) poll(sport models.Sport) ([]models.Game, bool) {
+	if _, ok := t.games[sport]; !ok {
+		return nil, false
+	}
+	t.mu.Lock()
+	t.polls[sport]++
+	n := t.polls[sport]
+	t.mu.Unlock()
+	return t.gamesAt(sport, n), true
+}
+
+// current returns a sport's games as of its last poll
+func (t *DemoTransport) current(sport models.Sport) ([]models.Game, bool) {
+	if _, ok := t.games[sport]; !ok {
+		return nil, false
+	}
+	t.mu.Lock()
+	n := t.polls[sport]
+	t.mu.Unlock()
+	return t.gamesAt(sport, n), true
+}
+
+// gamesAt builds a sport's games as they stand at its nth poll
+func (t *DemoTransport) gamesAt(sport models.Sport, n int) []models.Game {
+	samples := t.games[sport]
+	now := time.Now()
+	games := make([]models.Game, 0, len(samples))
+	for i, sample := range samples {
+		// Decoded afresh each time, so moves never build on each other
+		var g models.Game
+		if err := json.Unmarshal(sample.Game, &g); err != nil {
+			continue
+		}
+		g.CommenceTime = t.start.Add(time.Duration(sample.StartsIn) * time.Minute).Truncate(time.Second)
+
+		for b := range g.Bookmakers {
+			book := &g.Bookmakers[b]
+			book.LastUpdate = now.Truncate(time.Second)
+
+			// One book per game is slow to follow the steam
+			at := n
+			if b == i%len(g.Bookmakers) {
+				at = n - demoLag
+			}
+			steamed := steamMoves(at, i, len(samples))%2 == 1
+
+			for m := range book.Markets {
+				market := &book.Markets[m]
+				for o := range market.Outcomes {
+					outcome := &market.Outcomes[o]
+					switch market.Key {
+					case models.MarketH2H:
+						if steamed {
+							outcome.Price = awayFromEven(outcome.Price, demoSteamCents)
+						}
+						// Drift of up to 5 cents, different at each book
+						drift := []float64{0, 5, 0, -5}[(n+i+b)%4]
+						outcome.Price = awayFromEven(outcome.Price, drift)
+					case models.MarketSpreads:
+						if steamed && outcome.Point != nil {
+							point := *outcome.Point
+							if point < 0 {
+								point--
+							} else if point > 0 {
+								point++
+							}
+							outcome.Point = &point
+						}
+					}
+				}
+			}
+		}
+		games = append(games, g)
+	}
+	return games
+}
+
+// steamMoves counts the steam moves game i of a sport's games has had by
+// its nth poll. The first poll is always the baseline.
+func steamMoves(n, i, count int) int {
+	if n <= 0 || count == 0 {
+		return 0
+	}
+	cycles := (n - 1) / demoSteamEvery
+	if cycles < i+1 {
+		return 0
+	}
+	return (cycles-(i+1))/count + 1
+}
+
+// awayFromEven moves an American price by cents away from even money,
+// more negative for favourites and more positive for underdogs. Negative
+// cents move it towards even, stopping short of crossing it.
+func awayFromEven(price, cents float64) float64 {
+	if price < 0 {
+		return min(price-cents, -101)
+	}
+	return max(price+cents, 101)
+}
+
+// onlyMarkets keeps the markets named in a comma-separated list
+func onlyMarkets(g models.Game, markets string) models.Game {
+	want := make(map[string]bool)
+	for _, m := range strings.Split(markets, ",") {
+		want[m] = true
+	}
+	books := make([]models.Bookmaker, 0, len(g.Bookmakers))
+	for _, book := range g.Bookmakers {
+		var kept []models.MarketData
+		for _, market := range book.Markets {
+			if want[string(market.Key)] {
+				kept = append(kept, market)
+			}
+		}
+		book.Markets = kept
+		books = append(books, book)
+	}
+	g.Bookmakers = books
+	return g
+}
@@ -0,0 +1,207 @@
+package oddsapi
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fault kinds the injector can simulate
+const (
+	FaultRateLimit   = "rate_limit"   // 429 with the API's quota error
+	FaultServerError = "server_error" // 500
+	FaultSlow        = "slow"         // the real response, after a delay
+	FaultMalformed   = "malformed"    // 200 with a truncated JSON body
+)
+
+// maxFaultDelay caps slow responses. The client gives up after 30 seconds,
+// so longer delays already simulate a timeout.
+const maxFaultDelay = 2 * time.Minute
+
+// Fault is a simulated upstream failure applied to matching requests
+type Fault struct {
+	ID    int64   `json:"id"`
+	Kind  string  `json:"kind"`
+	Match string  `json:"match,omitempty"` // only requests whose path contains this, e.g. "/odds" or "basketball_nba"
+	Rate  float64 `json:"rate"`            // share of matching requests affected, 0-1
+	Delay int64   `json:"delay_ms,omitempty"`
+	Count int     `json:"count,omitempty"` // requests left to affect; 0 until cleared
+
+	Injected  int64     `json:"injected"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FaultInjector is a transport that fails Odds API requests on purpose, so
+// retries and recovery mode can be exercised against the real pipeline.
+// Requests no fault applies to go through unchanged.
+type FaultInjector struct {
+	next http.RoundTripper
+
+	mu     sync.Mutex
+	faults []*Fault
+	nextID int64
+	rand   *rand.Rand
+}
+
+// NewFaultInjector wraps a transport, nil meaning the default one
+func NewFaultInjector(next http.RoundTripper) *FaultInjector {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &FaultInjector{
+		next: next,
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// InjectFaults routes the client's requests through a fault injector and
+// returns it
+func (c *Client) InjectFaults() *FaultInjector {
+	f := NewFaultInjector(c.httpClient.Transport)
+	c.httpClient.Transport = f
+	return f
+}
+
+// Add validates a fault and starts applying it, returning it with its ID
+func (f *FaultInjector) Add(fault Fault) (Fault, error) {
+	switch fault.Kind {
+	case FaultRateLimit, FaultServerError, FaultMalformed:
+	case FaultSlow:
+		if fault.Delay <= 0 {
+			return Fault{}, fmt.Errorf("delay_ms is required for slow responses")
+		}
+		if time.Duration(fault.Delay)*time.Millisecond > maxFaultDelay {
+			return Fault{}, fmt.Errorf("delay_ms can be at most %d", maxFaultDelay.Milliseconds())
+		}
+	default:
+		return Fault{}, fmt.Errorf("kind must be %q, %q, %q or %q", FaultRateLimit, FaultServerError, FaultSlow, FaultMalformed)
+	}
+	if fault.Rate == 0 {
+		fault.Rate = 1
+	}
+	if fault.Rate < 0 || fault.Rate > 1 {
+		return Fault{}, fmt.Errorf("rate must be between 0 and 1")
+	}
+	if fault.Count < 0 {
+		return Fault{}, fmt.Errorf("count can't be negative")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	fault.ID = f.nextID
+	fault.Injected = 0
+	fault.CreatedAt = time.Now()
+	f.faults = append(f.faults, &fault)
+	log.Printf("Fault injection: %s on %q (rate %.2f, count %d)", fault.Kind, fault.Match, fault.Rate, fault.Count)
+	return fault, nil
+}
+
+// Remove stops a fault, returning false when it isn't active
+func (f *FaultInjector) Remove(id int64) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, fault := range f.faults {
+		if fault.ID == id {
+			f.faults = append(f.faults[:i], f.faults[i+1:]...)
+			log.Printf("Fault injection: removed %s fault %d", fault.Kind, id)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear stops every fault
+func (f *FaultInjector) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.faults) > 0 {
+		log.Printf("Fault injection: cleared %d fault(s)", len(f.faults))
+	}
+	f.faults = nil
+}
+
+// Faults returns the active faults
+func (f *FaultInjector) Faults() []Fault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	faults := make([]Fault, 0, len(f.faults))
+	for _, fault := range f.faults {
+		faults = append(faults, *fault)
+	}
+	return faults
+}
+
+// RoundTrip applies the first active fault matching the request, if any
+func (f *FaultInjector) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault, ok := f.pick(req)
+	if !ok {
+		return f.next.RoundTrip(req)
+	}
+
+	switch fault.Kind {
+	case FaultRateLimit:
+		return faultResponse(req, http.StatusTooManyRequests,
+			`{"message":"Usage quota has been reached. (injected fault)","error_code":"OUT_OF_USAGE_CREDITS"}`), nil
+	case FaultServerError:
+		return faultResponse(req, http.StatusInternalServerError,
+			`{"message":"Internal server error (injected fault)"}`), nil
+	case FaultMalformed:
+		return faultResponse(req, http.StatusOK, `[{"id":"injected-fault","sport_key":`), nil
+	}
+
+	// Slow: wait, unless the request gives up first, then send it
+	timer := time.NewTimer(time.Duration(fault.Delay) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	return f.next.RoundTrip(req)
+}
+
+// pick chooses the fault to apply to a request, counting it
+func (f *FaultInjector) pick(req *http.Request) (Fault, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, fault := range f.faults {
+		if fault.Match != "" && !strings.Contains(req.URL.Path, fault.Match) {
+			continue
+		}
+		if fault.Rate < 1 && f.rand.Float64() >= fault.Rate {
+			continue
+		}
+		fault.Injected++
+		picked := *fault
+		if fault.Count > 0 {
+			fault.Count--
+			if fault.Count == 0 {
+				// Used up
+				f.faults = append(f.faults[:i], f.faults[i+1:]...)
+			}
+		}
+		return picked, true
+	}
+	return Fault{}, false
+}
+
+// faultResponse builds a JSON response to a request
+func faultResponse(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
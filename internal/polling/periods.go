@@ -0,0 +1,116 @@
+package polling
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// DefaultPeriodInterval is how often half and quarter markets are fetched.
+// Each game costs a request per market, so they're polled less often than
+// full-game odds.
+const DefaultPeriodInterval = 10 * time.Minute
+
+// PeriodStatus is how half and quarter markets are being polled
+type PeriodStatus struct {
+	Markets  []models.Market   `json:"markets"`
+	Interval string            `json:"interval"`
+	LastPoll map[string]string `json:"last_poll,omitempty"`
+}
+
+// duePeriodSports returns the polled sports dealt in halves and quarters
+// whose period markets are due, on PeriodInterval stretched to fit the quota
+func (s *Service) duePeriodSports() []models.Sport {
+	now := s.clock.Now()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.quotaExhausted || len(s.config.PeriodMarkets) == 0 || s.config.PeriodInterval <= 0 {
+		return nil
+	}
+
+	interval := s.stretchedLocked(s.config.PeriodInterval)
+	var due []models.Sport
+	for _, sport := range s.config.Sports {
+		if !models.PeriodsOffered(sport) {
+			continue
+		}
+		if last := s.periodPolled[sport]; last.IsZero() || now.Sub(last)+time.Second >= interval {
+			due = append(due, sport)
+		}
+	}
+	return due
+}
+
+// pollPeriods fetches a sport's half and quarter markets for its games that
+// haven't started and broadcasts the sport's games if they changed
+func (s *Service) pollPeriods(sport models.Sport) {
+	s.mu.Lock()
+	s.periodPolled[sport] = s.clock.Now()
+	markets := s.config.PeriodMarkets
+	s.mu.Unlock()
+
+	games, err := s.oddsService.FetchAndStorePeriodOdds(sport, markets, s.clock.Now())
+	if err != nil {
+		log.Printf("Polling: Period markets for %s: %v", sport, err)
+	}
+	if len(games) == 0 {
+		return
+	}
+
+	// Other instances mirror the sport's games from broadcasts, so the
+	// order is kept steady for change detection
+	sort.Slice(games, func(i, j int) bool {
+		if !games[i].CommenceTime.Equal(games[j].CommenceTime) {
+			return games[i].CommenceTime.Before(games[j].CommenceTime)
+		}
+		return games[i].ID < games[j].ID
+	})
+	if s.hasChanges(sport, games) {
+		log.Printf("Polling: Period markets changed for %s, broadcasting to clients", sport)
+		s.metrics.RecordChange(string(sport))
+		s.hub.Broadcast(sport, games)
+		s.updateCache(sport, games)
+	}
+}
+
+// periodRateLocked is the requests per second period polls would use at
+// PeriodInterval: one per market for each upcoming game. The caller holds
+// s.mu.
+func (s *Service) periodRateLocked(now time.Time) float64 {
+	if len(s.config.PeriodMarkets) == 0 || s.config.PeriodInterval <= 0 {
+		return 0
+	}
+	var games int
+	for _, sport := range s.config.Sports {
+		if !models.PeriodsOffered(sport) {
+			continue
+		}
+		for _, game := range s.oddsService.GetGamesBySport(sport) {
+			if !game.Started(now) {
+				games++
+			}
+		}
+	}
+	return float64(games*len(s.config.PeriodMarkets)) / s.config.PeriodInterval.Seconds()
+}
+
+// periodStatusLocked returns how period markets are being polled, or nil
+// when they aren't. The caller holds s.mu.
+func (s *Service) periodStatusLocked() *PeriodStatus {
+	if len(s.config.PeriodMarkets) == 0 {
+		return nil
+	}
+	status := &PeriodStatus{
+		Markets:  s.config.PeriodMarkets,
+		Interval: s.stretchedLocked(s.config.PeriodInterval).String(),
+		LastPoll: make(map[string]string),
+	}
+	for sport, t := range s.periodPolled {
+		status.LastPoll[string(sport)] = s.clock.Now().Sub(t).Round(time.Second).String() + " ago"
+	}
+	return status
+}
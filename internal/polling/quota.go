@@ -0,0 +1,138 @@
+package polling
+
+import (
+	"log"
+	"math"
+	"time"
+)
+
+// QuotaStatus is how the API quota is shaping polling
+type QuotaStatus struct {
+	Remaining int64     `json:"remaining"`
+	ResetTime time.Time `json:"reset_time"`
+	Reserve   int64     `json:"reserve"`
+	Stretch   float64   `json:"stretch"` // intervals are multiplied by this
+	Exhausted bool      `json:"exhausted"`
+
+	// ClosingBurst is whether games about to start get the closing burst,
+	// which only runs when its extra requests fit in its budget
+	ClosingBurst bool `json:"closing_burst"`
+}
+
+// checkQuota projects the requests polling would use at the current
+// intervals, period markets included, until the quota resets. When that's
+// more than what's left above the reserve, intervals are stretched to fit;
+// when nothing is left, polling pauses until the reset and clients get a
+// quota_exhausted status. Closing bursts are budgeted separately: they only
+// run while their extra requests fit in Schedule.BurstBudget of what's left,
+// and aren't stretched.
+func (s *Service) checkQuota() {
+	now := s.clock.Now()
+	remaining, reset, ok := s.metrics.Quota()
+	cost := float64(s.metrics.RequestCost())
+
+	s.mu.Lock()
+	available := remaining - s.config.QuotaReserve
+
+	// Requests per second if every sport kept its current interval, and
+	// the polls closing bursts would make until their games start instead
+	// of the ones they replace
+	var rate, burstPolls, replacedPolls float64
+	shortest := time.Duration(0)
+	burstShortest := time.Duration(0)
+	for _, sport := range s.config.Sports {
+		games := s.oddsService.GetGamesBySport(sport)
+		interval, _ := s.sportInterval(games, now, false)
+		if interval <= 0 {
+			continue
+		}
+		rate += cost / interval.Seconds()
+		if shortest == 0 || interval < shortest {
+			shortest = interval
+		}
+		if until := s.config.Schedule.closingUntil(games, now); !until.IsZero() {
+			left := until.Sub(now).Seconds()
+			burstPolls += left / s.config.Schedule.BurstInterval.Seconds()
+			replacedPolls += left / interval.Seconds()
+			burstShortest = s.config.Schedule.BurstInterval
+		}
+	}
+	rate += s.periodRateLocked(now)
+
+	stretch := 1.0
+	exhausted := ok && available <= 0
+	if ok && !exhausted && rate > 0 {
+		if projected := rate * reset.Sub(now).Seconds(); projected > float64(available) {
+			stretch = projected / float64(available)
+		}
+	}
+	burst := cost * (burstPolls - replacedPolls/stretch)
+	closingBurst := !exhausted && (!ok || burst <= s.config.Schedule.BurstBudget*float64(available))
+	if closingBurst && burstShortest > 0 {
+		shortest = burstShortest
+	}
+
+	wasExhausted := s.quotaExhausted
+	previous := s.quotaStretch
+	wasBursting := s.closingBurst
+	s.quotaExhausted = exhausted
+	s.quotaStretch = stretch
+	s.closingBurst = closingBurst
+	s.mu.Unlock()
+
+	// Health shouldn't call polling stale while it's deliberately slow or off
+	if exhausted || shortest == 0 {
+		s.metrics.SetExpectedPollInterval(-1)
+	} else {
+		s.metrics.SetExpectedPollInterval(time.Duration(float64(shortest) * stretch))
+	}
+
+	switch {
+	case exhausted && !wasExhausted:
+		log.Printf("Polling: API quota exhausted (%d remaining, reserve %d) - pausing until %s",
+			remaining, s.config.QuotaReserve, reset.Format(time.RFC3339))
+		s.hub.BroadcastStatus("quota_exhausted")
+	case !exhausted && wasExhausted:
+		log.Println("Polling: API quota available again - resuming")
+		s.hub.BroadcastStatus("quota_restored")
+	}
+	if !exhausted && (stretch > 1 || previous > 1) && math.Abs(stretch-previous) >= 0.1*math.Max(previous, 1) {
+		log.Printf("Polling: %d requests left until %s - stretching intervals %.1fx",
+			available, reset.Format(time.RFC3339), stretch)
+	}
+	if burst > 0 && closingBurst != wasBursting {
+		if closingBurst {
+			log.Printf("Polling: Closing burst on (about %.0f extra requests)", burst)
+		} else {
+			log.Printf("Polling: Closing burst skipped - about %.0f extra requests is over budget (%d left)", burst, available)
+		}
+	}
+}
+
+// stretchedLocked applies the quota stretch to an interval. The caller holds
+// s.mu.
+func (s *Service) stretchedLocked(interval time.Duration) time.Duration {
+	if s.quotaStretch <= 1 {
+		return interval
+	}
+	return time.Duration(float64(interval) * s.quotaStretch)
+}
+
+// quotaStatusLocked returns how the quota is shaping polling. The caller
+// holds s.mu.
+func (s *Service) quotaStatusLocked() QuotaStatus {
+	remaining, reset, _ := s.metrics.Quota()
+	stretch := s.quotaStretch
+	if stretch < 1 {
+		stretch = 1
+	}
+	return QuotaStatus{
+		Remaining: remaining,
+		ResetTime: reset,
+		Reserve:   s.config.QuotaReserve,
+		Stretch:   math.Round(stretch*100) / 100,
+		Exhausted: s.quotaExhausted,
+
+		ClosingBurst: s.closingBurst && s.config.Schedule.bursting(),
+	}
+}
@@ -0,0 +1,216 @@
+package polling
+
+import (
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// Schedule modes, from most to least frequent polling
+const (
+	ModeClosing   = "closing"   // a game starts within the closing burst
+	ModeLive      = "live"      // a game is in progress or about to start
+	ModeNormal    = "normal"    // a game starts within the idle horizon
+	ModeIdle      = "idle"      // nothing starts within the idle horizon
+	ModeOvernight = "overnight" // overnight with nothing on, not polled
+)
+
+// Schedule adapts each sport's poll interval to how close its games are, to
+// stretch the API quota: fast around games, slow when nothing is near and
+// not at all overnight
+type Schedule struct {
+	// Enabled switches from polling every sport on Config.Interval to
+	// per-sport intervals. Config.Interval is used when a game is near but
+	// not imminent.
+	Enabled bool
+
+	// LiveInterval is used from Lead before a game starts until GameLength
+	// after
+	LiveInterval time.Duration
+	Lead         time.Duration
+	GameLength   time.Duration
+
+	// BurstInterval is used in the last BurstWindow before a game starts,
+	// when lines move most. A zero window disables it. A burst only runs
+	// while its extra requests are within BurstBudget, a share of what's
+	// left of the quota above the reserve.
+	BurstInterval time.Duration
+	BurstWindow   time.Duration
+	BurstBudget   float64
+
+	// IdleInterval is used when no game starts within IdleHorizon
+	IdleInterval time.Duration
+	IdleHorizon  time.Duration
+
+	// OvernightStart and OvernightEnd are the local hours (0-23) when a
+	// sport isn't polled unless a game is live. Equal hours disable it.
+	OvernightStart int
+	OvernightEnd   int
+	Location       *time.Location
+}
+
+// DefaultSchedule returns a sensible default schedule
+func DefaultSchedule() Schedule {
+	return Schedule{
+		Enabled:        true,
+		LiveInterval:   30 * time.Second,
+		Lead:           time.Hour,
+		GameLength:     4 * time.Hour,
+		BurstInterval:  10 * time.Second,
+		BurstWindow:    30 * time.Minute,
+		BurstBudget:    0.1,
+		IdleInterval:   15 * time.Minute,
+		IdleHorizon:    6 * time.Hour,
+		OvernightStart: 2,
+		OvernightEnd:   9,
+		Location:       time.Local,
+	}
+}
+
+// SportSchedule is when a sport is next polled and why
+type SportSchedule struct {
+	Mode       string     `json:"mode"`
+	Interval   string     `json:"interval"`
+	LastPolled *time.Time `json:"last_polled,omitempty"`
+	NextPoll   *time.Time `json:"next_poll,omitempty"`
+}
+
+// overnight reports whether a time falls in the overnight hours
+func (sch Schedule) overnight(t time.Time) bool {
+	if sch.OvernightStart == sch.OvernightEnd {
+		return false
+	}
+	loc := sch.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	hour := t.In(loc).Hour()
+	if sch.OvernightStart < sch.OvernightEnd {
+		return hour >= sch.OvernightStart && hour < sch.OvernightEnd
+	}
+	return hour >= sch.OvernightStart || hour < sch.OvernightEnd
+}
+
+// bursting reports whether closing bursts are configured
+func (sch Schedule) bursting() bool {
+	return sch.Enabled && sch.BurstWindow > 0 && sch.BurstInterval > 0
+}
+
+// closingUntil returns when the last game starting within the burst window
+// starts, or zero when none do
+func (sch Schedule) closingUntil(games []models.Game, now time.Time) time.Time {
+	var until time.Time
+	if !sch.bursting() {
+		return until
+	}
+	for _, game := range games {
+		start := game.CommenceTime
+		if start.After(now) && start.Sub(now) <= sch.BurstWindow && start.After(until) {
+			until = start
+		}
+	}
+	return until
+}
+
+// sportInterval returns how often a sport with these games should be polled
+// at a time, and the mode that decided it. Overnight returns 0. The closing
+// burst applies only when burst is set; it ends by itself once the game
+// starts.
+func (s *Service) sportInterval(games []models.Game, now time.Time, burst bool) (time.Duration, string) {
+	sch := s.config.Schedule
+	if !sch.Enabled {
+		return s.config.Interval, ModeNormal
+	}
+	if burst && !sch.closingUntil(games, now).IsZero() {
+		return sch.BurstInterval, ModeClosing
+	}
+
+	var next time.Time
+	for _, game := range games {
+		start := game.CommenceTime
+		if !now.Before(start.Add(-sch.Lead)) && now.Before(start.Add(sch.GameLength)) {
+			return sch.LiveInterval, ModeLive
+		}
+		if start.After(now) && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+
+	if sch.overnight(now) {
+		return 0, ModeOvernight
+	}
+	if next.IsZero() || next.Sub(now) > sch.IdleHorizon {
+		return sch.IdleInterval, ModeIdle
+	}
+	return s.config.Interval, ModeNormal
+}
+
+// tickIntervalLocked returns how often the loop wakes to check which sports
+// are due. The caller holds s.mu.
+func (s *Service) tickIntervalLocked() time.Duration {
+	sch := s.config.Schedule
+	interval := s.config.Interval
+	if sch.Enabled && sch.LiveInterval > 0 && sch.LiveInterval < interval {
+		interval = sch.LiveInterval
+	}
+	if sch.bursting() && sch.BurstInterval < interval {
+		interval = sch.BurstInterval
+	}
+	return interval
+}
+
+// dueSports returns the sports whose interval has passed since their last
+// poll. Sports never polled are due unless it's overnight with nothing on,
+// and none are while the quota is exhausted.
+func (s *Service) dueSports() []models.Sport {
+	now := s.clock.Now()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.quotaExhausted {
+		return nil
+	}
+
+	var due []models.Sport
+	for _, sport := range s.config.Sports {
+		interval, mode := s.sportInterval(s.oddsService.GetGamesBySport(sport), now, s.closingBurst)
+		if interval <= 0 {
+			continue
+		}
+		if mode != ModeClosing {
+			interval = s.stretchedLocked(interval)
+		}
+		// Polls land a little after the tick, so allow for a tick's slack
+		if last := s.lastPolled[sport]; last.IsZero() || now.Sub(last)+time.Second >= interval {
+			due = append(due, sport)
+		}
+	}
+	return due
+}
+
+// scheduleStatusLocked returns each sport's schedule. The caller holds s.mu.
+func (s *Service) scheduleStatusLocked() map[string]SportSchedule {
+	now := s.clock.Now()
+
+	status := make(map[string]SportSchedule, len(s.config.Sports))
+	for _, sport := range s.config.Sports {
+		interval, mode := s.sportInterval(s.oddsService.GetGamesBySport(sport), now, s.closingBurst)
+		if interval > 0 && mode != ModeClosing {
+			interval = s.stretchedLocked(interval)
+		}
+		if s.quotaExhausted {
+			interval = 0
+		}
+		sched := SportSchedule{Mode: mode, Interval: interval.String()}
+		if last, ok := s.lastPolled[sport]; ok {
+			sched.LastPolled = &last
+			if interval > 0 {
+				next := last.Add(interval)
+				sched.NextPoll = &next
+			}
+		}
+		status[string(sport)] = sched
+	}
+	return status
+}
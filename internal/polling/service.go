@@ -6,15 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/clock"
 	"github.com/joshuakim/linefinder/internal/metrics"
 	"github.com/joshuakim/linefinder/internal/models"
 	"github.com/joshuakim/linefinder/internal/service"
-	"github.com/joshuakim/linefinder/internal/store"
 	"github.com/joshuakim/linefinder/internal/websocket"
 )
 
@@ -40,6 +38,19 @@ type Config struct {
 
 	// RecoveryInterval is the interval when in recovery mode
 	RecoveryInterval time.Duration
+
+	// Schedule adapts each sport's interval to its game times
+	Schedule Schedule
+
+	// QuotaReserve is how many API requests polling leaves for manual
+	// refreshes and restarts. Polling pauses when only this many are left.
+	QuotaReserve int64
+
+	// PeriodMarkets are the half and quarter markets, and team totals,
+	// fetched for NBA and NFL games, e.g. "spreads_h1"; none by default.
+	// PeriodInterval is how often they're fetched.
+	PeriodMarkets  []models.Market
+	PeriodInterval time.Duration
 }
 
 // DefaultConfig returns a sensible default configuration
@@ -52,11 +63,45 @@ func DefaultConfig() Config {
 		RetryBaseDelay:       2 * time.Second,
 		MaxConsecutiveErrors: 5,
 		RecoveryInterval:     5 * time.Minute,
+		Schedule:             DefaultSchedule(),
+		QuotaReserve:         10,
+		PeriodInterval:       DefaultPeriodInterval,
 	}
 }
 
-// AlertCallback is called when value alerts are detected
-type AlertCallback func(alerts []alerts.ValueAlert)
+// RecoveryCallback is called when the service enters (entered=true) or
+// exits recovery mode
+type RecoveryCallback func(entered bool, consecutiveErrors int64, lastErr string)
+
+// PollCallback is called after each round of polling every sport, whether or
+// not the polls succeeded
+type PollCallback func()
+
+// RecoverySettings are the retry and recovery-mode settings that can be
+// changed at runtime
+type RecoverySettings struct {
+	MaxRetries              int `json:"max_retries"`
+	RetryBaseDelaySeconds   int `json:"retry_base_delay_seconds"`
+	MaxConsecutiveErrors    int `json:"max_consecutive_errors"`
+	RecoveryIntervalSeconds int `json:"recovery_interval_seconds"`
+}
+
+// Validate checks that all settings are positive
+func (r RecoverySettings) Validate() error {
+	if r.MaxRetries < 1 {
+		return fmt.Errorf("max_retries must be at least 1")
+	}
+	if r.RetryBaseDelaySeconds < 1 {
+		return fmt.Errorf("retry_base_delay_seconds must be at least 1")
+	}
+	if r.MaxConsecutiveErrors < 1 {
+		return fmt.Errorf("max_consecutive_errors must be at least 1")
+	}
+	if r.RecoveryIntervalSeconds < 1 {
+		return fmt.Errorf("recovery_interval_seconds must be at least 1")
+	}
+	return nil
+}
 
 // Service handles periodic polling of the Odds API
 type Service struct {
@@ -64,10 +109,11 @@ type Service struct {
 	oddsService *service.OddsService
 	hub         *websocket.Hub
 	metrics     *metrics.Metrics
+	clock       clock.Clock
 
-	// Alert detection
-	alertDetector *alerts.Detector
-	alertCallback AlertCallback
+	// Recovery mode notifications
+	recoveryCallback RecoveryCallback
+	pollCallback     PollCallback
 
 	// State
 	mu              sync.RWMutex
@@ -75,10 +121,18 @@ type Service struct {
 	inRecoveryMode  bool
 	lastData        map[models.Sport]string // Hash of last data for change detection
 	lastSuccessTime map[models.Sport]time.Time
+	lastPolled      map[models.Sport]time.Time
+	periodPolled    map[models.Sport]time.Time
+	quotaStretch    float64 // interval multiplier to last until the quota resets
+	quotaExhausted  bool
+	closingBurst    bool // the quota can afford closing bursts
 
 	// Control channels
 	stopCh   chan struct{}
 	toggleCh chan bool
+
+	// ctx is Start's context, which cuts retry backoff short on shutdown
+	ctx context.Context
 }
 
 // NewService creates a new polling service
@@ -88,25 +142,96 @@ func NewService(config Config, oddsService *service.OddsService, hub *websocket.
 		oddsService:     oddsService,
 		hub:             hub,
 		metrics:         m,
+		clock:           clock.Real{},
 		enabled:         config.Enabled,
 		lastData:        make(map[models.Sport]string),
 		lastSuccessTime: make(map[models.Sport]time.Time),
+		lastPolled:      make(map[models.Sport]time.Time),
+		periodPolled:    make(map[models.Sport]time.Time),
 		stopCh:          make(chan struct{}),
 		toggleCh:        make(chan bool, 1),
+		ctx:             context.Background(),
 	}
 }
 
-// SetAlertDetector sets the alert detector for value detection during polling
-func (s *Service) SetAlertDetector(detector *alerts.Detector, callback AlertCallback) {
-	s.alertDetector = detector
-	s.alertCallback = callback
+// SetClock sets the clock used for poll timestamps and retry backoff
+func (s *Service) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetRecoveryCallback sets the callback notified when recovery mode changes
+func (s *Service) SetRecoveryCallback(callback RecoveryCallback) {
+	s.recoveryCallback = callback
+}
+
+// SetPollCallback sets the callback notified after each polling round
+func (s *Service) SetPollCallback(callback PollCallback) {
+	s.pollCallback = callback
+}
+
+// GetRecoverySettings returns the current retry and recovery-mode settings
+func (s *Service) GetRecoverySettings() RecoverySettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return RecoverySettings{
+		MaxRetries:              s.config.MaxRetries,
+		RetryBaseDelaySeconds:   int(s.config.RetryBaseDelay / time.Second),
+		MaxConsecutiveErrors:    s.config.MaxConsecutiveErrors,
+		RecoveryIntervalSeconds: int(s.config.RecoveryInterval / time.Second),
+	}
+}
+
+// UpdateRecoverySettings changes the retry and recovery-mode settings.
+// The new recovery interval applies from the next tick.
+func (s *Service) UpdateRecoverySettings(r RecoverySettings) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.config.MaxRetries = r.MaxRetries
+	s.config.RetryBaseDelay = time.Duration(r.RetryBaseDelaySeconds) * time.Second
+	s.config.MaxConsecutiveErrors = r.MaxConsecutiveErrors
+	s.config.RecoveryInterval = time.Duration(r.RecoveryIntervalSeconds) * time.Second
+
+	log.Printf("Polling: Recovery settings updated (retries: %d, base delay: %v, max errors: %d, recovery interval: %v)",
+		s.config.MaxRetries, s.config.RetryBaseDelay, s.config.MaxConsecutiveErrors, s.config.RecoveryInterval)
+	return nil
+}
+
+// GetSports returns the sports currently being polled
+func (s *Service) GetSports() []models.Sport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sports := make([]models.Sport, len(s.config.Sports))
+	copy(sports, s.config.Sports)
+	return sports
+}
+
+// SetSports changes the sports being polled, starting with the next poll
+func (s *Service) SetSports(sports []models.Sport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.config.Sports = sports
+	log.Printf("Polling: Sports updated: %v", sports)
 }
 
 // Start begins the polling loop
 func (s *Service) Start(ctx context.Context) {
-	log.Printf("Polling service starting (enabled: %v, interval: %v)", s.enabled, s.config.Interval)
+	log.Printf("Polling service starting (enabled: %v, interval: %v, adaptive: %v)", s.enabled, s.config.Interval, s.config.Schedule.Enabled)
 
-	ticker := time.NewTicker(s.config.Interval)
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	s.mu.RLock()
+	ticker := time.NewTicker(s.tickIntervalLocked())
+	s.mu.RUnlock()
 	defer ticker.Stop()
 
 	// Do an immediate poll if enabled
@@ -193,7 +318,7 @@ func (s *Service) GetStatus() map[string]interface{} {
 	lastSuccess := make(map[string]string)
 	for sport, t := range s.lastSuccessTime {
 		if !t.IsZero() {
-			lastSuccess[string(sport)] = time.Since(t).Round(time.Second).String() + " ago"
+			lastSuccess[string(sport)] = s.clock.Now().Sub(t).Round(time.Second).String() + " ago"
 		}
 	}
 
@@ -203,6 +328,16 @@ func (s *Service) GetStatus() map[string]interface{} {
 		"interval":       s.config.Interval.String(),
 		"sports":         s.config.Sports,
 		"last_success":   lastSuccess,
+		"adaptive":       s.config.Schedule.Enabled,
+		"schedule":       s.scheduleStatusLocked(),
+		"quota":          s.quotaStatusLocked(),
+		"periods":        s.periodStatusLocked(),
+		"recovery": RecoverySettings{
+			MaxRetries:              s.config.MaxRetries,
+			RetryBaseDelaySeconds:   int(s.config.RetryBaseDelay / time.Second),
+			MaxConsecutiveErrors:    s.config.MaxConsecutiveErrors,
+			RecoveryIntervalSeconds: int(s.config.RecoveryInterval / time.Second),
+		},
 	}
 }
 
@@ -210,6 +345,11 @@ func (s *Service) handleToggle(enabled bool) {
 	s.mu.Lock()
 	wasEnabled := s.enabled
 	s.enabled = enabled
+	if enabled && !wasEnabled {
+		// Poll every sport right away, whatever its schedule
+		s.lastPolled = make(map[models.Sport]time.Time)
+		s.periodPolled = make(map[models.Sport]time.Time)
+	}
 	s.mu.Unlock()
 
 	if enabled && !wasEnabled {
@@ -224,22 +364,37 @@ func (s *Service) handleToggle(enabled bool) {
 func (s *Service) adjustTickerIfNeeded(ticker *time.Ticker) {
 	s.mu.RLock()
 	inRecovery := s.inRecoveryMode
+	recoveryInterval := s.config.RecoveryInterval
+	interval := s.tickIntervalLocked()
 	s.mu.RUnlock()
 
 	if inRecovery {
-		ticker.Reset(s.config.RecoveryInterval)
+		ticker.Reset(recoveryInterval)
 	} else {
-		ticker.Reset(s.config.Interval)
+		ticker.Reset(interval)
 	}
 }
 
+// pollAllSports polls every sport that's due on its schedule, as stretched
+// to fit the API quota
 func (s *Service) pollAllSports() {
-	for _, sport := range s.config.Sports {
+	s.checkQuota()
+	for _, sport := range s.dueSports() {
 		s.pollSport(sport)
 	}
+	for _, sport := range s.duePeriodSports() {
+		s.pollPeriods(sport)
+	}
+	if s.pollCallback != nil {
+		s.pollCallback()
+	}
 }
 
 func (s *Service) pollSport(sport models.Sport) {
+	s.mu.Lock()
+	s.lastPolled[sport] = s.clock.Now()
+	s.mu.Unlock()
+
 	start := s.metrics.RecordPollStart()
 
 	games, err := s.pollWithRetry(sport)
@@ -250,6 +405,7 @@ func (s *Service) pollSport(sport models.Sport) {
 	}
 
 	s.metrics.RecordPollSuccess(start, string(sport), len(games))
+	s.metrics.RecordBookCoverage(string(sport), games, service.AllowedBookmakers())
 	s.handlePollSuccess(sport)
 
 	// Check for changes
@@ -258,101 +414,30 @@ func (s *Service) pollSport(sport models.Sport) {
 		s.metrics.RecordChange(string(sport))
 		s.hub.Broadcast(sport, games)
 		s.updateCache(sport, games)
-
-		// Check for value alerts on changed data
-		if s.alertDetector != nil && s.alertCallback != nil {
-			go s.checkValueAlerts(sport, games)
-		}
-	}
-}
-
-// checkValueAlerts scans games for value alerts and notifies via callback
-func (s *Service) checkValueAlerts(sport models.Sport, games []models.Game) {
-	sportStr := string(sport)
-	var detectedAlerts []alerts.ValueAlert
-
-	// Get player averages
-	averages := store.GetDummyPlayerAverages(sportStr)
-	avgMap := make(map[string]map[string]float64)
-	for _, pa := range averages {
-		avgMap[strings.ToLower(pa.Name)] = pa.Averages
-	}
-
-	// Check each game for value
-	for _, game := range games {
-		props := store.GetDummyPlayerProps(game.ID, sport, game.HomeTeam, game.AwayTeam)
-
-		ctx := alerts.GameContext{
-			GameID:   game.ID,
-			Sport:    sportStr,
-			HomeTeam: game.HomeTeam,
-			AwayTeam: game.AwayTeam,
-			GameTime: game.CommenceTime,
-		}
-
-		// Process each player's props
-		for _, player := range props.Players {
-			playerAvg := avgMap[strings.ToLower(player.Name)]
-			if playerAvg == nil {
-				continue
-			}
-
-			for _, prop := range player.Props {
-				avg, ok := playerAvg[prop.Category]
-				if !ok {
-					continue
-				}
-
-				// Find best odds
-				var bestLine float64
-				var bestOdds float64
-				var bestBook string
-				for _, bm := range prop.Bookmakers {
-					if bestBook == "" || bm.OverPrice > bestOdds {
-						bestLine = bm.Point
-						bestOdds = bm.OverPrice
-						bestBook = bm.Title
-					}
-				}
-
-				propData := alerts.PropData{
-					PlayerName:   player.Name,
-					Team:         player.Team,
-					PropCategory: prop.Category,
-					Line:         bestLine,
-					Average:      avg,
-					BestOdds:     bestOdds,
-					Bookmaker:    bestBook,
-				}
-
-				alert := s.alertDetector.DetectValue(propData, ctx)
-				if alert != nil {
-					shouldNotify, _ := s.alertDetector.ShouldNotify(alert)
-					if shouldNotify {
-						s.alertDetector.RecordAlert(alert)
-						detectedAlerts = append(detectedAlerts, *alert)
-					}
-				}
-			}
-		}
-	}
-
-	// Notify via callback if we found alerts
-	if len(detectedAlerts) > 0 {
-		log.Printf("Polling: Found %d value alerts for %s", len(detectedAlerts), sport)
-		s.alertCallback(detectedAlerts)
 	}
 }
 
 func (s *Service) pollWithRetry(sport models.Sport) ([]models.Game, error) {
 	var lastErr error
 
-	for attempt := 0; attempt < s.config.MaxRetries; attempt++ {
+	s.mu.RLock()
+	maxRetries := s.config.MaxRetries
+	baseDelay := s.config.RetryBaseDelay
+	ctx := s.ctx
+	s.mu.RUnlock()
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
 		if attempt > 0 {
 			// Exponential backoff: 2s, 4s, 8s...
-			delay := s.config.RetryBaseDelay * time.Duration(1<<uint(attempt-1))
+			delay := baseDelay * time.Duration(1<<uint(attempt-1))
 			log.Printf("Polling: Retry %d for %s after %v", attempt, sport, delay)
-			time.Sleep(delay)
+			select {
+			case <-s.clock.After(delay):
+			case <-s.stopCh:
+				return nil, fmt.Errorf("polling stopped")
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
 		}
 
 		games, err := s.oddsService.FetchAndStoreOdds(sport)
@@ -364,34 +449,45 @@ func (s *Service) pollWithRetry(sport models.Sport) ([]models.Game, error) {
 		log.Printf("Polling: Attempt %d failed for %s: %v", attempt+1, sport, err)
 	}
 
-	return nil, fmt.Errorf("all %d retries failed: %w", s.config.MaxRetries, lastErr)
+	return nil, fmt.Errorf("all %d retries failed: %w", maxRetries, lastErr)
 }
 
 func (s *Service) handlePollError(sport models.Sport) {
 	consecutiveErrors := s.metrics.ConsecutiveErrors.Load()
 
-	if consecutiveErrors >= int64(s.config.MaxConsecutiveErrors) {
-		s.mu.Lock()
-		if !s.inRecoveryMode {
-			s.inRecoveryMode = true
-			log.Printf("Polling: Entering RECOVERY MODE after %d consecutive errors", consecutiveErrors)
-			s.hub.BroadcastStatus("polling_degraded")
-		}
-		s.mu.Unlock()
+	s.mu.Lock()
+	entered := false
+	if consecutiveErrors >= int64(s.config.MaxConsecutiveErrors) && !s.inRecoveryMode {
+		s.inRecoveryMode = true
+		entered = true
+		log.Printf("Polling: Entering RECOVERY MODE after %d consecutive errors", consecutiveErrors)
+		s.hub.BroadcastStatus("polling_degraded")
+	}
+	s.mu.Unlock()
+
+	if entered && s.recoveryCallback != nil {
+		lastErr, _ := s.metrics.LastPollError.Load().(string)
+		go s.recoveryCallback(true, consecutiveErrors, lastErr)
 	}
 }
 
 func (s *Service) handlePollSuccess(sport models.Sport) {
 	s.mu.Lock()
-	s.lastSuccessTime[sport] = time.Now()
+	s.lastSuccessTime[sport] = s.clock.Now()
 
 	// Exit recovery mode on success
+	exited := false
 	if s.inRecoveryMode {
 		s.inRecoveryMode = false
+		exited = true
 		log.Println("Polling: Exiting recovery mode - poll successful")
 		s.hub.BroadcastStatus("polling_healthy")
 	}
 	s.mu.Unlock()
+
+	if exited && s.recoveryCallback != nil {
+		go s.recoveryCallback(false, 0, "")
+	}
 }
 
 // hasChanges checks if the data has changed since last poll
@@ -485,6 +581,7 @@ func (s *Service) ForceRefresh(sport models.Sport) error {
 	}
 
 	s.metrics.RecordPollSuccess(start, string(sport), len(games))
+	s.metrics.RecordBookCoverage(string(sport), games, service.AllowedBookmakers())
 	s.handlePollSuccess(sport)
 
 	// Always broadcast on force refresh
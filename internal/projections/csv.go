@@ -0,0 +1,122 @@
+package projections
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/database"
+)
+
+// RowError is a CSV row that failed to parse or validate
+type RowError struct {
+	Row   int    `json:"row"` // 1-based line number, counting the header
+	Error string `json:"error"`
+}
+
+// ParseCSV reads projections or line targets from CSV. The header names the
+// columns: player and category are required, plus either projection or
+// target (a line to compare against as is), and optionally stddev. Rows
+// that fail are returned as errors alongside the rows that parsed. Every
+// row gets the source and expiry given.
+func ParseCSV(r io.Reader, source string, expiresAt *time.Time) ([]database.Projection, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("empty CSV")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	cols := make(map[string]int)
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"player", "category"} {
+		if _, ok := cols[required]; !ok {
+			return nil, nil, fmt.Errorf("missing %s column", required)
+		}
+	}
+	_, hasProjection := cols["projection"]
+	_, hasTarget := cols["target"]
+	if !hasProjection && !hasTarget {
+		return nil, nil, errors.New("missing projection or target column")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []database.Projection
+	var rowErrors []RowError
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Error: err.Error()})
+			continue
+		}
+
+		p, err := parseRow(record, field, source, expiresAt)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, p)
+	}
+	return rows, rowErrors, nil
+}
+
+// parseRow converts one CSV record to a validated projection
+func parseRow(record []string, field func([]string, string) string, source string, expiresAt *time.Time) (database.Projection, error) {
+	p := database.Projection{
+		Player:    field(record, "player"),
+		Category:  field(record, "category"),
+		Source:    source,
+		ExpiresAt: expiresAt,
+	}
+
+	value := field(record, "target")
+	p.Kind = database.ProjectionTarget
+	if value == "" {
+		value = field(record, "projection")
+		p.Kind = database.ProjectionModel
+	}
+	if value == "" {
+		return p, errors.New("projection or target required")
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return p, fmt.Errorf("invalid number %q", value)
+	}
+	p.Projection = v
+
+	if stddev := field(record, "stddev"); stddev != "" {
+		sd, err := strconv.ParseFloat(stddev, 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid stddev %q", stddev)
+		}
+		p.StdDev = sd
+	}
+
+	if err := Validate(&p); err != nil {
+		return p, err
+	}
+	return p, nil
+}
@@ -0,0 +1,255 @@
+package projections
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
+)
+
+// Modes for combining external projections with internal averages
+const (
+	ModeOff      = "off"
+	ModeOverride = "override"
+	ModeBlend    = "blend"
+)
+
+// DefaultWeight is the projection's share of a blended average
+const DefaultWeight = 0.5
+
+// ValidMode reports whether a projection mode is known. Empty means the
+// default, blend.
+func ValidMode(mode string) bool {
+	switch mode {
+	case "", ModeOff, ModeOverride, ModeBlend:
+		return true
+	}
+	return false
+}
+
+// Blender applies uploaded projections to player averages before they're
+// compared against lines
+type Blender struct {
+	db *database.DB
+}
+
+// NewBlender creates a blender reading projections and preferences from db
+func NewBlender(db *database.DB) *Blender {
+	return &Blender{db: db}
+}
+
+// Sources an average can come from besides a named projection: the internal
+// last-5 average, or a weighted blend of several sources
+const (
+	SourceAverage = "average"
+	SourceBlend   = "blend"
+)
+
+// Apply returns averages with projections applied according to the
+// projection preferences. Categories are normalized to their canonical
+// names. Players with a projection but no internal average are added.
+// Stats combined from more than one source list each source's value in
+// Sources, and every projected stat names what it came from in
+// ProjectionSource.
+func (b *Blender) Apply(averages []store.PlayerAverages) []store.PlayerAverages {
+	if b == nil || b.db == nil {
+		return averages
+	}
+
+	prefs, err := b.db.GetPreferences()
+	if err != nil {
+		log.Printf("Projections: failed to get preferences: %v", err)
+		return averages
+	}
+	if prefs.ProjectionMode == ModeOff {
+		return averages
+	}
+
+	stored, err := b.db.GetProjections()
+	if err != nil {
+		log.Printf("Projections: failed to load projections: %v", err)
+		return averages
+	}
+	if len(stored) == 0 {
+		return averages
+	}
+
+	// Group by player and category, keeping names as uploaded
+	byStat := make(map[string]map[string][]database.Projection)
+	names := make(map[string]string)
+	for _, p := range stored {
+		key := strings.ToLower(p.Player)
+		if byStat[key] == nil {
+			byStat[key] = make(map[string][]database.Projection)
+			names[key] = p.Player
+		}
+		byStat[key][p.Category] = append(byStat[key][p.Category], p)
+	}
+
+	result := make([]store.PlayerAverages, 0, len(averages))
+	seen := make(map[string]bool)
+	for _, pa := range averages {
+		key := strings.ToLower(pa.Name)
+		seen[key] = true
+
+		adjusted := make(map[string]float64, len(pa.Averages))
+		for category, avg := range pa.Averages {
+			adjusted[taxonomy.Normalize(category)] = avg
+		}
+		pa.Averages = adjusted
+		applyStats(&pa, byStat[key], prefs)
+		result = append(result, pa)
+	}
+
+	for key, stats := range byStat {
+		if seen[key] {
+			continue
+		}
+		pa := store.PlayerAverages{Name: names[key], Averages: make(map[string]float64)}
+		applyStats(&pa, stats, prefs)
+		result = append(result, pa)
+	}
+	return result
+}
+
+// applyStats combines a player's projections with their averages in place
+func applyStats(pa *store.PlayerAverages, stats map[string][]database.Projection, prefs *database.Preferences) {
+	for category, projs := range stats {
+		avg, hasAvg := pa.Averages[category]
+		value, source, values := combine(projs, avg, hasAvg, prefs)
+		pa.Averages[category] = value
+		if pa.ProjectionSource == nil {
+			pa.ProjectionSource = make(map[string]string)
+		}
+		pa.ProjectionSource[category] = source
+		if len(values) > 1 {
+			if pa.Sources == nil {
+				pa.Sources = make(map[string]map[string]float64)
+			}
+			pa.Sources[category] = values
+		}
+	}
+}
+
+// combine returns the value compared against lines for one stat and the
+// source it came from, along with every source's value. Targets are lines the user expects, so the
+// highest-precedence one is used as is. Otherwise override mode uses the
+// highest-precedence projection, and blend mode takes the weighted mean of
+// the average and every projection.
+func combine(projs []database.Projection, avg float64, hasAvg bool, prefs *database.Preferences) (float64, string, map[string]float64) {
+	values := make(map[string]float64, len(projs)+1)
+	if hasAvg {
+		values[SourceAverage] = avg
+	}
+	var targets, models []database.Projection
+	for _, p := range projs {
+		values[p.Source] = p.Projection
+		if p.Kind == database.ProjectionTarget {
+			targets = append(targets, p)
+		} else {
+			models = append(models, p)
+		}
+	}
+
+	if len(targets) > 0 {
+		p := pick(targets, prefs.ProjectionSources)
+		return p.Projection, p.Source, values
+	}
+	if prefs.ProjectionMode == ModeOverride {
+		p := pick(models, prefs.ProjectionSources)
+		return p.Projection, p.Source, values
+	}
+	if len(values) == 1 {
+		for source, v := range values {
+			return v, source, values
+		}
+	}
+
+	var sum, total float64
+	for source, v := range values {
+		w := weightFor(source, prefs)
+		sum += w * v
+		total += w
+	}
+	if total == 0 {
+		// Every weight is zero: fall back to a plain mean
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values)), SourceBlend, values
+	}
+	return sum / total, SourceBlend, values
+}
+
+// weightFor returns a source's blend weight. Sources without an explicit
+// weight split ProjectionWeight against the average's 1 - ProjectionWeight.
+func weightFor(source string, prefs *database.Preferences) float64 {
+	if w, ok := prefs.ProjectionWeights[source]; ok && w >= 0 {
+		return w
+	}
+	w := prefs.ProjectionWeight
+	if w <= 0 || w > 1 {
+		w = DefaultWeight
+	}
+	if source == SourceAverage {
+		return 1 - w
+	}
+	return w
+}
+
+// pick chooses the projection from the highest-precedence source. Sources
+// not in precedence follow; projections are newest first, so ties keep the
+// newer one.
+func pick(projs []database.Projection, precedence []string) database.Projection {
+	rankOf := func(source string) int {
+		for i, s := range precedence {
+			if strings.EqualFold(s, source) {
+				return i
+			}
+		}
+		return len(precedence)
+	}
+
+	best := projs[0]
+	for _, p := range projs[1:] {
+		if rankOf(p.Source) < rankOf(best.Source) {
+			best = p
+		}
+	}
+	return best
+}
+
+// Validate checks an uploaded projection and normalizes its player,
+// category and kind
+func Validate(p *database.Projection) error {
+	p.Player = strings.TrimSpace(p.Player)
+	p.Source = strings.TrimSpace(p.Source)
+	if p.Player == "" {
+		return fmt.Errorf("player required")
+	}
+	if p.Source == "" {
+		return fmt.Errorf("source required")
+	}
+	category, ok := taxonomy.Canonical(p.Category)
+	if !ok {
+		return fmt.Errorf("unknown category %q", p.Category)
+	}
+	p.Category = category
+	switch p.Kind {
+	case "":
+		p.Kind = database.ProjectionModel
+	case database.ProjectionModel, database.ProjectionTarget:
+	default:
+		return fmt.Errorf("kind must be %q or %q", database.ProjectionModel, database.ProjectionTarget)
+	}
+	if p.Projection < 0 {
+		return fmt.Errorf("projection must not be negative")
+	}
+	if p.StdDev < 0 {
+		return fmt.Errorf("stddev must not be negative")
+	}
+	return nil
+}
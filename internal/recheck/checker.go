@@ -0,0 +1,262 @@
+package recheck
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/projections"
+	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/store"
+)
+
+// Re-check statuses
+const (
+	// StillLive is an alert that still clears its threshold on the latest line
+	StillLive = "still_live"
+
+	// EdgeGone is an alert whose line moved back inside the threshold, flipped
+	// direction or came off the board
+	EdgeGone = "edge_gone"
+)
+
+// Confidence changes on a live alert
+const (
+	Upgraded   = "upgraded"
+	Downgraded = "downgraded"
+)
+
+// Config holds pre-game re-check configuration
+type Config struct {
+	// Interval is the time between checks for games entering the lead window
+	Interval time.Duration
+
+	// Lead is how long before a game's start its alerts are re-checked
+	Lead time.Duration
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Interval: time.Minute,
+		Lead:     60 * time.Minute,
+	}
+}
+
+// Result is the outcome of re-checking one earlier alert
+type Result struct {
+	AlertID      int64  `json:"alert_id"`
+	GameID       string `json:"game_id"`
+	HomeTeam     string `json:"home_team"`
+	AwayTeam     string `json:"away_team"`
+	PlayerName   string `json:"player_name"`
+	PropCategory string `json:"prop_category"`
+	Direction    string `json:"direction"`
+	Status       string `json:"status"`
+
+	PreviousLine       float64 `json:"previous_line"`
+	PreviousConfidence string  `json:"previous_confidence"`
+
+	// Latest values, set when the prop is still offered
+	Line             float64 `json:"line,omitempty"`
+	Average          float64 `json:"average,omitempty"`
+	Bookmaker        string  `json:"bookmaker,omitempty"`
+	Confidence       string  `json:"confidence,omitempty"`
+	ConfidenceChange string  `json:"confidence_change,omitempty"` // "upgraded" or "downgraded"
+
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Checker re-evaluates earlier alerts against the latest lines shortly
+// before each game starts, so alerts that weren't acted on get a final
+// "still live" or "edge gone" update
+type Checker struct {
+	config      Config
+	db          *database.DB
+	detector    *alerts.Detector
+	oddsService *service.OddsService
+	projections *projections.Blender
+	clock       clock.Clock
+
+	mu       sync.RWMutex
+	callback func([]Result)
+}
+
+// NewChecker creates a new pre-game re-checker
+func NewChecker(config Config, db *database.DB, detector *alerts.Detector, oddsService *service.OddsService) *Checker {
+	return &Checker{
+		config:      config,
+		db:          db,
+		detector:    detector,
+		oddsService: oddsService,
+		clock:       clock.Real{},
+	}
+}
+
+// SetClock sets the clock used for the lead window
+func (c *Checker) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+// SetProjections sets the blender applying external projections to player
+// averages, matching the scanner
+func (c *Checker) SetProjections(b *projections.Blender) {
+	c.projections = b
+}
+
+// SetCallback sets the function called with each game's re-check results
+func (c *Checker) SetCallback(fn func([]Result)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.callback = fn
+}
+
+// Start re-checks alerts on every interval until the context is cancelled
+func (c *Checker) Start(ctx context.Context) {
+	if c.config.Interval <= 0 {
+		c.config.Interval = DefaultConfig().Interval
+	}
+
+	log.Printf("Pre-game re-check starting (interval: %v, lead: %v)", c.config.Interval, c.config.Lead)
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Check()
+		}
+	}
+}
+
+// Check re-checks alerts for games starting within the lead window. Each
+// alert is re-checked once; games already underway are left alone.
+func (c *Checker) Check() {
+	if c.db == nil {
+		return
+	}
+	now := c.clock.Now()
+
+	for _, sport := range models.SportKeys() {
+		for _, game := range c.oddsService.GetGamesBySport(sport) {
+			if !game.CommenceTime.After(now) || game.CommenceTime.Sub(now) > c.config.Lead {
+				continue
+			}
+
+			pending, err := c.db.GetAlertsToRecheck(game.ID)
+			if err != nil {
+				log.Printf("Pre-game re-check: failed to get alerts for %s: %v", game.ID, err)
+				continue
+			}
+			if len(pending) == 0 {
+				continue
+			}
+
+			results := c.checkGame(sport, game, pending)
+			log.Printf("Pre-game re-check: re-checked %d alerts for %s @ %s", len(results), game.AwayTeam, game.HomeTeam)
+
+			c.mu.RLock()
+			callback := c.callback
+			c.mu.RUnlock()
+			if callback != nil && len(results) > 0 {
+				callback(results)
+			}
+		}
+	}
+}
+
+// checkGame re-evaluates a game's pending alerts against its latest props
+func (c *Checker) checkGame(sport models.Sport, game models.Game, pending []database.AlertHistory) []Result {
+	averages := c.projections.Apply(store.GetDummyPlayerAverages(string(sport)))
+	props := store.GetDummyPlayerProps(game.ID, sport, game.HomeTeam, game.AwayTeam)
+
+	// Index the latest props by player and canonical category
+	latest := make(map[string]alerts.PropData)
+	for _, prop := range c.detector.CollectProps(props, averages, nil) {
+		latest[propKey(prop.PlayerName, prop.PropCategory)] = prop
+	}
+
+	ctx := alerts.GameContext{
+		GameID:   game.ID,
+		Sport:    string(sport),
+		HomeTeam: game.HomeTeam,
+		AwayTeam: game.AwayTeam,
+		GameTime: game.CommenceTime,
+	}
+
+	var results []Result
+	for _, h := range pending {
+		result := Result{
+			AlertID:            h.ID,
+			GameID:             game.ID,
+			HomeTeam:           game.HomeTeam,
+			AwayTeam:           game.AwayTeam,
+			PlayerName:         h.PlayerName,
+			PropCategory:       h.PropCategory,
+			Direction:          h.Direction,
+			Status:             EdgeGone,
+			PreviousLine:       h.LineValue,
+			PreviousConfidence: h.Confidence,
+			CheckedAt:          c.clock.Now(),
+		}
+
+		var updated *database.AlertHistory
+		if prop, ok := latest[propKey(h.PlayerName, h.PropCategory)]; ok {
+			result.Line = prop.Line
+			result.Average = prop.Average
+			result.Bookmaker = prop.Bookmaker
+
+			alert := c.detector.DetectValue(prop, ctx)
+			if alert != nil && alert.Direction == h.Direction {
+				result.Status = StillLive
+				result.Confidence = alert.Confidence
+				result.ConfidenceChange = confidenceChange(h.Confidence, alert.Confidence)
+				updated = &database.AlertHistory{
+					LineValue:    alert.Line,
+					AverageValue: alert.Average,
+					Difference:   alert.Difference,
+					Confidence:   alert.Confidence,
+				}
+			}
+		}
+
+		if err := c.db.SaveAlertRecheck(h.ID, result.Status, updated); err != nil {
+			log.Printf("Pre-game re-check: failed to save alert %d: %v", h.ID, err)
+			continue
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// confidenceRank orders confidence levels for comparison
+var confidenceRank = map[string]int{
+	alerts.ConfidenceLow:    1,
+	alerts.ConfidenceMedium: 2,
+	alerts.ConfidenceHigh:   3,
+}
+
+// confidenceChange describes how confidence moved, or "" when unchanged
+func confidenceChange(previous, current string) string {
+	switch {
+	case confidenceRank[current] > confidenceRank[previous]:
+		return Upgraded
+	case confidenceRank[current] < confidenceRank[previous]:
+		return Downgraded
+	default:
+		return ""
+	}
+}
+
+func propKey(player, category string) string {
+	return strings.ToLower(player) + "|" + category
+}
@@ -0,0 +1,106 @@
+// Package redact masks secrets and personal data, such as API keys, push
+// subscription endpoints and email addresses, in log lines and error messages
+package redact
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces redacted text
+const Mask = "[REDACTED]"
+
+// minSecretLen is the shortest registered secret that's masked. Shorter
+// values would mask ordinary text.
+const minSecretLen = 8
+
+var (
+	mu      sync.RWMutex
+	secrets []string
+)
+
+// patterns catch secrets that aren't known ahead of time
+var patterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// Keys and tokens in query strings, e.g. Odds API and SportsDataIO URLs
+	// quoted in HTTP client errors
+	{regexp.MustCompile(`(?i)([?&](?:api_?key|key|token|access_token|secret|auth)=)[^&\s"'<>]+`), "${1}" + Mask},
+	// Authorization header values
+	{regexp.MustCompile(`(?i)\b(Bearer|Bot|Basic) [A-Za-z0-9._~+/=-]{8,}`), "${1} " + Mask},
+	// Push subscription keys in subscription JSON
+	{regexp.MustCompile(`("(?:p256dh|auth)"\s*:\s*")[^"]*`), "${1}" + Mask},
+	// Push service endpoints: the host stays, the per-subscription path doesn't
+	{regexp.MustCompile(`(https://(?:fcm\.googleapis\.com|updates\.push\.services\.mozilla\.com|[a-z0-9.-]*\.notify\.windows\.com|(?:[a-z0-9.-]+\.)?push\.apple\.com))/[^\s"'<>,]+`), "${1}/" + Mask},
+	// Discord webhook tokens
+	{regexp.MustCompile(`(discord(?:app)?\.com/api(?:/v\d+)?/webhooks/\d+/)[A-Za-z0-9._-]+`), "${1}" + Mask},
+	// Telegram bot tokens, which are part of every Bot API path
+	{regexp.MustCompile(`(api\.telegram\.org/bot)[0-9]+:[A-Za-z0-9_-]+`), "${1}" + Mask},
+	// Email addresses: the first character and domain stay
+	{regexp.MustCompile(`\b([A-Za-z0-9])[A-Za-z0-9._%+-]*@([A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,})\b`), "${1}***@${2}"},
+}
+
+// AddSecret registers values, such as API keys and tokens from the
+// environment, to mask wherever they appear. Empty and short values are
+// ignored.
+func AddSecret(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if len(v) < minSecretLen {
+			continue
+		}
+		secrets = append(secrets, v)
+	}
+	// Longest first, so a secret containing another is masked whole
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// String returns s with registered secrets and known secret patterns masked
+func String(s string) string {
+	mu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	mu.RUnlock()
+
+	for _, p := range patterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// Error returns an error's message with secrets masked, or "" for nil
+func Error(err error) string {
+	if err == nil {
+		return ""
+	}
+	return String(err.Error())
+}
+
+// writer redacts everything written through it
+type writer struct {
+	w io.Writer
+}
+
+// NewWriter wraps w so everything written to it is redacted first, for use
+// as the log output
+func NewWriter(w io.Writer) io.Writer {
+	return &writer{w: w}
+}
+
+// Write redacts p and writes it in one call, so log lines stay whole. It
+// reports len(p) on success, as callers expect, even though the redacted
+// text may be a different length.
+func (rw *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, String(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,228 @@
+package reference
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/sportsdata"
+)
+
+// Officials statuses
+const (
+	OfficialsAssigned     = "assigned"
+	OfficialsNotAnnounced = "not_announced"
+	OfficialsUnavailable  = "unavailable"
+)
+
+// Referee assignments are announced the morning of game day, so only games
+// starting within this window are looked up
+const officialsWindow = 24 * time.Hour
+
+const (
+	refereesTTL = 24 * time.Hour
+	scheduleTTL = 30 * time.Minute
+	eastCoastTZ = "America/New_York"
+)
+
+// Official is a referee assigned to a game
+type Official struct {
+	Name     string `json:"name"`
+	Number   int    `json:"number,omitempty"`
+	Position string `json:"position"`
+}
+
+// GameReference is venue and officiating context for a game
+type GameReference struct {
+	Venue *Venue `json:"venue,omitempty"`
+
+	// LocalStartTime is when the game starts in the venue's time zone
+	LocalStartTime *time.Time `json:"local_start_time,omitempty"`
+
+	// HomeAdvantage is the typical home edge in points
+	HomeAdvantage float64 `json:"home_advantage"`
+
+	// Officials are only looked up for NBA games starting soon
+	Officials       []Official `json:"officials,omitempty"`
+	OfficialsStatus string     `json:"officials_status,omitempty"`
+}
+
+// Service attaches reference data to games. NBA referee assignments come
+// from SportsDataIO when configured; everything else is static.
+type Service struct {
+	sportsData *sportsdata.Client
+	clock      clock.Clock
+
+	mu                sync.Mutex
+	referees          map[int]sportsdata.Referee
+	refereesFetchedAt time.Time
+	schedules         map[string]cachedSchedule
+}
+
+type cachedSchedule struct {
+	games     []sportsdata.NBAGame
+	fetchedAt time.Time
+}
+
+// NewService creates a reference data service. sportsData may be nil, in
+// which case officials are reported unavailable.
+func NewService(sportsData *sportsdata.Client) *Service {
+	return &Service{
+		sportsData: sportsData,
+		clock:      clock.Real{},
+		schedules:  make(map[string]cachedSchedule),
+	}
+}
+
+// SetClock sets the clock used for the officials window and cache expiry
+func (s *Service) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// ForGame returns reference data for a game, or nil if the home team isn't known
+func (s *Service) ForGame(game models.Game) *GameReference {
+	home, ok := teams[game.HomeTeam]
+	if !ok || home.Sport != game.SportKey {
+		return nil
+	}
+
+	venue := home.Venue
+	ref := &GameReference{
+		Venue:         &venue,
+		HomeAdvantage: home.HomeAdvantage,
+	}
+	if ref.HomeAdvantage == 0 {
+		ref.HomeAdvantage = defaultHomeAdvantage[home.Sport]
+	}
+	if loc, err := time.LoadLocation(venue.TimeZone); err == nil {
+		local := game.CommenceTime.In(loc)
+		ref.LocalStartTime = &local
+	}
+
+	if game.SportKey == models.SportNBA {
+		s.attachOfficials(ref, game, home)
+	}
+	return ref
+}
+
+// attachOfficials adds the referee crew for an NBA game starting soon
+func (s *Service) attachOfficials(ref *GameReference, game models.Game, home team) {
+	now := s.clock.Now()
+	if game.CommenceTime.After(now.Add(officialsWindow)) {
+		return
+	}
+	if s.sportsData == nil {
+		ref.OfficialsStatus = OfficialsUnavailable
+		return
+	}
+
+	away, ok := teams[game.AwayTeam]
+	if !ok {
+		ref.OfficialsStatus = OfficialsUnavailable
+		return
+	}
+
+	schedule, err := s.schedule(game.CommenceTime)
+	if err != nil {
+		log.Printf("Reference: failed to load NBA schedule: %v", err)
+		ref.OfficialsStatus = OfficialsUnavailable
+		return
+	}
+
+	for _, g := range schedule {
+		if g.HomeTeam != home.Abbreviation || g.AwayTeam != away.Abbreviation {
+			continue
+		}
+
+		referees, err := s.refereeIndex()
+		if err != nil {
+			log.Printf("Reference: failed to load NBA referees: %v", err)
+			ref.OfficialsStatus = OfficialsUnavailable
+			return
+		}
+
+		crew := []struct {
+			id       *int
+			position string
+		}{
+			{g.CrewChiefID, "Crew Chief"},
+			{g.RefereeID, "Referee"},
+			{g.UmpireID, "Umpire"},
+		}
+		for _, member := range crew {
+			if member.id == nil {
+				continue
+			}
+			official := Official{Position: member.position}
+			if r, ok := referees[*member.id]; ok {
+				official.Name = r.Name
+				official.Number = r.Number
+			}
+			ref.Officials = append(ref.Officials, official)
+		}
+
+		ref.OfficialsStatus = OfficialsNotAnnounced
+		if len(ref.Officials) > 0 {
+			ref.OfficialsStatus = OfficialsAssigned
+		}
+		return
+	}
+
+	ref.OfficialsStatus = OfficialsUnavailable
+}
+
+// schedule returns NBA games for the Eastern date of a tip-off, cached briefly
+// since assignments appear during the day
+func (s *Service) schedule(tipoff time.Time) ([]sportsdata.NBAGame, error) {
+	eastern, err := time.LoadLocation(eastCoastTZ)
+	if err != nil {
+		eastern = time.UTC
+	}
+	date := tipoff.In(eastern)
+	key := date.Format("2006-01-02")
+
+	s.mu.Lock()
+	cached, ok := s.schedules[key]
+	s.mu.Unlock()
+	if ok && s.clock.Now().Sub(cached.fetchedAt) < scheduleTTL {
+		return cached.games, nil
+	}
+
+	games, err := s.sportsData.GetNBAGamesByDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.schedules[key] = cachedSchedule{games: games, fetchedAt: s.clock.Now()}
+	s.mu.Unlock()
+	return games, nil
+}
+
+// refereeIndex returns NBA officials by ID, refreshed daily
+func (s *Service) refereeIndex() (map[int]sportsdata.Referee, error) {
+	s.mu.Lock()
+	referees, fetchedAt := s.referees, s.refereesFetchedAt
+	s.mu.Unlock()
+	if referees != nil && s.clock.Now().Sub(fetchedAt) < refereesTTL {
+		return referees, nil
+	}
+
+	list, err := s.sportsData.GetNBAReferees()
+	if err != nil {
+		return nil, err
+	}
+
+	referees = make(map[int]sportsdata.Referee, len(list))
+	for _, r := range list {
+		referees[r.RefereeID] = r
+	}
+
+	s.mu.Lock()
+	s.referees = referees
+	s.refereesFetchedAt = s.clock.Now()
+	s.mu.Unlock()
+	return referees, nil
+}
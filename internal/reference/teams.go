@@ -0,0 +1,126 @@
+package reference
+
+import "github.com/joshuakim/linefinder/internal/models"
+
+// Roof types
+const (
+	RoofOpen        = "open"
+	RoofDome        = "dome"
+	RoofRetractable = "retractable"
+)
+
+// Playing surfaces
+const (
+	SurfaceGrass = "grass"
+	SurfaceTurf  = "turf"
+)
+
+// Venue is a team's home arena or stadium
+type Venue struct {
+	Name        string `json:"name"`
+	City        string `json:"city"`
+	State       string `json:"state"`
+	ElevationFt int    `json:"elevation_ft"`
+
+	// Where the venue is, and its IANA time zone for local start times
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	TimeZone  string  `json:"timezone"`
+
+	// Roof and Surface are only set for NFL stadiums
+	Roof    string `json:"roof,omitempty"`
+	Surface string `json:"surface,omitempty"`
+}
+
+// team is a team's reference entry, keyed by its Odds API name
+type team struct {
+	Sport models.Sport
+
+	// Abbreviation is the SportsDataIO team key
+	Abbreviation string
+	Venue        Venue
+
+	// HomeAdvantage overrides the sport's default, in points
+	HomeAdvantage float64
+}
+
+// defaultHomeAdvantage is the typical home edge in points per sport. These
+// are rough league-wide figures for context, not a model.
+var defaultHomeAdvantage = map[models.Sport]float64{
+	models.SportNBA: 2.5,
+	models.SportNFL: 1.5,
+}
+
+var teams = map[string]team{
+	// NBA
+	"Atlanta Hawks":          {models.SportNBA, "ATL", Venue{Name: "State Farm Arena", City: "Atlanta", State: "GA", ElevationFt: 1050, Latitude: 33.7573, Longitude: -84.3963, TimeZone: "America/New_York"}, 0},
+	"Boston Celtics":         {models.SportNBA, "BOS", Venue{Name: "TD Garden", City: "Boston", State: "MA", ElevationFt: 20, Latitude: 42.3662, Longitude: -71.0621, TimeZone: "America/New_York"}, 0},
+	"Brooklyn Nets":          {models.SportNBA, "BKN", Venue{Name: "Barclays Center", City: "Brooklyn", State: "NY", ElevationFt: 30, Latitude: 40.6826, Longitude: -73.9754, TimeZone: "America/New_York"}, 0},
+	"Charlotte Hornets":      {models.SportNBA, "CHA", Venue{Name: "Spectrum Center", City: "Charlotte", State: "NC", ElevationFt: 750, Latitude: 35.2251, Longitude: -80.8392, TimeZone: "America/New_York"}, 0},
+	"Chicago Bulls":          {models.SportNBA, "CHI", Venue{Name: "United Center", City: "Chicago", State: "IL", ElevationFt: 595, Latitude: 41.8807, Longitude: -87.6742, TimeZone: "America/Chicago"}, 0},
+	"Cleveland Cavaliers":    {models.SportNBA, "CLE", Venue{Name: "Rocket Arena", City: "Cleveland", State: "OH", ElevationFt: 650, Latitude: 41.4965, Longitude: -81.6882, TimeZone: "America/New_York"}, 0},
+	"Dallas Mavericks":       {models.SportNBA, "DAL", Venue{Name: "American Airlines Center", City: "Dallas", State: "TX", ElevationFt: 430, Latitude: 32.7905, Longitude: -96.8103, TimeZone: "America/Chicago"}, 0},
+	"Denver Nuggets":         {models.SportNBA, "DEN", Venue{Name: "Ball Arena", City: "Denver", State: "CO", ElevationFt: 5280, Latitude: 39.7487, Longitude: -105.0077, TimeZone: "America/Denver"}, 3.5},
+	"Detroit Pistons":        {models.SportNBA, "DET", Venue{Name: "Little Caesars Arena", City: "Detroit", State: "MI", ElevationFt: 600, Latitude: 42.3411, Longitude: -83.0553, TimeZone: "America/Detroit"}, 0},
+	"Golden State Warriors":  {models.SportNBA, "GS", Venue{Name: "Chase Center", City: "San Francisco", State: "CA", ElevationFt: 10, Latitude: 37.7680, Longitude: -122.3877, TimeZone: "America/Los_Angeles"}, 0},
+	"Houston Rockets":        {models.SportNBA, "HOU", Venue{Name: "Toyota Center", City: "Houston", State: "TX", ElevationFt: 50, Latitude: 29.7508, Longitude: -95.3621, TimeZone: "America/Chicago"}, 0},
+	"Indiana Pacers":         {models.SportNBA, "IND", Venue{Name: "Gainbridge Fieldhouse", City: "Indianapolis", State: "IN", ElevationFt: 715, Latitude: 39.7640, Longitude: -86.1555, TimeZone: "America/Indiana/Indianapolis"}, 0},
+	"Los Angeles Clippers":   {models.SportNBA, "LAC", Venue{Name: "Intuit Dome", City: "Inglewood", State: "CA", ElevationFt: 100, Latitude: 33.9447, Longitude: -118.3418, TimeZone: "America/Los_Angeles"}, 0},
+	"Los Angeles Lakers":     {models.SportNBA, "LAL", Venue{Name: "Crypto.com Arena", City: "Los Angeles", State: "CA", ElevationFt: 270, Latitude: 34.0430, Longitude: -118.2673, TimeZone: "America/Los_Angeles"}, 0},
+	"Memphis Grizzlies":      {models.SportNBA, "MEM", Venue{Name: "FedExForum", City: "Memphis", State: "TN", ElevationFt: 260, Latitude: 35.1382, Longitude: -90.0506, TimeZone: "America/Chicago"}, 0},
+	"Miami Heat":             {models.SportNBA, "MIA", Venue{Name: "Kaseya Center", City: "Miami", State: "FL", ElevationFt: 10, Latitude: 25.7814, Longitude: -80.1870, TimeZone: "America/New_York"}, 0},
+	"Milwaukee Bucks":        {models.SportNBA, "MIL", Venue{Name: "Fiserv Forum", City: "Milwaukee", State: "WI", ElevationFt: 620, Latitude: 43.0451, Longitude: -87.9172, TimeZone: "America/Chicago"}, 0},
+	"Minnesota Timberwolves": {models.SportNBA, "MIN", Venue{Name: "Target Center", City: "Minneapolis", State: "MN", ElevationFt: 830, Latitude: 44.9795, Longitude: -93.2761, TimeZone: "America/Chicago"}, 0},
+	"New Orleans Pelicans":   {models.SportNBA, "NO", Venue{Name: "Smoothie King Center", City: "New Orleans", State: "LA", ElevationFt: 5, Latitude: 29.9490, Longitude: -90.0821, TimeZone: "America/Chicago"}, 0},
+	"New York Knicks":        {models.SportNBA, "NY", Venue{Name: "Madison Square Garden", City: "New York", State: "NY", ElevationFt: 30, Latitude: 40.7505, Longitude: -73.9934, TimeZone: "America/New_York"}, 0},
+	"Oklahoma City Thunder":  {models.SportNBA, "OKC", Venue{Name: "Paycom Center", City: "Oklahoma City", State: "OK", ElevationFt: 1200, Latitude: 35.4634, Longitude: -97.5151, TimeZone: "America/Chicago"}, 0},
+	"Orlando Magic":          {models.SportNBA, "ORL", Venue{Name: "Kia Center", City: "Orlando", State: "FL", ElevationFt: 90, Latitude: 28.5392, Longitude: -81.3839, TimeZone: "America/New_York"}, 0},
+	"Philadelphia 76ers":     {models.SportNBA, "PHI", Venue{Name: "Xfinity Mobile Arena", City: "Philadelphia", State: "PA", ElevationFt: 40, Latitude: 39.9012, Longitude: -75.1720, TimeZone: "America/New_York"}, 0},
+	"Phoenix Suns":           {models.SportNBA, "PHO", Venue{Name: "Mortgage Matchup Center", City: "Phoenix", State: "AZ", ElevationFt: 1090, Latitude: 33.4457, Longitude: -112.0712, TimeZone: "America/Phoenix"}, 0},
+	"Portland Trail Blazers": {models.SportNBA, "POR", Venue{Name: "Moda Center", City: "Portland", State: "OR", ElevationFt: 50, Latitude: 45.5316, Longitude: -122.6668, TimeZone: "America/Los_Angeles"}, 0},
+	"Sacramento Kings":       {models.SportNBA, "SAC", Venue{Name: "Golden 1 Center", City: "Sacramento", State: "CA", ElevationFt: 30, Latitude: 38.5802, Longitude: -121.4997, TimeZone: "America/Los_Angeles"}, 0},
+	"San Antonio Spurs":      {models.SportNBA, "SA", Venue{Name: "Frost Bank Center", City: "San Antonio", State: "TX", ElevationFt: 650, Latitude: 29.4270, Longitude: -98.4375, TimeZone: "America/Chicago"}, 0},
+	"Toronto Raptors":        {models.SportNBA, "TOR", Venue{Name: "Scotiabank Arena", City: "Toronto", State: "ON", ElevationFt: 250, Latitude: 43.6435, Longitude: -79.3791, TimeZone: "America/Toronto"}, 0},
+	"Utah Jazz":              {models.SportNBA, "UTA", Venue{Name: "Delta Center", City: "Salt Lake City", State: "UT", ElevationFt: 4300, Latitude: 40.7683, Longitude: -111.9011, TimeZone: "America/Denver"}, 3.0},
+	"Washington Wizards":     {models.SportNBA, "WAS", Venue{Name: "Capital One Arena", City: "Washington", State: "DC", ElevationFt: 30, Latitude: 38.8981, Longitude: -77.0209, TimeZone: "America/New_York"}, 0},
+
+	// NFL
+	"Arizona Cardinals":     {models.SportNFL, "ARI", Venue{Name: "State Farm Stadium", City: "Glendale", State: "AZ", ElevationFt: 1070, Latitude: 33.5276, Longitude: -112.2626, TimeZone: "America/Phoenix", Roof: RoofRetractable, Surface: SurfaceGrass}, 0},
+	"Atlanta Falcons":       {models.SportNFL, "ATL", Venue{Name: "Mercedes-Benz Stadium", City: "Atlanta", State: "GA", ElevationFt: 1050, Latitude: 33.7554, Longitude: -84.4010, TimeZone: "America/New_York", Roof: RoofRetractable, Surface: SurfaceTurf}, 0},
+	"Baltimore Ravens":      {models.SportNFL, "BAL", Venue{Name: "M&T Bank Stadium", City: "Baltimore", State: "MD", ElevationFt: 30, Latitude: 39.2780, Longitude: -76.6227, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceGrass}, 0},
+	"Buffalo Bills":         {models.SportNFL, "BUF", Venue{Name: "Highmark Stadium", City: "Orchard Park", State: "NY", ElevationFt: 600, Latitude: 42.7738, Longitude: -78.7870, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceTurf}, 0},
+	"Carolina Panthers":     {models.SportNFL, "CAR", Venue{Name: "Bank of America Stadium", City: "Charlotte", State: "NC", ElevationFt: 750, Latitude: 35.2258, Longitude: -80.8528, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceTurf}, 0},
+	"Chicago Bears":         {models.SportNFL, "CHI", Venue{Name: "Soldier Field", City: "Chicago", State: "IL", ElevationFt: 595, Latitude: 41.8623, Longitude: -87.6167, TimeZone: "America/Chicago", Roof: RoofOpen, Surface: SurfaceGrass}, 0},
+	"Cincinnati Bengals":    {models.SportNFL, "CIN", Venue{Name: "Paycor Stadium", City: "Cincinnati", State: "OH", ElevationFt: 490, Latitude: 39.0955, Longitude: -84.5161, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceTurf}, 0},
+	"Cleveland Browns":      {models.SportNFL, "CLE", Venue{Name: "Huntington Bank Field", City: "Cleveland", State: "OH", ElevationFt: 580, Latitude: 41.5061, Longitude: -81.6995, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceGrass}, 0},
+	"Dallas Cowboys":        {models.SportNFL, "DAL", Venue{Name: "AT&T Stadium", City: "Arlington", State: "TX", ElevationFt: 600, Latitude: 32.7473, Longitude: -97.0945, TimeZone: "America/Chicago", Roof: RoofRetractable, Surface: SurfaceTurf}, 0},
+	"Denver Broncos":        {models.SportNFL, "DEN", Venue{Name: "Empower Field at Mile High", City: "Denver", State: "CO", ElevationFt: 5280, Latitude: 39.7439, Longitude: -105.0201, TimeZone: "America/Denver", Roof: RoofOpen, Surface: SurfaceGrass}, 2.5},
+	"Detroit Lions":         {models.SportNFL, "DET", Venue{Name: "Ford Field", City: "Detroit", State: "MI", ElevationFt: 600, Latitude: 42.3400, Longitude: -83.0456, TimeZone: "America/Detroit", Roof: RoofDome, Surface: SurfaceTurf}, 0},
+	"Green Bay Packers":     {models.SportNFL, "GB", Venue{Name: "Lambeau Field", City: "Green Bay", State: "WI", ElevationFt: 640, Latitude: 44.5013, Longitude: -88.0622, TimeZone: "America/Chicago", Roof: RoofOpen, Surface: SurfaceGrass}, 0},
+	"Houston Texans":        {models.SportNFL, "HOU", Venue{Name: "NRG Stadium", City: "Houston", State: "TX", ElevationFt: 50, Latitude: 29.6847, Longitude: -95.4107, TimeZone: "America/Chicago", Roof: RoofRetractable, Surface: SurfaceTurf}, 0},
+	"Indianapolis Colts":    {models.SportNFL, "IND", Venue{Name: "Lucas Oil Stadium", City: "Indianapolis", State: "IN", ElevationFt: 715, Latitude: 39.7601, Longitude: -86.1639, TimeZone: "America/Indiana/Indianapolis", Roof: RoofRetractable, Surface: SurfaceTurf}, 0},
+	"Jacksonville Jaguars":  {models.SportNFL, "JAX", Venue{Name: "EverBank Stadium", City: "Jacksonville", State: "FL", ElevationFt: 15, Latitude: 30.3239, Longitude: -81.6373, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceGrass}, 0},
+	"Kansas City Chiefs":    {models.SportNFL, "KC", Venue{Name: "GEHA Field at Arrowhead Stadium", City: "Kansas City", State: "MO", ElevationFt: 750, Latitude: 39.0489, Longitude: -94.4839, TimeZone: "America/Chicago", Roof: RoofOpen, Surface: SurfaceGrass}, 0},
+	"Las Vegas Raiders":     {models.SportNFL, "LV", Venue{Name: "Allegiant Stadium", City: "Las Vegas", State: "NV", ElevationFt: 2000, Latitude: 36.0909, Longitude: -115.1833, TimeZone: "America/Los_Angeles", Roof: RoofDome, Surface: SurfaceGrass}, 0},
+	"Los Angeles Chargers":  {models.SportNFL, "LAC", Venue{Name: "SoFi Stadium", City: "Inglewood", State: "CA", ElevationFt: 100, Latitude: 33.9535, Longitude: -118.3392, TimeZone: "America/Los_Angeles", Roof: RoofDome, Surface: SurfaceTurf}, 0},
+	"Los Angeles Rams":      {models.SportNFL, "LAR", Venue{Name: "SoFi Stadium", City: "Inglewood", State: "CA", ElevationFt: 100, Latitude: 33.9535, Longitude: -118.3392, TimeZone: "America/Los_Angeles", Roof: RoofDome, Surface: SurfaceTurf}, 0},
+	"Miami Dolphins":        {models.SportNFL, "MIA", Venue{Name: "Hard Rock Stadium", City: "Miami Gardens", State: "FL", ElevationFt: 10, Latitude: 25.9580, Longitude: -80.2389, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceGrass}, 0},
+	"Minnesota Vikings":     {models.SportNFL, "MIN", Venue{Name: "U.S. Bank Stadium", City: "Minneapolis", State: "MN", ElevationFt: 830, Latitude: 44.9737, Longitude: -93.2577, TimeZone: "America/Chicago", Roof: RoofDome, Surface: SurfaceTurf}, 0},
+	"New England Patriots":  {models.SportNFL, "NE", Venue{Name: "Gillette Stadium", City: "Foxborough", State: "MA", ElevationFt: 290, Latitude: 42.0909, Longitude: -71.2643, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceTurf}, 0},
+	"New Orleans Saints":    {models.SportNFL, "NO", Venue{Name: "Caesars Superdome", City: "New Orleans", State: "LA", ElevationFt: 5, Latitude: 29.9511, Longitude: -90.0812, TimeZone: "America/Chicago", Roof: RoofDome, Surface: SurfaceTurf}, 0},
+	"New York Giants":       {models.SportNFL, "NYG", Venue{Name: "MetLife Stadium", City: "East Rutherford", State: "NJ", ElevationFt: 10, Latitude: 40.8135, Longitude: -74.0745, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceTurf}, 0},
+	"New York Jets":         {models.SportNFL, "NYJ", Venue{Name: "MetLife Stadium", City: "East Rutherford", State: "NJ", ElevationFt: 10, Latitude: 40.8135, Longitude: -74.0745, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceTurf}, 0},
+	"Philadelphia Eagles":   {models.SportNFL, "PHI", Venue{Name: "Lincoln Financial Field", City: "Philadelphia", State: "PA", ElevationFt: 40, Latitude: 39.9008, Longitude: -75.1675, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceGrass}, 0},
+	"Pittsburgh Steelers":   {models.SportNFL, "PIT", Venue{Name: "Acrisure Stadium", City: "Pittsburgh", State: "PA", ElevationFt: 730, Latitude: 40.4468, Longitude: -80.0158, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceGrass}, 0},
+	"San Francisco 49ers":   {models.SportNFL, "SF", Venue{Name: "Levi's Stadium", City: "Santa Clara", State: "CA", ElevationFt: 10, Latitude: 37.4030, Longitude: -121.9700, TimeZone: "America/Los_Angeles", Roof: RoofOpen, Surface: SurfaceGrass}, 0},
+	"Seattle Seahawks":      {models.SportNFL, "SEA", Venue{Name: "Lumen Field", City: "Seattle", State: "WA", ElevationFt: 10, Latitude: 47.5952, Longitude: -122.3316, TimeZone: "America/Los_Angeles", Roof: RoofOpen, Surface: SurfaceTurf}, 0},
+	"Tampa Bay Buccaneers":  {models.SportNFL, "TB", Venue{Name: "Raymond James Stadium", City: "Tampa", State: "FL", ElevationFt: 30, Latitude: 27.9759, Longitude: -82.5033, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceGrass}, 0},
+	"Tennessee Titans":      {models.SportNFL, "TEN", Venue{Name: "Nissan Stadium", City: "Nashville", State: "TN", ElevationFt: 450, Latitude: 36.1665, Longitude: -86.7713, TimeZone: "America/Chicago", Roof: RoofOpen, Surface: SurfaceTurf}, 0},
+	"Washington Commanders": {models.SportNFL, "WAS", Venue{Name: "Northwest Stadium", City: "Landover", State: "MD", ElevationFt: 200, Latitude: 38.9078, Longitude: -76.8645, TimeZone: "America/New_York", Roof: RoofOpen, Surface: SurfaceGrass}, 0},
+}
+
+// Abbreviation returns the SportsDataIO key for a team's Odds API name
+func Abbreviation(teamName string) (string, bool) {
+	t, ok := teams[teamName]
+	return t.Abbreviation, ok
+}
@@ -0,0 +1,96 @@
+package reports
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/closing"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// ClosingReport measures how close the closing line projections sent with
+// alerts came to the actual close
+type ClosingReport struct {
+	Since       time.Time         `json:"since"`
+	Predictions int               `json:"predictions"`
+	Markets     []ClosingAccuracy `json:"markets"`
+}
+
+// ClosingAccuracy is one sport's market's projection accuracy. Errors are
+// in the unit: points, or cents for moneylines.
+type ClosingAccuracy struct {
+	Sport       string `json:"sport"`
+	Market      string `json:"market"`
+	Unit        string `json:"unit"`
+	Predictions int    `json:"predictions"`
+
+	// MeanError is the mean absolute error of the projected close, and
+	// NoMoveError that of assuming the line holds where it was
+	MeanError   float64 `json:"mean_abs_error"`
+	NoMoveError float64 `json:"no_move_abs_error"`
+
+	// DirectionRate is the share of projected moves the line went on to
+	// make, counting only projections with a move
+	DirectionRate float64 `json:"direction_rate_percent"`
+
+	// Carry is the share of drift that carried on to the close across
+	// these projections
+	Carry float64 `json:"carry"`
+}
+
+// BuildClosingReport measures resolved closing line projections made since
+// the given time, optionally for a single sport
+func (b *Builder) BuildClosingReport(since time.Time, sport models.Sport) (*ClosingReport, error) {
+	resolved, err := b.db.GetResolvedClosingPredictions(since, string(sport))
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]database.ClosingPrediction)
+	var keys []string
+	for _, pr := range resolved {
+		key := pr.Sport + "|" + pr.Market + "|" + pr.Unit
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], pr)
+	}
+	sort.Strings(keys)
+
+	report := &ClosingReport{Since: since, Markets: []ClosingAccuracy{}}
+	for _, key := range keys {
+		group := groups[key]
+		var errorTotal, noMoveTotal float64
+		var moved, hits int
+		for _, pr := range group {
+			projectedMove := closing.UnitMove(pr.Line, pr.Projected, pr.Unit)
+			actualMove := closing.UnitMove(pr.Line, *pr.Close, pr.Unit)
+			errorTotal += math.Abs(actualMove - projectedMove)
+			noMoveTotal += math.Abs(actualMove)
+			if projectedMove != 0 {
+				moved++
+				if projectedMove*actualMove > 0 {
+					hits++
+				}
+			}
+		}
+
+		n := float64(len(group))
+		carry, _ := closing.FitCarry(group)
+		report.Markets = append(report.Markets, ClosingAccuracy{
+			Sport:         group[0].Sport,
+			Market:        group[0].Market,
+			Unit:          group[0].Unit,
+			Predictions:   len(group),
+			MeanError:     math.Round(errorTotal/n*100) / 100,
+			NoMoveError:   math.Round(noMoveTotal/n*100) / 100,
+			DirectionRate: math.Round(percent(hits, moved)*10) / 10,
+			Carry:         math.Round(carry*100) / 100,
+		})
+		report.Predictions += len(group)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,35 @@
+package reports
+
+import (
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// BuildDigest builds the morning digest: games starting before until with
+// their best lines and notable injuries, and the value alerts still open on
+// the sports' games, newest first
+func (b *Builder) BuildDigest(sports []models.Sport, until time.Time) (*DailySummary, error) {
+	summary := &DailySummary{GeneratedAt: b.clock.Now()}
+	b.addSlate(summary, sports, until)
+
+	if b.db == nil {
+		return summary, nil
+	}
+	live, err := b.db.GetLiveAlerts()
+	if err != nil {
+		return nil, err
+	}
+	games := make(map[string]bool)
+	for _, sport := range sports {
+		for _, game := range b.oddsService.GetGamesBySport(sport) {
+			games[game.ID] = true
+		}
+	}
+	for i := len(live) - 1; i >= 0; i-- {
+		if games[live[i].GameID] {
+			summary.Alerts = append(summary.Alerts, live[i])
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,88 @@
+package reports
+
+import (
+	"github.com/joshuakim/linefinder/internal/alerts"
+)
+
+// ExperimentReport compares what each threshold profile would have fired
+type ExperimentReport struct {
+	Experiment *alerts.Experiment         `json:"experiment"`
+	Profiles   map[string]*ProfileSummary `json:"profiles"`
+
+	// Alerts fired by both profiles vs only one
+	Overlap int `json:"overlap"`
+	OnlyA   int `json:"only_a"`
+	OnlyB   int `json:"only_b"`
+}
+
+// ProfileSummary counts alerts a single profile would have fired
+type ProfileSummary struct {
+	Total        int            `json:"total"`
+	ByCategory   map[string]int `json:"by_category"`
+	ByConfidence map[string]int `json:"by_confidence"`
+	AvgAbsDiff   float64        `json:"avg_abs_difference"`
+}
+
+// BuildExperimentReport summarizes recorded results for an experiment
+func (b *Builder) BuildExperimentReport(e *alerts.Experiment) (*ExperimentReport, error) {
+	results, err := b.db.GetExperimentResults(e.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ExperimentReport{
+		Experiment: e,
+		Profiles:   make(map[string]*ProfileSummary),
+	}
+	for _, p := range []string{alerts.ProfileA, alerts.ProfileB} {
+		report.Profiles[p] = &ProfileSummary{
+			ByCategory:   make(map[string]int),
+			ByConfidence: make(map[string]int),
+		}
+	}
+
+	// Track which profiles fired each alert key to compute overlap
+	fired := make(map[string]map[string]bool)
+	sumAbsDiff := make(map[string]float64)
+
+	for _, r := range results {
+		summary, ok := report.Profiles[r.Profile]
+		if !ok {
+			continue
+		}
+
+		summary.Total++
+		summary.ByCategory[r.PropCategory]++
+		summary.ByConfidence[r.Confidence]++
+		if r.Difference < 0 {
+			sumAbsDiff[r.Profile] -= r.Difference
+		} else {
+			sumAbsDiff[r.Profile] += r.Difference
+		}
+
+		key := r.GameID + "|" + r.PlayerName + "|" + r.PropCategory + "|" + r.Direction
+		if fired[key] == nil {
+			fired[key] = make(map[string]bool)
+		}
+		fired[key][r.Profile] = true
+	}
+
+	for p, summary := range report.Profiles {
+		if summary.Total > 0 {
+			summary.AvgAbsDiff = sumAbsDiff[p] / float64(summary.Total)
+		}
+	}
+
+	for _, profiles := range fired {
+		switch {
+		case profiles[alerts.ProfileA] && profiles[alerts.ProfileB]:
+			report.Overlap++
+		case profiles[alerts.ProfileA]:
+			report.OnlyA++
+		default:
+			report.OnlyB++
+		}
+	}
+
+	return report, nil
+}
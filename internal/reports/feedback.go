@@ -0,0 +1,153 @@
+package reports
+
+import (
+	"math"
+	"sort"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
+)
+
+const (
+	// minFeedbackSamples is how many ratings a category needs before tuning
+	minFeedbackSamples = 5
+
+	// poorFeedbackRate is the not-useful share that triggers a suggestion
+	poorFeedbackRate = 0.5
+
+	// thresholdStep is the multiplier applied to thresholds with poor feedback
+	thresholdStep = 1.25
+)
+
+// FeedbackReport summarizes alert feedback per prop category
+type FeedbackReport struct {
+	TotalFeedback int                   `json:"total_feedback"`
+	Categories    []CategoryFeedback    `json:"categories"`
+	Suggestions   []ThresholdSuggestion `json:"suggestions,omitempty"`
+}
+
+// CategoryFeedback holds feedback and outcome counts for one prop category
+type CategoryFeedback struct {
+	Category   string  `json:"category"`
+	Total      int     `json:"total"`
+	Useful     int     `json:"useful"`
+	NotUseful  int     `json:"not_useful"`
+	BetIt      int     `json:"bet_it"`
+	UsefulRate float64 `json:"useful_rate_percent"`
+
+	// Outcomes reported with feedback
+	Wins    int     `json:"wins"`
+	Losses  int     `json:"losses"`
+	Pushes  int     `json:"pushes"`
+	WinRate float64 `json:"win_rate_percent"`
+}
+
+// ThresholdSuggestion proposes a new threshold for a category
+type ThresholdSuggestion struct {
+	Category  string  `json:"category"`
+	Current   float64 `json:"current"`
+	Suggested float64 `json:"suggested"`
+	Reason    string  `json:"reason"`
+}
+
+// BuildFeedbackReport aggregates stored feedback. When suggest is set,
+// categories with mostly negative feedback get a raised threshold suggestion.
+func (b *Builder) BuildFeedbackReport(thresholds alerts.Thresholds, suggest bool) (*FeedbackReport, error) {
+	feedback, err := b.db.GetAllFeedback()
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := make(map[string]*CategoryFeedback)
+	for _, f := range feedback {
+		// Older alerts may carry alias names; group under the canonical one
+		category := taxonomy.Normalize(f.PropCategory)
+		c := byCategory[category]
+		if c == nil {
+			c = &CategoryFeedback{Category: category}
+			byCategory[category] = c
+		}
+
+		c.Total++
+		switch f.Rating {
+		case database.FeedbackUseful:
+			c.Useful++
+		case database.FeedbackNotUseful:
+			c.NotUseful++
+		case database.FeedbackBetIt:
+			c.BetIt++
+		}
+
+		switch f.Outcome {
+		case database.OutcomeWin:
+			c.Wins++
+		case database.OutcomeLoss:
+			c.Losses++
+		case database.OutcomePush:
+			c.Pushes++
+		}
+	}
+
+	report := &FeedbackReport{TotalFeedback: len(feedback)}
+	for _, c := range byCategory {
+		// Placing a bet counts as the alert being useful
+		c.UsefulRate = percent(c.Useful+c.BetIt, c.Total)
+		c.WinRate = percent(c.Wins, c.Wins+c.Losses)
+		report.Categories = append(report.Categories, *c)
+	}
+
+	sort.Slice(report.Categories, func(i, j int) bool {
+		return report.Categories[i].Category < report.Categories[j].Category
+	})
+
+	if suggest {
+		report.Suggestions = suggestThresholds(report.Categories, thresholds)
+	}
+
+	return report, nil
+}
+
+// suggestThresholds raises thresholds for categories users mostly rate as not useful
+func suggestThresholds(categories []CategoryFeedback, thresholds alerts.Thresholds) []ThresholdSuggestion {
+	var suggestions []ThresholdSuggestion
+	for _, c := range categories {
+		if c.Total < minFeedbackSamples {
+			continue
+		}
+
+		// Never tune on categories outside the taxonomy, or on ones sharing
+		// the default threshold, which would change it for all of them
+		if taxonomy.Validate(c.Category) != nil || !alerts.HasOwnThreshold(c.Category) {
+			continue
+		}
+
+		notUsefulRate := float64(c.NotUseful) / float64(c.Total)
+		if notUsefulRate < poorFeedbackRate {
+			continue
+		}
+
+		current := thresholds.GetThreshold(c.Category)
+		// Round up to the nearest half unit so suggestions match line increments
+		suggested := math.Ceil(current*thresholdStep*2) / 2
+		if suggested <= current {
+			suggested = current + 0.5
+		}
+
+		suggestions = append(suggestions, ThresholdSuggestion{
+			Category:  c.Category,
+			Current:   current,
+			Suggested: suggested,
+			Reason:    "majority of alerts rated not useful",
+		})
+	}
+	return suggestions
+}
+
+// percent returns part/total as a percentage, or 0 when total is 0
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
@@ -0,0 +1,160 @@
+package reports
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/service"
+)
+
+// HoldReport ranks bookmakers by their average hold (the margin built into
+// their prices) over stored odds
+type HoldReport struct {
+	Since   time.Time       `json:"since"`
+	Games   int             `json:"games"`
+	Overall []BookmakerHold `json:"overall"`
+	Markets []MarketHold    `json:"markets"`
+}
+
+// MarketHold ranks bookmakers for one market, lowest hold first
+type MarketHold struct {
+	Market     string          `json:"market"`
+	Bookmakers []BookmakerHold `json:"bookmakers"`
+}
+
+// BookmakerHold is a bookmaker's average hold across sampled markets
+type BookmakerHold struct {
+	Bookmaker   string  `json:"bookmaker"`
+	Samples     int     `json:"samples"`
+	AverageHold float64 `json:"average_hold_percent"`
+}
+
+// holdTally accumulates hold samples for one bookmaker
+type holdTally struct {
+	title   string
+	samples int
+	total   float64
+}
+
+// BuildHoldReport computes average hold per bookmaker per market from game
+// and prop snapshots since the given time, optionally for a single sport
+func (b *Builder) BuildHoldReport(since time.Time, sport models.Sport) (*HoldReport, error) {
+	games, err := b.db.GetGameSnapshots(since)
+	if err != nil {
+		return nil, err
+	}
+	props, err := b.db.GetPropSnapshots(since)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &HoldReport{Since: since}
+	byMarket := make(map[string]map[string]*holdTally)
+	overall := make(map[string]*holdTally)
+	add := func(market, key, title string, hold float64) {
+		if byMarket[market] == nil {
+			byMarket[market] = make(map[string]*holdTally)
+		}
+		for _, tallies := range []map[string]*holdTally{byMarket[market], overall} {
+			t := tallies[key]
+			if t == nil {
+				t = &holdTally{title: title}
+				tallies[key] = t
+			}
+			t.samples++
+			t.total += hold
+		}
+	}
+
+	gameIDs := make(map[string]bool)
+	for _, game := range games {
+		if sport != "" && game.SportKey != sport {
+			continue
+		}
+		gameIDs[game.ID] = true
+		report.Games++
+
+		for _, bm := range game.Bookmakers {
+			if !service.IsAllowedBookmaker(bm.Key) {
+				continue
+			}
+			for _, market := range bm.Markets {
+				prices := make([]float64, 0, len(market.Outcomes))
+				for _, o := range market.Outcomes {
+					prices = append(prices, o.Price)
+				}
+				if hold, ok := marketHold(prices); ok {
+					add(string(market.Key), bm.Key, bm.Title, hold)
+				}
+			}
+		}
+	}
+
+	for _, gp := range props {
+		if !gameIDs[gp.GameID] {
+			continue
+		}
+		for _, player := range gp.Players {
+			for _, category := range player.Props {
+				for _, bm := range category.Bookmakers {
+					if !service.IsAllowedBookmaker(bm.Key) {
+						continue
+					}
+					if hold, ok := marketHold([]float64{bm.OverPrice, bm.UnderPrice}); ok {
+						add(string(category.Market), bm.Key, bm.Title, hold)
+					}
+				}
+			}
+		}
+	}
+
+	report.Overall = rankHolds(overall)
+	for market, tallies := range byMarket {
+		report.Markets = append(report.Markets, MarketHold{
+			Market:     market,
+			Bookmakers: rankHolds(tallies),
+		})
+	}
+	sort.Slice(report.Markets, func(i, j int) bool {
+		return report.Markets[i].Market < report.Markets[j].Market
+	})
+
+	return report, nil
+}
+
+// marketHold returns the hold for a market's prices: the share of the total
+// implied probability above 100%. Markets missing a price are skipped.
+func marketHold(prices []float64) (float64, bool) {
+	if len(prices) < 2 {
+		return 0, false
+	}
+	var total float64
+	for _, p := range prices {
+		if p == 0 {
+			return 0, false
+		}
+		total += models.ImpliedProbability(p)
+	}
+	return (1 - 1/total) * 100, true
+}
+
+// rankHolds averages tallies and sorts bookmakers by hold, lowest first
+func rankHolds(tallies map[string]*holdTally) []BookmakerHold {
+	holds := make([]BookmakerHold, 0, len(tallies))
+	for _, t := range tallies {
+		holds = append(holds, BookmakerHold{
+			Bookmaker:   t.title,
+			Samples:     t.samples,
+			AverageHold: math.Round(t.total/float64(t.samples)*100) / 100,
+		})
+	}
+	sort.Slice(holds, func(i, j int) bool {
+		if holds[i].AverageHold != holds[j].AverageHold {
+			return holds[i].AverageHold < holds[j].AverageHold
+		}
+		return holds[i].Bookmaker < holds[j].Bookmaker
+	})
+	return holds
+}
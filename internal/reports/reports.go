@@ -0,0 +1,132 @@
+package reports
+
+import (
+	"sort"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/store"
+)
+
+// Builder assembles reports from the odds store and the database
+type Builder struct {
+	oddsService *service.OddsService
+	db          *database.DB
+	clock       clock.Clock
+}
+
+// NewBuilder creates a new report builder
+func NewBuilder(oddsService *service.OddsService, db *database.DB) *Builder {
+	return &Builder{
+		oddsService: oddsService,
+		db:          db,
+		clock:       clock.Real{},
+	}
+}
+
+// SetClock sets the clock used to pick the slate and alert window
+func (b *Builder) SetClock(c clock.Clock) {
+	b.clock = c
+}
+
+// DailySummary is a snapshot of the day's slate for digests
+type DailySummary struct {
+	GeneratedAt time.Time               `json:"generated_at"`
+	Games       []GameLines             `json:"games"`
+	Alerts      []database.AlertHistory `json:"alerts"`
+	Injuries    []InjuryNote            `json:"injuries"`
+}
+
+// GameLines holds the best available lines for one game
+type GameLines struct {
+	Sport        string                `json:"sport"`
+	GameID       string                `json:"game_id"`
+	HomeTeam     string                `json:"home_team"`
+	AwayTeam     string                `json:"away_team"`
+	CommenceTime time.Time             `json:"commence_time"`
+	Comparison   models.OddsComparison `json:"comparison"`
+}
+
+// InjuryNote is an injury worth calling out in a summary
+type InjuryNote struct {
+	Sport    string `json:"sport"`
+	GameID   string `json:"game_id"`
+	Team     string `json:"team"`
+	Player   string `json:"player"`
+	Position string `json:"position"`
+	Status   string `json:"status"`
+	Notes    string `json:"notes"`
+}
+
+// notableInjuryStatuses are the statuses that make it into summaries
+var notableInjuryStatuses = map[string]bool{
+	"Out":      true,
+	"Doubtful": true,
+}
+
+// BuildDailySummary builds a summary of games starting within the window,
+// alerts recorded in the last 24 hours, and notable injuries
+func (b *Builder) BuildDailySummary(sports []models.Sport, window time.Duration) (*DailySummary, error) {
+	now := b.clock.Now()
+	summary := &DailySummary{GeneratedAt: now}
+	b.addSlate(summary, sports, now.Add(window))
+
+	if b.db != nil {
+		alerts, err := b.db.GetRecentAlerts(now.Add(-24*time.Hour), 50)
+		if err != nil {
+			return nil, err
+		}
+		summary.Alerts = alerts
+	}
+
+	return summary, nil
+}
+
+// addSlate adds the games starting between now and until, with their best
+// lines and notable injuries, in start order
+func (b *Builder) addSlate(summary *DailySummary, sports []models.Sport, until time.Time) {
+	now := summary.GeneratedAt
+	for _, sport := range sports {
+		sportStr := sport.ShortKey()
+
+		for _, game := range b.oddsService.GetGamesBySport(sport) {
+			if game.CommenceTime.Before(now) || game.CommenceTime.After(until) {
+				continue
+			}
+
+			summary.Games = append(summary.Games, GameLines{
+				Sport:        sportStr,
+				GameID:       game.ID,
+				HomeTeam:     game.HomeTeam,
+				AwayTeam:     game.AwayTeam,
+				CommenceTime: game.CommenceTime,
+				Comparison:   b.oddsService.CompareOdds(game),
+			})
+
+			injuries := store.GetDummyInjuries(game.ID, game.HomeTeam, game.AwayTeam, sportStr)
+			for _, team := range []store.TeamInjuries{injuries.AwayTeam, injuries.HomeTeam} {
+				for _, p := range team.Players {
+					if !notableInjuryStatuses[p.Status] {
+						continue
+					}
+					summary.Injuries = append(summary.Injuries, InjuryNote{
+						Sport:    sportStr,
+						GameID:   game.ID,
+						Team:     team.Team,
+						Player:   p.Name,
+						Position: p.Position,
+						Status:   p.Status,
+						Notes:    p.Notes,
+					})
+				}
+			}
+		}
+	}
+
+	sort.Slice(summary.Games, func(i, j int) bool {
+		return summary.Games[i].CommenceTime.Before(summary.Games[j].CommenceTime)
+	})
+}
@@ -0,0 +1,51 @@
+package scanner
+
+import (
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/service"
+)
+
+// EVCallback is called when prices beating the no-vig consensus are found
+type EVCallback func(opportunities []models.EVOpportunity)
+
+// SetOddsService sets the service pricing games against their no-vig
+// consensus. Without it the scanner only looks for prop value alerts.
+func (s *Scanner) SetOddsService(svc *service.OddsService) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.oddsService = svc
+}
+
+// SetEVCallback sets the function called with +EV prices found by a scan
+func (s *Scanner) SetEVCallback(fn EVCallback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evCallback = fn
+}
+
+// scansEV reports whether game lines are checked for +EV prices
+func (s *Scanner) scansEV() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.oddsService != nil
+}
+
+// newEV returns the +EV prices for a sport that weren't flagged by the last
+// scan or whose price has moved since. Prices that drop off are forgotten so
+// they're flagged again if they come back.
+func (s *Scanner) newEV(sport models.Sport, opportunities []models.EVOpportunity) []models.EVOpportunity {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := s.evSeen[sport]
+	current := make(map[string]float64, len(opportunities))
+	var fresh []models.EVOpportunity
+	for _, opp := range opportunities {
+		current[opp.ID] = opp.Price
+		if price, ok := seen[opp.ID]; !ok || price != opp.Price {
+			fresh = append(fresh, opp)
+		}
+	}
+	s.evSeen[sport] = current
+	return fresh
+}
@@ -0,0 +1,34 @@
+package scanner
+
+import "github.com/joshuakim/linefinder/internal/models"
+
+// OutlierCallback is called when bookmakers far off the consensus are found
+type OutlierCallback func(outliers []models.OutlierLine)
+
+// SetOutlierCallback sets the function called with outlier books found by
+// a scan. Outliers are only checked with an odds service set.
+func (s *Scanner) SetOutlierCallback(fn OutlierCallback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outlierCallback = fn
+}
+
+// newOutliers returns the outliers for a sport that weren't flagged by the
+// last scan or whose price has moved since, forgetting ones that drop off
+// so they're flagged again if they come back
+func (s *Scanner) newOutliers(sport models.Sport, outliers []models.OutlierLine) []models.OutlierLine {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := s.outlierSeen[sport]
+	current := make(map[string]float64, len(outliers))
+	var fresh []models.OutlierLine
+	for _, o := range outliers {
+		current[o.ID] = o.Price
+		if price, ok := seen[o.ID]; !ok || price != o.Price {
+			fresh = append(fresh, o)
+		}
+	}
+	s.outlierSeen[sport] = current
+	return fresh
+}
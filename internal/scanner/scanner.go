@@ -0,0 +1,392 @@
+package scanner
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/metrics"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/projections"
+	"github.com/joshuakim/linefinder/internal/service"
+	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
+)
+
+// Config holds alert scanner configuration
+type Config struct {
+	// Enabled controls whether store updates are scanned
+	Enabled bool
+
+	// QueueSize is how many updates can wait for a scan. When full, the
+	// oldest waiting update is dropped.
+	QueueSize int
+
+	// ThrottleAlerts caps the value alerts one scan delivers, such as the
+	// flood after averages refresh. A scan finding more delivers the ones
+	// with the biggest edges and raises the sport's effective thresholds
+	// for ThrottleWindow. 0 turns throttling off.
+	ThrottleAlerts int
+	ThrottleWindow time.Duration
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Enabled:        true,
+		QueueSize:      16,
+		ThrottleAlerts: 25,
+		ThrottleWindow: 30 * time.Minute,
+	}
+}
+
+// AlertCallback is called when value alerts are detected
+type AlertCallback func(alerts []alerts.ValueAlert)
+
+// StateCallback is called when stored alerts change lifecycle state
+type StateCallback func(changes []alerts.StateChange)
+
+// Status is the scanner's state and counters
+type Status struct {
+	Enabled            bool      `json:"enabled"`
+	QueueLength        int       `json:"queue_length"`
+	QueueSize          int       `json:"queue_size"`
+	UpdatesReceived    int64     `json:"updates_received"`
+	UpdatesDropped     int64     `json:"updates_dropped"`
+	ScansRun           int64     `json:"scans_run"`
+	AlertsFound        int64     `json:"alerts_found"`
+	EVAlertsFound      int64     `json:"ev_alerts_found"`
+	OutlierAlertsFound int64     `json:"outlier_alerts_found"`
+	AlertsThrottled    int64     `json:"alerts_throttled"`
+	LastScanTime       time.Time `json:"last_scan_time,omitempty"`
+	LastScanSport      string    `json:"last_scan_sport,omitempty"`
+	LastScanDurationMs int64     `json:"last_scan_duration_ms"`
+
+	// Sports whose thresholds are raised after a flood of alerts
+	Throttled map[string]metrics.AlertThrottle `json:"throttled,omitempty"`
+}
+
+// Scanner checks odds for value alerts on its own worker, fed by store
+// updates, so slow scans never hold up polling or broadcasts
+type Scanner struct {
+	config      Config
+	detector    *alerts.Detector
+	metrics     *metrics.Metrics
+	clock       clock.Clock
+	projections *projections.Blender
+
+	updates     <-chan store.Update
+	unsubscribe func()
+	queue       chan store.Update
+
+	mu            sync.RWMutex
+	enabled       bool
+	callback      AlertCallback
+	stateCallback StateCallback
+	evCallback    EVCallback
+	oddsService   *service.OddsService
+	evSeen        map[models.Sport]map[string]float64
+	throttles     map[models.Sport]metrics.AlertThrottle
+	status        Status
+
+	// Outlier books flagged by the last scan of each sport, by price
+	outlierCallback OutlierCallback
+	outlierSeen     map[models.Sport]map[string]float64
+}
+
+// NewScanner creates a new alert scanner. It subscribes to the store right
+// away so updates written before Start aren't missed.
+func NewScanner(config Config, dataStore *store.Store, detector *alerts.Detector, m *metrics.Metrics) *Scanner {
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultConfig().QueueSize
+	}
+	if config.ThrottleWindow <= 0 {
+		config.ThrottleWindow = DefaultConfig().ThrottleWindow
+	}
+	updates, unsubscribe := dataStore.Watch("")
+	return &Scanner{
+		config:      config,
+		detector:    detector,
+		metrics:     m,
+		clock:       clock.Real{},
+		updates:     updates,
+		unsubscribe: unsubscribe,
+		queue:       make(chan store.Update, config.QueueSize),
+		enabled:     config.Enabled,
+		evSeen:      make(map[models.Sport]map[string]float64),
+		outlierSeen: make(map[models.Sport]map[string]float64),
+		throttles:   make(map[models.Sport]metrics.AlertThrottle),
+	}
+}
+
+// SetClock sets the clock used for scan timestamps
+func (s *Scanner) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetProjections sets the blender applying external projections to
+// player averages before they're compared against lines
+func (s *Scanner) SetProjections(b *projections.Blender) {
+	s.projections = b
+}
+
+// SetCallback sets the function called with alerts found by a scan
+func (s *Scanner) SetCallback(fn AlertCallback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callback = fn
+}
+
+// SetStateCallback sets the function called with lifecycle state changes
+// found by a scan
+func (s *Scanner) SetStateCallback(fn StateCallback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stateCallback = fn
+}
+
+// Enable turns scanning on
+func (s *Scanner) Enable() {
+	s.setEnabled(true)
+}
+
+// Disable turns scanning off. Updates received while disabled are ignored.
+func (s *Scanner) Disable() {
+	s.setEnabled(false)
+}
+
+func (s *Scanner) setEnabled(enabled bool) {
+	s.mu.Lock()
+	changed := s.enabled != enabled
+	s.enabled = enabled
+	s.mu.Unlock()
+
+	if changed {
+		log.Printf("Alert scanner enabled: %v", enabled)
+	}
+}
+
+// IsEnabled returns whether scanning is enabled
+func (s *Scanner) IsEnabled() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.enabled
+}
+
+// Status returns the scanner's state and counters
+func (s *Scanner) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := s.status
+	status.Enabled = s.enabled
+	status.QueueLength = len(s.queue)
+	status.QueueSize = cap(s.queue)
+	if throttled := s.throttleStatus(); len(throttled) > 0 {
+		status.Throttled = throttled
+	}
+	return status
+}
+
+// Start queues store updates and scans them until the context is cancelled
+func (s *Scanner) Start(ctx context.Context) {
+	defer s.unsubscribe()
+
+	log.Printf("Alert scanner starting (enabled: %v, queue: %d)", s.IsEnabled(), s.config.QueueSize)
+	go s.work(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case u, ok := <-s.updates:
+			if !ok {
+				return
+			}
+			s.enqueue(u)
+		}
+	}
+}
+
+// enqueue adds an update worth scanning to the queue, dropping the oldest
+// waiting update when it's full
+func (s *Scanner) enqueue(u store.Update) {
+	if !u.Changed || !s.IsEnabled() {
+		return
+	}
+	// Sports without prop categories only have game lines to check for +EV
+	if len(taxonomy.All(u.Sport)) == 0 && !s.scansEV() {
+		return
+	}
+	// Sports left out of the preferences aren't alerted on at all
+	if !s.detector.SportEnabled(string(u.Sport)) {
+		return
+	}
+
+	s.mu.Lock()
+	s.status.UpdatesReceived++
+	s.mu.Unlock()
+
+	select {
+	case s.queue <- u:
+		return
+	default:
+	}
+
+	select {
+	case <-s.queue:
+		s.mu.Lock()
+		s.status.UpdatesDropped++
+		s.mu.Unlock()
+	default:
+	}
+	select {
+	case s.queue <- u:
+	default:
+	}
+}
+
+// work runs scans from the queue one at a time
+func (s *Scanner) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case u := <-s.queue:
+			if s.IsEnabled() {
+				s.scan(u.Sport, u.Games)
+			}
+		}
+	}
+}
+
+// scan checks games for value alerts, +EV prices and outlier books and
+// notifies via the callbacks
+func (s *Scanner) scan(sport models.Sport, games []models.Game) {
+	start := s.clock.Now()
+	sportStr := string(sport)
+	var found []*alerts.ValueAlert
+	var detectedAlerts []alerts.ValueAlert
+	var stateChanges []alerts.StateChange
+	var evOpportunities []models.EVOpportunity
+	var outliers []models.OutlierLine
+	var scanStats metrics.AlertScanStats
+
+	s.mu.RLock()
+	oddsService := s.oddsService
+	s.mu.RUnlock()
+	scanProps := len(taxonomy.All(sport)) > 0
+
+	// Get player averages
+	averages := s.projections.Apply(store.GetDummyPlayerAverages(sportStr))
+
+	// Check each game for value
+	now := s.clock.Now()
+	for _, game := range games {
+		if !s.detector.InScanWindow(game.CommenceTime) {
+			scanStats.GamesOutsideWindow++
+			continue
+		}
+		// Games underway only have their earlier alerts expired
+		started := game.Started(now)
+
+		if oddsService != nil && !started {
+			_, positive := oddsService.FairOdds(game)
+			evOpportunities = append(evOpportunities, positive...)
+			_, off := oddsService.Consensus(game)
+			outliers = append(outliers, off...)
+		}
+		if !scanProps {
+			continue
+		}
+
+		props := store.GetDummyPlayerProps(game.ID, sport, game.HomeTeam, game.AwayTeam)
+
+		ctx := alerts.GameContext{
+			GameID:   game.ID,
+			Sport:    sportStr,
+			HomeTeam: game.HomeTeam,
+			AwayTeam: game.AwayTeam,
+			GameTime: game.CommenceTime,
+		}
+
+		propsData := s.detector.CollectProps(props, averages, &scanStats)
+
+		// Move earlier alerts along before new ones reset their state
+		stateChanges = append(stateChanges, s.detector.UpdateStates(propsData, ctx)...)
+		if started {
+			continue
+		}
+
+		for _, propData := range propsData {
+			s.detector.ObserveExperiment(propData, ctx)
+
+			alert := s.detector.DetectValue(propData, ctx)
+			if alert != nil {
+				shouldNotify, _ := s.detector.ShouldNotify(alert)
+				if shouldNotify {
+					found = append(found, alert)
+				}
+			}
+		}
+	}
+
+	// Only alerts throttling lets through are recorded and delivered
+	for _, alert := range s.throttle(sport, found, &scanStats) {
+		s.detector.RecordAlert(alert)
+		detectedAlerts = append(detectedAlerts, *alert)
+	}
+
+	if oddsService != nil {
+		evOpportunities = s.newEV(sport, evOpportunities)
+		outliers = s.newOutliers(sport, outliers)
+	}
+
+	s.metrics.RecordAlertScan(sportStr, scanStats)
+	if skipped := scanStats.Skipped(); skipped > 0 {
+		log.Printf("Alert scanner: scan for %s skipped %d of %d props", sport, skipped, scanStats.PropsScanned)
+	}
+
+	s.mu.Lock()
+	s.status.ScansRun++
+	s.status.AlertsFound += int64(len(detectedAlerts))
+	s.status.EVAlertsFound += int64(len(evOpportunities))
+	s.status.OutlierAlertsFound += int64(len(outliers))
+	s.status.LastScanTime = s.clock.Now()
+	s.status.LastScanSport = sportStr
+	s.status.LastScanDurationMs = s.clock.Now().Sub(start).Milliseconds()
+	callback := s.callback
+	stateCallback := s.stateCallback
+	evCallback := s.evCallback
+	outlierCallback := s.outlierCallback
+	s.mu.Unlock()
+
+	if len(stateChanges) > 0 && stateCallback != nil {
+		stateCallback(stateChanges)
+	}
+
+	// Notify via callback if we found alerts
+	if len(detectedAlerts) > 0 {
+		log.Printf("Alert scanner: found %d value alerts for %s", len(detectedAlerts), sport)
+		if callback != nil {
+			callback(detectedAlerts)
+		}
+	}
+
+	if len(evOpportunities) > 0 {
+		log.Printf("Alert scanner: found %d +EV prices for %s", len(evOpportunities), sport)
+		if evCallback != nil {
+			evCallback(evOpportunities)
+		}
+	}
+
+	if len(outliers) > 0 {
+		log.Printf("Alert scanner: found %d outlier lines for %s", len(outliers), sport)
+		if outlierCallback != nil {
+			outlierCallback(outliers)
+		}
+	}
+}
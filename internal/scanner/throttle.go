@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"log"
+	"sort"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/metrics"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// throttle picks which of a scan's alerts are delivered. While the sport is
+// throttled, alerts under its raised edge are held back. When more than
+// the cap are left, only the cap with the biggest edges go out and the
+// sport is throttled, with the edge raised to the smallest one delivered,
+// so following scans only deliver alerts at least that strong until the
+// window passes. Alerts held back aren't recorded, so they can fire later.
+func (s *Scanner) throttle(sport models.Sport, found []*alerts.ValueAlert, stats *metrics.AlertScanStats) []*alerts.ValueAlert {
+	stats.AlertsFound = len(found)
+	if s.config.ThrottleAlerts <= 0 {
+		return found
+	}
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, throttled := s.throttles[sport]
+	if throttled && !now.Before(state.Until) {
+		delete(s.throttles, sport)
+		state, throttled = metrics.AlertThrottle{}, false
+		log.Printf("Alert scanner: throttling for %s ended", sport)
+	}
+
+	deliver := found
+	if throttled {
+		deliver = make([]*alerts.ValueAlert, 0, len(found))
+		for _, a := range found {
+			if alerts.Edge(a) >= state.MinEdge {
+				deliver = append(deliver, a)
+			}
+		}
+	}
+	if len(deliver) > s.config.ThrottleAlerts {
+		sort.SliceStable(deliver, func(i, j int) bool { return alerts.Edge(deliver[i]) > alerts.Edge(deliver[j]) })
+		deliver = deliver[:s.config.ThrottleAlerts]
+		if cutoff := alerts.Edge(deliver[len(deliver)-1]); cutoff > state.MinEdge {
+			state.MinEdge = cutoff
+		}
+		state.Until = now.Add(s.config.ThrottleWindow)
+		s.throttles[sport] = state
+		throttled = true
+		log.Printf("Alert scanner: %d value alerts for %s, over the cap of %d; delivering alerts at %.2fx their threshold or more until %s",
+			len(found), sport, s.config.ThrottleAlerts, state.MinEdge, state.Until.Format("15:04"))
+	}
+
+	stats.AlertsThrottled = len(found) - len(deliver)
+	if throttled {
+		stats.Throttle = &state
+	}
+	s.status.AlertsThrottled += int64(stats.AlertsThrottled)
+	return deliver
+}
+
+// throttleStatus returns the sports currently throttled. The caller holds
+// s.mu.
+func (s *Scanner) throttleStatus() map[string]metrics.AlertThrottle {
+	now := s.clock.Now()
+	active := make(map[string]metrics.AlertThrottle)
+	for sport, state := range s.throttles {
+		if now.Before(state.Until) {
+			active[string(sport)] = state
+		}
+	}
+	return active
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// Default outlier margins: how far a book's line, in points, or its price,
+// in cents, can be from the consensus before the book is flagged
+const (
+	DefaultOutlierPoints = 1.0
+	DefaultOutlierCents  = 20.0
+)
+
+// minOutlierBooks is how many books must price an outcome before one can
+// stand out. With two, there's no telling which of them is off.
+const minOutlierBooks = 3
+
+// consensusMarkets are the markets checked for outlier books
+var consensusMarkets = []models.Market{models.MarketH2H, models.MarketSpreads, models.MarketTotals}
+
+// SetOutlierSettings sets how far, in points and in cents, a book can be
+// from the consensus before it's flagged. Zero or less restores a default.
+func (s *OddsService) SetOutlierSettings(points, cents float64) {
+	if points <= 0 {
+		points = DefaultOutlierPoints
+	}
+	if cents <= 0 {
+		cents = DefaultOutlierCents
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outlierPoints = points
+	s.outlierCents = cents
+}
+
+// OutlierSettings returns the outlier margins in points and cents
+func (s *OddsService) OutlierSettings() (float64, float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.outlierPoints, s.outlierCents
+}
+
+// bookQuote is one book's price, and line if it has one, for an outcome
+type bookQuote struct {
+	bookmaker string
+	key       string
+	price     float64
+	point     *float64
+}
+
+// Consensus takes the median line and price across bookmakers for each
+// outcome of a game's moneyline, spread and total. Books whose line is more
+// than the outlier points from the median, or whose price at the median
+// line is more than the outlier cents from the median price, are returned
+// as outliers, largest first. An outcome needs three books to have any.
+func (s *OddsService) Consensus(game models.Game) ([]models.ConsensusLine, []models.OutlierLine) {
+	points, cents := s.OutlierSettings()
+
+	var consensus []models.ConsensusLine
+	var outliers []models.OutlierLine
+	for _, market := range consensusMarkets {
+		quotes, outcomes := outcomeQuotes(game, market)
+		for _, name := range outcomes {
+			line, off := consensusForOutcome(game, market, name, quotes[name], points, cents)
+			consensus = append(consensus, line)
+			outliers = append(outliers, off...)
+		}
+	}
+
+	sort.SliceStable(outliers, func(i, j int) bool {
+		return outlierSize(outliers[i], points, cents) > outlierSize(outliers[j], points, cents)
+	})
+	return consensus, outliers
+}
+
+// outcomeQuotes collects each book's quote for a market by outcome, with
+// the outcomes in the order books first list them. Spreads and totals
+// without a line are skipped.
+func outcomeQuotes(game models.Game, market models.Market) (map[string][]bookQuote, []string) {
+	quotes := make(map[string][]bookQuote)
+	var outcomes []string
+	for _, bm := range game.Bookmakers {
+		for _, m := range bm.Markets {
+			if m.Key != market {
+				continue
+			}
+			for _, o := range m.Outcomes {
+				if o.Price == 0 || (market != models.MarketH2H && o.Point == nil) {
+					continue
+				}
+				if _, ok := quotes[o.Name]; !ok {
+					outcomes = append(outcomes, o.Name)
+				}
+				quotes[o.Name] = append(quotes[o.Name], bookQuote{
+					bookmaker: bm.Title,
+					key:       bm.Key,
+					price:     o.Price,
+					point:     o.Point,
+				})
+			}
+		}
+	}
+	return quotes, outcomes
+}
+
+// consensusForOutcome builds one outcome's consensus and flags the books
+// off it
+func consensusForOutcome(game models.Game, market models.Market, name string, quotes []bookQuote, pointsMargin, centsMargin float64) (models.ConsensusLine, []models.OutlierLine) {
+	line := models.ConsensusLine{Market: string(market), Outcome: name, Books: len(quotes)}
+
+	// Prices are only comparable between books dealing the same line
+	atLine := quotes
+	if quotes[0].point != nil {
+		points := make([]float64, len(quotes))
+		for i, q := range quotes {
+			points[i] = *q.point
+		}
+		mid := median(points)
+		line.Point = &mid
+
+		atLine = nil
+		for _, q := range quotes {
+			if *q.point == mid {
+				atLine = append(atLine, q)
+			}
+		}
+		if len(atLine) == 0 {
+			atLine = quotes
+		}
+	}
+	prices := make([]float64, len(atLine))
+	for i, q := range atLine {
+		prices[i] = models.PriceCents(q.price, -100)
+	}
+	line.Price = priceFromCents(math.Round(median(prices)))
+
+	if len(quotes) < minOutlierBooks {
+		return line, nil
+	}
+
+	var outliers []models.OutlierLine
+	for _, q := range quotes {
+		o := models.OutlierLine{
+			Category:       models.CategoryArbitrage,
+			GameID:         game.ID,
+			Sport:          string(game.SportKey),
+			HomeTeam:       game.HomeTeam,
+			AwayTeam:       game.AwayTeam,
+			CommenceTime:   game.CommenceTime,
+			Market:         string(market),
+			Outcome:        name,
+			Bookmaker:      q.bookmaker,
+			BookmakerKey:   q.key,
+			Point:          q.point,
+			Price:          q.price,
+			ConsensusPoint: line.Point,
+			ConsensusPrice: line.Price,
+			ConsensusBooks: len(quotes),
+		}
+
+		sameLine := q.point == nil || *q.point == *line.Point
+		switch {
+		case !sameLine && math.Abs(*q.point-*line.Point) > pointsMargin:
+			o.PointsOff = *q.point - *line.Point
+			o.Favorable = favorablePoints(market, name, o.PointsOff)
+		case sameLine && len(atLine) >= minOutlierBooks && math.Abs(models.PriceCents(q.price, line.Price)) > centsMargin:
+			o.CentsOff = models.PriceCents(q.price, line.Price)
+			o.Favorable = o.CentsOff > 0
+		default:
+			continue
+		}
+
+		o.ID = fmt.Sprintf("%s-%s-%s-%s", game.ID, market, name, q.key)
+		if q.point != nil {
+			o.ID += fmt.Sprintf("-%g", *q.point)
+		}
+		outliers = append(outliers, o)
+	}
+	return line, outliers
+}
+
+// favorablePoints reports whether a line that many points off the
+// consensus is better for the bettor: more points on a spread, a lower
+// total to go over or a higher one to stay under
+func favorablePoints(market models.Market, outcome string, off float64) bool {
+	if market == models.MarketTotals && outcome == "Over" {
+		return off < 0
+	}
+	return off > 0
+}
+
+// outlierSize is how far an outlier is off the consensus, in multiples of
+// its margin
+func outlierSize(o models.OutlierLine, pointsMargin, centsMargin float64) float64 {
+	if o.PointsOff != 0 {
+		return math.Abs(o.PointsOff) / pointsMargin
+	}
+	return math.Abs(o.CentsOff) / centsMargin
+}
+
+// median returns the middle value, or the mean of the middle two
+func median(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// priceFromCents turns cents from even money, as PriceCents counts them,
+// back into an American price
+func priceFromCents(cents float64) float64 {
+	if cents >= 0 {
+		return cents + 100
+	}
+	return cents - 100
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// DefaultEVThresholdPct is the expected value, in percent, a price needs over
+// fair odds to be flagged
+const DefaultEVThresholdPct = 2.0
+
+// minConsensusBooks is how many books must price a line before its
+// consensus is trusted for EV
+const minConsensusBooks = 2
+
+// ValidVigMethod reports whether method is a known vig removal method
+func ValidVigMethod(method string) bool {
+	return method == models.VigMultiplicative || method == models.VigPower
+}
+
+// SetEVSettings sets the vig removal method and the EV threshold, in percent,
+// used for fair odds and +EV flags
+func (s *OddsService) SetEVSettings(method string, thresholdPct float64) {
+	if !ValidVigMethod(method) {
+		method = models.VigMultiplicative
+	}
+	if thresholdPct <= 0 {
+		thresholdPct = DefaultEVThresholdPct
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vigMethod = method
+	s.evThresholdPct = thresholdPct
+}
+
+// EVSettings returns the vig removal method and EV threshold in use
+func (s *OddsService) EVSettings() (string, float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.vigMethod, s.evThresholdPct
+}
+
+// lineQuote is one book's prices for every outcome of a market at one line
+type lineQuote struct {
+	bookmaker string
+	key       string
+	prices    map[string]float64
+	points    map[string]*float64
+}
+
+// FairOdds removes the vig from each book's moneyline, spread and total,
+// averages the no-vig probabilities across books into a consensus per
+// outcome, and returns the prices beating it by at least the EV threshold.
+// Spreads and totals are only compared between books dealing the same line,
+// and each team's total on its own. Half and quarter markets are included
+// against their own threshold.
+func (s *OddsService) FairOdds(game models.Game) ([]models.FairOdds, []models.EVOpportunity) {
+	method, thresholdPct := s.EVSettings()
+	periodThresholdPct := s.PeriodEVThreshold()
+
+	var fair []models.FairOdds
+	var positive []models.EVOpportunity
+	markets := append([]models.Market{models.MarketH2H, models.MarketSpreads, models.MarketTotals}, eventMarkets(game)...)
+	for _, market := range markets {
+		base, period := models.SplitPeriod(market)
+		threshold := thresholdPct
+		if period != "" {
+			threshold = periodThresholdPct
+		}
+		// A team totals market holds an over and under for each team
+		teams := []string{""}
+		if base == models.MarketTeamTotals {
+			teams = []string{game.HomeTeam, game.AwayTeam}
+		}
+		for _, team := range teams {
+			for _, group := range groupByLine(game, market, team) {
+				f, ev := fairForLine(game, market, team, group, method, threshold)
+				fair = append(fair, f...)
+				positive = append(positive, ev...)
+			}
+		}
+	}
+
+	sort.SliceStable(positive, func(i, j int) bool {
+		return positive[i].EVPercent > positive[j].EVPercent
+	})
+	return fair, positive
+}
+
+// groupByLine collects each book's quote for a market, grouped by line.
+// Only outcomes for the given team are quoted, none meaning the game's.
+func groupByLine(game models.Game, market models.Market, team string) [][]lineQuote {
+	var order []string
+	groups := make(map[string][]lineQuote)
+	for _, bm := range game.Bookmakers {
+		for _, m := range bm.Markets {
+			if m.Key != market {
+				continue
+			}
+			outcomes := teamOutcomes(m.Outcomes, team)
+			if len(outcomes) < 2 {
+				continue
+			}
+
+			q := lineQuote{
+				bookmaker: bm.Title,
+				key:       bm.Key,
+				prices:    make(map[string]float64),
+				points:    make(map[string]*float64),
+			}
+			line := ""
+			for _, o := range outcomes {
+				q.prices[o.Name] = o.Price
+				q.points[o.Name] = o.Point
+				// The home spread or the total identifies the line
+				if o.Point != nil && (o.Name == game.HomeTeam || o.Name == "Over") {
+					line = fmt.Sprint(*o.Point)
+				}
+			}
+
+			if _, ok := groups[line]; !ok {
+				order = append(order, line)
+			}
+			groups[line] = append(groups[line], q)
+		}
+	}
+
+	result := make([][]lineQuote, 0, len(order))
+	for _, line := range order {
+		result = append(result, groups[line])
+	}
+	return result
+}
+
+// teamOutcomes returns a market's outcomes for a team, or the game's ones
+// when team is empty
+func teamOutcomes(outcomes []models.Outcome, team string) []models.Outcome {
+	var result []models.Outcome
+	for _, o := range outcomes {
+		if o.Description == team {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// fairForLine builds the consensus for one market line and flags prices
+// beating it. Team total outcomes are labelled with the team.
+func fairForLine(game models.Game, market models.Market, team string, quotes []lineQuote, method string, thresholdPct float64) ([]models.FairOdds, []models.EVOpportunity) {
+	// Outcomes in the order the first book lists them
+	var outcomes []string
+	for _, bm := range game.Bookmakers {
+		if bm.Key != quotes[0].key {
+			continue
+		}
+		for _, m := range bm.Markets {
+			if m.Key == market {
+				for _, o := range teamOutcomes(m.Outcomes, team) {
+					outcomes = append(outcomes, o.Name)
+				}
+			}
+		}
+	}
+
+	// No-vig probabilities per book and outcome, by each method
+	type noVig struct {
+		multiplicative map[string]float64
+		power          map[string]float64
+	}
+	perBook := make([]noVig, len(quotes))
+	for i, q := range quotes {
+		implied := make([]float64, len(outcomes))
+		for j, name := range outcomes {
+			price, ok := q.prices[name]
+			if !ok || price == 0 {
+				implied = nil
+				break
+			}
+			implied[j] = models.ImpliedProbability(price)
+		}
+		if implied == nil {
+			continue
+		}
+
+		mult := RemoveVig(implied, models.VigMultiplicative)
+		pow := RemoveVig(implied, models.VigPower)
+		perBook[i] = noVig{multiplicative: make(map[string]float64), power: make(map[string]float64)}
+		for j, name := range outcomes {
+			perBook[i].multiplicative[name] = mult[j]
+			perBook[i].power[name] = pow[j]
+		}
+	}
+
+	var fair []models.FairOdds
+	var positive []models.EVOpportunity
+	for _, name := range outcomes {
+		label := models.Outcome{Name: name, Description: team}.Label()
+		f := models.FairOdds{Market: string(market), Outcome: label}
+		var books int
+		for i, q := range quotes {
+			if perBook[i].multiplicative == nil {
+				continue
+			}
+			books++
+			f.Multiplicative += perBook[i].multiplicative[name]
+			f.Power += perBook[i].power[name]
+			if f.Point == nil {
+				f.Point = q.points[name]
+			}
+
+			selected := perBook[i].multiplicative[name]
+			if method == models.VigPower {
+				selected = perBook[i].power[name]
+			}
+			f.Books = append(f.Books, models.BookProbability{
+				Bookmaker: q.bookmaker,
+				Price:     q.prices[name],
+				Implied:   round4(models.ImpliedProbability(q.prices[name])),
+				NoVig:     round4(selected),
+			})
+		}
+		if books == 0 {
+			continue
+		}
+		f.Multiplicative /= float64(books)
+		f.Power /= float64(books)
+
+		probability := f.Multiplicative
+		if method == models.VigPower {
+			probability = f.Power
+		}
+		f.FairPrice = math.Round(models.AmericanPrice(probability))
+		f.Multiplicative = round4(f.Multiplicative)
+		f.Power = round4(f.Power)
+		fair = append(fair, f)
+
+		if books < minConsensusBooks {
+			continue
+		}
+		for i, q := range quotes {
+			if perBook[i].multiplicative == nil {
+				continue
+			}
+			price := q.prices[name]
+			ev := (probability*models.DecimalPayout(price) - 1) * 100
+			if ev < thresholdPct {
+				continue
+			}
+			id := fmt.Sprintf("%s-%s-%s-%s", game.ID, market, label, q.key)
+			if point := q.points[name]; point != nil {
+				id += fmt.Sprintf("-%g", *point)
+			}
+			positive = append(positive, models.EVOpportunity{
+				ID:                 id,
+				Category:           models.CategoryArbitrage,
+				GameID:             game.ID,
+				Sport:              string(game.SportKey),
+				HomeTeam:           game.HomeTeam,
+				AwayTeam:           game.AwayTeam,
+				CommenceTime:       game.CommenceTime,
+				Market:             string(market),
+				Outcome:            label,
+				Point:              q.points[name],
+				Bookmaker:          q.bookmaker,
+				BookmakerKey:       q.key,
+				Price:              price,
+				ImpliedProbability: round4(models.ImpliedProbability(price)),
+				FairProbability:    round4(probability),
+				FairPrice:          f.FairPrice,
+				EVPercent:          math.Round(ev*100) / 100,
+				Method:             method,
+				ConsensusBooks:     books,
+			})
+		}
+	}
+	return fair, positive
+}
+
+// RemoveVig turns implied probabilities that include a book's margin into
+// probabilities summing to 1
+func RemoveVig(implied []float64, method string) []float64 {
+	fair := make([]float64, len(implied))
+	var total float64
+	for _, p := range implied {
+		total += p
+	}
+	if total <= 0 {
+		return fair
+	}
+
+	if method != models.VigPower {
+		for i, p := range implied {
+			fair[i] = p / total
+		}
+		return fair
+	}
+
+	// Find k with sum(p^k) = 1 by bisection; the sum falls as k grows
+	sum := func(k float64) float64 {
+		var s float64
+		for _, p := range implied {
+			s += math.Pow(p, k)
+		}
+		return s
+	}
+	lo, hi := 0.01, 100.0
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if sum(mid) > 1 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	k := (lo + hi) / 2
+	for i, p := range implied {
+		fair[i] = math.Pow(p, k)
+	}
+	return fair
+}
+
+// round4 rounds a probability to four decimal places
+func round4(p float64) float64 {
+	return math.Round(p*10000) / 10000
+}
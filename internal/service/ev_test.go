@@ -0,0 +1,65 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+func TestRemoveVig(t *testing.T) {
+	tests := []struct {
+		name   string
+		prices []float64
+		method string
+		want   []float64
+	}{
+		// A symmetric market splits evenly either way
+		{"even multiplicative", []float64{-110, -110}, models.VigMultiplicative, []float64{0.5, 0.5}},
+		{"even power", []float64{-110, -110}, models.VigPower, []float64{0.5, 0.5}},
+		// Checked against Python: p / sum(p), and p^k with sum(p^k) = 1
+		// solved by bisection (k = 1.059323 and 1.086845)
+		{"favorite multiplicative", []float64{-200, 170}, models.VigMultiplicative, []float64{0.642857, 0.357143}},
+		{"favorite power", []float64{-200, 170}, models.VigPower, []float64{0.650822, 0.349178}},
+		{"three-way multiplicative", []float64{250, 260, -110}, models.VigMultiplicative, []float64{0.262774, 0.255474, 0.481752}},
+		{"three-way power", []float64{250, 260, -110}, models.VigPower, []float64{0.256261, 0.248534, 0.495205}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			implied := make([]float64, len(tt.prices))
+			for i, price := range tt.prices {
+				implied[i] = models.ImpliedProbability(price)
+			}
+			got := RemoveVig(implied, tt.method)
+			var total float64
+			for i := range got {
+				total += got[i]
+				if math.Abs(got[i]-tt.want[i]) > 1e-6 {
+					t.Errorf("outcome %d: got %.6f, want %.6f", i, got[i], tt.want[i])
+				}
+			}
+			if math.Abs(total-1) > 1e-9 {
+				t.Errorf("fair probabilities sum to %v, want 1", total)
+			}
+		})
+	}
+}
+
+// TestRemoveVigPowerFavorsFavorite checks the power method takes more of the
+// vig from the longshot than multiplicative does
+func TestRemoveVigPowerFavorsFavorite(t *testing.T) {
+	implied := []float64{models.ImpliedProbability(-200), models.ImpliedProbability(170)}
+	multiplicative := RemoveVig(implied, models.VigMultiplicative)
+	power := RemoveVig(implied, models.VigPower)
+	if power[0] <= multiplicative[0] || power[1] >= multiplicative[1] {
+		t.Errorf("power %v, multiplicative %v: want the favorite higher under power", power, multiplicative)
+	}
+}
+
+func TestRemoveVigEmpty(t *testing.T) {
+	for _, method := range []string{models.VigMultiplicative, models.VigPower} {
+		if got := RemoveVig([]float64{0, 0}, method); got[0] != 0 || got[1] != 0 {
+			t.Errorf("%s: got %v for no prices, want zeros", method, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"math"
+	"sort"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// IsAllowedBookmaker reports whether a bookmaker key is one we show odds for
+func IsAllowedBookmaker(key string) bool {
+	return allowedBookmakers[key]
+}
+
+// AllowedBookmakers returns the keys of the bookmakers we show odds for
+func AllowedBookmakers() []string {
+	keys := make([]string, 0, len(allowedBookmakers))
+	for key := range allowedBookmakers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CompareMyBook prices every side of a game's moneyline, spread and total at
+// the user's book against the best available price. Sides the book doesn't
+// offer are left out.
+func (s *OddsService) CompareMyBook(game models.Game, book string) []models.MyBookPrice {
+	type quote struct {
+		bookmaker string
+		price     float64
+		point     *float64
+	}
+
+	var mine *models.Bookmaker
+	for i := range game.Bookmakers {
+		if game.Bookmakers[i].Key == book {
+			mine = &game.Bookmakers[i]
+			break
+		}
+	}
+	if mine == nil {
+		return nil
+	}
+
+	var prices []models.MyBookPrice
+	for _, market := range []models.Market{models.MarketH2H, models.MarketSpreads, models.MarketTotals} {
+		// Best price per outcome across all books
+		best := make(map[string]quote)
+		for _, bm := range game.Bookmakers {
+			for _, m := range bm.Markets {
+				if m.Key != market {
+					continue
+				}
+				for _, o := range m.Outcomes {
+					if b, ok := best[o.Name]; !ok || o.Price > b.price {
+						best[o.Name] = quote{bookmaker: bm.Title, price: o.Price, point: o.Point}
+					}
+				}
+			}
+		}
+
+		for _, m := range mine.Markets {
+			if m.Key != market {
+				continue
+			}
+			for _, o := range m.Outcomes {
+				b := best[o.Name]
+				prices = append(prices, models.MyBookPrice{
+					Market:        string(market),
+					Outcome:       o.Name,
+					Bookmaker:     mine.Title,
+					Price:         o.Price,
+					Point:         o.Point,
+					BestBookmaker: b.bookmaker,
+					BestPrice:     b.price,
+					BestPoint:     b.point,
+					CentsGivenUp:  models.PriceCents(b.price, o.Price),
+					PointsGivenUp: pointsGivenUp(o.Name, o.Point, b.point),
+				})
+			}
+		}
+	}
+	return prices
+}
+
+// pointsGivenUp returns how many points worse a line is than the best
+// price's line. Higher is better for spreads and unders, lower for overs.
+func pointsGivenUp(outcome string, mine, best *float64) float64 {
+	if mine == nil || best == nil {
+		return 0
+	}
+	diff := *best - *mine
+	if outcome == "Over" {
+		diff = -diff
+	}
+	return math.Round(diff*10) / 10
+}
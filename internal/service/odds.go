@@ -2,6 +2,8 @@ package service
 
 import (
 	"math"
+	"sync"
+	"time"
 
 	"github.com/joshuakim/linefinder/internal/models"
 	"github.com/joshuakim/linefinder/internal/oddsapi"
@@ -19,13 +21,29 @@ var allowedBookmakers = map[string]bool{
 type OddsService struct {
 	client *oddsapi.Client
 	store  *store.Store
+
+	mu             sync.RWMutex
+	vigMethod      string
+	evThresholdPct float64
+
+	periodEVThresholdPct float64
+
+	// Outlier margins from the consensus, in points and in cents
+	outlierPoints float64
+	outlierCents  float64
 }
 
 // NewOddsService creates a new odds service
 func NewOddsService(client *oddsapi.Client, store *store.Store) *OddsService {
 	return &OddsService{
-		client: client,
-		store:  store,
+		client:         client,
+		store:          store,
+		vigMethod:      models.VigMultiplicative,
+		evThresholdPct: DefaultEVThresholdPct,
+
+		periodEVThresholdPct: DefaultPeriodEVThresholdPct,
+		outlierPoints:        DefaultOutlierPoints,
+		outlierCents:         DefaultOutlierCents,
 	}
 }
 
@@ -43,15 +61,20 @@ func filterBookmakers(games []models.Game) []models.Game {
 	return games
 }
 
-// FetchAndStoreOdds fetches odds from API and stores them
+// FetchAndStoreOdds fetches odds from API and stores them. Half and quarter
+// markets already stored for a game are kept until they're next fetched.
 func (s *OddsService) FetchAndStoreOdds(sport models.Sport) ([]models.Game, error) {
 	games, err := s.client.GetOdds(sport)
 	if err != nil {
 		return nil, err
 	}
 	games = filterBookmakers(games)
-	s.store.UpdateGames(games)
-	return games, nil
+	for i := range games {
+		if stored, found := s.store.GetGame(games[i].ID); found {
+			games[i] = carryPeriods(games[i], stored)
+		}
+	}
+	return s.store.UpdateGames(games), nil
 }
 
 // GetGamesBySport returns games for a sport from the store
@@ -60,6 +83,32 @@ func (s *OddsService) GetGamesBySport(sport models.Sport) []models.Game {
 	return filterBookmakers(games)
 }
 
+// GetAllGames returns every game in the store
+func (s *OddsService) GetAllGames() []models.Game {
+	return filterBookmakers(s.store.GetAllGames())
+}
+
+// SetGameStatuses moves games to new lifecycle states, removing final
+// ones, and returns the sports whose games changed
+func (s *OddsService) SetGameStatuses(statuses map[string]models.GameStatus) []models.Sport {
+	return s.store.SetStatuses(statuses)
+}
+
+// PinGame keeps a game in the store after it's final
+func (s *OddsService) PinGame(id string) {
+	s.store.Pin(id)
+}
+
+// UnpinGame lets a game be removed from the store once it's final
+func (s *OddsService) UnpinGame(id string) {
+	s.store.Unpin(id)
+}
+
+// LastChanged returns when a sport's games last changed in the store
+func (s *OddsService) LastChanged(sport models.Sport) time.Time {
+	return s.store.LastChanged(sport)
+}
+
 // GetGame returns a single game
 func (s *OddsService) GetGame(id string) (models.Game, bool) {
 	game, found := s.store.GetGame(id)
@@ -79,21 +128,32 @@ func (s *OddsService) CompareOdds(game models.Game) models.OddsComparison {
 		CommenceTime: game.CommenceTime,
 	}
 
-	comparison.Moneyline = s.compareMoneyline(game)
-	comparison.Spread = s.compareSpreads(game)
-	comparison.Total = s.compareTotals(game)
+	comparison.Moneyline = s.compareMoneyline(game, models.MarketH2H)
+	comparison.Spread = s.compareSpreads(game, models.MarketSpreads)
+	comparison.Total = s.compareTotals(game, models.MarketTotals, "")
+	comparison.TeamTotals = s.compareTeamTotals(game, models.MarketTeamTotals)
+	comparison.Periods = s.comparePeriods(game)
+
+	comparison.VigMethod, comparison.EVThresholdPct = s.EVSettings()
+	if len(comparison.Periods) > 0 {
+		comparison.PeriodEVThresholdPct = s.PeriodEVThreshold()
+	}
+	comparison.Fair, comparison.PositiveEV = s.FairOdds(game)
+	comparison.OutlierPoints, comparison.OutlierCents = s.OutlierSettings()
+	comparison.Consensus, comparison.Outliers = s.Consensus(game)
 
 	return comparison
 }
 
-func (s *OddsService) compareMoneyline(game models.Game) *models.MoneylineComparison {
+// compareMoneyline compares a moneyline market, full game or period
+func (s *OddsService) compareMoneyline(game models.Game, key models.Market) *models.MoneylineComparison {
 	var allBookmakers []models.BookmakerOdds
 	bestHome := models.BestOdds{Price: math.Inf(-1)}
 	bestAway := models.BestOdds{Price: math.Inf(-1)}
 
 	for _, bookmaker := range game.Bookmakers {
 		for _, market := range bookmaker.Markets {
-			if market.Key != models.MarketH2H {
+			if market.Key != key {
 				continue
 			}
 
@@ -136,14 +196,15 @@ func (s *OddsService) compareMoneyline(game models.Game) *models.MoneylineCompar
 	}
 }
 
-func (s *OddsService) compareSpreads(game models.Game) *models.SpreadComparison {
+// compareSpreads compares a spread market, full game or period
+func (s *OddsService) compareSpreads(game models.Game, key models.Market) *models.SpreadComparison {
 	var allBookmakers []models.BookmakerSpreadOdds
 	bestHome := models.BestSpreadOdds{Price: math.Inf(-1)}
 	bestAway := models.BestSpreadOdds{Price: math.Inf(-1)}
 
 	for _, bookmaker := range game.Bookmakers {
 		for _, market := range bookmaker.Markets {
-			if market.Key != models.MarketSpreads {
+			if market.Key != key {
 				continue
 			}
 
@@ -193,19 +254,21 @@ func (s *OddsService) compareSpreads(game models.Game) *models.SpreadComparison
 	}
 }
 
-func (s *OddsService) compareTotals(game models.Game) *models.TotalComparison {
+// compareTotals compares a total market, full game or period. For team
+// totals, only the given team's over and under are compared.
+func (s *OddsService) compareTotals(game models.Game, key models.Market, team string) *models.TotalComparison {
 	var allBookmakers []models.BookmakerTotalOdds
 	bestOver := models.BestTotalOdds{Price: math.Inf(-1)}
 	bestUnder := models.BestTotalOdds{Price: math.Inf(-1)}
 
 	for _, bookmaker := range game.Bookmakers {
 		for _, market := range bookmaker.Markets {
-			if market.Key != models.MarketTotals {
+			if market.Key != key {
 				continue
 			}
 
 			var overPrice, underPrice, point float64
-			for _, outcome := range market.Outcomes {
+			for _, outcome := range teamOutcomes(market.Outcomes, team) {
 				if outcome.Name == "Over" && outcome.Point != nil {
 					overPrice = outcome.Price
 					point = *outcome.Point
@@ -246,3 +309,14 @@ func (s *OddsService) compareTotals(game models.Game) *models.TotalComparison {
 		AllBookmakers: allBookmakers,
 	}
 }
+
+// compareTeamTotals compares each team's total in a team totals market,
+// full game or period
+func (s *OddsService) compareTeamTotals(game models.Game, key models.Market) *models.TeamTotalsComparison {
+	home := s.compareTotals(game, key, game.HomeTeam)
+	away := s.compareTotals(game, key, game.AwayTeam)
+	if home == nil && away == nil {
+		return nil
+	}
+	return &models.TeamTotalsComparison{Home: home, Away: away}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// DefaultPeriodEVThresholdPct is the EV threshold for half and quarter
+// prices. Fewer books deal them, so their consensus is noisier than the
+// full game's and needs a wider margin.
+const DefaultPeriodEVThresholdPct = 3.0
+
+// SetPeriodEVThreshold sets the EV threshold, in percent, for half and
+// quarter prices
+func (s *OddsService) SetPeriodEVThreshold(thresholdPct float64) {
+	if thresholdPct <= 0 {
+		thresholdPct = DefaultPeriodEVThresholdPct
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.periodEVThresholdPct = thresholdPct
+}
+
+// PeriodEVThreshold returns the EV threshold in use for half and quarter
+// prices
+func (s *OddsService) PeriodEVThreshold() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.periodEVThresholdPct
+}
+
+// FetchAndStorePeriodOdds fetches half and quarter markets for a sport's
+// games that haven't started and merges them into the stored games. Each
+// game is a separate request costing one credit per market. Games that
+// fail keep the period markets they had, and the errors are returned with
+// the sport's games as stored, or none when no game was fetched.
+func (s *OddsService) FetchAndStorePeriodOdds(sport models.Sport, markets []models.Market, now time.Time) ([]models.Game, error) {
+	games := s.store.GetGamesBySport(sport)
+	fetched := 0
+	var errs []error
+	for i, game := range games {
+		if game.Started(now) {
+			continue
+		}
+		event, err := s.client.GetEventOdds(sport, game.ID, markets)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", game.ID, err))
+			continue
+		}
+		event.Bookmakers = filterBookmakers([]models.Game{event})[0].Bookmakers
+		games[i] = mergePeriods(game, event, markets)
+		fetched++
+	}
+	if fetched == 0 {
+		return nil, errors.Join(errs...)
+	}
+	// The whole sport is stored so watchers see every game, as after a poll
+	return s.store.UpdateGames(games), errors.Join(errs...)
+}
+
+// mergePeriods replaces a game's period markets of the given keys with an
+// event's. Books the game doesn't list yet are added.
+func mergePeriods(game, event models.Game, markets []models.Market) models.Game {
+	fetched := make(map[models.Market]bool, len(markets))
+	for _, m := range markets {
+		fetched[m] = true
+	}
+
+	bookmakers := make([]models.Bookmaker, 0, len(game.Bookmakers))
+	seen := make(map[string]bool)
+	for _, bm := range game.Bookmakers {
+		seen[bm.Key] = true
+		kept := make([]models.MarketData, 0, len(bm.Markets))
+		for _, m := range bm.Markets {
+			if !fetched[m.Key] {
+				kept = append(kept, m)
+			}
+		}
+		for _, ebm := range event.Bookmakers {
+			if ebm.Key == bm.Key {
+				kept = append(kept, ebm.Markets...)
+			}
+		}
+		bm.Markets = kept
+		bookmakers = append(bookmakers, bm)
+	}
+	for _, ebm := range event.Bookmakers {
+		if !seen[ebm.Key] {
+			bookmakers = append(bookmakers, ebm)
+		}
+	}
+	game.Bookmakers = bookmakers
+	return game
+}
+
+// carryPeriods copies the per-game markets stored for a game, period
+// markets and team totals, onto a fresh full-game poll of it, which doesn't
+// include them. Only books the poll lists keep theirs, so coverage reflects
+// the full-game markets.
+func carryPeriods(game models.Game, stored models.Game) models.Game {
+	for i, bm := range game.Bookmakers {
+		for _, sbm := range stored.Bookmakers {
+			if sbm.Key != bm.Key {
+				continue
+			}
+			markets := append([]models.MarketData(nil), bm.Markets...)
+			for _, m := range sbm.Markets {
+				if models.EventOnly(m.Key) {
+					markets = append(markets, m)
+				}
+			}
+			game.Bookmakers[i].Markets = markets
+		}
+	}
+	return game
+}
+
+// comparePeriods compares each period's markets that any book prices, in
+// game order
+func (s *OddsService) comparePeriods(game models.Game) []models.PeriodComparison {
+	var result []models.PeriodComparison
+	for _, period := range models.Periods() {
+		c := models.PeriodComparison{
+			Period:     period,
+			Label:      period.Label(),
+			Moneyline:  s.compareMoneyline(game, models.PeriodMarket(models.MarketH2H, period)),
+			Spread:     s.compareSpreads(game, models.PeriodMarket(models.MarketSpreads, period)),
+			Total:      s.compareTotals(game, models.PeriodMarket(models.MarketTotals, period), ""),
+			TeamTotals: s.compareTeamTotals(game, models.PeriodMarket(models.MarketTeamTotals, period)),
+		}
+		if c.Moneyline != nil || c.Spread != nil || c.Total != nil || c.TeamTotals != nil {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// eventMarkets lists the per-game markets, period markets and team totals,
+// any of a game's books price, in the order they first appear
+func eventMarkets(game models.Game) []models.Market {
+	var markets []models.Market
+	seen := make(map[models.Market]bool)
+	for _, bm := range game.Bookmakers {
+		for _, m := range bm.Markets {
+			if models.EventOnly(m.Key) && !seen[m.Key] {
+				seen[m.Key] = true
+				markets = append(markets, m.Key)
+			}
+		}
+	}
+	return markets
+}
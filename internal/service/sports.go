@@ -0,0 +1,232 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/oddsapi"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
+)
+
+// sportsListTTL is how long the upstream sports list is cached
+const sportsListTTL = 24 * time.Hour
+
+// ErrUnknownSport is returned when enabling a sport the Odds API doesn't list
+var ErrUnknownSport = errors.New("sport not offered by the Odds API")
+
+// SportListing is an upstream sport with its local state
+type SportListing struct {
+	database.UpstreamSport
+	Enabled bool `json:"enabled"`
+
+	// Props is whether player props and value alerts are supported
+	Props bool `json:"props"`
+}
+
+// SportsCatalog lists the sports offered upstream and tracks which are
+// enabled locally
+type SportsCatalog struct {
+	client *oddsapi.Client
+	db     *database.DB
+	clock  clock.Clock
+
+	mu       sync.RWMutex
+	enabled  map[models.Sport]bool
+	onChange func([]models.Sport)
+}
+
+// NewSportsCatalog creates a new sports catalog
+func NewSportsCatalog(client *oddsapi.Client, db *database.DB) *SportsCatalog {
+	return &SportsCatalog{
+		client:  client,
+		db:      db,
+		clock:   clock.Real{},
+		enabled: make(map[models.Sport]bool),
+	}
+}
+
+// SetClock sets the clock used to expire the cached sports list
+func (c *SportsCatalog) SetClock(cl clock.Clock) {
+	c.clock = cl
+}
+
+// OnChange sets a callback invoked with the enabled sports after each change
+func (c *SportsCatalog) OnChange(fn func([]models.Sport)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = fn
+}
+
+// Load reads the enabled sports from the database, seeding it with the
+// defaults on first run
+func (c *SportsCatalog) Load(defaults []models.Sport) error {
+	settings, err := c.db.GetSportSettings()
+	if err != nil {
+		return err
+	}
+
+	if len(settings) == 0 {
+		for _, sport := range defaults {
+			if err := c.db.SetSportEnabled(string(sport), true); err != nil {
+				return err
+			}
+			settings[string(sport)] = true
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.enabled = make(map[models.Sport]bool)
+	for key, enabled := range settings {
+		if enabled {
+			c.enabled[models.Sport(key)] = true
+		}
+	}
+	return nil
+}
+
+// Enabled returns the locally enabled sports, sorted by key
+func (c *SportsCatalog) Enabled() []models.Sport {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.enabledLocked()
+}
+
+func (c *SportsCatalog) enabledLocked() []models.Sport {
+	sports := make([]models.Sport, 0, len(c.enabled))
+	for sport := range c.enabled {
+		sports = append(sports, sport)
+	}
+	sort.Slice(sports, func(i, j int) bool { return sports[i] < sports[j] })
+	return sports
+}
+
+// IsEnabled returns whether a sport is enabled locally
+func (c *SportsCatalog) IsEnabled(sport models.Sport) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.enabled[sport]
+}
+
+// List returns the upstream sports list with local state. The list is
+// refreshed from the Odds API when older than a day or when refresh is set;
+// if the refresh fails, the cached list is returned.
+func (c *SportsCatalog) List(refresh bool) ([]SportListing, error) {
+	upstream, err := c.upstream(refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	listed := make(map[string]bool, len(upstream))
+	listings := make([]SportListing, 0, len(upstream))
+	for _, s := range upstream {
+		listed[s.Key] = true
+		listings = append(listings, c.listing(s))
+	}
+
+	// Enabled sports that are out of season drop off the upstream list
+	for _, sport := range c.enabledLocked() {
+		if !listed[string(sport)] {
+			listings = append(listings, c.listing(database.UpstreamSport{Key: string(sport), Title: string(sport)}))
+		}
+	}
+
+	return listings, nil
+}
+
+func (c *SportsCatalog) listing(s database.UpstreamSport) SportListing {
+	return SportListing{
+		UpstreamSport: s,
+		Enabled:       c.enabled[models.Sport(s.Key)],
+		Props:         len(taxonomy.All(models.Sport(s.Key))) > 0,
+	}
+}
+
+// upstream returns the cached sports list, refreshing it when stale
+func (c *SportsCatalog) upstream(refresh bool) ([]database.UpstreamSport, error) {
+	cached, err := c.db.GetUpstreamSports()
+	if err != nil {
+		return nil, err
+	}
+
+	stale := len(cached) == 0 || c.clock.Now().Sub(cached[0].FetchedAt) > sportsListTTL
+	if !refresh && !stale {
+		return cached, nil
+	}
+
+	sports, err := c.client.GetSports()
+	if err != nil {
+		if len(cached) > 0 {
+			log.Printf("Sports: refresh failed, using cached list: %v", err)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	upstream := make([]database.UpstreamSport, len(sports))
+	for i, s := range sports {
+		upstream[i] = database.UpstreamSport{
+			Key:         s.Key,
+			Group:       s.Group,
+			Title:       s.Title,
+			Description: s.Description,
+			Active:      s.Active,
+		}
+	}
+	if err := c.db.SaveUpstreamSports(upstream); err != nil {
+		return nil, err
+	}
+
+	return c.db.GetUpstreamSports()
+}
+
+// SetEnabled enables or disables a sport and returns the enabled sports.
+// Only sports listed by the Odds API can be enabled.
+func (c *SportsCatalog) SetEnabled(sport models.Sport, enabled bool) ([]models.Sport, error) {
+	if enabled {
+		upstream, err := c.upstream(false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load sports list: %w", err)
+		}
+		found := false
+		for _, s := range upstream {
+			if s.Key == string(sport) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrUnknownSport
+		}
+	}
+
+	if err := c.db.SetSportEnabled(string(sport), enabled); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if enabled {
+		c.enabled[sport] = true
+	} else {
+		delete(c.enabled, sport)
+	}
+	sports := c.enabledLocked()
+	onChange := c.onChange
+	c.mu.Unlock()
+
+	log.Printf("Sports: %s enabled=%v (now %v)", sport, enabled, sports)
+	if onChange != nil {
+		onChange(sports)
+	}
+	return sports, nil
+}
@@ -0,0 +1,167 @@
+package slates
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// Slate keys, in display order
+const (
+	Earlier  = "earlier"
+	Today    = "today"
+	Tomorrow = "tomorrow"
+	ThisWeek = "this_week"
+	Later    = "later"
+)
+
+// Order lists slate keys in display order
+var Order = []string{Earlier, Today, Tomorrow, ThisWeek, Later}
+
+var labels = map[string]string{
+	Earlier:  "Earlier",
+	Today:    "Today",
+	Tomorrow: "Tomorrow",
+	ThisWeek: "This Week",
+	Later:    "Later",
+}
+
+// Label returns the display label for a slate key
+func Label(key string) string {
+	if label, ok := labels[key]; ok {
+		return label
+	}
+	return key
+}
+
+// nflRegularSeasonWeeks is the number of NFL regular season weeks
+const nflRegularSeasonWeeks = 18
+
+// Assignment places a game on a slate
+type Assignment struct {
+	Slate     string `json:"slate"`
+	LocalDate string `json:"local_date"`
+
+	// Week is the NFL week (1-18 regular season, higher for postseason),
+	// zero for other sports and preseason
+	Week int `json:"week,omitempty"`
+
+	// GameNumber is 1 or 2 when the same teams play twice on one local day
+	GameNumber int `json:"game_number,omitempty"`
+}
+
+// Assign places each game on a slate using day boundaries in loc, keyed by
+// game ID
+func Assign(games []models.Game, sport models.Sport, now time.Time, loc *time.Location) map[string]Assignment {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	assignments := make(map[string]Assignment, len(games))
+	matchups := make(map[string][]models.Game)
+
+	for _, g := range games {
+		local := g.CommenceTime.In(loc)
+		a := Assignment{
+			Slate:     slateFor(local, now.In(loc)),
+			LocalDate: local.Format("2006-01-02"),
+		}
+		if sport == models.SportNFL {
+			a.Week = NFLWeek(g.CommenceTime)
+		}
+		assignments[g.ID] = a
+
+		key := a.LocalDate + "|" + g.HomeTeam + "|" + g.AwayTeam
+		matchups[key] = append(matchups[key], g)
+	}
+
+	// Doubleheaders: number same-day meetings by start time
+	for _, meetings := range matchups {
+		if len(meetings) < 2 {
+			continue
+		}
+		sort.Slice(meetings, func(i, j int) bool {
+			return meetings[i].CommenceTime.Before(meetings[j].CommenceTime)
+		})
+		for i, g := range meetings {
+			a := assignments[g.ID]
+			a.GameNumber = i + 1
+			assignments[g.ID] = a
+		}
+	}
+
+	return assignments
+}
+
+// slateFor returns the slate for a start time, both in the viewer's zone
+func slateFor(start, now time.Time) string {
+	days := daysBetween(now, start)
+	switch {
+	case days < 0:
+		return Earlier
+	case days == 0:
+		return Today
+	case days == 1:
+		return Tomorrow
+	case days < 7:
+		return ThisWeek
+	default:
+		return Later
+	}
+}
+
+// daysBetween counts calendar days from a to b in their own zones, so DST
+// changes don't shift the boundary
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	da := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	db := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+// NFLWeek returns the NFL week for a kickoff. Weeks run Tuesday through
+// Monday Eastern, starting the day after Labor Day. Preseason returns zero.
+func NFLWeek(kickoff time.Time) int {
+	eastern, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		eastern = time.UTC
+	}
+	local := kickoff.In(eastern)
+
+	// Seasons straddle the new year; January and February belong to the
+	// previous season
+	season := local.Year()
+	if local.Month() < time.March {
+		season--
+	}
+
+	days := daysBetween(laborDay(season).AddDate(0, 0, 1), local)
+	if days < 0 {
+		return 0
+	}
+	return days/7 + 1
+}
+
+// WeekLabel returns a display label for an NFL week
+func WeekLabel(week int) string {
+	switch {
+	case week <= 0:
+		return "Preseason"
+	case week <= nflRegularSeasonWeeks:
+		return fmt.Sprintf("Week %d", week)
+	default:
+		return "Postseason"
+	}
+}
+
+// laborDay returns the first Monday of September
+func laborDay(year int) time.Time {
+	d := time.Date(year, time.September, 1, 0, 0, 0, 0, time.UTC)
+	for d.Weekday() != time.Monday {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -56,6 +57,7 @@ type PlayerGameStats struct {
 	Steals            float64 `json:"Steals"`
 	BlockedShots      float64 `json:"BlockedShots"`
 	ThreePointersMade float64 `json:"ThreePointersMade"`
+	Turnovers         float64 `json:"Turnovers"`
 	Minutes           int     `json:"Minutes"`
 	// NFL Stats
 	PassingYards      float64 `json:"PassingYards"`
@@ -92,6 +94,163 @@ func (c *Client) GetNFLPlayerGameStats(season string, playerID int) ([]PlayerGam
 	return c.fetchPlayerGameStats(url)
 }
 
+// NBAGame is a scheduled NBA game with its officiating crew, once assigned
+type NBAGame struct {
+	GameID      int    `json:"GameID"`
+	Status      string `json:"Status"`
+	DateTime    string `json:"DateTime"`
+	HomeTeam    string `json:"HomeTeam"`
+	AwayTeam    string `json:"AwayTeam"`
+	CrewChiefID *int   `json:"CrewChiefID"`
+	RefereeID   *int   `json:"RefereeID"`
+	UmpireID    *int   `json:"UmpireID"`
+}
+
+// LiveGame is an NBA game's score and clock, updated while it's in
+// progress. Quarter is "1" to "4", "Half", "OT", "2OT" and so on, or nil
+// before tip-off.
+type LiveGame struct {
+	GameID               int     `json:"GameID"`
+	Status               string  `json:"Status"`
+	DateTime             string  `json:"DateTime"`
+	HomeTeam             string  `json:"HomeTeam"`
+	AwayTeam             string  `json:"AwayTeam"`
+	HomeTeamScore        *int    `json:"HomeTeamScore"`
+	AwayTeamScore        *int    `json:"AwayTeamScore"`
+	Quarter              *string `json:"Quarter"`
+	TimeRemainingMinutes *int    `json:"TimeRemainingMinutes"`
+	TimeRemainingSeconds *int    `json:"TimeRemainingSeconds"`
+	IsClosed             bool    `json:"IsClosed"`
+}
+
+// BoxScore is a game's score and clock with its players' stats so far
+type BoxScore struct {
+	Game        LiveGame          `json:"Game"`
+	PlayerGames []PlayerGameStats `json:"PlayerGames"`
+}
+
+// Referee is an NBA official
+type Referee struct {
+	RefereeID int    `json:"RefereeID"`
+	Name      string `json:"Name"`
+	Number    int    `json:"Number"`
+	Position  string `json:"Position"`
+}
+
+// GetNBAGamesByDate fetches NBA games scheduled on a date
+func (c *Client) GetNBAGamesByDate(date time.Time) ([]NBAGame, error) {
+	url := fmt.Sprintf("%s/nba/scores/json/GamesByDate/%s?key=%s", baseURL, strings.ToUpper(date.Format("2006-Jan-02")), c.apiKey)
+
+	var games []NBAGame
+	if err := c.fetchJSON(url, &games); err != nil {
+		return nil, fmt.Errorf("failed to fetch games: %w", err)
+	}
+	return games, nil
+}
+
+// GetNBABoxScores fetches live and final NBA box scores for a date
+func (c *Client) GetNBABoxScores(date time.Time) ([]BoxScore, error) {
+	url := fmt.Sprintf("%s/nba/stats/json/BoxScores/%s?key=%s", baseURL, strings.ToUpper(date.Format("2006-Jan-02")), c.apiKey)
+
+	var boxScores []BoxScore
+	if err := c.fetchJSON(url, &boxScores); err != nil {
+		return nil, fmt.Errorf("failed to fetch box scores: %w", err)
+	}
+	return boxScores, nil
+}
+
+// GetNBAReferees fetches all NBA officials
+func (c *Client) GetNBAReferees() ([]Referee, error) {
+	url := fmt.Sprintf("%s/nba/scores/json/Referees?key=%s", baseURL, c.apiKey)
+
+	var referees []Referee
+	if err := c.fetchJSON(url, &referees); err != nil {
+		return nil, fmt.Errorf("failed to fetch referees: %w", err)
+	}
+	return referees, nil
+}
+
+// LineupPlayer is a player on a starting lineup
+type LineupPlayer struct {
+	PlayerID  int    `json:"PlayerID"`
+	Name      string `json:"Name"`
+	Team      string `json:"Team"`
+	Position  string `json:"Position"`
+	Starting  bool   `json:"Starting"`
+	Confirmed bool   `json:"Confirmed"`
+}
+
+// StartingLineups holds the projected or confirmed lineups for a game
+type StartingLineups struct {
+	GameID     int            `json:"GameID"`
+	DateTime   string         `json:"DateTime"`
+	HomeTeam   string         `json:"HomeTeam"`
+	AwayTeam   string         `json:"AwayTeam"`
+	HomeLineup []LineupPlayer `json:"HomeLineup"`
+	AwayLineup []LineupPlayer `json:"AwayLineup"`
+}
+
+// GetNBAStartingLineups fetches projected and confirmed NBA starting lineups
+// for a date
+func (c *Client) GetNBAStartingLineups(date time.Time) ([]StartingLineups, error) {
+	url := fmt.Sprintf("%s/nba/projections/json/StartingLineupsByDate/%s?key=%s", baseURL, strings.ToUpper(date.Format("2006-Jan-02")), c.apiKey)
+
+	var lineups []StartingLineups
+	if err := c.fetchJSON(url, &lineups); err != nil {
+		return nil, fmt.Errorf("failed to fetch starting lineups: %w", err)
+	}
+	return lineups, nil
+}
+
+// DepthChartEntry is a player's place on an NFL depth chart
+type DepthChartEntry struct {
+	PlayerID         int    `json:"PlayerID"`
+	TeamID           int    `json:"TeamID"`
+	Name             string `json:"Name"`
+	PositionCategory string `json:"PositionCategory"`
+	Position         string `json:"Position"`
+	DepthOrder       int    `json:"DepthOrder"`
+	Updated          string `json:"Updated"`
+}
+
+// TeamDepthChart is a team's NFL depth chart by unit
+type TeamDepthChart struct {
+	TeamID       int               `json:"TeamID"`
+	Offense      []DepthChartEntry `json:"Offense"`
+	Defense      []DepthChartEntry `json:"Defense"`
+	SpecialTeams []DepthChartEntry `json:"SpecialTeams"`
+}
+
+// GetNFLDepthCharts fetches current depth charts for every NFL team
+func (c *Client) GetNFLDepthCharts() ([]TeamDepthChart, error) {
+	url := fmt.Sprintf("%s/nfl/scores/json/DepthCharts?key=%s", baseURL, c.apiKey)
+
+	var charts []TeamDepthChart
+	if err := c.fetchJSON(url, &charts); err != nil {
+		return nil, fmt.Errorf("failed to fetch depth charts: %w", err)
+	}
+	return charts, nil
+}
+
+// Ping checks that the API is reachable and the key is accepted, using the
+// lightweight games-in-progress endpoint
+func (c *Client) Ping() error {
+	url := fmt.Sprintf("%s/nba/scores/json/AreAnyGamesInProgress?key=%s", baseURL, c.apiKey)
+
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to reach SportsDataIO: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
 func (c *Client) fetchPlayers(url string) ([]Player, error) {
 	resp, err := c.httpClient.Get(url)
 	if err != nil {
@@ -131,3 +290,21 @@ func (c *Client) fetchPlayerGameStats(url string) ([]PlayerGameStats, error) {
 
 	return stats, nil
 }
+
+func (c *Client) fetchJSON(url string, v interface{}) error {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,455 @@
+package steam
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/lineseries"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/store"
+	"github.com/joshuakim/linefinder/internal/taxonomy"
+)
+
+// Units a move is measured in
+const (
+	// Points is spread, total and prop lines
+	Points = "points"
+
+	// Cents is prices, in cents of American odds, for moneylines and for
+	// lines whose point held while the price moved
+	Cents = "cents"
+)
+
+// recentRetention is how long detected steam is listed after it's found
+const recentRetention = 6 * time.Hour
+
+// Config holds steam detector configuration
+type Config struct {
+	// Window is how far back a line is compared with its current value
+	Window time.Duration
+
+	// Points is how far a spread, total or prop line must move within the
+	// window to count at a bookmaker
+	Points float64
+
+	// Cents is how far a price must move within the window, at the same
+	// line, to count at a bookmaker
+	Cents float64
+
+	// MinBooks is how many bookmakers must move the same way before it's
+	// steam rather than one book adjusting
+	MinBooks int
+
+	// Cooldown is how long a game's market stays quiet after steam, and
+	// an outcome after a sharp divergence
+	Cooldown time.Duration
+
+	// DivergencePoints and DivergenceCents are how far a sharp book must
+	// move a line or price within the window, and how far recreational
+	// books must still be from its new number, for a sharp divergence
+	DivergencePoints float64
+	DivergenceCents  float64
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Window:   15 * time.Minute,
+		Points:   1.0,
+		Cents:    20,
+		MinBooks: 2,
+		Cooldown: 30 * time.Minute,
+
+		DivergencePoints: 0.5,
+		DivergenceCents:  10,
+	}
+}
+
+// BookMove is one bookmaker's part in a steam move
+type BookMove struct {
+	Bookmaker string  `json:"bookmaker"`
+	From      float64 `json:"from"` // line or price at the start of the window
+	To        float64 `json:"to"`
+	Move      float64 `json:"move"` // in the steam's unit
+}
+
+// Steam is a line moving the same way at several bookmakers within the
+// window, the mark of sharp money hitting a market
+type Steam struct {
+	GameID       string     `json:"game_id"`
+	Sport        string     `json:"sport"`
+	HomeTeam     string     `json:"home_team"`
+	AwayTeam     string     `json:"away_team"`
+	CommenceTime time.Time  `json:"commence_time"`
+	Market       string     `json:"market"`
+	Player       string     `json:"player,omitempty"` // for player props, or the team for team totals
+	Outcome      string     `json:"outcome"`
+	Unit         string     `json:"unit"`
+	Move         float64    `json:"move"` // average across the books moving
+	Books        []BookMove `json:"books"`
+	Window       float64    `json:"window_minutes"`
+	DetectedAt   time.Time  `json:"detected_at"`
+}
+
+// Subject names the line that moved, e.g. "Boston Celtics spreads" or
+// "Jayson Tatum Points Over"
+func (s Steam) Subject() string {
+	return subject(s.Market, s.Player, s.Outcome)
+}
+
+// subject names a line for alert text
+func subject(market, player, outcome string) string {
+	if player != "" {
+		return player + " " + taxonomy.Normalize(market) + " " + outcome
+	}
+	return outcome + " " + market
+}
+
+// Summary describes the move at each book, e.g. "Boston Celtics spreads
+// moved at 2 books in 15 minutes: draftkings -3 to -4, fanduel -3 to -4"
+func (s Steam) Summary() string {
+	moves := make([]string, len(s.Books))
+	for i, b := range s.Books {
+		if s.Unit == Cents {
+			moves[i] = fmt.Sprintf("%s %+.0f to %+.0f", b.Bookmaker, b.From, b.To)
+		} else {
+			moves[i] = fmt.Sprintf("%s %g to %g", b.Bookmaker, b.From, b.To)
+		}
+	}
+	return fmt.Sprintf("%s moved at %d books in %.0f minutes: %s",
+		s.Subject(), len(s.Books), s.Window, strings.Join(moves, ", "))
+}
+
+// sample is an outcome's line and price. Moneylines have no line.
+type sample struct {
+	line    float64
+	hasLine bool
+	price   float64
+}
+
+// info is the outcome at one bookmaker a series follows
+type info struct {
+	game    models.Game
+	book    string
+	market  string
+	player  string
+	outcome string
+}
+
+// series is one outcome at one bookmaker
+type series = lineseries.Series[sample, info]
+
+// Detector compares each poll's lines with those seen over the window and
+// reports markets moving at several bookmakers at once
+type Detector struct {
+	config Config
+	clock  clock.Clock
+
+	updates     <-chan store.Update
+	unsubscribe func()
+
+	mu        sync.RWMutex
+	series    *lineseries.Tracker[sample, info] // game|book|market|player|outcome
+	lastAlert map[string]time.Time              // game|market|player
+	recent    []Steam
+	callback  func([]Steam)
+
+	lastDivergence     map[string]time.Time // game|market|player|outcome
+	divergenceCallback func([]SharpDivergence)
+}
+
+// NewDetector creates a new steam detector. It subscribes to the store
+// right away so updates written before Start aren't missed.
+func NewDetector(config Config, dataStore *store.Store) *Detector {
+	defaults := DefaultConfig()
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.Points <= 0 {
+		config.Points = defaults.Points
+	}
+	if config.Cents <= 0 {
+		config.Cents = defaults.Cents
+	}
+	if config.MinBooks < 2 {
+		config.MinBooks = defaults.MinBooks
+	}
+	if config.Cooldown < 0 {
+		config.Cooldown = defaults.Cooldown
+	}
+	if config.DivergencePoints <= 0 {
+		config.DivergencePoints = defaults.DivergencePoints
+	}
+	if config.DivergenceCents <= 0 {
+		config.DivergenceCents = defaults.DivergenceCents
+	}
+	updates, unsubscribe := dataStore.Watch("")
+	// Moves are compared over the window; series outlive it by one more
+	return &Detector{
+		config:         config,
+		clock:          clock.Real{},
+		updates:        updates,
+		unsubscribe:    unsubscribe,
+		series:         lineseries.New[sample, info](config.Window, 2*config.Window),
+		lastAlert:      make(map[string]time.Time),
+		lastDivergence: make(map[string]time.Time),
+	}
+}
+
+// SetClock sets the clock used to time samples
+func (d *Detector) SetClock(c clock.Clock) {
+	d.clock = c
+}
+
+// SetCallback sets the function called with steam found in an update
+func (d *Detector) SetCallback(fn func([]Steam)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.callback = fn
+}
+
+// Start records store updates until the context is cancelled
+func (d *Detector) Start(ctx context.Context) {
+	defer d.unsubscribe()
+
+	log.Printf("Steam detector starting (window: %v, thresholds: %g points, %g cents, %d books)",
+		d.config.Window, d.config.Points, d.config.Cents, d.config.MinBooks)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case u, ok := <-d.updates:
+			if !ok {
+				return
+			}
+			if !u.Changed {
+				continue
+			}
+			found := d.record(u.Games)
+			divergences := d.newDivergences()
+
+			d.mu.RLock()
+			callback, divergenceCallback := d.callback, d.divergenceCallback
+			d.mu.RUnlock()
+			if callback != nil && len(found) > 0 {
+				callback(found)
+			}
+			if divergenceCallback != nil && len(divergences) > 0 {
+				divergenceCallback(divergences)
+			}
+		}
+	}
+}
+
+// Recent returns steam found within the last few hours on games that
+// haven't started, newest first. An empty sport returns every sport.
+func (d *Detector) Recent(sport models.Sport) []Steam {
+	now := d.clock.Now()
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	result := []Steam{}
+	for i := len(d.recent) - 1; i >= 0; i-- {
+		s := d.recent[i]
+		if sport != "" && s.Sport != string(sport) {
+			continue
+		}
+		if !s.CommenceTime.After(now) || now.Sub(s.DetectedAt) > recentRetention {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// record adds each upcoming game's lines, props included, to their series
+// and returns the markets moving the same way at enough bookmakers
+func (d *Detector) record(games []models.Game) []Steam {
+	now := d.clock.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var seen []*series
+	for _, game := range games {
+		if !game.CommenceTime.After(now) {
+			continue
+		}
+		for _, bm := range game.Bookmakers {
+			for _, market := range bm.Markets {
+				for _, o := range market.Outcomes {
+					seen = append(seen, d.observe(game, bm.Key, string(market.Key), o.Description, o.Name, o.Point, o.Price, now))
+				}
+			}
+		}
+		if _, ok := models.LookupSport(string(game.SportKey)); !ok {
+			continue
+		}
+		props := store.GetDummyPlayerProps(game.ID, game.SportKey, game.HomeTeam, game.AwayTeam)
+		for _, player := range props.Players {
+			for _, prop := range player.Props {
+				for _, book := range prop.Bookmakers {
+					point := book.Point
+					seen = append(seen, d.observe(game, book.Key, string(prop.Market), player.Name, "Over", &point, book.OverPrice, now))
+				}
+			}
+		}
+	}
+
+	found := d.detect(seen, now)
+	d.forget(now)
+	return found
+}
+
+// observe adds an outcome's current line and price to its series
+func (d *Detector) observe(game models.Game, book, market, player, outcome string, point *float64, price float64, now time.Time) *series {
+	key := game.ID + "|" + book + "|" + market + "|" + player + "|" + outcome
+	current := sample{price: price}
+	if point != nil {
+		current.line, current.hasLine = *point, true
+	}
+	return d.series.Observe(key, info{game: game, book: book, market: market, player: player, outcome: outcome}, current, now)
+}
+
+// move returns how far a series moved over the window: in points when its
+// line moved, otherwise in cents when its price did
+func (d *Detector) move(s *series, now time.Time) (BookMove, string) {
+	from := s.At(now.Add(-d.config.Window))
+	to := s.Latest()
+
+	if from.hasLine && to.hasLine && from.line != to.line {
+		return BookMove{Bookmaker: s.Info.book, From: from.line, To: to.line, Move: to.line - from.line}, Points
+	}
+	if from.price == 0 || to.price == 0 {
+		return BookMove{}, ""
+	}
+	return BookMove{Bookmaker: s.Info.book, From: from.price, To: to.price, Move: models.PriceCents(to.price, from.price)}, Cents
+}
+
+// detect groups the series moving past their threshold by market, side,
+// unit and direction, and returns each market's biggest group with enough
+// books, outside its cooldown
+func (d *Detector) detect(seen []*series, now time.Time) []Steam {
+	groups := make(map[string]*Steam)
+	var order []string
+	for _, s := range seen {
+		bookMove, unit := d.move(s, now)
+		if unit == "" || math.Abs(bookMove.Move) < d.threshold(unit) {
+			continue
+		}
+		direction := "+"
+		if bookMove.Move < 0 {
+			direction = "-"
+		}
+		key := s.Info.game.ID + "|" + s.Info.market + "|" + s.Info.player + "|" + s.Info.outcome + "|" + unit + "|" + direction
+		g := groups[key]
+		if g == nil {
+			g = &Steam{
+				GameID:       s.Info.game.ID,
+				Sport:        string(s.Info.game.SportKey),
+				HomeTeam:     s.Info.game.HomeTeam,
+				AwayTeam:     s.Info.game.AwayTeam,
+				CommenceTime: s.Info.game.CommenceTime,
+				Market:       s.Info.market,
+				Player:       s.Info.player,
+				Outcome:      s.Info.outcome,
+				Unit:         unit,
+				Window:       d.config.Window.Minutes(),
+				DetectedAt:   now,
+			}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.Books = append(g.Books, bookMove)
+	}
+
+	// The strongest group per market; both sides of a spread move together
+	best := make(map[string]*Steam)
+	var markets []string
+	for _, key := range order {
+		g := groups[key]
+		if len(g.Books) < d.config.MinBooks {
+			continue
+		}
+		var total float64
+		for _, b := range g.Books {
+			total += b.Move
+		}
+		g.Move = math.Round(total/float64(len(g.Books))*100) / 100
+		sort.Slice(g.Books, func(i, j int) bool {
+			return math.Abs(g.Books[i].Move) > math.Abs(g.Books[j].Move)
+		})
+
+		alertKey := g.GameID + "|" + g.Market + "|" + g.Player
+		current := best[alertKey]
+		if current == nil {
+			markets = append(markets, alertKey)
+		}
+		if current == nil || len(g.Books) > len(current.Books) ||
+			(len(g.Books) == len(current.Books) && d.strength(g) > d.strength(current)) {
+			best[alertKey] = g
+		}
+	}
+
+	var found []Steam
+	for _, alertKey := range markets {
+		if last, ok := d.lastAlert[alertKey]; ok && now.Sub(last) < d.config.Cooldown {
+			continue
+		}
+		d.lastAlert[alertKey] = now
+		found = append(found, *best[alertKey])
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if len(found[i].Books) != len(found[j].Books) {
+			return len(found[i].Books) > len(found[j].Books)
+		}
+		return d.strength(&found[i]) > d.strength(&found[j])
+	})
+	d.recent = append(d.recent, found...)
+	return found
+}
+
+// strength is how far steam moved relative to its threshold
+func (d *Detector) strength(s *Steam) float64 {
+	return math.Abs(s.Move) / d.threshold(s.Unit)
+}
+
+// threshold returns the move that counts at a bookmaker for a unit
+func (d *Detector) threshold(unit string) float64 {
+	if unit == Points {
+		return d.config.Points
+	}
+	return d.config.Cents
+}
+
+// forget drops series that haven't been seen for two windows, such as
+// started games, run-out cooldowns and steam past its retention
+func (d *Detector) forget(now time.Time) {
+	d.series.Forget(now)
+	for key, last := range d.lastAlert {
+		if now.Sub(last) >= d.config.Cooldown {
+			delete(d.lastAlert, key)
+		}
+	}
+	for key, last := range d.lastDivergence {
+		if now.Sub(last) >= d.config.Cooldown {
+			delete(d.lastDivergence, key)
+		}
+	}
+	kept := d.recent[:0]
+	for _, s := range d.recent {
+		if now.Sub(s.DetectedAt) <= recentRetention {
+			kept = append(kept, s)
+		}
+	}
+	d.recent = kept
+}
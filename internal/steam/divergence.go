@@ -0,0 +1,229 @@
+package steam
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// LaggingBook is a recreational bookmaker still dealing near the number a
+// sharp book moved off
+type LaggingBook struct {
+	Bookmaker string  `json:"bookmaker"`
+	Line      float64 `json:"line"` // its line, or price for a price move
+	Gap       float64 `json:"gap"`  // the sharp book's number minus this one, in the unit
+}
+
+// SharpDivergence is a sharp bookmaker moving a line within the window
+// while recreational books lag behind, leaving them dealing a stale number
+type SharpDivergence struct {
+	GameID       string        `json:"game_id"`
+	Sport        string        `json:"sport"`
+	HomeTeam     string        `json:"home_team"`
+	AwayTeam     string        `json:"away_team"`
+	CommenceTime time.Time     `json:"commence_time"`
+	Market       string        `json:"market"`
+	Player       string        `json:"player,omitempty"`
+	Outcome      string        `json:"outcome"`
+	Unit         string        `json:"unit"`
+	Sharp        BookMove      `json:"sharp"`   // the sharp book's move over the window
+	Lagging      []LaggingBook `json:"lagging"` // furthest behind first
+	Window       float64       `json:"window_minutes"`
+	DetectedAt   time.Time     `json:"detected_at"`
+}
+
+// Subject names the line that moved, e.g. "Boston Celtics spreads"
+func (dv SharpDivergence) Subject() string {
+	return subject(dv.Market, dv.Player, dv.Outcome)
+}
+
+// Summary describes the sharp move and the books behind it, e.g.
+// "pinnacle moved Boston Celtics spreads -3 to -4 in 15 minutes; still -3
+// at draftkings, fanduel"
+func (dv SharpDivergence) Summary() string {
+	format := func(v float64) string { return fmt.Sprintf("%g", v) }
+	if dv.Unit == Cents {
+		format = func(v float64) string { return fmt.Sprintf("%+.0f", v) }
+	}
+
+	// Books still on the same number are listed together
+	var order []string
+	byLine := make(map[string][]string)
+	for _, b := range dv.Lagging {
+		line := format(b.Line)
+		if _, ok := byLine[line]; !ok {
+			order = append(order, line)
+		}
+		byLine[line] = append(byLine[line], b.Bookmaker)
+	}
+	stale := make([]string, len(order))
+	for i, line := range order {
+		stale[i] = line + " at " + strings.Join(byLine[line], ", ")
+	}
+
+	return fmt.Sprintf("%s moved %s %s to %s in %.0f minutes; still %s",
+		dv.Sharp.Bookmaker, dv.Subject(), format(dv.Sharp.From), format(dv.Sharp.To), dv.Window, strings.Join(stale, "; "))
+}
+
+// SetDivergenceCallback sets the function called with sharp divergences
+// found in an update
+func (d *Detector) SetDivergenceCallback(fn func([]SharpDivergence)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.divergenceCallback = fn
+}
+
+// Divergences returns the sharp divergences standing now on a game that
+// hasn't started, largest first. An empty game ID returns every game.
+func (d *Detector) Divergences(gameID string) []SharpDivergence {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.divergences(gameID, d.clock.Now())
+}
+
+// newDivergences returns the divergences standing now on outcomes outside
+// their cooldown
+func (d *Detector) newDivergences() []SharpDivergence {
+	now := d.clock.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var found []SharpDivergence
+	for _, dv := range d.divergences("", now) {
+		key := dv.GameID + "|" + dv.Market + "|" + dv.Player + "|" + dv.Outcome
+		if last, ok := d.lastDivergence[key]; ok && now.Sub(last) < d.config.Cooldown {
+			continue
+		}
+		d.lastDivergence[key] = now
+		found = append(found, dv)
+	}
+	return found
+}
+
+// divergences groups the series of bookmakers with a tier by outcome and
+// returns the outcomes where a sharp book moved and recreational ones
+// haven't followed. The caller holds the lock.
+func (d *Detector) divergences(gameID string, now time.Time) []SharpDivergence {
+	groups := make(map[string][]*series)
+	var keys []string
+	for _, s := range d.series.All() {
+		if gameID != "" && s.Info.game.ID != gameID {
+			continue
+		}
+		if !s.Info.game.CommenceTime.After(now) || models.BookmakerTier(s.Info.book) == "" {
+			continue
+		}
+		key := s.Info.game.ID + "|" + s.Info.market + "|" + s.Info.player + "|" + s.Info.outcome
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], s)
+	}
+	sort.Strings(keys)
+
+	found := []SharpDivergence{}
+	for _, key := range keys {
+		if dv, ok := d.divergence(groups[key], now); ok {
+			found = append(found, dv)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return d.divergenceStrength(found[i]) > d.divergenceStrength(found[j])
+	})
+	return found
+}
+
+// divergence checks one outcome across books: the sharp book that moved
+// furthest over the window against every recreational book's current
+// number
+func (d *Detector) divergence(group []*series, now time.Time) (SharpDivergence, bool) {
+	var sharp *series
+	var sharpMove BookMove
+	var unit string
+	for _, s := range group {
+		if models.BookmakerTier(s.Info.book) != models.TierSharp {
+			continue
+		}
+		mv, u := d.move(s, now)
+		if u == "" || math.Abs(mv.Move) < d.divergenceThreshold(u) {
+			continue
+		}
+		if sharp == nil || math.Abs(mv.Move)/d.divergenceThreshold(u) > math.Abs(sharpMove.Move)/d.divergenceThreshold(unit) {
+			sharp, sharpMove, unit = s, mv, u
+		}
+	}
+	if sharp == nil {
+		return SharpDivergence{}, false
+	}
+
+	current := sharp.Latest()
+	var lagging []LaggingBook
+	for _, s := range group {
+		if models.BookmakerTier(s.Info.book) != models.TierRecreational {
+			continue
+		}
+		book := s.Latest()
+		line, gap, ok := lag(unit, current, book)
+		// Only books behind the move count, not ones past it
+		if !ok || gap == 0 || (gap > 0) != (sharpMove.Move > 0) || math.Abs(gap) < d.divergenceThreshold(unit) {
+			continue
+		}
+		lagging = append(lagging, LaggingBook{Bookmaker: s.Info.book, Line: line, Gap: gap})
+	}
+	if len(lagging) == 0 {
+		return SharpDivergence{}, false
+	}
+	sort.Slice(lagging, func(i, j int) bool {
+		return math.Abs(lagging[i].Gap) > math.Abs(lagging[j].Gap)
+	})
+
+	return SharpDivergence{
+		GameID:       sharp.Info.game.ID,
+		Sport:        string(sharp.Info.game.SportKey),
+		HomeTeam:     sharp.Info.game.HomeTeam,
+		AwayTeam:     sharp.Info.game.AwayTeam,
+		CommenceTime: sharp.Info.game.CommenceTime,
+		Market:       sharp.Info.market,
+		Player:       sharp.Info.player,
+		Outcome:      sharp.Info.outcome,
+		Unit:         unit,
+		Sharp:        sharpMove,
+		Lagging:      lagging,
+		Window:       d.config.Window.Minutes(),
+		DetectedAt:   now,
+	}, true
+}
+
+// lag measures how far a book's current number is from the sharp book's:
+// in points when the sharp line moved, otherwise in cents at the same line
+func lag(unit string, sharp, book sample) (line, gap float64, ok bool) {
+	if unit == Points {
+		if !sharp.hasLine || !book.hasLine {
+			return 0, 0, false
+		}
+		return book.line, sharp.line - book.line, true
+	}
+	if sharp.hasLine != book.hasLine || sharp.line != book.line || book.price == 0 {
+		return 0, 0, false
+	}
+	return book.price, models.PriceCents(sharp.price, book.price), true
+}
+
+// divergenceStrength is how far the furthest lagging book is behind,
+// relative to the threshold
+func (d *Detector) divergenceStrength(dv SharpDivergence) float64 {
+	return math.Abs(dv.Lagging[0].Gap) / d.divergenceThreshold(dv.Unit)
+}
+
+// divergenceThreshold returns the sharp move and lag that count for a unit
+func (d *Detector) divergenceThreshold(unit string) float64 {
+	if unit == Points {
+		return d.config.DivergencePoints
+	}
+	return d.config.DivergenceCents
+}
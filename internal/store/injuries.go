@@ -1,5 +1,7 @@
 package store
 
+import "github.com/joshuakim/linefinder/internal/models"
+
 // InjuredPlayer represents a player with an injury
 type InjuredPlayer struct {
 	Name         string  `json:"name"`
@@ -29,12 +31,30 @@ type PlayerAverages struct {
 	InjuryStatus   string             `json:"injury_status,omitempty"`
 	GamesPlayed    int                `json:"games_played"`
 	Averages       map[string]float64 `json:"averages"` // category -> average value
+
+	// Standard deviation of the games averaged, for categories where it's
+	// known (category -> stddev)
+	StdDevs map[string]float64 `json:"stddevs,omitempty"`
+
+	// Each source's value per category when averages were blended from
+	// several sources (category -> source -> value)
+	Sources map[string]map[string]float64 `json:"sources,omitempty"`
+
+	// What each projected category's average came from: a source name, or
+	// "blend" when several were combined (category -> source)
+	ProjectionSource map[string]string `json:"projection_source,omitempty"`
 }
 
-// GetDummyInjuries returns dummy injury data for a game
+// GetDummyInjuries returns dummy injury data for a game. The sport may be
+// a short or full key.
 func GetDummyInjuries(gameID, homeTeam, awayTeam, sport string) *GameInjuries {
-	if sport == "nba" {
+	switch models.Sport(sport).ShortKey() {
+	case "nba":
 		return getDummyNBAInjuries(gameID, homeTeam, awayTeam)
+	case "mlb":
+		return getDummyMLBInjuries(gameID, homeTeam, awayTeam)
+	case "nhl":
+		return getDummyNHLInjuries(gameID, homeTeam, awayTeam)
 	}
 	return getDummyNFLInjuries(gameID, homeTeam, awayTeam)
 }
@@ -81,10 +101,16 @@ func getDummyNFLInjuries(gameID, homeTeam, awayTeam string) *GameInjuries {
 	}
 }
 
-// GetDummyPlayerAverages returns dummy player averages for last 5 games
+// GetDummyPlayerAverages returns dummy player averages for last 5 games.
+// The sport may be a short or full key.
 func GetDummyPlayerAverages(sport string) []PlayerAverages {
-	if sport == "nba" {
+	switch models.Sport(sport).ShortKey() {
+	case "nba":
 		return getDummyNBAAverages()
+	case "mlb":
+		return getDummyMLBAverages()
+	case "nhl":
+		return getDummyNHLAverages()
 	}
 	return getDummyNFLAverages()
 }
@@ -94,18 +120,22 @@ func getDummyNBAAverages() []PlayerAverages {
 		{
 			Name: "Player 1", Team: "Away Team", InjuryStatus: "", GamesPlayed: 5,
 			Averages: map[string]float64{"Points": 26.4, "Rebounds": 7.2, "Assists": 8.8, "Threes Made": 2.6},
+			StdDevs:  map[string]float64{"Points": 5.1, "Rebounds": 2.3, "Assists": 2.6, "Threes Made": 1.2},
 		},
 		{
 			Name: "Player 2", Team: "Away Team", InjuryStatus: "Questionable", GamesPlayed: 5,
 			Averages: map[string]float64{"Points": 28.2, "Rebounds": 12.4, "Assists": 3.2},
+			StdDevs:  map[string]float64{"Points": 6.4, "Rebounds": 3.1, "Assists": 1.5},
 		},
 		{
 			Name: "Player 3", Team: "Home Team", InjuryStatus: "", GamesPlayed: 5,
 			Averages: map[string]float64{"Points": 29.8, "Rebounds": 8.6, "Assists": 5.4, "Threes Made": 3.8},
+			StdDevs:  map[string]float64{"Points": 4.7, "Rebounds": 2.0, "Assists": 1.9, "Threes Made": 1.5},
 		},
 		{
 			Name: "Player 4", Team: "Home Team", InjuryStatus: "Probable", GamesPlayed: 4,
 			Averages: map[string]float64{"Points": 24.5, "Rebounds": 5.8, "Assists": 3.5},
+			StdDevs:  map[string]float64{"Points": 7.2, "Rebounds": 1.8, "Assists": 1.4},
 		},
 	}
 }
@@ -0,0 +1,125 @@
+package store
+
+import "github.com/joshuakim/linefinder/internal/models"
+
+func getDummyMLBProps(gameID, homeTeam, awayTeam string) *models.GamePlayerProps {
+	// Default teams if not provided
+	if homeTeam == "" {
+		homeTeam = "Home Team"
+	}
+	if awayTeam == "" {
+		awayTeam = "Away Team"
+	}
+
+	return &models.GamePlayerProps{
+		GameID:   gameID,
+		HomeTeam: homeTeam,
+		AwayTeam: awayTeam,
+		Players: []models.PlayerWithProps{
+			{
+				Name: "Pitcher 1",
+				Team: homeTeam,
+				Props: []models.PlayerPropCategory{
+					{
+						Category: "Pitcher Strikeouts",
+						Market:   models.PitcherStrikeouts,
+						Bookmakers: []models.PropBookmaker{
+							{Key: "draftkings", Title: "DraftKings", Point: 6.5, OverPrice: -125, UnderPrice: 105},
+							{Key: "fanduel", Title: "FanDuel", Point: 6.5, OverPrice: -120, UnderPrice: 100},
+							{Key: "betmgm", Title: "BetMGM", Point: 7.5, OverPrice: 110, UnderPrice: -130},
+						},
+					},
+					{
+						Category: "Pitcher Outs",
+						Market:   models.PitcherOuts,
+						Bookmakers: []models.PropBookmaker{
+							{Key: "draftkings", Title: "DraftKings", Point: 17.5, OverPrice: -115, UnderPrice: -105},
+							{Key: "fanduel", Title: "FanDuel", Point: 17.5, OverPrice: -110, UnderPrice: -110},
+						},
+					},
+				},
+			},
+			{
+				Name: "Batter 1",
+				Team: awayTeam,
+				Props: []models.PlayerPropCategory{
+					{
+						Category: "Hits",
+						Market:   models.BatterHits,
+						Bookmakers: []models.PropBookmaker{
+							{Key: "draftkings", Title: "DraftKings", Point: 0.5, OverPrice: -220, UnderPrice: 170},
+							{Key: "fanduel", Title: "FanDuel", Point: 0.5, OverPrice: -210, UnderPrice: 165},
+							{Key: "betmgm", Title: "BetMGM", Point: 1.5, OverPrice: 150, UnderPrice: -190},
+						},
+					},
+					{
+						Category: "Total Bases",
+						Market:   models.BatterTotalBases,
+						Bookmakers: []models.PropBookmaker{
+							{Key: "draftkings", Title: "DraftKings", Point: 1.5, OverPrice: 105, UnderPrice: -135},
+							{Key: "fanduel", Title: "FanDuel", Point: 1.5, OverPrice: 100, UnderPrice: -130},
+						},
+					},
+				},
+			},
+			{
+				Name: "Batter 2",
+				Team: homeTeam,
+				Props: []models.PlayerPropCategory{
+					{
+						Category: "Home Runs",
+						Market:   models.BatterHomeRuns,
+						Bookmakers: []models.PropBookmaker{
+							{Key: "draftkings", Title: "DraftKings", Point: 0.5, OverPrice: 320, UnderPrice: -450},
+							{Key: "fanduel", Title: "FanDuel", Point: 0.5, OverPrice: 300, UnderPrice: -420},
+						},
+					},
+					{
+						Category: "RBIs",
+						Market:   models.BatterRBIs,
+						Bookmakers: []models.PropBookmaker{
+							{Key: "draftkings", Title: "DraftKings", Point: 0.5, OverPrice: 110, UnderPrice: -140},
+							{Key: "betmgm", Title: "BetMGM", Point: 0.5, OverPrice: 105, UnderPrice: -135},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getDummyMLBInjuries(gameID, homeTeam, awayTeam string) *GameInjuries {
+	return &GameInjuries{
+		GameID: gameID,
+		HomeTeam: TeamInjuries{
+			Team: homeTeam,
+			Players: []InjuredPlayer{
+				{Name: "Player A", Position: "SP", Status: "Out", BodyPart: "Elbow", Notes: "Elbow inflammation - 15-day IL"},
+			},
+		},
+		AwayTeam: TeamInjuries{
+			Team: awayTeam,
+			Players: []InjuredPlayer{
+				{Name: "Player B", Position: "SS", Status: "Questionable", BodyPart: "Hamstring", Notes: "Hamstring tightness - day-to-day"},
+				{Name: "Player C", Position: "RP", Status: "Out", BodyPart: "Shoulder", Notes: "Shoulder strain - 60-day IL"},
+			},
+		},
+	}
+}
+
+func getDummyMLBAverages() []PlayerAverages {
+	return []PlayerAverages{
+		{
+			Name: "Pitcher 1", Team: "Home Team", InjuryStatus: "", GamesPlayed: 5,
+			Averages: map[string]float64{"Pitcher Strikeouts": 8.8, "Pitcher Outs": 18.6, "Hits Allowed": 5.2},
+		},
+		{
+			Name: "Batter 1", Team: "Away Team", InjuryStatus: "", GamesPlayed: 5,
+			Averages: map[string]float64{"Hits": 1.4, "Total Bases": 2.2, "Home Runs": 0.2, "RBIs": 0.8},
+		},
+		{
+			Name: "Batter 2", Team: "Home Team", InjuryStatus: "Questionable", GamesPlayed: 4,
+			Averages: map[string]float64{"Hits": 0.8, "Total Bases": 1.6, "Home Runs": 0.4, "RBIs": 1.0},
+		},
+	}
+}
@@ -0,0 +1,116 @@
+package store
+
+import "github.com/joshuakim/linefinder/internal/models"
+
+func getDummyNHLProps(gameID, homeTeam, awayTeam string) *models.GamePlayerProps {
+	// Default teams if not provided
+	if homeTeam == "" {
+		homeTeam = "Home Team"
+	}
+	if awayTeam == "" {
+		awayTeam = "Away Team"
+	}
+
+	return &models.GamePlayerProps{
+		GameID:   gameID,
+		HomeTeam: homeTeam,
+		AwayTeam: awayTeam,
+		Players: []models.PlayerWithProps{
+			{
+				Name: "Skater 1",
+				Team: awayTeam,
+				Props: []models.PlayerPropCategory{
+					{
+						Category: "Shots on Goal",
+						Market:   models.PlayerShotsOnGoal,
+						Bookmakers: []models.PropBookmaker{
+							{Key: "draftkings", Title: "DraftKings", Point: 3.5, OverPrice: -130, UnderPrice: 110},
+							{Key: "fanduel", Title: "FanDuel", Point: 3.5, OverPrice: -125, UnderPrice: 105},
+							{Key: "betmgm", Title: "BetMGM", Point: 4.5, OverPrice: 140, UnderPrice: -170},
+						},
+					},
+					{
+						Category: "Goals",
+						Market:   models.PlayerGoals,
+						Bookmakers: []models.PropBookmaker{
+							{Key: "draftkings", Title: "DraftKings", Point: 0.5, OverPrice: 115, UnderPrice: -140},
+							{Key: "fanduel", Title: "FanDuel", Point: 0.5, OverPrice: 120, UnderPrice: -145},
+						},
+					},
+				},
+			},
+			{
+				Name: "Skater 2",
+				Team: homeTeam,
+				Props: []models.PlayerPropCategory{
+					{
+						Category: "Shots on Goal",
+						Market:   models.PlayerShotsOnGoal,
+						Bookmakers: []models.PropBookmaker{
+							{Key: "draftkings", Title: "DraftKings", Point: 2.5, OverPrice: -110, UnderPrice: -110},
+							{Key: "fanduel", Title: "FanDuel", Point: 2.5, OverPrice: -105, UnderPrice: -115},
+						},
+					},
+					{
+						Category: "Power Play Points",
+						Market:   models.PlayerPowerPlayPoints,
+						Bookmakers: []models.PropBookmaker{
+							{Key: "draftkings", Title: "DraftKings", Point: 0.5, OverPrice: 160, UnderPrice: -200},
+							{Key: "betmgm", Title: "BetMGM", Point: 0.5, OverPrice: 150, UnderPrice: -190},
+						},
+					},
+				},
+			},
+			{
+				Name: "Goalie 1",
+				Team: homeTeam,
+				Props: []models.PlayerPropCategory{
+					{
+						Category: "Saves",
+						Market:   models.PlayerTotalSaves,
+						Bookmakers: []models.PropBookmaker{
+							{Key: "draftkings", Title: "DraftKings", Point: 26.5, OverPrice: -115, UnderPrice: -105},
+							{Key: "fanduel", Title: "FanDuel", Point: 26.5, OverPrice: -110, UnderPrice: -110},
+							{Key: "betmgm", Title: "BetMGM", Point: 27.5, OverPrice: 100, UnderPrice: -120},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getDummyNHLInjuries(gameID, homeTeam, awayTeam string) *GameInjuries {
+	return &GameInjuries{
+		GameID: gameID,
+		HomeTeam: TeamInjuries{
+			Team: homeTeam,
+			Players: []InjuredPlayer{
+				{Name: "Player A", Position: "D", Status: "Out", BodyPart: "Upper Body", Notes: "Upper-body injury - week-to-week"},
+			},
+		},
+		AwayTeam: TeamInjuries{
+			Team: awayTeam,
+			Players: []InjuredPlayer{
+				{Name: "Player B", Position: "C", Status: "Questionable", BodyPart: "Lower Body", Notes: "Lower-body injury - game-time decision"},
+			},
+		},
+	}
+}
+
+func getDummyNHLAverages() []PlayerAverages {
+	return []PlayerAverages{
+		{
+			Name: "Skater 1", Team: "Away Team", InjuryStatus: "", GamesPlayed: 5,
+			Averages: map[string]float64{"Shots on Goal": 4.8, "Goals": 0.6, "Power Play Points": 0.4},
+		},
+		{
+			Name: "Skater 2", Team: "Home Team", InjuryStatus: "", GamesPlayed: 5,
+			Averages: map[string]float64{"Shots on Goal": 2.2, "Goals": 0.2, "Power Play Points": 0.6},
+		},
+		{
+			Name: "Goalie 1", Team: "Home Team", InjuryStatus: "Probable", GamesPlayed: 4,
+			Averages: map[string]float64{"Saves": 29.5},
+		},
+	}
+}
@@ -4,8 +4,13 @@ import "github.com/joshuakim/linefinder/internal/models"
 
 // GetDummyPlayerProps returns dummy player props data for a given game ID and sport
 func GetDummyPlayerProps(gameID string, sport models.Sport, homeTeam, awayTeam string) *models.GamePlayerProps {
-	if sport == models.SportNBA {
+	switch sport {
+	case models.SportNBA:
 		return getDummyNBAProps(gameID, homeTeam, awayTeam)
+	case models.SportMLB:
+		return getDummyMLBProps(gameID, homeTeam, awayTeam)
+	case models.SportNHL:
+		return getDummyNHLProps(gameID, homeTeam, awayTeam)
 	}
 	return getDummyNFLProps(gameID, homeTeam, awayTeam)
 }
@@ -7,40 +7,191 @@ import (
 	"github.com/joshuakim/linefinder/internal/models"
 )
 
+// finishedRetention is how long final games are kept out of the store, by
+// which time feeds have stopped listing them
+const finishedRetention = 72 * time.Hour
+
 // Store holds games data in memory
 type Store struct {
 	mu          sync.RWMutex
 	games       map[string]models.Game // keyed by game ID
+	finished    map[string]time.Time   // final games removed, and when
+	pinned      map[string]bool        // games kept once they're final
+	archived    map[string]models.Game // final games kept because they're pinned
 	lastUpdated time.Time
+	changed     map[models.Sport]time.Time // when a sport's games last changed
+	watchers    watchers
 }
 
 // New creates a new in-memory store
 func New() *Store {
 	return &Store{
-		games: make(map[string]models.Game),
+		games:    make(map[string]models.Game),
+		finished: make(map[string]time.Time),
+		pinned:   make(map[string]bool),
+		archived: make(map[string]models.Game),
+		changed:  make(map[models.Sport]time.Time),
+	}
+}
+
+// UpdateGames replaces all games for a given sport and notifies watchers.
+// Games keep the status they were given by SetStatuses, and games already
+// final aren't stored again. It returns the games it stored.
+func (s *Store) UpdateGames(games []models.Game) []models.Game {
+	s.mu.Lock()
+	now := time.Now()
+	bySport := make(map[models.Sport]*Update)
+	var order []models.Sport
+	stored := make([]models.Game, 0, len(games))
+	for _, game := range games {
+		if _, ok := s.finished[game.ID]; ok {
+			continue
+		}
+		current, found := s.games[game.ID]
+		if game.Status == "" {
+			game.Status = models.GameScheduled
+			if found {
+				game.Status = current.Status
+			}
+		}
+
+		u := bySport[game.SportKey]
+		if u == nil {
+			u = &Update{Sport: game.SportKey, UpdatedAt: now}
+			bySport[game.SportKey] = u
+			order = append(order, game.SportKey)
+		}
+		if gameChanged(current, found, game) {
+			u.Changed = true
+		}
+		u.Games = append(u.Games, game)
+		s.games[game.ID] = game
+		stored = append(stored, game)
 	}
+	for sport, u := range bySport {
+		if u.Changed {
+			s.changed[sport] = now
+		}
+	}
+	s.lastUpdated = now
+	s.mu.Unlock()
+
+	updates := make([]Update, 0, len(order))
+	for _, sport := range order {
+		updates = append(updates, *bySport[sport])
+	}
+	s.publish(updates)
+	return stored
 }
 
-// UpdateGames replaces all games for a given sport
-func (s *Store) UpdateGames(games []models.Game) {
+// SetStatuses moves games to new lifecycle states, by game ID. Final games
+// are removed and kept from being stored again, though pinned ones can
+// still be looked up with GetGame. Watchers get the games that changed,
+// including final ones; the sports they're in are returned.
+func (s *Store) SetStatuses(statuses map[string]models.GameStatus) []models.Sport {
+	s.mu.Lock()
+	now := time.Now()
+	for id, at := range s.finished {
+		if now.Sub(at) > finishedRetention {
+			delete(s.finished, id)
+		}
+	}
+
+	bySport := make(map[models.Sport]*Update)
+	var order []models.Sport
+	for id, status := range statuses {
+		game, ok := s.games[id]
+		if !ok || game.Status == status {
+			continue
+		}
+		game.Status = status
+		if status == models.GameFinal {
+			delete(s.games, id)
+			s.finished[id] = now
+			if s.pinned[id] {
+				s.archived[id] = game
+			}
+		} else {
+			s.games[id] = game
+		}
+
+		u := bySport[game.SportKey]
+		if u == nil {
+			u = &Update{Sport: game.SportKey, Changed: true, UpdatedAt: now}
+			bySport[game.SportKey] = u
+			order = append(order, game.SportKey)
+		}
+		u.Games = append(u.Games, game)
+		s.changed[game.SportKey] = now
+	}
+	s.mu.Unlock()
+
+	updates := make([]Update, 0, len(order))
+	for _, sport := range order {
+		updates = append(updates, *bySport[sport])
+	}
+	s.publish(updates)
+	return order
+}
+
+// Mirror replaces a sport's games with those another instance stored and
+// broadcast. Watchers aren't told: the instance that fetched the games has
+// already scanned and saved them.
+func (s *Store) Mirror(sport models.Sport, games []models.Game) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	now := time.Now()
+	listed := make(map[string]bool, len(games))
+	changed := false
 	for _, game := range games {
+		listed[game.ID] = true
+		current, found := s.games[game.ID]
+		if gameChanged(current, found, game) {
+			changed = true
+		}
 		s.games[game.ID] = game
 	}
-	s.lastUpdated = time.Now()
+	for id, game := range s.games {
+		if game.SportKey == sport && !listed[id] {
+			delete(s.games, id)
+			changed = true
+		}
+	}
+	if changed {
+		s.changed[sport] = now
+	}
+	s.lastUpdated = now
 }
 
-// GetGame returns a single game by ID
+// GetGame returns a single game by ID, including pinned games that are
+// final
 func (s *Store) GetGame(id string) (models.Game, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	game, ok := s.games[id]
+	if !ok {
+		game, ok = s.archived[id]
+	}
 	return game, ok
 }
 
+// Pin keeps a game available from GetGame once it's final
+func (s *Store) Pin(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pinned[id] = true
+}
+
+// Unpin lets a game go once it's final, dropping it if it already is
+func (s *Store) Unpin(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pinned, id)
+	delete(s.archived, id)
+}
+
 // GetGamesBySport returns all games for a specific sport
 func (s *Store) GetGamesBySport(sport models.Sport) []models.Game {
 	s.mu.RLock()
@@ -74,9 +225,21 @@ func (s *Store) LastUpdated() time.Time {
 	return s.lastUpdated
 }
 
+// LastChanged returns when a sport's games last changed, as opposed to
+// being refreshed with the same data. It's zero when none have been stored.
+func (s *Store) LastChanged(sport models.Sport) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.changed[sport]
+}
+
 // Clear removes all games from the store
 func (s *Store) Clear() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.games = make(map[string]models.Game)
+	now := time.Now()
+	for sport := range s.changed {
+		s.changed[sport] = now
+	}
 }
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// watchBuffer is how many updates a subscriber can fall behind before the
+// oldest pending update is dropped
+const watchBuffer = 8
+
+// Update is a batch of games for one sport written to the store
+type Update struct {
+	Sport     models.Sport  `json:"sport"`
+	Games     []models.Game `json:"games"`
+	Changed   bool          `json:"changed"` // any game is new or differs from the stored copy
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// watcher is a single subscription
+type watcher struct {
+	sport models.Sport
+	ch    chan Update
+}
+
+// watchers holds the store's subscriptions
+type watchers struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]*watcher
+}
+
+// Watch subscribes to updates for a sport, or every sport when sport is
+// empty. Updates are delivered without blocking writers: a subscriber that
+// falls behind loses its oldest pending updates. The returned function
+// unsubscribes and closes the channel.
+func (s *Store) Watch(sport models.Sport) (<-chan Update, func()) {
+	s.watchers.mu.Lock()
+	defer s.watchers.mu.Unlock()
+
+	if s.watchers.subs == nil {
+		s.watchers.subs = make(map[int]*watcher)
+	}
+	id := s.watchers.nextID
+	s.watchers.nextID++
+	w := &watcher{sport: sport, ch: make(chan Update, watchBuffer)}
+	s.watchers.subs[id] = w
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			s.watchers.mu.Lock()
+			defer s.watchers.mu.Unlock()
+			delete(s.watchers.subs, id)
+			close(w.ch)
+		})
+	}
+	return w.ch, cancel
+}
+
+// publish sends updates to matching subscribers
+func (s *Store) publish(updates []Update) {
+	s.watchers.mu.Lock()
+	defer s.watchers.mu.Unlock()
+
+	for _, u := range updates {
+		for _, w := range s.watchers.subs {
+			if w.sport != "" && w.sport != u.Sport {
+				continue
+			}
+			select {
+			case w.ch <- u:
+			default:
+				// Full: drop the oldest update to make room for the newest
+				select {
+				case <-w.ch:
+				default:
+				}
+				select {
+				case w.ch <- u:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// gameChanged reports whether an incoming game differs from the stored copy
+func gameChanged(stored models.Game, found bool, incoming models.Game) bool {
+	return !found || !reflect.DeepEqual(stored, incoming)
+}
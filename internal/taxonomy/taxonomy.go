@@ -0,0 +1,170 @@
+package taxonomy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// Canonical prop category names. Props, averages, thresholds and alerts
+// should all refer to categories by these names.
+const (
+	// NBA
+	Points    = "Points"
+	Rebounds  = "Rebounds"
+	Assists   = "Assists"
+	Threes    = "Threes Made"
+	Steals    = "Steals"
+	Blocks    = "Blocks"
+	Turnovers = "Turnovers"
+	PRA       = "Pts+Reb+Ast"
+	PR        = "Pts+Reb"
+	PA        = "Pts+Ast"
+	RA        = "Reb+Ast"
+
+	// NFL
+	PassingYards   = "Passing Yards"
+	PassingTDs     = "Passing TDs"
+	PassAttempts   = "Pass Attempts"
+	Completions    = "Completions"
+	RushYards      = "Rush Yards"
+	RushAttempts   = "Rush Attempts"
+	Receptions     = "Receptions"
+	ReceivingYards = "Receiving Yards"
+
+	// MLB
+	Hits               = "Hits"
+	TotalBases         = "Total Bases"
+	HomeRuns           = "Home Runs"
+	RBIs               = "RBIs"
+	RunsScored         = "Runs Scored"
+	PitcherStrikeouts  = "Pitcher Strikeouts"
+	PitcherOuts        = "Pitcher Outs"
+	PitcherHitsAllowed = "Hits Allowed"
+
+	// NHL
+	Goals           = "Goals"
+	ShotsOnGoal     = "Shots on Goal"
+	Saves           = "Saves"
+	PowerPlayPoints = "Power Play Points"
+	BlockedShots    = "Blocked Shots"
+)
+
+// Category describes a canonical prop category
+type Category struct {
+	Name    string                  `json:"name"`
+	Sport   models.Sport            `json:"sport"`
+	Market  models.PlayerPropMarket `json:"market,omitempty"`
+	Aliases []string                `json:"aliases,omitempty"`
+}
+
+// categories is the full taxonomy
+var categories = []Category{
+	{Name: Points, Sport: models.SportNBA, Market: models.PlayerPoints, Aliases: []string{"Pts"}},
+	{Name: Rebounds, Sport: models.SportNBA, Market: models.PlayerRebounds, Aliases: []string{"Reb", "Rebs"}},
+	{Name: Assists, Sport: models.SportNBA, Market: models.PlayerAssists, Aliases: []string{"Ast", "Asts"}},
+	{Name: Threes, Sport: models.SportNBA, Market: models.PlayerThrees, Aliases: []string{"Threes", "3PM", "3-Pointers Made"}},
+	{Name: Steals, Sport: models.SportNBA, Aliases: []string{"Stl"}},
+	{Name: Blocks, Sport: models.SportNBA, Aliases: []string{"Blk"}},
+	{Name: Turnovers, Sport: models.SportNBA, Aliases: []string{"TO"}},
+	{Name: PRA, Sport: models.SportNBA, Market: models.PlayerPRA, Aliases: []string{"PRA", "Points+Rebounds+Assists"}},
+	{Name: PR, Sport: models.SportNBA, Market: models.PlayerPointsRebounds, Aliases: []string{"Points+Rebounds"}},
+	{Name: PA, Sport: models.SportNBA, Market: models.PlayerPointsAssists, Aliases: []string{"Points+Assists"}},
+	{Name: RA, Sport: models.SportNBA, Market: models.PlayerReboundsAssists, Aliases: []string{"Rebounds+Assists"}},
+
+	{Name: PassingYards, Sport: models.SportNFL, Market: models.PlayerPassYards, Aliases: []string{"Pass Yards"}},
+	{Name: PassingTDs, Sport: models.SportNFL, Market: models.PlayerPassTDs, Aliases: []string{"Pass TDs", "Passing Touchdowns"}},
+	{Name: PassAttempts, Sport: models.SportNFL, Market: models.PlayerPassAttempts, Aliases: []string{"Passing Attempts"}},
+	{Name: Completions, Sport: models.SportNFL, Market: models.PlayerPassCompletions, Aliases: []string{"Pass Completions", "Passing Completions"}},
+	{Name: RushYards, Sport: models.SportNFL, Market: models.PlayerRushYards, Aliases: []string{"Rushing Yards"}},
+	{Name: RushAttempts, Sport: models.SportNFL, Market: models.PlayerRushAttempts, Aliases: []string{"Rushing Attempts"}},
+	{Name: Receptions, Sport: models.SportNFL, Market: models.PlayerReceptions, Aliases: []string{"Catches"}},
+	{Name: ReceivingYards, Sport: models.SportNFL, Market: models.PlayerReceivingYards, Aliases: []string{"Reception Yards", "Rec Yards"}},
+
+	{Name: Hits, Sport: models.SportMLB, Market: models.BatterHits},
+	{Name: TotalBases, Sport: models.SportMLB, Market: models.BatterTotalBases, Aliases: []string{"TB"}},
+	{Name: HomeRuns, Sport: models.SportMLB, Market: models.BatterHomeRuns, Aliases: []string{"HR", "Homers"}},
+	{Name: RBIs, Sport: models.SportMLB, Market: models.BatterRBIs, Aliases: []string{"RBI", "Runs Batted In"}},
+	{Name: RunsScored, Sport: models.SportMLB, Market: models.BatterRunsScored, Aliases: []string{"Runs"}},
+	{Name: PitcherStrikeouts, Sport: models.SportMLB, Market: models.PitcherStrikeouts, Aliases: []string{"Strikeouts", "Ks"}},
+	{Name: PitcherOuts, Sport: models.SportMLB, Market: models.PitcherOuts, Aliases: []string{"Outs Recorded"}},
+	{Name: PitcherHitsAllowed, Sport: models.SportMLB, Market: models.PitcherHitsAllowed},
+
+	{Name: Goals, Sport: models.SportNHL, Market: models.PlayerGoals},
+	{Name: ShotsOnGoal, Sport: models.SportNHL, Market: models.PlayerShotsOnGoal, Aliases: []string{"SOG", "Shots"}},
+	{Name: Saves, Sport: models.SportNHL, Market: models.PlayerTotalSaves, Aliases: []string{"Total Saves"}},
+	{Name: PowerPlayPoints, Sport: models.SportNHL, Market: models.PlayerPowerPlayPoints, Aliases: []string{"PPP"}},
+	{Name: BlockedShots, Sport: models.SportNHL, Market: models.PlayerBlockedShots},
+}
+
+// index maps lowercased names, aliases and market keys to categories
+var index = buildIndex()
+
+func buildIndex() map[string]Category {
+	idx := make(map[string]Category)
+	for _, c := range categories {
+		idx[strings.ToLower(c.Name)] = c
+		for _, alias := range c.Aliases {
+			idx[strings.ToLower(alias)] = c
+		}
+		if c.Market != "" {
+			idx[string(c.Market)] = c
+		}
+	}
+	return idx
+}
+
+// Lookup resolves a category name, alias, or market key to its category
+func Lookup(name string) (Category, bool) {
+	c, ok := index[strings.ToLower(strings.TrimSpace(name))]
+	return c, ok
+}
+
+// Canonical returns the canonical name for a category name, alias, or market key
+func Canonical(name string) (string, bool) {
+	c, ok := Lookup(name)
+	if !ok {
+		return "", false
+	}
+	return c.Name, true
+}
+
+// Normalize returns the canonical name when the category is mapped,
+// otherwise the input unchanged
+func Normalize(name string) string {
+	if canonical, ok := Canonical(name); ok {
+		return canonical
+	}
+	return name
+}
+
+// ForProp resolves a prop's category, falling back to its market key
+func ForProp(category string, market models.PlayerPropMarket) (string, bool) {
+	if canonical, ok := Canonical(category); ok {
+		return canonical, true
+	}
+	if market != "" {
+		return Canonical(string(market))
+	}
+	return "", false
+}
+
+// Validate returns an error if the category isn't in the taxonomy
+func Validate(name string) error {
+	if _, ok := Lookup(name); !ok {
+		return fmt.Errorf("unmapped prop category %q", name)
+	}
+	return nil
+}
+
+// All returns every category, optionally filtered by sport
+func All(sport models.Sport) []Category {
+	var result []Category
+	for _, c := range categories {
+		if sport == "" || c.Sport == sport {
+			result = append(result, c)
+		}
+	}
+	return result
+}
@@ -0,0 +1,277 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/database"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/service"
+)
+
+const (
+	// pollTimeout is how long each long poll waits for an update
+	pollTimeout = 30 * time.Second
+
+	// retryDelay is the wait after a failed poll
+	retryDelay = 5 * time.Second
+
+	// maxListed is the most alerts or games a reply lists
+	maxListed = 10
+)
+
+// command is one bot command
+type command struct {
+	name        string
+	usage       string // arguments, e.g. "<sport>"
+	description string
+	run         func(args string) string
+}
+
+// Bot answers commands sent from the configured chat, using the odds
+// service for lines and the database for alerts and mutes. Messages from
+// any other chat are ignored.
+type Bot struct {
+	client      *Client
+	chatID      string
+	oddsService *service.OddsService
+	db          *database.DB
+	clock       clock.Clock
+
+	commands []command
+}
+
+// NewBot creates a bot answering commands in a chat
+func NewBot(client *Client, chatID string, oddsService *service.OddsService, db *database.DB) *Bot {
+	b := &Bot{
+		client:      client,
+		chatID:      chatID,
+		oddsService: oddsService,
+		db:          db,
+		clock:       clock.Real{},
+	}
+	b.commands = []command{
+		{name: "/alerts", description: "open value alerts", run: b.alerts},
+		{name: "/odds", usage: "<sport>", description: "best lines for upcoming games, e.g. /odds nba", run: b.odds},
+		{name: "/mute", usage: "<player>", description: "stop alerts for a player, e.g. /mute LeBron; alone, lists muted players", run: b.mute},
+		{name: "/unmute", usage: "<player>", description: "start a muted player's alerts again", run: b.unmute},
+		{name: "/help", description: "this list", run: b.help},
+	}
+	return b
+}
+
+// SetClock sets the clock used for upcoming games
+func (b *Bot) SetClock(c clock.Clock) {
+	b.clock = c
+}
+
+// Start answers commands until the context is cancelled
+func (b *Bot) Start(ctx context.Context) {
+	log.Printf("Telegram bot starting (chat: %s)", b.chatID)
+
+	var offset int64
+	for {
+		updates, err := b.client.GetUpdates(ctx, offset, pollTimeout)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("Telegram: failed to get updates: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil || strconv.FormatInt(u.Message.Chat.ID, 10) != b.chatID {
+				continue
+			}
+			reply := b.Handle(u.Message.Text)
+			if reply == "" {
+				continue
+			}
+			if err := b.client.SendMessage(ctx, b.chatID, reply); err != nil {
+				log.Printf("Telegram: failed to reply: %v", err)
+			}
+		}
+	}
+}
+
+// Handle routes a message to its command and returns the reply. Messages
+// that aren't commands get none.
+func (b *Bot) Handle(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return ""
+	}
+	name, args, _ := strings.Cut(text, " ")
+	// In groups commands can be addressed to a bot, e.g. /odds@LineFinderBot
+	name, _, _ = strings.Cut(strings.ToLower(name), "@")
+	if name == "/start" {
+		name = "/help"
+	}
+
+	for _, c := range b.commands {
+		if c.name == name {
+			return c.run(strings.TrimSpace(args))
+		}
+	}
+	return "Unknown command " + html.EscapeString(name) + ". Send /help for the list."
+}
+
+// help lists the commands
+func (b *Bot) help(string) string {
+	lines := []string{"<b>LineFinder commands</b>"}
+	for _, c := range b.commands {
+		usage := c.name
+		if c.usage != "" {
+			usage += " " + c.usage
+		}
+		lines = append(lines, html.EscapeString(usage)+": "+c.description)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// alerts lists the newest alerts whose edge still stands
+func (b *Bot) alerts(string) string {
+	live, err := b.db.GetLiveAlerts()
+	if err != nil {
+		log.Printf("Telegram: failed to get live alerts: %v", err)
+		return "Couldn't load alerts, try again shortly."
+	}
+	if len(live) == 0 {
+		return "No open alerts."
+	}
+
+	prefs, err := b.db.GetPreferences()
+	if err != nil {
+		prefs = &database.Preferences{}
+	}
+	lines := []string{fmt.Sprintf("<b>%d open alerts</b>", len(live))}
+	// Newest first
+	for i := len(live) - 1; i >= 0 && len(live)-i <= maxListed; i-- {
+		a := live[i]
+		line := fmt.Sprintf("<b>%s</b> %s %s %g (avg %g, %+.1f) · %s",
+			html.EscapeString(a.PlayerName), html.EscapeString(a.PropCategory), strings.ToUpper(a.Direction),
+			a.LineValue, a.AverageValue, a.Difference, a.Confidence)
+		if prefs.PlayerMuted(a.PlayerName) {
+			line += " · muted"
+		}
+		lines = append(lines, line)
+	}
+	if len(live) > maxListed {
+		lines = append(lines, fmt.Sprintf("%d more in the app", len(live)-maxListed))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// odds lists the best lines for a sport's next games
+func (b *Bot) odds(args string) string {
+	if args == "" {
+		return "Which sport? Use " + html.EscapeString(models.SportChoices()) + ", e.g. /odds nba"
+	}
+	sport, ok := models.ParseSport(args)
+	if !ok {
+		return "Unknown sport " + html.EscapeString(args) + ". Use " + html.EscapeString(models.SportChoices()) + "."
+	}
+
+	now := b.clock.Now()
+	var games []models.Game
+	for _, game := range b.oddsService.GetGamesBySport(sport) {
+		if game.CommenceTime.After(now) {
+			games = append(games, game)
+		}
+	}
+	if len(games) == 0 {
+		return "No upcoming " + sport.ShortKey() + " games."
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].CommenceTime.Before(games[j].CommenceTime) })
+
+	loc := time.Local
+	if prefs, err := b.db.GetPreferences(); err == nil {
+		loc = prefs.Location()
+	}
+
+	var blocks []string
+	for i, game := range games {
+		if i == maxListed {
+			blocks = append(blocks, fmt.Sprintf("%d more games in the app", len(games)-maxListed))
+			break
+		}
+		blocks = append(blocks, formatGameLines(b.oddsService.CompareOdds(game), loc))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// formatGameLines lists a game's best moneyline, spread and total
+func formatGameLines(c models.OddsComparison, loc *time.Location) string {
+	away, home := html.EscapeString(c.AwayTeam), html.EscapeString(c.HomeTeam)
+	lines := []string{fmt.Sprintf("<b>%s @ %s</b> %s", away, home, c.CommenceTime.In(loc).Format("Mon 3:04 PM"))}
+	if ml := c.Moneyline; ml != nil {
+		lines = append(lines, fmt.Sprintf("ML: %s %+.0f (%s), %s %+.0f (%s)",
+			away, ml.BestAway.Price, ml.BestAway.Bookmaker, home, ml.BestHome.Price, ml.BestHome.Bookmaker))
+	}
+	if sp := c.Spread; sp != nil {
+		lines = append(lines, fmt.Sprintf("Spread: %s %+g %+.0f (%s), %s %+g %+.0f (%s)",
+			away, sp.BestAway.Point, sp.BestAway.Price, sp.BestAway.Bookmaker,
+			home, sp.BestHome.Point, sp.BestHome.Price, sp.BestHome.Bookmaker))
+	}
+	if t := c.Total; t != nil {
+		lines = append(lines, fmt.Sprintf("Total: O %g %+.0f (%s), U %g %+.0f (%s)",
+			t.BestOver.Point, t.BestOver.Price, t.BestOver.Bookmaker,
+			t.BestUnder.Point, t.BestUnder.Price, t.BestUnder.Bookmaker))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mute mutes a player's alerts, or lists the muted players
+func (b *Bot) mute(args string) string {
+	if args == "" {
+		prefs, err := b.db.GetPreferences()
+		if err != nil {
+			return "Couldn't load muted players, try again shortly."
+		}
+		if len(prefs.MutedPlayers) == 0 {
+			return "No players are muted. Mute one with /mute &lt;player&gt;."
+		}
+		return "Muted: " + html.EscapeString(strings.Join(prefs.MutedPlayers, ", "))
+	}
+	if strings.Contains(args, ",") {
+		return "Mute one player at a time."
+	}
+
+	muted, err := b.db.MutePlayer(args)
+	if err != nil {
+		log.Printf("Telegram: failed to mute %s: %v", args, err)
+		return "Couldn't mute " + html.EscapeString(args) + ", try again shortly."
+	}
+	return fmt.Sprintf("Muted alerts for players matching %s. Muted: %s",
+		html.EscapeString(args), html.EscapeString(strings.Join(muted, ", ")))
+}
+
+// unmute starts a muted player's alerts again
+func (b *Bot) unmute(args string) string {
+	if args == "" {
+		return "Who? e.g. /unmute LeBron"
+	}
+	removed, err := b.db.UnmutePlayer(args)
+	if err != nil {
+		log.Printf("Telegram: failed to unmute %s: %v", args, err)
+		return "Couldn't unmute " + html.EscapeString(args) + ", try again shortly."
+	}
+	if !removed {
+		return html.EscapeString(args) + " isn't muted. Send /mute to list muted players."
+	}
+	return "Unmuted " + html.EscapeString(args) + "."
+}
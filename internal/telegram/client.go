@@ -0,0 +1,142 @@
+// Package telegram talks to the Telegram Bot API: sending alert messages
+// to the configured chat and answering the commands sent from it.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// apiBase is the Telegram Bot API. The bot token is part of every path.
+const apiBase = "https://api.telegram.org"
+
+// MaxMessageLength is the longest message text Telegram accepts
+const MaxMessageLength = 4096
+
+// requestTimeout bounds requests other than long polls
+const requestTimeout = 10 * time.Second
+
+// Update is an incoming update. Only messages are handled.
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message,omitempty"`
+}
+
+// Message is a message sent to the bot
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text"`
+}
+
+// Chat is the chat a message was sent in
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+// APIError is a request Telegram refused
+type APIError struct {
+	StatusCode  int
+	Description string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram returned %d: %s", e.StatusCode, e.Description)
+}
+
+// Permanent reports whether retrying won't help: client errors other than
+// rate limiting, such as a bad token or a chat the bot isn't in
+func (e *APIError) Permanent() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500 && e.StatusCode != http.StatusTooManyRequests
+}
+
+// Client calls the Bot API with a bot token
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a Bot API client for a bot token
+func NewClient(token string) *Client {
+	return &Client{
+		token:      token,
+		baseURL:    apiBase,
+		httpClient: &http.Client{},
+	}
+}
+
+// SendMessage sends HTML-formatted text to a chat, by numeric ID or
+// @channel name
+func (c *Client) SendMessage(ctx context.Context, chatID, text string) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	return c.call(ctx, "sendMessage", map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}, nil)
+}
+
+// GetUpdates long-polls for updates from offset on, waiting up to timeout
+// for one to arrive
+func (c *Client) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout+requestTimeout)
+	defer cancel()
+	var updates []Update
+	err := c.call(ctx, "getUpdates", map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// GetMe checks the token by fetching the bot's own user
+func (c *Client) GetMe(ctx context.Context) error {
+	return c.call(ctx, "getMe", nil, nil)
+}
+
+// call posts a method's parameters and decodes its result
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/bot%s/%s", c.baseURL, c.token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err := json.Unmarshal(data, &envelope); err != nil || !envelope.OK {
+		description := envelope.Description
+		if description == "" {
+			description = resp.Status
+		}
+		return &APIError{StatusCode: resp.StatusCode, Description: description}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(envelope.Result, result)
+}
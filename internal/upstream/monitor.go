@@ -0,0 +1,148 @@
+package upstream
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/metrics"
+	"github.com/joshuakim/linefinder/internal/redact"
+)
+
+// Config holds upstream check configuration
+type Config struct {
+	// Interval is the time between checks
+	Interval time.Duration
+
+	// SlowThreshold marks a successful check as degraded when exceeded
+	SlowThreshold time.Duration
+
+	// DownAfter is how many consecutive failures mark a dependency down;
+	// fewer failures mark it degraded
+	DownAfter int
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Interval:      5 * time.Minute,
+		SlowThreshold: 3 * time.Second,
+		DownAfter:     3,
+	}
+}
+
+// CheckFunc probes a dependency, returning an error when it's unavailable
+type CheckFunc func() error
+
+// Monitor periodically checks upstream APIs, independently of polling,
+// and records their status in metrics
+type Monitor struct {
+	config  Config
+	metrics *metrics.Metrics
+	clock   clock.Clock
+
+	mu     sync.Mutex
+	checks map[string]CheckFunc
+}
+
+// NewMonitor creates a new upstream monitor
+func NewMonitor(config Config, m *metrics.Metrics) *Monitor {
+	return &Monitor{
+		config:  config,
+		metrics: m,
+		clock:   clock.Real{},
+		checks:  make(map[string]CheckFunc),
+	}
+}
+
+// SetClock sets the clock used for check timestamps and intervals
+func (mon *Monitor) SetClock(c clock.Clock) {
+	mon.clock = c
+}
+
+// Register adds a dependency check
+func (mon *Monitor) Register(name string, check CheckFunc) {
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+	mon.checks[name] = check
+}
+
+// Start runs checks immediately and then on every interval
+func (mon *Monitor) Start(ctx context.Context) {
+	if mon.config.Interval <= 0 {
+		mon.config.Interval = DefaultConfig().Interval
+	}
+
+	log.Printf("Upstream monitor starting (interval: %v)", mon.config.Interval)
+	mon.CheckAll()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-mon.clock.After(mon.config.Interval):
+			mon.CheckAll()
+		}
+	}
+}
+
+// CheckAll runs every registered check concurrently and records the results
+func (mon *Monitor) CheckAll() {
+	mon.mu.Lock()
+	checks := make(map[string]CheckFunc, len(mon.checks))
+	for name, check := range mon.checks {
+		checks[name] = check
+	}
+	mon.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+			mon.runCheck(name, check)
+		}(name, check)
+	}
+	wg.Wait()
+}
+
+// runCheck probes one dependency and records its status
+func (mon *Monitor) runCheck(name string, check CheckFunc) {
+	previous, _ := mon.metrics.GetDependency(name)
+
+	start := time.Now()
+	err := check()
+	latency := time.Since(start)
+
+	status := metrics.DependencyStatus{
+		Name:      name,
+		Status:    metrics.DependencyOK,
+		LastCheck: mon.clock.Now(),
+		LatencyMs: latency.Milliseconds(),
+		LastOK:    previous.LastOK,
+	}
+
+	if err != nil {
+		// Served by the public health endpoint, and transport errors quote
+		// URLs with their keys
+		status.LastError = redact.Error(err)
+		status.ConsecutiveFailures = previous.ConsecutiveFailures + 1
+		status.Status = metrics.DependencyDegraded
+		if status.ConsecutiveFailures >= mon.config.DownAfter {
+			status.Status = metrics.DependencyDown
+		}
+	} else {
+		status.LastOK = status.LastCheck
+		if latency > mon.config.SlowThreshold {
+			status.Status = metrics.DependencyDegraded
+		}
+	}
+
+	if status.Status != previous.Status && previous.Status != "" {
+		log.Printf("Upstream: %s is now %s (was %s)", name, status.Status, previous.Status)
+	}
+
+	mon.metrics.RecordDependencyCheck(status)
+}
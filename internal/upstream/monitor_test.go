@@ -0,0 +1,71 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/metrics"
+)
+
+// TestMonitorStart runs checks on the fake clock, which fires each interval
+// right away, until the dependency has failed twice and recovered
+func TestMonitorStart(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	fake := clock.NewFake(start)
+	m := metrics.New()
+	mon := NewMonitor(Config{Interval: time.Minute, SlowThreshold: time.Hour, DownAfter: 2}, m)
+	mon.SetClock(fake)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	var seen []string
+	mon.Register("database_replica", func() error {
+		calls++
+		if calls > 1 {
+			previous, _ := m.GetDependency("database_replica")
+			seen = append(seen, previous.Status)
+		}
+		if calls == 4 {
+			cancel()
+		}
+		if calls <= 2 {
+			return errors.New("dial tcp: connection refused")
+		}
+		return nil
+	})
+	mon.Start(ctx)
+
+	if want := []string{metrics.DependencyDegraded, metrics.DependencyDown, metrics.DependencyOK}; !reflect.DeepEqual(seen[:3], want) {
+		t.Errorf("statuses %v, want %v", seen[:3], want)
+	}
+	status, _ := m.GetDependency("database_replica")
+	if status.Status != metrics.DependencyOK || status.ConsecutiveFailures != 0 {
+		t.Errorf("after recovering: %+v", status)
+	}
+	// Each interval passed on the injected clock
+	if want := start.Add(time.Duration(calls-1) * time.Minute); !status.LastCheck.Equal(want) || !status.LastOK.Equal(want) {
+		t.Errorf("last check %v, last ok %v, want %v", status.LastCheck, status.LastOK, want)
+	}
+}
+
+func TestRunCheckRedactsErrors(t *testing.T) {
+	m := metrics.New()
+	mon := NewMonitor(DefaultConfig(), m)
+	mon.SetClock(clock.NewFake(time.Unix(1700000000, 0)))
+	mon.runCheck("odds_api", func() error {
+		return errors.New(`Get "https://api.the-odds-api.com/v4/sports?apiKey=abc123def456": EOF`)
+	})
+
+	status, _ := m.GetDependency("odds_api")
+	if want := `Get "https://api.the-odds-api.com/v4/sports?apiKey=[REDACTED]": EOF`; status.LastError != want {
+		t.Errorf("last error %q, want %q", status.LastError, want)
+	}
+	if status.Status != metrics.DependencyDegraded || status.ConsecutiveFailures != 1 {
+		t.Errorf("after one failure: %+v", status)
+	}
+}
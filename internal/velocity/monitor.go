@@ -0,0 +1,367 @@
+package velocity
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/clock"
+	"github.com/joshuakim/linefinder/internal/lineseries"
+	"github.com/joshuakim/linefinder/internal/models"
+	"github.com/joshuakim/linefinder/internal/store"
+)
+
+// Units a velocity is measured in
+const (
+	// Points is spread and total lines, in points per minute
+	Points = "points"
+
+	// Cents is moneyline prices, in cents of American odds per minute
+	Cents = "cents"
+)
+
+// Config holds velocity monitor configuration
+type Config struct {
+	// Window is the span velocity is measured over. The window before it
+	// is the baseline for acceleration.
+	Window time.Duration
+
+	// PointsPerMinute is the spread or total velocity that raises an alert
+	PointsPerMinute float64
+
+	// CentsPerMinute is the moneyline velocity that raises an alert
+	CentsPerMinute float64
+
+	// Cooldown is how long a game's market stays quiet after an alert
+	Cooldown time.Duration
+}
+
+// DefaultConfig returns a sensible default configuration
+func DefaultConfig() Config {
+	return Config{
+		Window:          10 * time.Minute,
+		PointsPerMinute: 0.05, // half a point in 10 minutes
+		CentsPerMinute:  1.5,  // 15 cents in 10 minutes
+		Cooldown:        30 * time.Minute,
+	}
+}
+
+// Reading is how fast one outcome's line is moving at one bookmaker
+type Reading struct {
+	GameID        string    `json:"game_id"`
+	Sport         string    `json:"sport"`
+	HomeTeam      string    `json:"home_team"`
+	AwayTeam      string    `json:"away_team"`
+	CommenceTime  time.Time `json:"commence_time"`
+	Bookmaker     string    `json:"bookmaker"`
+	Market        string    `json:"market"`
+	Outcome       string    `json:"outcome"`
+	Unit          string    `json:"unit"`
+	From          float64   `json:"from"` // line or price at the start of the window
+	To            float64   `json:"to"`
+	Velocity      float64   `json:"velocity"`       // per minute over the window
+	PriorVelocity float64   `json:"prior_velocity"` // per minute over the window before
+	Accelerating  bool      `json:"accelerating"`
+	MeasuredAt    time.Time `json:"measured_at"`
+}
+
+// Move is a game market moving faster than the threshold and speeding up,
+// led by its fastest bookmaker
+type Move struct {
+	Reading
+	Books      int       `json:"books"` // bookmakers over the threshold
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// sample is a line's value. Moneylines keep the price alongside its value
+// in cents.
+type sample struct {
+	value float64
+	line  float64
+}
+
+// info is the outcome at one bookmaker a series follows
+type info struct {
+	game    models.Game
+	book    string
+	market  models.Market
+	outcome string
+	unit    string
+}
+
+// series is one outcome at one bookmaker
+type series = lineseries.Series[sample, info]
+
+// Monitor tracks how fast lines move and reports rapid moves, which often
+// come before news breaks
+type Monitor struct {
+	config Config
+	clock  clock.Clock
+
+	updates     <-chan store.Update
+	unsubscribe func()
+
+	mu        sync.RWMutex
+	series    *lineseries.Tracker[sample, info] // game|book|market|outcome
+	lastAlert map[string]time.Time              // game|market
+	callback  func([]Move)
+}
+
+// NewMonitor creates a new velocity monitor. It subscribes to the store
+// right away so updates written before Start aren't missed.
+func NewMonitor(config Config, dataStore *store.Store) *Monitor {
+	defaults := DefaultConfig()
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.PointsPerMinute <= 0 {
+		config.PointsPerMinute = defaults.PointsPerMinute
+	}
+	if config.CentsPerMinute <= 0 {
+		config.CentsPerMinute = defaults.CentsPerMinute
+	}
+	if config.Cooldown < 0 {
+		config.Cooldown = defaults.Cooldown
+	}
+	updates, unsubscribe := dataStore.Watch("")
+	// Two windows of moves measure the window and the one before it
+	return &Monitor{
+		config:      config,
+		clock:       clock.Real{},
+		updates:     updates,
+		unsubscribe: unsubscribe,
+		series:      lineseries.New[sample, info](2*config.Window, 2*config.Window),
+		lastAlert:   make(map[string]time.Time),
+	}
+}
+
+// SetClock sets the clock used to time samples
+func (m *Monitor) SetClock(c clock.Clock) {
+	m.clock = c
+}
+
+// SetCallback sets the function called with rapid moves found in an update
+func (m *Monitor) SetCallback(fn func([]Move)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callback = fn
+}
+
+// Start records store updates until the context is cancelled
+func (m *Monitor) Start(ctx context.Context) {
+	defer m.unsubscribe()
+
+	log.Printf("Velocity monitor starting (window: %v, thresholds: %g points/min, %g cents/min)",
+		m.config.Window, m.config.PointsPerMinute, m.config.CentsPerMinute)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case u, ok := <-m.updates:
+			if !ok {
+				return
+			}
+			if !u.Changed {
+				continue
+			}
+			if moves := m.record(u.Games); len(moves) > 0 {
+				m.mu.RLock()
+				callback := m.callback
+				m.mu.RUnlock()
+				if callback != nil {
+					callback(moves)
+				}
+			}
+		}
+	}
+}
+
+// record adds each outcome's current line to its series and returns the
+// markets of upcoming games that are moving fast and speeding up
+func (m *Monitor) record(games []models.Game) []Move {
+	now := m.clock.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	candidates := make(map[string]*Move)
+	books := make(map[string]map[string]bool)
+	for _, game := range games {
+		for _, bm := range game.Bookmakers {
+			for _, market := range bm.Markets {
+				for _, o := range market.Outcomes {
+					line, value, unit, ok := lineValue(market.Key, o)
+					if !ok {
+						continue
+					}
+					key := game.ID + "|" + bm.Key + "|" + string(market.Key) + "|" + o.Name
+					s := m.series.Observe(key, info{game: game, book: bm.Key, market: market.Key, outcome: o.Name, unit: unit},
+						sample{value: value, line: line}, now)
+
+					if !game.CommenceTime.After(now) {
+						continue
+					}
+					reading := m.reading(s, now)
+					if !reading.Accelerating || math.Abs(reading.Velocity) < m.threshold(unit) {
+						continue
+					}
+
+					alertKey := game.ID + "|" + string(market.Key)
+					move := candidates[alertKey]
+					if move == nil {
+						move = &Move{Reading: reading, DetectedAt: now}
+						candidates[alertKey] = move
+						books[alertKey] = make(map[string]bool)
+					} else if math.Abs(reading.Velocity) > math.Abs(move.Velocity) {
+						move.Reading = reading
+					}
+					// Both sides of a market moving count the book once
+					if !books[alertKey][bm.Key] {
+						books[alertKey][bm.Key] = true
+						move.Books++
+					}
+				}
+			}
+		}
+	}
+	m.forget(now)
+
+	moves := make([]Move, 0, len(candidates))
+	for alertKey, move := range candidates {
+		if last, ok := m.lastAlert[alertKey]; ok && now.Sub(last) < m.config.Cooldown {
+			continue
+		}
+		m.lastAlert[alertKey] = now
+		moves = append(moves, *move)
+	}
+	sort.Slice(moves, func(i, j int) bool {
+		return math.Abs(moves[i].Velocity)/m.threshold(moves[i].Unit) > math.Abs(moves[j].Velocity)/m.threshold(moves[j].Unit)
+	})
+	return moves
+}
+
+// Readings returns every upcoming market's velocity, fastest relative to
+// its threshold first. An empty game ID returns every game.
+func (m *Monitor) Readings(gameID string) []Reading {
+	now := m.clock.Now()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	readings := []Reading{}
+	for _, s := range m.series.All() {
+		if gameID != "" && s.Info.game.ID != gameID {
+			continue
+		}
+		if !s.Info.game.CommenceTime.After(now) {
+			continue
+		}
+		readings = append(readings, m.reading(s, now))
+	}
+	sort.Slice(readings, func(i, j int) bool {
+		a := math.Abs(readings[i].Velocity) / m.threshold(readings[i].Unit)
+		b := math.Abs(readings[j].Velocity) / m.threshold(readings[j].Unit)
+		if a != b {
+			return a > b
+		}
+		return readings[i].GameID+readings[i].Bookmaker+readings[i].Market+readings[i].Outcome <
+			readings[j].GameID+readings[j].Bookmaker+readings[j].Market+readings[j].Outcome
+	})
+	return readings
+}
+
+// reading measures a series over the window ending now and the one before.
+// A move that started with the series (nothing earlier was seen) counts
+// from when it was first seen.
+func (m *Monitor) reading(s *series, now time.Time) Reading {
+	window := m.config.Window
+	minutes := window.Minutes()
+
+	from := s.At(now.Add(-window))
+	to := s.At(now)
+	prior := s.At(now.Add(-2 * window))
+
+	velocity := (to.value - from.value) / minutes
+	priorVelocity := (from.value - prior.value) / minutes
+
+	return Reading{
+		GameID:        s.Info.game.ID,
+		Sport:         string(s.Info.game.SportKey),
+		HomeTeam:      s.Info.game.HomeTeam,
+		AwayTeam:      s.Info.game.AwayTeam,
+		CommenceTime:  s.Info.game.CommenceTime,
+		Bookmaker:     s.Info.book,
+		Market:        string(s.Info.market),
+		Outcome:       s.Info.outcome,
+		Unit:          s.Info.unit,
+		From:          from.line,
+		To:            to.line,
+		Velocity:      velocity,
+		PriorVelocity: priorVelocity,
+		Accelerating:  accelerating(velocity, priorVelocity),
+		MeasuredAt:    now,
+	}
+}
+
+// accelerating reports whether a line is moving faster than in the window
+// before, in the same direction or after standing still or reversing
+func accelerating(velocity, prior float64) bool {
+	if velocity == 0 {
+		return false
+	}
+	if prior == 0 || (velocity > 0) != (prior > 0) {
+		return true
+	}
+	return math.Abs(velocity) > math.Abs(prior)
+}
+
+// forget drops series that haven't been seen for two windows, such as
+// finished games, and alert cooldowns that have run out
+func (m *Monitor) forget(now time.Time) {
+	m.series.Forget(now)
+	for key, last := range m.lastAlert {
+		if now.Sub(last) >= m.config.Cooldown {
+			delete(m.lastAlert, key)
+		}
+	}
+}
+
+// threshold returns the alerting velocity for a unit
+func (m *Monitor) threshold(unit string) float64 {
+	if unit == Points {
+		return m.config.PointsPerMinute
+	}
+	return m.config.CentsPerMinute
+}
+
+// lineValue returns an outcome's line and the value whose movement is
+// tracked: the point for spreads and totals, the price in cents for
+// moneylines
+func lineValue(market models.Market, o models.Outcome) (line, value float64, unit string, ok bool) {
+	switch market {
+	case models.MarketSpreads, models.MarketTotals:
+		if o.Point == nil {
+			return 0, 0, "", false
+		}
+		return *o.Point, *o.Point, Points, true
+	case models.MarketH2H:
+		return o.Price, cents(o.Price), Cents, true
+	}
+	return 0, 0, "", false
+}
+
+// cents maps American odds onto a continuous scale, so -105 to +105 is a
+// 10 cent move like -110 to -120
+func cents(price float64) float64 {
+	if price >= 100 {
+		return price - 100
+	}
+	if price <= -100 {
+		return price + 100
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Set at build time with -ldflags, e.g.
+//
+//	-X github.com/joshuakim/linefinder/internal/version.Version=v1.2.0
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string   `json:"version"`
+	Commit    string   `json:"commit"`
+	Date      string   `json:"date,omitempty"`
+	Modified  bool     `json:"modified,omitempty"` // built from a tree with uncommitted changes
+	Tags      []string `json:"tags,omitempty"`     // build tags, e.g. postgres
+	GoVersion string   `json:"go_version"`
+	OS        string   `json:"os"`
+	Arch      string   `json:"arch"`
+}
+
+// Get returns the running build's version info. Commit and date fall back to
+// the VCS stamp Go embeds in binaries built inside a git checkout.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range build.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			case "-tags":
+				info.Tags = strings.Split(s.Value, ",")
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	return info
+}
+
+// String returns a one-line summary, e.g. "v1.2.0 (3ad9367, linux/arm64)"
+func (i Info) String() string {
+	commit := i.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	if i.Modified {
+		commit += "-dirty"
+	}
+	return i.Version + " (" + commit + ", " + i.OS + "/" + i.Arch + ")"
+}
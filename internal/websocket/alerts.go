@@ -0,0 +1,106 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/alertstream"
+)
+
+// Alert stream message types
+const (
+	MessageTypeAlert             = "alert"
+	MessageTypeSubscribeAlerts   = "subscribe_alerts"
+	MessageTypeUnsubscribeAlerts = "unsubscribe_alerts"
+)
+
+// SetAlertStream sets the alert stream clients can subscribe to with
+// subscribe_alerts and starts forwarding it. Call before clients connect.
+func (h *Hub) SetAlertStream(stream *alertstream.Stream) {
+	h.alertStream = stream
+	feed, _ := stream.Subscribe(alertstream.Filter{})
+	go func() {
+		for alert := range feed {
+			h.broadcastAlert(alert)
+		}
+	}()
+}
+
+// SubscribeAlerts sets a client's alert filter, replacing any earlier one
+func (h *Hub) SubscribeAlerts(client *Client, filter alertstream.Filter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	client.alerts = &filter
+}
+
+// UnsubscribeAlerts stops sending alerts to a client
+func (h *Hub) UnsubscribeAlerts(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	client.alerts = nil
+}
+
+// broadcastAlert sends an alert to every client whose filter it passes,
+// whatever sports they're subscribed to for odds. Value alerts are left to
+// DeliverValueAlert, so they arrive once, typed and numbered, even for
+// clients also subscribed to the alerts topic.
+func (h *Hub) broadcastAlert(alert alertstream.Alert) {
+	if alert.Type == alertstream.TypeValue {
+		return
+	}
+
+	message := Message{
+		Type:      MessageTypeAlert,
+		Sport:     alert.Sport,
+		Alert:     &alert,
+		Timestamp: time.Now(),
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("WebSocket: Failed to marshal alert: %v", err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		if client.alerts == nil || !client.alerts.Match(alert) {
+			continue
+		}
+		select {
+		case client.send <- data:
+		default:
+			// Skip slow clients, as for status messages
+			h.metrics.RecordMessageFailed()
+		}
+	}
+}
+
+func (c *Client) handleSubscribeAlerts(filter alertstream.Filter) {
+	if c.hub.alertStream == nil {
+		c.sendError("Alert stream not available")
+		return
+	}
+	c.hub.mu.RLock()
+	replay := !c.valueAlerts
+	c.hub.mu.RUnlock()
+
+	c.hub.SubscribeAlerts(c, filter)
+	c.sendStatus("subscribed to alerts")
+
+	// Active value alerts the filter takes, unless the alerts topic
+	// already sent them
+	if replay {
+		c.replayValueAlerts(func(alert alerts.ValueAlert) bool {
+			return filter.Match(valueStreamAlert(alert))
+		})
+	}
+}
+
+func (c *Client) handleUnsubscribeAlerts() {
+	c.hub.UnsubscribeAlerts(c)
+	c.sendStatus("unsubscribed from alerts")
+}
@@ -0,0 +1,187 @@
+package websocket
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// MessageTypeAuth authenticates a connection opened without a token:
+// {"type": "auth", "token": "..."}
+const MessageTypeAuth = "auth"
+
+// authWait is how long a connection has to send its auth message
+const authWait = 10 * time.Second
+
+// DevOrigin is the Vite dev server's origin, allowed alongside the
+// server's own when no origins are configured
+const DevOrigin = "http://localhost:5173"
+
+// ParseTokens reads "identity:token" pairs, comma-separated, into a map
+// from token to identity
+func ParseTokens(s string) (map[string]string, error) {
+	tokens := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		identity, token, ok := strings.Cut(pair, ":")
+		identity, token = strings.TrimSpace(identity), strings.TrimSpace(token)
+		if !ok || identity == "" || token == "" {
+			// Without the entry, which may be a bare token
+			return nil, fmt.Errorf("entry %d isn't identity:token", len(tokens)+1)
+		}
+		if _, dup := tokens[token]; dup {
+			return nil, fmt.Errorf("token for %q is already used", identity)
+		}
+		tokens[token] = identity
+	}
+	return tokens, nil
+}
+
+// SetTokens requires clients to authenticate with one of the tokens, each
+// naming the identity its clients get. No tokens leaves connections
+// open to anyone. Call before serving.
+func (h *Hub) SetTokens(tokens map[string]string) {
+	h.tokens = tokens
+}
+
+// AuthRequired reports whether clients have to authenticate
+func (h *Hub) AuthRequired() bool {
+	return len(h.tokens) > 0
+}
+
+// SetAllowedOrigins sets the browser origins allowed to connect, such as
+// "https://app.example.com", or "*" for any. Without any, only pages
+// served by this server and the dev server can. Call before serving.
+func (h *Hub) SetAllowedOrigins(origins []string) {
+	h.origins = origins
+}
+
+// checkOrigin allows requests without an Origin header, which don't come
+// from browsers, and those from an allowed origin
+func (h *Hub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if len(h.origins) == 0 {
+		u, err := url.Parse(origin)
+		return origin == DevOrigin || (err == nil && strings.EqualFold(u.Host, r.Host))
+	}
+	for _, allowed := range h.origins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Identify looks up the identity a token belongs to. The alert stream
+// checks the same tokens, so it can't be used to get around them.
+func (h *Hub) Identify(token string) (string, bool) {
+	for t, identity := range h.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return identity, true
+		}
+	}
+	return "", false
+}
+
+// setIdentity records who a client authenticated as, so messages can be
+// routed to them
+func (h *Hub) setIdentity(client *Client, identity string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client.identity = identity
+	client.authenticated = true
+	if h.identities[identity] == nil {
+		h.identities[identity] = make(map[*Client]bool)
+	}
+	h.identities[identity][client] = true
+}
+
+// forgetIdentity drops a client from the identity index. Call with the
+// hub's mutex held.
+func (h *Hub) forgetIdentity(client *Client) {
+	if client.identity == "" {
+		return
+	}
+	delete(h.identities[client.identity], client)
+	if len(h.identities[client.identity]) == 0 {
+		delete(h.identities, client.identity)
+	}
+}
+
+// SendToIdentity sends a message to every connection of one identity on
+// this instance, returning how many it was sent to
+func (h *Hub) SendToIdentity(identity string, data []byte) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	sent := 0
+	for client := range h.identities[identity] {
+		select {
+		case client.send <- data:
+			sent++
+		default:
+			// Skip slow clients, as for broadcasts
+			h.metrics.RecordMessageFailed()
+		}
+	}
+	return sent
+}
+
+// handleAuth checks the first message of a connection that has to
+// authenticate, closing it unless it's an auth message with a valid token
+func (c *Client) handleAuth(msg ClientMessage) bool {
+	if msg.Type != MessageTypeAuth {
+		c.closeUnauthorized("authentication required")
+		return false
+	}
+	identity, ok := c.hub.Identify(msg.Token)
+	if !ok {
+		c.closeUnauthorized("invalid token")
+		return false
+	}
+	c.hub.setIdentity(c, identity)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.sendAuthenticated()
+	return true
+}
+
+// sendAuthenticated tells the client who it's connected as
+func (c *Client) sendAuthenticated() {
+	c.hub.mu.RLock()
+	identity := c.identity
+	c.hub.mu.RUnlock()
+
+	data, _ := json.Marshal(Message{
+		Type:       MessageTypeStatus,
+		Status:     "authenticated",
+		Timestamp:  time.Now(),
+		Instance:   c.hub.instance,
+		Connection: c.id,
+		Identity:   identity,
+	})
+	select {
+	case c.send <- data:
+	default:
+		// Buffer full, skip
+	}
+}
+
+// closeUnauthorized closes the connection with a policy violation
+func (c *Client) closeUnauthorized(reason string) {
+	msg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason)
+	c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+	c.conn.Close()
+}
@@ -0,0 +1,145 @@
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+
+	"github.com/joshuakim/linefinder/internal/metrics"
+)
+
+func TestParseTokens(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string]string
+		wantErr bool
+	}{
+		{in: "alice:s3cret, bot:t0ken", want: map[string]string{"s3cret": "alice", "t0ken": "bot"}},
+		{in: "", want: map[string]string{}},
+		{in: "alice:s3cret,", want: map[string]string{"s3cret": "alice"}},
+		{in: "s3cret", wantErr: true},
+		{in: "alice:", wantErr: true},
+		{in: "alice:same,bob:same", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTokens(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTokens(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTokens(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	tests := []struct {
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		// Not from a browser
+		{nil, "", true},
+		{nil, "https://odds.example.com", true},
+		{nil, DevOrigin, true},
+		{nil, "https://evil.example.com", false},
+		{[]string{"https://app.example.com/"}, "https://app.example.com", true},
+		// Configured origins replace the server's own
+		{[]string{"https://app.example.com"}, "https://odds.example.com", false},
+		{[]string{"*"}, "https://evil.example.com", true},
+	}
+	for _, tt := range tests {
+		hub := NewHub(metrics.New(), 0)
+		hub.SetAllowedOrigins(tt.allowed)
+		r := httptest.NewRequest(http.MethodGet, "http://odds.example.com/api/v1/ws", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if got := hub.checkOrigin(r); got != tt.want {
+			t.Errorf("allowed %v, origin %q: got %v, want %v", tt.allowed, tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	hub := NewHub(metrics.New(), 0)
+	hub.SetTokens(map[string]string{"s3cret": "alice"})
+	go hub.Run()
+
+	if _, resp, err := dial(t, hub, "?token=wrong"); err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong token in the URL: got %v, want a 401", err)
+	}
+
+	byURL, _, err := dial(t, hub, "?token=s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byMessage, _, err := dial(t, hub, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForClients(t, hub, 2)
+
+	// Only the authenticated connection gets status messages
+	hub.BroadcastStatus("quota_exhausted")
+	if got, want := receiveUntilPong(t, byURL), []string{"status quota_exhausted"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("authenticated by URL got %v, want %v", got, want)
+	}
+	send(t, byMessage, ClientMessage{Type: MessageTypeAuth, Token: "s3cret"})
+	if got, want := receiveUntilPong(t, byMessage), []string{"status authenticated"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("authenticating by message got %v, want %v", got, want)
+	}
+
+	// Anything else first closes the connection
+	for _, first := range []ClientMessage{
+		{Type: MessageTypeSubscribe, Sport: "nba"},
+		{Type: MessageTypeAuth, Token: "wrong"},
+	} {
+		conn, _, err := dial(t, hub, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		send(t, conn, first)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, _, err = conn.ReadMessage()
+		var closeErr *gorilla.CloseError
+		if !errors.As(err, &closeErr) || closeErr.Code != gorilla.ClosePolicyViolation {
+			t.Errorf("first message %s: got %v, want close %d", first.Type, err, gorilla.ClosePolicyViolation)
+		}
+	}
+}
+
+// waitForClients waits for the hub to register n clients
+func waitForClients(t *testing.T, hub *Hub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.ClientCount() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d clients registered, want %d", hub.ClientCount(), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	hub := NewHub(metrics.New(), 0)
+	if hub.AuthRequired() {
+		t.Error("auth required without tokens")
+	}
+	hub.SetTokens(map[string]string{"s3cret": "alice"})
+	if !hub.AuthRequired() {
+		t.Error("auth not required with tokens")
+	}
+	for token, want := range map[string]bool{"s3cret": true, "": false, "s3cre": false, strings.Repeat("s3cret", 2): false} {
+		if identity, ok := hub.Identify(token); ok != want || (ok && identity != "alice") {
+			t.Errorf("Identify(%q) = (%q, %v), want ok %v", token, identity, ok, want)
+		}
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/joshuakim/linefinder/internal/alertstream"
 	"github.com/joshuakim/linefinder/internal/models"
 )
 
@@ -27,14 +29,10 @@ const (
 	sendBufferSize = 256
 )
 
+// upgrader is copied for each connection with the hub's origin check
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		// In production, you should validate the origin properly
-		// For development, allow all origins
-		return true
-	},
 }
 
 // Client represents a WebSocket client connection
@@ -43,23 +41,69 @@ type Client struct {
 	conn *websocket.Conn
 	send chan []byte
 
+	// Who connected and when, for the connections listing
+	id           string
+	remoteAddr   string
+	forwardedFor string
+	userAgent    string
+	connectedAt  time.Time
+
 	// Subscriptions this client has
 	sports map[models.Sport]bool
+
+	// Alert stream filter when subscribed to alerts, guarded by the hub's mutex
+	alerts *alertstream.Filter
+
+	// Games followed for live prop updates, guarded by the hub's mutex
+	live map[string]bool
+
+	// Subscribed to the alerts topic, guarded by the hub's mutex
+	valueAlerts bool
+
+	// Who the client authenticated as, guarded by the hub's mutex, and
+	// whether it has, when the hub requires it
+	identity      string
+	authenticated bool
 }
 
 // ClientMessage represents a message from the client
 type ClientMessage struct {
 	Type  string `json:"type"`
 	Sport string `json:"sport,omitempty"`
+
+	// Topic for subscribe and unsubscribe instead of a sport, e.g. "alerts"
+	Topic string `json:"topic,omitempty"`
+
+	// Alert stream filters for subscribe_alerts
+	Types      []string `json:"types,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	Sports     []string `json:"sports,omitempty"`
+
+	// Game for subscribe_live and unsubscribe_live
+	GameID string `json:"game_id,omitempty"`
+
+	// Subscriptions to restore, from a reconnect_after message
+	Resume *ResumeState `json:"resume,omitempty"`
+
+	// Token for auth, when it wasn't given in the URL
+	Token string `json:"token,omitempty"`
+
+	// For subscribe, the seq of the last message received on the topic and
+	// the instance that sent it, to be sent what was missed since
+	ResumeFrom *int64 `json:"resume_from,omitempty"`
+	Instance   string `json:"instance,omitempty"`
 }
 
 // NewClient creates a new client and starts its goroutines
 func NewClient(hub *Hub, conn *websocket.Conn) *Client {
 	return &Client{
-		hub:    hub,
-		conn:   conn,
-		send:   make(chan []byte, sendBufferSize),
-		sports: make(map[models.Sport]bool),
+		hub:         hub,
+		conn:        conn,
+		send:        make(chan []byte, sendBufferSize),
+		id:          strconv.FormatInt(hub.lastConnection.Add(1), 10),
+		remoteAddr:  conn.RemoteAddr().String(),
+		connectedAt: time.Now(),
+		sports:      make(map[models.Sport]bool),
 	}
 }
 
@@ -70,14 +114,37 @@ func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Server at capacity", http.StatusServiceUnavailable)
 		return
 	}
+	if hub.Draining() {
+		http.Error(w, "Server restarting", http.StatusServiceUnavailable)
+		return
+	}
 
-	conn, err := upgrader.Upgrade(w, r, nil)
+	// A token in the URL authenticates before upgrading; without one,
+	// the first message has to
+	var identity string
+	if token := r.URL.Query().Get("token"); token != "" && hub.AuthRequired() {
+		var ok bool
+		if identity, ok = hub.Identify(token); !ok {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	up := upgrader
+	up.CheckOrigin = hub.checkOrigin
+	conn, err := up.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade failed: %v", err)
 		return
 	}
 
 	client := NewClient(hub, conn)
+	// Behind a load balancer the remote address is the balancer's
+	client.forwardedFor = r.Header.Get("X-Forwarded-For")
+	client.userAgent = r.UserAgent()
+	if identity != "" {
+		hub.setIdentity(client, identity)
+	}
 	hub.register <- client
 
 	// Start client goroutines
@@ -97,7 +164,11 @@ func (c *Client) readPump() {
 	}()
 
 	c.conn.SetReadLimit(maxMessageSize)
-	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	if c.hub.AuthRequired() && !c.authenticated {
+		c.conn.SetReadDeadline(time.Now().Add(authWait))
+	} else {
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	}
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(pongWait))
 		return nil
@@ -173,11 +244,37 @@ func (c *Client) handleMessage(data []byte) {
 		return
 	}
 
+	if c.hub.AuthRequired() && !c.authenticated {
+		c.handleAuth(msg)
+		return
+	}
+
 	switch msg.Type {
 	case MessageTypeSubscribe:
-		c.handleSubscribe(msg.Sport)
+		if msg.Topic != "" {
+			c.handleSubscribeTopic(msg.Topic, msg.ResumeFrom, msg.Instance)
+			break
+		}
+		c.handleSubscribe(msg.Sport, msg.ResumeFrom, msg.Instance)
 	case MessageTypeUnsubscribe:
+		if msg.Topic != "" {
+			c.handleUnsubscribeTopic(msg.Topic)
+			break
+		}
 		c.handleUnsubscribe(msg.Sport)
+	case MessageTypeSubscribeAlerts:
+		c.handleSubscribeAlerts(alertstream.NewFilter(msg.Types, msg.Categories, msg.Sports))
+	case MessageTypeUnsubscribeAlerts:
+		c.handleUnsubscribeAlerts()
+	case MessageTypeSubscribeLive:
+		c.handleSubscribeLive(msg.GameID)
+	case MessageTypeUnsubscribeLive:
+		c.handleUnsubscribeLive(msg.GameID)
+	case MessageTypeResume:
+		c.handleResume(msg.Resume)
+	case MessageTypeAuth:
+		// Already authenticated, or not required
+		c.sendAuthenticated()
 	case "ping":
 		c.sendPong()
 	default:
@@ -185,10 +282,12 @@ func (c *Client) handleMessage(data []byte) {
 	}
 }
 
-func (c *Client) handleSubscribe(sportStr string) {
-	sport := models.Sport(sportStr)
-	if sport != models.SportNFL && sport != models.SportNBA {
-		c.sendError("Invalid sport: use 'nfl' or 'nba'")
+// handleSubscribe subscribes a client to one sport's odds updates. With
+// resume_from, the updates it missed since are sent first.
+func (c *Client) handleSubscribe(sportStr string, resumeFrom *int64, instance string) {
+	sport, ok := models.ParseSport(sportStr)
+	if !ok {
+		c.sendError("Invalid sport: use " + models.SportChoices())
 		return
 	}
 
@@ -200,10 +299,15 @@ func (c *Client) handleSubscribe(sportStr string) {
 
 	// Subscribe to new sport
 	c.sports[sport] = true
-	c.hub.Subscribe(c, sport)
 
-	// Send confirmation
+	// Confirmation first, as updates replayed with resume_from follow it
 	c.sendStatus("subscribed to " + sportStr)
+	subscribe := func() { c.hub.Subscribe(c, sport) }
+	if resumeFrom == nil {
+		subscribe()
+	} else if !c.hub.resumeTopic(c, string(sport), *resumeFrom, instance, subscribe) {
+		c.sendStatus(StatusReplayUnavailable)
+	}
 }
 
 func (c *Client) handleUnsubscribe(sportStr string) {
@@ -231,9 +335,11 @@ func (c *Client) sendError(errMsg string) {
 
 func (c *Client) sendStatus(status string) {
 	msg := Message{
-		Type:      MessageTypeStatus,
-		Status:    status,
-		Timestamp: time.Now(),
+		Type:       MessageTypeStatus,
+		Status:     status,
+		Timestamp:  time.Now(),
+		Instance:   c.hub.instance,
+		Connection: c.id,
 	}
 	data, _ := json.Marshal(msg)
 	select {
@@ -0,0 +1,156 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/joshuakim/linefinder/internal/alertstream"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// Deploy migration message types
+const (
+	MessageTypeReconnectAfter = "reconnect_after"
+	MessageTypeResume         = "resume"
+)
+
+// ResumeState is what a client was subscribed to, sent with
+// reconnect_after and sent back in a resume message on the new connection
+type ResumeState struct {
+	Sport  string              `json:"sport,omitempty"`
+	Topics []string            `json:"topics,omitempty"`
+	Alerts *alertstream.Filter `json:"alerts,omitempty"`
+	Live   []string            `json:"live,omitempty"`
+}
+
+// Drain tells every client to reconnect elsewhere, each after a random
+// delay up to spread so they don't all arrive at once, and turns away new
+// connections. Keep serving for a while afterwards so clients can open
+// their new connection before this one closes.
+func (h *Hub) Drain(spread time.Duration) {
+	h.draining.Store(true)
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	sports := h.clientSports()
+	for client := range h.clients {
+		var retryAfter time.Duration
+		if spread > 0 {
+			retryAfter = time.Duration(rand.Int63n(int64(spread)))
+		}
+		state := ResumeState{Alerts: client.alerts}
+		if len(sports[client]) > 0 {
+			state.Sport = sports[client][0]
+		}
+		if client.valueAlerts {
+			state.Topics = []string{TopicAlerts}
+		}
+		for gameID := range client.live {
+			state.Live = append(state.Live, gameID)
+		}
+		sort.Strings(state.Live)
+
+		data, err := json.Marshal(Message{
+			Type:         MessageTypeReconnectAfter,
+			Timestamp:    time.Now(),
+			Instance:     h.instance,
+			Connection:   client.id,
+			RetryAfterMs: retryAfter.Milliseconds(),
+			Resume:       &state,
+		})
+		if err != nil {
+			log.Printf("WebSocket: Failed to marshal reconnect_after: %v", err)
+			continue
+		}
+		select {
+		case client.send <- data:
+		default:
+			// Skip slow clients, they reconnect once closed
+			h.metrics.RecordMessageFailed()
+		}
+	}
+	log.Printf("WebSocket: Draining %d clients over %s", len(h.clients), spread)
+}
+
+// Draining reports whether Drain has been called
+func (h *Hub) Draining() bool {
+	return h.draining.Load()
+}
+
+// CloseAll closes every client's connection, telling them the server is
+// restarting. Server.Shutdown leaves upgraded connections open, so call
+// this once done serving.
+func (h *Hub) CloseAll() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	msg := websocket.FormatCloseMessage(websocket.CloseServiceRestart, "server restarting")
+	deadline := time.Now().Add(writeWait)
+	for client := range h.clients {
+		client.conn.WriteControl(websocket.CloseMessage, msg, deadline)
+		client.conn.Close()
+	}
+}
+
+// clientSports maps each client to the sports it's subscribed to, by
+// short key. Call with the hub's mutex held.
+func (h *Hub) clientSports() map[*Client][]string {
+	// Sports come from the hub's subscriptions, as the client's own set
+	// is only safe to read from its goroutine
+	sports := make(map[*Client][]string)
+	for sport, clients := range h.subscriptions {
+		for client := range clients {
+			sports[client] = append(sports[client], sport.ShortKey())
+		}
+	}
+	for _, s := range sports {
+		sort.Strings(s)
+	}
+	return sports
+}
+
+// handleResume restores a client's subscriptions from a previous
+// connection in one go, replying with a single status
+func (c *Client) handleResume(state *ResumeState) {
+	if state == nil {
+		c.sendError("resume is required")
+		return
+	}
+
+	if state.Sport != "" {
+		sport, ok := models.ParseSport(state.Sport)
+		if !ok {
+			c.sendError("Invalid sport: use " + models.SportChoices())
+			return
+		}
+		for s := range c.sports {
+			c.hub.Unsubscribe(c, s)
+		}
+		c.sports = map[models.Sport]bool{sport: true}
+		c.hub.Subscribe(c, sport)
+	}
+
+	// No replay: the old connection has already seen them
+	for _, topic := range state.Topics {
+		if topic == TopicAlerts {
+			c.hub.SubscribeValueAlerts(c)
+		}
+	}
+
+	if state.Alerts != nil && c.hub.alertStream != nil {
+		c.hub.SubscribeAlerts(c, alertstream.NewFilter(state.Alerts.Types, state.Alerts.Categories, state.Alerts.Sports))
+	}
+
+	for _, gameID := range state.Live {
+		if gameID != "" && !c.hub.SubscribeLive(c, gameID) {
+			break
+		}
+	}
+
+	c.sendStatus("resumed")
+}
@@ -4,8 +4,12 @@ import (
 	"encoding/json"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/alertstream"
+	"github.com/joshuakim/linefinder/internal/liveprops"
 	"github.com/joshuakim/linefinder/internal/metrics"
 	"github.com/joshuakim/linefinder/internal/models"
 )
@@ -28,6 +32,40 @@ type Message struct {
 	Timestamp time.Time       `json:"timestamp"`
 	Error     string          `json:"error,omitempty"`
 	Status    string          `json:"status,omitempty"`
+	Alert     *alertstream.Alert `json:"alert,omitempty"`
+	Live      *liveprops.GameProps `json:"live,omitempty"`
+
+	// On value_alert messages, the alert, and whether it was raised before
+	// the client subscribed
+	ValueAlert *alerts.ValueAlert `json:"value_alert,omitempty"`
+	Replay     bool               `json:"replay,omitempty"`
+
+	// On ev_alert, outlier_alert, event_alert, system_notice and
+	// alert_state messages, what they're about; on alert_inbox, the unread
+	// count
+	EV         *models.EVOpportunity `json:"ev,omitempty"`
+	Outlier    *models.OutlierLine   `json:"outlier,omitempty"`
+	Event      *alerts.EventAlert    `json:"event,omitempty"`
+	Notice     *alerts.SystemNotice  `json:"notice,omitempty"`
+	AlertState *alerts.StateChange   `json:"alert_state,omitempty"`
+	Unread     *int                  `json:"unread,omitempty"`
+
+	// On status messages, the instance and connection delivering them;
+	// on odds_update and value_alert, the instance that numbered them
+	Instance   string `json:"instance,omitempty"`
+	Connection string `json:"connection,omitempty"`
+
+	// On the authenticated status, who the connection belongs to
+	Identity string `json:"identity,omitempty"`
+
+	// On odds_update and value_alert, increasing with each message sent,
+	// for resuming with resume_from
+	Seq int64 `json:"seq,omitempty"`
+
+	// On reconnect_after, how long to wait before reconnecting and the
+	// subscriptions to resume on the new connection
+	RetryAfterMs int64        `json:"retry_after_ms,omitempty"`
+	Resume       *ResumeState `json:"resume,omitempty"`
 }
 
 // Hub maintains the set of active clients and broadcasts messages
@@ -52,6 +90,35 @@ type Hub struct {
 
 	// Configuration
 	maxConnections int
+
+	// Unified alert feed for subscribe_alerts, when set
+	alertStream *alertstream.Stream
+
+	// Value alerts replayed on subscribing to the alerts topic, when set
+	activeAlerts func() []alerts.ValueAlert
+
+	// Shares broadcasts with other instances, when set
+	relay Relay
+
+	// This instance's ID, set before serving
+	instance string
+
+	// Last connection ID handed out
+	lastConnection atomic.Int64
+
+	// Set once Drain has told clients to move, turning new ones away
+	draining atomic.Bool
+
+	// Tokens clients authenticate with, mapped to their identities, and
+	// the browser origins allowed to connect; set before serving
+	tokens  map[string]string
+	origins []string
+
+	// Authenticated clients by identity
+	identities map[string]map[*Client]bool
+
+	// Sequence numbers and each topic's latest messages
+	replay *replayBuffers
 }
 
 // NewHub creates a new Hub
@@ -62,6 +129,8 @@ func NewHub(m *metrics.Metrics, maxConnections int) *Hub {
 	return &Hub{
 		clients:        make(map[*Client]bool),
 		subscriptions:  make(map[models.Sport]map[*Client]bool),
+		identities:     make(map[string]map[*Client]bool),
+		replay:         newReplayBuffers(),
 		register:       make(chan *Client, 256),
 		unregister:     make(chan *Client, 256),
 		metrics:        m,
@@ -110,6 +179,9 @@ func (h *Hub) unregisterClient(client *Client) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	// Including clients turned away at capacity
+	h.forgetIdentity(client)
+
 	if _, ok := h.clients[client]; ok {
 		delete(h.clients, client)
 
@@ -150,8 +222,9 @@ func (h *Hub) Unsubscribe(client *Client, sport models.Sport) {
 	}
 }
 
-// Broadcast sends a message to all clients subscribed to a sport
-func (h *Hub) Broadcast(sport models.Sport, games []models.Game) {
+// DeliverOdds sends an odds update to this instance's clients subscribed
+// to a sport
+func (h *Hub) DeliverOdds(sport models.Sport, games []models.Game) {
 	message := Message{
 		Type:      MessageTypeOddsUpdate,
 		Sport:     string(sport),
@@ -159,7 +232,8 @@ func (h *Hub) Broadcast(sport models.Sport, games []models.Game) {
 		Timestamp: time.Now(),
 	}
 
-	data, err := json.Marshal(message)
+	// Numbered and kept even without subscribers, for clients resuming
+	data, err := h.sequence(string(sport), &message)
 	if err != nil {
 		log.Printf("WebSocket: Failed to marshal broadcast message: %v", err)
 		return
@@ -201,29 +275,14 @@ func (h *Hub) Broadcast(sport models.Sport, games []models.Game) {
 	log.Printf("WebSocket: Broadcast %s to %d clients (%d bytes)", sport, clientCount-len(failedClients), len(data))
 }
 
-// BroadcastStatus sends a status message to all clients
-func (h *Hub) BroadcastStatus(status string) {
-	message := Message{
+// DeliverStatus sends a status message to all of this instance's clients,
+// leaving out those yet to authenticate
+func (h *Hub) DeliverStatus(status string) {
+	h.DeliverMessage(Message{
 		Type:      MessageTypeStatus,
 		Status:    status,
 		Timestamp: time.Now(),
-	}
-
-	data, err := json.Marshal(message)
-	if err != nil {
-		return
-	}
-
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-
-	for client := range h.clients {
-		select {
-		case client.send <- data:
-		default:
-			// Skip slow clients for status messages
-		}
-	}
+	})
 }
 
 // GetStats returns hub statistics
@@ -0,0 +1,72 @@
+package websocket
+
+import (
+	"sort"
+	"time"
+)
+
+// ConnectionInfo describes one client connection, for finding which
+// instance behind a load balancer holds it
+type ConnectionInfo struct {
+	ID           string    `json:"id"`
+	Identity     string    `json:"identity,omitempty"`
+	RemoteAddr   string    `json:"remote_addr"`
+	ForwardedFor string    `json:"forwarded_for,omitempty"`
+	UserAgent    string    `json:"user_agent,omitempty"`
+	ConnectedAt  time.Time `json:"connected_at"`
+	Sports       []string  `json:"sports,omitempty"`
+	Alerts       bool      `json:"alerts"`
+	Live         []string  `json:"live,omitempty"`
+}
+
+// InstanceConnections is the connections one instance holds
+type InstanceConnections struct {
+	Instance    string           `json:"instance"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	Connections []ConnectionInfo `json:"connections"`
+}
+
+// SetInstanceID names this instance in status messages and the connections
+// listing. Call before serving.
+func (h *Hub) SetInstanceID(id string) {
+	h.instance = id
+}
+
+// InstanceID returns the ID set with SetInstanceID
+func (h *Hub) InstanceID() string {
+	return h.instance
+}
+
+// Connections lists this instance's connections, oldest first
+func (h *Hub) Connections() InstanceConnections {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	sports := h.clientSports()
+
+	conns := make([]ConnectionInfo, 0, len(h.clients))
+	for client := range h.clients {
+		info := ConnectionInfo{
+			ID:           client.id,
+			Identity:     client.identity,
+			RemoteAddr:   client.remoteAddr,
+			ForwardedFor: client.forwardedFor,
+			UserAgent:    client.userAgent,
+			ConnectedAt:  client.connectedAt,
+			Sports:       sports[client],
+			Alerts:       client.alerts != nil,
+		}
+		for gameID := range client.live {
+			info.Live = append(info.Live, gameID)
+		}
+		sort.Strings(info.Live)
+		conns = append(conns, info)
+	}
+	sort.Slice(conns, func(i, j int) bool { return conns[i].ConnectedAt.Before(conns[j].ConnectedAt) })
+
+	return InstanceConnections{
+		Instance:    h.instance,
+		UpdatedAt:   time.Now(),
+		Connections: conns,
+	}
+}
@@ -0,0 +1,108 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/liveprops"
+)
+
+// Live prop message types
+const (
+	MessageTypeLiveProps       = "live_props"
+	MessageTypeSubscribeLive   = "subscribe_live"
+	MessageTypeUnsubscribeLive = "unsubscribe_live"
+)
+
+// maxLiveGames caps how many games one client follows live
+const maxLiveGames = 20
+
+// SubscribeLive adds a game to the ones a client gets live prop updates
+// for, returning false when the client already follows the most it can
+func (h *Hub) SubscribeLive(client *Client, gameID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if client.live == nil {
+		client.live = make(map[string]bool)
+	}
+	if !client.live[gameID] && len(client.live) >= maxLiveGames {
+		return false
+	}
+	client.live[gameID] = true
+	return true
+}
+
+// UnsubscribeLive stops live prop updates for a game, or for every game
+// when gameID is empty
+func (h *Hub) UnsubscribeLive(client *Client, gameID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if gameID == "" {
+		client.live = nil
+		return
+	}
+	delete(client.live, gameID)
+}
+
+// BroadcastLiveProps sends a live game's prop paces to the clients
+// following it, on this instance and through the relay on others
+func (h *Hub) BroadcastLiveProps(live liveprops.GameProps) {
+	h.DeliverLiveProps(live)
+	if h.relay != nil {
+		h.relay.PublishLiveProps(live)
+	}
+}
+
+// DeliverLiveProps sends a live game's prop paces to this instance's
+// clients following it
+func (h *Hub) DeliverLiveProps(live liveprops.GameProps) {
+	message := Message{
+		Type:      MessageTypeLiveProps,
+		Sport:     "nba",
+		Live:      &live,
+		Timestamp: time.Now(),
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("WebSocket: Failed to marshal live props: %v", err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		if !client.live[live.GameID] {
+			continue
+		}
+		select {
+		case client.send <- data:
+		default:
+			// Skip slow clients, as for status messages
+			h.metrics.RecordMessageFailed()
+		}
+	}
+}
+
+func (c *Client) handleSubscribeLive(gameID string) {
+	if gameID == "" {
+		c.sendError("game_id is required")
+		return
+	}
+	if !c.hub.SubscribeLive(c, gameID) {
+		c.sendError("Following too many live games: at most " + strconv.Itoa(maxLiveGames))
+		return
+	}
+	c.sendStatus("subscribed to live props for " + gameID)
+}
+
+func (c *Client) handleUnsubscribeLive(gameID string) {
+	c.hub.UnsubscribeLive(c, gameID)
+	if gameID == "" {
+		c.sendStatus("unsubscribed from live props")
+		return
+	}
+	c.sendStatus("unsubscribed from live props for " + gameID)
+}
@@ -0,0 +1,53 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// Message types for alerts and notices sent to every client, each with
+// what it's about in its own field
+const (
+	MessageTypeEVAlert      = "ev_alert"
+	MessageTypeOutlierAlert = "outlier_alert"
+	MessageTypeEventAlert   = "event_alert"
+	MessageTypeSystemNotice = "system_notice"
+	MessageTypeAlertInbox   = "alert_inbox"
+	MessageTypeAlertState   = "alert_state"
+)
+
+// BroadcastMessage sends a message to all clients, on this instance and
+// through the relay on others
+func (h *Hub) BroadcastMessage(msg Message) {
+	msg.Timestamp = time.Now()
+	h.DeliverMessage(msg)
+	if h.relay != nil {
+		h.relay.PublishMessage(msg)
+	}
+}
+
+// DeliverMessage sends a message to all of this instance's clients,
+// leaving out those yet to authenticate
+func (h *Hub) DeliverMessage(msg Message) {
+	msg.Instance = h.instance
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("WebSocket: Failed to marshal %s message: %v", msg.Type, err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		if h.AuthRequired() && !client.authenticated {
+			continue
+		}
+		select {
+		case client.send <- data:
+		default:
+			// Skip slow clients, as for status messages
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package websocket
+
+import (
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/liveprops"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// Relay passes broadcasts on to other instances, which deliver them to
+// their own clients with DeliverOdds, DeliverStatus, DeliverMessage,
+// DeliverLiveProps and DeliverValueAlert
+type Relay interface {
+	PublishOdds(sport models.Sport, games []models.Game)
+	PublishStatus(status string)
+	PublishMessage(msg Message)
+	PublishLiveProps(live liveprops.GameProps)
+	PublishValueAlert(alert alerts.ValueAlert)
+}
+
+// SetRelay shares every broadcast through a relay. Call before anything is
+// broadcast.
+func (h *Hub) SetRelay(r Relay) {
+	h.relay = r
+}
+
+// Broadcast sends an odds update to all clients subscribed to a sport, on
+// this instance and through the relay on others
+func (h *Hub) Broadcast(sport models.Sport, games []models.Game) {
+	h.DeliverOdds(sport, games)
+	if h.relay != nil {
+		h.relay.PublishOdds(sport, games)
+	}
+}
+
+// BroadcastStatus sends a status message to all clients, on this instance
+// and through the relay on others
+func (h *Hub) BroadcastStatus(status string) {
+	h.DeliverStatus(status)
+	if h.relay != nil {
+		h.relay.PublishStatus(status)
+	}
+}
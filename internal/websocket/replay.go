@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// replayBufferSize is how many of each topic's latest messages are kept for
+// clients resuming with resume_from
+const replayBufferSize = 32
+
+// StatusReplayUnavailable tells a client resuming with resume_from that
+// messages it missed are no longer buffered, so it should refetch instead
+const StatusReplayUnavailable = "replay_unavailable"
+
+// sequenced is a message as sent, with its sequence number
+type sequenced struct {
+	seq  int64
+	data []byte
+}
+
+// topicBuffer is a topic's latest messages, oldest first. Every message
+// after dropped is in it.
+type topicBuffer struct {
+	messages []sequenced
+	dropped  int64
+}
+
+// replayBuffers numbers hub messages and keeps each topic's latest. Its
+// mutex is taken before the hub's, never after.
+type replayBuffers struct {
+	mu      sync.Mutex
+	lastSeq int64
+	start   int64
+	topics  map[string]*topicBuffer
+}
+
+// newReplayBuffers starts sequence numbers at the current Unix time in
+// milliseconds, so they keep increasing across restarts unless more than a
+// thousand messages a second were sent
+func newReplayBuffers() *replayBuffers {
+	start := time.Now().UnixMilli()
+	return &replayBuffers{
+		lastSeq: start,
+		start:   start,
+		topics:  make(map[string]*topicBuffer),
+	}
+}
+
+// sequence numbers a message on a topic, marshals it and keeps it for
+// replay
+func (h *Hub) sequence(topic string, msg *Message) ([]byte, error) {
+	r := h.replay
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	msg.Seq = r.lastSeq + 1
+	msg.Instance = h.instance
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	r.lastSeq = msg.Seq
+
+	buf := r.topics[topic]
+	if buf == nil {
+		buf = &topicBuffer{dropped: r.start}
+		r.topics[topic] = buf
+	}
+	buf.messages = append(buf.messages, sequenced{seq: msg.Seq, data: data})
+	if len(buf.messages) > replayBufferSize {
+		buf.dropped = buf.messages[0].seq
+		buf.messages = buf.messages[1:]
+	}
+	return data, nil
+}
+
+// resumeTopic sends a client a topic's messages after seq from, then
+// subscribes it, so nothing sent meanwhile is missed; any message sent
+// both ways comes live after the replay with a seq already seen. It
+// returns false, subscribing all the same, when messages after from are
+// no longer buffered or were numbered by another instance.
+func (h *Hub) resumeTopic(client *Client, topic string, from int64, instance string, subscribe func()) bool {
+	r := h.replay
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	defer subscribe()
+
+	if instance != "" && instance != h.instance {
+		return false
+	}
+	if from > r.lastSeq {
+		return false
+	}
+	buf := r.topics[topic]
+	if buf == nil {
+		// Nothing sent on the topic since starting
+		return from >= r.start
+	}
+	if from < buf.dropped {
+		return false
+	}
+	for _, m := range buf.messages {
+		if m.seq <= from {
+			continue
+		}
+		select {
+		case client.send <- m.data:
+		default:
+			// Buffer full, the rest won't fit either
+			return false
+		}
+	}
+	return true
+}
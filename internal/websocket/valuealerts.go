@@ -0,0 +1,161 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/alertstream"
+)
+
+// MessageTypeValueAlert carries a value alert to clients subscribed to the
+// alerts topic, and to those whose subscribe_alerts filter takes value
+// alerts, which get them only this way
+const MessageTypeValueAlert = "value_alert"
+
+// TopicAlerts is the topic for value alerts:
+// {"type": "subscribe", "topic": "alerts"}
+const TopicAlerts = "alerts"
+
+// SetActiveAlerts sets where the value alerts replayed to clients
+// subscribing to the alerts topic come from. Call before clients connect.
+func (h *Hub) SetActiveAlerts(fn func() []alerts.ValueAlert) {
+	h.activeAlerts = fn
+}
+
+// BroadcastValueAlert sends a value alert to the clients subscribed to the
+// alerts topic, on this instance and through the relay on others
+func (h *Hub) BroadcastValueAlert(alert alerts.ValueAlert) {
+	h.DeliverValueAlert(alert)
+	if h.relay != nil {
+		h.relay.PublishValueAlert(alert)
+	}
+}
+
+// DeliverValueAlert sends a value alert to this instance's clients
+// subscribed to the alerts topic or with a subscribe_alerts filter it
+// passes, once each
+func (h *Hub) DeliverValueAlert(alert alerts.ValueAlert) {
+	msg := valueAlertMessage(alert, false)
+	data, err := h.sequence(TopicAlerts, &msg)
+	if err != nil {
+		log.Printf("WebSocket: Failed to marshal value alert: %v", err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		if !client.wantsValueAlert(alert) {
+			continue
+		}
+		select {
+		case client.send <- data:
+		default:
+			// Skip slow clients, as for status messages
+			h.metrics.RecordMessageFailed()
+		}
+	}
+}
+
+// SubscribeValueAlerts starts sending value alerts to a client
+func (h *Hub) SubscribeValueAlerts(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	client.valueAlerts = true
+}
+
+// UnsubscribeValueAlerts stops sending value alerts to a client
+func (h *Hub) UnsubscribeValueAlerts(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	client.valueAlerts = false
+}
+
+// wantsValueAlert reports whether a client is sent a value alert. Call with
+// the hub's mutex held.
+func (c *Client) wantsValueAlert(alert alerts.ValueAlert) bool {
+	return c.valueAlerts || (c.alerts != nil && c.alerts.Match(valueStreamAlert(alert)))
+}
+
+// valueStreamAlert is what a subscribe_alerts filter matches a value alert
+// by
+func valueStreamAlert(alert alerts.ValueAlert) alertstream.Alert {
+	return alertstream.Alert{Type: alertstream.TypeValue, Category: alert.Category, Sport: alert.Sport}
+}
+
+// valueAlertMessage builds a value_alert message, marked as a replay when
+// the alert was raised before the client subscribed
+func valueAlertMessage(alert alerts.ValueAlert, replay bool) Message {
+	return Message{
+		Type:       MessageTypeValueAlert,
+		Sport:      alert.Sport,
+		ValueAlert: &alert,
+		Replay:     replay,
+		Timestamp:  time.Now(),
+	}
+}
+
+// handleSubscribeTopic subscribes a client to a topic. With resume_from,
+// the alerts it missed since are sent first, as they were sent.
+func (c *Client) handleSubscribeTopic(topic string, resumeFrom *int64, instance string) {
+	if topic != TopicAlerts {
+		c.sendError("Invalid topic: use " + TopicAlerts)
+		return
+	}
+	c.sendStatus("subscribed to value alerts")
+
+	c.hub.mu.RLock()
+	filter := c.alerts
+	c.hub.mu.RUnlock()
+
+	subscribe := func() { c.hub.SubscribeValueAlerts(c) }
+	if resumeFrom != nil {
+		if c.hub.resumeTopic(c, topic, *resumeFrom, instance, subscribe) {
+			return
+		}
+		c.sendStatus(StatusReplayUnavailable)
+	} else {
+		subscribe()
+	}
+
+	// Active alerts, less those a subscribe_alerts filter already replayed
+	c.replayValueAlerts(func(alert alerts.ValueAlert) bool {
+		return filter == nil || !filter.Match(valueStreamAlert(alert))
+	})
+}
+
+// replayValueAlerts sends the value alerts whose edge still stands and that
+// match, oldest first, so the client starts with what it would have seen
+// had it been connected
+func (c *Client) replayValueAlerts(match func(alerts.ValueAlert) bool) {
+	if c.hub.activeAlerts == nil {
+		return
+	}
+	for _, alert := range c.hub.activeAlerts() {
+		if !match(alert) {
+			continue
+		}
+		data, err := json.Marshal(valueAlertMessage(alert, true))
+		if err != nil {
+			continue
+		}
+		select {
+		case c.send <- data:
+		default:
+			// Buffer full, the rest won't fit either
+			return
+		}
+	}
+}
+
+func (c *Client) handleUnsubscribeTopic(topic string) {
+	if topic != TopicAlerts {
+		c.sendError("Invalid topic: use " + TopicAlerts)
+		return
+	}
+	c.hub.UnsubscribeValueAlerts(c)
+	c.sendStatus("unsubscribed from value alerts")
+}
@@ -0,0 +1,110 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+
+	"github.com/joshuakim/linefinder/internal/alerts"
+	"github.com/joshuakim/linefinder/internal/alertstream"
+	"github.com/joshuakim/linefinder/internal/metrics"
+	"github.com/joshuakim/linefinder/internal/models"
+)
+
+// TestValueAlertsOnce subscribes to value alerts both ways and checks each
+// alert, replayed or new, arrives once as a value_alert message
+func TestValueAlertsOnce(t *testing.T) {
+	active := alerts.ValueAlert{PlayerName: "Jayson Tatum", Category: models.CategoryPropValue, Sport: string(models.SportNBA)}
+	raised := alerts.ValueAlert{PlayerName: "Jaylen Brown", Category: models.CategoryPropValue, Sport: string(models.SportNBA)}
+
+	hub := NewHub(metrics.New(), 0)
+	go hub.Run()
+	stream := alertstream.New()
+	hub.SetAlertStream(stream)
+	hub.SetActiveAlerts(func() []alerts.ValueAlert { return []alerts.ValueAlert{active} })
+	conn, _, err := dial(t, hub, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send(t, conn, ClientMessage{Type: MessageTypeSubscribeAlerts, Types: []string{alertstream.TypeValue}})
+	send(t, conn, ClientMessage{Type: MessageTypeSubscribe, Topic: TopicAlerts})
+	got := receiveUntilPong(t, conn)
+	if want := []string{"status subscribed to alerts", "value_alert Jayson Tatum replay", "status subscribed to value alerts"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("on subscribing got %v, want %v", got, want)
+	}
+
+	// Also published to the stream, as the notification service does
+	hub.DeliverValueAlert(raised)
+	stream.Publish(alertstream.Alert{Type: alertstream.TypeValue, Category: raised.Category, Sport: raised.Sport, Data: raised})
+	// The stream is forwarded asynchronously
+	time.Sleep(50 * time.Millisecond)
+	got = receiveUntilPong(t, conn)
+	if want := []string{"value_alert Jaylen Brown"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("on a new alert got %v, want %v", got, want)
+	}
+}
+
+// dial serves the hub and connects a client to it, with query added to
+// the URL
+func dial(t *testing.T, hub *Hub, query string) (*gorilla.Conn, *http.Response, error) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWs(hub, w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	conn, resp, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+query, nil)
+	if err == nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, resp, err
+}
+
+func send(t *testing.T, conn *gorilla.Conn, msg ClientMessage) {
+	t.Helper()
+	data, _ := json.Marshal(msg)
+	if err := conn.WriteMessage(gorilla.TextMessage, data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// receiveUntilPong pings and summarizes the messages received before the
+// pong: their type, with the status of status messages, and the player of
+// value alerts and whether replayed
+func receiveUntilPong(t *testing.T, conn *gorilla.Conn) []string {
+	t.Helper()
+	send(t, conn, ClientMessage{Type: "ping"})
+
+	var got []string
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, frame, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("after %v: %v", got, err)
+		}
+		for _, data := range strings.Split(string(frame), "\n") {
+			var msg Message
+			if err := json.Unmarshal([]byte(data), &msg); err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case msg.Type == MessageTypePong:
+				return got
+			case msg.ValueAlert != nil && msg.Replay:
+				got = append(got, msg.Type+" "+msg.ValueAlert.PlayerName+" replay")
+			case msg.ValueAlert != nil:
+				got = append(got, msg.Type+" "+msg.ValueAlert.PlayerName)
+			case msg.Type == MessageTypeStatus:
+				got = append(got, msg.Type+" "+msg.Status)
+			default:
+				got = append(got, msg.Type)
+			}
+		}
+	}
+}
@@ -24,7 +24,7 @@ self.addEventListener('push', (event) => {
     body: 'New value alert detected',
     icon: '/icon-192.png',
     badge: '/badge-72.png',
-    tag: 'value-alerts',
+    tag: 'prop_value:value-alerts',
     data: { url: '/' }
   };
 
@@ -57,8 +57,17 @@ self.addEventListener('push', (event) => {
     ]
   };
 
+  // Oversized batches carry alert IDs only: fetch the details so the page
+  // can show them when the notification is opened
+  const details = data.data.truncated && data.data.fetch_url
+    ? fetch(new URL(data.data.fetch_url, self.registration.scope))
+        .then((res) => res.json())
+        .then((body) => { options.data = { ...data.data, alerts: body.alerts }; })
+        .catch((e) => console.error('[SW] Error fetching alert details:', e))
+    : Promise.resolve();
+
   event.waitUntil(
-    self.registration.showNotification(data.title, options)
+    details.then(() => self.registration.showNotification(data.title, options))
   );
 });
 
@@ -36,10 +36,11 @@ function App() {
     setLoading(true)
     setError(null)
     try {
-      const response = await fetch(`/api/odds/${sport}`)
+      const response = await fetch(`/api/v1/odds/${sport}`)
       if (!response.ok) {
         throw new Error('Failed to fetch odds')
       }
+      /** @type {import('./api/types').OddsResponse} */
       const data = await response.json()
       setGames(data.games || [])
     } catch (err) {
@@ -54,7 +55,7 @@ function App() {
     setRefreshing(true)
     setError(null)
     try {
-      const response = await fetch(`/api/refresh/${selectedSport}`, {
+      const response = await fetch(`/api/v1/refresh/${selectedSport}`, {
         method: 'POST',
       })
       if (!response.ok) {
@@ -0,0 +1,695 @@
+// Code generated by cmd/gentypes from internal/contract. DO NOT EDIT.
+// Regenerate with `make generate-clients`.
+
+/** alertstream.Alert */
+export interface Alert {
+  type: string;
+  category: string;
+  kind?: string;
+  sport?: string;
+  game_id?: string;
+  player?: string;
+  title: string;
+  body?: string;
+  data?: unknown;
+  created_at: string;
+}
+
+/** api.AlertSubscriptions: GET, PUT /api/v1/preferences/subscriptions */
+export interface AlertSubscriptions {
+  subscriptions: Record<string, Record<string, boolean> | null> | null;
+}
+
+/** bets.Bankroll: GET /api/v1/bankroll */
+export interface Bankroll {
+  currency: string;
+  unit_size: number;
+  bets: number;
+  pending: number;
+  pending_stake: number;
+  pending_units: number;
+  wins: number;
+  losses: number;
+  pushes: number;
+  staked: number;
+  staked_units: number;
+  profit: number;
+  roi: number;
+  units_won: number;
+  win_rate: number;
+  books: BookSummary[] | null;
+}
+
+/** database.Bet: GET /api/v1/bets (bets), POST /api/v1/bets (bet) */
+export interface Bet {
+  id: number;
+  game_id: string;
+  sport: string;
+  home_team: string;
+  away_team: string;
+  commence_time: string;
+  market: string;
+  selection: string;
+  point?: number;
+  bookmaker: string;
+  price: number;
+  stake: number;
+  unit_size: number;
+  stake_units: number;
+  profit_units: number;
+  note?: string;
+  result: string;
+  profit: number;
+  home_score?: number;
+  away_score?: number;
+  created_at: string;
+  graded_at?: string;
+  reminded_at?: string;
+  alert_id?: number;
+}
+
+/** alerts.BookConsidered */
+export interface BookConsidered {
+  key: string;
+  bookmaker: string;
+  line: number;
+  over_price: number;
+  under_price: number;
+  selected?: boolean;
+  excluded?: boolean;
+}
+
+/** steam.BookMove */
+export interface BookMove {
+  bookmaker: string;
+  from: number;
+  to: number;
+  move: number;
+}
+
+/** bets.BookSummary */
+export interface BookSummary {
+  bookmaker: string;
+  bets: number;
+  pending: number;
+  pending_stake: number;
+  pending_units: number;
+  wins: number;
+  losses: number;
+  pushes: number;
+  staked: number;
+  staked_units: number;
+  profit: number;
+  roi: number;
+  units_won: number;
+  win_rate: number;
+}
+
+/** models.Bookmaker */
+export interface Bookmaker {
+  key: string;
+  title: string;
+  last_update: string;
+  markets: MarketData[] | null;
+}
+
+/** lineups.Change */
+export interface Change {
+  kind: string;
+  game_id: string;
+  home_team: string;
+  away_team: string;
+  team: string;
+  player: string;
+  position: string;
+  detected_at: string;
+}
+
+/** websocket.ClientMessage: WebSocket /api/v1/ws, client to server */
+export interface ClientMessage {
+  type: string;
+  sport?: string;
+  topic?: string;
+  types?: string[];
+  categories?: string[];
+  sports?: string[];
+  game_id?: string;
+  resume?: ResumeState;
+  token?: string;
+  resume_from?: number;
+  instance?: string;
+}
+
+/** alerts.ConfidenceInputs */
+export interface ConfidenceInputs {
+  abs_difference: number;
+  distance: number;
+  ratio: number;
+  medium_ratio: number;
+  high_ratio: number;
+}
+
+/** models.EVOpportunity */
+export interface EVOpportunity {
+  id: string;
+  category: string;
+  game_id: string;
+  sport?: string;
+  home_team: string;
+  away_team: string;
+  commence_time: string;
+  market: string;
+  outcome: string;
+  point?: number;
+  bookmaker: string;
+  bookmaker_key: string;
+  price: number;
+  implied_probability: number;
+  fair_probability: number;
+  fair_price: number;
+  ev_pct: number;
+  method: string;
+  consensus_books: number;
+}
+
+/** api.ErrorResponse: Error responses */
+export interface ErrorResponse {
+  error: string;
+  code: string;
+}
+
+/** alerts.EventAlert */
+export interface EventAlert {
+  type: string;
+  category: string;
+  kind: string;
+  title: string;
+  body: string;
+  sport?: string;
+  game_id?: string;
+  player?: string;
+  url?: string;
+  created_at: string;
+  props?: PlayerWithProps[];
+  projected_close?: Projection;
+}
+
+/** alerts.Explanation */
+export interface Explanation {
+  projection_source: string;
+  mode: string;
+  threshold: number;
+  threshold_profile?: string;
+  confidence: ConfidenceInputs;
+  books: BookConsidered[] | null;
+}
+
+/** alertstream.Filter */
+export interface Filter {
+  types?: string[];
+  categories?: string[];
+  sports?: string[];
+}
+
+/** models.Game */
+export interface Game {
+  id: string;
+  sport_key: Sport;
+  sport_title: string;
+  commence_time: string;
+  home_team: string;
+  away_team: string;
+  status?: GameStatus;
+  bookmakers?: Bookmaker[];
+}
+
+/** store.GameInjuries: GET /api/v1/injuries/{sport}/{gameID} */
+export interface GameInjuries {
+  game_id: string;
+  home_team: TeamInjuries;
+  away_team: TeamInjuries;
+}
+
+/** liveprops.GameProps: GET /api/v1/live/props/{gameID} */
+export interface GameProps {
+  game_id: string;
+  home_team: string;
+  away_team: string;
+  home_score: number;
+  away_score: number;
+  period: string;
+  clock?: string;
+  elapsed: number;
+  final: boolean;
+  props: PropPace[] | null;
+  updated_at: string;
+}
+
+/** models.GameStatus */
+export type GameStatus = string;
+
+/** store.InjuredPlayer */
+export interface InjuredPlayer {
+  name: string;
+  position: string;
+  status: string;
+  body_part: string;
+  notes: string;
+}
+
+/** models.Market */
+export type Market = string;
+
+/** models.MarketData */
+export interface MarketData {
+  key: Market;
+  outcomes: Outcome[] | null;
+}
+
+/** websocket.Message: WebSocket /api/v1/ws, server to client */
+export interface Message {
+  type: string;
+  sport?: string;
+  games?: Game[];
+  timestamp: string;
+  error?: string;
+  status?: string;
+  alert?: Alert;
+  live?: GameProps;
+  value_alert?: ValueAlert;
+  replay?: boolean;
+  ev?: EVOpportunity;
+  outlier?: OutlierLine;
+  event?: EventAlert;
+  notice?: SystemNotice;
+  alert_state?: StateChange;
+  unread?: number;
+  instance?: string;
+  connection?: string;
+  identity?: string;
+  seq?: number;
+  retry_after_ms?: number;
+  resume?: ResumeState;
+}
+
+/** models.MyBookPrice */
+export interface MyBookPrice {
+  market: string;
+  outcome: string;
+  bookmaker: string;
+  price: number;
+  point?: number;
+  best_bookmaker: string;
+  best_price: number;
+  best_point?: number;
+  cents_given_up: number;
+  points_given_up?: number;
+}
+
+/** api.OddsResponse: GET /api/v1/odds/{sport} */
+export interface OddsResponse {
+  sport: Sport;
+  count: number;
+  games: Game[] | null;
+}
+
+/** models.Outcome */
+export interface Outcome {
+  name: string;
+  price: number;
+  point?: number;
+  description?: string;
+}
+
+/** models.OutlierLine */
+export interface OutlierLine {
+  id: string;
+  category: string;
+  game_id: string;
+  sport?: string;
+  home_team: string;
+  away_team: string;
+  commence_time: string;
+  market: string;
+  outcome: string;
+  bookmaker: string;
+  bookmaker_key: string;
+  point?: number;
+  price: number;
+  consensus_point?: number;
+  consensus_price: number;
+  consensus_books: number;
+  points_off?: number;
+  cents_off?: number;
+  favorable: boolean;
+}
+
+/** alerts.PlayScore */
+export interface PlayScore {
+  score: number;
+  edge: number;
+  confidence: number;
+  odds: number;
+  timing: number;
+}
+
+/** store.PlayerAverages: GET /api/v1/averages/{sport}/{gameID} (array) */
+export interface PlayerAverages {
+  name: string;
+  team: string;
+  injury_status?: string;
+  games_played: number;
+  averages: Record<string, number> | null;
+  stddevs?: Record<string, number>;
+  sources?: Record<string, Record<string, number> | null>;
+  projection_source?: Record<string, string>;
+}
+
+/** models.PlayerPropCategory */
+export interface PlayerPropCategory {
+  category: string;
+  market: PlayerPropMarket;
+  bookmakers: PropBookmaker[] | null;
+}
+
+/** models.PlayerPropMarket */
+export type PlayerPropMarket = string;
+
+/** models.PlayerWithProps */
+export interface PlayerWithProps {
+  name: string;
+  team: string;
+  role?: string;
+  props: PlayerPropCategory[] | null;
+}
+
+/** database.PreferencePreset */
+export interface PreferencePreset {
+  name: string;
+  built_in: boolean;
+  threshold_points: number;
+  threshold_rebounds: number;
+  threshold_assists: number;
+  threshold_threes: number;
+  threshold_default: number;
+  ev_threshold_pct: number;
+  period_ev_threshold_pct?: number;
+  min_confidence: string;
+  batch_interval_seconds: number;
+  quiet_start: string;
+  quiet_end: string;
+  created_at?: string;
+}
+
+/** database.Preferences: GET, PUT /api/v1/preferences */
+export interface Preferences {
+  enable_websocket: boolean;
+  enable_push: boolean;
+  push_subscription?: string;
+  threshold_points: number;
+  threshold_rebounds: number;
+  threshold_assists: number;
+  threshold_threes: number;
+  threshold_default: number;
+  sports: string[] | null;
+  quiet_start: string;
+  quiet_end: string;
+  timezone: string;
+  rate_limit_push: number;
+  rate_limit_news: number;
+  rate_limit_discord: number;
+  rate_limit_telegram: number;
+  enable_discord: boolean;
+  discord_webhook_url: string;
+  discord_bot_token: string;
+  discord_channel_id: string;
+  enable_telegram: boolean;
+  alert_subscriptions: Record<string, Record<string, boolean> | null> | null;
+  watchlist: string[] | null;
+  muted_players: string[] | null;
+  my_book: string;
+  excluded_bookmakers: string[] | null;
+  scan_window_hours: number;
+  vig_method: string;
+  ev_threshold_pct: number;
+  period_ev_threshold_pct: number;
+  outlier_points: number;
+  outlier_cents: number;
+  projection_mode: string;
+  projection_weight: number;
+  projection_sources: string[] | null;
+  projection_weights: Record<string, number> | null;
+  projection_disagreement_pct: number;
+  min_confidence: string;
+  detection_modes: Record<string, string> | null;
+  detection_percent_pct: number;
+  detection_zscore: number;
+  min_odds: number;
+  max_hold_percent: number;
+  batch_interval_seconds: number;
+  email: string;
+  enable_email: boolean;
+  rate_limit_email: number;
+  email_summary_enabled: boolean;
+  email_summary_time: string;
+  digest_enabled: boolean;
+  digest_time: string;
+  digest_channels: string[] | null;
+  auto_tune_thresholds: boolean;
+  daily_alert_cap: number;
+  max_bet_amount: number;
+  daily_bet_limit: number;
+  weekly_deposit_limit: number;
+  show_helpline: boolean;
+  cool_off_until?: string;
+  unit_size: number;
+  currency: string;
+  updated_at: string;
+}
+
+/** api.PresetResponse: POST /api/v1/preferences/preset/{name} */
+export interface PresetResponse {
+  message: string;
+  preset: PreferencePreset;
+  preferences: Preferences | null;
+}
+
+/** api.PresetsResponse: GET /api/v1/preferences/presets */
+export interface PresetsResponse {
+  presets: PreferencePreset[] | null;
+}
+
+/** closing.Projection */
+export interface Projection {
+  game_id: string;
+  sport: string;
+  market: string;
+  outcome: string;
+  unit: string;
+  current: number;
+  drift: number;
+  carry: number;
+  fitted: boolean;
+  projected_close: number;
+  projected_move: number;
+  advice: string;
+  books: number;
+  commence_time: string;
+  projected_at: string;
+}
+
+/** models.PropBookmaker */
+export interface PropBookmaker {
+  key: string;
+  title: string;
+  over_price: number;
+  under_price: number;
+  point: number;
+}
+
+/** liveprops.PropPace */
+export interface PropPace {
+  player: string;
+  team: string;
+  category: string;
+  line: number;
+  current: number;
+  projected: number;
+  pace: string;
+  minutes: number;
+}
+
+/** api.PropsResponse: GET /api/v1/props/{sport}/{gameID} */
+export interface PropsResponse {
+  game_id: string;
+  home_team: string;
+  away_team: string;
+  players: PlayerWithProps[] | null;
+  value_alerts: ValueAlert[] | null;
+  lineup?: Status;
+}
+
+/** api.PublicStatsResponse: GET /api/v1/stats/public */
+export interface PublicStatsResponse {
+  games_tracked: number;
+  alerts_today: number;
+  alert_hit_rate_percent?: number;
+  graded_alerts: number;
+  updated_at: string;
+}
+
+/** alerts.RankedPlay */
+export interface RankedPlay {
+  rank: number;
+  score: PlayScore;
+  alert: ValueAlert;
+}
+
+/** websocket.ResumeState */
+export interface ResumeState {
+  sport?: string;
+  topics?: string[];
+  alerts?: Filter;
+  live?: string[];
+}
+
+/** models.Sport */
+export type Sport = string;
+
+/** api.SportPreferences: GET, PUT /api/v1/preferences/sports */
+export interface SportPreferences {
+  sports: Record<string, boolean> | null;
+}
+
+/** lineups.Starter */
+export interface Starter {
+  name: string;
+  position: string;
+}
+
+/** alerts.StateChange */
+export interface StateChange {
+  alert_id: number;
+  game_id: string;
+  player_name: string;
+  prop_category: string;
+  direction: string;
+  from: string;
+  to: string;
+  reason?: string;
+  line?: number;
+  confidence?: string;
+  changed_at: string;
+}
+
+/** lineups.Status */
+export interface Status {
+  game_id: string;
+  confirmed: boolean;
+  home: TeamLineup;
+  away: TeamLineup;
+  changes?: Change[];
+  updated_at: string;
+}
+
+/** steam.Steam */
+export interface Steam {
+  game_id: string;
+  sport: string;
+  home_team: string;
+  away_team: string;
+  commence_time: string;
+  market: string;
+  player?: string;
+  outcome: string;
+  unit: string;
+  move: number;
+  books: BookMove[] | null;
+  window_minutes: number;
+  detected_at: string;
+}
+
+/** api.SteamResponse: GET /api/v1/steam */
+export interface SteamResponse {
+  sport?: string;
+  steam: Steam[] | null;
+  count: number;
+}
+
+/** alerts.SystemNotice */
+export interface SystemNotice {
+  category: string;
+  kind: string;
+  title: string;
+  body: string;
+}
+
+/** store.TeamInjuries */
+export interface TeamInjuries {
+  team: string;
+  players: InjuredPlayer[] | null;
+}
+
+/** lineups.TeamLineup */
+export interface TeamLineup {
+  team: string;
+  confirmed: boolean;
+  starters: Starter[] | null;
+}
+
+/** api.TopPlaysResponse: GET /api/v1/topplays */
+export interface TopPlaysResponse {
+  sport: string;
+  games: number;
+  plays: RankedPlay[] | null;
+  count: number;
+  available: number;
+}
+
+/** api.UndoResponse: POST /api/v1/undo */
+export interface UndoResponse {
+  message: string;
+  kind: string;
+}
+
+/** api.UndoableResponse: PUT /api/v1/preferences, POST /api/v1/unsubscribe, DELETE /api/v1/preferences/presets/{name} and /api/v1/webhooks/{id} */
+export interface UndoableResponse {
+  message: string;
+  undo_token: string;
+  undo_expires_at: string;
+}
+
+/** api.VAPIDKeyResponse: GET /api/v1/vapid-public-key */
+export interface VAPIDKeyResponse {
+  publicKey: string;
+}
+
+/** alerts.ValueAlert */
+export interface ValueAlert {
+  id: string;
+  history_id?: number;
+  category: string;
+  player_name: string;
+  team: string;
+  sport: string;
+  game_id: string;
+  game_time: string;
+  away_team: string;
+  home_team: string;
+  prop_category: string;
+  line: number;
+  average: number;
+  difference: number;
+  abs_difference: number;
+  direction: string;
+  confidence: string;
+  state?: string;
+  best_odds: number;
+  bookmaker: string;
+  my_book?: MyBookPrice;
+  sources?: Record<string, number>;
+  sources_disagree?: boolean;
+  explanation?: Explanation;
+  detected_at: string;
+  expires_at: string;
+}
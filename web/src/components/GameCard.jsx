@@ -9,8 +9,9 @@ function GameCard({ game, sport, onClick }) {
   useEffect(() => {
     const fetchInjuries = async () => {
       try {
-        const response = await fetch(`/api/injuries/${sport}/${game.id}`)
+        const response = await fetch(`/api/v1/injuries/${sport}/${game.id}`)
         if (response.ok) {
+          /** @type {import('../api/types').GameInjuries} */
           const data = await response.json()
           setInjuries(data)
         }
@@ -15,23 +15,26 @@ function GameDetail({ game, sport, onBack }) {
       try {
         // Fetch all data in parallel
         const [propsRes, avgRes, injRes] = await Promise.all([
-          fetch(`/api/props/${sport}/${game.id}`),
-          fetch(`/api/averages/${sport}/${game.id}`),
-          fetch(`/api/injuries/${sport}/${game.id}`),
+          fetch(`/api/v1/props/${sport}/${game.id}`),
+          fetch(`/api/v1/averages/${sport}/${game.id}`),
+          fetch(`/api/v1/injuries/${sport}/${game.id}`),
         ])
 
         if (!propsRes.ok) {
           throw new Error('Failed to fetch player props')
         }
+        /** @type {import('../api/types').PropsResponse} */
         const propsData = await propsRes.json()
         setPlayerProps(propsData)
 
         if (avgRes.ok) {
+          /** @type {import('../api/types').PlayerAverages[]} */
           const avgData = await avgRes.json()
           setPlayerAverages(avgData)
         }
 
         if (injRes.ok) {
+          /** @type {import('../api/types').GameInjuries} */
           const injData = await injRes.json()
           setInjuries(injData)
         }
@@ -34,8 +34,9 @@ function Settings({ isOpen, onClose }) {
     setLoading(true)
     setError(null)
     try {
-      const response = await fetch('/api/preferences')
+      const response = await fetch('/api/v1/preferences')
       if (!response.ok) throw new Error('Failed to load preferences')
+      /** @type {import('../api/types').Preferences} */
       const data = await response.json()
       setPreferences(data)
     } catch (err) {
@@ -60,7 +61,7 @@ function Settings({ isOpen, onClose }) {
     setError(null)
     setSuccess(null)
     try {
-      const response = await fetch('/api/preferences', {
+      const response = await fetch('/api/v1/preferences', {
         method: 'PUT',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify({ ...preferences, ...updates })
@@ -76,6 +77,25 @@ function Settings({ isOpen, onClose }) {
     }
   }
 
+  const applyPreset = async (name) => {
+    setSaving(true)
+    setError(null)
+    setSuccess(null)
+    try {
+      const response = await fetch(`/api/v1/preferences/preset/${name}`, { method: 'POST' })
+      if (!response.ok) throw new Error('Failed to apply preset')
+      /** @type {import('../api/types').PresetResponse} */
+      const data = await response.json()
+      setPreferences(data.preferences)
+      setSuccess(`Applied ${name} preset`)
+      setTimeout(() => setSuccess(null), 2000)
+    } catch (err) {
+      setError(err.message)
+    } finally {
+      setSaving(false)
+    }
+  }
+
   const handleEnablePush = async () => {
     setPushLoading(true)
     setError(null)
@@ -229,6 +249,28 @@ function Settings({ isOpen, onClose }) {
               </div>
             </section>
 
+            {/* Presets */}
+            <section className="settings-section">
+              <h3>Presets</h3>
+              <p className="settings-note">
+                Set thresholds, confidence, batching and quiet hours in one step
+              </p>
+              <div className="settings-row">
+                <div className="push-controls">
+                  {['conservative', 'balanced', 'aggressive'].map(name => (
+                    <button
+                      key={name}
+                      className="btn-secondary"
+                      onClick={() => applyPreset(name)}
+                      disabled={saving}
+                    >
+                      {name.charAt(0).toUpperCase() + name.slice(1)}
+                    </button>
+                  ))}
+                </div>
+              </div>
+            </section>
+
             {/* Alert Thresholds */}
             <section className="settings-section">
               <h3>Value Alert Thresholds</h3>
@@ -2,6 +2,8 @@ function SportTabs({ selectedSport, onSportChange }) {
   const sports = [
     { key: 'nfl', label: 'NFL' },
     { key: 'nba', label: 'NBA' },
+    { key: 'mlb', label: 'MLB' },
+    { key: 'nhl', label: 'NHL' },
   ]
 
   return (
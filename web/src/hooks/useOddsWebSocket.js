@@ -8,9 +8,12 @@ import { useEffect, useRef, useCallback, useState } from 'react'
  * - Connection state tracking
  * - Subscription management per sport
  * - Ping/pong for keepalive
+ * - Moving to a new connection on reconnect_after before closing the old
+ *   one, resuming its subscriptions, so deploys don't drop updates
+ * - Catching up on updates missed while disconnected with resume_from
  *
- * @param {string} sport - The sport to subscribe to ('nba' or 'nfl')
- * @param {function} onUpdate - Callback when new odds data arrives
+ * @param {string} sport - The sport to subscribe to ('nba', 'nfl', 'mlb' or 'nhl')
+ * @param {(games: import('../api/types').Game[]) => void} onUpdate - Callback when new odds data arrives
  * @param {boolean} enabled - Whether WebSocket should be connected
  * @returns {object} - { connected, connecting, lastUpdate, error, reconnectAttempts }
  */
@@ -18,6 +21,12 @@ export function useOddsWebSocket(sport, onUpdate, enabled = true) {
   const ws = useRef(null)
   const reconnectTimeout = useRef(null)
   const pingInterval = useRef(null)
+  // Connection being replaced after reconnect_after, and what it was
+  // subscribed to
+  const retiring = useRef(null)
+  const resumeState = useRef(null)
+  // Last odds update applied: its sport, seq and the instance numbering it
+  const lastSeq = useRef(null)
 
   const [connected, setConnected] = useState(false)
   const [connecting, setConnecting] = useState(false)
@@ -50,28 +59,52 @@ export function useOddsWebSocket(sport, onUpdate, enabled = true) {
     // Determine WebSocket URL
     const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
     const host = window.location.host
-    const wsUrl = `${protocol}//${host}/api/ws`
+    // With WS_AUTH_TOKENS set, open the app as /?token=... to pass it on
+    const token = new URLSearchParams(window.location.search).get('token')
+    const query = token ? `?token=${encodeURIComponent(token)}` : ''
+    const wsUrl = `${protocol}//${host}/api/v1/ws${query}`
 
     console.log(`[WebSocket] Connecting to ${wsUrl}...`)
-    ws.current = new WebSocket(wsUrl)
+    const socket = new WebSocket(wsUrl)
+    ws.current = socket
 
-    ws.current.onopen = () => {
+    socket.onopen = () => {
       console.log('[WebSocket] Connected')
       setConnected(true)
       setConnecting(false)
       setError(null)
       reconnectAttempts.current = 0
 
-      // Subscribe to the current sport
-      if (sport) {
+      if (resumeState.current) {
+        // Pick up where the previous connection left off, on the current sport
+        console.log('[WebSocket] Resuming subscriptions')
+        socket.send(JSON.stringify({
+          type: 'resume',
+          resume: { ...resumeState.current, sport: sport || resumeState.current.sport }
+        }))
+        resumeState.current = null
+      } else if (sport) {
+        // Subscribe to the current sport, asking for updates missed since
+        // the last one when reconnecting
         console.log(`[WebSocket] Subscribing to ${sport}`)
-        ws.current.send(JSON.stringify({
+        const last = lastSeq.current?.sport === sport ? lastSeq.current : null
+        socket.send(JSON.stringify({
           type: 'subscribe',
-          sport: sport
+          sport: sport,
+          ...(last && { resume_from: last.seq, instance: last.instance })
         }))
       }
 
+      // Only now let go of a connection being replaced
+      if (retiring.current) {
+        retiring.current.close()
+        retiring.current = null
+      }
+
       // Start ping interval for keepalive
+      if (pingInterval.current) {
+        clearInterval(pingInterval.current)
+      }
       pingInterval.current = setInterval(() => {
         if (ws.current?.readyState === WebSocket.OPEN) {
           ws.current.send(JSON.stringify({ type: 'ping' }))
@@ -79,17 +112,26 @@ export function useOddsWebSocket(sport, onUpdate, enabled = true) {
       }, 30000) // Ping every 30 seconds
     }
 
-    ws.current.onmessage = (event) => {
+    socket.onmessage = (event) => {
       try {
         // Handle multiple messages (batched)
         const messages = event.data.split('\n').filter(Boolean)
 
         for (const msgStr of messages) {
+          /** @type {import('../api/types').Message} */
           const data = JSON.parse(msgStr)
 
           switch (data.type) {
             case 'odds_update':
               if (data.sport === sport && data.games) {
+                // Skip updates already applied, sent again after a replay
+                const last = lastSeq.current
+                if (data.seq && last?.sport === sport && last.instance === data.instance && data.seq <= last.seq) {
+                  break
+                }
+                if (data.seq) {
+                  lastSeq.current = { sport, seq: data.seq, instance: data.instance }
+                }
                 console.log(`[WebSocket] Received odds update for ${sport}: ${data.games.length} games`)
                 setLastUpdate(new Date(data.timestamp))
                 onUpdate(data.games)
@@ -110,6 +152,20 @@ export function useOddsWebSocket(sport, onUpdate, enabled = true) {
               setError(data.error)
               break
 
+            case 'reconnect_after':
+              // The server is shutting down: open a new connection after
+              // the suggested delay, keeping this one until it's up
+              if (socket !== ws.current) break
+              console.log(`[WebSocket] Server restarting, reconnecting in ${data.retry_after_ms || 0}ms`)
+              resumeState.current = data.resume || null
+              clearTimeout(reconnectTimeout.current)
+              reconnectTimeout.current = setTimeout(() => {
+                retiring.current = socket
+                ws.current = null
+                connect()
+              }, data.retry_after_ms || 0)
+              break
+
             default:
               console.log(`[WebSocket] Unknown message type: ${data.type}`)
           }
@@ -119,7 +175,14 @@ export function useOddsWebSocket(sport, onUpdate, enabled = true) {
       }
     }
 
-    ws.current.onclose = (event) => {
+    socket.onclose = (event) => {
+      // A replaced connection closing is expected
+      if (socket !== ws.current) {
+        if (retiring.current === socket) {
+          retiring.current = null
+        }
+        return
+      }
       console.log(`[WebSocket] Disconnected (code: ${event.code}, reason: ${event.reason})`)
       setConnected(false)
       setConnecting(false)
@@ -142,7 +205,8 @@ export function useOddsWebSocket(sport, onUpdate, enabled = true) {
       }
     }
 
-    ws.current.onerror = (event) => {
+    socket.onerror = (event) => {
+      if (socket !== ws.current) return
       console.error('[WebSocket] Error:', event)
       setError('WebSocket connection error')
     }
@@ -158,6 +222,10 @@ export function useOddsWebSocket(sport, onUpdate, enabled = true) {
         ws.current.close()
         ws.current = null
       }
+      if (retiring.current) {
+        retiring.current.close()
+        retiring.current = null
+      }
       if (reconnectTimeout.current) {
         clearTimeout(reconnectTimeout.current)
         reconnectTimeout.current = null
@@ -176,6 +244,10 @@ export function useOddsWebSocket(sport, onUpdate, enabled = true) {
         ws.current.close()
         ws.current = null
       }
+      if (retiring.current) {
+        retiring.current.close()
+        retiring.current = null
+      }
       if (reconnectTimeout.current) {
         clearTimeout(reconnectTimeout.current)
       }
@@ -45,11 +45,12 @@ export async function getServiceWorkerRegistration() {
 
 // Get VAPID public key from server
 export async function getVAPIDPublicKey() {
-  const response = await fetch('/api/vapid-public-key');
+  const response = await fetch('/api/v1/vapid-public-key');
   if (!response.ok) {
     const error = await response.json();
     throw new Error(error.error || 'Failed to get VAPID key');
   }
+  /** @type {import('../api/types').VAPIDKeyResponse} */
   const data = await response.json();
   return data.publicKey;
 }
@@ -80,7 +81,7 @@ export async function subscribeToPush(registration, vapidPublicKey) {
   });
 
   // Send subscription to server
-  const response = await fetch('/api/subscribe', {
+  const response = await fetch('/api/v1/subscribe', {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },
     body: JSON.stringify({ subscription: JSON.stringify(subscription) })
@@ -104,7 +105,7 @@ export async function unsubscribeFromPush(registration) {
   }
 
   // Notify server
-  const response = await fetch('/api/unsubscribe', {
+  const response = await fetch('/api/v1/unsubscribe', {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' }
   });